@@ -148,6 +148,17 @@ const (
 	EngineTypePebble
 )
 
+// String implements the fmt.Stringer interface.
+func (e EngineType) String() string {
+	switch e {
+	case EngineTypeRocksDB:
+		return "rocksdb"
+	case EngineTypePebble:
+		return "experimental-pebble"
+	}
+	return ""
+}
+
 // Config is embedded by server.Config. A base config is not meant to be used
 // directly, but embedding configs should call cfg.InitDefaults().
 type Config struct {
@@ -162,6 +162,8 @@ type StoreSpec struct {
 	Size       SizeSpec
 	InMemory   bool
 	Attributes roachpb.Attributes
+	// Engine is the storage engine used by the store.
+	Engine EngineType
 	// UseFileRegistry is true if the "file registry" store version is desired.
 	// This is set by CCL code when encryption-at-rest is in use.
 	UseFileRegistry bool
@@ -198,6 +200,9 @@ func (ss StoreSpec) String() string {
 		}
 		fmt.Fprintf(&buffer, ",")
 	}
+	if ss.Engine != EngineTypeRocksDB {
+		fmt.Fprintf(&buffer, "engine=%s,", ss.Engine)
+	}
 	// Trim the extra comma from the end if it exists.
 	if l := buffer.Len(); l > 0 {
 		buffer.Truncate(l - 1)
@@ -221,7 +226,7 @@ var fractionRegex = regexp.MustCompile(`^([-]?([0-9]+\.[0-9]*|[0-9]*\.[0-9]+|[0-
 
 // NewStoreSpec parses the string passed into a --store flag and returns a
 // StoreSpec if it is correctly parsed.
-// There are four possible fields that can be passed in, comma separated:
+// There are five possible fields that can be passed in, comma separated:
 // - path=xxx The directory in which to the rocks db instance should be
 //   located, required unless using a in memory storage.
 // - type=mem This specifies that the store is an in memory storage instead of
@@ -235,6 +240,8 @@ var fractionRegex = regexp.MustCompile(`^([-]?([0-9]+\.[0-9]*|[0-9]*\.[0-9]+|[0-
 //   - 20%             -> 20% of the available space
 //   - 0.2             -> 20% of the available space
 // - attrs=xxx:yyy:zzz A colon separated list of optional attributes.
+// - engine=xxx The storage engine used by the store, either rocksdb (the
+//   default) or experimental-pebble.
 // Note that commas are forbidden within any field name or value.
 func NewStoreSpec(value string) (StoreSpec, error) {
 	const pathField = "path"
@@ -308,12 +315,24 @@ func NewStoreSpec(value string) (StoreSpec, error) {
 			} else {
 				return StoreSpec{}, fmt.Errorf("%s is not a valid store type", value)
 			}
+		case "engine":
+			switch value {
+			case EngineTypeRocksDB.String():
+				ss.Engine = EngineTypeRocksDB
+			case EngineTypePebble.String():
+				ss.Engine = EngineTypePebble
+			default:
+				return StoreSpec{}, fmt.Errorf("%s is not a valid storage engine", value)
+			}
 		case "rocksdb":
 			ss.RocksDBOptions = value
 		default:
 			return StoreSpec{}, fmt.Errorf("%s is not a valid store field", field)
 		}
 	}
+	if ss.Engine == EngineTypePebble && ss.RocksDBOptions != "" {
+		return StoreSpec{}, fmt.Errorf("rocksdb options cannot be specified for a pebble store")
+	}
 	if ss.InMemory {
 		// Only in memory stores don't need a path and require a size.
 		if ss.Path != "" {
@@ -117,6 +117,17 @@ func TestNewStoreSpec(t *testing.T) {
 		// RocksDB
 		{"path=/,rocksdb=key1=val1;key2=val2", "", StoreSpec{Path: "/", RocksDBOptions: "key1=val1;key2=val2"}},
 
+		// engine
+		{"path=/mnt/hda1,engine=rocksdb", "", StoreSpec{Path: "/mnt/hda1"}},
+		{"path=/mnt/hda1,engine=experimental-pebble", "", StoreSpec{Path: "/mnt/hda1", Engine: base.EngineTypePebble}},
+		{"type=mem,size=20GiB,engine=experimental-pebble", "", StoreSpec{
+			Size:     SizeSpec{InBytes: 21474836480},
+			InMemory: true,
+			Engine:   base.EngineTypePebble,
+		}},
+		{"path=/mnt/hda1,engine=other", "other is not a valid storage engine", StoreSpec{}},
+		{"path=/,engine=experimental-pebble,rocksdb=key1=val1", "rocksdb options cannot be specified for a pebble store", StoreSpec{}},
+
 		// all together
 		{"path=/mnt/hda1,attrs=hdd:ssd,size=20GiB", "", StoreSpec{
 			Path:       "/mnt/hda1",
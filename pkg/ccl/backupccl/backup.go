@@ -66,12 +66,16 @@ const (
 
 const (
 	backupOptRevisionHistory = "revision_history"
+	backupOptDetached        = "detached"
 	localityURLParam         = "COCKROACH_LOCALITY"
 	defaultLocalityValue     = "default"
 )
 
 var backupOptionExpectValues = map[string]sql.KVStringOptValidate{
 	backupOptRevisionHistory: sql.KVStringOptRequireNoValue,
+	backupOptDetached:        sql.KVStringOptRequireNoValue,
+	backupOptEncPassphrase:   sql.KVStringOptRequireValue,
+	backupOptEncKeyFile:      sql.KVStringOptRequireValue,
 }
 
 // BackupCheckpointInterval is the interval at which backup progress is saved
@@ -80,16 +84,19 @@ var BackupCheckpointInterval = time.Minute
 
 // ReadBackupDescriptorFromURI creates an export store from the given URI, then
 // reads and unmarshals a BackupDescriptor at the standard location in the
-// export storage.
+// export storage. If the backup is encrypted, encryption must hold its key.
 func ReadBackupDescriptorFromURI(
-	ctx context.Context, uri string, settings *cluster.Settings,
+	ctx context.Context,
+	uri string,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupDescriptor, error) {
 	exportStore, err := storageccl.ExportStorageFromURI(ctx, uri, settings)
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
 	defer exportStore.Close()
-	backupDesc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorName)
+	backupDesc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorName, encryption)
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
@@ -102,7 +109,10 @@ func ReadBackupDescriptorFromURI(
 // readBackupDescriptor reads and unmarshals a BackupDescriptor from filename in
 // the provided export store.
 func readBackupDescriptor(
-	ctx context.Context, exportStore storageccl.ExportStorage, filename string,
+	ctx context.Context,
+	exportStore storageccl.ExportStorage,
+	filename string,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupDescriptor, error) {
 	r, err := exportStore.ReadFile(ctx, filename)
 	if err != nil {
@@ -113,6 +123,10 @@ func readBackupDescriptor(
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
+	descBytes, err = decryptDescriptor(descBytes, filename, encryption)
+	if err != nil {
+		return BackupDescriptor{}, err
+	}
 	var backupDesc BackupDescriptor
 	if err := protoutil.Unmarshal(descBytes, &backupDesc); err != nil {
 		return BackupDescriptor{}, err
@@ -121,7 +135,10 @@ func readBackupDescriptor(
 }
 
 func readBackupPartitionDescriptor(
-	ctx context.Context, exportStore storageccl.ExportStorage, filename string,
+	ctx context.Context,
+	exportStore storageccl.ExportStorage,
+	filename string,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupPartitionDescriptor, error) {
 	r, err := exportStore.ReadFile(ctx, filename)
 	if err != nil {
@@ -132,6 +149,10 @@ func readBackupPartitionDescriptor(
 	if err != nil {
 		return BackupPartitionDescriptor{}, err
 	}
+	descBytes, err = decryptDescriptor(descBytes, filename, encryption)
+	if err != nil {
+		return BackupPartitionDescriptor{}, err
+	}
 	var backupDesc BackupPartitionDescriptor
 	if err := protoutil.Unmarshal(descBytes, &backupDesc); err != nil {
 		return BackupPartitionDescriptor{}, err
@@ -463,9 +484,9 @@ func splitAndFilterSpans(
 	return out
 }
 
-func optsToKVOptions(opts map[string]string) tree.KVOptions {
+func optsToKVOptions(opts map[string]string) (tree.KVOptions, error) {
 	if len(opts) == 0 {
-		return nil
+		return nil, nil
 	}
 	sortedOpts := make([]string, 0, len(opts))
 	for k := range opts {
@@ -476,11 +497,20 @@ func optsToKVOptions(opts map[string]string) tree.KVOptions {
 	for _, k := range sortedOpts {
 		opt := tree.KVOption{Key: tree.Name(k)}
 		if v := opts[k]; v != "" {
+			switch k {
+			case backupOptEncPassphrase:
+				v = "redacted"
+			case backupOptEncKeyFile:
+				var err error
+				if v, err = storageccl.SanitizeExportStorageURI(v); err != nil {
+					return nil, err
+				}
+			}
 			opt.Value = tree.NewDString(v)
 		}
 		kvopts = append(kvopts, opt)
 	}
-	return kvopts
+	return kvopts, nil
 }
 
 func backupJobDescription(
@@ -490,9 +520,13 @@ func backupJobDescription(
 	incrementalFrom []string,
 	opts map[string]string,
 ) (string, error) {
+	kvOpts, err := optsToKVOptions(opts)
+	if err != nil {
+		return "", err
+	}
 	b := &tree.Backup{
 		AsOf:    backup.AsOf,
-		Options: optsToKVOptions(opts),
+		Options: kvOpts,
 		Targets: backup.Targets,
 	}
 
@@ -543,6 +577,7 @@ func writeBackupDescriptor(
 	settings *cluster.Settings,
 	exportStore storageccl.ExportStorage,
 	filename string,
+	encryption *roachpb.FileEncryptionOptions,
 	desc *BackupDescriptor,
 ) error {
 	sort.Sort(BackupFileDescriptors(desc.Files))
@@ -560,6 +595,10 @@ func writeBackupDescriptor(
 	if err != nil {
 		return err
 	}
+	descBuf, err = encryptDescriptor(descBuf, encryption)
+	if err != nil {
+		return err
+	}
 	return exportStore.WriteFile(ctx, filename, bytes.NewReader(descBuf))
 }
 
@@ -570,12 +609,17 @@ func writeBackupPartitionDescriptor(
 	ctx context.Context,
 	exportStore storageccl.ExportStorage,
 	filename string,
+	encryption *roachpb.FileEncryptionOptions,
 	desc *BackupPartitionDescriptor,
 ) error {
 	descBuf, err := protoutil.Marshal(desc)
 	if err != nil {
 		return err
 	}
+	descBuf, err = encryptDescriptor(descBuf, encryption)
+	if err != nil {
+		return err
+	}
 
 	return exportStore.WriteFile(ctx, filename, bytes.NewReader(descBuf))
 }
@@ -641,6 +685,7 @@ func backup(
 	job *jobs.Job,
 	backupDesc *BackupDescriptor,
 	checkpointDesc *BackupDescriptor,
+	encryption *roachpb.FileEncryptionOptions,
 	resultsCh chan<- tree.Datums,
 ) (roachpb.BulkOpSummary, error) {
 	// TODO(dan): Figure out how permissions should work. #6713 is tracking this
@@ -754,6 +799,7 @@ func backup(
 					StorageByLocalityKV: storageByLocalityKV,
 					StartTime:           span.start,
 					MVCCFilter:          roachpb.MVCCFilter(backupDesc.MVCCFilter),
+					Encryption:          encryption,
 				}
 				rawRes, pErr := client.SendWrappedWith(ctx, db.NonTransactionalSender(), header, req)
 				if pErr != nil {
@@ -795,7 +841,7 @@ func backup(
 					checkpointMu.Lock()
 					backupDesc.Files = checkpointFiles
 					err := writeBackupDescriptor(
-						ctx, settings, defaultStore, BackupDescriptorCheckpointName, backupDesc,
+						ctx, settings, defaultStore, BackupDescriptorCheckpointName, encryption, backupDesc,
 					)
 					checkpointMu.Unlock()
 					if err != nil {
@@ -849,14 +895,16 @@ func backup(
 					return err
 				}
 				defer store.Close()
-				return writeBackupPartitionDescriptor(ctx, store, filename, &desc)
+				return writeBackupPartitionDescriptor(ctx, store, filename, encryption, &desc)
 			}(); err != nil {
 				return mu.exported, err
 			}
 		}
 	}
 
-	if err := writeBackupDescriptor(ctx, settings, defaultStore, BackupDescriptorName, backupDesc); err != nil {
+	if err := writeBackupDescriptor(
+		ctx, settings, defaultStore, BackupDescriptorName, encryption, backupDesc,
+	); err != nil {
 		return mu.exported, err
 	}
 
@@ -884,12 +932,14 @@ func sanitizeLocalityKV(kv string) string {
 // that the location is writable and locking out accidental concurrent
 // operations on that location if subsequently try this check. Callers must
 // clean up the written checkpoint file (BackupDescriptorCheckpointName) only
-// after writing to the backup file location (BackupDescriptorName).
+// after writing to the backup file location (BackupDescriptorName). The
+// checkpoint is encrypted if encryption is set.
 func VerifyUsableExportTarget(
 	ctx context.Context,
 	settings *cluster.Settings,
 	exportStore storageccl.ExportStorage,
 	readable string,
+	encryption *roachpb.FileEncryptionOptions,
 ) error {
 	if r, err := exportStore.ReadFile(ctx, BackupDescriptorName); err == nil {
 		// TODO(dt): If we audit exactly what not-exists error each ExportStorage
@@ -906,7 +956,7 @@ func VerifyUsableExportTarget(
 			readable, BackupDescriptorCheckpointName)
 	}
 	if err := writeBackupDescriptor(
-		ctx, settings, exportStore, BackupDescriptorCheckpointName, &BackupDescriptor{},
+		ctx, settings, exportStore, BackupDescriptorCheckpointName, encryption, &BackupDescriptor{},
 	); err != nil {
 		return errors.Wrapf(err, "cannot write to %s", readable)
 	}
@@ -935,6 +985,15 @@ func backupPlanHook(
 		return nil, nil, nil, false, err
 	}
 
+	// A detached backup only creates its job, in the current transaction, and
+	// returns its ID without waiting for it.
+	var detached bool
+	for _, opt := range backupStmt.Options {
+		if opt.Key == backupOptDetached {
+			detached = true
+		}
+	}
+
 	header := sqlbase.ResultColumns{
 		{Name: "job_id", Typ: types.Int},
 		{Name: "status", Typ: types.String},
@@ -944,6 +1003,9 @@ func backupPlanHook(
 		{Name: "system_records", Typ: types.Int},
 		{Name: "bytes", Typ: types.Int},
 	}
+	if detached {
+		header = sqlbase.ResultColumns{{Name: "job_id", Typ: types.Int}}
+	}
 
 	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
 		// TODO(dan): Move this span into sql.
@@ -960,7 +1022,7 @@ func backupPlanHook(
 			return err
 		}
 
-		if !p.ExtendedEvalContext().TxnImplicit {
+		if !detached && !p.ExtendedEvalContext().TxnImplicit {
 			return errors.Errorf("BACKUP cannot be used inside a transaction")
 		}
 
@@ -1002,6 +1064,38 @@ func backupPlanHook(
 			mvccFilter = MVCCFilter_All
 		}
 
+		if err := checkEncryptionVersion(opts, p.ExecCfg().Settings); err != nil {
+			return err
+		}
+		if _, ok := opts[backupOptEncPassphrase]; ok && detached {
+			return errors.Errorf(
+				"%s cannot be used with %s: the passphrase isn't persisted, so the job could "+
+					"only be run by this node; use %s instead",
+				backupOptEncPassphrase, backupOptDetached, backupOptEncKeyFile)
+		}
+		passphrase, err := getEncryptionPassphrase(ctx, opts, p.ExecCfg().Settings)
+		if err != nil {
+			return err
+		}
+		// An incremental backup is encrypted with the same key as the full
+		// backup it is based on, which is derived from the salt stored with it.
+		var encryption *roachpb.FileEncryptionOptions
+		var encryptionSalt []byte
+		if len(incrementalFrom) > 0 {
+			encryption, encryptionSalt, err = getEncryptionFromBase(
+				ctx, incrementalFrom[0], passphrase, p.ExecCfg().Settings)
+			if err != nil {
+				return err
+			}
+		} else if passphrase != nil {
+			if encryptionSalt, err = storageccl.GenerateSalt(); err != nil {
+				return err
+			}
+			encryption = &roachpb.FileEncryptionOptions{
+				Key: storageccl.GenerateKey(passphrase, encryptionSalt),
+			}
+		}
+
 		targetDescs, completeDBs, err := ResolveTargetsToDescriptors(ctx, p, endTime, backupStmt.Targets)
 		if err != nil {
 			return err
@@ -1046,7 +1140,7 @@ func backupPlanHook(
 				// since all we need to do is get the past backups' table/index spans,
 				// but it will be safer for future code to avoid having older-style
 				// descriptors around.
-				desc, err := ReadBackupDescriptorFromURI(ctx, uri, p.ExecCfg().Settings)
+				desc, err := ReadBackupDescriptorFromURI(ctx, uri, p.ExecCfg().Settings, encryption)
 				if err != nil {
 					return errors.Wrapf(err, "failed to read backup from %q", uri)
 				}
@@ -1208,11 +1302,21 @@ func backupPlanHook(
 
 		// TODO (lucy): For partitioned backups, also add verification for other
 		// stores we are writing to in addition to the default.
-		if err := VerifyUsableExportTarget(ctx, p.ExecCfg().Settings, defaultStore, defaultURI); err != nil {
+		if err := VerifyUsableExportTarget(
+			ctx, p.ExecCfg().Settings, defaultStore, defaultURI, encryption,
+		); err != nil {
 			return err
 		}
+		if encryption != nil {
+			if err := writeEncryptionInfo(ctx, defaultStore, encryptionSalt); err != nil {
+				return errors.Wrapf(err, "cannot write to %s", defaultURI)
+			}
+		}
 
-		_, errCh, err := p.ExecCfg().JobRegistry.StartJob(ctx, resultsCh, jobs.Record{
+		jobEncryption, releaseKey := makeJobEncryptionOptions(opts, defaultURI, encryption)
+		defer releaseKey()
+
+		record := jobs.Record{
 			Description: description,
 			Username:    p.User(),
 			DescriptorIDs: func() (sqlDescIDs []sqlbase.ID) {
@@ -1227,9 +1331,19 @@ func backupPlanHook(
 				URI:              defaultURI,
 				URIsByLocalityKV: urisByLocalityKV,
 				BackupDescriptor: descBytes,
+				Encryption:       jobEncryption,
 			},
 			Progress: jobspb.BackupProgress{},
-		})
+		}
+		if detached {
+			job, err := p.ExecCfg().JobRegistry.CreateJobWithTxn(ctx, record, p.Txn())
+			if err != nil {
+				return err
+			}
+			resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(*job.ID()))}
+			return nil
+		}
+		_, errCh, err := p.ExecCfg().JobRegistry.StartJob(ctx, resultsCh, record)
 		if err != nil {
 			return err
 		}
@@ -1260,6 +1374,18 @@ func (b *backupResumer) Resume(
 		return pgerror.Wrapf(err, pgcode.DataCorrupted,
 			"unmarshal backup descriptor")
 	}
+	// Keep the history the backup reads from being GC'd while it runs. With
+	// revision_history, that's every revision since the previous backup. Until
+	// all nodes are upgraded, the GC TTL is all there is to rely on.
+	if len(backupDesc.Spans) > 0 && b.settings.Version.IsActive(cluster.VersionProtectedTimestamps) {
+		protectedTS := backupDesc.EndTime
+		if backupDesc.MVCCFilter == MVCCFilter_All && backupDesc.StartTime != (hlc.Timestamp{}) {
+			protectedTS = backupDesc.StartTime
+		}
+		if err := b.job.ProtectTimestamp(ctx, protectedTS, backupDesc.Spans); err != nil {
+			return errors.Wrap(err, "protecting timestamp")
+		}
+	}
 	// For all backups, partitioned or not, the main BACKUP manifest is stored at
 	// details.URI.
 	defaultConf, err := storageccl.ExportStorageConfFromURI(details.URI)
@@ -1270,6 +1396,10 @@ func (b *backupResumer) Resume(
 	if err != nil {
 		return errors.Wrapf(err, "make storage")
 	}
+	encryption, err := getJobEncryption(ctx, details.Encryption, b.settings)
+	if err != nil {
+		return err
+	}
 	storageByLocalityKV := make(map[string]*roachpb.ExportStorage)
 	for kv, uri := range details.URIsByLocalityKV {
 		conf, err := storageccl.ExportStorageConfFromURI(uri)
@@ -1283,7 +1413,9 @@ func (b *backupResumer) Resume(
 	// they could be using either the new or the old foreign key
 	// representations. We should just preserve whatever representation the
 	// table descriptors were using and leave them alone.
-	if desc, err := readBackupDescriptor(ctx, defaultStore, BackupDescriptorCheckpointName); err == nil {
+	if desc, err := readBackupDescriptor(
+		ctx, defaultStore, BackupDescriptorCheckpointName, encryption,
+	); err == nil {
 		// If the checkpoint is from a different cluster, it's meaningless to us.
 		// More likely though are dummy/lock-out checkpoints with no ClusterID.
 		if desc.ClusterID.Equal(p.ExecCfg().ClusterID()) {
@@ -1307,6 +1439,7 @@ func (b *backupResumer) Resume(
 		b.job,
 		&backupDesc,
 		checkpointDesc,
+		encryption,
 		resultsCh,
 	)
 	b.res = res
@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/partitionccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl/sampledataccl"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -39,6 +40,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -46,6 +49,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/jobutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -3167,3 +3171,290 @@ func TestBackupCreatedStatsFromIncrementalBackup(t *testing.T) {
 			{"bank_stats", "{payload}", "3", "2", "2"},
 		})
 }
+
+func TestBackupRestoreEncrypted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	full, inc := localFoo+"/full", localFoo+"/inc"
+	const passphrase = `encryption_passphrase = 'abcdefg'`
+
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH `+passphrase, full)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.ExpectErr(t, "appears encrypted",
+		`BACKUP DATABASE data TO $1 INCREMENTAL FROM $2`, inc, full)
+	sqlDB.ExpectErr(t, "is the key correct",
+		`BACKUP DATABASE data TO $1 INCREMENTAL FROM $2 WITH encryption_passphrase = 'wrong'`, inc, full)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 INCREMENTAL FROM $2 WITH `+passphrase, inc, full)
+
+	// Neither the descriptors nor the data are readable without the key.
+	for _, backupDir := range []string{"full", "inc"} {
+		files, err := ioutil.ReadDir(filepath.Join(dir, "foo", backupDir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			if f.Name() == backupccl.BackupEncryptionInfoName {
+				continue
+			}
+			contents, err := ioutil.ReadFile(filepath.Join(dir, "foo", backupDir, f.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(contents, []byte("encrypt")) {
+				t.Fatalf("expected %s/%s to be encrypted", backupDir, f.Name())
+			}
+		}
+	}
+
+	var description string
+	sqlDB.QueryRow(t,
+		`SELECT description FROM [SHOW JOBS] WHERE job_type = 'BACKUP' ORDER BY created DESC LIMIT 1`,
+	).Scan(&description)
+	if strings.Contains(description, "abcdefg") || !strings.Contains(description, "redacted") {
+		t.Fatalf("expected the passphrase to be redacted in %q", description)
+	}
+
+	sqlDB.ExpectErr(t, "appears encrypted", `SHOW BACKUP $1`, inc)
+	sqlDB.ExpectErr(t, "is the key correct", `SHOW BACKUP $1 WITH encryption_passphrase = 'wrong'`, inc)
+	sqlDB.CheckQueryResults(t,
+		`SELECT table_name, rows FROM [SHOW BACKUP $1 WITH `+passphrase+`]`,
+		[][]string{{"bank", fmt.Sprint(numAccounts)}})
+
+	sqlDB.Exec(t, `CREATE DATABASE data2`)
+	sqlDB.ExpectErr(t, "appears encrypted",
+		`RESTORE data.* FROM $1, $2 WITH into_db = 'data2'`, full, inc)
+	sqlDB.ExpectErr(t, "is the key correct",
+		`RESTORE data.* FROM $1, $2 WITH into_db = 'data2', encryption_passphrase = 'wrong'`, full, inc)
+	sqlDB.Exec(t, `RESTORE data.* FROM $1, $2 WITH into_db = 'data2', `+passphrase, full, inc)
+	sqlDB.CheckQueryResults(t,
+		`SELECT * FROM data2.bank ORDER BY id`, sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`))
+
+	// The key derived from the passphrase isn't persisted in the payload of
+	// either job, and a job that couldn't be resumed without it is refused.
+	salt, err := ioutil.ReadFile(filepath.Join(dir, "foo", "full", backupccl.BackupEncryptionInfoName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := storageccl.GenerateKey([]byte("abcdefg"), salt)
+	rows := sqlDB.Query(t, `SELECT id, payload FROM system.jobs`)
+	for rows.Next() {
+		var id int64
+		var payload []byte
+		if err := rows.Scan(&id, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(payload, key) {
+			t.Fatalf("expected the payload of job %d not to contain the key", id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.ExpectErr(t, "encryption_passphrase cannot be used with detached",
+		`BACKUP DATABASE data TO $1 WITH detached, `+passphrase, localFoo+"/detached")
+
+	t.Run("key-file", func(t *testing.T) {
+		if err := ioutil.WriteFile(filepath.Join(dir, "backup.key"), []byte("hijklmn\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		keyFile := `encryption_key_file = 'nodelocal:///backup.key'`
+		keyFileDir := localFoo + "/keyfile"
+
+		sqlDB.ExpectErr(t, "cannot specify both",
+			`BACKUP DATABASE data TO $1 WITH `+passphrase+`, `+keyFile, keyFileDir)
+		sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH `+keyFile, keyFileDir)
+		// The contents of the key file are equivalent to a passphrase.
+		sqlDB.CheckQueryResults(t,
+			`SELECT table_name FROM [SHOW BACKUP $1 WITH encryption_passphrase = 'hijklmn']`,
+			[][]string{{"bank"}})
+		sqlDB.Exec(t, `CREATE DATABASE data3`)
+		sqlDB.Exec(t, `RESTORE data.* FROM $1 WITH into_db = 'data3', `+keyFile, keyFileDir)
+		sqlDB.CheckQueryResults(t,
+			`SELECT count(*) FROM data3.bank`, [][]string{{fmt.Sprint(numAccounts)}})
+
+		// The key of a detached backup is read again from the key file by the
+		// node that adopts its job.
+		var jobID int64
+		sqlDB.QueryRow(t,
+			`BACKUP DATABASE data TO $1 WITH detached, `+keyFile, localFoo+"/keyfile-detached",
+		).Scan(&jobID)
+		testutils.SucceedsSoon(t, func() error {
+			var status string
+			sqlDB.QueryRow(t, `SELECT status FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&status)
+			if status != string(jobs.StatusSucceeded) {
+				return errors.Errorf("expected backup job %d to succeed, found %s", jobID, status)
+			}
+			return nil
+		})
+		sqlDB.CheckQueryResults(t,
+			`SELECT table_name FROM [SHOW BACKUP $1 WITH `+keyFile+`]`,
+			[][]string{{"bank"}})
+	})
+}
+
+func TestBackupRestoreEncryptedMixedVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	bootstrapVersion := cluster.ClusterVersion{
+		Version: cluster.VersionByKey(cluster.VersionBackupEncryption - 1),
+	}
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		ExternalIODir: dir,
+		Settings:      cluster.MakeClusterSettings(bootstrapVersion.Version, cluster.BinaryServerVersion),
+		Knobs: base.TestingKnobs{
+			Store:  &storage.StoreTestingKnobs{BootstrapVersion: &bootstrapVersion},
+			Server: &server.TestingKnobs{DisableAutomaticVersionUpgrade: 1},
+		},
+	})
+	defer s.Stopper().Stop(context.TODO())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE DATABASE data`)
+	sqlDB.Exec(t, `CREATE TABLE data.t (i INT PRIMARY KEY)`)
+	sqlDB.ExpectErr(t, "encryption_passphrase and encryption_key_file require all nodes to be upgraded",
+		`BACKUP DATABASE data TO $1 WITH encryption_passphrase = 'abcdefg'`, localFoo)
+	sqlDB.ExpectErr(t, "encryption_passphrase and encryption_key_file require all nodes to be upgraded",
+		`BACKUP DATABASE data TO $1 WITH encryption_key_file = 'nodelocal:///backup.key'`, localFoo)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, localFoo)
+	sqlDB.ExpectErr(t, "encryption_passphrase and encryption_key_file require all nodes to be upgraded",
+		`RESTORE data.* FROM $1 WITH into_db = 'data', encryption_passphrase = 'abcdefg'`, localFoo)
+}
+
+func TestScheduledBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Scheduled runs create their backup jobs without starting them, they are
+	// started by the adoption loop.
+	defer func(oldInterval time.Duration) {
+		jobs.DefaultAdoptInterval = oldInterval
+	}(jobs.DefaultAdoptInterval)
+	jobs.DefaultAdoptInterval = 100 * time.Millisecond
+
+	const numAccounts = 10
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	sqlDB.ExpectErr(t, "invalid cron expression",
+		`CREATE SCHEDULE FOR BACKUP DATABASE data TO $1 RECURRING 'sometimes'`, localFoo)
+	sqlDB.ExpectErr(t, "invalid cron expression",
+		`CREATE SCHEDULE FOR BACKUP DATABASE data TO $1 RECURRING '@hourly' FULL BACKUP '* *'`, localFoo)
+	sqlDB.ExpectErr(t, "does not support the encryption_passphrase option",
+		`CREATE SCHEDULE FOR BACKUP DATABASE data TO $1 RECURRING '@hourly' WITH encryption_passphrase = 'abc'`,
+		localFoo)
+
+	var id int64
+	var name string
+	sqlDB.QueryRow(t,
+		`CREATE SCHEDULE FOR BACKUP DATABASE data TO $1 RECURRING '@hourly' FULL BACKUP '@daily'`, localFoo,
+	).Scan(&id, &name)
+	if expected := "BACKUP DATABASE data"; name != expected {
+		t.Fatalf("expected schedule name %q, got %q", expected, name)
+	}
+	sqlDB.CheckQueryResults(t,
+		`SELECT name, schedule_status, recurrence, executor_type FROM [SHOW SCHEDULES]`,
+		[][]string{{name, "ACTIVE", "@hourly", "scheduled-backup"}})
+
+	backupDirs := func() []string {
+		files, err := ioutil.ReadDir(filepath.Join(dir, "foo"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			t.Fatal(err)
+		}
+		var dirs []string
+		for _, f := range files {
+			dirs = append(dirs, f.Name())
+		}
+		return dirs
+	}
+
+	// Make the schedule due, at two consecutive hours, so that the first run is
+	// a full backup and the second an incremental one on top of it. A run only
+	// takes into account the backup of the previous run once it has succeeded.
+	sqlDB.Exec(t, `SET CLUSTER SETTING jobs.scheduler.interval = '50ms'`)
+	scheduled := timeutil.Now().Add(-2 * time.Hour).Truncate(time.Hour)
+	for i := 1; i <= 2; i++ {
+		sqlDB.Exec(t, `UPDATE system.scheduled_jobs SET next_run = $2 WHERE schedule_id = $1`,
+			id, scheduled.Add(time.Duration(i)*time.Hour))
+		testutils.SucceedsSoon(t, func() error {
+			var n int
+			sqlDB.QueryRow(t,
+				`SELECT count(*) FROM [SHOW JOBS] WHERE job_type = 'BACKUP' AND status = 'succeeded'`,
+			).Scan(&n)
+			if n != i {
+				return errors.Errorf("expected %d succeeded backups, found %d", i, n)
+			}
+			return nil
+		})
+	}
+	if dirs := backupDirs(); len(dirs) != 2 {
+		t.Fatalf("expected 2 backups, found %v", dirs)
+	}
+	var description string
+	sqlDB.QueryRow(t,
+		`SELECT description FROM [SHOW JOBS] WHERE job_type = 'BACKUP' ORDER BY created DESC LIMIT 1`,
+	).Scan(&description)
+	if !strings.Contains(description, "INCREMENTAL FROM") {
+		t.Fatalf("expected the second scheduled backup to be incremental, got %q", description)
+	}
+
+	dirs := backupDirs()
+	sqlDB.Exec(t, `CREATE DATABASE data2`)
+	sqlDB.Exec(t, `RESTORE data.* FROM $1, $2 WITH into_db = 'data2'`,
+		localFoo+"/"+dirs[0], localFoo+"/"+dirs[1])
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM data2.bank`, [][]string{{fmt.Sprint(numAccounts)}})
+
+	sqlDB.Exec(t, `PAUSE SCHEDULE $1`, id)
+	sqlDB.CheckQueryResults(t,
+		`SELECT schedule_status, next_run IS NULL FROM [SHOW SCHEDULES]`, [][]string{{"PAUSED", "true"}})
+	sqlDB.Exec(t, `RESUME SCHEDULE $1`, id)
+	sqlDB.CheckQueryResults(t,
+		`SELECT schedule_status, next_run IS NULL FROM [SHOW SCHEDULES]`, [][]string{{"ACTIVE", "false"}})
+	sqlDB.Exec(t, `DROP SCHEDULE $1`, id)
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM [SHOW SCHEDULES]`, [][]string{{"0"}})
+	sqlDB.ExpectErr(t, "not found", `PAUSE SCHEDULE $1`, id)
+
+	// A detached backup can be run in a transaction: its job is only created
+	// if the transaction commits.
+	tx, err := sqlDB.DB.(*gosql.DB).Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jobID int64
+	if err := tx.QueryRow(
+		`BACKUP DATABASE data TO $1 WITH detached`, localFoo+"/detached",
+	).Scan(&jobID); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	testutils.SucceedsSoon(t, func() error {
+		var status string
+		sqlDB.QueryRow(t, `SELECT status FROM [SHOW JOBS] WHERE job_id = $1`, jobID).Scan(&status)
+		if status != string(jobs.StatusSucceeded) {
+			return errors.Errorf("expected backup job %d to succeed, found %s", jobID, status)
+		}
+		return nil
+	})
+	tx, err = sqlDB.DB.(*gosql.DB).Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`BACKUP DATABASE data TO $1`, localFoo+"/in-txn"); !testutils.IsError(
+		err, "BACKUP cannot be used inside a transaction",
+	) {
+		t.Fatalf("expected BACKUP in a transaction to fail, got %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// BackupEncryptionInfoName is the file name used to store the salt from which
+// the key of an encrypted backup is derived. Every backup in an incremental
+// chain uses the salt of the full backup it is based on, so that the whole
+// chain can be restored with a single passphrase.
+const BackupEncryptionInfoName = "ENCRYPTION-INFO"
+
+const (
+	backupOptEncPassphrase = "encryption_passphrase"
+	backupOptEncKeyFile    = "encryption_key_file"
+)
+
+// checkEncryptionVersion returns an error if opts ask for encryption before
+// every node knows how to encrypt the files of a backup.
+func checkEncryptionVersion(opts map[string]string, settings *cluster.Settings) error {
+	_, hasPassphrase := opts[backupOptEncPassphrase]
+	_, hasKeyFile := opts[backupOptEncKeyFile]
+	if (hasPassphrase || hasKeyFile) && !settings.Version.IsActive(cluster.VersionBackupEncryption) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"%s and %s require all nodes to be upgraded to %s",
+			backupOptEncPassphrase, backupOptEncKeyFile,
+			cluster.VersionByKey(cluster.VersionBackupEncryption))
+	}
+	return nil
+}
+
+// getEncryptionPassphrase returns the passphrase specified by either the
+// encryption_passphrase or the encryption_key_file option, or nil if the
+// operation is not encrypted. The key file is read from an export storage URI,
+// e.g. nodelocal:///backup.key, and its contents are used as the passphrase.
+func getEncryptionPassphrase(
+	ctx context.Context, opts map[string]string, settings *cluster.Settings,
+) ([]byte, error) {
+	passphrase, hasPassphrase := opts[backupOptEncPassphrase]
+	keyFile, hasKeyFile := opts[backupOptEncKeyFile]
+	if hasPassphrase && hasKeyFile {
+		return nil, errors.Errorf("cannot specify both %s and %s", backupOptEncPassphrase, backupOptEncKeyFile)
+	}
+	if hasPassphrase {
+		if passphrase == "" {
+			return nil, errors.Errorf("%s must not be empty", backupOptEncPassphrase)
+		}
+		return []byte(passphrase), nil
+	}
+	if !hasKeyFile {
+		return nil, nil
+	}
+	return readEncryptionKeyFile(ctx, keyFile, settings)
+}
+
+// readEncryptionKeyFile returns the contents of the encryption_key_file at
+// uri, which are used as the passphrase.
+func readEncryptionKeyFile(
+	ctx context.Context, uri string, settings *cluster.Settings,
+) ([]byte, error) {
+	store, err := storageccl.ExportStorageFromURI(ctx, uri, settings)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening %s", backupOptEncKeyFile)
+	}
+	defer store.Close()
+	r, err := store.ReadFile(ctx, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", backupOptEncKeyFile)
+	}
+	defer r.Close()
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", backupOptEncKeyFile)
+	}
+	// Editors like to end files with a newline, which shouldn't be part of the
+	// key.
+	contents = bytes.TrimRight(contents, "\r\n")
+	if len(contents) == 0 {
+		return nil, errors.Errorf("%s is empty", backupOptEncKeyFile)
+	}
+	return contents, nil
+}
+
+// readEncryptionInfo reads the salt stored alongside the encrypted backup at
+// uri.
+func readEncryptionInfo(
+	ctx context.Context, uri string, settings *cluster.Settings,
+) ([]byte, error) {
+	store, err := storageccl.ExportStorageFromURI(ctx, uri, settings)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	r, err := store.ReadFile(ctx, BackupEncryptionInfoName)
+	if err != nil {
+		return nil, errors.Wrapf(err,
+			"reading %s from %q (is the backup encrypted?)", BackupEncryptionInfoName, uri)
+	}
+	defer r.Close()
+	salt, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(salt) != storageccl.EncryptionSaltSize {
+		return nil, errors.Errorf("invalid %s in %q", BackupEncryptionInfoName, uri)
+	}
+	return salt, nil
+}
+
+func writeEncryptionInfo(
+	ctx context.Context, exportStore storageccl.ExportStorage, salt []byte,
+) error {
+	return exportStore.WriteFile(ctx, BackupEncryptionInfoName, bytes.NewReader(salt))
+}
+
+// getEncryptionFromBase returns the encryption options for the backups based
+// on the full backup at baseURI, or nil if passphrase is nil.
+func getEncryptionFromBase(
+	ctx context.Context, baseURI string, passphrase []byte, settings *cluster.Settings,
+) (*roachpb.FileEncryptionOptions, []byte, error) {
+	if passphrase == nil {
+		return nil, nil, nil
+	}
+	salt, err := readEncryptionInfo(ctx, baseURI, settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &roachpb.FileEncryptionOptions{Key: storageccl.GenerateKey(passphrase, salt)}, salt, nil
+}
+
+// encryptionKeys holds the keys derived from the encryption_passphrase of the
+// backup and restore jobs started by this node, by KeyID. The passphrase isn't
+// persisted, so these keys only live as long as the statement that started
+// the job.
+var encryptionKeys struct {
+	syncutil.Mutex
+	m map[string]*roachpb.FileEncryptionOptions
+}
+
+// makeJobEncryptionOptions returns the encryption options to persist in the
+// details of a job, or nil if encryption is nil. The key
+// is never persisted: a key file is read again when the job is resumed, and a
+// key derived from a passphrase is registered in memory until the returned
+// function is called, once the job is done.
+func makeJobEncryptionOptions(
+	opts map[string]string, baseURI string, encryption *roachpb.FileEncryptionOptions,
+) (*jobspb.BackupEncryptionOptions, func()) {
+	if encryption == nil {
+		return nil, func() {}
+	}
+	if keyFile, ok := opts[backupOptEncKeyFile]; ok {
+		return &jobspb.BackupEncryptionOptions{KeyFileURI: keyFile, BaseURI: baseURI}, func() {}
+	}
+	keyID := uuid.MakeV4().String()
+	encryptionKeys.Lock()
+	defer encryptionKeys.Unlock()
+	if encryptionKeys.m == nil {
+		encryptionKeys.m = make(map[string]*roachpb.FileEncryptionOptions)
+	}
+	encryptionKeys.m[keyID] = encryption
+	return &jobspb.BackupEncryptionOptions{BaseURI: baseURI, KeyID: keyID}, func() {
+		encryptionKeys.Lock()
+		defer encryptionKeys.Unlock()
+		delete(encryptionKeys.m, keyID)
+	}
+}
+
+// getJobEncryption returns the key of a job from the encryption options in its
+// details, or nil if the job doesn't use encryption.
+func getJobEncryption(
+	ctx context.Context, opts *jobspb.BackupEncryptionOptions, settings *cluster.Settings,
+) (*roachpb.FileEncryptionOptions, error) {
+	if opts == nil {
+		return nil, nil
+	}
+	if opts.KeyFileURI == "" {
+		encryptionKeys.Lock()
+		defer encryptionKeys.Unlock()
+		encryption, ok := encryptionKeys.m[opts.KeyID]
+		if !ok {
+			return nil, errors.Errorf(
+				"the key derived from the %s is only known to the node that started the job, "+
+					"which is no longer running it; the statement must be run again",
+				backupOptEncPassphrase)
+		}
+		return encryption, nil
+	}
+	passphrase, err := readEncryptionKeyFile(ctx, opts.KeyFileURI, settings)
+	if err != nil {
+		return nil, err
+	}
+	encryption, _, err := getEncryptionFromBase(ctx, opts.BaseURI, passphrase, settings)
+	return encryption, err
+}
+
+// encryptDescriptor encrypts the serialized descriptor if the backup is
+// encrypted.
+func encryptDescriptor(
+	descBytes []byte, encryption *roachpb.FileEncryptionOptions,
+) ([]byte, error) {
+	if encryption == nil {
+		return descBytes, nil
+	}
+	return storageccl.EncryptFile(descBytes, encryption.Key)
+}
+
+// decryptDescriptor decrypts the serialized descriptor read from filename if
+// the backup is encrypted, and otherwise checks that it isn't.
+func decryptDescriptor(
+	descBytes []byte, filename string, encryption *roachpb.FileEncryptionOptions,
+) ([]byte, error) {
+	if encryption == nil {
+		if storageccl.AppearsEncrypted(descBytes) {
+			return nil, errors.Errorf(
+				"%s appears encrypted -- try specifying %s or %s",
+				filename, backupOptEncPassphrase, backupOptEncKeyFile)
+		}
+		return descBytes, nil
+	}
+	descBytes, err := storageccl.DecryptFile(descBytes, encryption.Key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypting %s", filename)
+	}
+	return descBytes, nil
+}
@@ -63,15 +63,20 @@ var restoreOptionExpectValues = map[string]sql.KVStringOptValidate{
 	restoreOptSkipMissingFKs:       sql.KVStringOptRequireNoValue,
 	restoreOptSkipMissingSequences: sql.KVStringOptRequireNoValue,
 	restoreOptSkipMissingViews:     sql.KVStringOptRequireNoValue,
+	backupOptEncPassphrase:         sql.KVStringOptRequireValue,
+	backupOptEncKeyFile:            sql.KVStringOptRequireValue,
 }
 
 func loadBackupDescs(
-	ctx context.Context, uris []string, settings *cluster.Settings,
+	ctx context.Context,
+	uris []string,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) ([]BackupDescriptor, error) {
 	backupDescs := make([]BackupDescriptor, len(uris))
 
 	for i, uri := range uris {
-		desc, err := ReadBackupDescriptorFromURI(ctx, uri, settings)
+		desc, err := ReadBackupDescriptorFromURI(ctx, uri, settings, encryption)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to read backup descriptor")
 		}
@@ -89,7 +94,10 @@ func loadBackupDescs(
 // default) original backup locality values to URIs that currently contain
 // the backup files.
 func getBackupLocalityInfo(
-	ctx context.Context, uris []string, settings *cluster.Settings,
+	ctx context.Context,
+	uris []string,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) (jobspb.RestoreDetails_BackupLocalityInfo, error) {
 	var info jobspb.RestoreDetails_BackupLocalityInfo
 	if len(uris) == 1 {
@@ -112,7 +120,7 @@ func getBackupLocalityInfo(
 	// First read the main backup descriptor, which is required to be at the first
 	// URI in the list. We don't read the table descriptors, so there's no need to
 	// upgrade them.
-	mainBackupDesc, err := readBackupDescriptor(ctx, stores[0], BackupDescriptorName)
+	mainBackupDesc, err := readBackupDescriptor(ctx, stores[0], BackupDescriptorName, encryption)
 	if err != nil {
 		return info, err
 	}
@@ -123,7 +131,7 @@ func getBackupLocalityInfo(
 	for _, filename := range mainBackupDesc.PartitionDescriptorFilenames {
 		found := false
 		for i, store := range stores {
-			if desc, err := readBackupPartitionDescriptor(ctx, store, filename); err == nil {
+			if desc, err := readBackupPartitionDescriptor(ctx, store, filename, encryption); err == nil {
 				if desc.BackupID != mainBackupDesc.ID {
 					return info, errors.Errorf(
 						"expected backup part to have backup ID %s, found %s",
@@ -1105,9 +1113,13 @@ func WriteTableDescs(
 func restoreJobDescription(
 	p sql.PlanHookState, restore *tree.Restore, from [][]string, opts map[string]string,
 ) (string, error) {
+	kvOpts, err := optsToKVOptions(opts)
+	if err != nil {
+		return "", err
+	}
 	r := &tree.Restore{
 		AsOf:    restore.AsOf,
-		Options: optsToKVOptions(opts),
+		Options: kvOpts,
 		Targets: restore.Targets,
 		From:    make([]tree.PartitionedBackup, len(restore.From)),
 	}
@@ -1184,6 +1196,7 @@ func restore(
 	tableRewrites TableRewriteMap,
 	overrideDB string,
 	job *jobs.Job,
+	encryption *roachpb.FileEncryptionOptions,
 	resultsCh chan<- tree.Datums,
 ) (roachpb.BulkOpSummary, []*sqlbase.DatabaseDescriptor, []*sqlbase.TableDescriptor, error) {
 	// A note about contexts and spans in this method: the top-level context
@@ -1359,6 +1372,7 @@ func restore(
 				Files:         readyForImportSpan.files,
 				EndTime:       endTime,
 				Rekeys:        rekeys,
+				Encryption:    encryption,
 			}
 
 			log.VEventf(restoreCtx, 1, "importing %d of %d", idx, len(importSpans))
@@ -1502,18 +1516,32 @@ func doRestorePlan(
 	opts map[string]string,
 	resultsCh chan<- tree.Datums,
 ) error {
+	if err := checkEncryptionVersion(opts, p.ExecCfg().Settings); err != nil {
+		return err
+	}
+	passphrase, err := getEncryptionPassphrase(ctx, opts, p.ExecCfg().Settings)
+	if err != nil {
+		return err
+	}
+	// Every backup in the chain is encrypted with the key derived from the
+	// salt of the full backup, which comes first.
+	encryption, _, err := getEncryptionFromBase(ctx, from[0][0], passphrase, p.ExecCfg().Settings)
+	if err != nil {
+		return err
+	}
+
 	defaultURIs := make([]string, len(from))
 	localityInfo := make([]jobspb.RestoreDetails_BackupLocalityInfo, len(from))
 	for i, uris := range from {
 		// The first URI in the list must contain the main BACKUP manifest.
 		defaultURIs[i] = uris[0]
-		info, err := getBackupLocalityInfo(ctx, uris, p.ExecCfg().Settings)
+		info, err := getBackupLocalityInfo(ctx, uris, p.ExecCfg().Settings, encryption)
 		if err != nil {
 			return err
 		}
 		localityInfo[i] = info
 	}
-	mainBackupDescs, err := loadBackupDescs(ctx, defaultURIs, p.ExecCfg().Settings)
+	mainBackupDescs, err := loadBackupDescs(ctx, defaultURIs, p.ExecCfg().Settings, encryption)
 	if err != nil {
 		return err
 	}
@@ -1604,6 +1632,9 @@ func doRestorePlan(
 		}
 	}
 
+	jobEncryption, releaseKey := makeJobEncryptionOptions(opts, from[0][0], encryption)
+	defer releaseKey()
+
 	_, errCh, err := p.ExecCfg().JobRegistry.StartJob(ctx, resultsCh, jobs.Record{
 		Description: description,
 		Username:    p.User(),
@@ -1620,6 +1651,7 @@ func doRestorePlan(
 			BackupLocalityInfo: localityInfo,
 			TableDescs:         tables,
 			OverrideDB:         opts[restoreOptIntoDB],
+			Encryption:         jobEncryption,
 		},
 		Progress: jobspb.RestoreProgress{},
 	})
@@ -1636,9 +1668,12 @@ func doRestorePlan(
 // skip_missing_foreign_keys was set, we should have aborted the RESTORE and
 // returned an error prior to this.
 func loadBackupSQLDescs(
-	ctx context.Context, details jobspb.RestoreDetails, settings *cluster.Settings,
+	ctx context.Context,
+	details jobspb.RestoreDetails,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) ([]BackupDescriptor, BackupDescriptor, []sqlbase.Descriptor, error) {
-	backupDescs, err := loadBackupDescs(ctx, details.URIs, settings)
+	backupDescs, err := loadBackupDescs(ctx, details.URIs, settings, encryption)
 	if err != nil {
 		return nil, BackupDescriptor{}, nil, err
 	}
@@ -1705,7 +1740,13 @@ func (r *restoreResumer) Resume(
 	details := r.job.Details().(jobspb.RestoreDetails)
 	p := phs.(sql.PlanHookState)
 
-	backupDescs, latestBackupDesc, sqlDescs, err := loadBackupSQLDescs(ctx, details, r.settings)
+	encryption, err := getJobEncryption(ctx, details.Encryption, r.settings)
+	if err != nil {
+		return err
+	}
+	backupDescs, latestBackupDesc, sqlDescs, err := loadBackupSQLDescs(
+		ctx, details, r.settings, encryption,
+	)
 	if err != nil {
 		return err
 	}
@@ -1722,6 +1763,7 @@ func (r *restoreResumer) Resume(
 		details.TableRewrites,
 		details.OverrideDB,
 		r.job,
+		encryption,
 		resultsCh,
 	)
 	r.res = res
@@ -0,0 +1,292 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// scheduledBackupExecutorType is the executor_type of backup schedules in
+// system.scheduled_jobs.
+const scheduledBackupExecutorType = "scheduled-backup"
+
+// scheduledBackupDirFormat is the layout of the name of the directory each
+// scheduled backup is written to, under the schedule's destination.
+const scheduledBackupDirFormat = "20060102-150405"
+
+// createScheduledBackupPlanHook implements PlanHookFn.
+func createScheduledBackupPlanHook(
+	_ context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, sqlbase.ResultColumns, []sql.PlanNode, bool, error) {
+	schedule, ok := stmt.(*tree.ScheduledBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	const op = "CREATE SCHEDULE FOR BACKUP"
+	nameFn := func() (string, error) {
+		return "BACKUP " + tree.AsString(&schedule.Targets), nil
+	}
+	if schedule.ScheduleName != nil {
+		var err error
+		if nameFn, err = p.TypeAsString(schedule.ScheduleName, op); err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+	toFn, err := p.TypeAsString(schedule.To, op)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	recurrenceFn, err := p.TypeAsString(schedule.Recurrence, op)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	fullRecurrenceFn := func() (string, error) { return "", nil }
+	if schedule.FullBackup != nil && !schedule.FullBackup.AlwaysFull {
+		if fullRecurrenceFn, err = p.TypeAsString(schedule.FullBackup.Recurrence, op); err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+	optsFn, err := p.TypeAsStringOpts(schedule.BackupOptions, backupOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	header := sqlbase.ResultColumns{
+		{Name: "schedule_id", Typ: types.Int},
+		{Name: "name", Typ: types.String},
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer tracing.FinishSpan(span)
+
+		if err := utilccl.CheckEnterpriseEnabled(
+			p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(), "BACKUP",
+		); err != nil {
+			return err
+		}
+
+		if err := p.RequireAdminRole(ctx, op); err != nil {
+			return err
+		}
+
+		name, err := nameFn()
+		if err != nil {
+			return err
+		}
+		to, err := toFn()
+		if err != nil {
+			return err
+		}
+		if _, err := url.Parse(to); err != nil {
+			return errors.Wrapf(err, "invalid destination %q", to)
+		}
+		recurrence, err := recurrenceFn()
+		if err != nil {
+			return err
+		}
+		if _, err := jobs.ParseCronExpr(recurrence); err != nil {
+			return err
+		}
+		fullRecurrence, err := fullRecurrenceFn()
+		if err != nil {
+			return err
+		}
+		if schedule.FullBackup != nil && !schedule.FullBackup.AlwaysFull {
+			if _, err := jobs.ParseCronExpr(fullRecurrence); err != nil {
+				return err
+			}
+		}
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+		if _, ok := opts[backupOptEncPassphrase]; ok {
+			// The schedule would store the passphrase in plaintext.
+			return errors.Errorf("%s does not support the %s option", op, backupOptEncPassphrase)
+		}
+
+		// The schedule stores the BACKUP statement with its arguments evaluated,
+		// as placeholders can't be resolved when the schedule runs.
+		backupStmt := &tree.Backup{
+			Targets: schedule.Targets,
+			To:      tree.PartitionedBackup{tree.NewStrVal(to)},
+		}
+		keys := make([]string, 0, len(opts))
+		for k := range opts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			opt := tree.KVOption{Key: tree.Name(k)}
+			if backupOptionExpectValues[k] != sql.KVStringOptRequireNoValue {
+				opt.Value = tree.NewStrVal(opts[k])
+			}
+			backupStmt.Options = append(backupStmt.Options, opt)
+		}
+
+		args, err := protoutil.Marshal(&ScheduledBackupExecutionArgs{
+			BackupStatement: tree.AsString(backupStmt),
+			FullBackupExpr:  fullRecurrence,
+		})
+		if err != nil {
+			return err
+		}
+		id, err := p.ExecCfg().JobRegistry.CreateSchedule(ctx, p.Txn(), jobs.ScheduleRecord{
+			Name:          name,
+			Owner:         p.User(),
+			ScheduleExpr:  recurrence,
+			ExecutorType:  scheduledBackupExecutorType,
+			ExecutionArgs: args,
+		})
+		if err != nil {
+			return err
+		}
+		resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(id)), tree.NewDString(name)}
+		return nil
+	}
+	return fn, header, nil, false, nil
+}
+
+// scheduledBackupExecutor runs backup schedules. Each run writes a backup to
+// a new directory under the schedule's destination. Unless the schedule only
+// takes full backups, a run is an incremental backup on top of the chain of
+// backups taken since the last full backup, until the schedule's full backup
+// expression calls for a new full backup. A run only creates the backup job;
+// its outcome is taken into account by the next run.
+type scheduledBackupExecutor struct{}
+
+var _ jobs.ScheduledJobExecutor = scheduledBackupExecutor{}
+
+// ExecuteJob implements the jobs.ScheduledJobExecutor interface.
+func (scheduledBackupExecutor) ExecuteJob(
+	ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn, schedule *jobs.ScheduledJob,
+) error {
+	var args ScheduledBackupExecutionArgs
+	if err := protoutil.Unmarshal(schedule.ExecutionArgs, &args); err != nil {
+		return err
+	}
+
+	// Add the backup of the previous run to the chain if it succeeded. A run
+	// while it's still in progress is skipped, as an incremental backup on top
+	// of it might be taken on top of a backup that ends up failing.
+	if args.PendingJobID != 0 {
+		row, err := ex.QueryRow(ctx, "scheduled-backup-status", txn,
+			`SELECT status FROM system.jobs WHERE id = $1`, args.PendingJobID)
+		if err != nil {
+			return err
+		}
+		var status jobs.Status
+		if row != nil {
+			status = jobs.Status(tree.MustBeDString(row[0]))
+		}
+		switch status {
+		case jobs.StatusSucceeded:
+			args.Chain = args.PendingChain
+			args.NextFullBackup = args.PendingNextFullBackup
+		case jobs.StatusPending, jobs.StatusRunning, jobs.StatusPaused:
+			log.Infof(ctx, "skipping run of schedule %d: backup job %d is %s",
+				schedule.ID, args.PendingJobID, status)
+			return nil
+		}
+		args.PendingJobID = 0
+		args.PendingChain = nil
+		args.PendingNextFullBackup = hlc.Timestamp{}
+	}
+
+	stmt, err := parser.ParseOne(args.BackupStatement)
+	if err != nil {
+		return err
+	}
+	backupStmt, ok := stmt.AST.(*tree.Backup)
+	if !ok || len(backupStmt.To) != 1 {
+		return errors.AssertionFailedf("unexpected statement for scheduled backup: %s", args.BackupStatement)
+	}
+	dest, ok := backupStmt.To[0].(*tree.StrVal)
+	if !ok {
+		return errors.AssertionFailedf("unexpected destination for scheduled backup: %s", args.BackupStatement)
+	}
+
+	full := args.FullBackupExpr == "" || len(args.Chain) == 0 ||
+		!schedule.ScheduledTime.Before(args.NextFullBackup.GoTime())
+	uri, err := scheduledBackupURI(dest.RawString(), schedule.ScheduledTime)
+	if err != nil {
+		return err
+	}
+	backupStmt.To = tree.PartitionedBackup{tree.NewStrVal(uri)}
+	if !full {
+		for _, prev := range args.Chain {
+			backupStmt.IncrementalFrom = append(backupStmt.IncrementalFrom, tree.NewStrVal(prev))
+		}
+	}
+	detached := false
+	for _, opt := range backupStmt.Options {
+		detached = detached || opt.Key == backupOptDetached
+	}
+	if !detached {
+		backupStmt.Options = append(backupStmt.Options, tree.KVOption{Key: backupOptDetached})
+	}
+	row, err := ex.QueryRow(ctx, "scheduled-backup", txn, tree.AsString(backupStmt))
+	if err != nil {
+		return errors.Wrapf(err, "backing up to %s", uri)
+	}
+
+	args.PendingJobID = int64(tree.MustBeDInt(row[0]))
+	if full {
+		args.PendingChain = []string{uri}
+		if args.FullBackupExpr != "" {
+			e, err := jobs.ParseCronExpr(args.FullBackupExpr)
+			if err != nil {
+				return err
+			}
+			args.PendingNextFullBackup = hlc.Timestamp{WallTime: e.Next(schedule.ScheduledTime).UnixNano()}
+		}
+	} else {
+		args.PendingChain = append(append([]string(nil), args.Chain...), uri)
+		args.PendingNextFullBackup = args.NextFullBackup
+	}
+	schedule.ExecutionArgs, err = protoutil.Marshal(&args)
+	return err
+}
+
+// scheduledBackupURI returns the URI of the directory under dest that the run
+// of a backup schedule scheduled at the given time writes to.
+func scheduledBackupURI(dest string, scheduled time.Time) (string, error) {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, scheduled.UTC().Format(scheduledBackupDirFormat))
+	return u.String(), nil
+}
+
+func init() {
+	sql.AddPlanHook(createScheduledBackupPlanHook)
+	jobs.RegisterScheduledJobExecutor(scheduledBackupExecutorType, scheduledBackupExecutor{})
+}
@@ -12,6 +12,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
@@ -21,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
 )
 
 // showBackupPlanHook implements PlanHookFn.
@@ -47,6 +49,39 @@ func showBackupPlanHook(
 		return nil, nil, nil, false, err
 	}
 
+	incrementalFromFn, err := p.TypeAsStringArray(backup.IncrementalFrom, "SHOW BACKUP")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	expected := map[string]sql.KVStringOptValidate{
+		backupOptEncPassphrase: sql.KVStringOptRequireValue,
+		backupOptEncKeyFile:    sql.KVStringOptRequireValue,
+		backupOptCheckFiles:    sql.KVStringOptRequireNoValue,
+	}
+	optsFn, err := p.TypeAsStringOpts(backup.Options, expected)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	// Whether the backup is to be verified, rather than its contents listed,
+	// is known before the options are evaluated, as check_files has no value.
+	checkFiles := false
+	for _, opt := range backup.Options {
+		if string(opt.Key) == backupOptCheckFiles {
+			checkFiles = true
+		}
+	}
+	if checkFiles {
+		if backup.Details != tree.BackupDefaultDetails || backup.ShouldIncludeSchemas {
+			return nil, nil, nil, false, errors.Newf(
+				"%s cannot be used with SHOW BACKUP SCHEMAS, FILES or RANGES", backupOptCheckFiles)
+		}
+	} else if len(backup.IncrementalFrom) > 0 {
+		return nil, nil, nil, false, errors.Newf(
+			"SHOW BACKUP of multiple backups requires the %s option", backupOptCheckFiles)
+	}
+
 	var shower backupShower
 	switch backup.Details {
 	case tree.BackupRangeDetails:
@@ -56,6 +91,10 @@ func showBackupPlanHook(
 	default:
 		shower = backupShowerDefault(ctx, backup.ShouldIncludeSchemas)
 	}
+	header := shower.header
+	if checkFiles {
+		header = verifyBackupHeader
+	}
 
 	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
 		// TODO(dan): Move this span into sql.
@@ -66,10 +105,51 @@ func showBackupPlanHook(
 		if err != nil {
 			return err
 		}
-		desc, err := ReadBackupDescriptorFromURI(ctx, str, p.ExecCfg().Settings)
+		incrementalFrom, err := incrementalFromFn()
+		if err != nil {
+			return err
+		}
+		opts, err := optsFn()
 		if err != nil {
 			return err
 		}
+		passphrase, err := getEncryptionPassphrase(ctx, opts, p.ExecCfg().Settings)
+		if err != nil {
+			return err
+		}
+		// Every backup in an incremental chain stores the salt of the full
+		// backup it is based on, so the key can be derived from any of them.
+		encryption, _, err := getEncryptionFromBase(ctx, str, passphrase, p.ExecCfg().Settings)
+		if err != nil {
+			return err
+		}
+		desc, err := ReadBackupDescriptorFromURI(ctx, str, p.ExecCfg().Settings, encryption)
+		if err != nil {
+			return err
+		}
+
+		rows := shower.fn
+		if checkFiles {
+			chain := make([]BackupDescriptor, 0, len(incrementalFrom)+1)
+			for i, uri := range incrementalFrom {
+				prev, err := ReadBackupDescriptorFromURI(ctx, uri, p.ExecCfg().Settings, encryption)
+				if err != nil {
+					return errors.Wrapf(err, "reading backup %d", i+1)
+				}
+				chain = append(chain, prev)
+			}
+			chain = append(chain, desc)
+			exportStore, err := storageccl.ExportStorageFromURI(ctx, str, p.ExecCfg().Settings)
+			if err != nil {
+				return err
+			}
+			defer exportStore.Close()
+			problems, err := verifyBackup(ctx, exportStore, chain, encryption)
+			if err != nil {
+				return err
+			}
+			rows = func(BackupDescriptor) []tree.Datums { return problems }
+		}
 		// If we are restoring a backup with old-style foreign keys, skip over the
 		// FKs for which we can't resolve the cross-table references. We can't
 		// display them anyway, because we don't have the referenced table names,
@@ -78,7 +158,7 @@ func showBackupPlanHook(
 			return err
 		}
 
-		for _, row := range shower.fn(desc) {
+		for _, row := range rows(desc) {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
@@ -88,7 +168,7 @@ func showBackupPlanHook(
 		return nil
 	}
 
-	return fn, shower.header, nil, false, nil
+	return fn, header, nil, false, nil
 }
 
 type backupShower struct {
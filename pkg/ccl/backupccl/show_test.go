@@ -11,6 +11,9 @@ package backupccl_test
 import (
 	"database/sql/driver"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
@@ -208,6 +211,69 @@ func TestShowBackup(t *testing.T) {
 	}
 }
 
+func TestShowBackupCheckFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 11
+	_, _, sqlDB, dir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	full, inc, inc2 := localFoo+"/full", localFoo+"/inc", localFoo+"/inc2"
+	sqlDB.Exec(t, `BACKUP data.bank TO $1`, full)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.Exec(t, `BACKUP data.bank TO $1 INCREMENTAL FROM $2`, inc, full)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.Exec(t, `BACKUP data.bank TO $1 INCREMENTAL FROM $2, $3`, inc2, full, inc)
+
+	const checkFiles = `SELECT problem FROM [SHOW BACKUP %s WITH check_files]`
+	if rows := sqlDB.QueryStr(t, fmt.Sprintf(checkFiles, `$1`), full); len(rows) != 0 {
+		t.Fatalf("expected no problems with the full backup, got %v", rows)
+	}
+	if rows := sqlDB.QueryStr(t, fmt.Sprintf(checkFiles, `$1, $2, $3`), full, inc, inc2); len(rows) != 0 {
+		t.Fatalf("expected no problems with the backup chain, got %v", rows)
+	}
+
+	// An incremental backup needs the backups it is incremental on, in order.
+	if rows := sqlDB.QueryStr(t, fmt.Sprintf(checkFiles, `$1`), inc); len(rows) != 1 ||
+		!strings.Contains(rows[0][0], "no backup it is incremental on was given") {
+		t.Fatalf("expected a missing full backup to be reported, got %v", rows)
+	}
+	if rows := sqlDB.QueryStr(t, fmt.Sprintf(checkFiles, `$1, $2`), full, inc2); len(rows) != 1 ||
+		!strings.Contains(rows[0][0], "backup 2 starts at") {
+		t.Fatalf("expected a gap in the backup chain to be reported, got %v", rows)
+	}
+
+	sqlDB.ExpectErr(t, "requires the check_files option", `SHOW BACKUP $1, $2`, full, inc)
+	sqlDB.ExpectErr(t, "cannot be used with SHOW BACKUP SCHEMAS",
+		`SHOW BACKUP SCHEMAS $1 WITH check_files`, full)
+
+	// Corrupt one file of the full backup and remove another.
+	paths := sqlDB.QueryStr(t, `SELECT path FROM [SHOW BACKUP FILES $1]`, full)
+	if len(paths) < 2 {
+		t.Fatalf("expected at least 2 files, got %d", len(paths))
+	}
+	corrupt, missing := paths[0][0], paths[1][0]
+	if err := ioutil.WriteFile(filepath.Join(dir, "foo", "full", corrupt), []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "foo", "full", missing)); err != nil {
+		t.Fatal(err)
+	}
+	problems := sqlDB.QueryStr(t, `SELECT path, problem FROM [SHOW BACKUP $1 WITH check_files]`, full)
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got %v", problems)
+	}
+	for _, expected := range [][]string{{corrupt, "checksum mismatch"}, {missing, "reading file"}} {
+		found := false
+		for _, row := range problems {
+			found = found || (row[0] == expected[0] && strings.Contains(row[1], expected[1]))
+		}
+		if !found {
+			t.Errorf("expected problem %q with %s, got %v", expected[1], expected[0], problems)
+		}
+	}
+}
+
 func eqWhitespace(a, b string) bool {
 	return strings.Replace(a, "\t", "", -1) == strings.Replace(b, "\t", "", -1)
 }
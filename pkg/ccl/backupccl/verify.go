@@ -0,0 +1,217 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/interval"
+	"github.com/cockroachdb/errors"
+)
+
+const backupOptCheckFiles = "check_files"
+
+var verifyBackupHeader = sqlbase.ResultColumns{
+	{Name: "path", Typ: types.String},
+	{Name: "problem", Typ: types.String},
+}
+
+// backupVerifier collects the problems found while verifying a backup. A
+// problem with a file is reported with the file's path, while a problem with
+// the backup as a whole has a NULL path.
+type backupVerifier struct {
+	rows []tree.Datums
+}
+
+func (v *backupVerifier) addf(path string, format string, args ...interface{}) {
+	pathDatum := tree.DNull
+	if path != "" {
+		pathDatum = tree.NewDString(path)
+	}
+	v.rows = append(v.rows, tree.Datums{pathDatum, tree.NewDString(fmt.Sprintf(format, args...))})
+}
+
+// verifyBackup checks that the last of the given backups could be restored,
+// on top of the backups preceding it, without restoring it. It checks that the
+// backups form a chain of incremental backups on top of a full backup and that
+// each backup covers the key spans it needs to, and reads every file of the
+// last backup to verify its checksum and that its keys lie within the span
+// and time interval it claims to cover. A backup that verifies without
+// problems results in no rows.
+//
+// Files of a partitioned backup that were written to the storage of a
+// locality, rather than the default location, are not checked.
+func verifyBackup(
+	ctx context.Context,
+	exportStore storageccl.ExportStorage,
+	chain []BackupDescriptor,
+	encryption *roachpb.FileEncryptionOptions,
+) ([]tree.Datums, error) {
+	var v backupVerifier
+	verifyBackupChain(&v, chain)
+
+	desc := chain[len(chain)-1]
+	verifyBackupFileSpans(&v, desc)
+	for _, file := range desc.Files {
+		if file.Path == "" || file.LocalityKV != "" {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		start, end := desc.StartTime, desc.EndTime
+		if !file.EndTime.IsEmpty() {
+			start, end = file.StartTime, file.EndTime
+		}
+		if err := verifyBackupFile(ctx, exportStore, file, start, end, encryption); err != nil {
+			v.addf(file.Path, "%v", err)
+		}
+	}
+	return v.rows, nil
+}
+
+// verifyBackupChain checks the timestamps and spans of a chain of backups,
+// listed from the full backup to the latest incremental one.
+func verifyBackupChain(v *backupVerifier, chain []BackupDescriptor) {
+	for i, desc := range chain {
+		if desc.EndTime.IsEmpty() {
+			v.addf("", "backup %d has no end time", i+1)
+		}
+		introduced := spanGroup(desc.IntroducedSpans)
+		spans := spanGroup(desc.Spans)
+		for _, span := range desc.IntroducedSpans {
+			if !spans.Encloses(spanRange(span)) {
+				v.addf("", "backup %d introduces span %s which it does not back up", i+1, span)
+			}
+		}
+		if i == 0 {
+			if !desc.StartTime.IsEmpty() {
+				v.addf("", "backup 1 is incremental from %s, but no backup it is incremental on was given",
+					desc.StartTime)
+			}
+			continue
+		}
+		prev := chain[i-1]
+		if desc.StartTime != prev.EndTime {
+			v.addf("", "backup %d starts at %s, but backup %d ends at %s",
+				i+1, desc.StartTime, i, prev.EndTime)
+		}
+		// Spans that weren't backed up by the previous backup must have been
+		// backed up in full, rather than incrementally.
+		prevSpans := spanGroup(prev.Spans)
+		for _, span := range desc.Spans {
+			r := spanRange(span)
+			if !prevSpans.Encloses(r) && !introduced.Encloses(r) {
+				v.addf("", "backup %d backs up span %s incrementally, but backup %d does not cover it",
+					i+1, span, i)
+			}
+		}
+	}
+}
+
+// verifyBackupFileSpans checks that the files of a backup don't overlap and
+// lie within the spans of the backup.
+func verifyBackupFileSpans(v *backupVerifier, desc BackupDescriptor) {
+	spans := spanGroup(desc.Spans)
+	files := make([]BackupDescriptor_File, len(desc.Files))
+	copy(files, desc.Files)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Span.Key.Compare(files[j].Span.Key) < 0
+	})
+	for i, file := range files {
+		if !spans.Encloses(spanRange(file.Span)) {
+			v.addf(file.Path, "file span %s is not within the spans of the backup", file.Span)
+		}
+		if i > 0 && files[i-1].Span.Overlaps(file.Span) {
+			v.addf(file.Path, "file span %s overlaps the span %s of file %s",
+				file.Span, files[i-1].Span, files[i-1].Path)
+		}
+	}
+}
+
+// verifyBackupFile reads a file of a backup, verifying its checksum and that
+// it contains only keys in its span, at timestamps in (start, end].
+func verifyBackupFile(
+	ctx context.Context,
+	exportStore storageccl.ExportStorage,
+	file BackupDescriptor_File,
+	start, end hlc.Timestamp,
+	encryption *roachpb.FileEncryptionOptions,
+) error {
+	r, err := exportStore.ReadFile(ctx, file.Path)
+	if err != nil {
+		return errors.Wrap(err, "reading file")
+	}
+	defer r.Close()
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return errors.Wrap(err, "reading file")
+	}
+	if len(file.Sha512) > 0 {
+		checksum, err := storageccl.SHA512ChecksumData(contents)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(checksum, file.Sha512) {
+			return errors.New("checksum mismatch")
+		}
+	}
+	if encryption != nil {
+		if contents, err = storageccl.DecryptFile(contents, encryption.Key); err != nil {
+			return errors.Wrap(err, "decrypting file")
+		}
+	}
+
+	iter, err := engine.NewMemSSTIterator(contents, true /* verify */)
+	if err != nil {
+		return errors.Wrap(err, "reading SST")
+	}
+	defer iter.Close()
+	for iter.Seek(engine.NilKey); ; iter.Next() {
+		ok, err := iter.Valid()
+		if err != nil {
+			return errors.Wrap(err, "reading SST")
+		}
+		if !ok {
+			return nil
+		}
+		key := iter.UnsafeKey()
+		if !file.Span.ContainsKey(key.Key) {
+			return errors.Errorf("key %s is outside of the file span %s", key.Key, file.Span)
+		}
+		if end.Less(key.Timestamp) || (!start.IsEmpty() && !start.Less(key.Timestamp)) {
+			return errors.Errorf("key %s is outside of the backup's time interval (%s, %s]", key, start, end)
+		}
+	}
+}
+
+func spanRange(span roachpb.Span) interval.Range {
+	return interval.Range{Start: interval.Comparable(span.Key), End: interval.Comparable(span.EndKey)}
+}
+
+func spanGroup(spans []roachpb.Span) interval.RangeGroup {
+	g := interval.NewRangeTree()
+	for _, span := range spans {
+		g.Add(spanRange(span))
+	}
+	return g
+}
@@ -9,6 +9,8 @@
 package changefeedccl
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -20,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/linkedin/goavro"
 	"github.com/pkg/errors"
 )
@@ -124,8 +127,8 @@ type avroMetadata map[string]interface{}
 
 // avroEnvelopeOpts controls which fields in avroEnvelopeRecord are set.
 type avroEnvelopeOpts struct {
-	updatedField, resolvedField bool
-	afterField                  bool
+	updatedField, resolvedField       bool
+	keyField, beforeField, afterField bool
 }
 
 // avroEnvelopeRecord is an `avroRecord` that wraps a changed SQL row and some
@@ -133,8 +136,8 @@ type avroEnvelopeOpts struct {
 type avroEnvelopeRecord struct {
 	avroRecord
 
-	opts  avroEnvelopeOpts
-	after *avroDataRecord
+	opts               avroEnvelopeOpts
+	key, before, after *avroDataRecord
 }
 
 // columnDescToAvroSchema converts a column descriptor into its corresponding
@@ -489,7 +492,7 @@ func (r *avroDataRecord) rowFromNative(native interface{}) (sqlbase.EncDatumRow,
 // envelopeToAvroSchema creates an avro record schema for an envelope containing
 // before and after versions of a row change and metadata about that row change.
 func envelopeToAvroSchema(
-	topic string, opts avroEnvelopeOpts, after *avroDataRecord,
+	topic string, opts avroEnvelopeOpts, key, before, after *avroDataRecord,
 ) (*avroEnvelopeRecord, error) {
 	schema := &avroEnvelopeRecord{
 		avroRecord: avroRecord{
@@ -515,6 +518,24 @@ func envelopeToAvroSchema(
 		}
 		schema.Fields = append(schema.Fields, resolvedField)
 	}
+	if opts.keyField {
+		schema.key = key
+		keyField := &avroSchemaField{
+			Name:       `key`,
+			SchemaType: []avroSchemaType{avroSchemaNull, key},
+			Default:    nil,
+		}
+		schema.Fields = append(schema.Fields, keyField)
+	}
+	if opts.beforeField {
+		schema.before = before
+		beforeField := &avroSchemaField{
+			Name:       `before`,
+			SchemaType: []avroSchemaType{avroSchemaNull, before},
+			Default:    nil,
+		}
+		schema.Fields = append(schema.Fields, beforeField)
+	}
 	if opts.afterField {
 		schema.after = after
 		afterField := &avroSchemaField{
@@ -566,6 +587,38 @@ func (r *avroEnvelopeRecord) BinaryFromRow(
 			native[`resolved`] = goavro.Union(avroUnionKey(avroSchemaString), ts.AsOfSystemTime())
 		}
 	}
+	if r.opts.keyField {
+		k, ok := meta[`key`]
+		if !ok {
+			return nil, errors.New(`missing key metadata`)
+		}
+		delete(meta, `key`)
+		keyRow, ok := k.(sqlbase.EncDatumRow)
+		if !ok {
+			return nil, errors.Errorf(`unknown metadata key type: %T`, k)
+		}
+		keyNative, err := r.key.nativeFromRow(keyRow)
+		if err != nil {
+			return nil, err
+		}
+		native[`key`] = goavro.Union(avroUnionKey(&r.key.avroRecord), keyNative)
+	}
+	if r.opts.beforeField {
+		// The previous value of the row is only in the metadata if it existed.
+		native[`before`] = nil
+		if b, ok := meta[`before`]; ok {
+			delete(meta, `before`)
+			beforeRow, ok := b.(sqlbase.EncDatumRow)
+			if !ok {
+				return nil, errors.Errorf(`unknown metadata before type: %T`, b)
+			}
+			beforeNative, err := r.before.nativeFromRow(beforeRow)
+			if err != nil {
+				return nil, err
+			}
+			native[`before`] = goavro.Union(avroUnionKey(&r.before.avroRecord), beforeNative)
+		}
+	}
 	// WIP verify that meta is now empty
 	if r.opts.afterField {
 		if row == nil {
@@ -620,3 +673,95 @@ func ratToDecimal(rat big.Rat, scale int32) apd.Decimal {
 	dec := apd.NewWithBigInt(coeff, -scale)
 	return *dec
 }
+
+// avroOCFSchema creates the avro record schema of the rows written to avro
+// object container files. It's an envelope containing the primary key of the
+// changed row, its new value (null for deletions) and, optionally, the updated
+// timestamp.
+func avroOCFSchema(
+	tableDesc *sqlbase.TableDescriptor, updatedField bool,
+) (*avroEnvelopeRecord, error) {
+	key, err := indexToAvroSchema(tableDesc, &tableDesc.PrimaryIndex)
+	if err != nil {
+		return nil, err
+	}
+	// The key and value records are both named after the table, but names have
+	// to be unique within a schema. The key is only ever encoded as part of the
+	// envelope, so its own codec doesn't need to be rebuilt.
+	key.Name += `_key`
+	after, err := tableToAvroSchema(tableDesc)
+	if err != nil {
+		return nil, err
+	}
+	opts := avroEnvelopeOpts{keyField: true, afterField: true, updatedField: updatedField}
+	return envelopeToAvroSchema(tableDesc.Name, opts, key, nil /* before */, after)
+}
+
+// avroOCFFileWriter accumulates records in avro's binary format and writes
+// them out as an avro object container file, which embeds the schema of its
+// records. All the records are written in one uncompressed block.
+//
+// https://avro.apache.org/docs/1.8.2/spec.html#Object+Container+Files
+type avroOCFFileWriter struct {
+	schemaJSON []byte
+	records    bytes.Buffer
+	count      int64
+}
+
+var _ cloudStorageFileWriter = &avroOCFFileWriter{}
+
+func makeAvroOCFFileWriter(schema *avroEnvelopeRecord) (*avroOCFFileWriter, error) {
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &avroOCFFileWriter{schemaJSON: schemaJSON}, nil
+}
+
+// AddRecord implements the cloudStorageFileWriter interface.
+func (w *avroOCFFileWriter) AddRecord(record []byte) error {
+	w.records.Write(record)
+	w.count++
+	return nil
+}
+
+// Len implements the cloudStorageFileWriter interface.
+func (w *avroOCFFileWriter) Len() int {
+	return w.records.Len()
+}
+
+// Contents implements the cloudStorageFileWriter interface.
+func (w *avroOCFFileWriter) Contents() ([]byte, error) {
+	sync := uuid.MakeV4().GetBytes()
+	buf := make([]byte, 0, len(w.schemaJSON)+w.records.Len()+128)
+	buf = append(buf, 'O', 'b', 'j', 1)
+	// The file metadata is a map from string to bytes, encoded as a single block
+	// of entries followed by an empty block.
+	buf = appendAvroLong(buf, 2)
+	buf = appendAvroBytes(buf, []byte(`avro.schema`))
+	buf = appendAvroBytes(buf, w.schemaJSON)
+	buf = appendAvroBytes(buf, []byte(`avro.codec`))
+	buf = appendAvroBytes(buf, []byte(`null`))
+	buf = appendAvroLong(buf, 0)
+	buf = append(buf, sync...)
+
+	buf = appendAvroLong(buf, w.count)
+	buf = appendAvroLong(buf, int64(w.records.Len()))
+	buf = append(buf, w.records.Bytes()...)
+	buf = append(buf, sync...)
+	return buf, nil
+}
+
+// appendAvroLong appends the avro binary encoding of a long, which is a zigzag
+// encoded varint.
+func appendAvroLong(buf []byte, v int64) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(scratch[:], v)
+	return append(buf, scratch[:n]...)
+}
+
+// appendAvroBytes appends the avro binary encoding of bytes or a string.
+func appendAvroBytes(buf []byte, v []byte) []byte {
+	buf = appendAvroLong(buf, int64(len(v)))
+	return append(buf, v...)
+}
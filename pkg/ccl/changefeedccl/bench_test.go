@@ -218,7 +218,8 @@ func createBenchmarkChangefeed(
 	}
 	rowsFn := kvsToRows(s.LeaseManager().(*sql.LeaseManager), details, buf.Get)
 	tickFn := emitEntries(
-		s.ClusterSettings(), details, spans, encoder, sink, rowsFn, TestingKnobs{}, metrics)
+		s.ClusterSettings(), details, spans, encoder, sink, nil /* commitFn */, rowsFn, TestingKnobs{},
+		metrics)
 
 	ctx, cancel := context.WithCancel(ctx)
 	go func() { _ = poller.RunUsingRangefeeds(ctx) }()
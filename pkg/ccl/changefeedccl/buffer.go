@@ -26,7 +26,13 @@ import (
 )
 
 type bufferEntry struct {
-	kv       roachpb.KeyValue
+	kv roachpb.KeyValue
+	// prevVal is the value of kv's key before the change. It's only populated
+	// when the changefeed was created with the diff option. An empty value
+	// means that the key didn't exist. If its timestamp is set, it's the
+	// timestamp of the schema that should be used to read it, otherwise the
+	// schema used to read kv is used.
+	prevVal  roachpb.Value
 	resolved *jobspb.ResolvedSpan
 	// Timestamp of the schema that should be used to read this KV.
 	// If unset (zero-valued), the value's timestamp will be used instead.
@@ -48,9 +54,9 @@ func makeBuffer() *buffer {
 // AddKV inserts a changed kv into the buffer. Individual keys must be added in
 // increasing mvcc order.
 func (b *buffer) AddKV(
-	ctx context.Context, kv roachpb.KeyValue, schemaTimestamp hlc.Timestamp,
+	ctx context.Context, kv roachpb.KeyValue, prevVal roachpb.Value, schemaTimestamp hlc.Timestamp,
 ) error {
-	return b.addEntry(ctx, bufferEntry{kv: kv, schemaTimestamp: schemaTimestamp})
+	return b.addEntry(ctx, bufferEntry{kv: kv, prevVal: prevVal, schemaTimestamp: schemaTimestamp})
 }
 
 // AddResolved inserts a resolved timestamp notification in the buffer.
@@ -96,6 +102,9 @@ var memBufferColTypes = []types.T{
 	*types.Int,   // ts.Logical
 	*types.Int,   // schemaTimestamp.WallTime
 	*types.Int,   // schemaTimestamp.Logical
+	*types.Bytes, // prevVal.RawBytes
+	*types.Int,   // prevVal.Timestamp.WallTime
+	*types.Int,   // prevVal.Timestamp.Logical
 }
 
 // memBuffer is an in-memory buffer for changed KV and resolved timestamp
@@ -136,9 +145,13 @@ func (b *memBuffer) Close(ctx context.Context) {
 // AddKV inserts a changed kv into the buffer. Individual keys must be added in
 // increasing mvcc order.
 func (b *memBuffer) AddKV(
-	ctx context.Context, kv roachpb.KeyValue, schemaTimestamp hlc.Timestamp,
+	ctx context.Context, kv roachpb.KeyValue, prevVal roachpb.Value, schemaTimestamp hlc.Timestamp,
 ) error {
 	b.allocMu.Lock()
+	prevValDatum := tree.DNull
+	if prevVal.RawBytes != nil {
+		prevValDatum = b.allocMu.a.NewDBytes(tree.DBytes(prevVal.RawBytes))
+	}
 	row := tree.Datums{
 		b.allocMu.a.NewDBytes(tree.DBytes(kv.Key)),
 		b.allocMu.a.NewDBytes(tree.DBytes(kv.Value.RawBytes)),
@@ -148,6 +161,9 @@ func (b *memBuffer) AddKV(
 		b.allocMu.a.NewDInt(tree.DInt(kv.Value.Timestamp.Logical)),
 		b.allocMu.a.NewDInt(tree.DInt(schemaTimestamp.WallTime)),
 		b.allocMu.a.NewDInt(tree.DInt(schemaTimestamp.Logical)),
+		prevValDatum,
+		b.allocMu.a.NewDInt(tree.DInt(prevVal.Timestamp.WallTime)),
+		b.allocMu.a.NewDInt(tree.DInt(prevVal.Timestamp.Logical)),
 	}
 	b.allocMu.Unlock()
 	return b.addRow(ctx, row)
@@ -165,6 +181,9 @@ func (b *memBuffer) AddResolved(ctx context.Context, span roachpb.Span, ts hlc.T
 		b.allocMu.a.NewDInt(tree.DInt(ts.Logical)),
 		tree.DNull,
 		tree.DNull,
+		tree.DNull,
+		tree.DNull,
+		tree.DNull,
 	}
 	b.allocMu.Unlock()
 	return b.addRow(ctx, row)
@@ -194,6 +213,15 @@ func (b *memBuffer) Get(ctx context.Context) (bufferEntry, error) {
 			WallTime: int64(*row[6].(*tree.DInt)),
 			Logical:  int32(*row[7].(*tree.DInt)),
 		}
+		if row[8] != tree.DNull {
+			e.prevVal = roachpb.Value{
+				RawBytes: []byte(*row[8].(*tree.DBytes)),
+				Timestamp: hlc.Timestamp{
+					WallTime: int64(*row[9].(*tree.DInt)),
+					Logical:  int32(*row[10].(*tree.DInt)),
+				},
+			}
+		}
 		return e, nil
 	}
 	e.resolved = &jobspb.ResolvedSpan{
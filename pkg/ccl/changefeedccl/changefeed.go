@@ -62,11 +62,12 @@ func kvsToRows(
 	inputFn func(context.Context) (bufferEntry, error),
 ) func(context.Context) ([]emitEntry, error) {
 	rfCache := newRowFetcherCache(leaseMgr)
+	_, withDiff := details.Opts[optDiff]
 
 	var kvs row.SpanKVFetcher
 	appendEmitEntryForKV := func(
 		ctx context.Context, output []emitEntry, kv roachpb.KeyValue, schemaTimestamp hlc.Timestamp,
-		bufferGetTimestamp time.Time,
+		prevVal roachpb.Value, bufferGetTimestamp time.Time,
 	) ([]emitEntry, error) {
 		// Reuse kvs to save allocations.
 		kvs.KVs = kvs.KVs[:0]
@@ -93,6 +94,7 @@ func kvsToRows(
 			return nil, err
 		}
 
+		rowsStart := len(output)
 		for {
 			var r emitEntry
 			r.bufferGetTimestamp = bufferGetTimestamp
@@ -108,6 +110,44 @@ func kvsToRows(
 			r.row.updated = schemaTimestamp
 			output = append(output, r)
 		}
+		if !withDiff || len(output) == rowsStart {
+			return output, nil
+		}
+
+		// The previous value is read with the schema it was buffered with, if
+		// any, which is the case for the scans done for backfills. Otherwise, it's
+		// read with the same schema as the new value.
+		prevSchemaTimestamp := schemaTimestamp
+		if prevVal.Timestamp != (hlc.Timestamp{}) {
+			prevSchemaTimestamp = prevVal.Timestamp
+		}
+		prevDesc, err := rfCache.TableDescForKey(ctx, kv.Key, prevSchemaTimestamp)
+		if err != nil {
+			return nil, err
+		}
+		prevRF, err := rfCache.RowFetcherForTableDesc(prevDesc)
+		if err != nil {
+			return nil, err
+		}
+		kvs.KVs = kvs.KVs[:0]
+		kvs.KVs = append(kvs.KVs, roachpb.KeyValue{Key: kv.Key, Value: prevVal})
+		if err := prevRF.StartScanFrom(ctx, &kvs); err != nil {
+			return nil, err
+		}
+		prevDatums, prevTableDesc, _, err := prevRF.NextRow(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// A missing previous value decodes as a deletion.
+		prevDeleted := prevDatums == nil || prevRF.RowIsDeleted()
+		for i := rowsStart; i < len(output); i++ {
+			r := &output[i].row
+			r.prevDeleted = prevDeleted
+			if !prevDeleted {
+				r.prevDatums = append(sqlbase.EncDatumRow(nil), prevDatums...)
+				r.prevTableDesc = prevTableDesc
+			}
+		}
 		return output, nil
 	}
 
@@ -129,7 +169,7 @@ func kvsToRows(
 					schemaTimestamp = input.schemaTimestamp
 				}
 				output, err = appendEmitEntryForKV(
-					ctx, output, input.kv, schemaTimestamp, input.bufferGetTimestamp)
+					ctx, output, input.kv, schemaTimestamp, input.prevVal, input.bufferGetTimestamp)
 				if err != nil {
 					return nil, err
 				}
@@ -150,13 +190,16 @@ func kvsToRows(
 // emitEntries connects to a sink, receives rows from a closure, and repeatedly
 // emits them to the sink. It returns a closure that may be repeatedly called to
 // advance the changefeed and which returns span-level resolved timestamp
-// updates. The returned closure is not threadsafe.
+// updates. The returned closure is not threadsafe. If commitFn is non-nil, it
+// is used in place of flushing the sink and its returned span-level resolved
+// timestamps in place of the ones received from the closure.
 func emitEntries(
 	settings *cluster.Settings,
 	details jobspb.ChangefeedDetails,
 	watchedSpans []roachpb.Span,
 	encoder Encoder,
 	sink Sink,
+	commitFn func(context.Context, hlc.Timestamp) ([]jobspb.ResolvedSpan, error),
 	inputFn func(context.Context) ([]emitEntry, error),
 	knobs TestingKnobs,
 	metrics *Metrics,
@@ -256,6 +299,19 @@ func emitEntries(
 			return nil, nil
 		}
 
+		// A sink with exactly-once delivery only commits the rows at or below
+		// the resolved timestamp of every watched span, and returns the spans it
+		// committed, which are forwarded instead.
+		if commitFn != nil {
+			resolvedSpans = resolvedSpans[:0]
+			committed, err := commitFn(ctx, watchedSF.Frontier())
+			if err != nil {
+				return nil, MarkRetryableError(err)
+			}
+			lastFlush = timeutil.Now()
+			return committed, nil
+		}
+
 		// Make sure to flush the sink before forwarding resolved spans,
 		// otherwise, we could lose buffered messages and violate the
 		// at-least-once guarantee. This is also true for checkpointing the
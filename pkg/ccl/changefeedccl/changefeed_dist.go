@@ -13,6 +13,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlplan"
@@ -105,16 +106,48 @@ func distChangefeedFlow(
 		}
 	}
 
+	// A kafka sink with exactly-once delivery may have committed the rows of
+	// some spans past the high-water. Those spans are watched from the
+	// timestamp their rows were committed through instead.
+	var committed *spanFrontier
+	if cfProgress := progress.GetChangefeed(); cfProgress != nil && len(cfProgress.KafkaTransactions) > 0 {
+		committed = makeSpanFrontier(trackedSpans...)
+		for _, txn := range cfProgress.KafkaTransactions {
+			for _, resolved := range txn.ResolvedSpans {
+				committed.Forward(resolved.Span, resolved.Timestamp)
+			}
+		}
+	}
+
 	changeAggregatorProcs := make([]distsqlplan.Processor, 0, len(spanPartitions))
 	for _, sp := range spanPartitions {
 		// TODO(dan): Merge these watches with the span-level resolved
 		// timestamps from the job progress.
-		watches := make([]distsqlpb.ChangeAggregatorSpec_Watch, len(sp.Spans))
-		for i, nodeSpan := range sp.Spans {
-			watches[i] = distsqlpb.ChangeAggregatorSpec_Watch{
-				Span:            nodeSpan,
-				InitialResolved: initialHighWater,
+		watches := make([]distsqlpb.ChangeAggregatorSpec_Watch, 0, len(sp.Spans))
+		for _, nodeSpan := range sp.Spans {
+			if committed == nil {
+				watches = append(watches, distsqlpb.ChangeAggregatorSpec_Watch{
+					Span:            nodeSpan,
+					InitialResolved: initialHighWater,
+				})
+				continue
 			}
+			committed.Entries(func(span roachpb.Span, ts hlc.Timestamp) {
+				if !span.Overlaps(nodeSpan) {
+					return
+				}
+				if span.Key.Compare(nodeSpan.Key) < 0 {
+					span.Key = nodeSpan.Key
+				}
+				if nodeSpan.EndKey.Compare(span.EndKey) < 0 {
+					span.EndKey = nodeSpan.EndKey
+				}
+				ts.Forward(initialHighWater)
+				watches = append(watches, distsqlpb.ChangeAggregatorSpec_Watch{
+					Span:            span,
+					InitialResolved: ts,
+				})
+			})
 		}
 
 		changeAggregatorProcs = append(changeAggregatorProcs, distsqlplan.Processor{
@@ -124,6 +157,7 @@ func distChangefeedFlow(
 					ChangeAggregator: &distsqlpb.ChangeAggregatorSpec{
 						Watches: watches,
 						Feed:    details,
+						JobID:   jobID,
 					},
 				},
 				Output: []distsqlpb.OutputRouterSpec{{Type: distsqlpb.OutputRouterSpec_PASS_THROUGH}},
@@ -52,6 +52,10 @@ type changeAggregator struct {
 
 	// encoder is the Encoder to use for key and value serialization.
 	encoder Encoder
+	// evaluator, if non-nil, applies the projection and filter of a changefeed
+	// created with CREATE CHANGEFEED ... AS SELECT to the changed rows before
+	// they're encoded.
+	evaluator *rowEvaluator
 	// sink is the Sink to write rows to. Resolved timestamps are never written
 	// by changeAggregator.
 	sink Sink
@@ -106,6 +110,11 @@ func newChangeAggregatorProcessor(
 	if ca.encoder, err = getEncoder(ca.spec.Feed.Opts); err != nil {
 		return nil, err
 	}
+	if ca.spec.Feed.Select != `` {
+		if ca.evaluator, err = newRowEvaluator(ca.spec.Feed.Select, flowCtx.NewEvalCtx()); err != nil {
+			return nil, err
+		}
+	}
 
 	return ca, nil
 }
@@ -138,6 +147,17 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 	if b, ok := ca.sink.(*bufferSink); ok {
 		ca.changedRowBuf = &b.buf
 	}
+	// A kafka sink with exactly-once delivery holds rows until they're resolved
+	// and commits them itself, recording what it committed in the job.
+	txnSink, _ := ca.sink.(*kafkaTxnSink)
+	if txnSink != nil {
+		if err := ca.beginKafkaTxns(ctx, txnSink); err != nil {
+			err = MarkRetryableError(err)
+			ca.MoveToDraining(err)
+			ca.cancel()
+			return ctx
+		}
+	}
 
 	initialHighWater := hlc.Timestamp{WallTime: -1}
 	var spans []roachpb.Span
@@ -178,10 +198,20 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 		ca.flowCtx.Cfg.Settings, ca.flowCtx.Cfg.DB, ca.flowCtx.Cfg.DB.Clock(), ca.flowCtx.Cfg.Gossip,
 		spans, ca.spec.Feed, initialHighWater, buf, leaseMgr, metrics, ca.pollerMemMon,
 	)
-	rowsFn := kvsToRows(leaseMgr, ca.spec.Feed, buf.Get)
+	inputFn := buf.Get
+	var commitFn func(context.Context, hlc.Timestamp) ([]jobspb.ResolvedSpan, error)
+	if txnSink != nil {
+		inputFn = skipCommittedKVs(ca.spec.Watches, inputFn)
+		commitFn = txnSink.commitResolved
+	}
+	rowsFn := kvsToRows(leaseMgr, ca.spec.Feed, inputFn)
+	if ca.evaluator != nil {
+		rowsFn = ca.evaluator.evalRows(rowsFn)
+	}
 
 	ca.tickFn = emitEntries(
-		ca.flowCtx.Cfg.Settings, ca.spec.Feed, spans, ca.encoder, ca.sink, rowsFn, knobs, metrics)
+		ca.flowCtx.Cfg.Settings, ca.spec.Feed, spans, ca.encoder, ca.sink, commitFn, rowsFn, knobs,
+		metrics)
 
 	// Give errCh enough buffer both possible errors from supporting goroutines,
 	// but only the first one is ever used.
@@ -208,6 +238,23 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 	return ctx
 }
 
+// beginKafkaTxns starts the producer of a kafka sink with exactly-once
+// delivery. Each node's changeAggregator uses its own transactional id.
+func (ca *changeAggregator) beginKafkaTxns(ctx context.Context, txnSink *kafkaTxnSink) error {
+	job, err := ca.flowCtx.Cfg.JobRegistry.LoadJob(ctx, ca.spec.JobID)
+	if err != nil {
+		return err
+	}
+	resolved := make([]jobspb.ResolvedSpan, len(ca.spec.Watches))
+	for i, watch := range ca.spec.Watches {
+		resolved[i] = jobspb.ResolvedSpan{Span: watch.Span, Timestamp: watch.InitialResolved}
+	}
+	txnID := fmt.Sprintf(`cockroach-%s-changefeed-%d-%d`,
+		ca.flowCtx.EvalCtx.ClusterID, ca.spec.JobID, ca.flowCtx.EvalCtx.NodeID)
+	txnSink.memAcc = &ca.memAcc
+	return txnSink.begin(ctx, txnID, resolved, kafkaTxnCheckpointer(job))
+}
+
 // close has two purposes: to synchronize on the completion of the helper
 // goroutines created by the Start method, and to clean up any resources used by
 // the processor. Due to the fact that this method may be called even if the
@@ -332,6 +379,10 @@ type changeFrontier struct {
 	// jobProgressedFn, if non-nil, is called to checkpoint the changefeed's
 	// progress in the corresponding system job entry.
 	jobProgressedFn func(context.Context, jobs.HighWaterProgressedFn) error
+	// releaseProtectedTSFn, if non-nil, is called to release the protected
+	// timestamp held by the job for its initial scan once the high-water
+	// reaches the statement time.
+	releaseProtectedTSFn func(context.Context) error
 	// highWaterAtStart is the greater of the job high-water and the timestamp the
 	// CHANGEFEED statement was run at. It's used in an assertion that we never
 	// regress the job high-water.
@@ -432,6 +483,17 @@ func (cf *changeFrontier) Start(ctx context.Context) context.Context {
 	if b, ok := cf.sink.(*bufferSink); ok {
 		cf.resolvedBuf = &b.buf
 	}
+	if txnSink, ok := cf.sink.(*kafkaTxnSink); ok {
+		// The resolved timestamps are committed as they're emitted, so there's
+		// no producer state to record in the job.
+		txnID := fmt.Sprintf(`cockroach-%s-changefeed-%d-resolved`,
+			cf.flowCtx.EvalCtx.ClusterID, cf.spec.JobID)
+		if err := txnSink.begin(ctx, txnID, nil /* resolved */, nil /* checkpointFn */); err != nil {
+			err = MarkRetryableError(err)
+			cf.MoveToDraining(err)
+			return ctx
+		}
+	}
 
 	// The job registry has a set of metrics used to monitor the various jobs it
 	// runs. They're all stored as the `metric.Struct` interface because of
@@ -448,6 +510,9 @@ func (cf *changeFrontier) Start(ctx context.Context) context.Context {
 			return ctx
 		}
 		cf.jobProgressedFn = job.HighWaterProgressed
+		if job.Payload().ProtectedTimestampRecord != nil {
+			cf.releaseProtectedTSFn = job.ReleaseProtectedTimestamp
+		}
 
 		p := job.Progress()
 		if ts := p.GetHighWater(); ts != nil {
@@ -577,6 +642,12 @@ func (cf *changeFrontier) noteResolvedSpan(d sqlbase.EncDatum) error {
 		if err := checkpointResolvedTimestamp(cf.Ctx, cf.jobProgressedFn, cf.sf); err != nil {
 			return err
 		}
+		if cf.releaseProtectedTSFn != nil && !newResolved.Less(cf.spec.Feed.StatementTime) {
+			if err := cf.releaseProtectedTSFn(cf.Ctx); err != nil {
+				return err
+			}
+			cf.releaseProtectedTSFn = nil
+		}
 		sinceEmitted := newResolved.GoTime().Sub(cf.lastEmitResolved)
 		if cf.freqEmitResolved != emitNoResolved && sinceEmitted >= cf.freqEmitResolved {
 			// Keeping this after the checkpointResolvedTimestamp call will avoid
@@ -0,0 +1,363 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/pkg/errors"
+)
+
+// rowEvaluator applies the projection and filter of a changefeed created with
+// CREATE CHANGEFEED ... AS SELECT to its changed rows.
+type rowEvaluator struct {
+	sel     *tree.SelectClause
+	evalCtx *tree.EvalContext
+
+	// compiled caches the SELECT clause resolved against each version of the
+	// watched table seen so far.
+	compiled map[tableIDAndVersion]*compiledSelect
+	alloc    sqlbase.DatumAlloc
+}
+
+// compiledSelect is a SELECT clause resolved and type checked against one
+// version of a table descriptor.
+type compiledSelect struct {
+	// desc describes the projected rows. It's a copy of the table descriptor
+	// with the columns replaced by the outputs of the SELECT clause followed by
+	// any primary key columns not among them, which are needed to encode the
+	// key of the row.
+	desc *sqlbase.TableDescriptor
+	// exprs holds the expression of each output column. srcIdxs holds, for
+	// each output column that is a plain reference to a table column, the
+	// index of that column, and -1 otherwise.
+	exprs   []tree.TypedExpr
+	srcIdxs []int
+	// filter is the WHERE clause, if any.
+	filter tree.TypedExpr
+
+	ivars rowIVarContainer
+}
+
+// rowIVarContainer is a tree.IndexedVarContainer over a table row, which lets
+// expressions resolved against the table's columns be evaluated on it.
+type rowIVarContainer struct {
+	cols  []sqlbase.ColumnDescriptor
+	row   sqlbase.EncDatumRow
+	alloc *sqlbase.DatumAlloc
+}
+
+var _ tree.IndexedVarContainer = &rowIVarContainer{}
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (c *rowIVarContainer) IndexedVarEval(idx int, _ *tree.EvalContext) (tree.Datum, error) {
+	ed := &c.row[idx]
+	if err := ed.EnsureDecoded(&c.cols[idx].Type, c.alloc); err != nil {
+		return nil, err
+	}
+	return ed.Datum, nil
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (c *rowIVarContainer) IndexedVarResolvedType(idx int) *types.T {
+	return &c.cols[idx].Type
+}
+
+// IndexedVarNodeFormatter implements the tree.IndexedVarContainer interface.
+func (c *rowIVarContainer) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	n := tree.Name(c.cols[idx].Name)
+	return &n
+}
+
+// parseChangefeedSelect parses the SELECT clause stored in the
+// ChangefeedDetails of a changefeed created with CREATE CHANGEFEED ... AS
+// SELECT.
+func parseChangefeedSelect(sql string) (*tree.SelectClause, error) {
+	stmt, err := parser.ParseOne(sql)
+	if err != nil {
+		return nil, err
+	}
+	if sel, ok := stmt.AST.(*tree.Select); ok {
+		if clause, ok := sel.Select.(*tree.SelectClause); ok {
+			return clause, nil
+		}
+	}
+	return nil, errors.Errorf(`expected a SELECT clause: %s`, sql)
+}
+
+func newRowEvaluator(sql string, evalCtx *tree.EvalContext) (*rowEvaluator, error) {
+	sel, err := parseChangefeedSelect(sql)
+	if err != nil {
+		return nil, err
+	}
+	return &rowEvaluator{
+		sel:      sel,
+		evalCtx:  evalCtx,
+		compiled: make(map[tableIDAndVersion]*compiledSelect),
+	}, nil
+}
+
+// compile resolves and type checks the SELECT clause against the given table
+// descriptor, returning the cached result if it's already been done for this
+// version of the table.
+func (e *rowEvaluator) compile(tableDesc *sqlbase.TableDescriptor) (*compiledSelect, error) {
+	cacheKey := makeTableIDAndVersion(tableDesc.ID, tableDesc.Version)
+	if c, ok := e.compiled[cacheKey]; ok {
+		return c, nil
+	}
+
+	c := &compiledSelect{
+		ivars: rowIVarContainer{cols: tableDesc.Columns, alloc: &e.alloc},
+	}
+	ivarHelper := tree.MakeIndexedVarHelper(&c.ivars, len(tableDesc.Columns))
+	sources := sqlbase.MakeMultiSourceInfo(sqlbase.NewSourceInfoForSingleTable(
+		tree.MakeUnqualifiedTableName(tree.Name(tableDesc.Name)),
+		sqlbase.ResultColumnsFromColDescs(tableDesc.Columns),
+	))
+	searchPath := e.evalCtx.SessionData.SearchPath
+	typeCheck := func(expr tree.Expr, desired *types.T, context string) (tree.TypedExpr, error) {
+		expr, _, _, err := sqlbase.ResolveNames(expr, sources, ivarHelper, searchPath)
+		if err != nil {
+			return nil, err
+		}
+		semaCtx := tree.MakeSemaContext()
+		semaCtx.IVarContainer = &c.ivars
+		semaCtx.Properties.Require(context, tree.RejectSpecial|tree.RejectSubqueries)
+		return tree.TypeCheckAndRequire(expr, &semaCtx, desired, context)
+	}
+
+	desc := *tableDesc
+	desc.Columns = nil
+	nextColumnID := tableDesc.NextColumnID
+	names := make(map[string]struct{})
+	addColumn := func(name string, typ *types.T, srcIdx int, expr tree.TypedExpr) error {
+		if _, ok := names[name]; ok {
+			return errors.Errorf(`CHANGEFEED SELECT has more than one column named %q`, name)
+		}
+		names[name] = struct{}{}
+		col := sqlbase.ColumnDescriptor{Name: name, Type: *typ, Nullable: true}
+		if srcIdx >= 0 {
+			col.ID = tableDesc.Columns[srcIdx].ID
+		} else {
+			col.ID = nextColumnID
+			nextColumnID++
+		}
+		desc.Columns = append(desc.Columns, col)
+		c.exprs = append(c.exprs, expr)
+		c.srcIdxs = append(c.srcIdxs, srcIdx)
+		return nil
+	}
+
+	for _, target := range e.sel.Exprs {
+		expr := target.Expr
+		if v, ok := expr.(tree.VarName); ok {
+			var err error
+			if expr, err = v.NormalizeVarName(); err != nil {
+				return nil, err
+			}
+		}
+		switch expr.(type) {
+		case tree.UnqualifiedStar, *tree.AllColumnsSelector:
+			// There is only one table, so every star expands to its columns.
+			for i := range tableDesc.Columns {
+				col := &tableDesc.Columns[i]
+				if col.Hidden {
+					continue
+				}
+				if err := addColumn(col.Name, &col.Type, i, ivarHelper.IndexedVar(i)); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		name, err := tree.GetRenderColName(searchPath, target)
+		if err != nil {
+			return nil, err
+		}
+		typedExpr, err := typeCheck(expr, types.Any, `CHANGEFEED SELECT`)
+		if err != nil {
+			return nil, err
+		}
+		srcIdx := -1
+		if ivar, ok := typedExpr.(*tree.IndexedVar); ok {
+			srcIdx = ivar.Idx
+		}
+		if err := addColumn(name, typedExpr.ResolvedType(), srcIdx, typedExpr); err != nil {
+			return nil, err
+		}
+	}
+	if len(desc.Columns) == 0 {
+		return nil, errors.New(`CHANGEFEED SELECT must have at least one column`)
+	}
+
+	// The encoders find the primary key columns by ID.
+	projectedIDs := make(map[sqlbase.ColumnID]struct{}, len(desc.Columns))
+	for _, srcIdx := range c.srcIdxs {
+		if srcIdx >= 0 {
+			projectedIDs[tableDesc.Columns[srcIdx].ID] = struct{}{}
+		}
+	}
+	colIdxByID := tableDesc.ColumnIdxMap()
+	for _, colID := range tableDesc.PrimaryIndex.ColumnIDs {
+		if _, ok := projectedIDs[colID]; ok {
+			continue
+		}
+		srcIdx, ok := colIdxByID[colID]
+		if !ok {
+			return nil, errors.Errorf(`unknown column id: %d`, colID)
+		}
+		col := &tableDesc.Columns[srcIdx]
+		if err := addColumn(col.Name, &col.Type, srcIdx, ivarHelper.IndexedVar(srcIdx)); err != nil {
+			return nil, err
+		}
+	}
+
+	if e.sel.Where != nil {
+		var err error
+		if c.filter, err = typeCheck(e.sel.Where.Expr, types.Bool, `CHANGEFEED WHERE`); err != nil {
+			return nil, err
+		}
+	}
+
+	c.desc = &desc
+	// Only the current version of the table and the one before it are needed
+	// once a schema change is done (the latter for the previous values of rows
+	// when optDiff is set), so drop anything older to keep the cache bounded.
+	for k := range e.compiled {
+		if k>>32 == cacheKey>>32 && k < cacheKey-1 {
+			delete(e.compiled, k)
+		}
+	}
+	e.compiled[cacheKey] = c
+	return c, nil
+}
+
+// eval applies the projection and filter to a changed row. It returns false
+// if the row is filtered out. Otherwise, the row's datums and table descriptor
+// are replaced by the projected ones.
+//
+// Deletions only carry the primary key of the row, so unless the previous
+// value of the row is available (see optDiff), the filter can't be evaluated
+// for them and they're never filtered out. Their projections only hold the
+// table columns referenced directly by the SELECT clause. When the previous
+// value is available, it's projected the same way as the row itself and a
+// deletion is filtered based on it.
+func (e *rowEvaluator) eval(row *encodeRow) (bool, error) {
+	if row.prevTableDesc != nil && !row.prevDeleted {
+		prevKeep, prevDatums, prevDesc, err := e.evalDatums(row.prevTableDesc, row.prevDatums)
+		if err != nil {
+			return false, err
+		}
+		if row.deleted && !prevKeep {
+			return false, nil
+		}
+		if prevKeep {
+			row.prevDatums, row.prevTableDesc = prevDatums, prevDesc
+		} else {
+			// The previous value didn't pass the filter, so as far as this
+			// changefeed is concerned, the row didn't exist before.
+			row.prevDatums, row.prevDeleted = nil, true
+		}
+	}
+
+	c, err := e.compile(row.tableDesc)
+	if err != nil {
+		return false, err
+	}
+	if row.deleted {
+		projection := make(sqlbase.EncDatumRow, len(c.exprs))
+		for i, srcIdx := range c.srcIdxs {
+			if srcIdx >= 0 {
+				projection[i] = row.datums[srcIdx]
+			} else {
+				projection[i] = sqlbase.DatumToEncDatum(&c.desc.Columns[i].Type, tree.DNull)
+			}
+		}
+		row.datums, row.tableDesc = projection, c.desc
+		return true, nil
+	}
+
+	keep, projection, desc, err := e.evalDatums(row.tableDesc, row.datums)
+	if err != nil || !keep {
+		return false, err
+	}
+	row.datums, row.tableDesc = projection, desc
+	return true, nil
+}
+
+// evalDatums applies the filter and projection to the datums of a row that
+// exists, returning false if it is filtered out.
+func (e *rowEvaluator) evalDatums(
+	tableDesc *sqlbase.TableDescriptor, datums sqlbase.EncDatumRow,
+) (bool, sqlbase.EncDatumRow, *sqlbase.TableDescriptor, error) {
+	c, err := e.compile(tableDesc)
+	if err != nil {
+		return false, nil, nil, err
+	}
+
+	c.ivars.row = datums
+	e.evalCtx.PushIVarContainer(&c.ivars)
+	defer e.evalCtx.PopIVarContainer()
+
+	if c.filter != nil {
+		d, err := c.filter.Eval(e.evalCtx)
+		if err != nil {
+			return false, nil, nil, err
+		}
+		if d != tree.DBoolTrue {
+			return false, nil, nil, nil
+		}
+	}
+	projection := make(sqlbase.EncDatumRow, len(c.exprs))
+	for i, expr := range c.exprs {
+		d, err := expr.Eval(e.evalCtx)
+		if err != nil {
+			return false, nil, nil, err
+		}
+		projection[i] = sqlbase.DatumToEncDatum(&c.desc.Columns[i].Type, d)
+	}
+	return true, projection, c.desc, nil
+}
+
+// evalRows wraps a closure returning changed rows (see kvsToRows) with one
+// that applies the projection and filter to them, dropping the rows that are
+// filtered out.
+func (e *rowEvaluator) evalRows(
+	inputFn func(context.Context) ([]emitEntry, error),
+) func(context.Context) ([]emitEntry, error) {
+	return func(ctx context.Context) ([]emitEntry, error) {
+		inputs, err := inputFn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Filter in place, reusing the slice returned by inputFn.
+		output := inputs[:0]
+		for _, input := range inputs {
+			if input.row.datums != nil {
+				keep, err := e.eval(&input.row)
+				if err != nil {
+					return nil, err
+				}
+				if !keep {
+					if input.resolved == nil {
+						continue
+					}
+					input.row = encodeRow{}
+				}
+			}
+			output = append(output, input)
+		}
+		return output, nil
+	}
+}
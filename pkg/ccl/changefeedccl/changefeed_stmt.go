@@ -24,6 +24,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
@@ -50,21 +52,29 @@ type formatType string
 const (
 	optConfluentSchemaRegistry = `confluent_schema_registry`
 	optCursor                  = `cursor`
+	optDiff                    = `diff`
 	optEnvelope                = `envelope`
 	optFormat                  = `format`
 	optKeyInValue              = `key_in_value`
 	optResolvedTimestamps      = `resolved`
 	optUpdatedTimestamps       = `updated`
+	optWebhookAuthHeader       = `webhook_auth_header`
 
 	optEnvelopeKeyOnly       envelopeType = `key_only`
 	optEnvelopeRow           envelopeType = `row`
 	optEnvelopeDeprecatedRow envelopeType = `deprecated_row`
 	optEnvelopeWrapped       envelopeType = `wrapped`
 
-	optFormatJSON formatType = `json`
-	optFormatAvro formatType = `experimental_avro`
+	optFormatJSON    formatType = `json`
+	optFormatAvro    formatType = `experimental_avro`
+	optFormatAvroOCF formatType = `avro`
+	optFormatParquet formatType = `parquet`
 
+	sinkParamBatchSize        = `batch_size`
 	sinkParamCACert           = `ca_cert`
+	sinkParamClientCert       = `client_cert`
+	sinkParamClientKey        = `client_key`
+	sinkParamExactlyOnce      = `exactly_once`
 	sinkParamFileSize         = `file_size`
 	sinkParamSchemaTopic      = `schema_topic`
 	sinkParamTLSEnabled       = `tls_enabled`
@@ -72,6 +82,7 @@ const (
 	sinkSchemeBuffer          = ``
 	sinkSchemeExperimentalSQL = `experimental-sql`
 	sinkSchemeKafka           = `kafka`
+	sinkSchemeWebhookHTTPS    = `webhook-https`
 	sinkParamSASLEnabled      = `sasl_enabled`
 	sinkParamSASLHandshake    = `sasl_handshake`
 	sinkParamSASLUser         = `sasl_user`
@@ -81,11 +92,13 @@ const (
 var changefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
 	optConfluentSchemaRegistry: sql.KVStringOptRequireValue,
 	optCursor:                  sql.KVStringOptRequireValue,
+	optDiff:                    sql.KVStringOptRequireNoValue,
 	optEnvelope:                sql.KVStringOptRequireValue,
 	optFormat:                  sql.KVStringOptRequireValue,
 	optKeyInValue:              sql.KVStringOptRequireNoValue,
 	optResolvedTimestamps:      sql.KVStringOptAny,
 	optUpdatedTimestamps:       sql.KVStringOptRequireNoValue,
+	optWebhookAuthHeader:       sql.KVStringOptRequireValue,
 }
 
 // changefeedPlanHook implements sql.PlanHookFn.
@@ -154,6 +167,12 @@ func changefeedPlanHook(
 		if err != nil {
 			return err
 		}
+		if _, ok := opts[optDiff]; ok &&
+			!p.ExecCfg().Settings.Version.IsActive(cluster.VersionChangefeedDiff) {
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				`%s requires all nodes to be upgraded to %s`,
+				optDiff, cluster.VersionByKey(cluster.VersionChangefeedDiff))
+		}
 
 		jobDescription, err := changefeedJobDescription(p, changefeedStmt, sinkURI, opts)
 		if err != nil {
@@ -208,11 +227,31 @@ func changefeedPlanHook(
 			}
 		}
 
+		// The SELECT clause of a CREATE CHANGEFEED ... AS SELECT is checked
+		// against the table as of the statement time. It's resolved again
+		// against every later version of the table as the changefeed runs.
+		var selectClause string
+		if changefeedStmt.Select != nil {
+			selectClause = tree.AsString(changefeedStmt.Select)
+			evaluator, err := newRowEvaluator(selectClause, &p.ExtendedEvalContext().EvalContext)
+			if err != nil {
+				return err
+			}
+			for _, desc := range targetDescs {
+				if tableDesc := desc.GetTable(); tableDesc != nil {
+					if _, err := evaluator.compile(tableDesc); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
 		details := jobspb.ChangefeedDetails{
 			Targets:       targets,
 			Opts:          opts,
 			SinkURI:       sinkURI,
 			StatementTime: statementTime,
+			Select:        selectClause,
 		}
 		progress := jobspb.Progress{
 			Progress: &jobspb.Progress_HighWater{HighWater: &initialHighWater},
@@ -259,6 +298,11 @@ func changefeedPlanHook(
 		}
 		if isCloudStorageSink(parsedSink) {
 			details.Opts[optKeyInValue] = ``
+		} else {
+			switch f := formatType(details.Opts[optFormat]); f {
+			case optFormatAvroOCF, optFormatParquet:
+				return errors.Errorf(`%s=%s is only supported by cloud storage sinks`, optFormat, f)
+			}
 		}
 
 		// Feature telemetry
@@ -269,6 +313,9 @@ func changefeedPlanHook(
 		telemetry.Count(`changefeed.create.sink.` + telemetrySink)
 		telemetry.Count(`changefeed.create.format.` + details.Opts[optFormat])
 		telemetry.CountBucketed(`changefeed.create.num_tables`, int64(len(targets)))
+		if details.Select != `` {
+			telemetry.Count(`changefeed.create.select`)
+		}
 
 		if details.SinkURI == `` {
 			err := distChangefeedFlow(ctx, p, 0 /* jobID */, details, progress, resultsCh)
@@ -344,9 +391,14 @@ func changefeedJobDescription(
 	c := &tree.CreateChangefeed{
 		Targets: changefeed.Targets,
 		SinkURI: tree.NewDString(cleanedSinkURI),
+		Select:  changefeed.Select,
 	}
 	for k, v := range opts {
 		opt := tree.KVOption{Key: tree.Name(k)}
+		if k == optWebhookAuthHeader {
+			// The header usually carries a credential.
+			v = `redacted`
+		}
 		if len(v) > 0 {
 			opt.Value = tree.NewDString(v)
 		}
@@ -390,7 +442,7 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 	switch formatType(details.Opts[optFormat]) {
 	case ``, optFormatJSON:
 		details.Opts[optFormat] = string(optFormatJSON)
-	case optFormatAvro:
+	case optFormatAvro, optFormatAvroOCF, optFormatParquet:
 		// No-op.
 	default:
 		return jobspb.ChangefeedDetails{}, errors.Errorf(
@@ -470,6 +522,22 @@ func (b *changefeedResumer) Resume(
 		}
 	}
 
+	// Until the initial scan is done, the table data as of the statement time
+	// must not be garbage collected. The changeFrontier releases the protection
+	// once the high-water reaches the statement time. Until all nodes are
+	// upgraded, the GC TTL is all there is to rely on.
+	h := progress.GetHighWater()
+	if (h == nil || *h == (hlc.Timestamp{})) &&
+		execCfg.Settings.Version.IsActive(cluster.VersionProtectedTimestamps) {
+		spans, err := fetchSpansForTargets(ctx, execCfg.DB, details.Targets, details.StatementTime)
+		if err != nil {
+			return err
+		}
+		if err := b.job.ProtectTimestamp(ctx, details.StatementTime, spans); err != nil {
+			return errors.Wrap(err, "protecting timestamp")
+		}
+	}
+
 	// We'd like to avoid failing a changefeed unnecessarily, so when an error
 	// bubbles up to this level, we'd like to "retry" the flow if possible. This
 	// could be because the sink is down or because a cockroach node has crashed
@@ -481,8 +549,14 @@ func (b *changefeedResumer) Resume(
 	}
 	var err error
 	for r := retry.StartWithCtx(ctx, opts); r.Next(); {
-		if err = distChangefeedFlow(ctx, phs, jobID, details, progress, startedCh); err == nil {
-			return nil
+		// Kafka sinks with exactly-once delivery need the transactions of the
+		// previous flow, if any, to be finished before starting a new one.
+		if progress, err = recoverKafkaTxns(
+			ctx, b.job, execCfg.Settings, details, progress,
+		); err == nil {
+			if err = distChangefeedFlow(ctx, phs, jobID, details, progress, startedCh); err == nil {
+				return nil
+			}
 		}
 		if !IsRetryableError(err) {
 			log.Warningf(ctx, `CHANGEFEED job %d returning with error: %+v`, jobID, err)
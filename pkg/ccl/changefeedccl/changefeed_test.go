@@ -78,6 +78,31 @@ func TestChangefeedBasics(t *testing.T) {
 		assertPayloads(t, foo, []string{
 			`foo: [1]->{"after": null}`,
 		})
+
+		// With diff, the previous value of the row is filtered too, so rows
+		// moving in or out of the filter and deletes can be told apart.
+		sqlDB.Exec(t, `CREATE TABLE bar (a INT PRIMARY KEY, b STRING, c INT)`)
+		sqlDB.Exec(t, `INSERT INTO bar VALUES (1, 'a', 10), (2, 'b', 20)`)
+		bar := feed(t, f, `CREATE CHANGEFEED WITH diff AS SELECT b, c FROM bar WHERE c > 15`)
+		defer closeFeed(t, bar)
+		assertPayloads(t, bar, []string{
+			`bar: [2]->{"after": {"a": 2, "b": "b", "c": 20}, "before": null}`,
+		})
+
+		// An update moving a row out of the filter is skipped, and one moving
+		// a row into it has no previous value.
+		sqlDB.Exec(t, `UPDATE bar SET c = 5 WHERE a = 2`)
+		sqlDB.Exec(t, `UPDATE bar SET c = 25 WHERE a = 1`)
+		assertPayloads(t, bar, []string{
+			`bar: [1]->{"after": {"a": 1, "b": "a", "c": 25}, "before": null}`,
+		})
+
+		// Deleting a row that's filtered out is skipped.
+		sqlDB.Exec(t, `DELETE FROM bar WHERE a = 2`)
+		sqlDB.Exec(t, `DELETE FROM bar WHERE a = 1`)
+		assertPayloads(t, bar, []string{
+			`bar: [1]->{"after": null, "before": {"a": 1, "b": "a", "c": 25}}`,
+		})
 	}
 
 	t.Run(`sinkless`, sinklessTest(testFn))
@@ -127,6 +152,42 @@ func TestChangefeedEnvelope(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedDiff(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'initial')`)
+		sqlDB.Exec(t, `UPSERT INTO foo VALUES (0, 'updated')`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH diff`)
+		defer closeFeed(t, foo)
+
+		// The initial scan has no previous values.
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": {"a": 0, "b": "updated"}, "before": null}`,
+		})
+
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a')`)
+		sqlDB.Exec(t, `UPSERT INTO foo VALUES (0, 'b')`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "a"}, "before": null}`,
+			`foo: [0]->{"after": {"a": 0, "b": "b"}, "before": {"a": 0, "b": "updated"}}`,
+		})
+
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'c')`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": null, "before": {"a": 1, "b": "a"}}`,
+			`foo: [1]->{"after": {"a": 1, "b": "c"}, "before": null}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedMultiTable(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -813,6 +874,39 @@ func TestChangefeedComputedColumn(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedSelect(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c INT)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a', 10), (2, 'b', 20)`)
+
+		// The primary key is always included, even if it isn't selected.
+		foo := feed(t, f, `CREATE CHANGEFEED AS SELECT b, c * 2 AS d FROM foo WHERE c > 15`)
+		defer closeFeed(t, foo)
+		assertPayloads(t, foo, []string{
+			`foo: [2]->{"after": {"a": 2, "b": "b", "d": 40}}`,
+		})
+
+		// Updates that no longer match the filter are skipped.
+		sqlDB.Exec(t, `UPDATE foo SET c = 5 WHERE a = 2`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (3, 'c', 30)`)
+		assertPayloads(t, foo, []string{
+			`foo: [3]->{"after": {"a": 3, "b": "c", "d": 60}}`,
+		})
+
+		// Deletes don't have the rest of the row to filter on.
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": null}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedUpdatePrimaryKey(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -1308,6 +1402,19 @@ func TestChangefeedErrors(t *testing.T) {
 		`EXPERIMENTAL CHANGEFEED FOR foo WITH cursor=$1`, timeutil.Now().Add(time.Hour),
 	)
 
+	sqlDB.ExpectErr(
+		t, `column "nope" does not exist`,
+		`CREATE CHANGEFEED AS SELECT nope FROM foo`,
+	)
+	sqlDB.ExpectErr(
+		t, `argument of CHANGEFEED WHERE must be type bool, not type int`,
+		`CREATE CHANGEFEED AS SELECT b FROM foo WHERE a`,
+	)
+	sqlDB.ExpectErr(
+		t, `aggregate functions are not allowed in CHANGEFEED SELECT`,
+		`CREATE CHANGEFEED AS SELECT max(a) FROM foo`,
+	)
+
 	sqlDB.ExpectErr(
 		t, `omit the SINK clause`,
 		`CREATE CHANGEFEED FOR foo INTO ''`,
@@ -1455,6 +1562,21 @@ func TestChangefeedErrors(t *testing.T) {
 		`experimental-nodelocal:///bar`,
 	)
 
+	// The self-describing file formats only work with cloud storage sinks.
+	sqlDB.ExpectErr(
+		t, `format=avro is only supported by cloud storage sinks`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='avro'`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `format=parquet is only supported by cloud storage sinks`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='parquet'`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `envelope=key_only is not supported with format=parquet`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='parquet', envelope='key_only'`,
+		`experimental-nodelocal:///bar`,
+	)
+
 	// WITH key_in_value requires envelope=wrapped
 	sqlDB.ExpectErr(
 		t, `key_in_value is only usable with envelope=wrapped`,
@@ -1464,6 +1586,27 @@ func TestChangefeedErrors(t *testing.T) {
 		t, `key_in_value is only usable with envelope=wrapped`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH key_in_value, envelope='row'`, `kafka://nope`,
 	)
+
+	// WITH diff requires envelope=wrapped and isn't supported by the
+	// self-describing file formats.
+	sqlDB.ExpectErr(
+		t, `diff is only usable with envelope=wrapped`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH diff, envelope='key_only'`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `diff is only usable with envelope=wrapped`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH diff, envelope='row'`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `diff is not supported with format=avro`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH diff, format='avro'`,
+		`experimental-nodelocal:///bar`,
+	)
+	sqlDB.ExpectErr(
+		t, `diff is not supported with format=parquet`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH diff, format='parquet'`,
+		`experimental-nodelocal:///bar`,
+	)
 }
 
 func TestChangefeedPermissions(t *testing.T) {
@@ -47,6 +47,16 @@ type encodeRow struct {
 	// tableDesc is a TableDescriptor for the table containing `datums`.
 	// It's valid for interpreting the row at `updated`.
 	tableDesc *sqlbase.TableDescriptor
+	// prevDatums is the old value of a changed table row. The field is set
+	// when the changefeed includes the diff option.
+	prevDatums sqlbase.EncDatumRow
+	// prevDeleted is true if prevDatums is missing or is a deletion.
+	prevDeleted bool
+	// prevTableDesc is a TableDescriptor for the table containing
+	// `prevDatums`. It's usually the same as `tableDesc`, but differs for the
+	// rows emitted by the scan for a backfill, whose previous values are read
+	// with the schema from before it.
+	prevTableDesc *sqlbase.TableDescriptor
 }
 
 // Encoder turns a row into a serialized changefeed key, value, or resolved
@@ -74,6 +84,10 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 		return makeJSONEncoder(opts)
 	case optFormatAvro:
 		return newConfluentAvroEncoder(opts)
+	case optFormatAvroOCF:
+		return newAvroOCFEncoder(opts)
+	case optFormatParquet:
+		return newParquetEncoder(opts)
 	default:
 		return nil, errors.Errorf(`unknown %s: %s`, optFormat, opts[optFormat])
 	}
@@ -84,7 +98,7 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 // to its value. Updated timestamps in rows and resolved timestamp payloads are
 // stored in a sub-object under the `__crdb__` key in the top-level JSON object.
 type jsonEncoder struct {
-	updatedField, wrapped, keyOnly, keyInValue bool
+	updatedField, beforeField, wrapped, keyOnly, keyInValue bool
 
 	alloc sqlbase.DatumAlloc
 	buf   bytes.Buffer
@@ -98,6 +112,11 @@ func makeJSONEncoder(opts map[string]string) (*jsonEncoder, error) {
 		wrapped: envelopeType(opts[optEnvelope]) == optEnvelopeWrapped,
 	}
 	_, e.updatedField = opts[optUpdatedTimestamps]
+	_, e.beforeField = opts[optDiff]
+	if e.beforeField && !e.wrapped {
+		return nil, errors.Errorf(`%s is only usable with %s=%s`,
+			optDiff, optEnvelope, optEnvelopeWrapped)
+	}
 	_, e.keyInValue = opts[optKeyInValue]
 	if e.keyInValue && !e.wrapped {
 		return nil, errors.Errorf(`%s is only usable with %s=%s`,
@@ -150,19 +169,17 @@ func (e *jsonEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 
 	var after map[string]interface{}
 	if !row.deleted {
-		columns := row.tableDesc.Columns
-		after = make(map[string]interface{}, len(columns))
-		for i := range columns {
-			col := &columns[i]
-			datum := row.datums[i]
-			if err := datum.EnsureDecoded(&col.Type, &e.alloc); err != nil {
-				return nil, err
-			}
-			var err error
-			after[col.Name], err = tree.AsJSON(datum.Datum)
-			if err != nil {
-				return nil, err
-			}
+		var err error
+		if after, err = e.encodeColumns(row.tableDesc, row.datums); err != nil {
+			return nil, err
+		}
+	}
+
+	var before map[string]interface{}
+	if e.beforeField && !row.prevDeleted {
+		var err error
+		if before, err = e.encodeColumns(row.prevTableDesc, row.prevDatums); err != nil {
+			return nil, err
 		}
 	}
 
@@ -173,6 +190,13 @@ func (e *jsonEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 		} else {
 			jsonEntries = map[string]interface{}{`after`: nil}
 		}
+		if e.beforeField {
+			if before != nil {
+				jsonEntries[`before`] = before
+			} else {
+				jsonEntries[`before`] = nil
+			}
+		}
 		if e.keyInValue {
 			keyEntries, err := e.encodeKeyRaw(row)
 			if err != nil {
@@ -204,6 +228,28 @@ func (e *jsonEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 	return e.buf.Bytes(), nil
 }
 
+// encodeColumns returns a map of every column name to its value in the given
+// row.
+func (e *jsonEncoder) encodeColumns(
+	tableDesc *sqlbase.TableDescriptor, datums sqlbase.EncDatumRow,
+) (map[string]interface{}, error) {
+	columns := tableDesc.Columns
+	jsonEntries := make(map[string]interface{}, len(columns))
+	for i := range columns {
+		col := &columns[i]
+		datum := datums[i]
+		if err := datum.EnsureDecoded(&col.Type, &e.alloc); err != nil {
+			return nil, err
+		}
+		var err error
+		jsonEntries[col.Name], err = tree.AsJSON(datum.Datum)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return jsonEntries, nil
+}
+
 // EncodeResolvedTimestamp implements the Encoder interface.
 func (e *jsonEncoder) EncodeResolvedTimestamp(_ string, resolved hlc.Timestamp) ([]byte, error) {
 	meta := map[string]interface{}{
@@ -224,15 +270,16 @@ func (e *jsonEncoder) EncodeResolvedTimestamp(_ string, resolved hlc.Timestamp)
 // JSON format. Keys are the primary key columns in a record. Values are all
 // columns in a record.
 type confluentAvroEncoder struct {
-	registryURL           string
-	updatedField, keyOnly bool
+	registryURL                        string
+	updatedField, beforeField, keyOnly bool
 
 	keyCache      map[tableIDAndVersion]confluentRegisteredKeySchema
-	valueCache    map[tableIDAndVersion]confluentRegisteredEnvelopeSchema
+	valueCache    map[tableIDAndVersionPair]confluentRegisteredEnvelopeSchema
 	resolvedCache map[string]confluentRegisteredEnvelopeSchema
 }
 
 type tableIDAndVersion uint64
+type tableIDAndVersionPair [2]tableIDAndVersion // [before, after]
 
 func makeTableIDAndVersion(id sqlbase.ID, version sqlbase.DescriptorVersion) tableIDAndVersion {
 	return tableIDAndVersion(id)<<32 + tableIDAndVersion(version)
@@ -262,6 +309,11 @@ func newConfluentAvroEncoder(opts map[string]string) (*confluentAvroEncoder, err
 			optEnvelope, opts[optEnvelope], optFormat, optFormatAvro)
 	}
 	_, e.updatedField = opts[optUpdatedTimestamps]
+	_, e.beforeField = opts[optDiff]
+	if e.beforeField && e.keyOnly {
+		return nil, errors.Errorf(`%s is only usable with %s=%s`,
+			optDiff, optEnvelope, optEnvelopeWrapped)
+	}
 
 	if _, ok := opts[optKeyInValue]; ok {
 		return nil, errors.Errorf(`%s is not supported with %s=%s`,
@@ -274,7 +326,7 @@ func newConfluentAvroEncoder(opts map[string]string) (*confluentAvroEncoder, err
 	}
 
 	e.keyCache = make(map[tableIDAndVersion]confluentRegisteredKeySchema)
-	e.valueCache = make(map[tableIDAndVersion]confluentRegisteredEnvelopeSchema)
+	e.valueCache = make(map[tableIDAndVersionPair]confluentRegisteredEnvelopeSchema)
 	e.resolvedCache = make(map[string]confluentRegisteredEnvelopeSchema)
 	return e, nil
 }
@@ -316,16 +368,41 @@ func (e *confluentAvroEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 		return nil, nil
 	}
 
-	cacheKey := makeTableIDAndVersion(row.tableDesc.ID, row.tableDesc.Version)
+	var cacheKey tableIDAndVersionPair
+	if e.beforeField && row.prevTableDesc != nil {
+		cacheKey[0] = makeTableIDAndVersion(row.prevTableDesc.ID, row.prevTableDesc.Version)
+	}
+	cacheKey[1] = makeTableIDAndVersion(row.tableDesc.ID, row.tableDesc.Version)
 	registered, ok := e.valueCache[cacheKey]
 	if !ok {
+		var beforeDataSchema *avroDataRecord
+		if e.beforeField {
+			// Without a previous value, the before record can only ever be null,
+			// so it's made from the current version of the table.
+			beforeDesc := row.prevTableDesc
+			if beforeDesc == nil {
+				beforeDesc = row.tableDesc
+			}
+			var err error
+			if beforeDataSchema, err = tableToAvroSchema(beforeDesc); err != nil {
+				return nil, err
+			}
+			// The before and after records are both named after the table, but
+			// names have to be unique within a schema. The before record is only
+			// ever encoded as part of the envelope, so its own codec doesn't
+			// need to be rebuilt.
+			beforeDataSchema.Name += `_before`
+		}
 		afterDataSchema, err := tableToAvroSchema(row.tableDesc)
 		if err != nil {
 			return nil, err
 		}
 
-		opts := avroEnvelopeOpts{afterField: true, updatedField: e.updatedField}
-		registered.schema, err = envelopeToAvroSchema(row.tableDesc.Name, opts, afterDataSchema)
+		opts := avroEnvelopeOpts{
+			beforeField: e.beforeField, afterField: true, updatedField: e.updatedField,
+		}
+		registered.schema, err = envelopeToAvroSchema(
+			row.tableDesc.Name, opts, nil /* key */, beforeDataSchema, afterDataSchema)
 		if err != nil {
 			return nil, err
 		}
@@ -340,11 +417,12 @@ func (e *confluentAvroEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 		// TODO(dan): Bound the size of this cache.
 		e.valueCache[cacheKey] = registered
 	}
-	var meta avroMetadata
+	meta := avroMetadata{}
 	if registered.schema.opts.updatedField {
-		meta = map[string]interface{}{
-			`updated`: row.updated,
-		}
+		meta[`updated`] = row.updated
+	}
+	if registered.schema.opts.beforeField && !row.prevDeleted {
+		meta[`before`] = row.prevDatums
 	}
 	var datums sqlbase.EncDatumRow
 	if !row.deleted {
@@ -367,7 +445,8 @@ func (e *confluentAvroEncoder) EncodeResolvedTimestamp(
 	if !ok {
 		opts := avroEnvelopeOpts{resolvedField: true}
 		var err error
-		registered.schema, err = envelopeToAvroSchema(topic, opts, nil /* after */)
+		registered.schema, err = envelopeToAvroSchema(
+			topic, opts, nil /* key */, nil /* before */, nil /* after */)
 		if err != nil {
 			return nil, err
 		}
@@ -438,3 +517,131 @@ func (e *confluentAvroEncoder) register(schema *avroRecord, subject string) (int
 
 	return res.ID, nil
 }
+
+// avroOCFEncoder encodes changefeed entries as records in Avro's binary format,
+// for the avro object container files written by cloud storage sinks. Values
+// are an envelope with the primary key, the new value of the row and,
+// optionally, the updated timestamp. Keys are not encoded, because these
+// files have nowhere to put them. Resolved timestamps are written to their own
+// files and so are encoded as JSON, the same as with the wrapped envelope.
+type avroOCFEncoder struct {
+	updatedField bool
+
+	valueCache map[tableIDAndVersion]*avroEnvelopeRecord
+	resolved   jsonEncoder
+}
+
+var _ Encoder = &avroOCFEncoder{}
+
+func newAvroOCFEncoder(opts map[string]string) (*avroOCFEncoder, error) {
+	if envelopeType(opts[optEnvelope]) != optEnvelopeWrapped {
+		return nil, errors.Errorf(`%s=%s is not supported with %s=%s`,
+			optEnvelope, opts[optEnvelope], optFormat, optFormatAvroOCF)
+	}
+	if _, ok := opts[optDiff]; ok {
+		return nil, errors.Errorf(`%s is not supported with %s=%s`,
+			optDiff, optFormat, optFormatAvroOCF)
+	}
+	e := &avroOCFEncoder{
+		valueCache: make(map[tableIDAndVersion]*avroEnvelopeRecord),
+		resolved:   jsonEncoder{wrapped: true},
+	}
+	_, e.updatedField = opts[optUpdatedTimestamps]
+	return e, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *avroOCFEncoder) EncodeKey(encodeRow) ([]byte, error) {
+	return nil, nil
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *avroOCFEncoder) EncodeValue(row encodeRow) ([]byte, error) {
+	cacheKey := makeTableIDAndVersion(row.tableDesc.ID, row.tableDesc.Version)
+	schema, ok := e.valueCache[cacheKey]
+	if !ok {
+		var err error
+		if schema, err = avroOCFSchema(row.tableDesc, e.updatedField); err != nil {
+			return nil, err
+		}
+		// TODO(dan): Bound the size of this cache.
+		e.valueCache[cacheKey] = schema
+	}
+	meta := avroMetadata{`key`: row.datums}
+	if e.updatedField {
+		meta[`updated`] = row.updated
+	}
+	var datums sqlbase.EncDatumRow
+	if !row.deleted {
+		datums = row.datums
+	}
+	return schema.BinaryFromRow(nil /* buf */, meta, datums)
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *avroOCFEncoder) EncodeResolvedTimestamp(
+	topic string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	return e.resolved.EncodeResolvedTimestamp(topic, resolved)
+}
+
+// parquetEncoder encodes changefeed entries as the records of the parquet
+// files written by cloud storage sinks; see parquetSchema. Like with
+// avroOCFEncoder, keys are not encoded and resolved timestamps are encoded as
+// JSON.
+type parquetEncoder struct {
+	updatedField bool
+
+	schemaCache map[tableIDAndVersion]*parquetSchema
+	resolved    jsonEncoder
+	alloc       sqlbase.DatumAlloc
+	buf         []byte
+}
+
+var _ Encoder = &parquetEncoder{}
+
+func newParquetEncoder(opts map[string]string) (*parquetEncoder, error) {
+	if envelopeType(opts[optEnvelope]) != optEnvelopeWrapped {
+		return nil, errors.Errorf(`%s=%s is not supported with %s=%s`,
+			optEnvelope, opts[optEnvelope], optFormat, optFormatParquet)
+	}
+	if _, ok := opts[optDiff]; ok {
+		return nil, errors.Errorf(`%s is not supported with %s=%s`,
+			optDiff, optFormat, optFormatParquet)
+	}
+	e := &parquetEncoder{
+		schemaCache: make(map[tableIDAndVersion]*parquetSchema),
+		resolved:    jsonEncoder{wrapped: true},
+	}
+	_, e.updatedField = opts[optUpdatedTimestamps]
+	return e, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *parquetEncoder) EncodeKey(encodeRow) ([]byte, error) {
+	return nil, nil
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *parquetEncoder) EncodeValue(row encodeRow) ([]byte, error) {
+	cacheKey := makeTableIDAndVersion(row.tableDesc.ID, row.tableDesc.Version)
+	schema, ok := e.schemaCache[cacheKey]
+	if !ok {
+		var err error
+		if schema, err = makeParquetSchema(row.tableDesc, e.updatedField); err != nil {
+			return nil, err
+		}
+		// TODO(dan): Bound the size of this cache.
+		e.schemaCache[cacheKey] = schema
+	}
+	var err error
+	e.buf, err = schema.appendRow(e.buf[:0], row, &e.alloc)
+	return e.buf, err
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *parquetEncoder) EncodeResolvedTimestamp(
+	topic string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	return e.resolved.EncodeResolvedTimestamp(topic, resolved)
+}
@@ -0,0 +1,274 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/parquet"
+	"github.com/pkg/errors"
+)
+
+// This file contains the encoding of row changes as parquet records, for the
+// cloud storage sink with `format=parquet`. The files themselves are written by
+// the parquet package.
+
+const (
+	parquetCreatedBy = `cockroachdb changefeed`
+
+	parquetUpdatedColumnName = `__crdb__updated`
+	parquetDeletedColumnName = `__crdb__deleted`
+)
+
+type parquetColumnKind int
+
+const (
+	// parquetColumnData holds the value of a SQL column.
+	parquetColumnData parquetColumnKind = iota
+	// parquetColumnUpdated holds the updated timestamp of the row.
+	parquetColumnUpdated
+	// parquetColumnDeleted holds whether the row was deleted.
+	parquetColumnDeleted
+)
+
+// parquetColumn is the schema of one column of a parquet file.
+type parquetColumn struct {
+	parquet.Column
+	kind parquetColumnKind
+	// colIdx is the index of the SQL column in the table, for data columns.
+	colIdx int
+}
+
+// parquetSchema is the schema of the parquet files written for a table. Each
+// SQL column maps to a nullable parquet column. SQL types without a natural
+// parquet counterpart are written as strings. Deletions are written as a row
+// with only the primary key columns set and `__crdb__deleted` set to true.
+type parquetSchema struct {
+	columns      []parquetColumn
+	inPrimaryKey map[int]struct{}
+}
+
+func makeParquetSchema(
+	tableDesc *sqlbase.TableDescriptor, updatedField bool,
+) (*parquetSchema, error) {
+	s := &parquetSchema{inPrimaryKey: make(map[int]struct{})}
+	colIdxByID := tableDesc.ColumnIdxMap()
+	for _, colID := range tableDesc.PrimaryIndex.ColumnIDs {
+		colIdx, ok := colIdxByID[colID]
+		if !ok {
+			return nil, errors.Errorf(`unknown column id: %d`, colID)
+		}
+		s.inPrimaryKey[colIdx] = struct{}{}
+	}
+	for colIdx := range tableDesc.Columns {
+		col := &tableDesc.Columns[colIdx]
+		c := parquetColumn{
+			Column: parquet.Column{Name: col.Name},
+			kind:   parquetColumnData,
+			colIdx: colIdx,
+		}
+		switch col.Type.Family() {
+		case types.BoolFamily:
+			c.Type = parquet.Boolean
+		case types.IntFamily:
+			c.Type = parquet.Int64
+		case types.FloatFamily:
+			c.Type = parquet.Double
+		case types.BytesFamily:
+			c.Type = parquet.ByteArray
+		default:
+			c.Type, c.Annotation = parquet.ByteArray, parquet.UTF8
+		}
+		s.columns = append(s.columns, c)
+	}
+	if updatedField {
+		s.columns = append(s.columns, parquetColumn{
+			Column: parquet.Column{
+				Name: parquetUpdatedColumnName, Type: parquet.ByteArray, Annotation: parquet.UTF8,
+			},
+			kind: parquetColumnUpdated,
+		})
+	}
+	s.columns = append(s.columns, parquetColumn{
+		Column: parquet.Column{Name: parquetDeletedColumnName, Type: parquet.Boolean, Required: true},
+		kind:   parquetColumnDeleted,
+	})
+	return s, nil
+}
+
+// appendRow encodes the given row change as a record accepted by
+// parquetFileWriter.AddRecord. For each column, the record has a byte set to 1
+// if the value is not null, followed by the PLAIN encoding of the value, except
+// for booleans which take a whole byte.
+func (s *parquetSchema) appendRow(
+	buf []byte, row encodeRow, alloc *sqlbase.DatumAlloc,
+) ([]byte, error) {
+	for i := range s.columns {
+		c := &s.columns[i]
+		var d tree.Datum = tree.DNull
+		switch c.kind {
+		case parquetColumnData:
+			if _, ok := s.inPrimaryKey[c.colIdx]; row.deleted && !ok {
+				// Only the primary key columns are set for deletions.
+				break
+			}
+			ed, col := row.datums[c.colIdx], &row.tableDesc.Columns[c.colIdx]
+			if err := ed.EnsureDecoded(&col.Type, alloc); err != nil {
+				return nil, err
+			}
+			d = ed.Datum
+		case parquetColumnUpdated:
+			d = tree.NewDString(row.updated.AsOfSystemTime())
+		case parquetColumnDeleted:
+			d = tree.MakeDBool(tree.DBool(row.deleted))
+		}
+		var err error
+		if buf, err = appendParquetValue(buf, c, d); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func appendParquetValue(buf []byte, c *parquetColumn, d tree.Datum) ([]byte, error) {
+	if d == tree.DNull {
+		return append(buf, 0), nil
+	}
+	buf = append(buf, 1)
+	var scratch [8]byte
+	switch c.Type {
+	case parquet.Boolean:
+		b, ok := d.(*tree.DBool)
+		if !ok {
+			return nil, errors.Errorf(`unexpected datum %T for boolean column %s`, d, c.Name)
+		}
+		if *b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case parquet.Int64:
+		i, ok := d.(*tree.DInt)
+		if !ok {
+			return nil, errors.Errorf(`unexpected datum %T for int64 column %s`, d, c.Name)
+		}
+		binary.LittleEndian.PutUint64(scratch[:], uint64(*i))
+		return append(buf, scratch[:]...), nil
+	case parquet.Double:
+		f, ok := d.(*tree.DFloat)
+		if !ok {
+			return nil, errors.Errorf(`unexpected datum %T for double column %s`, d, c.Name)
+		}
+		binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(float64(*f)))
+		return append(buf, scratch[:]...), nil
+	case parquet.ByteArray:
+		var s string
+		switch t := d.(type) {
+		case *tree.DBytes:
+			s = string(*t)
+		case *tree.DString:
+			s = string(*t)
+		default:
+			s = tree.AsStringWithFlags(d, tree.FmtExport)
+		}
+		binary.LittleEndian.PutUint32(scratch[:4], uint32(len(s)))
+		buf = append(buf, scratch[:4]...)
+		return append(buf, s...), nil
+	default:
+		return nil, errors.Errorf(`unknown parquet type %d`, c.Type)
+	}
+}
+
+// parquetFileWriter accumulates records encoded by parquetSchema.appendRow
+// and writes them out as a parquet file.
+type parquetFileWriter struct {
+	schema *parquetSchema
+	w      *parquet.Writer
+	size   int
+}
+
+var _ cloudStorageFileWriter = &parquetFileWriter{}
+
+func makeParquetFileWriter(schema *parquetSchema) *parquetFileWriter {
+	columns := make([]parquet.Column, len(schema.columns))
+	for i := range schema.columns {
+		columns[i] = schema.columns[i].Column
+	}
+	return &parquetFileWriter{
+		schema: schema,
+		w:      parquet.NewWriter(columns, parquet.Uncompressed, parquetCreatedBy),
+	}
+}
+
+// AddRecord implements the cloudStorageFileWriter interface.
+func (w *parquetFileWriter) AddRecord(record []byte) error {
+	values := make([]interface{}, len(w.schema.columns))
+	rest := record
+	for i := range w.schema.columns {
+		c := &w.schema.columns[i]
+		if len(rest) == 0 {
+			return errors.Errorf(`truncated parquet record at column %s`, c.Name)
+		}
+		present := rest[0] == 1
+		rest = rest[1:]
+		if !present {
+			if c.Required {
+				return errors.Errorf(`null value in required parquet column %s`, c.Name)
+			}
+			continue
+		}
+		n := 1
+		switch c.Type {
+		case parquet.Int64, parquet.Double:
+			n = 8
+		case parquet.ByteArray:
+			if len(rest) >= 4 {
+				n = 4 + int(binary.LittleEndian.Uint32(rest))
+			} else {
+				n = 4
+			}
+		}
+		if len(rest) < n {
+			return errors.Errorf(`truncated parquet record at column %s`, c.Name)
+		}
+		var v []byte
+		v, rest = rest[:n], rest[n:]
+		switch c.Type {
+		case parquet.Boolean:
+			values[i] = v[0] == 1
+		case parquet.Int64:
+			values[i] = int64(binary.LittleEndian.Uint64(v))
+		case parquet.Double:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(v))
+		default:
+			values[i] = v[4:]
+		}
+	}
+	if len(rest) > 0 {
+		return errors.New(`parquet record has more columns than the schema`)
+	}
+	if err := w.w.AddRow(values); err != nil {
+		return err
+	}
+	w.size += len(record)
+	return nil
+}
+
+// Len implements the cloudStorageFileWriter interface.
+func (w *parquetFileWriter) Len() int {
+	return w.size
+}
+
+// Contents implements the cloudStorageFileWriter interface.
+func (w *parquetFileWriter) Contents() ([]byte, error) {
+	return w.w.Finish()
+}
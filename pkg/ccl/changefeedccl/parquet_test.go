@@ -0,0 +1,41 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParquetFileWriter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	schema, err := makeParquetSchema(makeCloudStorageTestTable(1), false /* updatedField */)
+	require.NoError(t, err)
+	w := makeParquetFileWriter(schema)
+
+	// k=1, s=NULL, deleted=false.
+	record := []byte{1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0}
+	require.NoError(t, w.AddRecord(record))
+	require.Equal(t, len(record), w.Len())
+
+	require.EqualError(t, w.AddRecord(record[:4]), `truncated parquet record at column k`)
+	require.EqualError(t, w.AddRecord(record[:11]),
+		`truncated parquet record at column __crdb__deleted`)
+	require.EqualError(t, w.AddRecord(append(record, 0)),
+		`parquet record has more columns than the schema`)
+	require.EqualError(t, w.AddRecord([]byte{0, 0, 0}),
+		`null value in required parquet column __crdb__deleted`)
+
+	// Invalid records are not added.
+	require.Equal(t, len(record), w.Len())
+	require.Equal(t, int64(1), w.w.NumRows())
+}
@@ -53,6 +53,9 @@ type poller struct {
 	leaseMgr  *sql.LeaseManager
 	metrics   *Metrics
 	mm        *mon.BytesMonitor
+	// withDiff is true if the changefeed was created with the diff option, in
+	// which case changed kvs are buffered along with their previous values.
+	withDiff bool
 
 	mu struct {
 		syncutil.Mutex
@@ -99,6 +102,7 @@ func makePoller(
 		metrics:  metrics,
 		mm:       mm,
 	}
+	_, p.withDiff = details.Opts[optDiff]
 	p.mu.previousTableVersion = make(map[sqlbase.ID]*sqlbase.TableDescriptor)
 	// If no highWater is specified, set the highwater to the statement time
 	// and add a scanBoundary at the statement time to trigger an immediate output
@@ -153,9 +157,13 @@ func (p *poller) rangefeedImpl(ctx context.Context) error {
 		}
 		p.mu.Unlock()
 		if scanTime != (hlc.Timestamp{}) {
+			// Only the scans for backfills have previous values; the initial scan,
+			// which is the only one at the statement time, emits every row as if
+			// it was new.
+			withDiff := p.withDiff && scanTime != p.details.StatementTime
 			// TODO(dan): Now that we no longer have the poller, we should stop using
 			// ExportRequest and start using normal Scans.
-			if err := p.exportSpansParallel(ctx, spans, scanTime); err != nil {
+			if err := p.exportSpansParallel(ctx, spans, scanTime, withDiff); err != nil {
 				return err
 			}
 		}
@@ -206,7 +214,7 @@ func (p *poller) rangefeedImpl(ctx context.Context) error {
 			span := span
 			frontier.Forward(span, rangeFeedStartTS)
 			g.GoCtx(func(ctx context.Context) error {
-				return ds.RangeFeed(ctx, span, rangeFeedStartTS, eventC)
+				return ds.RangeFeed(ctx, span, rangeFeedStartTS, p.withDiff, eventC)
 			})
 		}
 		g.GoCtx(func(ctx context.Context) error {
@@ -216,7 +224,7 @@ func (p *poller) rangefeedImpl(ctx context.Context) error {
 					switch t := e.GetValue().(type) {
 					case *roachpb.RangeFeedValue:
 						kv := roachpb.KeyValue{Key: t.Key, Value: t.Value}
-						if err := memBuf.AddKV(ctx, kv, hlc.Timestamp{}); err != nil {
+						if err := memBuf.AddKV(ctx, kv, t.PrevValue, hlc.Timestamp{}); err != nil {
 							return err
 						}
 					case *roachpb.RangeFeedCheckpoint:
@@ -258,7 +266,7 @@ func (p *poller) rangefeedImpl(ctx context.Context) error {
 					if pastBoundary {
 						continue
 					}
-					if err := p.buf.AddKV(ctx, e.kv, e.schemaTimestamp); err != nil {
+					if err := p.buf.AddKV(ctx, e.kv, e.prevVal, e.schemaTimestamp); err != nil {
 						return err
 					}
 				} else if e.resolved != nil {
@@ -346,7 +354,7 @@ func getSpansToProcess(
 }
 
 func (p *poller) exportSpansParallel(
-	ctx context.Context, spans []roachpb.Span, ts hlc.Timestamp,
+	ctx context.Context, spans []roachpb.Span, ts hlc.Timestamp, withDiff bool,
 ) error {
 	// Export requests for the various watched spans are executed in parallel,
 	// with a semaphore-enforced limit based on a cluster setting.
@@ -371,7 +379,7 @@ func (p *poller) exportSpansParallel(
 		g.GoCtx(func(ctx context.Context) error {
 			defer func() { <-exportsSem }()
 
-			err := p.exportSpan(ctx, span, ts)
+			err := p.exportSpan(ctx, span, ts, withDiff)
 			finished := atomic.AddInt64(&atomicFinished, 1)
 			if log.V(2) {
 				log.Infof(ctx, `exported %d of %d`, finished, len(spans))
@@ -385,7 +393,9 @@ func (p *poller) exportSpansParallel(
 	return g.Wait()
 }
 
-func (p *poller) exportSpan(ctx context.Context, span roachpb.Span, ts hlc.Timestamp) error {
+func (p *poller) exportSpan(
+	ctx context.Context, span roachpb.Span, ts hlc.Timestamp, withDiff bool,
+) error {
 	sender := p.db.NonTransactionalSender()
 	if log.V(2) {
 		log.Infof(ctx, `sending ExportRequest %s at %s`, span, ts)
@@ -423,7 +433,7 @@ func (p *poller) exportSpan(ctx context.Context, span roachpb.Span, ts hlc.Times
 	schemaTimestamp := ts
 	stopwatchStart = timeutil.Now()
 	for _, file := range exported.(*roachpb.ExportResponse).Files {
-		if err := p.slurpSST(ctx, file.SST, schemaTimestamp); err != nil {
+		if err := p.slurpSST(ctx, file.SST, schemaTimestamp, withDiff); err != nil {
 			return err
 		}
 	}
@@ -465,13 +475,24 @@ func (p *poller) pollTableHistory(ctx context.Context) error {
 
 // slurpSST iterates an encoded sst and inserts the contained kvs into the
 // buffer.
-func (p *poller) slurpSST(ctx context.Context, sst []byte, schemaTimestamp hlc.Timestamp) error {
+//
+// If withDiff is true, the sst is the scan for a backfill and each kv is
+// buffered with itself as its previous value, to be read with the schema from
+// just before the backfill.
+func (p *poller) slurpSST(
+	ctx context.Context, sst []byte, schemaTimestamp hlc.Timestamp, withDiff bool,
+) error {
 	var previousKey roachpb.Key
 	var kvs []roachpb.KeyValue
 	slurpKVs := func() error {
 		sort.Sort(byValueTimestamp(kvs))
 		for _, kv := range kvs {
-			if err := p.buf.AddKV(ctx, kv, schemaTimestamp); err != nil {
+			var prevVal roachpb.Value
+			if withDiff {
+				prevVal = kv.Value
+				prevVal.Timestamp = schemaTimestamp.Prev()
+			}
+			if err := p.buf.AddKV(ctx, kv, prevVal, schemaTimestamp); err != nil {
 				return err
 			}
 		}
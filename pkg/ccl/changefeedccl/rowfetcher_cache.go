@@ -98,7 +98,8 @@ func (c *rowFetcherCache) RowFetcherForTableDesc(
 
 	var rf row.Fetcher
 	if err := rf.Init(
-		false /* reverse */, false /* returnRangeInfo */, false /* isCheck */, &c.a,
+		false /* reverse */, false /* returnRangeInfo */, false, /* isCheck */
+		roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, &c.a,
 		row.FetcherTableArgs{
 			Spans:            tableDesc.AllIndexSpans(),
 			Desc:             tableDesc,
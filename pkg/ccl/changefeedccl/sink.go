@@ -154,7 +154,17 @@ func getSink(
 			}
 		}
 
+		if exactlyOnceParam := q.Get(sinkParamExactlyOnce); exactlyOnceParam != `` {
+			if cfg.exactlyOnce, err = strconv.ParseBool(exactlyOnceParam); err != nil {
+				return nil, errors.Errorf(`param %s must be a bool: %s`, sinkParamExactlyOnce, err)
+			}
+		}
+		q.Del(sinkParamExactlyOnce)
+
 		makeSink = func() (Sink, error) {
+			if cfg.exactlyOnce {
+				return makeKafkaTxnSink(cfg, u.Host, targets)
+			}
 			return makeKafkaSink(cfg, u.Host, targets)
 		}
 	case isCloudStorageSink(u):
@@ -189,10 +199,53 @@ func getSink(
 		q.Del(`sslkey`)
 		q.Del(`sslmode`)
 		q.Del(`sslrootcert`)
+	case u.Scheme == sinkSchemeWebhookHTTPS:
+		var cfg webhookSinkConfig
+		for _, param := range []struct {
+			name string
+			dst  *[]byte
+		}{
+			{sinkParamCACert, &cfg.caCert},
+			{sinkParamClientCert, &cfg.clientCert},
+			{sinkParamClientKey, &cfg.clientKey},
+		} {
+			if encoded := q.Get(param.name); encoded != `` {
+				if *param.dst, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+					return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, param.name, err)
+				}
+			}
+			q.Del(param.name)
+		}
+		if (cfg.clientCert == nil) != (cfg.clientKey == nil) {
+			return nil, errors.Errorf(`%s and %s must be provided together`,
+				sinkParamClientCert, sinkParamClientKey)
+		}
+		cfg.batchSize = defaultWebhookBatchSize
+		if batchSizeParam := q.Get(sinkParamBatchSize); batchSizeParam != `` {
+			if cfg.batchSize, err = strconv.Atoi(batchSizeParam); err != nil || cfg.batchSize <= 0 {
+				return nil, errors.Errorf(`param %s must be a positive integer: %s`,
+					sinkParamBatchSize, batchSizeParam)
+			}
+		}
+		q.Del(sinkParamBatchSize)
+		cfg.authHeader = opts[optWebhookAuthHeader]
+
+		// None of the sink parameters are meant for the endpoint.
+		postURL := *u
+		postURL.Scheme = `https`
+		postURL.RawQuery = ``
+		makeSink = func() (Sink, error) {
+			return makeWebhookSink(postURL.String(), cfg, opts)
+		}
 	default:
 		return nil, errors.Errorf(`unsupported sink: %s`, u.Scheme)
 	}
 
+	if _, ok := opts[optWebhookAuthHeader]; ok && u.Scheme != sinkSchemeWebhookHTTPS {
+		return nil, errors.Errorf(`%s is only supported by %s sinks`,
+			optWebhookAuthHeader, sinkSchemeWebhookHTTPS)
+	}
+
 	for k := range q {
 		return nil, errors.Errorf(`unknown sink query parameter: %s`, k)
 	}
@@ -276,6 +329,7 @@ type kafkaSinkConfig struct {
 	saslHandshake    bool
 	saslUser         string
 	saslPassword     string
+	exactlyOnce      bool
 }
 
 // kafkaSink emits to Kafka asynchronously. It is not concurrency-safe; all
@@ -310,32 +364,13 @@ func makeKafkaSink(
 		sink.topics[cfg.kafkaTopicPrefix+SQLNameToKafkaName(t.StatementTimeName)] = struct{}{}
 	}
 
-	config := sarama.NewConfig()
-	config.ClientID = `CockroachDB`
+	config, err := makeKafkaConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
 	config.Producer.Return.Successes = true
 	config.Producer.Partitioner = newChangefeedPartitioner
 
-	if cfg.caCert != nil {
-		if !cfg.tlsEnabled {
-			return nil, errors.Errorf(`%s requires %s=true`, sinkParamCACert, sinkParamTLSEnabled)
-		}
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(cfg.caCert)
-		config.Net.TLS.Config = &tls.Config{
-			RootCAs: caCertPool,
-		}
-		config.Net.TLS.Enable = true
-	} else if cfg.tlsEnabled {
-		config.Net.TLS.Enable = true
-	}
-
-	if cfg.saslEnabled {
-		config.Net.SASL.Enable = true
-		config.Net.SASL.Handshake = cfg.saslHandshake
-		config.Net.SASL.User = cfg.saslUser
-		config.Net.SASL.Password = cfg.saslPassword
-	}
-
 	// When we emit messages to sarama, they're placed in a queue (as does any
 	// reasonable kafka producer client). When our sink's Flush is called, we
 	// have to wait for all buffered and inflight requests to be sent and then
@@ -371,7 +406,6 @@ func makeKafkaSink(
 	// sarama prints scary things to the logs if we don't.
 	config.Producer.Flush.Frequency = time.Hour
 
-	var err error
 	sink.client, err = sarama.NewClient(strings.Split(bootstrapServers, `,`), config)
 	if err != nil {
 		err = pgerror.Wrapf(err, pgcode.CannotConnectNow,
@@ -389,6 +423,35 @@ func makeKafkaSink(
 	return sink, nil
 }
 
+// makeKafkaConfig returns the sarama configuration, with the connection
+// settings from cfg, shared by all kafka sinks.
+func makeKafkaConfig(cfg kafkaSinkConfig) (*sarama.Config, error) {
+	config := sarama.NewConfig()
+	config.ClientID = `CockroachDB`
+
+	if cfg.caCert != nil {
+		if !cfg.tlsEnabled {
+			return nil, errors.Errorf(`%s requires %s=true`, sinkParamCACert, sinkParamTLSEnabled)
+		}
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(cfg.caCert)
+		config.Net.TLS.Config = &tls.Config{
+			RootCAs: caCertPool,
+		}
+		config.Net.TLS.Enable = true
+	} else if cfg.tlsEnabled {
+		config.Net.TLS.Enable = true
+	}
+
+	if cfg.saslEnabled {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Handshake = cfg.saslHandshake
+		config.Net.SASL.User = cfg.saslUser
+		config.Net.SASL.Password = cfg.saslPassword
+	}
+	return config, nil
+}
+
 func (s *kafkaSink) start() {
 	s.stopWorkerCh = make(chan struct{})
 	s.worker.Add(1)
@@ -12,7 +12,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"net/url"
 	"path/filepath"
 	"sync/atomic"
@@ -53,7 +52,42 @@ type cloudStorageSinkKey struct {
 
 type cloudStorageSinkFile struct {
 	earliestTs hlc.Timestamp
-	buf        bytes.Buffer
+	w          cloudStorageFileWriter
+}
+
+// cloudStorageFileWriter accumulates the records of a data file and produces
+// the file in one of the formats supported by cloudStorageSink.
+type cloudStorageFileWriter interface {
+	// AddRecord appends a record, as returned by the Encoder, to the file.
+	AddRecord(record []byte) error
+	// Len returns the size of the records added so far.
+	Len() int
+	// Contents returns the contents of the file.
+	Contents() ([]byte, error)
+}
+
+// ndjsonFileWriter writes one record per line.
+type ndjsonFileWriter struct {
+	buf bytes.Buffer
+}
+
+var _ cloudStorageFileWriter = &ndjsonFileWriter{}
+
+// AddRecord implements the cloudStorageFileWriter interface.
+func (w *ndjsonFileWriter) AddRecord(record []byte) error {
+	w.buf.Write(record)
+	w.buf.WriteByte('\n')
+	return nil
+}
+
+// Len implements the cloudStorageFileWriter interface.
+func (w *ndjsonFileWriter) Len() int {
+	return w.buf.Len()
+}
+
+// Contents implements the cloudStorageFileWriter interface.
+func (w *ndjsonFileWriter) Contents() ([]byte, error) {
+	return w.buf.Bytes(), nil
 }
 
 // cloudStorageSink emits to files on cloud storage.
@@ -78,9 +112,10 @@ type cloudStorageSinkFile struct {
 // cloudStorageSink in a running process and `<file_id>` is a unique id for each
 // file written by a given `<sink_id>`.
 //
-// `<ext>` implies the format of the file: currently the only option is
-// `ndjson`, which means a text file conforming to the "Newline Delimited JSON"
-// spec.
+// `<ext>` implies the format of the file: `ndjson` means a text file conforming
+// to the "Newline Delimited JSON" spec, `avro` means an Avro object container
+// file, which embeds the Avro schema of its records, and `parquet` means an
+// Apache Parquet file.
 //
 // Each record in the data files is a value, keys are not included, so the
 // `envelope` option must be set to `value_only`. Within a file, records are not
@@ -95,7 +130,7 @@ type cloudStorageSinkFile struct {
 // deleted, included in hive queries, etc). A typical user of cloudStorageSink
 // would periodically do exactly this.
 //
-// Still TODO is writing out data schemas, Avro support, bounding memory usage.
+// Still TODO is bounding memory usage.
 type cloudStorageSink struct {
 	nodeID            roachpb.NodeID
 	sinkID            int64
@@ -103,8 +138,8 @@ type cloudStorageSink struct {
 	settings          *cluster.Settings
 	partitionFormat   string
 
-	ext           string
-	recordDelimFn func(io.Writer) error
+	ext       string
+	newFileFn func(*sqlbase.TableDescriptor) (cloudStorageFileWriter, error)
 
 	es     storageccl.ExportStorage
 	fileID int64
@@ -139,9 +174,28 @@ func makeCloudStorageSink(
 		// TODO(dan): It seems like these should be on the encoder, but that
 		// would require a bit of refactoring.
 		s.ext = `.ndjson`
-		s.recordDelimFn = func(w io.Writer) error {
-			_, err := w.Write([]byte{'\n'})
-			return err
+		s.newFileFn = func(*sqlbase.TableDescriptor) (cloudStorageFileWriter, error) {
+			return &ndjsonFileWriter{}, nil
+		}
+	case optFormatAvroOCF:
+		_, updatedField := opts[optUpdatedTimestamps]
+		s.ext = `.avro`
+		s.newFileFn = func(table *sqlbase.TableDescriptor) (cloudStorageFileWriter, error) {
+			schema, err := avroOCFSchema(table, updatedField)
+			if err != nil {
+				return nil, err
+			}
+			return makeAvroOCFFileWriter(schema)
+		}
+	case optFormatParquet:
+		_, updatedField := opts[optUpdatedTimestamps]
+		s.ext = `.parquet`
+		s.newFileFn = func(table *sqlbase.TableDescriptor) (cloudStorageFileWriter, error) {
+			schema, err := makeParquetSchema(table, updatedField)
+			if err != nil {
+				return nil, err
+			}
+			return makeParquetFileWriter(schema), nil
 		}
 	default:
 		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
@@ -184,7 +238,11 @@ func (s *cloudStorageSink) EmitRow(
 	if file == nil {
 		// We could pool the bytes.Buffers if necessary, but we'd need to be
 		// careful to bound the size of the memory held by the pool.
-		file = &cloudStorageSinkFile{}
+		w, err := s.newFileFn(table)
+		if err != nil {
+			return err
+		}
+		file = &cloudStorageSinkFile{w: w}
 		s.files[key] = file
 	}
 	if file.earliestTs.IsEmpty() || updated.Less(file.earliestTs) {
@@ -192,14 +250,11 @@ func (s *cloudStorageSink) EmitRow(
 	}
 
 	// TODO(dan): Memory monitoring for this
-	if _, err := file.buf.Write(value); err != nil {
-		return err
-	}
-	if err := s.recordDelimFn(&file.buf); err != nil {
+	if err := file.w.AddRecord(value); err != nil {
 		return err
 	}
 
-	if int64(file.buf.Len()) > s.targetMaxFileSize {
+	if int64(file.w.Len()) > s.targetMaxFileSize {
 		if err := s.flushFile(ctx, key, file); err != nil {
 			return err
 		}
@@ -251,7 +306,7 @@ func (s *cloudStorageSink) Flush(ctx context.Context) error {
 func (s *cloudStorageSink) flushFile(
 	ctx context.Context, key cloudStorageSinkKey, file *cloudStorageSinkFile,
 ) error {
-	if file.buf.Len() == 0 {
+	if file.w.Len() == 0 {
 		// This method shouldn't be called with an empty file, but be defensive
 		// about not writing empty files anyway.
 		return nil
@@ -266,7 +321,11 @@ func (s *cloudStorageSink) flushFile(
 	if log.V(1) {
 		log.Info(ctx, "writing ", filename)
 	}
-	return s.es.WriteFile(ctx, filepath.Join(part, filename), bytes.NewReader(file.buf.Bytes()))
+	contents, err := file.w.Contents()
+	if err != nil {
+		return err
+	}
+	return s.es.WriteFile(ctx, filepath.Join(part, filename), bytes.NewReader(contents))
 }
 
 // Close implements the Sink interface.
@@ -10,18 +10,23 @@ package changefeedccl
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/linkedin/goavro"
 	"github.com/stretchr/testify/require"
 )
 
@@ -336,4 +341,136 @@ func TestCloudStorageSink(t *testing.T) {
 			`{"resolved":"4.0000000000"}`,
 		}, slurpDir(t, dir))
 	})
+	t.Run(`avro`, func(t *testing.T) {
+		avroOpts := map[string]string{
+			optFormat:     string(optFormatAvroOCF),
+			optEnvelope:   string(optEnvelopeWrapped),
+			optKeyInValue: ``,
+		}
+		e, err := getEncoder(avroOpts)
+		require.NoError(t, err)
+		dir := `avro`
+		s, err := makeCloudStorageSink(`nodelocal:///`+dir, 1, unlimitedFileSize, settings, avroOpts)
+		require.NoError(t, err)
+
+		v1 := makeCloudStorageTestTable(1)
+		require.NoError(t, emitCloudStorageTestRow(ctx, s, e, v1, false /* deleted */, 1, `a`))
+		require.NoError(t, emitCloudStorageTestRow(ctx, s, e, v1, true /* deleted */, 2, ``))
+		// A schema change starts a new file.
+		v2 := makeCloudStorageTestTable(2)
+		v2.Columns = append(v2.Columns, sqlbase.ColumnDescriptor{
+			ID: 3, Name: `c`, Type: *types.Bool, Nullable: true,
+		})
+		require.NoError(t, emitCloudStorageTestRow(ctx, s, e, v2, false /* deleted */, 3, `c`, true))
+		require.NoError(t, s.Flush(ctx))
+
+		var files [][]interface{}
+		for _, file := range slurpDir(t, dir) {
+			r, err := goavro.NewOCFReader(strings.NewReader(file))
+			require.NoError(t, err)
+			var records []interface{}
+			for r.Scan() {
+				record, err := r.Read()
+				require.NoError(t, err)
+				records = append(records, record)
+			}
+			require.NoError(t, r.Err())
+			files = append(files, records)
+		}
+		key := func(k int64) interface{} {
+			return map[string]interface{}{`t1_key`: map[string]interface{}{
+				`k`: map[string]interface{}{`long`: k},
+			}}
+		}
+		require.Equal(t, [][]interface{}{{
+			map[string]interface{}{`key`: key(1), `after`: map[string]interface{}{`t1`: map[string]interface{}{
+				`k`: map[string]interface{}{`long`: int64(1)},
+				`s`: map[string]interface{}{`string`: `a`},
+			}}},
+			map[string]interface{}{`key`: key(2), `after`: nil},
+		}, {
+			map[string]interface{}{`key`: key(3), `after`: map[string]interface{}{`t1`: map[string]interface{}{
+				`k`: map[string]interface{}{`long`: int64(3)},
+				`s`: map[string]interface{}{`string`: `c`},
+				`c`: map[string]interface{}{`boolean`: true},
+			}}},
+		}}, files)
+	})
+	t.Run(`parquet`, func(t *testing.T) {
+		parquetOpts := map[string]string{
+			optFormat:            string(optFormatParquet),
+			optEnvelope:          string(optEnvelopeWrapped),
+			optKeyInValue:        ``,
+			optUpdatedTimestamps: ``,
+		}
+		e, err := getEncoder(parquetOpts)
+		require.NoError(t, err)
+		sinkDir := `parquet`
+		s, err := makeCloudStorageSink(`nodelocal:///`+sinkDir, 1, unlimitedFileSize, settings, parquetOpts)
+		require.NoError(t, err)
+		s.(*cloudStorageSink).sinkID = 7 // Force a deterministic sinkID.
+
+		t1 := makeCloudStorageTestTable(1)
+		require.NoError(t, emitCloudStorageTestRow(ctx, s, e, t1, false /* deleted */, 1, `a`))
+		require.NoError(t, emitCloudStorageTestRow(ctx, s, e, t1, true /* deleted */, 2, ``))
+		require.NoError(t, s.Flush(ctx))
+
+		file, err := ioutil.ReadFile(filepath.Join(
+			dir, sinkDir, `1970-01-01`, `197001010000000000000010000000000-t1-1-1-7-0.parquet`))
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(file), `PAR1`))
+		require.True(t, strings.HasSuffix(string(file), `PAR1`))
+		footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+		footer := string(file[len(file)-8-footerLen : len(file)-8])
+		for _, column := range []string{`k`, `s`, parquetUpdatedColumnName, parquetDeletedColumnName} {
+			require.Contains(t, footer, column)
+		}
+	})
+}
+
+// makeCloudStorageTestTable returns the descriptor of a table `t1` with an INT
+// primary key column `k` and a STRING column `s`.
+func makeCloudStorageTestTable(version sqlbase.DescriptorVersion) *sqlbase.TableDescriptor {
+	return &sqlbase.TableDescriptor{
+		Name:    `t1`,
+		ID:      52,
+		Version: version,
+		Columns: []sqlbase.ColumnDescriptor{
+			{ID: 1, Name: `k`, Type: *types.Int},
+			{ID: 2, Name: `s`, Type: *types.String, Nullable: true},
+		},
+		PrimaryIndex: sqlbase.IndexDescriptor{
+			Name: `primary`, ID: 1, ColumnIDs: []sqlbase.ColumnID{1},
+		},
+	}
+}
+
+// emitCloudStorageTestRow encodes a row of a table created by
+// makeCloudStorageTestTable, with any extra BOOL columns, and emits it to the
+// sink at timestamp 1.
+func emitCloudStorageTestRow(
+	ctx context.Context,
+	s Sink,
+	e Encoder,
+	table *sqlbase.TableDescriptor,
+	deleted bool,
+	k int64,
+	str string,
+	extra ...bool,
+) error {
+	row := sqlbase.EncDatumRow{
+		sqlbase.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(k))),
+		sqlbase.DatumToEncDatum(types.String, tree.NewDString(str)),
+	}
+	for _, b := range extra {
+		row = append(row, sqlbase.DatumToEncDatum(types.Bool, tree.MakeDBool(tree.DBool(b))))
+	}
+	updated := hlc.Timestamp{WallTime: 1}
+	value, err := e.EncodeValue(encodeRow{
+		datums: row, updated: updated, deleted: deleted, tableDesc: table,
+	})
+	if err != nil {
+		return err
+	}
+	return s.EmitRow(ctx, table, nil /* key */, value, updated)
 }
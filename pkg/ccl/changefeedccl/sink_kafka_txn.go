@@ -0,0 +1,804 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/Shopify/sarama"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	// kafkaTxnTimeout is the transaction timeout requested from the kafka
+	// transaction coordinator. A transaction is only open while commitResolved
+	// produces and commits one resolved timestamp's worth of rows.
+	kafkaTxnTimeout = time.Minute
+	// kafkaTxnMaxBatchRecords is the most records of one partition sent in a
+	// single produce request. It mirrors the `Flush.MaxMessages` workaround in
+	// makeKafkaSink.
+	kafkaTxnMaxBatchRecords = 1000
+)
+
+type kafkaTopicPartition struct {
+	topic     string
+	partition int32
+}
+
+// txnProducer is the transactional kafka producer used by kafkaTxnSink. It is
+// an interface so that tests can mock it.
+type txnProducer interface {
+	// initTxns gets a new producer epoch for the transactional id, which fences
+	// off any previous producer using it and aborts its open transaction.
+	initTxns() (producerID int64, producerEpoch int16, err error)
+	// partitions returns the partitions of a topic.
+	partitions(topic string) ([]int32, error)
+	// produce sends records in the open transaction, starting one if
+	// necessary, and waits for them to be acknowledged.
+	produce(records map[kafkaTopicPartition][]*sarama.Record) error
+	// commitTxn commits the open transaction, if any.
+	commitTxn() error
+	// commitPrepared commits the transaction left open by a previous producer
+	// with the given id and epoch, if it is still open. It returns whether that
+	// transaction is committed, which is false if it was aborted instead.
+	commitPrepared(producerID int64, producerEpoch int16) (bool, error)
+	// Close aborts the open transaction, if any, and releases the connection to
+	// the transaction coordinator.
+	Close() error
+}
+
+// kafkaTxnSink emits to kafka with exactly-once delivery for consumers that
+// read with `isolation.level=read_committed`. Instead of handing rows to the
+// producer as they're emitted, it holds them until the span-level resolved
+// timestamps of all its watched spans have passed their updated timestamp,
+// then commitResolved produces everything at or below that timestamp in a
+// single kafka transaction.
+//
+// The timestamp a transaction commits rows through is recorded in the job
+// before the transaction is committed, and the commit is recorded after. This
+// ties the kafka commit to the job progress: before the changefeed flow is
+// started again, recoverKafkaTxns finishes any commit that was interrupted (or
+// finds the transaction aborted) and each span is then only watched from the
+// timestamp its rows were committed through.
+//
+// The changeAggregator and changeFrontier processors each begin their own
+// producer with a distinct transactional id. It is not concurrency-safe.
+type kafkaTxnSink struct {
+	cfg          kafkaSinkConfig
+	client       sarama.Client
+	topics       map[string]struct{}
+	partitioners map[string]sarama.Partitioner
+	newProducer  func(txnID string) txnProducer
+
+	producer txnProducer
+	// checkpointFn, if non-nil, records the producer state in the job.
+	checkpointFn func(context.Context, jobspb.ChangefeedKafkaTransaction) error
+	state        jobspb.ChangefeedKafkaTransaction
+
+	// held contains the emitted rows that have not been produced yet, in the
+	// order they were emitted.
+	held []kafkaTxnRow
+	// memAcc, if non-nil, is charged for the held rows. The rows can't be
+	// produced before they're resolved, so if they don't fit in its budget, the
+	// changefeed fails instead.
+	memAcc  *mon.BoundAccount
+	scratch bufalloc.ByteAllocator
+}
+
+type kafkaTxnRow struct {
+	topic      string
+	key, value []byte
+	updated    hlc.Timestamp
+}
+
+const sizeOfKafkaTxnRow = int64(unsafe.Sizeof(kafkaTxnRow{}))
+
+// memSize returns the memory the held row is charged for.
+func (r *kafkaTxnRow) memSize() int64 {
+	return sizeOfKafkaTxnRow + int64(len(r.key)+len(r.value))
+}
+
+func makeKafkaTxnSink(
+	cfg kafkaSinkConfig, bootstrapServers string, targets jobspb.ChangefeedTargets,
+) (Sink, error) {
+	sink := &kafkaTxnSink{
+		cfg:          cfg,
+		partitioners: make(map[string]sarama.Partitioner),
+	}
+	sink.topics = make(map[string]struct{})
+	for _, t := range targets {
+		sink.topics[cfg.kafkaTopicPrefix+SQLNameToKafkaName(t.StatementTimeName)] = struct{}{}
+	}
+
+	config, err := makeKafkaConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// Transactions and the record batches that carry producer ids require
+	// kafka 0.11.
+	config.Version = sarama.V0_11_0_0
+	config.Producer.RequiredAcks = sarama.WaitForAll
+
+	sink.client, err = sarama.NewClient(strings.Split(bootstrapServers, `,`), config)
+	if err != nil {
+		err = pgerror.Wrapf(err, pgcode.CannotConnectNow,
+			`connecting to kafka: %s`, bootstrapServers)
+		return nil, err
+	}
+	sink.newProducer = func(txnID string) txnProducer {
+		return &kafkaTxnProducer{client: sink.client, txnID: txnID}
+	}
+	return sink, nil
+}
+
+// begin starts the sink's producer with the given transactional id. resolved
+// contains the spans whose rows are emitted to the sink, each with the
+// timestamp through which their rows have already been committed. If
+// checkpointFn is non-nil, it is used to record the producer state in the job
+// whenever it changes.
+func (s *kafkaTxnSink) begin(
+	ctx context.Context,
+	txnID string,
+	resolved []jobspb.ResolvedSpan,
+	checkpointFn func(context.Context, jobspb.ChangefeedKafkaTransaction) error,
+) error {
+	s.producer = s.newProducer(txnID)
+	producerID, producerEpoch, err := s.producer.initTxns()
+	if err != nil {
+		return err
+	}
+	s.checkpointFn = checkpointFn
+	s.state = jobspb.ChangefeedKafkaTransaction{
+		TransactionalID: txnID,
+		ProducerID:      producerID,
+		ProducerEpoch:   int32(producerEpoch),
+		ResolvedSpans:   resolved,
+	}
+	return s.checkpoint(ctx)
+}
+
+func (s *kafkaTxnSink) checkpoint(ctx context.Context) error {
+	if s.checkpointFn == nil {
+		return nil
+	}
+	return s.checkpointFn(ctx, s.state)
+}
+
+// EmitRow implements the Sink interface.
+func (s *kafkaTxnSink) EmitRow(
+	ctx context.Context, table *sqlbase.TableDescriptor, key, value []byte, updated hlc.Timestamp,
+) error {
+	topic := s.cfg.kafkaTopicPrefix + SQLNameToKafkaName(table.Name)
+	if _, ok := s.topics[topic]; !ok {
+		return errors.Errorf(`cannot emit to undeclared topic: %s`, topic)
+	}
+	row := kafkaTxnRow{topic: topic, key: key, value: value, updated: updated}
+	if s.memAcc != nil {
+		if err := s.memAcc.Grow(ctx, row.memSize()); err != nil {
+			return errors.Wrap(err, `holding rows until they're resolved`)
+		}
+	}
+	s.held = append(s.held, row)
+	return nil
+}
+
+// commitResolved commits every held row with an updated timestamp at or below
+// resolved to kafka in a single transaction. The caller guarantees that all
+// such rows have been emitted, i.e. that resolved is at or below the
+// span-level resolved timestamps of all the watched spans. It returns the
+// watched spans, each with the timestamp through which its rows have now been
+// committed.
+func (s *kafkaTxnSink) commitResolved(
+	ctx context.Context, resolved hlc.Timestamp,
+) ([]jobspb.ResolvedSpan, error) {
+	if s.producer == nil {
+		return nil, errors.New(`kafka sink with exactly-once delivery was not started`)
+	}
+	records := make(map[kafkaTopicPartition][]*sarama.Record)
+	remaining := s.held[:0]
+	var released int64
+	for _, row := range s.held {
+		if resolved.Less(row.updated) {
+			remaining = append(remaining, row)
+			continue
+		}
+		released += row.memSize()
+		partition, err := s.partition(row.topic, row.key)
+		if err != nil {
+			return nil, err
+		}
+		tp := kafkaTopicPartition{topic: row.topic, partition: partition}
+		records[tp] = append(records[tp], &sarama.Record{Key: row.key, Value: row.value})
+	}
+	for i := len(remaining); i < len(s.held); i++ {
+		s.held[i] = kafkaTxnRow{}
+	}
+	s.held = remaining
+	if s.memAcc != nil {
+		s.memAcc.Shrink(ctx, released)
+	}
+
+	// produce consumes records.
+	produced := len(records) > 0
+	if produced {
+		if err := s.producer.produce(records); err != nil {
+			return nil, err
+		}
+		// Record what the transaction commits before committing it. If the
+		// commit is interrupted, recoverKafkaTxns finishes it using this.
+		s.state.Prepared = resolved
+		if err := s.checkpoint(ctx); err != nil {
+			return nil, err
+		}
+		if err := s.producer.commitTxn(); err != nil {
+			return nil, err
+		}
+	}
+	for i := range s.state.ResolvedSpans {
+		s.state.ResolvedSpans[i].Timestamp.Forward(resolved)
+	}
+	if produced {
+		s.state.Prepared = hlc.Timestamp{}
+		if err := s.checkpoint(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return append([]jobspb.ResolvedSpan(nil), s.state.ResolvedSpans...), nil
+}
+
+// partition mirrors the partitioning of keyed messages by kafkaSink.
+func (s *kafkaTxnSink) partition(topic string, key []byte) (int32, error) {
+	partitions, err := s.producer.partitions(topic)
+	if err != nil {
+		return 0, err
+	}
+	partitioner, ok := s.partitioners[topic]
+	if !ok {
+		partitioner = sarama.NewHashPartitioner(topic)
+		s.partitioners[topic] = partitioner
+	}
+	msg := &sarama.ProducerMessage{Topic: topic, Key: sarama.ByteEncoder(key)}
+	choice, err := partitioner.Partition(msg, int32(len(partitions)))
+	if err != nil {
+		return 0, err
+	}
+	return partitions[choice], nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface. Unlike rows, the
+// resolved timestamp messages are committed immediately.
+func (s *kafkaTxnSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	if s.producer == nil {
+		return errors.New(`kafka sink with exactly-once delivery was not started`)
+	}
+	records := make(map[kafkaTopicPartition][]*sarama.Record)
+	for topic := range s.topics {
+		payload, err := encoder.EncodeResolvedTimestamp(topic, resolved)
+		if err != nil {
+			return err
+		}
+		s.scratch, payload = s.scratch.Copy(payload, 0 /* extraCap */)
+
+		partitions, err := s.producer.partitions(topic)
+		if err != nil {
+			return err
+		}
+		for _, partition := range partitions {
+			tp := kafkaTopicPartition{topic: topic, partition: partition}
+			records[tp] = append(records[tp], &sarama.Record{Value: payload})
+		}
+	}
+	if err := s.producer.produce(records); err != nil {
+		return err
+	}
+	return s.producer.commitTxn()
+}
+
+// Flush implements the Sink interface. Rows are only handed to kafka by
+// commitResolved, once their updated timestamp is resolved, and resolved
+// timestamps are committed as they're emitted, so there is nothing to flush.
+func (s *kafkaTxnSink) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Close implements the Sink interface.
+func (s *kafkaTxnSink) Close() error {
+	if s.producer != nil {
+		// If we're shutting down, the open transaction, if any, is discarded.
+		_ = s.producer.Close()
+	}
+	// s.client is only nil in tests.
+	if s.client != nil {
+		return s.client.Close()
+	}
+	return nil
+}
+
+// recoverTxns finishes the kafka transactions left behind by the producers of
+// a previous changefeed flow and fences off those producers in case they're
+// still running. A transaction that was prepared is committed if it's still
+// open, and the spans of its producer are forwarded to the prepared timestamp
+// if it turns out to be committed. If it was aborted instead, e.g. because it
+// timed out, its rows are emitted again by the next flow.
+func (s *kafkaTxnSink) recoverTxns(
+	ctx context.Context, txns []jobspb.ChangefeedKafkaTransaction,
+) error {
+	for i := range txns {
+		txn := &txns[i]
+		if txn.TransactionalID == `` {
+			continue
+		}
+		if err := func() error {
+			p := s.newProducer(txn.TransactionalID)
+			defer func() { _ = p.Close() }()
+			if !txn.Prepared.IsEmpty() {
+				committed, err := p.commitPrepared(txn.ProducerID, int16(txn.ProducerEpoch))
+				if err != nil {
+					return err
+				}
+				if committed {
+					for j := range txn.ResolvedSpans {
+						txn.ResolvedSpans[j].Timestamp.Forward(txn.Prepared)
+					}
+				} else {
+					log.Infof(ctx, `kafka transaction of %s preparing %s was aborted`,
+						txn.TransactionalID, txn.Prepared)
+				}
+				txn.Prepared = hlc.Timestamp{}
+			}
+			_, _, err := p.initTxns()
+			return err
+		}(); err != nil {
+			return errors.Wrapf(err, `recovering kafka transactions of %s`, txn.TransactionalID)
+		}
+	}
+	return nil
+}
+
+// recoverKafkaTxns prepares the progress of a changefeed with exactly-once
+// kafka delivery for its flow to be (re)started. The transactions of the
+// previous flow are recovered and the spans they committed past the job
+// high-water are merged into a single entry without a producer, which
+// distChangefeedFlow uses to start each watched span after its committed rows.
+// The new flow's producers record their own entries as they start.
+func recoverKafkaTxns(
+	ctx context.Context,
+	job *jobs.Job,
+	settings *cluster.Settings,
+	details jobspb.ChangefeedDetails,
+	progress jobspb.Progress,
+) (jobspb.Progress, error) {
+	cfProgress := progress.GetChangefeed()
+	if cfProgress == nil || len(cfProgress.KafkaTransactions) == 0 {
+		return progress, nil
+	}
+	sink, err := getSink(details.SinkURI, 0 /* nodeID */, details.Opts, details.Targets, settings)
+	if err != nil {
+		return progress, MarkRetryableError(err)
+	}
+	defer func() { _ = sink.Close() }()
+	txnSink, ok := sink.(*kafkaTxnSink)
+	if !ok {
+		return progress, nil
+	}
+	txns := append([]jobspb.ChangefeedKafkaTransaction(nil), cfProgress.KafkaTransactions...)
+	if err := txnSink.recoverTxns(ctx, txns); err != nil {
+		return progress, MarkRetryableError(err)
+	}
+
+	var highWater hlc.Timestamp
+	if h := progress.GetHighWater(); h != nil {
+		highWater = *h
+	}
+	var merged jobspb.ChangefeedKafkaTransaction
+	for _, txn := range txns {
+		for _, resolved := range txn.ResolvedSpans {
+			if highWater.Less(resolved.Timestamp) {
+				merged.ResolvedSpans = append(merged.ResolvedSpans, resolved)
+			}
+		}
+	}
+	var recovered []jobspb.ChangefeedKafkaTransaction
+	if len(merged.ResolvedSpans) > 0 {
+		recovered = append(recovered, merged)
+	}
+
+	if err := job.Update(ctx, func(_ *client.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+		if err := md.CheckRunning(); err != nil {
+			return err
+		}
+		md.Progress.GetChangefeed().KafkaTransactions = recovered
+		ju.UpdateProgress(md.Progress)
+		return nil
+	}); err != nil {
+		return progress, err
+	}
+	progress = *protoutil.Clone(&progress).(*jobspb.Progress)
+	progress.GetChangefeed().KafkaTransactions = recovered
+	return progress, nil
+}
+
+// kafkaTxnCheckpointer returns a function that records the state of a
+// producer of a kafkaTxnSink in the job.
+func kafkaTxnCheckpointer(
+	job *jobs.Job,
+) func(context.Context, jobspb.ChangefeedKafkaTransaction) error {
+	return func(ctx context.Context, state jobspb.ChangefeedKafkaTransaction) error {
+		return job.Update(ctx, func(_ *client.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+			if err := md.CheckRunning(); err != nil {
+				return err
+			}
+			cfProgress := md.Progress.GetChangefeed()
+			for i := range cfProgress.KafkaTransactions {
+				txn := &cfProgress.KafkaTransactions[i]
+				if txn.TransactionalID != state.TransactionalID {
+					continue
+				}
+				if txn.ProducerID != state.ProducerID || txn.ProducerEpoch > state.ProducerEpoch {
+					return errors.Errorf(`kafka producer %s was fenced by a newer producer`,
+						state.TransactionalID)
+				}
+				*txn = state
+				ju.UpdateProgress(md.Progress)
+				return nil
+			}
+			cfProgress.KafkaTransactions = append(cfProgress.KafkaTransactions, state)
+			ju.UpdateProgress(md.Progress)
+			return nil
+		})
+	}
+}
+
+// skipCommittedKVs wraps a changeAggregator's input to drop the kvs at or
+// below the initial resolved timestamp of the watch they belong to. The poller
+// starts all the watches at the lowest of these, but a kafkaTxnSink may have
+// committed the rows of some spans through a later timestamp.
+func skipCommittedKVs(
+	watches []distsqlpb.ChangeAggregatorSpec_Watch,
+	inputFn func(context.Context) (bufferEntry, error),
+) func(context.Context) (bufferEntry, error) {
+	sorted := append([]distsqlpb.ChangeAggregatorSpec_Watch(nil), watches...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Span.Key.Compare(sorted[j].Span.Key) < 0
+	})
+	return func(ctx context.Context) (bufferEntry, error) {
+		for {
+			entry, err := inputFn(ctx)
+			if err != nil || entry.kv.Key == nil {
+				return entry, err
+			}
+			key := entry.kv.Key
+			i := sort.Search(len(sorted), func(i int) bool {
+				return key.Compare(sorted[i].Span.EndKey) < 0
+			})
+			if i < len(sorted) && sorted[i].Span.ContainsKey(key) &&
+				!sorted[i].InitialResolved.Less(entry.kv.Value.Timestamp) {
+				continue
+			}
+			return entry, nil
+		}
+	}
+}
+
+// kafkaTxnProducer implements txnProducer by speaking the kafka transactional
+// producer protocol directly to the brokers, which sarama's producers don't
+// support. Every record batch it sends carries its producer id, epoch and a
+// per-partition sequence number, which lets the brokers drop retried
+// duplicates, and is part of a transaction. Any error leaves it unusable, and
+// the changefeed flow is restarted.
+type kafkaTxnProducer struct {
+	client sarama.Client
+	txnID  string
+
+	coordinator   *sarama.Broker
+	producerID    int64
+	producerEpoch int16
+	sequences     map[kafkaTopicPartition]int32
+	// txnPartitions are the partitions that have been added to the open
+	// transaction, if any.
+	txnPartitions map[kafkaTopicPartition]struct{}
+}
+
+var _ txnProducer = (*kafkaTxnProducer)(nil)
+
+func openKafkaBroker(broker *sarama.Broker, config *sarama.Config) error {
+	if err := broker.Open(config); err != nil && err != sarama.ErrAlreadyConnected {
+		return err
+	}
+	return nil
+}
+
+// kafkaCoordinatorRetryOptions are used to retry the requests that the
+// transaction coordinator rejects because it is still completing the previous
+// transaction.
+var kafkaCoordinatorRetryOptions = retry.Options{
+	InitialBackoff: 10 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Multiplier:     2,
+	MaxRetries:     20,
+}
+
+// connect finds the broker that coordinates the transactions of p's
+// transactional id.
+func (p *kafkaTxnProducer) connect() error {
+	if p.coordinator != nil {
+		return nil
+	}
+	err := errors.New(`no kafka brokers available`)
+	for _, broker := range p.client.Brokers() {
+		if err = openKafkaBroker(broker, p.client.Config()); err != nil {
+			continue
+		}
+		var resp *sarama.FindCoordinatorResponse
+		resp, err = broker.FindCoordinator(&sarama.FindCoordinatorRequest{
+			Version:         1,
+			CoordinatorKey:  p.txnID,
+			CoordinatorType: sarama.CoordinatorTransaction,
+		})
+		if err != nil {
+			continue
+		}
+		if resp.Err != sarama.ErrNoError {
+			err = resp.Err
+			continue
+		}
+		if err = openKafkaBroker(resp.Coordinator, p.client.Config()); err != nil {
+			break
+		}
+		p.coordinator = resp.Coordinator
+		return nil
+	}
+	return errors.Wrapf(err, `finding the transaction coordinator of %s`, p.txnID)
+}
+
+func (p *kafkaTxnProducer) initTxns() (int64, int16, error) {
+	if err := p.connect(); err != nil {
+		return 0, 0, err
+	}
+	var resp *sarama.InitProducerIDResponse
+	for r := retry.Start(kafkaCoordinatorRetryOptions); r.Next(); {
+		var err error
+		resp, err = p.coordinator.InitProducerID(&sarama.InitProducerIDRequest{
+			TransactionalID:    &p.txnID,
+			TransactionTimeout: kafkaTxnTimeout,
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		if resp.Err != sarama.ErrConcurrentTransactions {
+			break
+		}
+	}
+	if resp.Err != sarama.ErrNoError {
+		return 0, 0, errors.Wrapf(resp.Err, `initializing kafka producer %s`, p.txnID)
+	}
+	p.producerID, p.producerEpoch = resp.ProducerID, resp.ProducerEpoch
+	p.sequences = make(map[kafkaTopicPartition]int32)
+	p.txnPartitions = make(map[kafkaTopicPartition]struct{})
+	return p.producerID, p.producerEpoch, nil
+}
+
+func (p *kafkaTxnProducer) partitions(topic string) ([]int32, error) {
+	return p.client.Partitions(topic)
+}
+
+func (p *kafkaTxnProducer) addPartitions(added map[string][]int32) error {
+	var resp *sarama.AddPartitionsToTxnResponse
+	for r := retry.Start(kafkaCoordinatorRetryOptions); r.Next(); {
+		var err error
+		resp, err = p.coordinator.AddPartitionsToTxn(&sarama.AddPartitionsToTxnRequest{
+			TransactionalID: p.txnID,
+			ProducerID:      p.producerID,
+			ProducerEpoch:   p.producerEpoch,
+			TopicPartitions: added,
+		})
+		if err != nil {
+			return err
+		}
+		var retryable bool
+		for topic, partitionErrs := range resp.Errors {
+			for _, partitionErr := range partitionErrs {
+				switch partitionErr.Err {
+				case sarama.ErrNoError:
+				case sarama.ErrConcurrentTransactions:
+					retryable = true
+				default:
+					return errors.Wrapf(partitionErr.Err, `adding partition %d of %s to transaction`,
+						partitionErr.Partition, topic)
+				}
+			}
+		}
+		if !retryable {
+			for topic, partitions := range added {
+				for _, partition := range partitions {
+					p.txnPartitions[kafkaTopicPartition{topic: topic, partition: partition}] = struct{}{}
+				}
+			}
+			return nil
+		}
+	}
+	return errors.Errorf(`adding partitions to the transaction of %s: %s`,
+		p.txnID, sarama.ErrConcurrentTransactions)
+}
+
+// produce implements the txnProducer interface. records is consumed.
+func (p *kafkaTxnProducer) produce(records map[kafkaTopicPartition][]*sarama.Record) error {
+	added := make(map[string][]int32)
+	for tp := range records {
+		if _, ok := p.txnPartitions[tp]; !ok {
+			added[tp.topic] = append(added[tp.topic], tp.partition)
+		}
+	}
+	if len(added) > 0 {
+		if err := p.addPartitions(added); err != nil {
+			return err
+		}
+	}
+
+	timeout := int32(p.client.Config().Producer.Timeout / time.Millisecond)
+	for len(records) > 0 {
+		// Each round sends up to kafkaTxnMaxBatchRecords records of every
+		// partition, in one request per partition leader.
+		requests := make(map[*sarama.Broker]*sarama.ProduceRequest)
+		leaders := make(map[kafkaTopicPartition]*sarama.Broker, len(records))
+		now := timeutil.Now()
+		for tp, recs := range records {
+			leader, err := p.client.Leader(tp.topic, tp.partition)
+			if err != nil {
+				return err
+			}
+			leaders[tp] = leader
+			req, ok := requests[leader]
+			if !ok {
+				req = &sarama.ProduceRequest{
+					TransactionalID: &p.txnID,
+					RequiredAcks:    sarama.WaitForAll,
+					Timeout:         timeout,
+					Version:         3,
+				}
+				requests[leader] = req
+			}
+			if len(recs) > kafkaTxnMaxBatchRecords {
+				recs = recs[:kafkaTxnMaxBatchRecords]
+			}
+			for i, rec := range recs {
+				rec.OffsetDelta = int64(i)
+			}
+			req.AddBatch(tp.topic, tp.partition, &sarama.RecordBatch{
+				Version:         2,
+				ProducerID:      p.producerID,
+				ProducerEpoch:   p.producerEpoch,
+				FirstSequence:   p.sequences[tp],
+				IsTransactional: true,
+				FirstTimestamp:  now,
+				MaxTimestamp:    now,
+				LastOffsetDelta: int32(len(recs) - 1),
+				Records:         recs,
+			})
+		}
+		responses := make(map[*sarama.Broker]*sarama.ProduceResponse, len(requests))
+		for leader, req := range requests {
+			resp, err := leader.Produce(req)
+			if err != nil {
+				return err
+			}
+			responses[leader] = resp
+		}
+		for tp, leader := range leaders {
+			block := responses[leader].GetBlock(tp.topic, tp.partition)
+			if block == nil {
+				return errors.Errorf(`no produce response for partition %d of %s`, tp.partition, tp.topic)
+			}
+			if block.Err != sarama.ErrNoError {
+				return errors.Wrapf(block.Err, `producing to partition %d of %s`, tp.partition, tp.topic)
+			}
+			n := len(records[tp])
+			if n > kafkaTxnMaxBatchRecords {
+				n = kafkaTxnMaxBatchRecords
+			}
+			p.sequences[tp] += int32(n)
+			if n == len(records[tp]) {
+				delete(records, tp)
+			} else {
+				records[tp] = records[tp][n:]
+			}
+		}
+	}
+	return nil
+}
+
+func (p *kafkaTxnProducer) endTxn(
+	producerID int64, producerEpoch int16, commit bool,
+) (sarama.KError, error) {
+	var resp *sarama.EndTxnResponse
+	for r := retry.Start(kafkaCoordinatorRetryOptions); r.Next(); {
+		var err error
+		resp, err = p.coordinator.EndTxn(&sarama.EndTxnRequest{
+			TransactionalID:   p.txnID,
+			ProducerID:        producerID,
+			ProducerEpoch:     producerEpoch,
+			TransactionResult: commit,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if resp.Err != sarama.ErrConcurrentTransactions {
+			break
+		}
+	}
+	return resp.Err, nil
+}
+
+func (p *kafkaTxnProducer) commitTxn() error {
+	if len(p.txnPartitions) == 0 {
+		return nil
+	}
+	kerr, err := p.endTxn(p.producerID, p.producerEpoch, true /* commit */)
+	if err != nil {
+		return err
+	}
+	if kerr != sarama.ErrNoError {
+		return errors.Wrapf(kerr, `committing kafka transaction of %s`, p.txnID)
+	}
+	p.txnPartitions = make(map[kafkaTopicPartition]struct{})
+	return nil
+}
+
+func (p *kafkaTxnProducer) commitPrepared(producerID int64, producerEpoch int16) (bool, error) {
+	if err := p.connect(); err != nil {
+		return false, err
+	}
+	kerr, err := p.endTxn(producerID, producerEpoch, true /* commit */)
+	if err != nil {
+		return false, err
+	}
+	switch kerr {
+	case sarama.ErrNoError:
+		// Either the transaction was still open and is now committed, or it had
+		// already been committed.
+		return true, nil
+	case sarama.ErrInvalidTxnState, sarama.ErrInvalidProducerEpoch, sarama.ErrInvalidProducerIDMapping:
+		// The transaction was aborted, because it timed out or because a newer
+		// producer fenced it off, or the coordinator no longer knows about it.
+		return false, nil
+	default:
+		return false, errors.Wrapf(kerr, `committing kafka transaction of %s`, p.txnID)
+	}
+}
+
+func (p *kafkaTxnProducer) Close() error {
+	if p.coordinator == nil {
+		return nil
+	}
+	if len(p.txnPartitions) > 0 {
+		_, _ = p.endTxn(p.producerID, p.producerEpoch, false /* commit */)
+	}
+	return p.coordinator.Close()
+}
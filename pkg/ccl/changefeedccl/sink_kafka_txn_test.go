@@ -0,0 +1,428 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// kafkaTxnHandlers returns the handlers of a mock broker that is the
+// transaction coordinator and the leader of both partitions of topic `t`.
+// Every request succeeds, and producers get id 7 and epoch 1.
+func kafkaTxnHandlers(t *testing.T, broker *sarama.MockBroker) map[string]sarama.MockResponse {
+	return map[string]sarama.MockResponse{
+		`MetadataRequest`: sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader(`t`, 0, broker.BrokerID()).
+			SetLeader(`t`, 1, broker.BrokerID()),
+		// sarama.MockFindCoordinatorResponse only answers version 0 requests.
+		`FindCoordinatorRequest`: sarama.NewMockWrapper(&sarama.FindCoordinatorResponse{
+			Version: 1, Coordinator: sarama.NewBroker(broker.Addr()),
+		}),
+		`InitProducerIDRequest`: sarama.NewMockWrapper(&sarama.InitProducerIDResponse{
+			ProducerID: 7, ProducerEpoch: 1,
+		}),
+		`AddPartitionsToTxnRequest`: sarama.NewMockWrapper(&sarama.AddPartitionsToTxnResponse{}),
+		`ProduceRequest`:            sarama.NewMockProduceResponse(t).SetVersion(3),
+		`EndTxnRequest`:             sarama.NewMockWrapper(&sarama.EndTxnResponse{}),
+	}
+}
+
+func startKafkaTxnBroker(t *testing.T) (*sarama.MockBroker, sarama.Client) {
+	broker := sarama.NewMockBroker(t, 1)
+	broker.SetHandlerByMap(kafkaTxnHandlers(t, broker))
+	config := sarama.NewConfig()
+	config.Version = sarama.V0_11_0_0
+	client, err := sarama.NewClient([]string{broker.Addr()}, config)
+	if err != nil {
+		broker.Close()
+		t.Fatal(err)
+	}
+	return broker, client
+}
+
+// kafkaTxnRequests returns the requests received by the broker since the
+// given number of requests, except for metadata requests.
+func kafkaTxnRequests(broker *sarama.MockBroker, since int) []interface{} {
+	var requests []interface{}
+	for _, rr := range broker.History()[since:] {
+		if _, ok := rr.Request.(*sarama.MetadataRequest); !ok {
+			requests = append(requests, rr.Request)
+		}
+	}
+	return requests
+}
+
+type producedBatch struct {
+	transactional bool
+	producerID    int64
+	producerEpoch int16
+	firstSequence int32
+	values        []string
+}
+
+// producedBatches returns the record batches of a produce request, which
+// sarama decodes but doesn't export.
+func producedBatches(req *sarama.ProduceRequest) map[kafkaTopicPartition]producedBatch {
+	batches := make(map[kafkaTopicPartition]producedBatch)
+	topics := reflect.ValueOf(req).Elem().FieldByName(`records`)
+	for _, topic := range topics.MapKeys() {
+		partitions := topics.MapIndex(topic)
+		for _, partition := range partitions.MapKeys() {
+			b := partitions.MapIndex(partition).FieldByName(`RecordBatch`).Elem()
+			batch := producedBatch{
+				transactional: b.FieldByName(`IsTransactional`).Bool(),
+				producerID:    b.FieldByName(`ProducerID`).Int(),
+				producerEpoch: int16(b.FieldByName(`ProducerEpoch`).Int()),
+				firstSequence: int32(b.FieldByName(`FirstSequence`).Int()),
+			}
+			records := b.FieldByName(`Records`)
+			for i := 0; i < records.Len(); i++ {
+				value := records.Index(i).Elem().FieldByName(`Value`).Bytes()
+				batch.values = append(batch.values, string(value))
+			}
+			tp := kafkaTopicPartition{topic: topic.String(), partition: int32(partition.Int())}
+			batches[tp] = batch
+		}
+	}
+	return batches
+}
+
+func kafkaTxnRecords(values ...string) []*sarama.Record {
+	records := make([]*sarama.Record, len(values))
+	for i, value := range values {
+		records[i] = &sarama.Record{Value: []byte(value)}
+	}
+	return records
+}
+
+func TestKafkaTxnProducer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	broker, client := startKafkaTxnBroker(t)
+	defer broker.Close()
+	defer func() { require.NoError(t, client.Close()) }()
+	t0 := kafkaTopicPartition{topic: `t`, partition: 0}
+	t1 := kafkaTopicPartition{topic: `t`, partition: 1}
+
+	p := &kafkaTxnProducer{client: client, txnID: `txn`}
+	since := len(broker.History())
+	producerID, producerEpoch, err := p.initTxns()
+	require.NoError(t, err)
+	require.Equal(t, int64(7), producerID)
+	require.Equal(t, int16(1), producerEpoch)
+
+	require.NoError(t, p.produce(map[kafkaTopicPartition][]*sarama.Record{
+		t0: kafkaTxnRecords(`a`, `b`),
+		t1: kafkaTxnRecords(`c`),
+	}))
+	require.NoError(t, p.produce(map[kafkaTopicPartition][]*sarama.Record{
+		t0: kafkaTxnRecords(`d`),
+	}))
+	require.NoError(t, p.commitTxn())
+
+	requests := kafkaTxnRequests(broker, since)
+	require.Len(t, requests, 6)
+	find := requests[0].(*sarama.FindCoordinatorRequest)
+	require.Equal(t, `txn`, find.CoordinatorKey)
+	require.Equal(t, sarama.CoordinatorTransaction, find.CoordinatorType)
+	require.Equal(t, `txn`, *requests[1].(*sarama.InitProducerIDRequest).TransactionalID)
+	add := requests[2].(*sarama.AddPartitionsToTxnRequest)
+	sort.Slice(add.TopicPartitions[`t`], func(i, j int) bool {
+		return add.TopicPartitions[`t`][i] < add.TopicPartitions[`t`][j]
+	})
+	require.Equal(t, &sarama.AddPartitionsToTxnRequest{
+		TransactionalID: `txn`, ProducerID: 7, ProducerEpoch: 1,
+		TopicPartitions: map[string][]int32{`t`: {0, 1}},
+	}, add)
+	// Every batch is transactional and carries the producer id, epoch and the
+	// sequence number of its first record in the partition.
+	produce := requests[3].(*sarama.ProduceRequest)
+	require.Equal(t, `txn`, *produce.TransactionalID)
+	require.Equal(t, sarama.WaitForAll, produce.RequiredAcks)
+	require.Equal(t, map[kafkaTopicPartition]producedBatch{
+		t0: {transactional: true, producerID: 7, producerEpoch: 1, firstSequence: 0, values: []string{`a`, `b`}},
+		t1: {transactional: true, producerID: 7, producerEpoch: 1, firstSequence: 0, values: []string{`c`}},
+	}, producedBatches(produce))
+	// The partition was already added to the transaction.
+	require.Equal(t, map[kafkaTopicPartition]producedBatch{
+		t0: {transactional: true, producerID: 7, producerEpoch: 1, firstSequence: 2, values: []string{`d`}},
+	}, producedBatches(requests[4].(*sarama.ProduceRequest)))
+	require.Equal(t, &sarama.EndTxnRequest{
+		TransactionalID: `txn`, ProducerID: 7, ProducerEpoch: 1, TransactionResult: true,
+	}, requests[5])
+
+	// Committing without an open transaction is a no-op.
+	since = len(broker.History())
+	require.NoError(t, p.commitTxn())
+	require.Empty(t, kafkaTxnRequests(broker, since))
+
+	// The next transaction adds its partitions again, and the sequence numbers
+	// carry on. Big batches are split across requests.
+	values := make([]string, kafkaTxnMaxBatchRecords+1)
+	for i := range values {
+		values[i] = `e`
+	}
+	require.NoError(t, p.produce(map[kafkaTopicPartition][]*sarama.Record{
+		t1: kafkaTxnRecords(values...),
+	}))
+	// Closing aborts the open transaction.
+	require.NoError(t, p.Close())
+
+	requests = kafkaTxnRequests(broker, since)
+	require.Len(t, requests, 4)
+	require.Equal(t, map[string][]int32{`t`: {1}},
+		requests[0].(*sarama.AddPartitionsToTxnRequest).TopicPartitions)
+	first := producedBatches(requests[1].(*sarama.ProduceRequest))[t1]
+	require.Equal(t, int32(1), first.firstSequence)
+	require.Len(t, first.values, kafkaTxnMaxBatchRecords)
+	second := producedBatches(requests[2].(*sarama.ProduceRequest))[t1]
+	require.Equal(t, int32(1+kafkaTxnMaxBatchRecords), second.firstSequence)
+	require.Len(t, second.values, 1)
+	require.Equal(t, &sarama.EndTxnRequest{
+		TransactionalID: `txn`, ProducerID: 7, ProducerEpoch: 1, TransactionResult: false,
+	}, requests[3])
+}
+
+func TestKafkaTxnProducerFenced(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	broker, client := startKafkaTxnBroker(t)
+	defer broker.Close()
+	defer func() { require.NoError(t, client.Close()) }()
+	t0 := kafkaTopicPartition{topic: `t`, partition: 0}
+
+	// The coordinator asks producers to retry while it completes the previous
+	// transaction. Each producer with the same transactional id gets a new
+	// epoch.
+	handlers := kafkaTxnHandlers(t, broker)
+	handlers[`InitProducerIDRequest`] = sarama.NewMockSequence(
+		&sarama.InitProducerIDResponse{Err: sarama.ErrConcurrentTransactions},
+		&sarama.InitProducerIDResponse{ProducerID: 7, ProducerEpoch: 1},
+		&sarama.InitProducerIDResponse{ProducerID: 7, ProducerEpoch: 2},
+	)
+	broker.SetHandlerByMap(handlers)
+
+	fenced := &kafkaTxnProducer{client: client, txnID: `txn`}
+	defer func() { require.NoError(t, fenced.Close()) }()
+	_, producerEpoch, err := fenced.initTxns()
+	require.NoError(t, err)
+	require.Equal(t, int16(1), producerEpoch)
+	require.NoError(t, fenced.produce(map[kafkaTopicPartition][]*sarama.Record{
+		t0: kafkaTxnRecords(`a`),
+	}))
+
+	p := &kafkaTxnProducer{client: client, txnID: `txn`}
+	defer func() { require.NoError(t, p.Close()) }()
+	_, producerEpoch, err = p.initTxns()
+	require.NoError(t, err)
+	require.Equal(t, int16(2), producerEpoch)
+
+	// The requests of the fenced producer are rejected from then on.
+	handlers[`ProduceRequest`] = sarama.NewMockProduceResponse(t).SetVersion(3).
+		SetError(`t`, 0, sarama.ErrInvalidProducerEpoch)
+	handlers[`EndTxnRequest`] = sarama.NewMockWrapper(&sarama.EndTxnResponse{
+		Err: sarama.ErrInvalidProducerEpoch,
+	})
+	broker.SetHandlerByMap(handlers)
+	err = fenced.produce(map[kafkaTopicPartition][]*sarama.Record{t0: kafkaTxnRecords(`b`)})
+	require.EqualError(t, err, `producing to partition 0 of t: `+sarama.ErrInvalidProducerEpoch.Error())
+	err = fenced.commitTxn()
+	require.EqualError(t, err, `committing kafka transaction of txn: `+sarama.ErrInvalidProducerEpoch.Error())
+	require.Equal(t, sarama.ErrInvalidProducerEpoch, errors.Cause(err))
+}
+
+func TestKafkaTxnProducerCommitPrepared(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	broker, client := startKafkaTxnBroker(t)
+	defer broker.Close()
+	defer func() { require.NoError(t, client.Close()) }()
+
+	for _, tc := range []struct {
+		name      string
+		responses []sarama.KError
+		committed bool
+		err       string
+	}{
+		{name: `open`, responses: []sarama.KError{sarama.ErrNoError}, committed: true},
+		{
+			name:      `completing`,
+			responses: []sarama.KError{sarama.ErrConcurrentTransactions, sarama.ErrNoError},
+			committed: true,
+		},
+		{name: `aborted`, responses: []sarama.KError{sarama.ErrInvalidTxnState}},
+		{name: `fenced`, responses: []sarama.KError{sarama.ErrInvalidProducerEpoch}},
+		{name: `unknown`, responses: []sarama.KError{sarama.ErrInvalidProducerIDMapping}},
+		{
+			name:      `error`,
+			responses: []sarama.KError{sarama.ErrConsumerCoordinatorNotAvailable},
+			err:       `committing kafka transaction of txn: ` + sarama.ErrConsumerCoordinatorNotAvailable.Error(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			handlers := kafkaTxnHandlers(t, broker)
+			var responses []interface{}
+			for _, kerr := range tc.responses {
+				responses = append(responses, &sarama.EndTxnResponse{Err: kerr})
+			}
+			handlers[`EndTxnRequest`] = sarama.NewMockSequence(responses...)
+			broker.SetHandlerByMap(handlers)
+
+			p := &kafkaTxnProducer{client: client, txnID: `txn`}
+			defer func() { require.NoError(t, p.Close()) }()
+			since := len(broker.History())
+			committed, err := p.commitPrepared(3, 4)
+			if tc.err != `` {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.committed, committed)
+			}
+
+			// The transaction is committed with the producer id and epoch that
+			// prepared it, not those of a new producer.
+			requests := kafkaTxnRequests(broker, since)
+			require.Len(t, requests, 1+len(tc.responses))
+			for _, req := range requests[1:] {
+				require.Equal(t, &sarama.EndTxnRequest{
+					TransactionalID: `txn`, ProducerID: 3, ProducerEpoch: 4, TransactionResult: true,
+				}, req)
+			}
+		})
+	}
+}
+
+// TestKafkaTxnSinkRestart checks that the rows of a transaction that was
+// prepared when the changefeed stopped are committed exactly once, whether or
+// not the transaction is still open when the changefeed restarts.
+func TestKafkaTxnSinkRestart(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	broker, client := startKafkaTxnBroker(t)
+	defer broker.Close()
+	defer func() { require.NoError(t, client.Close()) }()
+
+	ctx := context.Background()
+	table := &sqlbase.TableDescriptor{Name: `t`}
+	ts := func(wallTime int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wallTime} }
+	span := roachpb.Span{Key: roachpb.Key(`a`), EndKey: roachpb.Key(`b`)}
+	errStopped := errors.New(`stopped`)
+
+	// newSink returns a sink with a producer whose state is recorded in job,
+	// as if it was the job progress. If stopAfterPrepare is true, the
+	// changefeed stops as soon as a transaction is prepared.
+	newSink := func(
+		job *jobspb.ChangefeedKafkaTransaction, stopAfterPrepare bool,
+	) *kafkaTxnSink {
+		sink := &kafkaTxnSink{
+			topics:       map[string]struct{}{`t`: {}},
+			partitioners: make(map[string]sarama.Partitioner),
+			newProducer: func(txnID string) txnProducer {
+				return &kafkaTxnProducer{client: client, txnID: txnID}
+			},
+		}
+		resolved := job.ResolvedSpans
+		if resolved == nil {
+			resolved = []jobspb.ResolvedSpan{{Span: span, Timestamp: ts(1)}}
+		}
+		require.NoError(t, sink.begin(ctx, `txn`, resolved,
+			func(_ context.Context, state jobspb.ChangefeedKafkaTransaction) error {
+				state.ResolvedSpans = append([]jobspb.ResolvedSpan(nil), state.ResolvedSpans...)
+				*job = state
+				if stopAfterPrepare && !state.Prepared.IsEmpty() {
+					return errStopped
+				}
+				return nil
+			}))
+		return sink
+	}
+
+	for _, stillOpen := range []bool{true, false} {
+		t.Run(fmt.Sprintf(`open=%t`, stillOpen), func(t *testing.T) {
+			broker.SetHandlerByMap(kafkaTxnHandlers(t, broker))
+			var job jobspb.ChangefeedKafkaTransaction
+			sink := newSink(&job, true /* stopAfterPrepare */)
+			require.NoError(t, sink.EmitRow(ctx, table, []byte(`k`), []byte(`v`), ts(2)))
+			_, err := sink.commitResolved(ctx, ts(2))
+			require.Equal(t, errStopped, err)
+			require.Equal(t, ts(2), job.Prepared)
+			// The node stops without aborting the transaction.
+			require.NoError(t, sink.producer.(*kafkaTxnProducer).coordinator.Close())
+
+			handlers := kafkaTxnHandlers(t, broker)
+			handlers[`InitProducerIDRequest`] = sarama.NewMockWrapper(&sarama.InitProducerIDResponse{
+				ProducerID: 7, ProducerEpoch: 2,
+			})
+			if !stillOpen {
+				// The transaction timed out and the coordinator aborted it.
+				handlers[`EndTxnRequest`] = sarama.NewMockSequence(
+					&sarama.EndTxnResponse{Err: sarama.ErrInvalidTxnState},
+					&sarama.EndTxnResponse{},
+				)
+			}
+			broker.SetHandlerByMap(handlers)
+			since := len(broker.History())
+			txns := []jobspb.ChangefeedKafkaTransaction{job}
+			require.NoError(t, (&kafkaTxnSink{newProducer: sink.newProducer}).recoverTxns(ctx, txns))
+			require.Equal(t, hlc.Timestamp{}, txns[0].Prepared)
+
+			// The prepared transaction is committed by its producer id and epoch,
+			// then a new producer fences off the old one. The recovering producer
+			// has nothing to abort when it's closed.
+			requests := kafkaTxnRequests(broker, since)
+			require.Len(t, requests, 3)
+			require.IsType(t, &sarama.FindCoordinatorRequest{}, requests[0])
+			require.Equal(t, &sarama.EndTxnRequest{
+				TransactionalID: `txn`, ProducerID: 7, ProducerEpoch: 1, TransactionResult: true,
+			}, requests[1])
+			require.IsType(t, &sarama.InitProducerIDRequest{}, requests[2])
+
+			// If the transaction was committed, the changefeed restarts after its
+			// rows. Otherwise, they're emitted and committed again.
+			if stillOpen {
+				require.Equal(t, ts(2), txns[0].ResolvedSpans[0].Timestamp)
+				return
+			}
+			require.Equal(t, ts(1), txns[0].ResolvedSpans[0].Timestamp)
+			job = txns[0]
+			sink = newSink(&job, false /* stopAfterPrepare */)
+			defer func() { require.NoError(t, sink.Close()) }()
+			since = len(broker.History())
+			require.NoError(t, sink.EmitRow(ctx, table, []byte(`k`), []byte(`v`), ts(2)))
+			committed, err := sink.commitResolved(ctx, ts(2))
+			require.NoError(t, err)
+			require.Equal(t, []jobspb.ResolvedSpan{{Span: span, Timestamp: ts(2)}}, committed)
+			require.Equal(t, committed, job.ResolvedSpans)
+
+			requests = kafkaTxnRequests(broker, since)
+			require.Len(t, requests, 3)
+			for _, batch := range producedBatches(requests[1].(*sarama.ProduceRequest)) {
+				require.Equal(t, int16(2), batch.producerEpoch)
+				require.Equal(t, []string{`v`}, batch.values)
+			}
+			require.Equal(t, &sarama.EndTxnRequest{
+				TransactionalID: `txn`, ProducerID: 7, ProducerEpoch: 2, TransactionResult: true,
+			}, requests[2])
+		})
+	}
+}
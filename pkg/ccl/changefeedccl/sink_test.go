@@ -10,21 +10,28 @@ package changefeedccl
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
@@ -165,6 +172,172 @@ func TestKafkaSinkEscaping(t *testing.T) {
 	require.Equal(t, sarama.ByteEncoder(`v☃`), m.Value)
 }
 
+// txnProducerMock records the calls made to a txnProducer.
+type txnProducerMock struct {
+	// committedPrepared is returned by commitPrepared.
+	committedPrepared bool
+	calls             []string
+}
+
+func (p *txnProducerMock) initTxns() (int64, int16, error) {
+	p.calls = append(p.calls, `init`)
+	return 7, 1, nil
+}
+func (p *txnProducerMock) partitions(string) ([]int32, error) { return []int32{0, 1}, nil }
+func (p *txnProducerMock) produce(records map[kafkaTopicPartition][]*sarama.Record) error {
+	var values []string
+	for tp, recs := range records {
+		for _, rec := range recs {
+			values = append(values, string(rec.Value))
+		}
+		// Like kafkaTxnProducer, consume records.
+		delete(records, tp)
+	}
+	sort.Strings(values)
+	p.calls = append(p.calls, fmt.Sprintf(`produce %s`, strings.Join(values, `,`)))
+	return nil
+}
+func (p *txnProducerMock) commitTxn() error {
+	p.calls = append(p.calls, `commit`)
+	return nil
+}
+func (p *txnProducerMock) commitPrepared(producerID int64, producerEpoch int16) (bool, error) {
+	p.calls = append(p.calls, fmt.Sprintf(`commit prepared %d/%d`, producerID, producerEpoch))
+	return p.committedPrepared, nil
+}
+func (p *txnProducerMock) Close() error { return nil }
+
+func TestKafkaTxnSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	table := func(name string) *sqlbase.TableDescriptor {
+		return &sqlbase.TableDescriptor{Name: name}
+	}
+	ts := func(wallTime int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wallTime} }
+	span := roachpb.Span{Key: roachpb.Key(`a`), EndKey: roachpb.Key(`b`)}
+
+	ctx := context.Background()
+	p := &txnProducerMock{}
+	sink := &kafkaTxnSink{
+		topics:       map[string]struct{}{`t`: {}},
+		partitioners: make(map[string]sarama.Partitioner),
+		newProducer:  func(string) txnProducer { return p },
+	}
+	defer func() { require.NoError(t, sink.Close()) }()
+
+	checkpointFn := func(_ context.Context, state jobspb.ChangefeedKafkaTransaction) error {
+		p.calls = append(p.calls, fmt.Sprintf(`checkpoint %s prepared=%s`,
+			state.ResolvedSpans[0].Timestamp, state.Prepared))
+		return nil
+	}
+	resolved := []jobspb.ResolvedSpan{{Span: span, Timestamp: ts(1)}}
+	require.NoError(t, sink.begin(ctx, `txn`, resolved, checkpointFn))
+	require.Equal(t, []string{`init`, `checkpoint 0.000000001,0 prepared=0.000000000,0`}, p.calls)
+
+	// The held rows are charged to the memory account until they're produced.
+	st := cluster.MakeTestingClusterSettings()
+	memMon := mon.MakeMonitor(`test`, mon.MemoryResource,
+		nil /* curCount */, nil /* maxHist */, 1 /* increment */, math.MaxInt64, st)
+	memMon.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(math.MaxInt64))
+	defer memMon.Stop(ctx)
+	memAcc := memMon.MakeBoundAccount()
+	defer memAcc.Close(ctx)
+	sink.memAcc = &memAcc
+	rowSize := sizeOfKafkaTxnRow + 2
+
+	for i := int64(2); i <= 4; i++ {
+		value := []byte(strconv.FormatInt(i, 10))
+		require.NoError(t, sink.EmitRow(ctx, table(`t`), value, value, ts(i)))
+	}
+	require.Empty(t, p.calls[2:])
+	require.Equal(t, 3*rowSize, memAcc.Used())
+
+	// Only the rows at or below the resolved timestamp are committed, and the
+	// timestamp is recorded before the transaction is committed.
+	p.calls = nil
+	committed, err := sink.commitResolved(ctx, ts(3))
+	require.NoError(t, err)
+	require.Equal(t, []jobspb.ResolvedSpan{{Span: span, Timestamp: ts(3)}}, committed)
+	require.Equal(t, []string{
+		`produce 2,3`,
+		`checkpoint 0.000000001,0 prepared=0.000000003,0`,
+		`commit`,
+		`checkpoint 0.000000003,0 prepared=0.000000000,0`,
+	}, p.calls)
+	require.Equal(t, rowSize, memAcc.Used())
+
+	// Without any rows to commit, there's no transaction.
+	p.calls = nil
+	committed, err = sink.commitResolved(ctx, ts(3))
+	require.NoError(t, err)
+	require.Equal(t, []jobspb.ResolvedSpan{{Span: span, Timestamp: ts(3)}}, committed)
+	require.Empty(t, p.calls)
+
+	p.calls = nil
+	_, err = sink.commitResolved(ctx, ts(5))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`produce 4`,
+		`checkpoint 0.000000003,0 prepared=0.000000005,0`,
+		`commit`,
+		`checkpoint 0.000000005,0 prepared=0.000000000,0`,
+	}, p.calls)
+	require.Equal(t, int64(0), memAcc.Used())
+
+	// The changefeed fails if the held rows don't fit in the budget.
+	smallMon := mon.MakeMonitor(`small`, mon.MemoryResource,
+		nil /* curCount */, nil /* maxHist */, 1 /* increment */, math.MaxInt64, st)
+	smallMon.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(rowSize))
+	defer smallMon.Stop(ctx)
+	smallAcc := smallMon.MakeBoundAccount()
+	defer smallAcc.Close(ctx)
+	sink.memAcc = &smallAcc
+	require.NoError(t, sink.EmitRow(ctx, table(`t`), []byte(`5`), []byte(`5`), ts(5)))
+	err = sink.EmitRow(ctx, table(`t`), []byte(`6`), []byte(`6`), ts(6))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `holding rows until they're resolved: small: memory budget exceeded`)
+	require.Len(t, sink.held, 1)
+	_, err = sink.commitResolved(ctx, ts(6))
+	require.NoError(t, err)
+	require.Equal(t, int64(0), smallAcc.Used())
+	sink.memAcc = nil
+
+	// Resolved timestamps go to every partition and are committed right away.
+	p.calls = nil
+	require.NoError(t, sink.EmitResolvedTimestamp(ctx, testEncoder{}, ts(6)))
+	require.Equal(t, []string{`produce 0.000000006,0,0.000000006,0`, `commit`}, p.calls)
+
+	// Recovering commits prepared transactions and forwards their spans if they
+	// turn out to be committed. Every producer is fenced off.
+	for _, committedPrepared := range []bool{true, false} {
+		t.Run(fmt.Sprintf(`committed=%t`, committedPrepared), func(t *testing.T) {
+			p.calls = nil
+			p.committedPrepared = committedPrepared
+			txns := []jobspb.ChangefeedKafkaTransaction{
+				{ResolvedSpans: []jobspb.ResolvedSpan{{Span: span, Timestamp: ts(2)}}},
+				{
+					TransactionalID: `a`, ProducerID: 3, ProducerEpoch: 4,
+					ResolvedSpans: []jobspb.ResolvedSpan{{Span: span, Timestamp: ts(2)}},
+					Prepared:      ts(5),
+				},
+				{
+					TransactionalID: `b`,
+					ResolvedSpans:   []jobspb.ResolvedSpan{{Span: span, Timestamp: ts(2)}},
+				},
+			}
+			require.NoError(t, sink.recoverTxns(ctx, txns))
+			require.Equal(t, []string{`commit prepared 3/4`, `init`, `init`}, p.calls)
+			expected := ts(2)
+			if committedPrepared {
+				expected = ts(5)
+			}
+			require.Equal(t, expected, txns[1].ResolvedSpans[0].Timestamp)
+			require.Equal(t, hlc.Timestamp{}, txns[1].Prepared)
+			require.Equal(t, ts(2), txns[2].ResolvedSpans[0].Timestamp)
+		})
+	}
+}
+
 type testEncoder struct{}
 
 func (testEncoder) EncodeKey(encodeRow) ([]byte, error)   { panic(`unimplemented`) }
@@ -0,0 +1,260 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultWebhookBatchSize = 100
+	webhookRequestTimeout   = 30 * time.Second
+)
+
+type webhookSinkConfig struct {
+	caCert     []byte
+	clientCert []byte
+	clientKey  []byte
+	authHeader string
+	batchSize  int
+}
+
+// webhookMessage is the JSON representation of a single row in the payload
+// of a webhook request.
+type webhookMessage struct {
+	Topic string          `json:"topic"`
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// webhookEnvelope is the JSON body of a webhook request carrying rows.
+type webhookEnvelope struct {
+	Payload []webhookMessage `json:"payload"`
+	Length  int              `json:"length"`
+}
+
+// webhookSink emits to an HTTPS endpoint. Rows are batched into a JSON
+// envelope and POSTed once the batch is full or the sink is flushed. Resolved
+// timestamps are POSTed on their own, after every row buffered before them.
+// Requests are retried with backoff if they fail for a reason that is likely
+// to be transient.
+type webhookSink struct {
+	url       string
+	cfg       webhookSinkConfig
+	client    *http.Client
+	retryOpts retry.Options
+
+	batch   []webhookMessage
+	scratch bufalloc.ByteAllocator
+}
+
+func makeWebhookSink(postURL string, cfg webhookSinkConfig, opts map[string]string) (Sink, error) {
+	switch formatType(opts[optFormat]) {
+	case optFormatJSON:
+	default:
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			optFormat, opts[optFormat])
+	}
+
+	tlsConf := &tls.Config{}
+	if cfg.caCert != nil {
+		caCertPool, err := x509.SystemCertPool()
+		if err != nil || caCertPool == nil {
+			caCertPool = x509.NewCertPool()
+		}
+		if !caCertPool.AppendCertsFromPEM(cfg.caCert) {
+			return nil, errors.Errorf(`failed to parse %s`, sinkParamCACert)
+		}
+		tlsConf.RootCAs = caCertPool
+	}
+	if cfg.clientCert != nil {
+		cert, err := tls.X509KeyPair(cfg.clientCert, cfg.clientKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, `invalid %s or %s`, sinkParamClientCert, sinkParamClientKey)
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+
+	s := &webhookSink{
+		url: postURL,
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: webhookRequestTimeout,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   30 * time.Second,
+					KeepAlive: 30 * time.Second,
+				}).DialContext,
+				TLSClientConfig:     tlsConf,
+				TLSHandshakeTimeout: 10 * time.Second,
+				MaxIdleConnsPerHost: 2,
+			},
+		},
+		retryOpts: retry.Options{
+			InitialBackoff: 500 * time.Millisecond,
+			MaxBackoff:     30 * time.Second,
+			Multiplier:     2,
+			MaxRetries:     5,
+		},
+	}
+	return s, nil
+}
+
+// EmitRow implements the Sink interface.
+func (s *webhookSink) EmitRow(
+	ctx context.Context, table *sqlbase.TableDescriptor, key, value []byte, _ hlc.Timestamp,
+) error {
+	if s.client == nil {
+		return errors.New(`cannot EmitRow on a closed sink`)
+	}
+
+	// The key and value are only valid until the next call, so they need to be
+	// copied before being buffered.
+	s.scratch, key = s.scratch.Copy(key, 0 /* extraCap */)
+	s.scratch, value = s.scratch.Copy(value, 0 /* extraCap */)
+	s.batch = append(s.batch, webhookMessage{
+		Topic: table.Name,
+		Key:   key,
+		Value: value,
+	})
+	if len(s.batch) >= s.cfg.batchSize {
+		return s.Flush(ctx)
+	}
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *webhookSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	if s.client == nil {
+		return errors.New(`cannot EmitResolvedTimestamp on a closed sink`)
+	}
+
+	// The resolved timestamp promises that every row before it has been
+	// emitted, so the buffered rows have to be delivered first.
+	if err := s.Flush(ctx); err != nil {
+		return err
+	}
+	var noTopic string
+	payload, err := encoder.EncodeResolvedTimestamp(noTopic, resolved)
+	if err != nil {
+		return err
+	}
+	// Don't need to copy payload because we never buffer it anywhere.
+	return s.send(ctx, payload)
+}
+
+// Flush implements the Sink interface.
+func (s *webhookSink) Flush(ctx context.Context) error {
+	if s.client == nil {
+		return errors.New(`cannot Flush on a closed sink`)
+	}
+	if len(s.batch) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(webhookEnvelope{Payload: s.batch, Length: len(s.batch)})
+	if err != nil {
+		return err
+	}
+	if err := s.send(ctx, body); err != nil {
+		return err
+	}
+	s.batch = s.batch[:0]
+	s.scratch = s.scratch[:0]
+	return nil
+}
+
+// send POSTs the body to the endpoint, retrying with backoff while the
+// failures look transient.
+func (s *webhookSink) send(ctx context.Context, body []byte) error {
+	var err error
+	for r := retry.StartWithCtx(ctx, s.retryOpts); r.Next(); {
+		var retryable bool
+		if retryable, err = s.sendOnce(ctx, body); err == nil || !retryable {
+			return err
+		}
+		if log.V(1) {
+			log.Infof(ctx, `webhook sink request failed, retrying: %v`, err)
+		}
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// sendOnce makes a single request to the endpoint. It returns whether the
+// request may succeed if it is retried.
+func (s *webhookSink) sendOnce(ctx context.Context, body []byte) (retryable bool, _ error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set(`Content-Type`, `application/json`)
+	if s.cfg.authHeader != `` {
+		req.Header.Set(`Authorization`, s.cfg.authHeader)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The error returned by the client includes the url, which isn't
+		// something we want to leak into job errors and logs.
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return true, errors.Wrap(err, `sending to webhook sink`)
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = errors.Errorf(`webhook sink responded with %s`, resp.Status)
+	switch {
+	case resp.StatusCode >= 500,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusRequestTimeout:
+		return true, err
+	default:
+		return false, err
+	}
+}
+
+// Close implements the Sink interface.
+func (s *webhookSink) Close() error {
+	if s.client != nil {
+		s.client.Transport.(*http.Transport).CloseIdleConnections()
+	}
+	s.client = nil
+	s.batch = nil
+	return nil
+}
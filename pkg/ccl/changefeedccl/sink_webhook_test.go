@@ -0,0 +1,285 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/security/securitytest"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookRequest is what the test server records about each request it
+// receives.
+type webhookRequest struct {
+	body     string
+	auth     string
+	clientCN string
+}
+
+// webhookTestServer is an HTTPS server that records every request it receives
+// and responds to them with the queued status codes, or 200 once there are
+// none left.
+type webhookTestServer struct {
+	*httptest.Server
+
+	mu struct {
+		syncutil.Mutex
+		requests []webhookRequest
+		statuses []int
+	}
+}
+
+func makeWebhookTestServer(t *testing.T) *webhookTestServer {
+	s := &webhookTestServer{}
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		req := webhookRequest{body: string(body), auth: r.Header.Get(`Authorization`)}
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			req.clientCN = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.mu.requests = append(s.mu.requests, req)
+		status := http.StatusOK
+		if len(s.mu.statuses) > 0 {
+			status, s.mu.statuses = s.mu.statuses[0], s.mu.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	tlsConf, err := security.LoadServerTLSConfig(
+		filepath.Join(security.EmbeddedCertsDir, security.EmbeddedCACert),
+		filepath.Join(security.EmbeddedCertsDir, security.EmbeddedCACert),
+		filepath.Join(security.EmbeddedCertsDir, security.EmbeddedNodeCert),
+		filepath.Join(security.EmbeddedCertsDir, security.EmbeddedNodeKey))
+	require.NoError(t, err)
+	s.TLS = tlsConf
+	s.StartTLS()
+	return s
+}
+
+func (s *webhookTestServer) respondWith(statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.statuses = append(s.mu.statuses, statuses...)
+}
+
+func (s *webhookTestServer) requests() []webhookRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := s.mu.requests
+	s.mu.requests = nil
+	return requests
+}
+
+func TestWebhookSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	server := makeWebhookTestServer(t)
+	defer server.Close()
+
+	readAsset := func(name string) string {
+		contents, err := securitytest.Asset(filepath.Join(security.EmbeddedCertsDir, name))
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(contents)
+	}
+	sinkURL := func(params url.Values) string {
+		u, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		u.Scheme = sinkSchemeWebhookHTTPS
+		u.Path = `/changefeed`
+		params.Set(sinkParamCACert, readAsset(security.EmbeddedCACert))
+		u.RawQuery = params.Encode()
+		return u.String()
+	}
+
+	opts := map[string]string{
+		optFormat:   string(optFormatJSON),
+		optEnvelope: string(optEnvelopeWrapped),
+	}
+	makeSink := func(t *testing.T, params url.Values, opts map[string]string) *webhookSink {
+		s, err := getSink(sinkURL(params), 1, opts, jobspb.ChangefeedTargets{},
+			cluster.MakeTestingClusterSettings())
+		require.NoError(t, err)
+		ws := s.(*webhookSink)
+		ws.retryOpts = retry.Options{
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+			MaxRetries:     2,
+		}
+		return ws
+	}
+	e, err := makeJSONEncoder(opts)
+	require.NoError(t, err)
+	t1 := &sqlbase.TableDescriptor{Name: `t1`}
+	t2 := &sqlbase.TableDescriptor{Name: `t2`}
+	ts := func(i int64) hlc.Timestamp { return hlc.Timestamp{WallTime: i} }
+
+	t.Run(`batching`, func(t *testing.T) {
+		s := makeSink(t, url.Values{sinkParamBatchSize: {`2`}}, opts)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		// Empty flushes don't send anything.
+		require.NoError(t, s.Flush(ctx))
+		require.Empty(t, server.requests())
+
+		// A full batch is sent right away.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"a":1}`), ts(1)))
+		require.Empty(t, server.requests())
+		require.NoError(t, s.EmitRow(ctx, t2, []byte(`[2]`), []byte(`{"b":2}`), ts(1)))
+		require.Equal(t, []webhookRequest{{
+			body: `{"payload":[` +
+				`{"topic":"t1","key":[1],"value":{"a":1}},` +
+				`{"topic":"t2","key":[2],"value":{"b":2}}],"length":2}`,
+		}}, server.requests())
+
+		// A partial batch is sent on flush.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[3]`), []byte(`{"a":3}`), ts(2)))
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []webhookRequest{{
+			body: `{"payload":[{"topic":"t1","key":[3],"value":{"a":3}}],"length":1}`,
+		}}, server.requests())
+	})
+
+	t.Run(`resolved`, func(t *testing.T) {
+		s := makeSink(t, url.Values{}, opts)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		// Buffered rows are sent before the resolved timestamp.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"a":1}`), ts(1)))
+		require.NoError(t, s.EmitResolvedTimestamp(ctx, e, ts(5)))
+		require.Equal(t, []webhookRequest{
+			{body: `{"payload":[{"topic":"t1","key":[1],"value":{"a":1}}],"length":1}`},
+			{body: `{"resolved":"5.0000000000"}`},
+		}, server.requests())
+	})
+
+	t.Run(`auth`, func(t *testing.T) {
+		authOpts := map[string]string{
+			optFormat:            string(optFormatJSON),
+			optEnvelope:          string(optEnvelopeWrapped),
+			optWebhookAuthHeader: `Bearer s3cr3t`,
+		}
+		s := makeSink(t, url.Values{
+			sinkParamClientCert: {readAsset(security.EmbeddedRootCert)},
+			sinkParamClientKey:  {readAsset(security.EmbeddedRootKey)},
+		}, authOpts)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		require.NoError(t, s.EmitResolvedTimestamp(ctx, e, ts(1)))
+		require.Equal(t, []webhookRequest{{
+			body:     `{"resolved":"1.0000000000"}`,
+			auth:     `Bearer s3cr3t`,
+			clientCN: security.RootUser,
+		}}, server.requests())
+	})
+
+	t.Run(`retry`, func(t *testing.T) {
+		s := makeSink(t, url.Values{}, opts)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		// Transient errors are retried.
+		server.respondWith(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"a":1}`), ts(1)))
+		require.NoError(t, s.Flush(ctx))
+		require.Len(t, server.requests(), 3)
+
+		// Until we run out of retries.
+		server.respondWith(http.StatusInternalServerError, http.StatusInternalServerError,
+			http.StatusInternalServerError)
+		require.EqualError(t, s.EmitResolvedTimestamp(ctx, e, ts(2)),
+			`webhook sink responded with 500 Internal Server Error`)
+		require.Len(t, server.requests(), 3)
+
+		// Other errors are not.
+		server.respondWith(http.StatusBadRequest)
+		require.EqualError(t, s.EmitResolvedTimestamp(ctx, e, ts(2)),
+			`webhook sink responded with 400 Bad Request`)
+		require.Len(t, server.requests(), 1)
+	})
+
+	t.Run(`closed`, func(t *testing.T) {
+		s := makeSink(t, url.Values{}, opts)
+		require.NoError(t, s.Close())
+		require.EqualError(t, s.EmitRow(ctx, t1, nil, nil, ts(1)),
+			`cannot EmitRow on a closed sink`)
+		require.EqualError(t, s.Flush(ctx), `cannot Flush on a closed sink`)
+	})
+
+	t.Run(`params`, func(t *testing.T) {
+		settings := cluster.MakeTestingClusterSettings()
+		for _, tc := range []struct {
+			params url.Values
+			opts   map[string]string
+			err    string
+		}{
+			{
+				params: url.Values{sinkParamBatchSize: {`0`}},
+				opts:   opts,
+				err:    `param batch_size must be a positive integer: 0`,
+			},
+			{
+				params: url.Values{sinkParamClientCert: {readAsset(security.EmbeddedRootCert)}},
+				opts:   opts,
+				err:    `client_cert and client_key must be provided together`,
+			},
+			{
+				params: url.Values{sinkParamClientKey: {`!`}},
+				opts:   opts,
+				err:    `param client_key must be base 64 encoded: illegal base64 data at input byte 0`,
+			},
+			{
+				params: url.Values{`foo`: {`bar`}},
+				opts:   opts,
+				err:    `unknown sink query parameter: foo`,
+			},
+			{
+				params: url.Values{},
+				opts: map[string]string{
+					optFormat:   string(optFormatAvro),
+					optEnvelope: string(optEnvelopeKeyOnly),
+				},
+				err: `this sink is incompatible with format=experimental_avro`,
+			},
+		} {
+			_, err := getSink(sinkURL(tc.params), 1, tc.opts, jobspb.ChangefeedTargets{}, settings)
+			require.EqualError(t, err, tc.err)
+		}
+
+		_, err := getSink(`kafka://localhost:9092`, 1, map[string]string{
+			optFormat:            string(optFormatJSON),
+			optWebhookAuthHeader: `Bearer s3cr3t`,
+		}, jobspb.ChangefeedTargets{}, settings)
+		if !testutils.IsError(err, `webhook_auth_header is only supported by webhook-https sinks`) {
+			t.Fatalf(`unexpected error: %v`, err)
+		}
+	})
+}
@@ -79,9 +79,10 @@ func evalFollowerReadOffset(clusterID uuid.UUID, st *cluster.Settings) (time.Dur
 }
 
 // batchCanBeEvaluatedOnFollower determines if a batch consists exclusively of
-// requests that can be evaluated on a follower replica.
+// requests that can be evaluated on a follower replica. Locking requests must
+// be evaluated on the leaseholder, which maintains the lock table.
 func batchCanBeEvaluatedOnFollower(ba roachpb.BatchRequest) bool {
-	return ba.IsReadOnly() && ba.IsAllTransactional()
+	return ba.IsReadOnly() && ba.IsAllTransactional() && !ba.IsLocking()
 }
 
 // txnCanPerformFollowerRead determines if the provided transaction can perform
@@ -133,6 +134,8 @@ func newOracleFactory(cfg replicaoracle.Config) replicaoracle.OracleFactory {
 }
 
 func (f oracleFactory) Oracle(txn *client.Txn) replicaoracle.Oracle {
+	// The closest replica may be a non-voting replica, which are placed in
+	// remote regions precisely to serve follower reads.
 	if txn != nil && canUseFollowerRead(f.clusterID.Get(), f.st, txn.OrigTimestamp()) {
 		return f.closest.Oracle(txn)
 	}
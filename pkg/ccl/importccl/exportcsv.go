@@ -16,6 +16,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
@@ -30,6 +31,75 @@ import (
 const exportFilePatternPart = "%part%"
 const exportFilePatternDefault = exportFilePatternPart + ".csv"
 
+// exportChunkWriter encodes the rows of one exported file.
+type exportChunkWriter interface {
+	// addRow encodes a row, whose datums may be DNull.
+	addRow(row tree.Datums) error
+	// finish returns the contents of the file.
+	finish() ([]byte, error)
+}
+
+// newExportChunkWriter returns a writer of a file in the format of the spec,
+// holding rows of the given types.
+func newExportChunkWriter(
+	spec *distsqlpb.CSVWriterSpec, typs []types.T,
+) (exportChunkWriter, error) {
+	switch spec.Format {
+	case roachpb.IOFileFormat_Unknown, roachpb.IOFileFormat_CSV:
+		return newCSVChunkWriter(spec.Options, len(typs)), nil
+	case roachpb.IOFileFormat_JSONL:
+		return newJSONLChunkWriter(spec.ColumnNames, len(typs))
+	case roachpb.IOFileFormat_Parquet:
+		return newParquetChunkWriter(spec.ColumnNames, typs, spec.ParquetOptions)
+	default:
+		return nil, errors.Errorf("unsupported export format: %s", spec.Format)
+	}
+}
+
+type csvChunkWriter struct {
+	buf     bytes.Buffer
+	writer  *csv.Writer
+	nullsAs string
+	f       *tree.FmtCtx
+	csvRow  []string
+}
+
+var _ exportChunkWriter = &csvChunkWriter{}
+
+func newCSVChunkWriter(opts roachpb.CSVOptions, numCols int) *csvChunkWriter {
+	c := &csvChunkWriter{
+		f:      tree.NewFmtCtx(tree.FmtExport),
+		csvRow: make([]string, numCols),
+	}
+	c.writer = csv.NewWriter(&c.buf)
+	if opts.Comma != 0 {
+		c.writer.Comma = opts.Comma
+	}
+	if opts.NullEncoding != nil {
+		c.nullsAs = *opts.NullEncoding
+	}
+	return c
+}
+
+func (c *csvChunkWriter) addRow(row tree.Datums) error {
+	for i, d := range row {
+		if d == tree.DNull {
+			c.csvRow[i] = c.nullsAs
+			continue
+		}
+		d.Format(c.f)
+		c.csvRow[i] = c.f.String()
+		c.f.Reset()
+	}
+	return c.writer.Write(c.csvRow)
+}
+
+func (c *csvChunkWriter) finish() ([]byte, error) {
+	c.writer.Flush()
+	c.f.Close()
+	return c.buf.Bytes(), c.writer.Error()
+}
+
 func newCSVWriterProcessor(
 	flowCtx *distsqlrun.FlowCtx,
 	processorID int32,
@@ -94,26 +164,16 @@ func (sp *csvWriter) Run(ctx context.Context) {
 		input := distsqlrun.MakeNoMetadataRowSource(sp.input, sp.output)
 
 		alloc := &sqlbase.DatumAlloc{}
-
-		var buf bytes.Buffer
-		writer := csv.NewWriter(&buf)
-		if sp.spec.Options.Comma != 0 {
-			writer.Comma = sp.spec.Options.Comma
-		}
-		nullsAs := ""
-		if sp.spec.Options.NullEncoding != nil {
-			nullsAs = *sp.spec.Options.NullEncoding
-		}
-		f := tree.NewFmtCtx(tree.FmtExport)
-		defer f.Close()
-
-		csvRow := make([]string, len(typs))
+		datums := make(tree.Datums, len(typs))
 
 		chunk := 0
 		done := false
 		for {
 			var rows int64
-			buf.Reset()
+			writer, err := newExportChunkWriter(&sp.spec, typs)
+			if err != nil {
+				return err
+			}
 			for {
 				if sp.spec.ChunkRows > 0 && rows >= sp.spec.ChunkRows {
 					break
@@ -129,25 +189,22 @@ func (sp *csvWriter) Run(ctx context.Context) {
 				rows++
 
 				for i, ed := range row {
-					if ed.IsNull() {
-						csvRow[i] = nullsAs
-						continue
-					}
 					if err := ed.EnsureDecoded(&typs[i], alloc); err != nil {
 						return err
 					}
-					ed.Datum.Format(f)
-					csvRow[i] = f.String()
-					f.Reset()
+					datums[i] = ed.Datum
 				}
-				if err := writer.Write(csvRow); err != nil {
+				if err := writer.addRow(datums); err != nil {
 					return err
 				}
 			}
 			if rows < 1 {
 				break
 			}
-			writer.Flush()
+			contents, err := writer.finish()
+			if err != nil {
+				return err
+			}
 
 			conf, err := storageccl.ExportStorageConfFromURI(sp.spec.Destination)
 			if err != nil {
@@ -159,12 +216,12 @@ func (sp *csvWriter) Run(ctx context.Context) {
 			}
 			defer es.Close()
 
-			size := buf.Len()
+			size := len(contents)
 
 			part := fmt.Sprintf("n%d.%d", sp.flowCtx.EvalCtx.NodeID, chunk)
 			chunk++
 			filename := strings.Replace(pattern, exportFilePatternPart, part, -1)
-			if err := es.WriteFile(ctx, filename, bytes.NewReader(buf.Bytes())); err != nil {
+			if err := es.WriteFile(ctx, filename, bytes.NewReader(contents)); err != nil {
 				return err
 			}
 			res := sqlbase.EncDatumRow{
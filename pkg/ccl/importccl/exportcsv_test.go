@@ -13,8 +13,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/config"
@@ -23,7 +25,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/parquet"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/cockroach/pkg/workload/bank"
 	"github.com/cockroachdb/cockroach/pkg/workload/workloadsql"
 	"github.com/gogo/protobuf/proto"
@@ -217,3 +221,120 @@ func TestExportShow(t *testing.T) {
 		t.Fatalf("expected %q, got %q", expected, got)
 	}
 }
+
+func TestExportJSONL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE foo (i INT PRIMARY KEY, s STRING, a INT[], j JSONB)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'x', ARRAY[1, NULL], '{"k": true}'), (2, NULL, NULL, NULL)`)
+
+	sqlDB.Exec(t, `EXPORT INTO JSONL 'nodelocal:///jsonl' FROM SELECT * FROM foo ORDER BY i`)
+	content, err := ioutil.ReadFile(filepath.Join(dir, "jsonl", "n1.0.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"i": 1, "s": "x", "a": [1, null], "j": {"k": true}}` + "\n" +
+		`{"i": 2, "s": null, "a": null, "j": null}` + "\n"
+	if got := string(content); expected != got {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	sqlDB.ExpectErr(t, `delimiter option is only supported for CSV`,
+		`EXPORT INTO JSONL 'nodelocal:///jsonl' WITH delimiter = '|' FROM SELECT * FROM foo`)
+}
+
+func TestExportParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE foo (
+		i INT PRIMARY KEY, d DECIMAL(10, 2), s STRING, t TIMESTAMP, a STRING[], u UUID
+	)`)
+	sqlDB.Exec(t, `INSERT INTO foo VALUES
+		(1, 1.5, 'x', '2019-01-02 03:04:05', ARRAY['a', NULL], gen_random_uuid()),
+		(2, -3.25, NULL, NULL, ARRAY[], NULL),
+		(3, NULL, 'z', NULL, NULL, NULL)`)
+
+	codecs := map[string]parquet.Compression{
+		`snappy`: parquet.Snappy,
+		`gzip`:   parquet.Gzip,
+		`none`:   parquet.Uncompressed,
+	}
+	for _, compression := range []string{`snappy`, `gzip`, `none`} {
+		t.Run(compression, func(t *testing.T) {
+			var rows int
+			sqlDB.QueryRow(t, fmt.Sprintf(
+				`SELECT rows FROM [EXPORT INTO PARQUET 'nodelocal:///%s' WITH compression = '%s' FROM SELECT * FROM foo]`,
+				compression, compression),
+			).Scan(&rows)
+			if rows != 3 {
+				t.Fatalf("expected 3 rows, got %d", rows)
+			}
+			content, err := ioutil.ReadFile(filepath.Join(dir, compression, "n1.0.parquet"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := parquet.ReadFile(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Compression != codecs[compression] {
+				t.Fatalf("expected codec %d, got %d", codecs[compression], f.Compression)
+			}
+			if f.NumRows != 3 {
+				t.Fatalf("expected 3 rows in the file metadata, got %d", f.NumRows)
+			}
+			expectedColumns := []parquet.Column{
+				{Name: "i", Type: parquet.Int64},
+				{Name: "d", Type: parquet.ByteArray, Annotation: parquet.Decimal, Precision: 10, Scale: 2},
+				{Name: "s", Type: parquet.ByteArray, Annotation: parquet.UTF8},
+				{Name: "t", Type: parquet.Int64, Annotation: parquet.TimestampMicros},
+				{Name: "a", Type: parquet.ByteArray, Annotation: parquet.UTF8, List: true},
+				{Name: "u", Type: parquet.ByteArray, Annotation: parquet.UTF8},
+			}
+			if !reflect.DeepEqual(expectedColumns, f.Columns) {
+				t.Fatalf("expected columns %+v, got %+v", expectedColumns, f.Columns)
+			}
+
+			if len(f.Rows) != 3 {
+				t.Fatalf("expected 3 rows, got %d", len(f.Rows))
+			}
+			// The UUID is random, so only check that it round-trips.
+			u, ok := f.Rows[0][5].([]byte)
+			if !ok {
+				t.Fatalf("expected a uuid, got %v", f.Rows[0][5])
+			}
+			if _, err := uuid.FromString(string(u)); err != nil {
+				t.Fatal(err)
+			}
+			f.Rows[0][5] = nil
+			ts := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+			expectedRows := [][]interface{}{
+				// 1.5 and -3.25 are exported as their unscaled values, 150 and -325.
+				{int64(1), []byte{0x00, 0x96}, []byte("x"), ts.UnixNano() / 1e3,
+					[]interface{}{[]byte("a"), nil}, nil},
+				{int64(2), []byte{0xfe, 0xbb}, nil, nil, []interface{}{}, nil},
+				{int64(3), nil, []byte("z"), nil, nil, nil},
+			}
+			if !reflect.DeepEqual(expectedRows, f.Rows) {
+				t.Fatalf("expected rows %v, got %v", expectedRows, f.Rows)
+			}
+		})
+	}
+
+	sqlDB.ExpectErr(t, `unsupported compression "lz4"`,
+		`EXPORT INTO PARQUET 'nodelocal:///bad' WITH compression = 'lz4' FROM SELECT * FROM foo`)
+	sqlDB.ExpectErr(t, `compression option is only supported for PARQUET`,
+		`EXPORT INTO CSV 'nodelocal:///bad' WITH compression = 'gzip' FROM SELECT * FROM foo`)
+}
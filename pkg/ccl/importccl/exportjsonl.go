@@ -0,0 +1,60 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bytes"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/pkg/errors"
+)
+
+// jsonlChunkWriter writes newline-delimited JSON, in which each line holds an
+// object with the values of one row keyed by column name. The keys are
+// written in the order of the columns.
+type jsonlChunkWriter struct {
+	buf  bytes.Buffer
+	keys []json.JSON
+}
+
+var _ exportChunkWriter = &jsonlChunkWriter{}
+
+func newJSONLChunkWriter(columnNames []string, numCols int) (*jsonlChunkWriter, error) {
+	if len(columnNames) != numCols {
+		return nil, errors.Errorf("expected %d column names, got %d", numCols, len(columnNames))
+	}
+	keys := make([]json.JSON, len(columnNames))
+	for i, name := range columnNames {
+		keys[i] = json.FromString(name)
+	}
+	return &jsonlChunkWriter{keys: keys}, nil
+}
+
+func (j *jsonlChunkWriter) addRow(row tree.Datums) error {
+	j.buf.WriteByte('{')
+	for i, d := range row {
+		v, err := tree.AsJSON(d)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			j.buf.WriteString(", ")
+		}
+		j.keys[i].Format(&j.buf)
+		j.buf.WriteString(": ")
+		v.Format(&j.buf)
+	}
+	j.buf.WriteString("}\n")
+	return nil
+}
+
+func (j *jsonlChunkWriter) finish() ([]byte, error) {
+	return j.buf.Bytes(), nil
+}
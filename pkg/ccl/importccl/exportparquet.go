@@ -0,0 +1,238 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"math/big"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/parquet"
+	"github.com/pkg/errors"
+)
+
+const parquetCreatedBy = "cockroachdb export"
+
+var parquetCompression = map[roachpb.ParquetOptions_Compression]parquet.Compression{
+	roachpb.ParquetOptions_Snappy:       parquet.Snappy,
+	roachpb.ParquetOptions_Gzip:         parquet.Gzip,
+	roachpb.ParquetOptions_Uncompressed: parquet.Uncompressed,
+}
+
+// parquetChunkWriter writes a parquet file with a column for each exported
+// column. Arrays are written as lists of their elements, and SQL types
+// without a parquet counterpart are written as strings.
+type parquetChunkWriter struct {
+	w       *parquet.Writer
+	columns []parquet.Column
+	row     []interface{}
+	f       *tree.FmtCtx
+}
+
+var _ exportChunkWriter = &parquetChunkWriter{}
+
+func newParquetChunkWriter(
+	columnNames []string, typs []types.T, opts roachpb.ParquetOptions,
+) (*parquetChunkWriter, error) {
+	if len(columnNames) != len(typs) {
+		return nil, errors.Errorf("expected %d column names, got %d", len(typs), len(columnNames))
+	}
+	compression, ok := parquetCompression[opts.Compression]
+	if !ok {
+		return nil, errors.Errorf("unsupported parquet compression: %s", opts.Compression)
+	}
+	columns := make([]parquet.Column, len(typs))
+	for i := range typs {
+		columns[i] = parquetColumnForType(columnNames[i], &typs[i])
+	}
+	return &parquetChunkWriter{
+		w:       parquet.NewWriter(columns, compression, parquetCreatedBy),
+		columns: columns,
+		row:     make([]interface{}, len(columns)),
+		f:       tree.NewFmtCtx(tree.FmtExport),
+	}, nil
+}
+
+// parquetColumnForType returns the parquet column that values of the given
+// type are exported to.
+func parquetColumnForType(name string, t *types.T) parquet.Column {
+	c := parquet.Column{Name: name}
+	if t.Family() == types.ArrayFamily {
+		c = parquetColumnForType(name, t.ArrayContents())
+		c.List = true
+		return c
+	}
+	switch t.Family() {
+	case types.BoolFamily:
+		c.Type = parquet.Boolean
+	case types.IntFamily:
+		c.Type = parquet.Int64
+	case types.FloatFamily:
+		c.Type = parquet.Double
+	case types.DecimalFamily:
+		if t.Precision() > 0 {
+			c.Type, c.Annotation = parquet.ByteArray, parquet.Decimal
+			c.Precision, c.Scale = t.Precision(), t.Scale()
+		} else {
+			// Decimals of unconstrained scale have no parquet counterpart.
+			c.Type, c.Annotation = parquet.ByteArray, parquet.UTF8
+		}
+	case types.BytesFamily:
+		c.Type = parquet.ByteArray
+	case types.DateFamily:
+		c.Type, c.Annotation = parquet.Int32, parquet.Date
+	case types.TimestampFamily, types.TimestampTZFamily:
+		c.Type, c.Annotation = parquet.Int64, parquet.TimestampMicros
+	case types.TimeFamily:
+		c.Type, c.Annotation = parquet.Int64, parquet.TimeMicros
+	case types.JsonFamily:
+		c.Type, c.Annotation = parquet.ByteArray, parquet.JSON
+	default:
+		c.Type, c.Annotation = parquet.ByteArray, parquet.UTF8
+	}
+	return c
+}
+
+func (p *parquetChunkWriter) addRow(row tree.Datums) error {
+	for i, d := range row {
+		c := &p.columns[i]
+		if d == tree.DNull {
+			p.row[i] = nil
+			continue
+		}
+		if !c.List {
+			v, err := p.parquetValue(c, d)
+			if err != nil {
+				return err
+			}
+			p.row[i] = v
+			continue
+		}
+		arr, ok := tree.UnwrapDatum(nil, d).(*tree.DArray)
+		if !ok {
+			return errors.Errorf("unexpected datum %T for list column %s", d, c.Name)
+		}
+		list := make([]interface{}, len(arr.Array))
+		for j, elem := range arr.Array {
+			if elem == tree.DNull {
+				continue
+			}
+			v, err := p.parquetValue(c, elem)
+			if err != nil {
+				return err
+			}
+			list[j] = v
+		}
+		p.row[i] = list
+	}
+	return p.w.AddRow(p.row)
+}
+
+// parquetValue converts a non-null datum to the value of the given column
+// accepted by parquet.Writer.
+func (p *parquetChunkWriter) parquetValue(c *parquet.Column, d tree.Datum) (interface{}, error) {
+	switch t := tree.UnwrapDatum(nil, d).(type) {
+	case *tree.DBool:
+		if c.Type == parquet.Boolean {
+			return bool(*t), nil
+		}
+	case *tree.DInt:
+		if c.Type == parquet.Int64 {
+			return int64(*t), nil
+		}
+	case *tree.DFloat:
+		if c.Type == parquet.Double {
+			return float64(*t), nil
+		}
+	case *tree.DDecimal:
+		if c.Annotation == parquet.Decimal {
+			return parquetDecimal(&t.Decimal, c)
+		}
+	case *tree.DBytes:
+		if c.Type == parquet.ByteArray && c.Annotation == parquet.NoAnnotation {
+			return []byte(*t), nil
+		}
+	case *tree.DString:
+		if c.Annotation == parquet.UTF8 {
+			return string(*t), nil
+		}
+	case *tree.DDate:
+		if c.Annotation == parquet.Date {
+			if !t.IsFinite() {
+				return nil, errors.Errorf("infinite date in column %s cannot be exported to parquet", c.Name)
+			}
+			return int32(t.UnixEpochDays()), nil
+		}
+	case *tree.DTimestamp:
+		if c.Annotation == parquet.TimestampMicros {
+			return t.Unix()*1e6 + int64(t.Nanosecond()/1e3), nil
+		}
+	case *tree.DTimestampTZ:
+		if c.Annotation == parquet.TimestampMicros {
+			return t.Unix()*1e6 + int64(t.Nanosecond()/1e3), nil
+		}
+	case *tree.DTime:
+		if c.Annotation == parquet.TimeMicros {
+			return int64(*t), nil
+		}
+	case *tree.DJSON:
+		if c.Annotation == parquet.JSON {
+			return t.JSON.String(), nil
+		}
+	}
+	if c.Annotation != parquet.UTF8 {
+		return nil, errors.Errorf("unexpected datum %T for parquet column %s", d, c.Name)
+	}
+	d.Format(p.f)
+	s := p.f.String()
+	p.f.Reset()
+	return s, nil
+}
+
+// parquetDecimal returns the unscaled value of a decimal, at the scale of the
+// given column, as a big-endian two's complement integer.
+func parquetDecimal(d *apd.Decimal, c *parquet.Column) ([]byte, error) {
+	if d.Form != apd.Finite {
+		return nil, errors.Errorf("%s value in column %s cannot be exported to parquet", d, c.Name)
+	}
+	var q apd.Decimal
+	q.Set(d)
+	if err := tree.LimitDecimalWidth(&q, int(c.Precision), int(c.Scale)); err != nil {
+		return nil, err
+	}
+	unscaled := new(big.Int).Set(&q.Coeff)
+	if q.Negative {
+		unscaled.Neg(unscaled)
+	}
+	return bigIntTwosComplement(unscaled), nil
+}
+
+// bigIntTwosComplement returns the minimal big-endian two's complement
+// representation of i.
+func bigIntTwosComplement(i *big.Int) []byte {
+	if i.Sign() >= 0 {
+		b := i.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+	// A negative number that takes n bytes is represented as 2^(8n) + i, where
+	// n leaves room for the sign bit of the magnitude of i - 1.
+	n := (new(big.Int).Not(i).BitLen() + 8) / 8
+	b := new(big.Int).Lsh(big.NewInt(1), uint(8*n))
+	return b.Add(b, i).Bytes()
+}
+
+func (p *parquetChunkWriter) finish() ([]byte, error) {
+	p.f.Close()
+	return p.w.Finish()
+}
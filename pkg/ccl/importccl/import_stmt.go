@@ -69,6 +69,10 @@ const (
 	pgCopyNull      = "nullif"
 
 	pgMaxRowSize = "max_row_size"
+
+	avroStrict = "strict_validation"
+
+	jsonlColumnMapping = "column_mapping"
 )
 
 var importOptionExpectValues = map[string]sql.KVStringOptValidate{
@@ -92,6 +96,10 @@ var importOptionExpectValues = map[string]sql.KVStringOptValidate{
 	importOptionSortedIngest: sql.KVStringOptRequireNoValue,
 
 	pgMaxRowSize: sql.KVStringOptRequireValue,
+
+	avroStrict: sql.KVStringOptRequireNoValue,
+
+	jsonlColumnMapping: sql.KVStringOptRequireValue,
 }
 
 func importJobDescription(
@@ -331,6 +339,20 @@ func importPlanHook(
 				maxRowSize = int32(sz)
 			}
 			format.PgDump.MaxRowSize = maxRowSize
+		case "AVRO":
+			telemetry.Count("import.format.avro")
+			format.Format = roachpb.IOFileFormat_Avro
+			_, format.Avro.StrictMode = opts[avroStrict]
+		case "JSONL":
+			telemetry.Count("import.format.jsonl")
+			format.Format = roachpb.IOFileFormat_JSONL
+			if override, ok := opts[jsonlColumnMapping]; ok {
+				mapping, err := parseJSONLColumnMapping(override)
+				if err != nil {
+					return err
+				}
+				format.JSONL.ColumnMapping = mapping
+			}
 		default:
 			return unimplemented.Newf("import.format", "unsupported import format: %q", importStmt.FileFormat)
 		}
@@ -422,16 +444,25 @@ func importPlanHook(
 				intoCols = append(intoCols, name.String())
 			}
 
-			// IMPORT INTO does not support columns with DEFAULT expressions. Ensure
-			// that all non-target columns are nullable until we support DEFAULT
-			// expressions.
+			// Non-target columns are populated with their DEFAULT expressions, or
+			// NULL if they have none, and computed columns are computed, so they
+			// can't be target columns. Without a list of target columns, every
+			// visible column is a target.
 			for _, col := range found.VisibleColumns() {
-				if col.HasDefault() {
-					return errors.Errorf("cannot IMPORT INTO a table with a DEFAULT expression for any of its columns")
+				isTarget := len(isTargetCol) == 0 || isTargetCol[col.Name]
+				if col.IsComputed() {
+					if isTarget {
+						if len(isTargetCol) == 0 {
+							return errors.Errorf(
+								"IMPORT INTO a table with computed column %q requires a list of target columns", col.Name)
+						}
+						return sqlbase.CannotWriteToComputedColError(col.Name)
+					}
+					continue
 				}
-
-				if len(isTargetCol) != 0 && !isTargetCol[col.Name] && !col.IsNullable() {
-					return errors.Errorf("all non-target columns in IMPORT INTO must be nullable")
+				if !isTarget && !col.IsNullable() && !col.HasDefault() {
+					return errors.Errorf(
+						"non-target column %q in IMPORT INTO must be nullable or have a DEFAULT expression", col.Name)
 				}
 			}
 
@@ -898,6 +929,22 @@ func (r *importResumer) Resume(
 		}
 	}
 
+	// OnFailOrCancel reverts existing tables to just before Walltime, so their
+	// history as of then must not be GC'd until the job is done. Until all
+	// nodes are upgraded, the GC TTL is all there is to rely on.
+	var existingSpans []roachpb.Span
+	for _, i := range details.Tables {
+		if !i.IsNew {
+			existingSpans = append(existingSpans, i.Desc.TableSpan())
+		}
+	}
+	if len(existingSpans) > 0 && p.ExecCfg().Settings.Version.IsActive(cluster.VersionProtectedTimestamps) {
+		ts := hlc.Timestamp{WallTime: details.Walltime}.Prev()
+		if err := r.job.ProtectTimestamp(ctx, ts, existingSpans); err != nil {
+			return errors.Wrap(err, "protecting timestamp")
+		}
+	}
+
 	// TODO(jeffreyxiao): Remove this check in 20.1.
 	// If the cluster supports sticky bits, then we don't have to worry about the
 	// merge queue automatically merging the splits performed during IMPORT.
@@ -971,11 +1018,11 @@ func (r *importResumer) OnFailOrCancel(ctx context.Context, txn *client.Txn) err
 	// better to do the revert here so that the table comes back if and only if,
 	// it was rolled back to its pre-IMPORT state, and instead provide a manual
 	// admin knob (e.g. ALTER TABLE REVERT TO SYSTEM TIME) if anything goes wrong.
-	if len(revert) > 0 {
-		// Sanity check Walltime so it doesn't become a TRUNCATE if there's a bug.
-		if details.Walltime == 0 {
-			return errors.Errorf("invalid pre-IMPORT time to rollback")
-		}
+	//
+	// A zero Walltime means the job failed before choosing the time to IMPORT
+	// at, so nothing was ingested and there is nothing to revert. Reverting to
+	// it would instead TRUNCATE the tables.
+	if len(revert) > 0 && details.Walltime != 0 {
 		ts := hlc.Timestamp{WallTime: details.Walltime}.Prev()
 		if err := sql.RevertTables(ctx, txn.DB(), revert, ts, sql.RevertTableDefaultBatchSize); err != nil {
 			return errors.Wrap(err, "rolling back partially completed IMPORT")
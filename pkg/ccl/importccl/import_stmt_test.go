@@ -45,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/linkedin/goavro"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 )
@@ -1673,8 +1674,8 @@ func TestImportIntoCSV(t *testing.T) {
 	})
 
 	// Tests IMPORT INTO with a target column set which does not include all PKs.
-	// As a result the non-target column is non-nullable, which is not allowed
-	// until we support DEFAULT expressions.
+	// As a result the non-target column is non-nullable and has no DEFAULT
+	// expression, which is not allowed.
 	t.Run("target-cols-excluding-explicit-pk", func(t *testing.T) {
 		sqlDB.Exec(t, `CREATE TABLE t (a INT PRIMARY KEY, b STRING)`)
 		defer sqlDB.Exec(t, `DROP TABLE t`)
@@ -1682,7 +1683,7 @@ func TestImportIntoCSV(t *testing.T) {
 		// Expect an error if attempting to IMPORT INTO a target list which does
 		// not include all the PKs of the table.
 		sqlDB.ExpectErr(
-			t, `pq: all non-target columns in IMPORT INTO must be nullable`,
+			t, `pq: non-target column "a" in IMPORT INTO must be nullable or have a DEFAULT expression`,
 			fmt.Sprintf(`IMPORT INTO t (b) CSV DATA (%s)`, testFiles.files[0]),
 		)
 	})
@@ -1749,22 +1750,67 @@ func TestImportIntoCSV(t *testing.T) {
 		}
 	})
 
-	// IMPORT INTO does not support DEFAULT expressions for either target or
-	// non-target columns.
-	t.Run("import-into-check-no-default-cols", func(t *testing.T) {
-		sqlDB.Exec(t, `CREATE TABLE t (a INT DEFAULT 1, b STRING)`)
+	// Tests that the non-target columns of IMPORT INTO are populated with their
+	// DEFAULT expressions, including volatile ones, and that computed columns
+	// are computed.
+	t.Run("import-into-default-and-computed-cols", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE TABLE t (
+			a INT,
+			b STRING,
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			r INT DEFAULT unique_rowid(),
+			created TIMESTAMPTZ NOT NULL DEFAULT now(),
+			c INT DEFAULT 7,
+			d INT AS (a + c) STORED
+		)`)
 		defer sqlDB.Exec(t, `DROP TABLE t`)
 
-		// Insert the test data
-		insert := []string{"''", "'text'", "'a'", "'e'", "'l'", "'t'", "'z'"}
-
-		for i, v := range insert {
-			sqlDB.Exec(t, "INSERT INTO t (a, b) VALUES ($1, $2)", i, v)
+		sqlDB.Exec(t, fmt.Sprintf(`IMPORT INTO t (a, b) CSV DATA (%s)`, testFiles.files[0]))
+		sqlDB.CheckQueryResults(t, `SELECT
+			count(*), count(DISTINCT id), count(DISTINCT r), count(DISTINCT created),
+			count(*) FILTER (WHERE c = 7), count(*) FILTER (WHERE d = a + 7)
+		FROM t`, [][]string{{
+			fmt.Sprint(rowsPerFile), fmt.Sprint(rowsPerFile), fmt.Sprint(rowsPerFile), "1",
+			fmt.Sprint(rowsPerFile), fmt.Sprint(rowsPerFile),
+		}})
+
+		// The volatile DEFAULT expressions are seeded by the position of the row
+		// in the source, so importing it again, e.g. when the import is resumed,
+		// yields the same keys rather than duplicates.
+		for _, name := range []string{"t2", "t3"} {
+			sqlDB.Exec(t, fmt.Sprintf(`CREATE TABLE %s (
+				a INT,
+				b STRING,
+				id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				r INT DEFAULT unique_rowid(),
+				f INT DEFAULT (random() * 1000000)::INT,
+				u BYTES DEFAULT uuid_v4()
+			)`, name))
+			defer sqlDB.Exec(t, fmt.Sprintf(`DROP TABLE %s`, name))
+			sqlDB.Exec(t, fmt.Sprintf(`IMPORT INTO %s (a, b) CSV DATA (%s)`, name, testFiles.files[0]))
 		}
+		sqlDB.CheckQueryResults(t,
+			`SELECT count(*), count(DISTINCT u) FROM t2 JOIN t USING (id, r)`,
+			[][]string{{fmt.Sprint(rowsPerFile), fmt.Sprint(rowsPerFile)}},
+		)
+		sqlDB.CheckQueryResults(t,
+			`SELECT count(*) FROM t2 JOIN t3 USING (id, r, f, u)`, [][]string{{fmt.Sprint(rowsPerFile)}},
+		)
 
+		sqlDB.Exec(t, `CREATE TABLE t4 (a INT, b STRING, ts TIMESTAMPTZ DEFAULT clock_timestamp())`)
+		defer sqlDB.Exec(t, `DROP TABLE t4`)
 		sqlDB.ExpectErr(
-			t, fmt.Sprintf("pq: cannot IMPORT INTO a table with a DEFAULT expression for any of its columns"),
-			fmt.Sprintf(`IMPORT INTO t (a) CSV DATA (%s)`, testFiles.files[0]),
+			t, `IMPORT does not support the volatile function clock_timestamp\(\) in the DEFAULT expression of column "ts"`,
+			fmt.Sprintf(`IMPORT INTO t4 (a, b) CSV DATA (%s)`, testFiles.files[0]),
+		)
+
+		sqlDB.ExpectErr(
+			t, `cannot write directly to computed column "d"`,
+			fmt.Sprintf(`IMPORT INTO t (a, d) CSV DATA (%s)`, testFiles.files[0]),
+		)
+		sqlDB.ExpectErr(
+			t, `IMPORT INTO a table with computed column "d" requires a list of target columns`,
+			fmt.Sprintf(`IMPORT INTO t CSV DATA (%s)`, testFiles.files[0]),
 		)
 	})
 
@@ -2766,6 +2812,99 @@ func TestImportPgCopy(t *testing.T) {
 	}
 }
 
+func TestImportJSONL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	baseDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+	if err := ioutil.WriteFile(filepath.Join(baseDir, "data.jsonl"), []byte(`
+{"i": 1, "s": "a", "j": {"k": [1, 2]}, "n": {"x": 1.5}}
+{"i": 2, "s": null, "extra": true}
+
+{"i": 3, "j": null, "n": {"x": "2"}}
+`), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(baseDir, "bad.jsonl"), []byte(`{"i": 1}
+[1, 2]
+`), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	args := base.TestServerArgs{ExternalIODir: baseDir}
+	tc := testcluster.StartTestCluster(t, 1, base.TestClusterArgs{ServerArgs: args})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(tc.Conns[0])
+
+	sqlDB.Exec(t, `IMPORT TABLE t (i INT8 PRIMARY KEY, s STRING, j JSONB, x DECIMAL) JSONL DATA ($1)
+		WITH column_mapping = 'x=n.x'`, "nodelocal:///data.jsonl")
+	sqlDB.CheckQueryResults(t, `SELECT * FROM t ORDER BY i`, [][]string{
+		{"1", "a", `{"k": [1, 2]}`, "1.5"},
+		{"2", "NULL", "NULL", "NULL"},
+		{"3", "NULL", "NULL", "2"},
+	})
+
+	sqlDB.Exec(t, `CREATE TABLE u (i INT8 PRIMARY KEY, s STRING DEFAULT 'def')`)
+	sqlDB.Exec(t, `IMPORT INTO u (i) JSONL DATA ($1)`, "nodelocal:///data.jsonl")
+	sqlDB.CheckQueryResults(t, `SELECT i FROM u ORDER BY i`, [][]string{{"1"}, {"2"}, {"3"}})
+
+	sqlDB.ExpectErr(t, `bad.jsonl": row 2: parsing JSON object`,
+		`IMPORT TABLE bad (i INT8 PRIMARY KEY) JSONL DATA ($1)`, "nodelocal:///bad.jsonl")
+	sqlDB.ExpectErr(t, `column "nope" in the column mapping is not a target column`,
+		`IMPORT TABLE bad (i INT8 PRIMARY KEY) JSONL DATA ($1) WITH column_mapping = 'nope=i'`,
+		"nodelocal:///data.jsonl")
+	sqlDB.ExpectErr(t, `invalid column_mapping entry "i": expected column=key`,
+		`IMPORT TABLE bad (i INT8 PRIMARY KEY) JSONL DATA ($1) WITH column_mapping = 'i'`,
+		"nodelocal:///data.jsonl")
+}
+
+func TestImportAvro(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	baseDir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	ocf, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &buf, Schema: `{
+		"type": "record", "name": "r", "fields": [
+			{"name": "I", "type": "long"},
+			{"name": "s", "type": ["null", "string"]},
+			{"name": "ts", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+			{"name": "extra", "type": "boolean"}
+		]}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	if err := ocf.Append([]interface{}{
+		map[string]interface{}{"I": int64(1), "s": goavro.Union("string", "a"), "ts": ts, "extra": true},
+		map[string]interface{}{"I": int64(2), "s": nil, "ts": ts.Add(time.Second), "extra": false},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(baseDir, "data.avro"), buf.Bytes(), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	args := base.TestServerArgs{ExternalIODir: baseDir}
+	tc := testcluster.StartTestCluster(t, 1, base.TestClusterArgs{ServerArgs: args})
+	defer tc.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(tc.Conns[0])
+
+	sqlDB.Exec(t, `IMPORT TABLE t (i INT8 PRIMARY KEY, s STRING, ts TIMESTAMP) AVRO DATA ($1)`,
+		"nodelocal:///data.avro")
+	sqlDB.CheckQueryResults(t, `SELECT i, s, extract(epoch FROM ts)::INT8 FROM t ORDER BY i`, [][]string{
+		{"1", "a", "1569931200"},
+		{"2", "NULL", "1569931201"},
+	})
+
+	sqlDB.ExpectErr(t, `avro field "extra" does not match any target column`,
+		`IMPORT TABLE strict (i INT8 PRIMARY KEY, s STRING, ts TIMESTAMP) AVRO DATA ($1) WITH strict_validation`,
+		"nodelocal:///data.avro")
+}
+
 func TestImportPgDump(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
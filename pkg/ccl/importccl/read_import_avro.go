@@ -0,0 +1,241 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"context"
+	gojson "encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/cockroachdb/errors"
+	"github.com/linkedin/goavro"
+)
+
+// avroInputReader reads avro object container files, whose records each hold
+// the values of one row. Record fields are matched to the target columns by
+// name.
+type avroInputReader struct {
+	conv row.DatumRowConverter
+	cols namedTargetColumns
+	opts roachpb.AvroOptions
+}
+
+var _ inputConverter = &avroInputReader{}
+
+func newAvroInputReader(
+	kvCh chan row.KVBatch,
+	opts roachpb.AvroOptions,
+	tableDesc *sqlbase.TableDescriptor,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+) (*avroInputReader, error) {
+	conv, err := row.NewDatumRowConverter(tableDesc, targetCols, evalCtx, kvCh)
+	if err != nil {
+		return nil, err
+	}
+	return &avroInputReader{conv: *conv, cols: makeNamedTargetColumns(conv), opts: opts}, nil
+}
+
+func (a *avroInputReader) start(ctx ctxgroup.Group) {
+}
+
+func (a *avroInputReader) inputFinished(ctx context.Context) {
+	close(a.conv.KvCh)
+}
+
+func (a *avroInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]importResumePos,
+	format roachpb.IOFileFormat,
+	progressFn func(float32) error,
+	settings *cluster.Settings,
+) error {
+	return readInputFiles(ctx, dataFiles, resumePos, format, a.readFile, progressFn, settings)
+}
+
+// avroRecordSchema is the subset of an avro record schema needed to decode the
+// records of a file.
+type avroRecordSchema struct {
+	Type   string `json:"type"`
+	Fields []struct {
+		Name string            `json:"name"`
+		Type gojson.RawMessage `json:"type"`
+	} `json:"fields"`
+}
+
+// avroField describes how the values of a field of the records of a file are
+// imported.
+type avroField struct {
+	name string
+	// union is set if the field's type is a union, whose values the avro
+	// library wraps in a map from the name of the value's type to the value.
+	union bool
+	// col is the index of the datum the field is imported into, or -1 if the
+	// field matches no target column.
+	col int
+}
+
+func (a *avroInputReader) readFile(
+	ctx context.Context, input *fileReader, inputIdx int32, inputName string, progressFn progressFn,
+) error {
+	a.conv.KvBatch.Source = inputIdx
+	a.conv.KvBatch.FirstRow = input.resume.row + 1
+	a.conv.FractionFn = input.ReadFraction
+
+	ocf, err := goavro.NewOCFReader(bufio.NewReader(input))
+	if err != nil {
+		return wrapRowErr(err, inputName, 0, pgcode.Syntax, "reading avro file")
+	}
+	fields, err := a.matchFields(ocf.Codec().Schema())
+	if err != nil {
+		return wrapRowErr(err, inputName, 0, pgcode.Syntax, "")
+	}
+
+	count := int64(0)
+	for ocf.Scan() {
+		count++
+		native, err := ocf.Read()
+		if err != nil {
+			return wrapRowErr(err, inputName, count, pgcode.Syntax, "")
+		}
+		// Skip the rows ingested by a previous attempt of the IMPORT.
+		if uint64(count) <= input.resume.row {
+			continue
+		}
+		if err := a.convertRecord(native, fields); err != nil {
+			return wrapRowErr(err, inputName, count, pgcode.Syntax, "")
+		}
+		a.conv.SetRowPosition(uint64(count), 0)
+		if err := a.conv.Row(ctx, inputIdx, count); err != nil {
+			return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "")
+		}
+	}
+	if err := ocf.Err(); err != nil {
+		return wrapRowErr(err, inputName, count, pgcode.Syntax, "")
+	}
+	return a.conv.SendBatch(ctx)
+}
+
+// matchFields matches the fields of the records described by the given
+// writer schema to the target columns, first by exact name and then ignoring
+// case. In strict mode, every field must match a column.
+func (a *avroInputReader) matchFields(schemaJSON string) ([]avroField, error) {
+	var schema avroRecordSchema
+	if err := gojson.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return nil, errors.Wrap(err, "parsing avro schema")
+	}
+	if schema.Type != "record" {
+		return nil, errors.Errorf("expected avro records, found %q", schema.Type)
+	}
+	lowerCols := make(map[string]int, len(a.cols.names))
+	for i, name := range a.cols.names {
+		lowerCols[strings.ToLower(name)] = i
+	}
+	fields := make([]avroField, len(schema.Fields))
+	for i, f := range schema.Fields {
+		fields[i] = avroField{
+			name:  f.Name,
+			union: strings.HasPrefix(strings.TrimSpace(string(f.Type)), "["),
+			col:   -1,
+		}
+		if col, ok := a.cols.byName[f.Name]; ok {
+			fields[i].col = col
+		} else if col, ok := lowerCols[strings.ToLower(f.Name)]; ok {
+			fields[i].col = col
+		} else if a.opts.StrictMode {
+			return nil, errors.Errorf("avro field %q does not match any target column", f.Name)
+		}
+	}
+	return fields, nil
+}
+
+func (a *avroInputReader) convertRecord(native interface{}, fields []avroField) error {
+	record, ok := native.(map[string]interface{})
+	if !ok {
+		return errors.Errorf("expected an avro record, found %T", native)
+	}
+	for i := range a.conv.Datums {
+		a.conv.Datums[i] = tree.DNull
+	}
+	for _, f := range fields {
+		if f.col < 0 {
+			continue
+		}
+		v := record[f.name]
+		if u, ok := v.(map[string]interface{}); ok && f.union {
+			for _, inner := range u {
+				v = inner
+			}
+		}
+		t := a.cols.types[f.col]
+		d, err := datumFromAvro(v, t, a.conv.EvalCtx)
+		if err != nil {
+			return errors.Wrapf(err, "parse %q as %s", a.cols.names[f.col], t.SQLString())
+		}
+		a.conv.Datums[f.col] = d
+	}
+	return nil
+}
+
+// datumFromAvro converts a value decoded by the avro library to a datum of
+// the given type. The library decodes values of logical types to time.Time,
+// time.Duration and *big.Rat, which are handled here; everything else is
+// converted like a value decoded from JSON.
+func datumFromAvro(v interface{}, t *types.T, evalCtx *tree.EvalContext) (tree.Datum, error) {
+	switch v := v.(type) {
+	case time.Time:
+		switch t.Family() {
+		case types.TimestampFamily:
+			return tree.MakeDTimestamp(v, time.Microsecond), nil
+		case types.TimestampTZFamily:
+			return tree.MakeDTimestampTZ(v, time.Microsecond), nil
+		case types.DateFamily:
+			return tree.NewDDateFromTime(v)
+		}
+		return datumFromNative(v.Format(time.RFC3339Nano), t, evalCtx)
+	case time.Duration:
+		if t.Family() == types.TimeFamily {
+			return tree.MakeDTime(timeofday.TimeOfDay(v / time.Microsecond)), nil
+		}
+		return datumFromNative(int64(v/time.Microsecond), t, evalCtx)
+	case *big.Rat:
+		return datumFromNative(v.FloatString(ratScale(v)), t, evalCtx)
+	}
+	return datumFromNative(v, t, evalCtx)
+}
+
+// ratScale returns the number of decimal digits needed to represent the given
+// rational exactly. Avro decimals always have a denominator that divides a
+// power of ten, but other values are cut off at maxRatScale digits.
+func ratScale(r *big.Rat) int {
+	const maxRatScale = 64
+	pow := big.NewInt(1)
+	ten := big.NewInt(10)
+	var rem big.Int
+	for scale := 0; scale < maxRatScale; scale++ {
+		if rem.Mod(pow, r.Denom()).Sign() == 0 {
+			return scale
+		}
+		pow.Mul(pow, ten)
+	}
+	return maxRatScale
+}
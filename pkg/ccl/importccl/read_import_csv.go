@@ -11,6 +11,7 @@ package importccl
 import (
 	"context"
 	"io"
+	"io/ioutil"
 	"runtime"
 	"time"
 
@@ -81,11 +82,12 @@ func (c *csvInputReader) inputFinished(_ context.Context) {
 func (c *csvInputReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
+	resumePos map[int32]importResumePos,
 	format roachpb.IOFileFormat,
 	progressFn func(float32) error,
 	settings *cluster.Settings,
 ) error {
-	return readInputFiles(ctx, dataFiles, format, c.readFile, progressFn, settings)
+	return readInputFiles(ctx, dataFiles, resumePos, format, c.readFile, progressFn, settings)
 }
 
 func (c *csvInputReader) flushBatch(ctx context.Context, finished bool, progFn progressFn) error {
@@ -102,6 +104,7 @@ func (c *csvInputReader) flushBatch(ctx context.Context, finished bool, progFn p
 	}
 	if !finished {
 		c.batch.r = make([][]string, 0, c.batchSize)
+		c.batch.endOffsets = make([]int64, 0, c.batchSize)
 	}
 	return nil
 }
@@ -109,6 +112,16 @@ func (c *csvInputReader) flushBatch(ctx context.Context, finished bool, progFn p
 func (c *csvInputReader) readFile(
 	ctx context.Context, input *fileReader, inputIdx int32, inputName string, progressFn progressFn,
 ) error {
+	// If a previous attempt of the IMPORT recorded the offset up to which it
+	// ingested the file, resume reading from there. Otherwise the rows it
+	// ingested are read, and skipped, below.
+	resume := input.resume
+	if resume.offset > 0 {
+		if _, err := io.CopyN(ioutil.Discard, input, resume.offset); err != nil {
+			return errors.Wrap(err, "seeking to resume offset")
+		}
+	}
+
 	cr := csv.NewReader(input)
 	if c.opts.Comma != 0 {
 		cr.Comma = c.opts.Comma
@@ -118,13 +131,18 @@ func (c *csvInputReader) readFile(
 	cr.Comment = c.opts.Comment
 
 	c.batch = csvRecord{
-		file:      inputName,
-		fileIndex: inputIdx,
-		rowOffset: 1,
-		r:         make([][]string, 0, c.batchSize),
+		file:       inputName,
+		fileIndex:  inputIdx,
+		startRow:   resume.row + 1,
+		r:          make([][]string, 0, c.batchSize),
+		endOffsets: make([]int64, 0, c.batchSize),
 	}
 
-	for i := 1; ; i++ {
+	i := 1
+	if resume.offset > 0 {
+		i = int(resume.row) + 1
+	}
+	for ; ; i++ {
 		record, err := cr.Read()
 		finished := err == io.EOF
 		if finished || len(c.batch.r) >= c.batchSize {
@@ -132,7 +150,7 @@ func (c *csvInputReader) readFile(
 			if err := c.flushBatch(ctx, finished, progressFn); err != nil {
 				return err
 			}
-			c.batch.rowOffset = i
+			c.batch.startRow = uint64(i)
 		}
 		if finished {
 			break
@@ -140,8 +158,8 @@ func (c *csvInputReader) readFile(
 		if err != nil {
 			return errors.Wrapf(err, "row %d: reading CSV record", i)
 		}
-		// Ignore the first N lines.
-		if uint32(i) <= c.opts.Skip {
+		// Ignore the first N lines, and those ingested by a previous attempt.
+		if uint32(i) <= c.opts.Skip || uint64(i) <= resume.row {
 			continue
 		}
 		if len(record) == c.expectedCols {
@@ -152,7 +170,11 @@ func (c *csvInputReader) readFile(
 		} else {
 			return errors.Errorf("row %d: expected %d fields, got %d", i, c.expectedCols, len(record))
 		}
+		if len(c.batch.r) == 0 {
+			c.batch.rowOffset = i
+		}
 		c.batch.r = append(c.batch.r, record)
+		c.batch.endOffsets = append(c.batch.endOffsets, resume.offset+cr.InputOffset())
 	}
 	return nil
 }
@@ -161,8 +183,14 @@ type csvRecord struct {
 	r         [][]string
 	file      string
 	fileIndex int32
+	// startRow is the first row of the file that the batch covers, including
+	// any skipped rows before the first record in r.
+	startRow uint64
+	// rowOffset is the row number of the first record in r.
 	rowOffset int
-	progress  float32
+	// endOffsets are the byte offsets in the file just past each record in r.
+	endOffsets []int64
+	progress   float32
 }
 
 // convertRecordWorker converts CSV records into KV pairs and sends them on the
@@ -183,11 +211,18 @@ func (c *csvInputReader) convertRecordWorker(ctx context.Context) error {
 	timestamp := uint64(c.walltime) / precision
 
 	for batch := range c.recordCh {
-		if conv.KvBatch.Source != batch.fileIndex {
+		// The rows a KvBatch covers must be contiguous, but this worker may not
+		// have converted the batches of records right before this one.
+		nextRow := conv.KvBatch.FirstRow
+		if conv.KvBatch.LastRow != 0 {
+			nextRow = conv.KvBatch.LastRow + 1
+		}
+		if conv.KvBatch.Source != batch.fileIndex || nextRow != batch.startRow {
 			if err := conv.SendBatch(ctx); err != nil {
 				return err
 			}
 			conv.KvBatch.Source = batch.fileIndex
+			conv.KvBatch.FirstRow = batch.startRow
 		}
 		conv.KvBatch.Progress = batch.progress
 		for batchIdx, record := range batch.r {
@@ -214,6 +249,7 @@ func (c *csvInputReader) convertRecordWorker(ctx context.Context) error {
 			}
 
 			rowIndex := int64(timestamp) + rowNum
+			conv.SetRowPosition(uint64(rowNum), batch.endOffsets[batchIdx])
 			if err := conv.Row(ctx, batch.fileIndex, rowIndex); err != nil {
 				return wrapRowErr(err, batch.file, rowNum, pgcode.Uncategorized, "")
 			}
@@ -0,0 +1,227 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	gojson "encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/errors"
+)
+
+// jsonlInputReader reads newline-delimited JSON, in which each line holds an
+// object with the values of one row.
+type jsonlInputReader struct {
+	conv row.DatumRowConverter
+	cols namedTargetColumns
+	// keys holds, for each datum of the row converter, the path of keys that
+	// the datum is read from.
+	keys [][]string
+}
+
+var _ inputConverter = &jsonlInputReader{}
+
+func newJSONLInputReader(
+	kvCh chan row.KVBatch,
+	opts roachpb.JSONLOptions,
+	tableDesc *sqlbase.TableDescriptor,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+) (*jsonlInputReader, error) {
+	conv, err := row.NewDatumRowConverter(tableDesc, targetCols, evalCtx, kvCh)
+	if err != nil {
+		return nil, err
+	}
+	cols := makeNamedTargetColumns(conv)
+	keys := make([][]string, len(cols.names))
+	for i, name := range cols.names {
+		keys[i] = []string{name}
+	}
+	for _, m := range opts.ColumnMapping {
+		i, ok := cols.byName[m.Column]
+		if !ok {
+			return nil, pgerror.Newf(pgcode.UndefinedColumn,
+				"column %q in the column mapping is not a target column", m.Column)
+		}
+		keys[i] = strings.Split(m.Key, ".")
+	}
+	return &jsonlInputReader{conv: *conv, cols: cols, keys: keys}, nil
+}
+
+// parseJSONLColumnMapping parses the value of the column_mapping option, a
+// comma-separated list of column=key entries.
+func parseJSONLColumnMapping(s string) ([]roachpb.JSONLOptions_ColumnMapping, error) {
+	var mapping []roachpb.JSONLOptions_ColumnMapping
+	for _, entry := range strings.Split(s, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, pgerror.Newf(pgcode.Syntax,
+				"invalid %s entry %q: expected column=key", jsonlColumnMapping, entry)
+		}
+		col, key := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if col == "" || key == "" {
+			return nil, pgerror.Newf(pgcode.Syntax,
+				"invalid %s entry %q: expected column=key", jsonlColumnMapping, entry)
+		}
+		mapping = append(mapping, roachpb.JSONLOptions_ColumnMapping{Column: col, Key: key})
+	}
+	return mapping, nil
+}
+
+func (j *jsonlInputReader) start(ctx ctxgroup.Group) {
+}
+
+func (j *jsonlInputReader) inputFinished(ctx context.Context) {
+	close(j.conv.KvCh)
+}
+
+func (j *jsonlInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	resumePos map[int32]importResumePos,
+	format roachpb.IOFileFormat,
+	progressFn func(float32) error,
+	settings *cluster.Settings,
+) error {
+	return readInputFiles(ctx, dataFiles, resumePos, format, j.readFile, progressFn, settings)
+}
+
+func (j *jsonlInputReader) readFile(
+	ctx context.Context, input *fileReader, inputIdx int32, inputName string, progressFn progressFn,
+) error {
+	r := bufio.NewReader(input)
+	j.conv.KvBatch.Source = inputIdx
+	j.conv.KvBatch.FirstRow = input.resume.row + 1
+	j.conv.FractionFn = input.ReadFraction
+
+	for count := int64(1); ; count++ {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "")
+		}
+		eof := err == io.EOF
+		// Skip the rows ingested by a previous attempt of the IMPORT.
+		if line = bytes.TrimSpace(line); len(line) > 0 && uint64(count) > input.resume.row {
+			if err := j.convertLine(line); err != nil {
+				return wrapRowErr(err, inputName, count, pgcode.Syntax, "")
+			}
+			j.conv.SetRowPosition(uint64(count), 0)
+			if err := j.conv.Row(ctx, inputIdx, count); err != nil {
+				return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "")
+			}
+		}
+		if eof {
+			break
+		}
+	}
+	return j.conv.SendBatch(ctx)
+}
+
+func (j *jsonlInputReader) convertLine(line []byte) error {
+	dec := gojson.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return errors.Wrap(err, "parsing JSON object")
+	}
+	if obj == nil {
+		return errors.New("expected a JSON object, found null")
+	}
+	for i, path := range j.keys {
+		v := lookupJSONPath(obj, path)
+		d, err := datumFromNative(v, j.cols.types[i], j.conv.EvalCtx)
+		if err != nil {
+			return errors.Wrapf(err, "parse %q as %s", j.cols.names[i], j.cols.types[i].SQLString())
+		}
+		j.conv.Datums[i] = d
+	}
+	return nil
+}
+
+// lookupJSONPath returns the value at the given path of keys of nested
+// objects, or nil if there is none.
+func lookupJSONPath(obj map[string]interface{}, path []string) interface{} {
+	var v interface{} = obj
+	for _, key := range path {
+		o, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = o[key]
+	}
+	return v
+}
+
+// namedTargetColumns describes the target columns of an import from a format
+// whose values are identified by name, rather than by position. The columns
+// are listed in the order of the datums of the import's row converter.
+type namedTargetColumns struct {
+	names  []string
+	types  []*types.T
+	byName map[string]int
+}
+
+func makeNamedTargetColumns(conv *row.DatumRowConverter) namedTargetColumns {
+	var cols namedTargetColumns
+	cols.byName = make(map[string]int)
+	for i := range conv.VisibleCols {
+		if _, ok := conv.IsTargetCol[i]; !ok {
+			continue
+		}
+		cols.byName[conv.VisibleCols[i].Name] = len(cols.names)
+		cols.names = append(cols.names, conv.VisibleCols[i].Name)
+		cols.types = append(cols.types, conv.VisibleColTypes[i])
+	}
+	return cols
+}
+
+// datumFromNative converts a value decoded from JSON or Avro to a datum of the
+// given type. Scalars are converted through their string representation, so
+// that e.g. a number can be imported into a STRING or DECIMAL column, and
+// objects and arrays through their JSON representation.
+func datumFromNative(v interface{}, t *types.T, evalCtx *tree.EvalContext) (tree.Datum, error) {
+	switch v := v.(type) {
+	case nil:
+		return tree.DNull, nil
+	case string:
+		return tree.ParseDatumStringAs(t, v, evalCtx)
+	case []byte:
+		if t.Family() == types.BytesFamily {
+			return tree.NewDBytes(tree.DBytes(v)), nil
+		}
+		return tree.ParseDatumStringAs(t, string(v), evalCtx)
+	case bool, int32, int64, gojson.Number:
+		return tree.ParseDatumStringAs(t, fmt.Sprint(v), evalCtx)
+	case float32:
+		return tree.ParseDatumStringAs(t, strconv.FormatFloat(float64(v), 'g', -1, 32), evalCtx)
+	case float64:
+		return tree.ParseDatumStringAs(t, strconv.FormatFloat(v, 'g', -1, 64), evalCtx)
+	default:
+		encoded, err := gojson.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return tree.ParseDatumStringAs(t, string(encoded), evalCtx)
+	}
+}
@@ -84,11 +84,12 @@ func (m *mysqldumpReader) inputFinished(ctx context.Context) {
 func (m *mysqldumpReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
+	resumePos map[int32]importResumePos,
 	format roachpb.IOFileFormat,
 	progressFn func(float32) error,
 	settings *cluster.Settings,
 ) error {
-	return readInputFiles(ctx, dataFiles, format, m.readFile, progressFn, settings)
+	return readInputFiles(ctx, dataFiles, resumePos, format, m.readFile, progressFn, settings)
 }
 
 func (m *mysqldumpReader) readFile(
@@ -56,17 +56,19 @@ func (d *mysqloutfileReader) inputFinished(ctx context.Context) {
 func (d *mysqloutfileReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
+	resumePos map[int32]importResumePos,
 	format roachpb.IOFileFormat,
 	progressFn func(float32) error,
 	settings *cluster.Settings,
 ) error {
-	return readInputFiles(ctx, dataFiles, format, d.readFile, progressFn, settings)
+	return readInputFiles(ctx, dataFiles, resumePos, format, d.readFile, progressFn, settings)
 }
 
 func (d *mysqloutfileReader) readFile(
 	ctx context.Context, input *fileReader, inputIdx int32, inputName string, progressFn progressFn,
 ) error {
 	d.conv.KvBatch.Source = inputIdx
+	d.conv.KvBatch.FirstRow = input.resume.row + 1
 	d.conv.FractionFn = input.ReadFraction
 	var count int64 = 1
 
@@ -115,9 +117,13 @@ func (d *mysqloutfileReader) readFile(
 		return nil
 	}
 	addRow := func() error {
-		copy(d.conv.Datums, row)
-		if err := d.conv.Row(ctx, inputIdx, count); err != nil {
-			return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "")
+		// Skip the rows ingested by a previous attempt of the IMPORT.
+		if uint64(count) > input.resume.row {
+			copy(d.conv.Datums, row)
+			d.conv.SetRowPosition(uint64(count), 0)
+			if err := d.conv.Row(ctx, inputIdx, count); err != nil {
+				return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "")
+			}
 		}
 		count++
 
@@ -64,11 +64,12 @@ func (d *pgCopyReader) inputFinished(ctx context.Context) {
 func (d *pgCopyReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
+	resumePos map[int32]importResumePos,
 	format roachpb.IOFileFormat,
 	progressFn func(float32) error,
 	settings *cluster.Settings,
 ) error {
-	return readInputFiles(ctx, dataFiles, format, d.readFile, progressFn, settings)
+	return readInputFiles(ctx, dataFiles, resumePos, format, d.readFile, progressFn, settings)
 }
 
 type postgreStreamCopy struct {
@@ -269,6 +270,7 @@ func (d *pgCopyReader) readFile(
 		d.opts.Null,
 	)
 	d.conv.KvBatch.Source = inputIdx
+	d.conv.KvBatch.FirstRow = input.resume.row + 1
 	d.conv.FractionFn = input.ReadFraction
 
 	for count := int64(1); ; count++ {
@@ -279,6 +281,10 @@ func (d *pgCopyReader) readFile(
 		if err != nil {
 			return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "")
 		}
+		// Skip the rows ingested by a previous attempt of the IMPORT.
+		if uint64(count) <= input.resume.row {
+			continue
+		}
 		if len(row) != len(d.conv.VisibleColTypes) {
 			return makeRowErr(inputName, count, pgcode.Syntax,
 				"expected %d values, got %d", len(d.conv.VisibleColTypes), len(row))
@@ -296,6 +302,7 @@ func (d *pgCopyReader) readFile(
 			}
 		}
 
+		d.conv.SetRowPosition(uint64(count), 0)
 		if err := d.conv.Row(ctx, inputIdx, count); err != nil {
 			return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "")
 		}
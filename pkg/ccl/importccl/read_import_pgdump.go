@@ -438,11 +438,12 @@ func (m *pgDumpReader) inputFinished(ctx context.Context) {
 func (m *pgDumpReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
+	resumePos map[int32]importResumePos,
 	format roachpb.IOFileFormat,
 	progressFn func(float32) error,
 	settings *cluster.Settings,
 ) error {
-	return readInputFiles(ctx, dataFiles, format, m.readFile, progressFn, settings)
+	return readInputFiles(ctx, dataFiles, resumePos, format, m.readFile, progressFn, settings)
 }
 
 func (m *pgDumpReader) readFile(
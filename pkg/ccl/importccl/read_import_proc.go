@@ -17,6 +17,7 @@ import (
 	"math"
 	"math/rand"
 	"net/url"
+	"sort"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -40,6 +41,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/errors"
 )
@@ -48,7 +51,9 @@ type readFileFunc func(context.Context, *fileReader, int32, string, progressFn)
 
 // readInputFile reads each of the passed dataFiles using the passed func. The
 // key part of dataFiles is the unique index of the data file among all files in
-// the IMPORT. progressFn, if not nil, is periodically invoked with a percentage
+// the IMPORT. resumePos holds the position up to which a previous attempt of
+// the IMPORT ingested each file; it is made available to the func through the
+// fileReader, and files that were entirely ingested are skipped. progressFn, if not nil, is periodically invoked with a percentage
 // of the total progress of reading through all of the files. This percentage
 // attempts to use the Size() method of ExportStorage to determine how many
 // bytes must be read of the input files, and reports the percent of bytes read
@@ -57,6 +62,7 @@ type readFileFunc func(context.Context, *fileReader, int32, string, progressFn)
 func readInputFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
+	resumePos map[int32]importResumePos,
 	format roachpb.IOFileFormat,
 	fileFunc readFileFunc,
 	progressFn func(float32) error,
@@ -98,6 +104,10 @@ func readInputFiles(
 			return ctx.Err()
 		default:
 		}
+		resume := resumePos[dataFileIndex]
+		if resume.row == math.MaxUint64 {
+			continue
+		}
 		if err := func() error {
 			conf, err := storageccl.ExportStorageConfFromURI(dataFile)
 			if err != nil {
@@ -114,7 +124,7 @@ func readInputFiles(
 			}
 			defer raw.Close()
 
-			src := &fileReader{total: fileSizes[dataFileIndex], counter: byteCounter{r: raw}}
+			src := &fileReader{total: fileSizes[dataFileIndex], counter: byteCounter{r: raw}, resume: resume}
 			decompressed, err := decompressingReader(&src.counter, dataFile, format.Compression)
 			if err != nil {
 				return err
@@ -205,6 +215,9 @@ type fileReader struct {
 	io.Reader
 	total   int64
 	counter byteCounter
+	// resume is the position in the file up to which a previous attempt of the
+	// IMPORT ingested it. Readers skip the rows up to it.
+	resume importResumePos
 }
 
 func (f fileReader) ReadFraction() float32 {
@@ -242,7 +255,7 @@ type progressFn func(finished bool) error
 
 type inputConverter interface {
 	start(group ctxgroup.Group)
-	readFiles(ctx context.Context, dataFiles map[int32]string, format roachpb.IOFileFormat, progressFn func(float32) error, settings *cluster.Settings) error
+	readFiles(ctx context.Context, dataFiles map[int32]string, resumePos map[int32]importResumePos, format roachpb.IOFileFormat, progressFn func(float32) error, settings *cluster.Settings) error
 	inputFinished(ctx context.Context)
 }
 
@@ -287,6 +300,11 @@ func (cp *readImportDataProcessor) doRun(ctx context.Context) error {
 	group := ctxgroup.WithContext(ctx)
 	kvCh := make(chan row.KVBatch, 10)
 	evalCtx := cp.flowCtx.NewEvalCtx()
+	// Evaluate now() and its kin in DEFAULT and computed column expressions as
+	// of the import's timestamp, so that every row imported agrees on it.
+	importTime := timeutil.Unix(0, cp.spec.WalltimeNanos)
+	evalCtx.SetTxnTimestamp(importTime)
+	evalCtx.SetStmtTimestamp(importTime)
 
 	var singleTable *sqlbase.TableDescriptor
 	var singleTableTargetCols tree.NameList
@@ -328,6 +346,10 @@ func (cp *readImportDataProcessor) doRun(ctx context.Context) error {
 		conv, err = newPgCopyReader(kvCh, cp.spec.Format.PgCopy, singleTable, evalCtx)
 	case roachpb.IOFileFormat_PgDump:
 		conv, err = newPgDumpReader(kvCh, cp.spec.Format.PgDump, cp.spec.Tables, evalCtx)
+	case roachpb.IOFileFormat_Avro:
+		conv, err = newAvroInputReader(kvCh, cp.spec.Format.Avro, singleTable, singleTableTargetCols, evalCtx)
+	case roachpb.IOFileFormat_JSONL:
+		conv, err = newJSONLInputReader(kvCh, cp.spec.Format.JSONL, singleTable, singleTableTargetCols, evalCtx)
 	default:
 		err = errors.Errorf("Requested IMPORT format (%d) not supported by this node", cp.spec.Format.Format)
 	}
@@ -363,7 +385,7 @@ func (cp *readImportDataProcessor) doRun(ctx context.Context) error {
 			})
 		}
 
-		return conv.readFiles(ctx, cp.spec.Uri, cp.spec.Format, progFn, cp.flowCtx.Cfg.Settings)
+		return conv.readFiles(ctx, cp.spec.Uri, cp.resumePos(), cp.spec.Format, progFn, cp.flowCtx.Cfg.Settings)
 	})
 	if cp.spec.IngestDirectly {
 		// IngestDirectly means this reader will just ingest the KVs that the
@@ -382,6 +404,16 @@ func (cp *readImportDataProcessor) doRun(ctx context.Context) error {
 	return group.Wait()
 }
 
+// resumePos returns the positions up to which a previous attempt of the IMPORT
+// ingested each of the input files.
+func (cp *readImportDataProcessor) resumePos() map[int32]importResumePos {
+	pos := make(map[int32]importResumePos, len(cp.spec.ResumeRow))
+	for i, row := range cp.spec.ResumeRow {
+		pos[i] = importResumePos{row: row, offset: cp.spec.ResumeOffset[i]}
+	}
+	return pos
+}
+
 type sampleFunc func(roachpb.KeyValue) bool
 
 // sampleRate is a sampleFunc that samples a row with a probability of the
@@ -507,6 +539,50 @@ func (cp *readImportDataProcessor) presplitTableBoundaries(ctx context.Context)
 	return nil
 }
 
+// importResumePos is a position in an input file up to which it has been
+// ingested: row is the number of the last ingested row, numbered from 1, and
+// offset, if non-zero, is the byte offset of the decompressed input just past
+// that row.
+type importResumePos struct {
+	row    uint64
+	offset int64
+}
+
+// rowRangeTracker tracks the rows of an input file whose KVs have been added,
+// as the ranges of rows that KVBatches cover. Batches converted in parallel may
+// arrive out of order, so ranges beyond the completed prefix of the file are
+// held until the rows before them are added too.
+type rowRangeTracker struct {
+	completed importResumePos
+	// pending holds the ranges after completed, sorted by their first row.
+	pending []pendingRowRange
+}
+
+type pendingRowRange struct {
+	first uint64
+	last  importResumePos
+}
+
+// add records that the rows from first to last.row have been added.
+func (t *rowRangeTracker) add(first uint64, last importResumePos) {
+	if first > t.completed.row+1 {
+		i := sort.Search(len(t.pending), func(i int) bool { return t.pending[i].first > first })
+		t.pending = append(t.pending, pendingRowRange{})
+		copy(t.pending[i+1:], t.pending[i:])
+		t.pending[i] = pendingRowRange{first: first, last: last}
+		return
+	}
+	if last.row > t.completed.row {
+		t.completed = last
+	}
+	for len(t.pending) > 0 && t.pending[0].first <= t.completed.row+1 {
+		if t.pending[0].last.row > t.completed.row {
+			t.completed = t.pending[0].last
+		}
+		t.pending = t.pending[1:]
+	}
+}
+
 // ingestKvs drains kvs from the channel until it closes, ingesting them using
 // the BulkAdder. It handles the required buffering/sorting/etc.
 func (cp *readImportDataProcessor) ingestKvs(ctx context.Context, kvCh <-chan row.KVBatch) error {
@@ -562,45 +638,59 @@ func (cp *readImportDataProcessor) ingestKvs(ctx context.Context, kvCh <-chan ro
 
 	// Setup progress tracking:
 	//  - offsets maps source file IDs to offsets in the slices below.
-	//  - writtenRow contains LastRow of batch most recently added to the buffer.
+	//  - written tracks the rows of the batches added to the buffers.
 	//  - writtenFraction contains % of the input finished as of last batch.
-	//  - pkFlushedRow contains `writtenRow` as of the last pk adder flush.
-	//  - idxFlushedRow contains `writtenRow` as of the last index adder flush.
-	// In pkFlushedRow, idxFlushedRow and writtenFaction values are written via
-	// `atomic` so the progress reporting go goroutine can read them.
-	writtenRow := make([]uint64, len(cp.spec.Uri))
+	//  - pkFlushed contains the completed position of `written` as of the last pk
+	//    adder flush.
+	//  - idxFlushed contains the completed position of `written` as of the last
+	//    index adder flush.
+	// writtenFaction values are written via `atomic` and the flushed positions
+	// under flushedMu so the progress reporting go goroutine can read them.
+	written := make([]rowRangeTracker, len(cp.spec.Uri))
 	writtenFraction := make([]uint32, len(cp.spec.Uri))
 
-	pkFlushedRow := make([]uint64, len(cp.spec.Uri))
-	idxFlushedRow := make([]uint64, len(cp.spec.Uri))
+	var flushedMu syncutil.Mutex
+	pkFlushed := make([]importResumePos, len(cp.spec.Uri))
+	idxFlushed := make([]importResumePos, len(cp.spec.Uri))
+
+	// offsets maps input file ID to a slot in our progress tracking slices.
+	offsets := make(map[int32]int, len(cp.spec.Uri))
+	var offset int
+	for i := range cp.spec.Uri {
+		offsets[i] = offset
+		offset++
+	}
+	for i, pos := range cp.resumePos() {
+		if offset, ok := offsets[i]; ok {
+			written[offset].completed = pos
+			pkFlushed[offset] = pos
+			idxFlushed[offset] = pos
+		}
+	}
 
 	// When the PK adder flushes, everything written has been flushed, so we set
-	// pkFlushedRow to writtenRow. Additionally if the indexAdder is empty then we
-	// can treat it as flushed as well (in case we're not adding anything to it).
+	// pkFlushed to written. Additionally if the indexAdder is empty then we can
+	// treat it as flushed as well (in case we're not adding anything to it).
 	pkIndexAdder.SetOnFlush(func() {
-		for _, i := range writtenRow {
-			atomic.StoreUint64(&pkFlushedRow[i], writtenRow[i])
+		flushedMu.Lock()
+		defer flushedMu.Unlock()
+		for i := range written {
+			pkFlushed[i] = written[i].completed
 		}
 		if indexAdder.IsEmpty() {
-			for _, i := range writtenRow {
-				atomic.StoreUint64(&idxFlushedRow[i], writtenRow[i])
+			for i := range written {
+				idxFlushed[i] = written[i].completed
 			}
 		}
 	})
 	indexAdder.SetOnFlush(func() {
-		for _, i := range writtenRow {
-			atomic.StoreUint64(&idxFlushedRow[i], writtenRow[i])
+		flushedMu.Lock()
+		defer flushedMu.Unlock()
+		for i := range written {
+			idxFlushed[i] = written[i].completed
 		}
 	})
 
-	// offsets maps input file ID to a slot in our progress tracking slices.
-	offsets := make(map[int32]int, len(cp.spec.Uri))
-	var offset int
-	for i := range cp.spec.Uri {
-		offsets[i] = offset
-		offset++
-	}
-
 	// stopProgress will be closed when there is no more progress to report.
 	stopProgress := make(chan struct{})
 	g := ctxgroup.WithContext(ctx)
@@ -617,19 +707,21 @@ func (cp *readImportDataProcessor) ingestKvs(ctx context.Context, kvCh <-chan ro
 			case <-tick.C:
 				var prog distsqlpb.RemoteProducerMetadata_BulkProcessorProgress
 				prog.CompletedRow = make(map[int32]uint64)
+				prog.ResumeOffset = make(map[int32]int64)
 				prog.CompletedFraction = make(map[int32]float32)
+				flushedMu.Lock()
 				for file, offset := range offsets {
-					pk := atomic.LoadUint64(&pkFlushedRow[offset])
-					idx := atomic.LoadUint64(&idxFlushedRow[offset])
 					// On resume we'll be able to skip up the last row for which both the
 					// PK and index adders have flushed KVs.
-					if idx > pk {
-						prog.CompletedRow[file] = pk
-					} else {
-						prog.CompletedRow[file] = idx
+					pos := pkFlushed[offset]
+					if idx := idxFlushed[offset]; idx.row < pos.row {
+						pos = idx
 					}
+					prog.CompletedRow[file] = pos.row
+					prog.ResumeOffset[file] = pos.offset
 					prog.CompletedFraction[file] = math.Float32frombits(atomic.LoadUint32(&writtenFraction[offset]))
 				}
+				flushedMu.Unlock()
 				meta := &distsqlpb.ProducerMetadata{BulkProcessorProgress: &prog}
 				if !distsqlrun.EmitHelper(ctx, &cp.out, nil /* row */, meta, func(_ context.Context) {}) {
 					return errors.New("consumer closed")
@@ -690,7 +782,11 @@ func (cp *readImportDataProcessor) ingestKvs(ctx context.Context, kvCh <-chan ro
 				}
 			}
 			offset := offsets[kvBatch.Source]
-			writtenRow[offset] = kvBatch.LastRow
+			if kvBatch.LastRow != 0 {
+				flushedMu.Lock()
+				written[offset].add(kvBatch.FirstRow, importResumePos{row: kvBatch.LastRow, offset: kvBatch.EndOffset})
+				flushedMu.Unlock()
+			}
 			atomic.StoreUint32(&writtenFraction[offset], math.Float32bits(kvBatch.Progress))
 		}
 		return nil
@@ -0,0 +1,138 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRowRangeTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	pos := func(row uint64) importResumePos {
+		return importResumePos{row: row, offset: int64(row) * 10}
+	}
+
+	var tr rowRangeTracker
+	tr.completed = pos(2)
+
+	// Ranges that do not follow the completed prefix are held.
+	tr.add(11, pos(15))
+	tr.add(6, pos(8))
+	if expected := pos(2); tr.completed != expected {
+		t.Fatalf("expected %v, got %v", expected, tr.completed)
+	}
+	if len(tr.pending) != 2 || tr.pending[0].first != 6 || tr.pending[1].first != 11 {
+		t.Fatalf("unexpected pending ranges %v", tr.pending)
+	}
+
+	// Filling the gap before a held range completes it too.
+	tr.add(3, pos(5))
+	if expected := pos(8); tr.completed != expected {
+		t.Fatalf("expected %v, got %v", expected, tr.completed)
+	}
+	tr.add(9, pos(10))
+	if expected := pos(15); tr.completed != expected {
+		t.Fatalf("expected %v, got %v", expected, tr.completed)
+	}
+	if len(tr.pending) != 0 {
+		t.Fatalf("unexpected pending ranges %v", tr.pending)
+	}
+
+	// Ranges at or before the completed prefix do not move it back.
+	tr.add(1, pos(4))
+	if expected := pos(15); tr.completed != expected {
+		t.Fatalf("expected %v, got %v", expected, tr.completed)
+	}
+}
+
+func TestCSVReaderResume(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numRows = 20
+	var data strings.Builder
+	var offsets []int64
+	for i := 1; i <= numRows; i++ {
+		fmt.Fprintf(&data, "%d,\"row\n%d\"\n", i, i)
+		offsets = append(offsets, int64(data.Len()))
+	}
+	table := descForTable(t, `CREATE TABLE t (a INT, b STRING)`, 10, 20, NoFKs)
+
+	// read converts the CSV data, resuming from the passed position, and returns
+	// the KVs and the position up to which the batches covered the input.
+	read := func(resume importResumePos) (map[string]roachpb.Value, importResumePos) {
+		t.Helper()
+		ctx := context.Background()
+		kvCh := make(chan row.KVBatch, 10)
+		conv := newCSVInputReader(
+			kvCh, roachpb.CSVOptions{}, testEvalCtx.StmtTimestamp.UnixNano(), table, nil, testEvalCtx,
+		)
+		conv.batchSize = 3
+		group := ctxgroup.WithContext(ctx)
+		conv.start(group)
+		group.GoCtx(func(ctx context.Context) error {
+			defer conv.inputFinished(ctx)
+			src := &fileReader{counter: byteCounter{r: strings.NewReader(data.String())}, resume: resume}
+			src.Reader = &src.counter
+			return conv.readFile(ctx, src, 0, "t.csv", func(bool) error { return nil })
+		})
+
+		kvs := make(map[string]roachpb.Value)
+		tr := rowRangeTracker{completed: resume}
+		for batch := range kvCh {
+			for _, kv := range batch.KVs {
+				kvs[string(kv.Key)] = kv.Value
+			}
+			tr.add(batch.FirstRow, importResumePos{row: batch.LastRow, offset: batch.EndOffset})
+		}
+		if err := group.Wait(); err != nil {
+			t.Fatal(err)
+		}
+		return kvs, tr.completed
+	}
+
+	all, end := read(importResumePos{})
+	if len(all) != numRows {
+		t.Fatalf("expected %d KVs, got %d", numRows, len(all))
+	}
+	if expected := (importResumePos{row: numRows, offset: offsets[numRows-1]}); end != expected {
+		t.Fatalf("expected to end at %v, got %v", expected, end)
+	}
+
+	for _, resume := range []importResumePos{
+		{row: 7, offset: offsets[6]},
+		{row: 7},
+	} {
+		t.Run(fmt.Sprintf("%d/%d", resume.row, resume.offset), func(t *testing.T) {
+			kvs, end := read(resume)
+			if expected := numRows - int(resume.row); len(kvs) != expected {
+				t.Fatalf("expected %d KVs, got %d", expected, len(kvs))
+			}
+			// The KVs of the remaining rows, including their row IDs, are the same
+			// as the ones produced when reading the whole input.
+			for k, v := range kvs {
+				if expected, ok := all[k]; !ok || !reflect.DeepEqual(expected, v) {
+					t.Fatalf("unexpected KV %s: %v", roachpb.Key(k), v)
+				}
+			}
+			if expected := (importResumePos{row: numRows, offset: offsets[numRows-1]}); end != expected {
+				t.Fatalf("expected to end at %v, got %v", expected, end)
+			}
+		})
+	}
+}
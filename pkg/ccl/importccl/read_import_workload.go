@@ -104,6 +104,7 @@ func makeDatumFromColOffset(
 func (w *workloadReader) readFiles(
 	ctx context.Context,
 	dataFiles map[int32]string,
+	_ map[int32]importResumePos,
 	_ roachpb.IOFileFormat,
 	_ func(float32) error,
 	_ *cluster.Settings,
@@ -0,0 +1,119 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Encrypted files are laid out as a fixed preamble -- a magic string and a
+// version byte -- followed by the nonce and then the AES-GCM sealed contents.
+// GCM authenticates the contents, so a file that was modified or that is
+// opened with the wrong key fails to decrypt rather than producing garbage.
+const (
+	encryptionPreamble = "encrypt"
+	encryptionVersion  = 1
+
+	// EncryptionKeySize is the size, in bytes, of the keys used to encrypt
+	// files. It selects AES-256.
+	EncryptionKeySize = 32
+	// EncryptionSaltSize is the size, in bytes, of the salts generated to
+	// derive keys from passphrases.
+	EncryptionSaltSize = 16
+
+	encryptionNonceSize = 12
+	// encryptionKDFIterations is the number of PBKDF2 rounds used to derive a
+	// key from a passphrase. Keys are only derived once per operation, so
+	// this can afford to be expensive.
+	encryptionKDFIterations = 64000
+)
+
+var encryptionHeader = append([]byte(encryptionPreamble), encryptionVersion)
+
+// GenerateSalt returns a random salt for use with GenerateKey.
+func GenerateSalt() ([]byte, error) {
+	salt := make([]byte, EncryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// GenerateKey derives an encryption key from a passphrase and salt.
+func GenerateKey(passphrase, salt []byte) []byte {
+	return pbkdf2.Key(passphrase, salt, encryptionKDFIterations, EncryptionKeySize, sha256.New)
+}
+
+// AppearsEncrypted returns true if the file looks like it was written by
+// EncryptFile.
+func AppearsEncrypted(contents []byte) bool {
+	return bytes.HasPrefix(contents, []byte(encryptionPreamble))
+}
+
+// EncryptFile encrypts and authenticates plaintext with the given key.
+func EncryptFile(plaintext, key []byte) ([]byte, error) {
+	gcm, err := aesgcm(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, encryptionNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext := make([]byte, 0, len(encryptionHeader)+len(nonce)+len(plaintext)+gcm.Overhead())
+	ciphertext = append(ciphertext, encryptionHeader...)
+	ciphertext = append(ciphertext, nonce...)
+	return gcm.Seal(ciphertext, nonce, plaintext, nil), nil
+}
+
+// DecryptFile decrypts a file written by EncryptFile, returning an error if
+// the key is not the one it was encrypted with or the file was modified.
+func DecryptFile(ciphertext, key []byte) ([]byte, error) {
+	if !AppearsEncrypted(ciphertext) {
+		return nil, errors.New("file does not appear to be encrypted")
+	}
+	ciphertext = ciphertext[len(encryptionPreamble):]
+	if len(ciphertext) < 1+encryptionNonceSize {
+		return nil, errors.New("invalid encrypted file: too short")
+	}
+	if version := ciphertext[0]; version != encryptionVersion {
+		return nil, errors.Errorf("unexpected encryption version %d", version)
+	}
+	nonce := ciphertext[1 : 1+encryptionNonceSize]
+	ciphertext = ciphertext[1+encryptionNonceSize:]
+
+	gcm, err := aesgcm(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(ciphertext[:0:0], nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt -- is the key correct?")
+	}
+	return plaintext, nil
+}
+
+func aesgcm(key []byte) (cipher.AEAD, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, errors.Errorf("encryption key must be %d bytes, got %d", EncryptionKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithNonceSize(block, encryptionNonceSize)
+}
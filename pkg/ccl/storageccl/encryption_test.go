@@ -0,0 +1,70 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	salt, err := GenerateSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := GenerateKey([]byte("passphrase"), salt)
+	if !bytes.Equal(key, GenerateKey([]byte("passphrase"), salt)) {
+		t.Fatal("expected key derivation to be deterministic")
+	}
+	wrongKey := GenerateKey([]byte("wrong passphrase"), salt)
+
+	for _, plaintext := range [][]byte{nil, []byte("hello world")} {
+		ciphertext, err := EncryptFile(plaintext, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !AppearsEncrypted(ciphertext) {
+			t.Fatal("expected ciphertext to appear encrypted")
+		}
+		if len(plaintext) > 0 && bytes.Contains(ciphertext, plaintext) {
+			t.Fatal("expected ciphertext to not contain the plaintext")
+		}
+
+		decrypted, err := DecryptFile(ciphertext, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(plaintext, decrypted) {
+			t.Fatalf("expected %q, got %q", plaintext, decrypted)
+		}
+
+		if _, err := DecryptFile(ciphertext, wrongKey); !testutils.IsError(err, "is the key correct") {
+			t.Fatalf("expected authentication error, got %v", err)
+		}
+		ciphertext[len(ciphertext)-1] ^= 1
+		if _, err := DecryptFile(ciphertext, key); !testutils.IsError(err, "is the key correct") {
+			t.Fatalf("expected authentication error, got %v", err)
+		}
+	}
+
+	if AppearsEncrypted([]byte("hello world")) {
+		t.Fatal("expected plaintext to not appear encrypted")
+	}
+	if _, err := DecryptFile([]byte("hello world"), key); !testutils.IsError(err, "does not appear to be encrypted") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := EncryptFile(nil, key[:16]); !testutils.IsError(err, "must be 32 bytes") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
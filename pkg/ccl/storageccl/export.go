@@ -144,10 +144,20 @@ func evalExport(
 		return result.Result{}, nil
 	}
 
+	// The stored file, and so its checksum, is the encrypted version of the
+	// data if encryption was requested.
+	payload := data
+	if args.Encryption != nil {
+		payload, err = EncryptFile(data, args.Encryption.Key)
+		if err != nil {
+			return result.Result{}, err
+		}
+	}
+
 	var checksum []byte
 	if !args.OmitChecksum {
 		// Compute the checksum before we upload and remove the local file.
-		checksum, err = SHA512ChecksumData(data)
+		checksum, err = SHA512ChecksumData(payload)
 		if err != nil {
 			return result.Result{}, err
 		}
@@ -162,7 +172,7 @@ func evalExport(
 
 	if exportStore != nil {
 		exported.Path = fmt.Sprintf("%d.sst", builtins.GenerateUniqueInt(cArgs.EvalCtx.NodeID()))
-		if err := exportStore.WriteFile(ctx, exported.Path, bytes.NewReader(data)); err != nil {
+		if err := exportStore.WriteFile(ctx, exported.Path, bytes.NewReader(payload)); err != nil {
 			return result.Result{}, err
 		}
 	}
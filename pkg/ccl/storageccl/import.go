@@ -183,6 +183,13 @@ func evalImport(ctx context.Context, cArgs batcheval.CommandArgs) (*roachpb.Impo
 			}
 		}
 
+		if args.Encryption != nil {
+			fileContents, err = DecryptFile(fileContents, args.Encryption.Key)
+			if err != nil {
+				return nil, errors.Wrapf(err, "decrypting %q", file.Path)
+			}
+		}
+
 		iter, err := engine.NewMemSSTIterator(fileContents, false)
 		if err != nil {
 			return nil, err
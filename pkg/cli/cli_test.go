@@ -2170,6 +2170,8 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/18.json
   debug/nodes/1/ranges/19.json
   debug/nodes/1/ranges/20.json
+  debug/nodes/1/ranges/21.json
+  debug/nodes/1/ranges/22.json
   debug/schema/defaultdb@details.json
   debug/schema/postgres@details.json
   debug/schema/system@details.json
@@ -2180,8 +2182,10 @@ writing ` + os.DevNull + `
   debug/schema/system/lease.json
   debug/schema/system/locations.json
   debug/schema/system/namespace.json
+  debug/schema/system/protected_ts_records.json
   debug/schema/system/rangelog.json
   debug/schema/system/role_members.json
+  debug/schema/system/scheduled_jobs.json
   debug/schema/system/settings.json
   debug/schema/system/table_statistics.json
   debug/schema/system/ui.json
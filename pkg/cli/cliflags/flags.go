@@ -679,6 +679,14 @@ memory that the store may consume, for example:
   --store=type=mem,size=20GiB
   --store=type=mem,size=90%
 
+</PRE>
+The "engine" field selects the storage engine of the store. It defaults to
+"rocksdb"; "experimental-pebble" uses Pebble, a pure-Go storage engine, which
+does not support encryption at rest or RocksDB options, for example:
+<PRE>
+
+  --store=path=/mnt/ssd01,engine=experimental-pebble
+
 </PRE>
 Commas are forbidden in all values, since they are used to separate fields.
 Also, if you use equal signs in the file path to a store, you must use the
@@ -12,9 +12,11 @@ package coldata
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 )
 
 // column is an interface that represents a raw array of a Go native type.
@@ -98,6 +100,10 @@ type Vec interface {
 	// TODO(jordan): should this be [][]byte?
 	// Decimal returns an apd.Decimal slice.
 	Decimal() []apd.Decimal
+	// Timestamp returns a time.Time slice.
+	Timestamp() []time.Time
+	// Interval returns a duration.Duration slice.
+	Interval() []duration.Duration
 
 	// Col returns the raw, typeless backing storage for this Vec.
 	Col() interface{}
@@ -177,6 +183,10 @@ func NewMemColumn(t coltypes.T, n int) Vec {
 		return &memColumn{t: t, col: make([]float64, n), nulls: nulls}
 	case coltypes.Decimal:
 		return &memColumn{t: t, col: make([]apd.Decimal, n), nulls: nulls}
+	case coltypes.Timestamp:
+		return &memColumn{t: t, col: make([]time.Time, n), nulls: nulls}
+	case coltypes.Interval:
+		return &memColumn{t: t, col: make([]duration.Duration, n), nulls: nulls}
 	default:
 		panic(fmt.Sprintf("unhandled type %s", t))
 	}
@@ -226,6 +236,14 @@ func (m *memColumn) Decimal() []apd.Decimal {
 	return m.col.([]apd.Decimal)
 }
 
+func (m *memColumn) Timestamp() []time.Time {
+	return m.col.([]time.Time)
+}
+
+func (m *memColumn) Interval() []duration.Duration {
+	return m.col.([]duration.Duration)
+}
+
 func (m *memColumn) Col() interface{} {
 	return m.col
 }
@@ -21,6 +21,7 @@ package coldata
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
@@ -31,6 +32,7 @@ import (
 	// block. This was picked because it sorts after "pkg/sql/exec/execgen" and
 	// has no deps.
 	_ "github.com/cockroachdb/cockroach/pkg/util/bufalloc"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 )
 
 // {{/*
@@ -42,6 +44,12 @@ const _TYPES_T = coltypes.Unhandled
 // Dummy import to pull in "apd" package.
 var _ apd.Decimal
 
+// Dummy import to pull in "time" package.
+var _ time.Time
+
+// Dummy import to pull in "duration" package.
+var _ duration.Duration
+
 // */}}
 
 func (m *memColumn) Append(args AppendArgs) {
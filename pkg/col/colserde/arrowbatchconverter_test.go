@@ -30,8 +30,9 @@ func randomBatch() ([]coltypes.T, coldata.Batch) {
 
 	availableTyps := make([]coltypes.T, 0, len(coltypes.AllTypes))
 	for _, typ := range coltypes.AllTypes {
-		// TODO(asubiotto): We do not support decimal conversion yet.
-		if typ == coltypes.Decimal {
+		// TODO(asubiotto): We do not support decimal, timestamp and interval
+		// conversion yet.
+		if typ == coltypes.Decimal || typ == coltypes.Timestamp || typ == coltypes.Interval {
 			continue
 		}
 		availableTyps = append(availableTyps, typ)
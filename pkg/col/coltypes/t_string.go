@@ -17,12 +17,14 @@ func _() {
 	_ = x[Int64-6]
 	_ = x[Float32-7]
 	_ = x[Float64-8]
-	_ = x[Unhandled-9]
+	_ = x[Timestamp-9]
+	_ = x[Interval-10]
+	_ = x[Unhandled-11]
 }
 
-const _T_name = "BoolBytesDecimalInt8Int16Int32Int64Float32Float64Unhandled"
+const _T_name = "BoolBytesDecimalInt8Int16Int32Int64Float32Float64TimestampIntervalUnhandled"
 
-var _T_index = [...]uint8{0, 4, 9, 16, 20, 25, 30, 35, 42, 49, 58}
+var _T_index = [...]uint8{0, 4, 9, 16, 20, 25, 30, 35, 42, 49, 58, 66, 75}
 
 func (i T) String() string {
 	if i < 0 || i >= T(len(_T_index)-1) {
@@ -12,8 +12,10 @@ package coltypes
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 )
 
 // T represents an exec physical type - a bytes representation of a particular
@@ -40,6 +42,10 @@ const (
 	Float32
 	// Float64 is a column of type float64
 	Float64
+	// Timestamp is a column of type time.Time
+	Timestamp
+	// Interval is a column of type duration.Duration
+	Interval
 
 	// Unhandled is a temporary value that represents an unhandled type.
 	// TODO(jordan): this should be replaced by a panic once all types are
@@ -72,6 +78,8 @@ func init() {
 	CompatibleTypes[Int64] = append(CompatibleTypes[Int64], intTypes...)
 	CompatibleTypes[Float32] = append(CompatibleTypes[Float32], floatTypes...)
 	CompatibleTypes[Float64] = append(CompatibleTypes[Float64], floatTypes...)
+	CompatibleTypes[Timestamp] = append(CompatibleTypes[Timestamp], Timestamp)
+	CompatibleTypes[Interval] = append(CompatibleTypes[Interval], Interval)
 }
 
 // FromGoType returns the type for a Go value, if applicable. Shouldn't be used at
@@ -98,6 +106,10 @@ func FromGoType(v interface{}) T {
 		return Bytes
 	case apd.Decimal:
 		return Decimal
+	case time.Time:
+		return Timestamp
+	case duration.Duration:
+		return Interval
 	default:
 		panic(fmt.Sprintf("type %T not supported yet", t))
 	}
@@ -124,6 +136,10 @@ func (t T) GoTypeName() string {
 		return "float32"
 	case Float64:
 		return "float64"
+	case Timestamp:
+		return "time.Time"
+	case Interval:
+		return "duration.Duration"
 	default:
 		panic(fmt.Sprintf("unhandled type %d", t))
 	}
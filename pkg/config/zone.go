@@ -258,6 +258,9 @@ func (z *ZoneConfig) ValidateTandemFields() error {
 	if numConstrainedRepls > 0 && z.NumReplicas == nil {
 		return fmt.Errorf("when per-replica constraints are set, num_replicas must be set as well")
 	}
+	if len(z.VoterConstraints) > 0 && z.NumVoters == nil {
+		return fmt.Errorf("when voter_constraints are set, num_voters must be set as well")
+	}
 	if (z.RangeMinBytes != nil || z.RangeMaxBytes != nil) &&
 		(z.RangeMinBytes == nil || z.RangeMaxBytes == nil) {
 		return fmt.Errorf("range_min_bytes and range_max_bytes must be set together")
@@ -293,6 +296,18 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	if z.NumVoters != nil {
+		switch {
+		case *z.NumVoters <= 0:
+			return fmt.Errorf("at least one voting replica is required")
+		case *z.NumVoters == 2:
+			return fmt.Errorf("at least 3 voting replicas are required for multi-replica configurations")
+		case z.NumReplicas != nil && *z.NumVoters > *z.NumReplicas:
+			return fmt.Errorf("num_voters (%d) cannot be greater than num_replicas (%d)",
+				*z.NumVoters, *z.NumReplicas)
+		}
+	}
+
 	if z.RangeMaxBytes != nil && *z.RangeMaxBytes < base.MinRangeMaxBytes {
 		return fmt.Errorf("RangeMaxBytes %d less than minimum allowed %d",
 			*z.RangeMaxBytes, base.MinRangeMaxBytes)
@@ -347,6 +362,22 @@ func (z *ZoneConfig) Validate() error {
 		}
 	}
 
+	var numConstrainedVoters int64
+	for _, constraints := range z.VoterConstraints {
+		for _, constraint := range constraints.Constraints {
+			if constraint.Type == Constraint_DEPRECATED_POSITIVE {
+				return fmt.Errorf("voter constraints must either be required (prefixed with a '+') or " +
+					"prohibited (prefixed with a '-')")
+			}
+		}
+		numConstrainedVoters += int64(constraints.NumReplicas)
+	}
+	if z.NumVoters != nil && numConstrainedVoters > int64(*z.NumVoters) {
+		return fmt.Errorf("the number of replicas specified in voter constraints (%d) cannot be "+
+			"greater than the number of voting replicas configured for the zone (%d)",
+			numConstrainedVoters, *z.NumVoters)
+	}
+
 	for _, leasePref := range z.LeasePreferences {
 		if len(leasePref.Constraints) == 0 {
 			return fmt.Errorf("every lease preference must include at least one constraint")
@@ -391,6 +422,14 @@ func (z *ZoneConfig) InheritFromParent(parent *ZoneConfig) {
 			z.InheritedConstraints = false
 		}
 	}
+	// The voter constraints only make sense along with num_voters, so they're
+	// inherited together.
+	if z.NumVoters == nil {
+		if parent.NumVoters != nil {
+			z.NumVoters = proto.Int32(*parent.NumVoters)
+			z.VoterConstraints = parent.VoterConstraints
+		}
+	}
 	if z.InheritedLeasePreferences {
 		if !parent.InheritedLeasePreferences {
 			z.LeasePreferences = parent.LeasePreferences
@@ -408,6 +447,15 @@ func (z *ZoneConfig) CopyFromZone(other ZoneConfig, fieldList []tree.Name) {
 				z.NumReplicas = proto.Int32(*other.NumReplicas)
 			}
 		}
+		if fieldName == "num_voters" {
+			z.NumVoters = nil
+			if other.NumVoters != nil {
+				z.NumVoters = proto.Int32(*other.NumVoters)
+			}
+		}
+		if fieldName == "voter_constraints" {
+			z.VoterConstraints = other.VoterConstraints
+		}
 		if fieldName == "range_min_bytes" {
 			z.RangeMinBytes = nil
 			if other.RangeMinBytes != nil {
@@ -438,6 +486,53 @@ func (z *ZoneConfig) CopyFromZone(other ZoneConfig, fieldList []tree.Name) {
 	}
 }
 
+// ConfiguresNonVoters returns whether the zone config or any of its subzones
+// sets num_voters or voter_constraints, which can only be used once all nodes
+// support non-voting replicas.
+func (z *ZoneConfig) ConfiguresNonVoters() bool {
+	if z.NumVoters != nil || len(z.VoterConstraints) > 0 {
+		return true
+	}
+	for i := range z.Subzones {
+		if z.Subzones[i].Config.ConfiguresNonVoters() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetNumVoters returns the desired number of voting replicas. NumReplicas must
+// be set.
+func (z *ZoneConfig) GetNumVoters() int32 {
+	if z.NumVoters != nil && *z.NumVoters < *z.NumReplicas {
+		return *z.NumVoters
+	}
+	return *z.NumReplicas
+}
+
+// GetNumNonVoters returns the desired number of non-voting replicas.
+// NumReplicas must be set.
+func (z *ZoneConfig) GetNumNonVoters() int32 {
+	return *z.NumReplicas - z.GetNumVoters()
+}
+
+// VoterConfig returns the zone config which governs the placement of the
+// voting replicas: its NumReplicas is the number of voters and its
+// Constraints are the VoterConstraints, if any. If the zone config doesn't
+// configure non-voting replicas, it is returned unchanged.
+func (z *ZoneConfig) VoterConfig() *ZoneConfig {
+	if z.NumVoters == nil && len(z.VoterConstraints) == 0 {
+		return z
+	}
+	voterZone := *z
+	voterZone.NumReplicas = proto.Int32(z.GetNumVoters())
+	if len(z.VoterConstraints) > 0 {
+		voterZone.Constraints = z.VoterConstraints
+		voterZone.InheritedConstraints = false
+	}
+	return &voterZone
+}
+
 // StoreMatchesConstraint returns whether a store matches the given constraint.
 func StoreMatchesConstraint(store roachpb.StoreDescriptor, constraint Constraint) bool {
 	hasConstraint := storeHasConstraint(store, constraint)
@@ -232,6 +232,69 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"",
 		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				NumVoters:   proto.Int32(0),
+			},
+			"at least one voting replica is required",
+		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(5),
+				NumVoters:   proto.Int32(2),
+			},
+			"at least 3 voting replicas are required for multi-replica configurations",
+		},
+		{
+			ZoneConfig{
+				NumReplicas: proto.Int32(3),
+				NumVoters:   proto.Int32(5),
+			},
+			"num_voters \\(5\\) cannot be greater than num_replicas \\(3\\)",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(5),
+				NumVoters:     proto.Int32(3),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+				VoterConstraints: []Constraints{
+					{Constraints: []Constraint{{Value: "a", Type: Constraint_DEPRECATED_POSITIVE}}},
+				},
+			},
+			"voter constraints must either be required .+ or prohibited .+",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(5),
+				NumVoters:     proto.Int32(3),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+				VoterConstraints: []Constraints{
+					{
+						Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}},
+						NumReplicas: 4,
+					},
+				},
+			},
+			"the number of replicas specified in voter constraints .+ cannot be greater than",
+		},
+		{
+			ZoneConfig{
+				NumReplicas:   proto.Int32(5),
+				NumVoters:     proto.Int32(3),
+				RangeMaxBytes: DefaultZoneConfig().RangeMaxBytes,
+				GC:            &GCPolicy{TTLSeconds: 1},
+				VoterConstraints: []Constraints{
+					{
+						Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}},
+						NumReplicas: 3,
+					},
+				},
+			},
+			"",
+		},
 	}
 
 	for i, c := range testCases {
@@ -272,6 +335,14 @@ func TestZoneConfigValidateTandemFields(t *testing.T) {
 			},
 			"when per-replica constraints are set, num_replicas must be set as well",
 		},
+		{
+			ZoneConfig{
+				VoterConstraints: []Constraints{
+					{Constraints: []Constraint{{Value: "a", Type: Constraint_REQUIRED}}},
+				},
+			},
+			"when voter_constraints are set, num_voters must be set as well",
+		},
 		{
 			ZoneConfig{
 				InheritedConstraints:      true,
@@ -294,6 +365,47 @@ func TestZoneConfigValidateTandemFields(t *testing.T) {
 	}
 }
 
+func TestZoneConfigVoterConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	constraints := []Constraints{
+		{Constraints: []Constraint{{Key: "region", Value: "a", Type: Constraint_REQUIRED}}},
+	}
+	voterConstraints := []Constraints{
+		{Constraints: []Constraint{{Key: "region", Value: "b", Type: Constraint_REQUIRED}}},
+	}
+
+	zone := ZoneConfig{NumReplicas: proto.Int32(3), Constraints: constraints}
+	if voterZone := zone.VoterConfig(); voterZone != &zone {
+		t.Errorf("expected the zone config to be returned unchanged, got %+v", voterZone)
+	}
+	if n := zone.GetNumNonVoters(); n != 0 {
+		t.Errorf("expected no non-voters, got %d", n)
+	}
+
+	zone.NumReplicas = proto.Int32(5)
+	zone.NumVoters = proto.Int32(3)
+	if n := zone.GetNumNonVoters(); n != 2 {
+		t.Errorf("expected 2 non-voters, got %d", n)
+	}
+	voterZone := zone.VoterConfig()
+	if *voterZone.NumReplicas != 3 {
+		t.Errorf("expected 3 voters, got %d", *voterZone.NumReplicas)
+	}
+	if !reflect.DeepEqual(voterZone.Constraints, constraints) {
+		t.Errorf("expected the voters to inherit the constraints, got %+v", voterZone.Constraints)
+	}
+
+	zone.VoterConstraints = voterConstraints
+	voterZone = zone.VoterConfig()
+	if !reflect.DeepEqual(voterZone.Constraints, voterConstraints) {
+		t.Errorf("expected the voter constraints, got %+v", voterZone.Constraints)
+	}
+	if *zone.NumReplicas != 5 || !reflect.DeepEqual(zone.Constraints, constraints) {
+		t.Errorf("expected the original zone config to be unmodified, got %+v", zone)
+	}
+}
+
 func TestZoneConfigSubzones(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -0,0 +1,173 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CronExpr is a parsed cron expression, which determines the times at which a
+// schedule runs. It uses the standard five fields -- minute, hour, day of
+// month, month and day of week -- each of which is either "*" or a
+// comma-separated list of values and ranges, optionally followed by a step,
+// e.g. "*/15", "1-5" or "0,30". As with cron, if both day fields are
+// restricted, a time matches if either of them does. The shorthands @hourly,
+// @daily, @weekly, @monthly and @yearly are also accepted. All times are
+// interpreted in UTC.
+type CronExpr struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record whether the day of month and day of week
+	// fields were unrestricted, which determines how they are combined.
+	domStar, dowStar bool
+}
+
+var cronShorthands = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [...]cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	// Both 0 and 7 mean Sunday.
+	{name: "day of week", min: 0, max: 7},
+}
+
+// ParseCronExpr parses a cron expression.
+func ParseCronExpr(expr string) (*CronExpr, error) {
+	s := strings.TrimSpace(expr)
+	if shorthand, ok := cronShorthands[strings.ToLower(s)]; ok {
+		s = shorthand
+	}
+	fields := strings.Fields(s)
+	if len(fields) != len(cronFields) {
+		return nil, errors.Errorf(
+			"invalid cron expression %q: expected %d fields, found %d", expr, len(cronFields), len(fields))
+	}
+	var bits [len(cronFields)]uint64
+	for i, f := range fields {
+		var err error
+		if bits[i], err = parseCronField(f, cronFields[i]); err != nil {
+			return nil, errors.Wrapf(err, "invalid cron expression %q", expr)
+		}
+	}
+	// Fold Sunday-as-7 into Sunday-as-0.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+	return &CronExpr{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField returns the set of values matched by one field of a cron
+// expression, as a bitmask.
+func parseCronField(s string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid step in %s field: %q", field.name, part)
+			}
+		}
+
+		lo, hi := field.min, field.max
+		if rng != "*" {
+			var err error
+			bounds := strings.SplitN(rng, "-", 2)
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, errors.Errorf("invalid value in %s field: %q", field.name, part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, errors.Errorf("invalid value in %s field: %q", field.name, part)
+				}
+			} else if step != 1 {
+				// As with cron, "n/step" means every step starting at n.
+				hi = field.max
+			}
+			if lo < field.min || hi > field.max || lo > hi {
+				return 0, errors.Errorf(
+					"%s field out of range [%d-%d]: %q", field.name, field.min, field.max, part)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// maxCronSearch bounds how far into the future Next looks for a matching time,
+// so that expressions that can never match (e.g. February 30th) terminate.
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+// Next returns the first time strictly after t matched by the expression,
+// truncated to the minute, or the zero time if there is no such time.
+func (e *CronExpr) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxCronSearch)
+	for t.Before(limit) {
+		if e.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !e.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if e.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if e.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (e *CronExpr) matchesDay(t time.Time) bool {
+	dom := e.dom&(1<<uint(t.Day())) != 0
+	dow := e.dow&(1<<uint(t.Weekday())) != 0
+	if e.domStar || e.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
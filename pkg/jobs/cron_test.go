@@ -0,0 +1,78 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestCronExpr(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// A Wednesday.
+	now := time.Date(2019, 7, 3, 10, 17, 42, 0, time.UTC)
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2019, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	for _, tc := range []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", at(7, 3, 10, 18)},
+		{"*/15 * * * *", at(7, 3, 10, 30)},
+		{"5/15 * * * *", at(7, 3, 10, 20)},
+		{"@hourly", at(7, 3, 11, 0)},
+		{"@daily", at(7, 4, 0, 0)},
+		{"@weekly", at(7, 7, 0, 0)},
+		{"@monthly", at(8, 1, 0, 0)},
+		{"@yearly", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", at(7, 4, 2, 30)},
+		{"0 0 * * 7", at(7, 7, 0, 0)},
+		{"0,45 10,12 * * *", at(7, 3, 10, 45)},
+		// Both day fields are restricted, so either matching suffices.
+		{"0 0 15 * 5", at(7, 5, 0, 0)},
+		{"0 0 31 * *", at(7, 31, 0, 0)},
+		// September never has 31 days.
+		{"0 0 31 9 *", time.Time{}},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := ParseCronExpr(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next := e.Next(now); !next.Equal(tc.expected) {
+				t.Fatalf("expected %s, got %s", tc.expected, next)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		expr string
+		err  string
+	}{
+		{"", "expected 5 fields"},
+		{"* * * *", "expected 5 fields"},
+		{"60 * * * *", "minute field out of range"},
+		{"* 5-2 * * *", "hour field out of range"},
+		{"* * 0 * *", "day of month field out of range"},
+		{"* * * x *", "invalid value in month field"},
+		{"*/0 * * * *", "invalid step in minute field"},
+	} {
+		if _, err := ParseCronExpr(tc.expr); !testutils.IsError(err, tc.err) {
+			t.Errorf("%q: expected error %q, got %v", tc.expr, tc.err, err)
+		}
+	}
+}
@@ -319,6 +319,9 @@ func (j *Job) canceled(ctx context.Context, fn func(context.Context, *client.Txn
 				return err
 			}
 		}
+		if err := j.releaseProtectedTimestamp(ctx, txn, md.Payload); err != nil {
+			return err
+		}
 		ju.UpdateStatus(StatusCanceled)
 		md.Payload.FinishedMicros = timeutil.ToUnixMicros(j.registry.clock.Now().GoTime())
 		ju.UpdatePayload(md.Payload)
@@ -342,6 +345,9 @@ func (j *Job) Failed(
 		if err := fn(ctx, txn); err != nil {
 			return err
 		}
+		if err := j.releaseProtectedTimestamp(ctx, txn, md.Payload); err != nil {
+			return err
+		}
 		ju.UpdateStatus(StatusFailed)
 		md.Payload.Error = err.Error()
 		md.Payload.FinishedMicros = timeutil.ToUnixMicros(j.registry.clock.Now().GoTime())
@@ -361,6 +367,9 @@ func (j *Job) Succeeded(ctx context.Context, fn func(context.Context, *client.Tx
 		if err := fn(ctx, txn); err != nil {
 			return err
 		}
+		if err := j.releaseProtectedTimestamp(ctx, txn, md.Payload); err != nil {
+			return err
+		}
 		ju.UpdateStatus(StatusSucceeded)
 		md.Payload.FinishedMicros = timeutil.ToUnixMicros(j.registry.clock.Now().GoTime())
 		ju.UpdatePayload(md.Payload)
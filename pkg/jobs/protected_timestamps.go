@@ -0,0 +1,100 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
+)
+
+// ProtectedTimestampMetaType is the MetaType of the protected timestamp
+// records held by jobs. Their Meta is the job ID, encoded as an ascending
+// uvarint.
+const ProtectedTimestampMetaType = "jobs"
+
+// ProtectTimestamp protects the history of the given spans at and above ts
+// from GC until the job releases it or reaches a terminal state. The
+// protected timestamp record is written in the same transaction that stores
+// its ID in the job's payload, so it can't be leaked by a job which fails
+// concurrently. It's a no-op if the job already holds a record, for example
+// because it is being resumed.
+//
+// It fails until all nodes have been upgraded to a version whose GC queue
+// honors protected timestamps. See package protectedts for how old ts may be.
+func (j *Job) ProtectTimestamp(ctx context.Context, ts hlc.Timestamp, spans []roachpb.Span) error {
+	pts := j.registry.pts
+	if pts == nil {
+		return nil
+	}
+	if err := protectedts.CheckVersion(j.registry.settings); err != nil {
+		return err
+	}
+	return j.Update(ctx, func(txn *client.Txn, md JobMetadata, ju *JobUpdater) error {
+		if md.Payload.ProtectedTimestampRecord != nil {
+			return nil
+		}
+		id := uuid.MakeV4()
+		if err := pts.Protect(ctx, txn, &protectedts.Record{
+			ID:        id,
+			Timestamp: ts,
+			MetaType:  ProtectedTimestampMetaType,
+			Meta:      encoding.EncodeUvarintAscending(nil, uint64(md.ID)),
+			Spans:     spans,
+		}); err != nil {
+			return err
+		}
+		md.Payload.ProtectedTimestampRecord = &id
+		ju.UpdatePayload(md.Payload)
+		return nil
+	})
+}
+
+// ReleaseProtectedTimestamp releases the protected timestamp record held by
+// the job, if any, before the job reaches a terminal state.
+func (j *Job) ReleaseProtectedTimestamp(ctx context.Context) error {
+	return j.Update(ctx, func(txn *client.Txn, md JobMetadata, ju *JobUpdater) error {
+		if md.Payload.ProtectedTimestampRecord == nil {
+			return nil
+		}
+		if err := j.releaseProtectedTimestamp(ctx, txn, md.Payload); err != nil {
+			return err
+		}
+		ju.UpdatePayload(md.Payload)
+		return nil
+	})
+}
+
+// releaseProtectedTimestamp releases the protected timestamp record referenced
+// by the payload, if any, and clears the reference. The caller is responsible
+// for persisting the payload in the same transaction.
+func (j *Job) releaseProtectedTimestamp(
+	ctx context.Context, txn *client.Txn, payload *jobspb.Payload,
+) error {
+	id := payload.ProtectedTimestampRecord
+	if id == nil || j.registry.pts == nil {
+		return nil
+	}
+	// The record may have been removed by an operator, in which case there's
+	// nothing left to release.
+	if err := j.registry.pts.Release(ctx, txn, *id); err != nil && err != protectedts.ErrNotExists {
+		return errors.Wrapf(err, "failed to release protected timestamp record of job %d", *j.ID())
+	}
+	payload.ProtectedTimestampRecord = nil
+	return nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -90,6 +91,7 @@ type Registry struct {
 	stopper  *stop.Stopper
 	db       *client.DB
 	ex       sqlutil.InternalExecutor
+	pts      protectedts.Storage
 	clock    *hlc.Clock
 	nodeID   *base.NodeIDContainer
 	settings *cluster.Settings
@@ -139,6 +141,7 @@ func MakeRegistry(
 	clock *hlc.Clock,
 	db *client.DB,
 	ex sqlutil.InternalExecutor,
+	pts protectedts.Storage,
 	nodeID *base.NodeIDContainer,
 	settings *cluster.Settings,
 	histogramWindowInterval time.Duration,
@@ -150,6 +153,7 @@ func MakeRegistry(
 		clock:    clock,
 		db:       db,
 		ex:       ex,
+		pts:      pts,
 		nodeID:   nodeID,
 		settings: settings,
 		planFn:   planFn,
@@ -226,6 +230,25 @@ func (r *Registry) StartJob(
 	return j, errCh, nil
 }
 
+// CreateJobWithTxn creates a job from record in the given transaction, and
+// returns it. The job is leased to this node but isn't started here: once the
+// transaction commits, the adoption loop of this node finds the job, which it
+// holds the lease on but doesn't run, and resumes it. Should this node die
+// first, the job is adopted by another node.
+func (r *Registry) CreateJobWithTxn(ctx context.Context, record Record, txn *client.Txn) (*Job, error) {
+	j := r.NewJob(record)
+	if _, err := r.createResumer(j, r.settings); err != nil {
+		return nil, err
+	}
+	if err := j.WithTxn(txn).insert(ctx, r.makeJobID(), r.newLease()); err != nil {
+		return nil, err
+	}
+	if err := j.WithTxn(txn).Started(ctx); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
 // NewJob creates a new Job.
 func (r *Registry) NewJob(record Record) *Job {
 	job := &Job{
@@ -329,6 +352,29 @@ func (r *Registry) Start(
 			}
 		}
 	})
+
+	// Changes to the scheduler interval take effect immediately, rather than
+	// after the previous interval elapses.
+	schedulerIntervalChanged := make(chan struct{}, 1)
+	schedulerInterval.SetOnChange(&r.settings.SV, func() {
+		select {
+		case schedulerIntervalChanged <- struct{}{}:
+		default:
+		}
+	})
+	stopper.RunWorker(context.Background(), func(ctx context.Context) {
+		for {
+			select {
+			case <-schedulerIntervalChanged:
+			case <-time.After(schedulerInterval.Get(&r.settings.SV)):
+				if err := r.runDueSchedules(ctx); err != nil {
+					log.Errorf(ctx, "error while running schedules: %s", err)
+				}
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
 	return nil
 }
 
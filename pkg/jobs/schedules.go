@@ -0,0 +1,254 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// Schedules are stored in the `system.scheduled_jobs` table. Every node polls
+// the table for schedules whose next_run has passed. A node claims a due
+// schedule by advancing its next_run, conditional on it not having changed, so
+// each scheduled run is executed by exactly one node. The work of a schedule
+// is done by the ScheduledJobExecutor registered for its executor_type, which
+// creates jobs in the transaction that claims the run: either the run is
+// claimed and its jobs exist, or neither happened. A schedule whose next_run
+// is NULL is paused.
+
+var schedulerInterval = settings.RegisterNonNegativeDurationSetting(
+	"jobs.scheduler.interval",
+	"how often each node checks for scheduled jobs that are due to run",
+	time.Minute,
+)
+
+// maxSchedulesPerPoll bounds the number of schedules a node claims each time
+// it polls the schedules table.
+const maxSchedulesPerPoll = 100
+
+// ScheduledJob is a schedule that is due to run.
+type ScheduledJob struct {
+	ID    int64
+	Name  string
+	Owner string
+	// ScheduledTime is the time this run of the schedule was due.
+	ScheduledTime time.Time
+	// ExecutionArgs is the executor-specific state of the schedule. Changes
+	// made by the executor are persisted along with the claim of the run.
+	ExecutionArgs []byte
+}
+
+// ScheduledJobExecutor does the work of the schedules of one executor type.
+type ScheduledJobExecutor interface {
+	// ExecuteJob runs the given schedule once, in the transaction claiming the
+	// run. It must not wait for the work it starts, e.g. a backup, to finish:
+	// it creates the jobs doing the work in txn, and they only start once txn
+	// commits.
+	ExecuteJob(ctx context.Context, ex sqlutil.InternalExecutor, txn *client.Txn, schedule *ScheduledJob) error
+}
+
+var scheduledJobExecutors = make(map[string]ScheduledJobExecutor)
+
+// RegisterScheduledJobExecutor registers the executor for schedules of the
+// given executor type.
+func RegisterScheduledJobExecutor(executorType string, executor ScheduledJobExecutor) {
+	scheduledJobExecutors[executorType] = executor
+}
+
+// ScheduleRecord bundles together the user-managed fields of a new schedule.
+type ScheduleRecord struct {
+	Name          string
+	Owner         string
+	ScheduleExpr  string
+	ExecutorType  string
+	ExecutionArgs []byte
+}
+
+// CreateSchedule validates the record's cron expression and inserts a new
+// schedule, which is first run at the next time the expression matches. It
+// returns the ID of the schedule.
+func (r *Registry) CreateSchedule(
+	ctx context.Context, txn *client.Txn, record ScheduleRecord,
+) (int64, error) {
+	if _, ok := scheduledJobExecutors[record.ExecutorType]; !ok {
+		return 0, errors.Errorf("no executor registered for schedules of type %q", record.ExecutorType)
+	}
+	next, err := nextScheduledRun(record.ScheduleExpr, timeutil.Now())
+	if err != nil {
+		return 0, err
+	}
+	row, err := r.ex.QueryRow(ctx, "create-schedule", txn,
+		`INSERT INTO system.scheduled_jobs
+		   (schedule_name, owner, next_run, schedule_expr, executor_type, execution_args)
+		 VALUES ($1, $2, $3, $4, $5, $6) RETURNING schedule_id`,
+		record.Name, record.Owner, next, record.ScheduleExpr, record.ExecutorType, record.ExecutionArgs,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "creating schedule")
+	}
+	return int64(tree.MustBeDInt(row[0])), nil
+}
+
+// PauseSchedule stops the schedule with the given ID from running until it is
+// resumed.
+func (r *Registry) PauseSchedule(ctx context.Context, txn *client.Txn, id int64) error {
+	return r.updateSchedule(ctx, txn, "pause-schedule", id,
+		`UPDATE system.scheduled_jobs SET next_run = NULL WHERE schedule_id = $1`)
+}
+
+// ResumeSchedule resumes the schedule with the given ID, which next runs at
+// the next time its cron expression matches.
+func (r *Registry) ResumeSchedule(ctx context.Context, txn *client.Txn, id int64) error {
+	row, err := r.ex.QueryRow(ctx, "resume-schedule", txn,
+		`SELECT schedule_expr, next_run FROM system.scheduled_jobs WHERE schedule_id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if row == nil {
+		return errors.Errorf("schedule %d not found", id)
+	}
+	if row[1] != tree.DNull {
+		// The schedule isn't paused.
+		return nil
+	}
+	next, err := nextScheduledRun(string(tree.MustBeDString(row[0])), timeutil.Now())
+	if err != nil {
+		return err
+	}
+	return r.updateSchedule(ctx, txn, "resume-schedule", id,
+		`UPDATE system.scheduled_jobs SET next_run = $2 WHERE schedule_id = $1`, next)
+}
+
+// DropSchedule deletes the schedule with the given ID. Jobs already started by
+// the schedule are not affected.
+func (r *Registry) DropSchedule(ctx context.Context, txn *client.Txn, id int64) error {
+	return r.updateSchedule(ctx, txn, "drop-schedule", id,
+		`DELETE FROM system.scheduled_jobs WHERE schedule_id = $1`)
+}
+
+func (r *Registry) updateSchedule(
+	ctx context.Context, txn *client.Txn, opName string, id int64, stmt string, qargs ...interface{},
+) error {
+	n, err := r.ex.Exec(ctx, opName, txn, stmt, append([]interface{}{id}, qargs...)...)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.Errorf("schedule %d not found", id)
+	}
+	return nil
+}
+
+// nextScheduledRun returns the first time after now matched by the cron
+// expression expr.
+func nextScheduledRun(expr string, now time.Time) (time.Time, error) {
+	e, err := ParseCronExpr(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := e.Next(now)
+	if next.IsZero() {
+		return time.Time{}, errors.Errorf("cron expression %q never matches", expr)
+	}
+	return next, nil
+}
+
+// runDueSchedules claims and executes the schedules that are due to run.
+func (r *Registry) runDueSchedules(ctx context.Context) error {
+	// The schedules table is created by a migration, which may not have run
+	// yet while the cluster is being upgraded.
+	if !r.settings.Version.IsActive(cluster.VersionScheduledJobs) {
+		return nil
+	}
+
+	now := timeutil.Now()
+	rows, err := r.ex.Query(ctx, "find-due-schedules", nil, /* txn */
+		`SELECT schedule_id, schedule_name, owner, next_run, schedule_expr, executor_type, execution_args
+		 FROM system.scheduled_jobs WHERE next_run <= $1 ORDER BY next_run LIMIT $2`,
+		now, maxSchedulesPerPoll,
+	)
+	if err != nil {
+		return errors.Wrap(err, "finding due schedules")
+	}
+
+	for _, row := range rows {
+		schedule := &ScheduledJob{
+			ID:            int64(tree.MustBeDInt(row[0])),
+			Name:          string(tree.MustBeDString(row[1])),
+			Owner:         string(tree.MustBeDString(row[2])),
+			ScheduledTime: row[3].(*tree.DTimestamp).Time,
+		}
+		expr := string(tree.MustBeDString(row[4]))
+		executorType := string(tree.MustBeDString(row[5]))
+		executionArgs := []byte(tree.MustBeDBytes(row[6]))
+
+		// A schedule whose cron expression is unusable is paused.
+		var next interface{} = tree.DNull
+		if t, err := nextScheduledRun(expr, now); err != nil {
+			log.Warningf(ctx, "pausing schedule %d: %v", schedule.ID, err)
+		} else {
+			next = t
+		}
+		executor, ok := scheduledJobExecutors[executorType]
+		if !ok {
+			log.Warningf(ctx, "no executor registered for schedule %d of type %q", schedule.ID, executorType)
+		}
+
+		if err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			schedule.ExecutionArgs = executionArgs
+			claimed, err := r.claimScheduledRun(ctx, txn, schedule.ID, row[3], next)
+			if err != nil || !claimed || next == tree.DNull || executor == nil {
+				return err
+			}
+			log.Infof(ctx, "running schedule %d (%s)", schedule.ID, schedule.Name)
+			if err := executor.ExecuteJob(ctx, r.ex, txn, schedule); err != nil {
+				return err
+			}
+			_, err = r.ex.Exec(ctx, "update-schedule-args", txn,
+				`UPDATE system.scheduled_jobs SET execution_args = $2 WHERE schedule_id = $1`,
+				schedule.ID, schedule.ExecutionArgs,
+			)
+			return err
+		}); err != nil {
+			log.Errorf(ctx, "error running schedule %d (%s): %v", schedule.ID, schedule.Name, err)
+			// Skip the run, rather than retrying it at every poll.
+			if _, err := r.claimScheduledRun(ctx, nil /* txn */, schedule.ID, row[3], next); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// claimScheduledRun claims the run of the schedule with the given ID that was
+// due at the given time by advancing its next_run. It returns false if another
+// node got there first, or the schedule was paused or dropped in the meantime.
+func (r *Registry) claimScheduledRun(
+	ctx context.Context, txn *client.Txn, id int64, due tree.Datum, next interface{},
+) (bool, error) {
+	n, err := r.ex.Exec(ctx, "claim-schedule", txn,
+		`UPDATE system.scheduled_jobs SET next_run = $3 WHERE schedule_id = $1 AND next_run = $2`,
+		id, due, next,
+	)
+	if err != nil {
+		return false, errors.Wrapf(err, "claiming schedule %d", id)
+	}
+	return n > 0, nil
+}
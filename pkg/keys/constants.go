@@ -326,6 +326,8 @@ const (
 	LivenessRangesID       = 22
 	RoleMembersTableID     = 23
 	CommentsTableID        = 24
+	ScheduledJobsTableID   = 25
+	ProtectedTSRecordsID   = 26
 
 	// CommentType is type for system.comments
 	DatabaseCommentType = 0
@@ -491,16 +491,22 @@ func (ds *DistSender) getDescriptor(
 func (ds *DistSender) sendSingleRange(
 	ctx context.Context, ba roachpb.BatchRequest, desc *roachpb.RangeDescriptor, withCommit bool,
 ) (*roachpb.BatchResponse, *roachpb.Error) {
+	canSendToFollower := ds.clusterID != nil &&
+		CanSendToFollower(ds.clusterID.Get(), ds.st, ba)
+
 	// Try to send the call. Learner replicas won't serve reads/writes, so send
 	// only to the `Voters` replicas. This is just an optimization to save a
-	// network hop, everything would still work if we had `All` here.
-	replicas := NewReplicaSlice(ds.gossip, desc.Replicas().Voters())
+	// network hop, everything would still work if we had `All` here. Non-voting
+	// replicas serve follower reads, so they're included if this is one.
+	replicaDescs := desc.Replicas().Voters()
+	if canSendToFollower {
+		replicaDescs = append(replicaDescs, desc.Replicas().NonVoters()...)
+	}
+	replicas := NewReplicaSlice(ds.gossip, replicaDescs)
 
 	// If this request needs to go to a lease holder and we know who that is, move
 	// it to the front.
 	var cachedLeaseHolder roachpb.ReplicaDescriptor
-	canSendToFollower := ds.clusterID != nil &&
-		CanSendToFollower(ds.clusterID.Get(), ds.st, ba)
 	if !canSendToFollower && ba.RequiresLeaseHolder() {
 		if storeID, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
 			if i := replicas.FindReplica(storeID); i >= 0 {
@@ -390,9 +390,11 @@ func (h *txnHeartbeater) abortTxnAsyncLocked(ctx context.Context) {
 }
 
 // firstWriteIndex returns the index of the first transactional write in the
-// BatchRequest. Returns -1 if the batch has not intention to write. It also
-// verifies that if an EndTransactionRequest is included, then it is the last
-// request in the batch.
+// BatchRequest. Returns -1 if the batch has not intention to write. Locking
+// reads are treated as writes, since the locks that they acquire need to be
+// released by the transaction's record when it finalizes. It also verifies
+// that if an EndTransactionRequest is included, then it is the last request
+// in the batch.
 func firstWriteIndex(ba *roachpb.BatchRequest) (int, *roachpb.Error) {
 	for i, ru := range ba.Requests {
 		args := ru.GetInner()
@@ -401,7 +403,7 @@ func firstWriteIndex(ba *roachpb.BatchRequest) (int, *roachpb.Error) {
 				return -1, roachpb.NewErrorf("%s sent as non-terminal call", args.Method())
 			}
 		}
-		if roachpb.IsTransactionWrite(args) {
+		if roachpb.IsTransactionWrite(args) || roachpb.IsLocking(args) {
 			return i, nil
 		}
 	}
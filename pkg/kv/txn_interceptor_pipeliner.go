@@ -267,6 +267,10 @@ func (tp *txnPipeliner) attachWritesToEndTxn(
 				w := roachpb.SequencedWrite{Key: h.Key, Sequence: h.Sequence}
 				et.InFlightWrites = append(et.InFlightWrites, w)
 			}
+		} else if roachpb.IsLocking(req) {
+			// Locking reads don't leave intents, but the locks that they
+			// acquire must be released when the transaction finalizes.
+			et.IntentSpans = append(et.IntentSpans, h.Span())
 		}
 	}
 
@@ -515,6 +519,13 @@ func (tp *txnPipeliner) updateWriteTracking(
 					tp.footprint.insert(sp)
 				}
 			}
+		} else if roachpb.IsLocking(req) {
+			// If the request was a locking read, track the span that it
+			// acquired locks over so that the locks are released when the
+			// transaction finalizes.
+			if sp, ok := roachpb.ActualSpan(req, resp); ok {
+				tp.footprint.insert(sp)
+			}
 		}
 	}
 }
@@ -88,6 +88,7 @@ const (
 	isWrite                         // write cmds go through raft and must be proposed on lease holder
 	isTxn                           // txn commands may be part of a transaction
 	isTxnWrite                      // txn write cmds start heartbeat and are marked for intent resolution
+	isLocking                       // txn read cmds which acquire locks that are released when the txn is finalized
 	isRange                         // range commands may span multiple keys
 	isReverse                       // reverse commands traverse ranges in descending direction
 	isAlone                         // requests which must be alone in a batch
@@ -127,6 +128,12 @@ func IsTransactionWrite(args Request) bool {
 	return (args.flags() & isTxnWrite) != 0
 }
 
+// IsLocking returns true if the request acquires locks on the keys that it
+// reads when used within a transaction.
+func IsLocking(args Request) bool {
+	return (args.flags() & isLocking) != 0
+}
+
 // IsRange returns true if the command is range-based and must include
 // a start and an end key.
 func IsRange(args Request) bool {
@@ -1056,9 +1063,20 @@ func (*ClearRangeRequest) flags() int { return isWrite | isRange | isAlone }
 // they clear all MVCC versions above their target time.
 func (*RevertRangeRequest) flags() int { return isWrite | isRange }
 
-func (*ScanRequest) flags() int { return isRead | isRange | isTxn | updatesReadTSCache | needsRefresh }
-func (*ReverseScanRequest) flags() int {
-	return isRead | isRange | isReverse | isTxn | updatesReadTSCache | needsRefresh
+func (r *ScanRequest) flags() int {
+	return isRead | isRange | isTxn | flagForKeyLocking(r.KeyLocking) | updatesReadTSCache | needsRefresh
+}
+func (r *ReverseScanRequest) flags() int {
+	return isRead | isRange | isReverse | isTxn | flagForKeyLocking(r.KeyLocking) | updatesReadTSCache | needsRefresh
+}
+
+// flagForKeyLocking returns the isLocking flag if the key locking strength
+// requires locks to be acquired.
+func flagForKeyLocking(str KeyLockingStrength) int {
+	if str != KEY_LOCKING_NONE {
+		return isLocking
+	}
+	return 0
 }
 func (*BeginTransactionRequest) flags() int { return isWrite | isTxn }
 
@@ -1230,6 +1248,12 @@ func (rc ReplicationChanges) Additions() []ReplicationTarget {
 	return rc.byType(ADD_REPLICA)
 }
 
+// NonVoterAdditions returns a slice of all contained replication changes that
+// add non-voting replicas.
+func (rc ReplicationChanges) NonVoterAdditions() []ReplicationTarget {
+	return rc.byType(ADD_NON_VOTER)
+}
+
 // Removals returns a slice of all contained replication changes that remove replicas.
 func (rc ReplicationChanges) Removals() []ReplicationTarget {
 	return rc.byType(REMOVE_REPLICA)
@@ -138,6 +138,12 @@ func (ba *BatchRequest) IsTransactionWrite() bool {
 	return ba.hasFlag(isTxnWrite)
 }
 
+// IsLocking returns true iff the BatchRequest contains a request that
+// acquires locks on the keys that it reads.
+func (ba *BatchRequest) IsLocking() bool {
+	return ba.hasFlag(isLocking)
+}
+
 // IsUnsplittable returns true iff the BatchRequest an un-splittable request.
 func (ba *BatchRequest) IsUnsplittable() bool {
 	return ba.hasFlag(isUnsplittable)
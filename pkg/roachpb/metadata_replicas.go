@@ -29,6 +29,13 @@ func ReplicaTypeLearner() *ReplicaType {
 	return &t
 }
 
+// ReplicaTypeNonVoter returns a ReplicaType_NON_VOTER pointer suitable for use
+// in a nullable proto field.
+func ReplicaTypeNonVoter() *ReplicaType {
+	t := ReplicaType_NON_VOTER
+	return &t
+}
+
 // ReplicaDescriptors is a set of replicas, usually the nodes/stores on which
 // replicas of a range are stored.
 type ReplicaDescriptors struct {
@@ -63,8 +70,8 @@ func (d ReplicaDescriptors) String() string {
 	return buf.String()
 }
 
-// All returns every replica in the set, including both voter replicas and
-// learner replicas. Voter replicas are ordered first in the returned slice.
+// All returns every replica in the set, including voter, learner and
+// non-voting replicas.
 func (d ReplicaDescriptors) All() []ReplicaDescriptor {
 	return d.wrapped
 }
@@ -109,10 +116,8 @@ func (d ReplicaDescriptors) Voters() []ReplicaDescriptor {
 // then a second ConfChange promotes it to a full replica.
 //
 // This means that learners are currently always expected to have a short
-// lifetime, approximately the time it takes to send a snapshot. Ideas have been
-// kicked around to use learners with follower reads, which could be a cheap way
-// to allow many geographies to have local reads without affecting write
-// latencies. If implemented, these learners would have long lifetimes.
+// lifetime, approximately the time it takes to send a snapshot. Long-lived
+// replicas which don't vote are of a different type; see NonVoters.
 //
 // For simplicity, CockroachDB treats learner replicas the same as voter
 // replicas as much as possible, but there are a few exceptions:
@@ -195,6 +200,30 @@ func (d ReplicaDescriptors) Learners() []ReplicaDescriptor {
 	return learners
 }
 
+// NonVoters returns the non-voting replicas in the set. This may allocate.
+//
+// Like a learner, a non-voter is a raft learner: it receives the log but
+// doesn't vote, so it doesn't affect the quorum (nor the commit latency) of the
+// range. Unlike learners, non-voters are long-lived. They're placed by the
+// allocator to satisfy the num_voters field of the zone config, which allows
+// ranges to have local replicas in regions whose replicas shouldn't take part
+// in consensus, and they serve follower reads.
+//
+// Non-voters are treated like learners in most other respects: they can't hold
+// the lease and they're removed before merges and relocations, after which the
+// replicate queue adds them back. In contrast to learners, they're never
+// promoted to voters and they aren't considered by the quota pool, so a slow or
+// far away non-voter doesn't slow down writes.
+func (d ReplicaDescriptors) NonVoters() []ReplicaDescriptor {
+	var nonVoters []ReplicaDescriptor
+	for i := range d.wrapped {
+		if d.wrapped[i].GetType() == ReplicaType_NON_VOTER {
+			nonVoters = append(nonVoters, d.wrapped[i])
+		}
+	}
+	return nonVoters
+}
+
 // AsProto returns the protobuf representation of these replicas, suitable for
 // setting the InternalReplicas field of a RangeDescriptor. When possible the
 // SetReplicas method of RangeDescriptor should be used instead, this is only
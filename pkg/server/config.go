@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/elastic/gosigar"
+	pebblecache "github.com/petermattis/pebble/cache"
 	"github.com/pkg/errors"
 )
 
@@ -427,8 +428,25 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 
 	var details []string
 
-	details = append(details, fmt.Sprintf("RocksDB cache size: %s", humanizeutil.IBytes(cfg.CacheSize)))
-	cache := engine.NewRocksDBCache(cfg.CacheSize)
+	// Pebble stores share a block cache of their own, since they can't use the
+	// RocksDB one. The two caches split cfg.CacheSize in proportion to the
+	// number of stores using each engine.
+	var pebbleStores int
+	for _, spec := range cfg.Stores.Specs {
+		if spec.Engine == base.EngineTypePebble {
+			pebbleStores++
+		}
+	}
+	rocksDBCacheSize := cfg.CacheSize
+	var pebbleCache *pebblecache.Cache
+	if pebbleStores > 0 {
+		pebbleCacheSize := cfg.CacheSize * int64(pebbleStores) / int64(len(cfg.Stores.Specs))
+		rocksDBCacheSize -= pebbleCacheSize
+		details = append(details, fmt.Sprintf("Pebble cache size: %s", humanizeutil.IBytes(pebbleCacheSize)))
+		pebbleCache = pebblecache.New(pebbleCacheSize)
+	}
+	details = append(details, fmt.Sprintf("RocksDB cache size: %s", humanizeutil.IBytes(rocksDBCacheSize)))
+	cache := engine.NewRocksDBCache(rocksDBCacheSize)
 	defer cache.Release()
 
 	var physicalStores int
@@ -461,6 +479,17 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 				return Engines{}, errors.Errorf("%f%% of memory is only %s bytes, which is below the minimum requirement of %s",
 					spec.Size.Percent, humanizeutil.IBytes(sizeInBytes), humanizeutil.IBytes(base.MinimumStoreSize))
 			}
+			if spec.Engine == base.EngineTypePebble {
+				details = append(details, fmt.Sprintf("store %d: in-memory Pebble, size %s",
+					i, humanizeutil.IBytes(sizeInBytes)))
+				pebbleConfig := cfg.pebbleConfig(spec, "" /* dir */, sizeInBytes, pebbleCache)
+				eng, err := engine.NewPebble(ctx, pebbleConfig)
+				if err != nil {
+					return Engines{}, err
+				}
+				engines = append(engines, eng)
+				continue
+			}
 			details = append(details, fmt.Sprintf("store %d: in-memory, size %s",
 				i, humanizeutil.IBytes(sizeInBytes)))
 			engines = append(engines, engine.NewInMem(spec.Attributes, sizeInBytes))
@@ -477,6 +506,22 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 					spec.Size.Percent, spec.Path, humanizeutil.IBytes(sizeInBytes), humanizeutil.IBytes(base.MinimumStoreSize))
 			}
 
+			if spec.Engine == base.EngineTypePebble {
+				if spec.UseFileRegistry {
+					return Engines{}, errors.Errorf(
+						"store %d: encryption-at-rest is not supported on pebble stores", i)
+				}
+				details = append(details, fmt.Sprintf("store %d: Pebble, max size %s",
+					i, humanizeutil.IBytes(sizeInBytes)))
+				pebbleConfig := cfg.pebbleConfig(spec, spec.Path, sizeInBytes, pebbleCache)
+				eng, err := engine.NewPebble(ctx, pebbleConfig)
+				if err != nil {
+					return Engines{}, err
+				}
+				engines = append(engines, eng)
+				continue
+			}
+
 			details = append(details, fmt.Sprintf("store %d: RocksDB, max size %s, max open file limit %d",
 				i, humanizeutil.IBytes(sizeInBytes), openFileLimitPerStore))
 			rocksDBConfig := engine.RocksDBConfig{
@@ -509,6 +554,22 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 	return enginesCopy, nil
 }
 
+// pebbleConfig returns the configuration of a Pebble engine for the given
+// store spec. An empty dir creates an in-memory engine.
+func (cfg *Config) pebbleConfig(
+	spec base.StoreSpec, dir string, sizeInBytes int64, cache *pebblecache.Cache,
+) engine.PebbleConfig {
+	opts := engine.DefaultPebbleOptions()
+	opts.Cache = cache
+	return engine.PebbleConfig{
+		Attrs:        spec.Attributes,
+		Dir:          dir,
+		MaxSizeBytes: sizeInBytes,
+		Settings:     cfg.Settings,
+		Opts:         opts,
+	}
+}
+
 // InitNode parses node attributes and initializes the gossip bootstrap
 // resolvers.
 func (cfg *Config) InitNode() error {
@@ -58,6 +58,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/bulk"
 	"github.com/cockroachdb/cockroach/pkg/storage/closedts/container"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/ui"
@@ -185,6 +186,7 @@ type Server struct {
 	// shared between the sql.Server and the statusServer.
 	sessionRegistry    *sql.SessionRegistry
 	jobRegistry        *jobs.Registry
+	protectedts        protectedts.Provider
 	statsRefresher     *stats.Refresher
 	engines            Engines
 	internalMemMetrics sql.MemoryMetrics
@@ -429,6 +431,12 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	// Similarly for execCfg.
 	var execCfg sql.ExecutorConfig
 
+	s.protectedts = protectedts.NewProvider(protectedts.Config{
+		Settings: st,
+		DB:       s.db,
+		Executor: internalExecutor,
+	})
+
 	// TODO(bdarnell): make StoreConfig configurable.
 	storeCfg := storage.StoreConfig{
 		DefaultZoneConfig:       &s.cfg.DefaultZoneConfig,
@@ -452,6 +460,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		LogRangeEvents:          s.cfg.EventLogEnabled,
 		RangeDescriptorCache:    s.distSender.RangeDescriptorCache(),
 		TimeSeriesDataStore:     s.tsDB,
+		ProtectedTimestampCache: s.protectedts,
 
 		// Initialize the closed timestamp subsystem. Note that it won't
 		// be ready until it is .Start()ed, but the grpc server can be
@@ -500,6 +509,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		s.clock,
 		s.db,
 		internalExecutor,
+		s.protectedts,
 		&s.nodeIDContainer,
 		st,
 		s.cfg.HistogramWindowInterval(),
@@ -1561,6 +1571,12 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 	log.Infof(ctx, "done ensuring all necessary migrations have run")
 
+	// Start polling the protected timestamp records, which the GC queue waits
+	// for. The table they're stored in may have been created by a migration.
+	if err := s.protectedts.Start(ctx, s.stopper); err != nil {
+		return err
+	}
+
 	// Start garbage collecting system events.
 	s.startSystemLogsGC(ctx)
 
@@ -43,6 +43,16 @@ const (
 	VersionLearnerReplicas
 	VersionTopLevelForeignKeys
 	VersionAtomicChangeReplicasTrigger
+	VersionRowLevelLocking
+	VersionPartialIndexes
+	VersionAlterPrimaryKey
+	VersionScheduledJobs
+	VersionNonVotingReplicas
+	VersionTemporaryTables
+	VersionBackupEncryption
+	VersionEnums
+	VersionProtectedTimestamps
+	VersionChangefeedDiff
 
 	// Add new versions here (step one of two).
 
@@ -520,6 +530,81 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     VersionAtomicChangeReplicasTrigger,
 		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 8},
 	},
+	{
+		// VersionRowLevelLocking is the version where the KeyLocking and LockWaitPolicy
+		// fields of ScanRequest and ReverseScanRequest are introduced. Nodes at older
+		// versions ignore them, so SELECT FOR UPDATE/SHARE is rejected until this
+		// version is active.
+		Key:     VersionRowLevelLocking,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 9},
+	},
+	{
+		// VersionPartialIndexes is the version where the predicate field of
+		// IndexDescriptor is introduced. Nodes at older versions ignore it and
+		// would maintain the index for every row, so partial indexes are rejected
+		// until this version is active.
+		Key:     VersionPartialIndexes,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 10},
+	},
+	{
+		// VersionAlterPrimaryKey is the version where the PrimaryKeySwap mutation and
+		// the encoding type of IndexDescriptor are introduced. Nodes at older versions
+		// don't know how to maintain the indexes built for a primary key change, so
+		// ALTER PRIMARY KEY is rejected until this version is active.
+		Key:     VersionAlterPrimaryKey,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 11},
+	},
+	{
+		// VersionScheduledJobs is the version where the system.scheduled_jobs table is
+		// introduced. Schedules aren't polled until this version is active, as the
+		// table may not have been created yet.
+		Key:     VersionScheduledJobs,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 12},
+	},
+	{
+		// VersionNonVotingReplicas is the version where ranges can have
+		// non-voting replicas, which are added with ADD_NON_VOTER and configured
+		// by the num_voters and voter_constraints zone config fields.
+		Key:     VersionNonVotingReplicas,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 13},
+	},
+	{
+		// VersionTemporaryTables is the version where temporary tables and views can
+		// be created. Older nodes don't know about the temporary schemas they're
+		// registered under in system.namespace, so they can't resolve them.
+		Key:     VersionTemporaryTables,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 14},
+	},
+	{
+		// VersionBackupEncryption is the version where BACKUP and RESTORE accept
+		// the encryption_passphrase and encryption_key_file options. Older nodes
+		// ignore the encryption options of ExportRequests and of backup jobs, so
+		// they'd write the files of the backup in the clear.
+		Key:     VersionBackupEncryption,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 15},
+	},
+	{
+		// VersionEnums is the version where user-defined ENUM types can be
+		// created and used. Older nodes can't decode type descriptors nor the
+		// ENUM family of column types.
+		Key:     VersionEnums,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 16},
+	},
+	{
+		// VersionProtectedTimestamps is the version where jobs can protect the
+		// history of the spans they read from GC. The GC queues of older nodes
+		// don't consult system.protected_ts_records, so a record wouldn't be
+		// honored on ranges whose leaseholder runs an older version.
+		Key:     VersionProtectedTimestamps,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 17},
+	},
+	{
+		// VersionChangefeedDiff is the version where RangeFeedRequests can ask
+		// for the previous values of the keys they emit, which changefeeds use
+		// for the diff option. Older nodes ignore the WithDiff field.
+		Key:     VersionChangefeedDiff,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 18},
+	},
 
 	// Add new versions here (step two of two).
 
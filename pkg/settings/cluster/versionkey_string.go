@@ -20,11 +20,21 @@ func _() {
 	_ = x[VersionLearnerReplicas-9]
 	_ = x[VersionTopLevelForeignKeys-10]
 	_ = x[VersionAtomicChangeReplicasTrigger-11]
+	_ = x[VersionRowLevelLocking-12]
+	_ = x[VersionPartialIndexes-13]
+	_ = x[VersionAlterPrimaryKey-14]
+	_ = x[VersionScheduledJobs-15]
+	_ = x[VersionNonVotingReplicas-16]
+	_ = x[VersionTemporaryTables-17]
+	_ = x[VersionBackupEncryption-18]
+	_ = x[VersionEnums-19]
+	_ = x[VersionProtectedTimestamps-20]
+	_ = x[VersionChangefeedDiff-21]
 }
 
-const _VersionKey_name = "Version2_1VersionUnreplicatedRaftTruncatedStateVersionSideloadedStorageNoReplicaIDVersion19_1VersionStart19_2VersionQueryTxnTimestampVersionStickyBitVersionParallelCommitsVersionGenerationComparableVersionLearnerReplicasVersionTopLevelForeignKeysVersionAtomicChangeReplicasTrigger"
+const _VersionKey_name = "Version2_1VersionUnreplicatedRaftTruncatedStateVersionSideloadedStorageNoReplicaIDVersion19_1VersionStart19_2VersionQueryTxnTimestampVersionStickyBitVersionParallelCommitsVersionGenerationComparableVersionLearnerReplicasVersionTopLevelForeignKeysVersionAtomicChangeReplicasTriggerVersionRowLevelLockingVersionPartialIndexesVersionAlterPrimaryKeyVersionScheduledJobsVersionNonVotingReplicasVersionTemporaryTablesVersionBackupEncryptionVersionEnumsVersionProtectedTimestampsVersionChangefeedDiff"
 
-var _VersionKey_index = [...]uint16{0, 10, 47, 82, 93, 109, 133, 149, 171, 198, 220, 246, 280}
+var _VersionKey_index = [...]uint16{0, 10, 47, 82, 93, 109, 133, 149, 171, 198, 220, 246, 280, 302, 323, 345, 365, 389, 411, 434, 446, 472, 493}
 
 func (i VersionKey) String() string {
 	if i < 0 || i >= VersionKey(len(_VersionKey_index)-1) {
@@ -0,0 +1,265 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+// alterPrimaryKey queues up the mutations changing the primary key of the
+// table to the given columns. The change is performed online by the schema
+// changer: a new primary index keyed on the new columns, and a copy of every
+// secondary index encoded against the new primary key, are built by the
+// index backfiller while being maintained by writers. Once they are built, a
+// PrimaryKeySwap mutation makes them public all at once, and the old indexes
+// are dropped. Unless the new primary key implies it, the uniqueness of the
+// old primary key columns is preserved by a new unique secondary index.
+func alterPrimaryKey(
+	tableDesc *sqlbase.MutableTableDescriptor, alterPKNode *tree.AlterTableAlterPrimaryKey,
+) error {
+	if tableDesc.IsNewTable() {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot alter the primary key of table %q in the transaction that created it",
+			tableDesc.Name)
+	}
+	if len(tableDesc.Mutations) > 0 {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"table %q is currently undergoing a schema change, try again later", tableDesc.Name)
+	}
+	if tableDesc.IsInterleaved() {
+		return unimplemented.New("alter-primary-key-interleaved",
+			"cannot alter the primary key of an interleaved table")
+	}
+	if len(tableDesc.OutboundFKs) > 0 || len(tableDesc.InboundFKs) > 0 {
+		return unimplemented.New("alter-primary-key-fk",
+			"cannot alter the primary key of a table with foreign key references")
+	}
+	for _, idx := range tableDesc.AllNonDropIndexes() {
+		if idx.Partitioning.NumColumns > 0 {
+			return unimplemented.New("alter-primary-key-partitioned",
+				"cannot alter the primary key of a partitioned table")
+		}
+	}
+
+	isComposite := make(map[sqlbase.ColumnID]struct{})
+	familyZero := make(map[sqlbase.ColumnID]struct{})
+	for _, col := range tableDesc.Columns {
+		if sqlbase.HasCompositeKeyEncoding(col.Type.Family()) {
+			isComposite[col.ID] = struct{}{}
+		}
+	}
+	for _, id := range tableDesc.Families[0].ColumnIDs {
+		familyZero[id] = struct{}{}
+	}
+	setCompositeColumnIDs := func(idx *sqlbase.IndexDescriptor) {
+		idx.CompositeColumnIDs = nil
+		for _, ids := range [][]sqlbase.ColumnID{idx.ColumnIDs, idx.ExtraColumnIDs} {
+			for _, id := range ids {
+				if _, ok := isComposite[id]; ok {
+					idx.CompositeColumnIDs = append(idx.CompositeColumnIDs, id)
+				}
+			}
+		}
+	}
+	allocateIndexID := func(idx *sqlbase.IndexDescriptor) {
+		idx.ID = tableDesc.NextIndexID
+		tableDesc.NextIndexID++
+	}
+
+	// The new primary index is encoded like a primary index, and so stores all
+	// the columns of the table.
+	newPrimaryIndex := sqlbase.IndexDescriptor{
+		Name:         makeTemporaryIndexName(tableDesc, "new_primary_key"),
+		Unique:       true,
+		EncodingType: sqlbase.PrimaryIndexEncoding,
+	}
+	if err := newPrimaryIndex.FillColumns(alterPKNode.Columns); err != nil {
+		return err
+	}
+	inPrimaryKey := make(map[sqlbase.ColumnID]struct{}, len(newPrimaryIndex.ColumnNames))
+	for _, name := range newPrimaryIndex.ColumnNames {
+		col, err := tableDesc.FindActiveColumnByName(name)
+		if err != nil {
+			return err
+		}
+		if _, ok := inPrimaryKey[col.ID]; ok {
+			return pgerror.Newf(pgcode.DuplicateColumn,
+				"column %q appears twice in primary key", col.Name)
+		}
+		if col.Nullable {
+			return pgerror.Newf(pgcode.InvalidSchemaDefinition,
+				"cannot use nullable column %q in primary key", col.Name)
+		}
+		if _, ok := familyZero[col.ID]; !ok {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"primary key column %q must be in column family %q",
+				col.Name, tableDesc.Families[0].Name)
+		}
+		inPrimaryKey[col.ID] = struct{}{}
+		newPrimaryIndex.ColumnIDs = append(newPrimaryIndex.ColumnIDs, col.ID)
+	}
+	for _, col := range tableDesc.Columns {
+		if _, ok := inPrimaryKey[col.ID]; !ok {
+			newPrimaryIndex.StoreColumnIDs = append(newPrimaryIndex.StoreColumnIDs, col.ID)
+			newPrimaryIndex.StoreColumnNames = append(newPrimaryIndex.StoreColumnNames, col.Name)
+		}
+	}
+	allocateIndexID(&newPrimaryIndex)
+	setCompositeColumnIDs(&newPrimaryIndex)
+	if err := tableDesc.AddIndexMutation(&newPrimaryIndex, sqlbase.DescriptorMutation_ADD); err != nil {
+		return err
+	}
+
+	// Secondary indexes embed the primary key columns, so each of them is
+	// rebuilt against the new primary key.
+	swap := &sqlbase.PrimaryKeySwap{NewPrimaryIndexID: newPrimaryIndex.ID}
+	for i := range tableDesc.Indexes {
+		oldIdx := &tableDesc.Indexes[i]
+		newIdx := protoutil.Clone(oldIdx).(*sqlbase.IndexDescriptor)
+		newIdx.Name = makeTemporaryIndexName(tableDesc, oldIdx.Name+"_rewrite")
+		newIdx.ExtraColumnIDs = nil
+		newIdx.StoreColumnIDs = nil
+		newIdx.StoreColumnNames = nil
+		for _, name := range oldIdx.StoreColumnNames {
+			col, err := tableDesc.FindActiveColumnByName(name)
+			if err != nil {
+				return err
+			}
+			if _, ok := inPrimaryKey[col.ID]; ok {
+				// Implicitly part of the index from now on.
+				continue
+			}
+			newIdx.StoreColumnIDs = append(newIdx.StoreColumnIDs, col.ID)
+			newIdx.StoreColumnNames = append(newIdx.StoreColumnNames, col.Name)
+		}
+		for _, id := range newPrimaryIndex.ColumnIDs {
+			if !columnIDsContain(newIdx.ColumnIDs, id) {
+				newIdx.ExtraColumnIDs = append(newIdx.ExtraColumnIDs, id)
+			}
+		}
+		allocateIndexID(newIdx)
+		setCompositeColumnIDs(newIdx)
+		if err := tableDesc.AddIndexMutation(newIdx, sqlbase.DescriptorMutation_ADD); err != nil {
+			return err
+		}
+		swap.OldIndexIDs = append(swap.OldIndexIDs, oldIdx.ID)
+		swap.NewIndexIDs = append(swap.NewIndexIDs, newIdx.ID)
+	}
+
+	if needsOldPrimaryKeyIndex(tableDesc, inPrimaryKey) {
+		oldPrimaryIndex := &tableDesc.PrimaryIndex
+		uniqueIdx := sqlbase.IndexDescriptor{
+			Name: makeTemporaryIndexName(tableDesc, fmt.Sprintf("%s_%s_key",
+				tableDesc.Name, strings.Join(oldPrimaryIndex.ColumnNames, "_"))),
+			Unique:           true,
+			ColumnNames:      append([]string(nil), oldPrimaryIndex.ColumnNames...),
+			ColumnIDs:        append([]sqlbase.ColumnID(nil), oldPrimaryIndex.ColumnIDs...),
+			ColumnDirections: append([]sqlbase.IndexDescriptor_Direction(nil), oldPrimaryIndex.ColumnDirections...),
+		}
+		for _, id := range newPrimaryIndex.ColumnIDs {
+			if !columnIDsContain(uniqueIdx.ColumnIDs, id) {
+				uniqueIdx.ExtraColumnIDs = append(uniqueIdx.ExtraColumnIDs, id)
+			}
+		}
+		allocateIndexID(&uniqueIdx)
+		setCompositeColumnIDs(&uniqueIdx)
+		if err := tableDesc.AddIndexMutation(&uniqueIdx, sqlbase.DescriptorMutation_ADD); err != nil {
+			return err
+		}
+	}
+
+	tableDesc.AddPrimaryKeySwapMutation(swap)
+	return nil
+}
+
+// needsOldPrimaryKeyIndex returns whether the uniqueness of the columns of the
+// current primary key has to be enforced by a new unique index once the
+// primary key is changed to the columns in newPrimaryKey. That's not the case
+// when the current primary key is the implicit rowid column, when the new
+// primary key only contains columns of the current one, or when an existing
+// unique index already enforces it.
+func needsOldPrimaryKeyIndex(
+	tableDesc *sqlbase.MutableTableDescriptor, newPrimaryKey map[sqlbase.ColumnID]struct{},
+) bool {
+	oldPrimaryIndex := &tableDesc.PrimaryIndex
+	if len(oldPrimaryIndex.ColumnIDs) == 1 {
+		col, err := tableDesc.FindColumnByID(oldPrimaryIndex.ColumnIDs[0])
+		if err == nil && col.Hidden {
+			return false
+		}
+	}
+	newKeyInOldKey := true
+	for id := range newPrimaryKey {
+		if !columnIDsContain(oldPrimaryIndex.ColumnIDs, id) {
+			newKeyInOldKey = false
+			break
+		}
+	}
+	if newKeyInOldKey {
+		return false
+	}
+	for i := range tableDesc.Indexes {
+		idx := &tableDesc.Indexes[i]
+		if !idx.Unique || idx.IsPartial() || len(idx.ColumnIDs) > len(oldPrimaryIndex.ColumnIDs) {
+			continue
+		}
+		covered := true
+		for _, id := range idx.ColumnIDs {
+			if !columnIDsContain(oldPrimaryIndex.ColumnIDs, id) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return false
+		}
+	}
+	return true
+}
+
+// makeTemporaryIndexName returns a name based on baseName that isn't used by
+// any index of the table, including the indexes being added.
+func makeTemporaryIndexName(tableDesc *sqlbase.MutableTableDescriptor, baseName string) string {
+	name := baseName
+	for i := 1; ; i++ {
+		if _, _, err := tableDesc.FindIndexByName(name); err != nil {
+			return name
+		}
+		name = fmt.Sprintf("%s%d", baseName, i)
+	}
+}
+
+func columnIDsContain(ids []sqlbase.ColumnID, id sqlbase.ColumnID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNoPrimaryKeySwap returns an error if the primary key of the table is
+// being changed, in which case the indexes of the table can't be altered.
+func checkNoPrimaryKeySwap(tableDesc *sqlbase.MutableTableDescriptor) error {
+	if tableDesc.PrimaryKeySwapMutation() != nil {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"table %q is undergoing a primary key change, try again later", tableDesc.Name)
+	}
+	return nil
+}
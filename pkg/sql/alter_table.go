@@ -99,6 +99,10 @@ func (n *alterTableNode) startExec(params runParams) error {
 	var droppedViews []string
 	tn := params.p.ResolvedName(n.n.Table)
 
+	if err := checkNoPrimaryKeySwap(n.tableDesc); err != nil {
+		return err
+	}
+
 	for i, cmd := range n.n.Cmds {
 		switch t := cmd.(type) {
 		case *tree.AlterTableAddColumn:
@@ -203,6 +207,14 @@ func (n *alterTableNode) startExec(params runParams) error {
 				if err := idx.FillColumns(d.Columns); err != nil {
 					return err
 				}
+				if d.Predicate != nil {
+					if idx.Predicate, err = validateIndexPredicate(
+						params.ctx, params.p.ExecCfg().Settings, n.tableDesc, d.Predicate, tn,
+						&params.p.semaCtx,
+					); err != nil {
+						return err
+					}
+				}
 				if d.PartitionBy != nil {
 					partitioning, err := CreatePartitioning(
 						params.ctx, params.p.ExecCfg().Settings,
@@ -411,6 +423,20 @@ func (n *alterTableNode) startExec(params runParams) error {
 						containsThisColumn = true
 					}
 				}
+				// The predicate of a partial index can't outlive the columns it
+				// references, so the index is only dropped on its own if it has no
+				// other columns.
+				predCols, err := idx.PredicateColumnIDs(n.tableDesc.TableDesc())
+				if err != nil {
+					return err
+				}
+				for _, id := range predCols {
+					if id == col.ID {
+						containsThisColumn = true
+					} else if !n.tableDesc.PrimaryIndex.ContainsColumnID(id) {
+						containsOnlyThisColumn = false
+					}
+				}
 
 				// Perform the DROP.
 				if containsThisColumn {
@@ -478,6 +504,20 @@ func (n *alterTableNode) startExec(params runParams) error {
 				return err
 			}
 
+		case *tree.AlterTableAlterPrimaryKey:
+			if !params.p.ExecCfg().Settings.Version.IsActive(cluster.VersionAlterPrimaryKey) {
+				return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					"ALTER PRIMARY KEY requires all nodes to be upgraded to %s",
+					cluster.VersionByKey(cluster.VersionAlterPrimaryKey))
+			}
+			if len(n.n.Cmds) > 1 {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"ALTER PRIMARY KEY cannot be combined with other ALTER TABLE commands")
+			}
+			if err := alterPrimaryKey(n.tableDesc, t); err != nil {
+				return err
+			}
+
 		case *tree.AlterTableDropConstraint:
 			info, err := n.tableDesc.GetConstraintInfo(params.ctx, nil)
 			if err != nil {
@@ -0,0 +1,282 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/enum"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+type alterTypeNode struct {
+	n    *tree.AlterType
+	tn   tree.TableName
+	desc *sqlbase.TypeDescriptor
+}
+
+// AlterType applies a schema change on a user-defined type.
+// Privileges: CREATE on type.
+func (p *planner) AlterType(ctx context.Context, n *tree.AlterType) (planNode, error) {
+	if err := p.checkEnumsVersion(); err != nil {
+		return nil, err
+	}
+
+	tn := n.Type.ToTableName()
+	desc, err := p.resolveTypeDesc(ctx, &tn, true /* required */)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, desc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &alterTypeNode{n: n, tn: tn, desc: desc}, nil
+}
+
+func (n *alterTypeNode) startExec(params runParams) error {
+	switch t := n.n.Cmd.(type) {
+	case *tree.AlterTypeAddValue:
+		added, err := params.p.addEnumValue(params.ctx, n.desc, t)
+		if err != nil {
+			return err
+		}
+		if !added {
+			return nil
+		}
+	default:
+		return errors.AssertionFailedf("unsupported alter command: %T", t)
+	}
+
+	// Record this type alteration in the event log. This is an auditable log
+	// event and is recorded in the same transaction as the type descriptor
+	// update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogAlterType,
+		int32(n.desc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		struct {
+			TypeName  string
+			Statement string
+			User      string
+		}{n.tn.FQString(), n.n.String(), params.SessionData().User},
+	)
+}
+
+func (*alterTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterTypeNode) Values() tree.Datums          { return tree.Datums{} }
+func (*alterTypeNode) Close(context.Context)        {}
+
+// addEnumValue adds a member to an ENUM type. It returns false if the member
+// already exists and IF NOT EXISTS was specified.
+//
+// The existing data does not need to be rewritten, because the physical
+// representation of the new member is generated in between the ones of its
+// neighbors. However, nodes which still use a version of a table that
+// predates the new member are not able to decode it. The member is thus
+// added as READ_ONLY to the type and to the columns of the tables that use
+// the type, and a schema change is queued on each of these tables. Once all
+// the nodes use the new version of a table, the schema changer makes the
+// member writable in it, and once that is the case in all the tables, in the
+// type itself; see SchemaChanger.maybePromoteEnumMembers.
+func (p *planner) addEnumValue(
+	ctx context.Context, desc *sqlbase.TypeDescriptor, cmd *tree.AlterTypeAddValue,
+) (bool, error) {
+	if desc.FindEnumMember(cmd.NewVal) != -1 {
+		if cmd.IfNotExists {
+			return false, nil
+		}
+		return false, pgerror.Newf(pgcode.DuplicateObject,
+			"enum label %q already exists", cmd.NewVal)
+	}
+	if err := validateEnumLabel(cmd.NewVal); err != nil {
+		return false, err
+	}
+
+	// Find the position of the new member.
+	pos := len(desc.EnumMembers)
+	if cmd.Placement != nil {
+		existing := desc.FindEnumMember(cmd.Placement.ExistingVal)
+		if existing == -1 {
+			return false, pgerror.Newf(pgcode.InvalidParameterValue,
+				"%q is not an existing enum label", cmd.Placement.ExistingVal)
+		}
+		pos = existing
+		if !cmd.Placement.Before {
+			pos++
+		}
+	}
+	var prev, next []byte
+	if pos > 0 {
+		prev = desc.EnumMembers[pos-1].PhysicalRepresentation
+	}
+	if pos < len(desc.EnumMembers) {
+		next = desc.EnumMembers[pos].PhysicalRepresentation
+	}
+
+	tables, err := p.getTablesUsingType(ctx, desc.ID)
+	if err != nil {
+		return false, err
+	}
+
+	member := sqlbase.TypeDescriptor_EnumMember{
+		PhysicalRepresentation: enum.GenByteStringBetween(prev, next),
+		LogicalRepresentation:  cmd.NewVal,
+	}
+	if len(tables) > 0 {
+		member.Capability = sqlbase.TypeDescriptor_EnumMember_READ_ONLY
+	}
+	desc.EnumMembers = append(desc.EnumMembers, sqlbase.TypeDescriptor_EnumMember{})
+	copy(desc.EnumMembers[pos+1:], desc.EnumMembers[pos:])
+	desc.EnumMembers[pos] = member
+	desc.Version++
+	if err := p.writeTypeDesc(ctx, desc); err != nil {
+		return false, err
+	}
+
+	newTyp := desc.MakeTypesT()
+	for _, table := range tables {
+		table.ForeachColumnOfType(desc.ID, func(col *sqlbase.ColumnDescriptor) {
+			col.Type = *mergeEnumMemberCapabilities(newTyp, &col.Type)
+		})
+		if err := p.writeSchemaChange(ctx, table, sqlbase.InvalidMutationID); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// mergeEnumMemberCapabilities returns a copy of the ENUM type newTyp in which
+// the members that are writable in the previous version oldTyp of the type of
+// a column remain writable.
+func mergeEnumMemberCapabilities(newTyp, oldTyp *types.T) *types.T {
+	writable := make(map[string]struct{})
+	for i, label := range oldTyp.EnumLogicalRepresentations() {
+		if !oldTyp.EnumMemberIsReadOnly(i) {
+			writable[label] = struct{}{}
+		}
+	}
+	meta := *newTyp.InternalType.EnumData
+	meta.ReadOnly = make([]bool, len(meta.LogicalRepresentations))
+	for i, label := range meta.LogicalRepresentations {
+		_, ok := writable[label]
+		meta.ReadOnly[i] = newTyp.EnumMemberIsReadOnly(i) && !ok
+	}
+	return types.MakeEnum(types.UserDefinedTypeOIDToID(newTyp.Oid()), meta)
+}
+
+// getTablesUsingType returns the mutable descriptors of the tables that are
+// not being dropped and have a column of the given type.
+func (p *planner) getTablesUsingType(
+	ctx context.Context, typeID sqlbase.ID,
+) ([]*sqlbase.MutableTableDescriptor, error) {
+	descs, err := GetAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return nil, err
+	}
+	var tables []*sqlbase.MutableTableDescriptor
+	for _, desc := range descs {
+		table, ok := desc.(*sqlbase.TableDescriptor)
+		if !ok || table.Dropped() || !table.UsesType(typeID) {
+			continue
+		}
+		mutDesc, err := p.Tables().getMutableTableVersionByID(ctx, table.ID, p.txn)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, mutDesc)
+	}
+	return tables, nil
+}
+
+// writeTypeDesc writes a type descriptor in the current transaction.
+func (p *planner) writeTypeDesc(ctx context.Context, desc *sqlbase.TypeDescriptor) error {
+	if err := desc.Validate(); err != nil {
+		return errors.AssertionFailedf("type descriptor is not valid: %s\n%v", err, desc)
+	}
+	b := p.txn.NewBatch()
+	if err := writeDescToBatch(
+		ctx, p.extendedEvalCtx.Tracing.KVTracingEnabled(), p.execCfg.Settings, b, desc.ID, desc,
+	); err != nil {
+		return err
+	}
+	return p.txn.Run(ctx, b)
+}
+
+// promoteEnumMembers makes the READ_ONLY members of the ENUM type with the
+// given ID writable, provided they are writable in the columns of all the
+// tables that use the type.
+func promoteEnumMembers(ctx context.Context, txn *client.Txn, typeID sqlbase.ID) error {
+	desc, err := sqlbase.GetTypeDescFromID(ctx, txn, typeID)
+	if err == sqlbase.ErrDescriptorNotFound {
+		// The type was dropped in the meantime.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !desc.HasReadOnlyEnumMembers() {
+		return nil
+	}
+
+	descs, err := GetAllDescriptors(ctx, txn)
+	if err != nil {
+		return err
+	}
+	// Collect the labels which are not writable in some column yet.
+	pending := make(map[string]struct{})
+	for _, d := range descs {
+		table, ok := d.(*sqlbase.TableDescriptor)
+		if !ok || table.Dropped() {
+			continue
+		}
+		table.ForeachColumnOfType(typeID, func(col *sqlbase.ColumnDescriptor) {
+			writable := make(map[string]struct{})
+			for i, label := range col.Type.EnumLogicalRepresentations() {
+				if !col.Type.EnumMemberIsReadOnly(i) {
+					writable[label] = struct{}{}
+				}
+			}
+			for i := range desc.EnumMembers {
+				label := desc.EnumMembers[i].LogicalRepresentation
+				if _, ok := writable[label]; !ok {
+					pending[label] = struct{}{}
+				}
+			}
+		})
+	}
+
+	changed := false
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		if _, ok := pending[member.LogicalRepresentation]; ok {
+			continue
+		}
+		if member.Capability == sqlbase.TypeDescriptor_EnumMember_READ_ONLY {
+			member.Capability = sqlbase.TypeDescriptor_EnumMember_ALL
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	desc.Version++
+	return txn.Put(ctx, sqlbase.MakeDescMetadataKey(desc.ID), sqlbase.WrapDescriptor(desc))
+}
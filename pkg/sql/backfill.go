@@ -173,6 +173,9 @@ func (sc *SchemaChanger) runBackfill(
 					constraintsToAddBeforeValidation = append(constraintsToAddBeforeValidation, *t.Constraint)
 					constraintsToValidate = append(constraintsToValidate, *t.Constraint)
 				}
+			case *sqlbase.DescriptorMutation_PrimaryKeySwap:
+				// The swap happens once the new indexes have been backfilled,
+				// when the mutations are made complete.
 			default:
 				return errors.AssertionFailedf(
 					"unsupported mutation: %+v", m)
@@ -188,6 +191,8 @@ func (sc *SchemaChanger) runBackfill(
 				}
 			case *sqlbase.DescriptorMutation_Constraint:
 				constraintsToDrop = append(constraintsToDrop, *t.Constraint)
+			case *sqlbase.DescriptorMutation_PrimaryKeySwap:
+				// A reversed swap has nothing to undo.
 			default:
 				return errors.AssertionFailedf(
 					"unsupported mutation: %+v", m)
@@ -1168,9 +1173,15 @@ func (sc *SchemaChanger) validateForwardIndexes(
 				ie.impl.tcModifier = nil
 			}()
 
+			// A partial index only contains the rows which satisfy its predicate,
+			// so it is compared with the number of such rows in the table.
+			var predClause string
+			if idx.IsPartial() {
+				predClause = " WHERE " + idx.Predicate
+			}
 			row, err := newEvalCtx.InternalExecutor.QueryRow(ctx, "verify-idx-count", txn,
-				fmt.Sprintf(`SELECT count(1) FROM [%d AS t]@[%d] AS OF SYSTEM TIME %s`,
-					tableDesc.ID, idx.ID, readAsOf.AsOfSystemTime()))
+				fmt.Sprintf(`SELECT count(1) FROM [%d AS t]@[%d] AS OF SYSTEM TIME %s%s`,
+					tableDesc.ID, idx.ID, readAsOf.AsOfSystemTime(), predClause))
 			if err != nil {
 				return err
 			}
@@ -1179,6 +1190,23 @@ func (sc *SchemaChanger) validateForwardIndexes(
 			log.Infof(ctx, "validation: index %s/%s row count = %d, took %s",
 				tableDesc.Name, idx.Name, idxLen, timeutil.Since(start))
 
+			if idx.IsPartial() {
+				row, err := newEvalCtx.InternalExecutor.QueryRow(ctx, "verify-partial-idx-count", txn,
+					fmt.Sprintf(`SELECT count(1) FROM [%d AS t] AS OF SYSTEM TIME %s%s`,
+						tableDesc.ID, readAsOf.AsOfSystemTime(), predClause))
+				if err != nil {
+					return err
+				}
+				if expected := int64(tree.MustBeDInt(row[0])); idxLen != expected {
+					return pgerror.Newf(
+						pgcode.UniqueViolation,
+						"%d entries, expected %d violates unique constraint %q",
+						idxLen, expected, idx.Name,
+					)
+				}
+				return nil
+			}
+
 			select {
 			case <-tableCountReady:
 				if idxLen != tableRowCount {
@@ -121,7 +121,8 @@ func (cb *ColumnBackfiller) Init(
 		ValNeededForCol: valNeededForCol,
 	}
 	return cb.fetcher.Init(
-		false /* reverse */, false /* returnRangeInfo */, false /* isCheck */, &cb.alloc, tableArgs,
+		false /* reverse */, false /* returnRangeInfo */, false, /* isCheck */
+		roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, &cb.alloc, tableArgs,
 	)
 }
 
@@ -292,6 +293,10 @@ type IndexBackfiller struct {
 
 	types   []types.T
 	rowVals tree.Datums
+
+	// partialIndexPreds holds the predicates of the partial indexes among
+	// added, or nil if there are none.
+	partialIndexPreds *sqlbase.PartialIndexPredicates
 }
 
 // ContainsInvertedIndex returns true if backfilling an inverted index.
@@ -329,15 +334,33 @@ func (ib *IndexBackfiller) Init(desc *sqlbase.ImmutableTableDescriptor) error {
 		if IndexMutationFilter(m) {
 			idx := m.GetIndex()
 			ib.added = append(ib.added, *idx)
+			predCols, err := idx.PredicateColumnIDs(desc.TableDesc())
+			if err != nil {
+				return err
+			}
 			for i := range cols {
 				id := cols[i].ID
 				if idx.ContainsColumnID(id) {
 					valNeededForCol.Add(i)
 				}
 			}
+			for _, id := range predCols {
+				for i := range cols {
+					if cols[i].ID == id {
+						valNeededForCol.Add(i)
+					}
+				}
+			}
 		}
 	}
 
+	var err error
+	if ib.partialIndexPreds, err = sqlbase.NewPartialIndexPredicates(
+		desc.TableDesc(), ib.added,
+	); err != nil {
+		return err
+	}
+
 	ib.types = make([]types.T, len(cols))
 	for i := range cols {
 		ib.types[i] = cols[i].Type
@@ -356,7 +379,8 @@ func (ib *IndexBackfiller) Init(desc *sqlbase.ImmutableTableDescriptor) error {
 		ValNeededForCol: valNeededForCol,
 	}
 	return ib.fetcher.Init(
-		false /* reverse */, false /* returnRangeInfo */, false /* isCheck */, &ib.alloc, tableArgs,
+		false /* reverse */, false /* returnRangeInfo */, false, /* isCheck */
+		roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, &ib.alloc, tableArgs,
 	)
 }
 
@@ -413,10 +437,15 @@ func (ib *IndexBackfiller) BuildIndexEntriesChunk(
 		buffer = buffer[:len(ib.added)]
 		if buffer, err = sqlbase.EncodeSecondaryIndexes(
 			tableDesc.TableDesc(), ib.added, ib.colIdxMap,
-			ib.rowVals, buffer); err != nil {
+			ib.rowVals, buffer, ib.partialIndexPreds); err != nil {
 			return nil, nil, err
 		}
-		entries = append(entries, buffer...)
+		for j := range buffer {
+			// Skip the partial indexes whose predicate the row doesn't satisfy.
+			if buffer[j].Key != nil {
+				entries = append(entries, buffer[j])
+			}
+		}
 	}
 	return entries, ib.fetcher.Key(), nil
 }
@@ -11,12 +11,15 @@
 package colencoding
 
 import (
+	"time"
+
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
@@ -211,7 +214,7 @@ func decodeTableKeyToCol(
 			rkey, d, err = encoding.DecodeDecimalDescending(key, nil)
 		}
 		vec.Decimal()[idx] = d
-	case types.BytesFamily, types.StringFamily:
+	case types.BytesFamily, types.StringFamily, types.UuidFamily:
 		var r []byte
 		if dir == sqlbase.IndexDescriptor_ASC {
 			rkey, r, err = encoding.DecodeBytesAscending(key, nil)
@@ -227,6 +230,22 @@ func decodeTableKeyToCol(
 			rkey, t, err = encoding.DecodeVarintDescending(key)
 		}
 		vec.Int64()[idx] = t
+	case types.TimestampFamily, types.TimestampTZFamily:
+		var t time.Time
+		if dir == sqlbase.IndexDescriptor_ASC {
+			rkey, t, err = encoding.DecodeTimeAscending(key)
+		} else {
+			rkey, t, err = encoding.DecodeTimeDescending(key)
+		}
+		vec.Timestamp()[idx] = t
+	case types.IntervalFamily:
+		var d duration.Duration
+		if dir == sqlbase.IndexDescriptor_ASC {
+			rkey, d, err = encoding.DecodeDurationAscending(key)
+		} else {
+			rkey, d, err = encoding.DecodeDurationDescending(key)
+		}
+		vec.Interval()[idx] = d
 	default:
 		return rkey, errors.AssertionFailedf("unsupported type %+v", log.Safe(valType))
 	}
@@ -262,7 +281,7 @@ func skipTableKey(
 		} else {
 			rkey, _, err = encoding.DecodeFloatDescending(key)
 		}
-	case types.BytesFamily, types.StringFamily:
+	case types.BytesFamily, types.StringFamily, types.UuidFamily:
 		if dir == sqlbase.IndexDescriptor_ASC {
 			rkey, _, err = encoding.DecodeBytesAscending(key, nil)
 		} else {
@@ -274,6 +293,18 @@ func skipTableKey(
 		} else {
 			rkey, _, err = encoding.DecodeDecimalDescending(key, nil)
 		}
+	case types.TimestampFamily, types.TimestampTZFamily:
+		if dir == sqlbase.IndexDescriptor_ASC {
+			rkey, _, err = encoding.DecodeTimeAscending(key)
+		} else {
+			rkey, _, err = encoding.DecodeTimeDescending(key)
+		}
+	case types.IntervalFamily:
+		if dir == sqlbase.IndexDescriptor_ASC {
+			rkey, _, err = encoding.DecodeDurationAscending(key)
+		} else {
+			rkey, _, err = encoding.DecodeDurationDescending(key)
+		}
 	default:
 		return key, errors.AssertionFailedf("unsupported type %+v", log.Safe(valType))
 	}
@@ -322,7 +353,7 @@ func UnmarshalColumnValueToCol(
 		vec.Float64()[idx] = v
 	case types.DecimalFamily:
 		err = value.GetDecimalInto(&vec.Decimal()[idx])
-	case types.BytesFamily, types.StringFamily:
+	case types.BytesFamily, types.StringFamily, types.UuidFamily:
 		var v []byte
 		v, err = value.GetBytes()
 		vec.Bytes().Set(int(idx), v)
@@ -330,6 +361,14 @@ func UnmarshalColumnValueToCol(
 		var v int64
 		v, err = value.GetInt()
 		vec.Int64()[idx] = v
+	case types.TimestampFamily, types.TimestampTZFamily:
+		var v time.Time
+		v, err = value.GetTime()
+		vec.Timestamp()[idx] = v
+	case types.IntervalFamily:
+		var v duration.Duration
+		v, err = value.GetDuration()
+		vec.Interval()[idx] = v
 	default:
 		return errors.AssertionFailedf("unsupported column type: %s", log.Safe(typ.Family()))
 	}
@@ -11,10 +11,14 @@
 package colencoding
 
 import (
+	"time"
+
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 )
 
@@ -63,6 +67,18 @@ func decodeUntaggedDatumToCol(vec coldata.Vec, idx uint16, t *types.T, buf []byt
 		var i int64
 		buf, i, err = encoding.DecodeUntaggedIntValue(buf)
 		vec.Int64()[idx] = i
+	case types.TimestampFamily, types.TimestampTZFamily:
+		var t time.Time
+		buf, t, err = encoding.DecodeUntaggedTimeValue(buf)
+		vec.Timestamp()[idx] = t
+	case types.IntervalFamily:
+		var d duration.Duration
+		buf, d, err = encoding.DecodeUntaggedDurationValue(buf)
+		vec.Interval()[idx] = d
+	case types.UuidFamily:
+		var u uuid.UUID
+		buf, u, err = encoding.DecodeUntaggedUUIDValue(buf)
+		vec.Bytes().Set(int(idx), u.GetBytes())
 	case types.DecimalFamily:
 		buf, err = encoding.DecodeIntoUntaggedDecimalValue(&vec.Decimal()[idx], buf)
 	case types.FloatFamily:
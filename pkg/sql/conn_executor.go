@@ -329,6 +329,7 @@ func (s *Server) Start(ctx context.Context, stopper *stop.Stopper) {
 		}
 	})
 	s.PeriodicallyClearStmtStats(ctx, stopper)
+	s.PeriodicallyCleanupTemporaryObjects(ctx, stopper)
 }
 
 // ResetStatementStats resets the executor's collected statement statistics.
@@ -755,6 +756,18 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		ex.extraTxnState.prepStmtsNamespaceAtTxnRewindPos.resetTo(ctx, prepStmtNamespace{})
 	}
 
+	if closeType == normalClose && ex.dataMutator != nil {
+		// Drop the temporary objects of the session, if any. If this fails (or
+		// the node dies before getting here), the temporary object cleaner will
+		// eventually get to them.
+		if scName := ex.sessionData.SearchPath.GetTemporarySchemaName(); scName != "" {
+			cleanupCtx := ex.server.cfg.AmbientCtx.AnnotateCtx(context.Background())
+			if err := cleanupTemporarySchema(cleanupCtx, ex.server.cfg, scName); err != nil {
+				log.Warningf(ctx, "error while cleaning up temporary schema %s: %s", scName, err)
+			}
+		}
+	}
+
 	if ex.sessionTracing.Enabled() {
 		if err := ex.sessionTracing.StopTracing(); err != nil {
 			log.Warningf(ctx, "error stopping tracing: %s", err)
@@ -1127,6 +1140,11 @@ func (ex *connExecutor) run(
 	ex.onCancelSession = onCancel
 
 	ex.sessionID = ex.generateID()
+	if ex.dataMutator != nil {
+		// Session-bound internal executors share the session data of their
+		// parent and can't create temporary objects of their own.
+		ex.sessionData.TemporarySchemaName = temporarySchemaName(ex.sessionID)
+	}
 	ex.server.cfg.SessionRegistry.register(ex.sessionID, ex)
 	defer ex.server.cfg.SessionRegistry.deregister(ex.sessionID)
 
@@ -1896,6 +1914,7 @@ func (ex *connExecutor) resetPlanner(
 	p.semaCtx = tree.MakeSemaContext()
 	p.semaCtx.Location = &ex.sessionData.DataConversion.Location
 	p.semaCtx.SearchPath = ex.sessionData.SearchPath
+	p.semaCtx.TypeResolver = p
 	p.semaCtx.AsOfTimestamp = nil
 	p.semaCtx.Annotations = tree.MakeAnnotations(numAnnotations)
 
@@ -342,6 +342,9 @@ CREATE TABLE crdb_internal.schema_changes (
 				case *sqlbase.DescriptorMutation_Constraint:
 					mutType = "CONSTRAINT VALIDATION"
 					targetName = tree.NewDString(d.Constraint.Name)
+				case *sqlbase.DescriptorMutation_PrimaryKeySwap:
+					mutType = "PRIMARY KEY SWAP"
+					targetID = tree.NewDInt(tree.DInt(int64(d.PrimaryKeySwap.NewPrimaryIndexID)))
 				}
 				if err := addRow(
 					tableID,
@@ -1337,6 +1340,10 @@ func showCreateIndexWithInterleave(
 		comma = ", "
 	}
 	f.WriteString(")")
+	if idx.IsPartial() {
+		f.WriteString(" WHERE ")
+		f.WriteString(idx.Predicate)
+	}
 }
 
 // crdbInternalTableColumnsTable exposes the column descriptors.
@@ -1813,6 +1820,7 @@ CREATE VIEW crdb_internal.ranges AS SELECT
 	index_name,
 	replicas,
 	learner_replicas,
+	non_voting_replicas,
 	split_enforced_until,
 	crdb_internal.lease_holder(start_key) AS lease_holder
 FROM crdb_internal.ranges_no_leases
@@ -1828,6 +1836,7 @@ FROM crdb_internal.ranges_no_leases
 		{Name: "index_name", Typ: types.String},
 		{Name: "replicas", Typ: types.Int2Vector},
 		{Name: "learner_replicas", Typ: types.Int2Vector},
+		{Name: "non_voting_replicas", Typ: types.Int2Vector},
 		{Name: "split_enforced_until", Typ: types.Timestamp},
 		{Name: "lease_holder", Typ: types.Int},
 	},
@@ -1851,6 +1860,7 @@ CREATE TABLE crdb_internal.ranges_no_leases (
   index_name           STRING NOT NULL,
 	replicas             INT[] NOT NULL,
 	learner_replicas     INT[] NOT NULL,
+	non_voting_replicas  INT[] NOT NULL,
   split_enforced_until TIMESTAMP
 )
 `,
@@ -1904,15 +1914,19 @@ CREATE TABLE crdb_internal.ranges_no_leases (
 				return nil, err
 			}
 
-			var voterReplicas, learnerReplicas []int
+			var voterReplicas, learnerReplicas, nonVoterReplicas []int
 			for _, rd := range desc.Replicas().Voters() {
 				voterReplicas = append(voterReplicas, int(rd.StoreID))
 			}
 			for _, rd := range desc.Replicas().Learners() {
 				learnerReplicas = append(learnerReplicas, int(rd.StoreID))
 			}
+			for _, rd := range desc.Replicas().NonVoters() {
+				nonVoterReplicas = append(nonVoterReplicas, int(rd.StoreID))
+			}
 			sort.Ints(voterReplicas)
 			sort.Ints(learnerReplicas)
+			sort.Ints(nonVoterReplicas)
 			votersArr := tree.NewDArray(types.Int)
 			for _, replica := range voterReplicas {
 				if err := votersArr.Append(tree.NewDInt(tree.DInt(replica))); err != nil {
@@ -1925,6 +1939,12 @@ CREATE TABLE crdb_internal.ranges_no_leases (
 					return nil, err
 				}
 			}
+			nonVotersArr := tree.NewDArray(types.Int)
+			for _, replica := range nonVoterReplicas {
+				if err := nonVotersArr.Append(tree.NewDInt(tree.DInt(replica))); err != nil {
+					return nil, err
+				}
+			}
 
 			var dbName, tableName, indexName string
 			if _, id, err := keys.DecodeTablePrefix(desc.StartKey.AsRawKey()); err == nil {
@@ -1956,6 +1976,7 @@ CREATE TABLE crdb_internal.ranges_no_leases (
 				tree.NewDString(indexName),
 				votersArr,
 				learnersArr,
+				nonVotersArr,
 				splitEnforcedUntil,
 			}, nil
 		}, nil
@@ -13,11 +13,13 @@ package sql
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 )
 
 type createIndexNode struct {
@@ -68,6 +70,10 @@ func MakeIndexDescriptor(n *tree.CreateIndex) (*sqlbase.IndexDescriptor, error)
 		if n.Unique {
 			return nil, pgerror.New(pgcode.InvalidSQLStatementName, "inverted indexes can't be unique")
 		}
+
+		if n.Predicate != nil {
+			return nil, pgerror.New(pgcode.InvalidSQLStatementName, "inverted indexes can't be partial")
+		}
 		indexDesc.Type = sqlbase.IndexDescriptor_INVERTED
 	}
 
@@ -78,6 +84,10 @@ func MakeIndexDescriptor(n *tree.CreateIndex) (*sqlbase.IndexDescriptor, error)
 }
 
 func (n *createIndexNode) startExec(params runParams) error {
+	if err := checkNoPrimaryKeySwap(n.tableDesc); err != nil {
+		return err
+	}
+
 	_, dropped, err := n.tableDesc.FindIndexByName(string(n.n.Name))
 	if err == nil {
 		if dropped {
@@ -101,6 +111,15 @@ func (n *createIndexNode) startExec(params runParams) error {
 		return err
 	}
 
+	if n.n.Predicate != nil {
+		if indexDesc.Predicate, err = validateIndexPredicate(
+			params.ctx, params.p.ExecCfg().Settings, n.tableDesc, n.n.Predicate, &n.n.Table,
+			&params.p.semaCtx,
+		); err != nil {
+			return err
+		}
+	}
+
 	if n.n.PartitionBy != nil {
 		partitioning, err := CreatePartitioning(params.ctx, params.p.ExecCfg().Settings,
 			params.EvalContext(), n.tableDesc, indexDesc, n.n.PartitionBy)
@@ -165,6 +184,59 @@ func (n *createIndexNode) startExec(params runParams) error {
 	)
 }
 
+// validateIndexPredicate checks that the predicate of a partial index is a
+// boolean expression over the columns of the table which doesn't contain
+// subqueries or impure functions, and returns its serialized form. Partial
+// indexes are rejected until all nodes understand the predicate.
+func validateIndexPredicate(
+	ctx context.Context,
+	st *cluster.Settings,
+	desc *sqlbase.MutableTableDescriptor,
+	expr tree.Expr,
+	tableName *tree.TableName,
+	semaCtx *tree.SemaContext,
+) (string, error) {
+	if !st.Version.IsActive(cluster.VersionPartialIndexes) {
+		return "", pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`partial indexes require all nodes to be upgraded to %s`,
+			cluster.VersionByKey(cluster.VersionPartialIndexes))
+	}
+	if _, err := tree.SimpleVisit(expr, func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		if _, ok := expr.(*tree.Subquery); ok {
+			return false, nil, pgerror.New(pgcode.FeatureNotSupported,
+				"subqueries are not allowed in index predicate")
+		}
+		return true, expr, nil
+	}); err != nil {
+		return "", err
+	}
+
+	// Replace column references with typed dummies to allow typechecking.
+	replacedExpr, _, err := replaceVars(desc, expr)
+	if err != nil {
+		return "", err
+	}
+
+	if semaCtx == nil {
+		sc := tree.MakeSemaContext()
+		semaCtx = &sc
+	}
+	if _, err := sqlbase.SanitizeVarFreeExpr(
+		replacedExpr, types.Bool, "index predicate", semaCtx, false, /* allowImpure */
+	); err != nil {
+		return "", err
+	}
+
+	sourceInfo := sqlbase.NewSourceInfoForSingleTable(
+		*tableName, sqlbase.ResultColumnsFromColDescs(desc.TableDesc().AllNonDropColumns()),
+	)
+	expr, err = dequalifyColumnRefs(ctx, sqlbase.MultiSourceInfo{sourceInfo}, expr)
+	if err != nil {
+		return "", err
+	}
+	return tree.Serialize(expr), nil
+}
+
 func (*createIndexNode) Next(runParams) (bool, error) { return false, nil }
 func (*createIndexNode) Values() tree.Datums          { return tree.Datums{} }
 func (*createIndexNode) Close(context.Context)        {}
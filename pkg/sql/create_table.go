@@ -48,7 +48,13 @@ type createTableNode struct {
 // Privileges: CREATE on database.
 //   Notes: postgres/mysql require CREATE on database.
 func (p *planner) CreateTable(ctx context.Context, n *tree.CreateTable) (planNode, error) {
-	dbDesc, err := p.ResolveUncachedDatabase(ctx, &n.Table)
+	temporary, err := n.Table.QualifyTemporaryTarget(n.Temporary, p.SessionData().TemporarySchemaName)
+	if err != nil {
+		return nil, err
+	}
+	n.Temporary = temporary
+
+	dbDesc, err := p.resolveUncachedTargetDatabase(ctx, &n.Table, n.Temporary)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +132,24 @@ type createTableRun struct {
 }
 
 func (n *createTableNode) startExec(params runParams) error {
-	tKey := sqlbase.NewTableKey(n.dbDesc.ID, n.n.Table.Table())
+	// Temporary tables are registered in system.namespace under the ID of the
+	// session's temporary schema instead of under the ID of the database.
+	var temporarySchemaID sqlbase.ID
+	parentID := n.dbDesc.ID
+	if n.n.Temporary {
+		var err error
+		temporarySchemaID, err = params.p.getOrCreateTemporarySchemaID(params.ctx, n.dbDesc.ID)
+		if err != nil {
+			return err
+		}
+		parentID = temporarySchemaID
+		if n.n.Interleave != nil {
+			return pgerror.New(pgcode.FeatureNotSupported,
+				"temporary tables cannot be interleaved")
+		}
+	}
+
+	tKey := sqlbase.NewTableKey(parentID, n.n.Table.Table())
 	key := tKey.Key()
 	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
 		if n.n.IfNotExists {
@@ -215,6 +238,11 @@ func (n *createTableNode) startExec(params runParams) error {
 		}
 	}
 
+	desc.TemporarySchemaID = temporarySchemaID
+	if err := checkTemporaryFKReferences(&desc, affected); err != nil {
+		return err
+	}
+
 	// Descriptor written to store here.
 	if err := params.p.createDescriptorWithID(
 		params.ctx, key, id, &desc, params.EvalContext().Settings); err != nil {
@@ -480,6 +508,14 @@ func ResolveFK(
 		// we edit the same copy.
 		target = tbl
 	} else {
+		// The references of new tables are checked by the caller, once the
+		// table has been placed in its schema.
+		if ts != NewTable {
+			if err := checkTemporaryFKReference(tbl.TableDesc(), target.TableDesc()); err != nil {
+				return err
+			}
+		}
+
 		// Since this FK is referencing another table, this table must be created in
 		// a non-public "ADD" state and made public only after all leases on the
 		// other table are updated to include the backref, if it does not already
@@ -1159,11 +1195,21 @@ func MakeTableDesc(
 				StoreColumnNames: d.Storing.ToStrings(),
 			}
 			if d.Inverted {
+				if d.Predicate != nil {
+					return desc, pgerror.New(pgcode.InvalidSQLStatementName, "inverted indexes can't be partial")
+				}
 				idx.Type = sqlbase.IndexDescriptor_INVERTED
 			}
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
+			if d.Predicate != nil {
+				pred, err := validateIndexPredicate(ctx, st, &desc, d.Predicate, &n.Table, semaCtx)
+				if err != nil {
+					return desc, err
+				}
+				idx.Predicate = pred
+			}
 			if d.PartitionBy != nil {
 				partitioning, err := CreatePartitioning(ctx, st, evalCtx, &desc, &idx, d.PartitionBy)
 				if err != nil {
@@ -1186,6 +1232,13 @@ func MakeTableDesc(
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
+			if d.Predicate != nil {
+				pred, err := validateIndexPredicate(ctx, st, &desc, d.Predicate, &n.Table, semaCtx)
+				if err != nil {
+					return desc, err
+				}
+				idx.Predicate = pred
+			}
 			if d.PartitionBy != nil {
 				partitioning, err := CreatePartitioning(ctx, st, evalCtx, &desc, &idx, d.PartitionBy)
 				if err != nil {
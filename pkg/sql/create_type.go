@@ -0,0 +1,153 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/enum"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/errors"
+)
+
+// maxEnumLabelLength is the maximum length in bytes of the label of an ENUM
+// member. It matches the limit imposed by PostgreSQL.
+const maxEnumLabelLength = 63
+
+type createTypeNode struct {
+	n      *tree.CreateType
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+// CreateType creates a user-defined type.
+// Privileges: CREATE on database.
+func (p *planner) CreateType(ctx context.Context, n *tree.CreateType) (planNode, error) {
+	if err := p.checkEnumsVersion(); err != nil {
+		return nil, err
+	}
+
+	dbDesc, err := p.ResolveUncachedDatabase(ctx, &n.TypeName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	if err := validateEnumLabels(n.EnumLabels); err != nil {
+		return nil, err
+	}
+
+	return &createTypeNode{n: n, dbDesc: dbDesc}, nil
+}
+
+// checkEnumsVersion returns an error unless all nodes are upgraded to a
+// version that knows about user-defined types.
+func (p *planner) checkEnumsVersion() error {
+	if !p.ExecCfg().Settings.Version.IsActive(cluster.VersionEnums) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"user-defined types require all nodes to be upgraded to %s",
+			cluster.VersionByKey(cluster.VersionEnums))
+	}
+	return nil
+}
+
+// validateEnumLabels checks that the labels of an ENUM are valid and unique.
+func validateEnumLabels(labels []string) error {
+	seen := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		if err := validateEnumLabel(label); err != nil {
+			return err
+		}
+		if _, ok := seen[label]; ok {
+			return pgerror.Newf(pgcode.DuplicateObject,
+				"enum label %q used more than once", label)
+		}
+		seen[label] = struct{}{}
+	}
+	return nil
+}
+
+func validateEnumLabel(label string) error {
+	if len(label) == 0 || len(label) > maxEnumLabelLength {
+		return errors.WithDetailf(
+			pgerror.Newf(pgcode.InvalidParameterValue, "invalid enum label %q", label),
+			"Labels must be between 1 and %d bytes long.", maxEnumLabelLength)
+	}
+	return nil
+}
+
+func (n *createTypeNode) startExec(params runParams) error {
+	tKey := sqlbase.NewTableKey(n.dbDesc.ID, n.n.TypeName.Table())
+	if exists, err := descExists(params.ctx, params.p.txn, tKey.Key()); err == nil && exists {
+		return sqlbase.NewTypeAlreadyExistsError(tKey.Name())
+	} else if err != nil {
+		return err
+	}
+
+	id, err := GenerateUniqueDescID(params.ctx, params.p.ExecCfg().DB)
+	if err != nil {
+		return err
+	}
+
+	physReps := enum.GenerateNEvenlySpacedBytes(len(n.n.EnumLabels))
+	members := make([]sqlbase.TypeDescriptor_EnumMember, len(n.n.EnumLabels))
+	for i, label := range n.n.EnumLabels {
+		members[i] = sqlbase.TypeDescriptor_EnumMember{
+			PhysicalRepresentation: physReps[i],
+			LogicalRepresentation:  label,
+		}
+	}
+
+	desc := &sqlbase.TypeDescriptor{
+		Name:        n.n.TypeName.Table(),
+		ID:          id,
+		ParentID:    n.dbDesc.ID,
+		Version:     1,
+		EnumMembers: members,
+		// Inherit permissions from the database descriptor.
+		Privileges: n.dbDesc.GetPrivileges(),
+	}
+	if err := desc.Validate(); err != nil {
+		return err
+	}
+
+	if err := params.p.createDescriptorWithID(
+		params.ctx, tKey.Key(), id, desc, params.EvalContext().Settings,
+	); err != nil {
+		return err
+	}
+
+	// Log Create Type event. This is an auditable log event and is
+	// recorded in the same transaction as the type descriptor update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogCreateType,
+		int32(desc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		struct {
+			TypeName  string
+			Statement string
+			User      string
+		}{n.n.TypeName.FQString(), n.n.String(), params.SessionData().User},
+	)
+}
+
+func (*createTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*createTypeNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createTypeNode) Close(context.Context)        {}
@@ -14,6 +14,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -25,6 +27,9 @@ import (
 // createViewNode represents a CREATE VIEW statement.
 type createViewNode struct {
 	viewName tree.Name
+	// temporary is true if the view is created in the session's temporary
+	// schema.
+	temporary bool
 	// viewQuery contains the view definition, with all table names fully
 	// qualified.
 	viewQuery string
@@ -43,7 +48,12 @@ type createViewNode struct {
 //						selected columns.
 //          mysql requires CREATE VIEW plus SELECT on all the selected columns.
 func (p *planner) CreateView(ctx context.Context, n *tree.CreateView) (planNode, error) {
-	dbDesc, err := p.ResolveUncachedDatabase(ctx, &n.Name)
+	temporary, err := n.Name.QualifyTemporaryTarget(n.Temporary, p.SessionData().TemporarySchemaName)
+	if err != nil {
+		return nil, err
+	}
+
+	dbDesc, err := p.resolveUncachedTargetDatabase(ctx, &n.Name, temporary)
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +111,7 @@ func (p *planner) CreateView(ctx context.Context, n *tree.CreateView) (planNode,
 
 	return &createViewNode{
 		viewName:  n.Name.TableName,
+		temporary: temporary,
 		viewQuery: tree.AsStringWithFlags(n.AsSource, tree.FmtParsable),
 		dbDesc:    dbDesc,
 		columns:   sourceColumns,
@@ -112,7 +123,35 @@ func (n *createViewNode) startExec(params runParams) error {
 	viewName := string(n.viewName)
 	log.VEventf(params.ctx, 2, "dependencies for view %s:\n%s", viewName, n.planDeps.String())
 
-	tKey := sqlbase.NewTableKey(n.dbDesc.ID, viewName)
+	// Like in Postgres, a view that depends on temporary tables or views is
+	// itself temporary.
+	if !n.temporary {
+		for _, dep := range n.planDeps {
+			if dep.desc.IsTemporary() {
+				if params.SessionData().TemporarySchemaName == "" {
+					return pgerror.New(pgcode.FeatureNotSupported,
+						"temporary objects cannot be created in this session")
+				}
+				n.temporary = true
+				break
+			}
+		}
+	}
+
+	// Temporary views are registered in system.namespace under the ID of the
+	// session's temporary schema instead of under the ID of the database.
+	var temporarySchemaID sqlbase.ID
+	parentID := n.dbDesc.ID
+	if n.temporary {
+		var err error
+		temporarySchemaID, err = params.p.getOrCreateTemporarySchemaID(params.ctx, n.dbDesc.ID)
+		if err != nil {
+			return err
+		}
+		parentID = temporarySchemaID
+	}
+
+	tKey := sqlbase.NewTableKey(parentID, viewName)
 	key := tKey.Key()
 	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
 		// TODO(a-robinson): Support CREATE OR REPLACE commands.
@@ -143,6 +182,8 @@ func (n *createViewNode) startExec(params runParams) error {
 		return err
 	}
 
+	desc.TemporarySchemaID = temporarySchemaID
+
 	// Collect all the tables/views this view depends on.
 	for backrefID := range n.planDeps {
 		desc.DependsOn = append(desc.DependsOn, backrefID)
@@ -66,6 +66,9 @@ func TryDelegate(
 	case *tree.ShowJobs:
 		return d.delegateShowJobs(t)
 
+	case *tree.ShowSchedules:
+		return d.delegateShowSchedules(t)
+
 	case *tree.ShowQueries:
 		return d.delegateShowQueries(t)
 
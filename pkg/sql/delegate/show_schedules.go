@@ -0,0 +1,27 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package delegate
+
+import "github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+
+func (d *delegator) delegateShowSchedules(n *tree.ShowSchedules) (tree.Statement, error) {
+	return parse(`
+SELECT schedule_id AS id,
+       schedule_name AS name,
+       IF(next_run IS NULL, 'PAUSED', 'ACTIVE') AS schedule_status,
+       next_run,
+       schedule_expr AS recurrence,
+       executor_type,
+       owner,
+       created
+  FROM system.scheduled_jobs
+ ORDER BY created`)
+}
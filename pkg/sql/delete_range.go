@@ -143,7 +143,7 @@ func (d *deleteRangeNode) startExec(params runParams) error {
 		return err
 	}
 	if err := d.fetcher.Init(
-		false, false, false, &params.p.alloc,
+		false, false, false, roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, &params.p.alloc,
 		row.FetcherTableArgs{
 			Desc:  d.desc,
 			Index: &d.desc.PrimaryIndex,
@@ -37,6 +37,11 @@ var (
 	errNoDatabase        = pgerror.New(pgcode.InvalidName, "no database specified")
 	errNoTable           = pgerror.New(pgcode.InvalidName, "no table specified")
 	errNoMatch           = pgerror.New(pgcode.UndefinedObject, "no object matched")
+
+	// errDescriptorIsType is returned when a table descriptor is requested
+	// for the ID of a user-defined type, whose name lives in the same
+	// namespace as the tables of its database.
+	errDescriptorIsType = pgerror.New(pgcode.WrongObjectType, "descriptor is a type")
 )
 
 // GenerateUniqueDescID returns the next available Descriptor ID and increments
@@ -173,6 +178,9 @@ func getDescriptorByID(
 	case *sqlbase.TableDescriptor:
 		table := desc.GetTable()
 		if table == nil {
+			if desc.GetType() != nil {
+				return errDescriptorIsType
+			}
 			return pgerror.Newf(pgcode.WrongObjectType,
 				"%q is not a table", desc.String())
 		}
@@ -223,6 +231,8 @@ func GetAllDescriptors(ctx context.Context, txn *client.Txn) ([]sqlbase.Descript
 			descs = append(descs, table)
 		case *sqlbase.Descriptor_Database:
 			descs = append(descs, desc.GetDatabase())
+		case *sqlbase.Descriptor_Type:
+			descs = append(descs, desc.GetType())
 		default:
 			return nil, errors.AssertionFailedf("Descriptor.Union has unexpected type %T", t)
 		}
@@ -248,6 +258,7 @@ func writeDescToBatch(
 	case *MutableTableDescriptor:
 		tableToDowngrade = d.TableDesc()
 	case *DatabaseDescriptor:
+	case *sqlbase.TypeDescriptor:
 	default:
 		return errors.AssertionFailedf("unexpected proto type %T", desc)
 	}
@@ -288,6 +299,7 @@ func WriteNewDescToBatch(
 	case *MutableTableDescriptor:
 		tableToDowngrade = d.TableDesc()
 	case *DatabaseDescriptor:
+	case *sqlbase.TypeDescriptor:
 	default:
 		return errors.AssertionFailedf("unexpected proto type %T", desc)
 	}
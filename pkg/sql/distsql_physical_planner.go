@@ -217,11 +217,22 @@ func (v *distSQLExprCheckVisitor) VisitPre(expr tree.Expr) (recurse bool, newExp
 	case *tree.DOid:
 		v.err = newQueryNotSupportedError("OID expressions are not supported by distsql")
 		return false, expr
+	case *tree.DEnum:
+		// The serialized form of the value refers to its type by name, which
+		// cannot be resolved on the remote nodes.
+		v.err = newQueryNotSupportedError("user-defined type values are not supported by distsql")
+		return false, expr
 	case *tree.CastExpr:
-		if t.Type.Family() == types.OidFamily {
+		switch t.Type.Family() {
+		case types.OidFamily, types.EnumFamily:
 			v.err = newQueryNotSupportedErrorf("cast to %s is not supported by distsql", t.Type)
 			return false, expr
 		}
+	case *tree.AnnotateTypeExpr:
+		if t.Type.Family() == types.EnumFamily {
+			v.err = newQueryNotSupportedErrorf("type annotation to %s is not supported by distsql", t.Type)
+			return false, expr
+		}
 	}
 	return true, expr
 }
@@ -380,6 +391,12 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return rec, nil
 
 	case *scanNode:
+		if n.lockingStrength != tree.ForNone {
+			// Scans that perform row-level locking must run on the gateway using
+			// the root transaction, so that the locks they acquire are tracked
+			// and released when the transaction finishes.
+			return cannotDistribute, nil
+		}
 		rec := canDistribute
 		if n.softLimit != 0 {
 			// We don't yet recommend distributing plans where soft limits propagate
@@ -870,10 +887,12 @@ func initTableReaderSpec(
 ) (*distsqlpb.TableReaderSpec, distsqlpb.PostProcessSpec, error) {
 	s := distsqlplan.NewTableReaderSpec()
 	*s = distsqlpb.TableReaderSpec{
-		Table:      *n.desc.TableDesc(),
-		Reverse:    n.reverse,
-		IsCheck:    n.isCheck,
-		Visibility: n.colCfg.visibility.toDistSQLScanVisibility(),
+		Table:             *n.desc.TableDesc(),
+		Reverse:           n.reverse,
+		IsCheck:           n.isCheck,
+		Visibility:        n.colCfg.visibility.toDistSQLScanVisibility(),
+		LockingStrength:   toDistSQLScanLockingStrength(n.lockingStrength),
+		LockingWaitPolicy: toDistSQLScanLockingWaitPolicy(n.lockingWaitPolicy),
 
 		// Retain the capacity of the spans slice.
 		Spans: s.Spans[:0],
@@ -3267,7 +3286,8 @@ func (dsp *DistSQLPlanner) createPlanForWindow(
 }
 
 // createPlanForExport creates a physical plan for EXPORT.
-// We add a new stage of CSVWriter processors to the input plan.
+// We add a new stage of CSVWriter processors, which write files of any of the
+// export formats, to the input plan.
 func (dsp *DistSQLPlanner) createPlanForExport(
 	planCtx *PlanningCtx, n *exportNode,
 ) (PhysicalPlan, error) {
@@ -3276,11 +3296,19 @@ func (dsp *DistSQLPlanner) createPlanForExport(
 		return PhysicalPlan{}, err
 	}
 
+	cols := planColumns(n.source)
+	colNames := make([]string, len(cols))
+	for i := range cols {
+		colNames[i] = cols[i].Name
+	}
 	core := distsqlpb.ProcessorCoreUnion{CSVWriter: &distsqlpb.CSVWriterSpec{
-		Destination: n.fileName,
-		NamePattern: exportFilePatternDefault,
-		Options:     n.csvOpts,
-		ChunkRows:   int64(n.chunkSize),
+		Destination:    n.fileName,
+		NamePattern:    exportFilePatternPart + n.filePatternExt,
+		Options:        n.csvOpts,
+		ChunkRows:      int64(n.chunkSize),
+		Format:         n.format,
+		ColumnNames:    colNames,
+		ParquetOptions: n.parquetOpts,
 	}}
 
 	resTypes := make([]types.T, len(sqlbase.ExportColumns))
@@ -37,6 +37,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/errors"
 	"github.com/cockroachdb/logtags"
 )
@@ -626,8 +627,34 @@ func DistIngest(
 
 	inputSpecs := makeImportReaderSpecs(job, tables, from, format, nodes, walltime)
 
+	// If a previous attempt of this job ingested some of the input, resume each
+	// file from the last row that was recorded as ingested. The walltime is the
+	// same as in that attempt, so the KVs of any rows that are ingested again
+	// are identical to the ones already ingested.
+	var resumeRow []uint64
+	var resumeOffset []int64
+	if prog := job.Progress().Details.(*jobspb.Progress_Import).Import; len(prog.CompletedRow) == len(from) {
+		resumeRow = prog.CompletedRow
+		if len(prog.ResumeOffset) == len(from) {
+			resumeOffset = prog.ResumeOffset
+		}
+	}
+
 	for i := range inputSpecs {
 		inputSpecs[i].IngestDirectly = true
+		for file := range inputSpecs[i].Uri {
+			if resumeRow == nil || resumeRow[file] == 0 {
+				continue
+			}
+			if inputSpecs[i].ResumeRow == nil {
+				inputSpecs[i].ResumeRow = make(map[int32]uint64)
+				inputSpecs[i].ResumeOffset = make(map[int32]int64)
+			}
+			inputSpecs[i].ResumeRow[file] = resumeRow[file]
+			if resumeOffset != nil {
+				inputSpecs[i].ResumeOffset[file] = resumeOffset[file]
+			}
+		}
 	}
 
 	var p PhysicalPlan
@@ -658,20 +685,38 @@ func DistIngest(
 		func(ctx context.Context, details jobspb.ProgressDetails) float32 {
 			prog := details.(*jobspb.Progress_Import).Import
 			prog.ReadProgress = make([]float32, len(from))
-			prog.CompletedRow = make([]uint64, len(from))
+			if resumeRow == nil {
+				prog.CompletedRow = make([]uint64, len(from))
+			}
+			if resumeOffset == nil {
+				prog.ResumeOffset = make([]int64, len(from))
+			}
 			return 0.0
 		},
 	); err != nil {
 		return roachpb.BulkOpSummary{}, err
 	}
 
-	rowProgress := make([]uint64, len(from))
+	// rowProgress holds the position up to which each file has been ingested.
+	// The row and offset of a file are updated together, under the mutex.
+	var rowProgress struct {
+		syncutil.Mutex
+		row    []uint64
+		offset []int64
+	}
+	rowProgress.row = make([]uint64, len(from))
+	rowProgress.offset = make([]int64, len(from))
+	copy(rowProgress.row, resumeRow)
+	copy(rowProgress.offset, resumeOffset)
 	fractionProgress := make([]uint32, len(from))
 	metaFn := func(_ context.Context, meta *distsqlpb.ProducerMetadata) {
 		if meta.BulkProcessorProgress != nil {
+			rowProgress.Lock()
 			for i, v := range meta.BulkProcessorProgress.CompletedRow {
-				atomic.StoreUint64(&rowProgress[i], v)
+				rowProgress.row[i] = v
+				rowProgress.offset[i] = meta.BulkProcessorProgress.ResumeOffset[i]
 			}
+			rowProgress.Unlock()
 			for i, v := range meta.BulkProcessorProgress.CompletedFraction {
 				atomic.StoreUint32(&fractionProgress[i], math.Float32bits(v))
 			}
@@ -717,9 +762,10 @@ func DistIngest(
 					func(ctx context.Context, details jobspb.ProgressDetails) float32 {
 						var overall float32
 						prog := details.(*jobspb.Progress_Import).Import
-						for i := range rowProgress {
-							prog.CompletedRow[i] = atomic.LoadUint64(&rowProgress[i])
-						}
+						rowProgress.Lock()
+						copy(prog.CompletedRow, rowProgress.row)
+						copy(prog.ResumeOffset, rowProgress.offset)
+						rowProgress.Unlock()
 						for i := range fractionProgress {
 							fileProgress := math.Float32frombits(atomic.LoadUint32(&fractionProgress[i]))
 							prog.ReadProgress[i] = fileProgress
@@ -447,7 +447,11 @@ func (s *SSTWriterSpec) summary() (string, []string) {
 
 // summary implements the diagramCellType interface.
 func (s *CSVWriterSpec) summary() (string, []string) {
-	return "CSVWriter", []string{s.Destination}
+	details := []string{s.Destination}
+	if s.Format != roachpb.IOFileFormat_Unknown && s.Format != roachpb.IOFileFormat_CSV {
+		details = append(details, fmt.Sprintf("Format: %s", s.Format))
+	}
+	return "CSVWriter", details
 }
 
 // summary implements the diagramCellType interface.
@@ -125,7 +125,7 @@ func newColBatchScan(
 	fetcher := row.CFetcher{}
 	if _, _, err := initCRowFetcher(
 		&fetcher, &spec.Table, int(spec.IndexIdx), columnIdxMap, spec.Reverse,
-		neededColumns, spec.IsCheck, spec.Visibility,
+		neededColumns, spec.IsCheck, spec.Visibility, spec.LockingStrength, spec.LockingWaitPolicy,
 	); err != nil {
 		return nil, err
 	}
@@ -154,6 +154,8 @@ func initCRowFetcher(
 	valNeededForCol util.FastIntSet,
 	isCheck bool,
 	scanVisibility distsqlpb.ScanVisibility,
+	lockStr distsqlpb.ScanLockingStrength,
+	lockWaitPolicy distsqlpb.ScanLockingWaitPolicy,
 ) (index *sqlbase.IndexDescriptor, isSecondaryIndex bool, err error) {
 	immutDesc := sqlbase.NewImmutableTableDescriptor(*desc)
 	index, isSecondaryIndex, err = immutDesc.FindIndexByIndexIdx(indexIdx)
@@ -174,7 +176,8 @@ func initCRowFetcher(
 		ValNeededForCol:  valNeededForCol,
 	}
 	if err := fetcher.Init(
-		reverseScan, true /* returnRangeInfo */, isCheck, tableArgs,
+		reverseScan, true /* returnRangeInfo */, isCheck,
+		toKeyLockingStrength(lockStr), toLockWaitPolicy(lockWaitPolicy), tableArgs,
 	); err != nil {
 		return nil, false, err
 	}
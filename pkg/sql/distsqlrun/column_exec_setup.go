@@ -20,6 +20,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/rpc/nodedialer"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/colrpc"
@@ -91,6 +92,23 @@ func wrapRowSource(
 	return newColumnarizer(ctx, flowCtx, processorID, toWrap)
 }
 
+// spillingMemoryLimit returns the amount of memory (in bytes) that a
+// disk-backed vectorized operator is allowed to use before spilling to temp
+// storage. Zero is returned if the operator must stay in memory, either
+// because temp storage is unavailable or because useTempStorage is disabled.
+func spillingMemoryLimit(flowCtx *FlowCtx, useTempStorage *settings.BoolSetting) int64 {
+	if flowCtx.Cfg.TempStorage == nil {
+		return 0
+	}
+	if limit := flowCtx.Cfg.TestingKnobs.MemoryLimitBytes; limit > 0 {
+		return limit
+	}
+	if !useTempStorage.Get(&flowCtx.Cfg.Settings.SV) {
+		return 0
+	}
+	return settingWorkMemBytes.Get(&flowCtx.Cfg.Settings.SV)
+}
+
 type newColOperatorResult struct {
 	op              exec.Operator
 	outputTypes     []coltypes.T
@@ -251,9 +269,22 @@ func newColOperator(
 			return result, err
 		}
 		if needHash {
-			result.op, err = exec.NewHashAggregator(
-				inputs[0], typs, aggFns, aggSpec.GroupCols, aggCols, isScalarAggregate(aggSpec),
-			)
+			if limit := spillingMemoryLimit(flowCtx, settingUseTempStorageAggregations); limit > 0 &&
+				len(aggSpec.GroupCols) > 0 && exec.CanSpillTypes(typs) {
+				// The hash aggregator buffers all of its input, so use the external
+				// one that spills to disk if the input doesn't fit within the memory
+				// limit.
+				result.op, err = exec.NewExternalHashAggregator(
+					inputs[0], typs, aggFns, aggSpec.GroupCols, aggCols, limit, flowCtx.Cfg.TempStorage,
+				)
+				if err == nil {
+					result.metadataSources = append(result.metadataSources, result.op.(distsqlpb.MetadataSource))
+				}
+			} else {
+				result.op, err = exec.NewHashAggregator(
+					inputs[0], typs, aggFns, aggSpec.GroupCols, aggCols, isScalarAggregate(aggSpec),
+				)
+			}
 		} else {
 			result.op, err = exec.NewOrderedAggregator(
 				inputs[0], typs, aggFns, aggSpec.GroupCols, aggCols, isScalarAggregate(aggSpec),
@@ -339,19 +370,41 @@ func newColOperator(
 			}
 		}
 
-		result.op, err = exec.NewEqHashJoinerOp(
-			inputs[0],
-			inputs[1],
-			core.HashJoiner.LeftEqColumns,
-			core.HashJoiner.RightEqColumns,
-			leftOutCols,
-			rightOutCols,
-			leftTypes,
-			rightTypes,
-			core.HashJoiner.RightEqColumnsAreKey,
-			core.HashJoiner.LeftEqColumnsAreKey || core.HashJoiner.RightEqColumnsAreKey,
-			core.HashJoiner.Type,
-		)
+		if limit := spillingMemoryLimit(flowCtx, settingUseTempStorageJoins); limit > 0 &&
+			exec.CanSpillTypes(leftTypes) && exec.CanSpillTypes(rightTypes) {
+			result.op, err = exec.NewExternalHashJoiner(
+				inputs[0],
+				inputs[1],
+				core.HashJoiner.LeftEqColumns,
+				core.HashJoiner.RightEqColumns,
+				leftOutCols,
+				rightOutCols,
+				leftTypes,
+				rightTypes,
+				core.HashJoiner.RightEqColumnsAreKey,
+				core.HashJoiner.LeftEqColumnsAreKey || core.HashJoiner.RightEqColumnsAreKey,
+				core.HashJoiner.Type,
+				limit,
+				flowCtx.Cfg.TempStorage,
+			)
+			if err == nil {
+				result.metadataSources = append(result.metadataSources, result.op.(distsqlpb.MetadataSource))
+			}
+		} else {
+			result.op, err = exec.NewEqHashJoinerOp(
+				inputs[0],
+				inputs[1],
+				core.HashJoiner.LeftEqColumns,
+				core.HashJoiner.RightEqColumns,
+				leftOutCols,
+				rightOutCols,
+				leftTypes,
+				rightTypes,
+				core.HashJoiner.RightEqColumnsAreKey,
+				core.HashJoiner.LeftEqColumnsAreKey || core.HashJoiner.RightEqColumnsAreKey,
+				core.HashJoiner.Type,
+			)
+		}
 		if err != nil {
 			return result, err
 		}
@@ -509,6 +562,17 @@ func newColOperator(
 			// which uses a heap to avoid storing more rows than necessary.
 			k := uint16(post.Limit + post.Offset)
 			result.op, result.isStreaming = exec.NewTopKSorter(input, inputTypes, orderingCols, k), true
+		} else if limit := spillingMemoryLimit(flowCtx, settingUseTempStorageSorts); limit > 0 &&
+			exec.CanSpillTypes(inputTypes) {
+			// No optimizations possible, but we're allowed to use temp storage,
+			// so choose the external sorter that spills to disk if the input
+			// doesn't fit within the memory limit.
+			result.op, err = exec.NewExternalSorter(
+				input, inputTypes, orderingCols, limit, flowCtx.Cfg.TempStorage,
+			)
+			if err == nil {
+				result.metadataSources = append(result.metadataSources, result.op.(distsqlpb.MetadataSource))
+			}
 		} else {
 			// No optimizations possible. Default to the standard sort operator.
 			result.op, err = exec.NewSorter(input, inputTypes, orderingCols)
@@ -523,14 +587,20 @@ func newColOperator(
 			return result, errors.Newf("only a single window function is currently supported")
 		}
 		wf := core.Windower.WindowFns[0]
-		if wf.Frame != nil &&
-			(wf.Frame.Mode != distsqlpb.WindowerSpec_Frame_RANGE ||
-				wf.Frame.Bounds.Start.BoundType != distsqlpb.WindowerSpec_Frame_UNBOUNDED_PRECEDING ||
-				(wf.Frame.Bounds.End != nil && wf.Frame.Bounds.End.BoundType != distsqlpb.WindowerSpec_Frame_CURRENT_ROW)) {
-			return result, errors.Newf("window functions with non-default window frames are not supported")
+		if wf.FilterColIdx != -1 {
+			return result, errors.Newf("window functions with FILTER clause are not supported")
 		}
-		if wf.Func.AggregateFunc != nil {
-			return result, errors.Newf("aggregate functions used as window functions are not supported")
+		argTypes := make([]types.T, len(wf.ArgsIdxs))
+		for i, idx := range wf.ArgsIdxs {
+			if int(idx) >= len(spec.Input[0].ColumnTypes) {
+				return result, errors.AssertionFailedf("window function argument column %d is out of range", idx)
+			}
+			argTypes[i] = spec.Input[0].ColumnTypes[idx]
+		}
+		var returnType *types.T
+		_, returnType, err = GetWindowFunctionInfo(wf.Func, argTypes...)
+		if err != nil {
+			return result, err
 		}
 
 		input := inputs[0]
@@ -561,15 +631,24 @@ func newColOperator(
 		for i, col := range wf.Ordering.Columns {
 			orderingCols[i] = col.ColIdx
 		}
-		switch *wf.Func.WindowFunc {
-		case distsqlpb.WindowerSpec_ROW_NUMBER:
-			result.op = vecbuiltins.NewRowNumberOperator(input, int(wf.OutputColIdx)+tempPartitionColOffset, partitionColIdx)
-		case distsqlpb.WindowerSpec_RANK:
-			result.op, err = vecbuiltins.NewRankOperator(input, typs, false /* dense */, orderingCols, int(wf.OutputColIdx)+tempPartitionColOffset, partitionColIdx)
-		case distsqlpb.WindowerSpec_DENSE_RANK:
-			result.op, err = vecbuiltins.NewRankOperator(input, typs, true /* dense */, orderingCols, int(wf.OutputColIdx)+tempPartitionColOffset, partitionColIdx)
+		outputColIdx := int(wf.OutputColIdx) + tempPartitionColOffset
+		// Note that the ranking functions don't depend on the window frame.
+		switch {
+		case wf.Func.WindowFunc != nil && *wf.Func.WindowFunc == distsqlpb.WindowerSpec_ROW_NUMBER:
+			result.op = vecbuiltins.NewRowNumberOperator(input, outputColIdx, partitionColIdx)
+		case wf.Func.WindowFunc != nil && *wf.Func.WindowFunc == distsqlpb.WindowerSpec_RANK:
+			result.op, err = vecbuiltins.NewRankOperator(input, typs, false /* dense */, orderingCols, outputColIdx, partitionColIdx)
+		case wf.Func.WindowFunc != nil && *wf.Func.WindowFunc == distsqlpb.WindowerSpec_DENSE_RANK:
+			result.op, err = vecbuiltins.NewRankOperator(input, typs, true /* dense */, orderingCols, outputColIdx, partitionColIdx)
 		default:
-			return result, errors.Newf("window function %s is not supported", wf.String())
+			// All other supported functions need to buffer the whole partition.
+			result.op, err = vecbuiltins.NewBufferedWindowOperator(
+				input, typs, wf.Func, wf.ArgsIdxs, orderingCols, wf.Frame,
+				typeconv.FromColumnType(returnType), outputColIdx, partitionColIdx,
+			)
+		}
+		if err != nil {
+			return result, err
 		}
 
 		if partitionColIdx != -1 {
@@ -583,7 +662,7 @@ func newColOperator(
 			result.op = exec.NewSimpleProjectOp(result.op, int(wf.OutputColIdx+1), projection)
 		}
 
-		columnTypes = append(spec.Input[0].ColumnTypes, *types.Int)
+		columnTypes = append(spec.Input[0].ColumnTypes, *returnType)
 
 	default:
 		return result, errors.Newf("unsupported processor core %q", core)
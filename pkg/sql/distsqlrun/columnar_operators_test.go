@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"strconv"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -428,52 +429,170 @@ func TestWindowFunctionsAgainstProcessor(t *testing.T) {
 		// window functions that take in arguments.
 		typs[i] = *types.Int
 	}
-	for _, windowFn := range []distsqlpb.WindowerSpec_WindowFunc{
-		distsqlpb.WindowerSpec_ROW_NUMBER,
-		distsqlpb.WindowerSpec_RANK,
-		distsqlpb.WindowerSpec_DENSE_RANK,
+	sumFn, avgFn := distsqlpb.AggregatorSpec_SUM, distsqlpb.AggregatorSpec_AVG
+	minFn, maxFn := distsqlpb.AggregatorSpec_MIN, distsqlpb.AggregatorSpec_MAX
+	frameBound := func(
+		boundType distsqlpb.WindowerSpec_Frame_BoundType, offset uint64,
+	) distsqlpb.WindowerSpec_Frame_Bound {
+		return distsqlpb.WindowerSpec_Frame_Bound{BoundType: boundType, IntOffset: offset}
+	}
+	frame := func(
+		mode distsqlpb.WindowerSpec_Frame_Mode, start, end distsqlpb.WindowerSpec_Frame_Bound,
+	) *distsqlpb.WindowerSpec_Frame {
+		return &distsqlpb.WindowerSpec_Frame{
+			Mode:   mode,
+			Bounds: distsqlpb.WindowerSpec_Frame_Bounds{Start: start, End: &end},
+		}
+	}
+	frames := []struct {
+		frame *distsqlpb.WindowerSpec_Frame
+		// orderDependent indicates whether the tuples in the frame depend on
+		// the order of the tuples that are peers.
+		orderDependent bool
+	}{
+		// The default frame: RANGE BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW.
+		{frame: nil},
+		{
+			frame: frame(
+				distsqlpb.WindowerSpec_Frame_RANGE,
+				frameBound(distsqlpb.WindowerSpec_Frame_CURRENT_ROW, 0),
+				frameBound(distsqlpb.WindowerSpec_Frame_UNBOUNDED_FOLLOWING, 0),
+			),
+		},
+		{
+			frame: frame(
+				distsqlpb.WindowerSpec_Frame_ROWS,
+				frameBound(distsqlpb.WindowerSpec_Frame_UNBOUNDED_PRECEDING, 0),
+				frameBound(distsqlpb.WindowerSpec_Frame_UNBOUNDED_FOLLOWING, 0),
+			),
+		},
+		{
+			frame: frame(
+				distsqlpb.WindowerSpec_Frame_ROWS,
+				frameBound(distsqlpb.WindowerSpec_Frame_OFFSET_PRECEDING, 1),
+				frameBound(distsqlpb.WindowerSpec_Frame_OFFSET_FOLLOWING, 1),
+			),
+			orderDependent: true,
+		},
+		{
+			frame: frame(
+				distsqlpb.WindowerSpec_Frame_ROWS,
+				frameBound(distsqlpb.WindowerSpec_Frame_CURRENT_ROW, 0),
+				frameBound(distsqlpb.WindowerSpec_Frame_OFFSET_FOLLOWING, 2),
+			),
+			orderDependent: true,
+		},
+	}
+	for _, tc := range []struct {
+		windowFn distsqlpb.WindowerSpec_Func
+		argsIdxs []uint32
+		// orderDependent indicates whether the output of the function depends
+		// on the order of the tuples that are peers.
+		orderDependent bool
+		// usesFrame indicates whether the output of the function depends on the
+		// window frame.
+		usesFrame bool
+		// argTypes are the types of the argument that are tested, in addition
+		// to INT.
+		argTypes []*types.T
+	}{
+		{windowFn: windowFuncSpec(distsqlpb.WindowerSpec_ROW_NUMBER), orderDependent: true},
+		{windowFn: windowFuncSpec(distsqlpb.WindowerSpec_RANK)},
+		{windowFn: windowFuncSpec(distsqlpb.WindowerSpec_DENSE_RANK)},
+		{windowFn: windowFuncSpec(distsqlpb.WindowerSpec_PERCENT_RANK)},
+		{windowFn: windowFuncSpec(distsqlpb.WindowerSpec_CUME_DIST)},
+		{windowFn: windowFuncSpec(distsqlpb.WindowerSpec_NTILE), argsIdxs: []uint32{0}, orderDependent: true},
+		{windowFn: windowFuncSpec(distsqlpb.WindowerSpec_LAG), argsIdxs: []uint32{0}, orderDependent: true},
+		{windowFn: windowFuncSpec(distsqlpb.WindowerSpec_LEAD), argsIdxs: []uint32{0}, orderDependent: true},
+		{windowFn: windowFuncSpec(distsqlpb.WindowerSpec_FIRST_VALUE), argsIdxs: []uint32{0}, orderDependent: true, usesFrame: true},
+		{windowFn: windowFuncSpec(distsqlpb.WindowerSpec_LAST_VALUE), argsIdxs: []uint32{0}, orderDependent: true, usesFrame: true},
+		{
+			windowFn: distsqlpb.WindowerSpec_Func{AggregateFunc: &sumFn}, argsIdxs: []uint32{0}, usesFrame: true,
+			argTypes: []*types.T{types.Float, types.Decimal},
+		},
+		{
+			windowFn: distsqlpb.WindowerSpec_Func{AggregateFunc: &avgFn}, argsIdxs: []uint32{0}, usesFrame: true,
+			argTypes: []*types.T{types.Float, types.Decimal},
+		},
+		{windowFn: distsqlpb.WindowerSpec_Func{AggregateFunc: &minFn}, argsIdxs: []uint32{0}, usesFrame: true},
+		{windowFn: distsqlpb.WindowerSpec_Func{AggregateFunc: &maxFn}, argsIdxs: []uint32{0}, usesFrame: true},
 	} {
-		for _, partitionBy := range [][]uint32{
-			{},     // No PARTITION BY clause.
-			{0},    // Partitioning on the first input column.
-			{0, 1}, // Partitioning on the first and second input columns.
-		} {
-			for _, nOrderingCols := range []int{
-				0, // No ORDER BY clause.
-				1, // ORDER BY on at most one column.
-				2, // ORDER BY on at most two columns.
-			} {
-				for nCols := 1; nCols <= maxCols; nCols++ {
-					if len(partitionBy) > nCols || nOrderingCols > nCols {
-						continue
-					}
-					inputTypes := typs[:nCols]
-					rows := sqlbase.MakeRandIntRowsInRange(rng, nRows, nCols, maxNum, nullProbability)
+		for _, argType := range append([]*types.T{types.Int}, tc.argTypes...) {
+			colTyps := append([]types.T{*argType}, typs[1:]...)
+			_, outputType, err := GetWindowFunctionInfo(tc.windowFn, colTyps[:len(tc.argsIdxs)]...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			testFrames := frames
+			if !tc.usesFrame {
+				testFrames = frames[:1]
+			}
+			for _, fr := range testFrames {
+				for _, partitionBy := range [][]uint32{
+					{},     // No PARTITION BY clause.
+					{0},    // Partitioning on the first input column.
+					{0, 1}, // Partitioning on the first and second input columns.
+				} {
+					for _, nOrderingCols := range []int{
+						0, // No ORDER BY clause.
+						1, // ORDER BY on at most one column.
+						2, // ORDER BY on at most two columns.
+					} {
+						for nCols := 1; nCols <= maxCols; nCols++ {
+							if len(partitionBy) > nCols || nOrderingCols > nCols {
+								continue
+							}
+							inputTypes := colTyps[:nCols]
+							rows := sqlbase.MakeRandIntRowsInRange(rng, nRows, nCols, maxNum, nullProbability)
+							if argType.Family() != types.IntFamily {
+								for _, row := range rows {
+									row[0] = randFloatOrDecimalEncDatum(rng, argType, maxNum)
+								}
+							}
+							if fn := tc.windowFn.WindowFunc; fn != nil && *fn == distsqlpb.WindowerSpec_NTILE {
+								// The number of buckets of NTILE must be positive, and it is
+								// taken from the first tuple of the partition, so we use the
+								// same number for all tuples.
+								numBuckets := sqlbase.IntEncDatum(1 + rng.Intn(maxNum))
+								for _, row := range rows {
+									row[tc.argsIdxs[0]] = numBuckets
+								}
+							}
 
-					windowerSpec := &distsqlpb.WindowerSpec{
-						PartitionBy: partitionBy,
-						WindowFns: []distsqlpb.WindowerSpec_WindowFn{
-							{
-								Func:         distsqlpb.WindowerSpec_Func{WindowFunc: &windowFn},
-								Ordering:     generateOrderingGivenPartitionBy(rng, nCols, nOrderingCols, partitionBy),
-								OutputColIdx: uint32(nCols),
-							},
-						},
-					}
-					if windowFn == distsqlpb.WindowerSpec_ROW_NUMBER &&
-						len(partitionBy)+len(windowerSpec.WindowFns[0].Ordering.Columns) < nCols {
-						// The output of row_number is not deterministic if there are
-						// columns that are not present in either PARTITION BY or ORDER BY
-						// clauses, so we skip such a configuration.
-						continue
-					}
+							windowerSpec := &distsqlpb.WindowerSpec{
+								PartitionBy: partitionBy,
+								WindowFns: []distsqlpb.WindowerSpec_WindowFn{
+									{
+										Func:         tc.windowFn,
+										ArgsIdxs:     tc.argsIdxs,
+										Ordering:     generateOrderingGivenPartitionBy(rng, nCols, nOrderingCols, partitionBy),
+										Frame:        fr.frame,
+										FilterColIdx: noFilterIdx,
+										OutputColIdx: uint32(nCols),
+									},
+								},
+							}
+							// The sums of FLOATs, as well as the scale of the sums of
+							// DECIMALs, depend on the order in which they are added up,
+							// so those are treated as order dependent too.
+							if (tc.orderDependent || fr.orderDependent || argType.Family() != types.IntFamily) &&
+								len(partitionBy)+len(windowerSpec.WindowFns[0].Ordering.Columns) < nCols {
+								// The output of order dependent functions (like row_number),
+								// as well as of functions over order dependent frames, is not
+								// deterministic if there are columns that are not present in
+								// either PARTITION BY or ORDER BY clauses, so we skip such a
+								// configuration.
+								continue
+							}
 
-					pspec := &distsqlpb.ProcessorSpec{
-						Input: []distsqlpb.InputSyncSpec{{ColumnTypes: inputTypes}},
-						Core:  distsqlpb.ProcessorCoreUnion{Windower: windowerSpec},
-					}
-					if err := verifyColOperator(true /* anyOrder */, [][]types.T{inputTypes}, []sqlbase.EncDatumRows{rows}, append(inputTypes, *types.Int), pspec); err != nil {
-						t.Fatal(err)
+							pspec := &distsqlpb.ProcessorSpec{
+								Input: []distsqlpb.InputSyncSpec{{ColumnTypes: inputTypes}},
+								Core:  distsqlpb.ProcessorCoreUnion{Windower: windowerSpec},
+							}
+							if err := verifyColOperator(true /* anyOrder */, [][]types.T{inputTypes}, []sqlbase.EncDatumRows{rows}, append(inputTypes, *outputType), pspec); err != nil {
+								t.Fatal(err)
+							}
+						}
 					}
 				}
 			}
@@ -481,6 +600,46 @@ func TestWindowFunctionsAgainstProcessor(t *testing.T) {
 	}
 }
 
+// randFloatOrDecimalEncDatum returns a random FLOAT or DECIMAL in the range
+// [0, maxNum), or sometimes a NaN or an infinity. Equal DECIMALs always have the
+// same scale, so that the order of the tuples sorted on them is deterministic.
+func randFloatOrDecimalEncDatum(rng *rand.Rand, typ *types.T, maxNum int) sqlbase.EncDatum {
+	var s string
+	switch rng.Intn(10) {
+	case 0:
+		s = "NaN"
+	case 1:
+		s = "Infinity"
+	case 2:
+		s = "-Infinity"
+	default:
+		s = strconv.Itoa(rng.Intn(maxNum))
+		if scale := rng.Intn(3); scale > 0 {
+			s += "."
+			for i := 1; i < scale; i++ {
+				s += strconv.Itoa(rng.Intn(10))
+			}
+			s += strconv.Itoa(1 + rng.Intn(9))
+		}
+	}
+	var d tree.Datum
+	var err error
+	if typ.Family() == types.FloatFamily {
+		d, err = tree.ParseDFloat(s)
+	} else {
+		d, err = tree.ParseDDecimal(s)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return sqlbase.DatumToEncDatum(typ, d)
+}
+
+// windowFuncSpec returns the spec of the given window function.
+func windowFuncSpec(windowFn distsqlpb.WindowerSpec_WindowFunc) distsqlpb.WindowerSpec_Func {
+	return distsqlpb.WindowerSpec_Func{WindowFunc: &windowFn}
+}
+
 // generateOrderingGivenPartitionBy produces a random ordering of up to
 // nOrderingCols columns on a table with nCols columns such that only columns
 // not present in partitionBy are used. This is useful to simulate how
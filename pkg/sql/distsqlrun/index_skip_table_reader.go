@@ -124,7 +124,8 @@ func newIndexSkipTableReader(
 	}
 
 	if err := t.fetcher.Init(t.reverse, true, /* returnRangeInfo */
-		false /* isCheck */, &t.alloc, tableArgs); err != nil {
+		false /* isCheck */, roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK,
+		&t.alloc, tableArgs); err != nil {
 		return nil, err
 	}
 
@@ -108,6 +108,8 @@ func newIndexJoiner(
 		false, /* isCheck */
 		&ij.alloc,
 		spec.Visibility,
+		distsqlpb.ScanLockingStrength_FOR_NONE,
+		distsqlpb.ScanLockingWaitPolicy_BLOCK,
 	); err != nil {
 		return nil, err
 	}
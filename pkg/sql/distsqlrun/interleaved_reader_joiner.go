@@ -416,8 +416,8 @@ func (irj *interleavedReaderJoiner) initRowFetcher(
 		}
 	}
 
-	return irj.fetcher.Init(reverseScan, true /* returnRangeInfo */, true /* isCheck */, alloc,
-		args...)
+	return irj.fetcher.Init(reverseScan, true /* returnRangeInfo */, true, /* isCheck */
+		roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, alloc, args...)
 }
 
 func (irj *interleavedReaderJoiner) generateTrailingMeta(
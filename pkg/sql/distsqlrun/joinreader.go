@@ -194,6 +194,7 @@ func newJoinReader(
 	_, _, err = initRowFetcher(
 		&fetcher, &jr.desc, int(spec.IndexIdx), jr.colIdxMap, false, /* reverse */
 		neededRightCols, false /* isCheck */, &jr.alloc, spec.Visibility,
+		distsqlpb.ScanLockingStrength_FOR_NONE, distsqlpb.ScanLockingWaitPolicy_BLOCK,
 	)
 	if err != nil {
 		return nil, err
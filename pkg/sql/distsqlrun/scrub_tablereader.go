@@ -127,6 +127,8 @@ func newScrubTableReader(
 		&fetcher, &tr.tableDesc, int(spec.IndexIdx), tr.tableDesc.ColumnIdxMap(), spec.Reverse,
 		neededColumns, true /* isCheck */, &tr.alloc,
 		distsqlpb.ScanVisibility_PUBLIC,
+		distsqlpb.ScanLockingStrength_FOR_NONE,
+		distsqlpb.ScanLockingWaitPolicy_BLOCK,
 	); err != nil {
 		return nil, err
 	}
@@ -89,6 +89,12 @@ var settingUseTempStorageSorts = settings.RegisterBoolSetting(
 	true,
 )
 
+var settingUseTempStorageAggregations = settings.RegisterBoolSetting(
+	"sql.distsql.temp_storage.aggregations",
+	"set to true to enable use of disk for distributed sql aggregations",
+	true,
+)
+
 var settingUseTempStorageJoins = settings.RegisterBoolSetting(
 	"sql.distsql.temp_storage.joins",
 	"set to true to enable use of disk for distributed sql joins",
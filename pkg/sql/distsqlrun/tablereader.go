@@ -24,8 +24,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
 	opentracing "github.com/opentracing/opentracing-go"
-	"github.com/pkg/errors"
 )
 
 // ParallelScanResultThreshold is the number of results up to which, if the
@@ -135,7 +135,8 @@ func newTableReader(
 	columnIdxMap := spec.Table.ColumnIdxMapWithMutations(returnMutations)
 	if _, _, err := initRowFetcher(
 		&fetcher, &spec.Table, int(spec.IndexIdx), columnIdxMap, spec.Reverse,
-		neededColumns, spec.IsCheck, &tr.alloc, spec.Visibility,
+		neededColumns, spec.IsCheck, &tr.alloc, spec.Visibility, spec.LockingStrength,
+		spec.LockingWaitPolicy,
 	); err != nil {
 		return nil, err
 	}
@@ -170,6 +171,8 @@ func initRowFetcher(
 	isCheck bool,
 	alloc *sqlbase.DatumAlloc,
 	scanVisibility distsqlpb.ScanVisibility,
+	lockStr distsqlpb.ScanLockingStrength,
+	lockWaitPolicy distsqlpb.ScanLockingWaitPolicy,
 ) (index *sqlbase.IndexDescriptor, isSecondaryIndex bool, err error) {
 	immutDesc := sqlbase.NewImmutableTableDescriptor(*desc)
 	index, isSecondaryIndex, err = immutDesc.FindIndexByIndexIdx(indexIdx)
@@ -190,7 +193,8 @@ func initRowFetcher(
 		ValNeededForCol:  valNeededForCol,
 	}
 	if err := fetcher.Init(
-		reverseScan, true /* returnRangeInfo */, isCheck, alloc, tableArgs,
+		reverseScan, true /* returnRangeInfo */, isCheck,
+		toKeyLockingStrength(lockStr), toLockWaitPolicy(lockWaitPolicy), alloc, tableArgs,
 	); err != nil {
 		return nil, false, err
 	}
@@ -198,6 +202,39 @@ func initRowFetcher(
 	return index, isSecondaryIndex, nil
 }
 
+// toKeyLockingStrength converts the row-level locking strength of a scan into
+// the key-level locking strength used by the KV layer. The KV layer does not
+// distinguish between the weaker and stronger variants of exclusive and shared
+// locks, so FOR NO KEY UPDATE and FOR KEY SHARE are strengthened to FOR UPDATE
+// and FOR SHARE, respectively.
+func toKeyLockingStrength(s distsqlpb.ScanLockingStrength) roachpb.KeyLockingStrength {
+	switch s {
+	case distsqlpb.ScanLockingStrength_FOR_NONE:
+		return roachpb.KEY_LOCKING_NONE
+	case distsqlpb.ScanLockingStrength_FOR_KEY_SHARE, distsqlpb.ScanLockingStrength_FOR_SHARE:
+		return roachpb.KEY_LOCKING_SHARED
+	case distsqlpb.ScanLockingStrength_FOR_NO_KEY_UPDATE, distsqlpb.ScanLockingStrength_FOR_UPDATE:
+		return roachpb.KEY_LOCKING_EXCLUSIVE
+	default:
+		panic(errors.AssertionFailedf("unknown locking strength %s", s))
+	}
+}
+
+// toLockWaitPolicy converts the locking wait policy of a scan into the wait
+// policy used by the KV layer.
+func toLockWaitPolicy(wp distsqlpb.ScanLockingWaitPolicy) roachpb.LockWaitPolicy {
+	switch wp {
+	case distsqlpb.ScanLockingWaitPolicy_BLOCK:
+		return roachpb.LOCK_WAIT_BLOCK
+	case distsqlpb.ScanLockingWaitPolicy_SKIP:
+		return roachpb.LOCK_WAIT_SKIP
+	case distsqlpb.ScanLockingWaitPolicy_ERROR:
+		return roachpb.LOCK_WAIT_ERROR
+	default:
+		panic(errors.AssertionFailedf("unknown locking wait policy %s", wp))
+	}
+}
+
 func (tr *tableReader) generateTrailingMeta(ctx context.Context) []distsqlpb.ProducerMetadata {
 	trailingMeta := tr.generateMeta(ctx)
 	tr.InternalClose()
@@ -441,6 +441,8 @@ func (z *zigzagJoiner) setupInfo(spec *distsqlpb.ZigzagJoinerSpec, side int, col
 		false, /* check */
 		info.alloc,
 		distsqlpb.ScanVisibility_PUBLIC,
+		distsqlpb.ScanLockingStrength_FOR_NONE,
+		distsqlpb.ScanLockingWaitPolicy_BLOCK,
 	)
 	if err != nil {
 		return err
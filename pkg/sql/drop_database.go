@@ -29,6 +29,7 @@ type dropDatabaseNode struct {
 	n      *tree.DropDatabase
 	dbDesc *sqlbase.DatabaseDescriptor
 	td     []toDelete
+	types  []*sqlbase.TypeDescriptor
 }
 
 // DropDatabase drops a database.
@@ -107,7 +108,43 @@ func (p *planner) DropDatabase(ctx context.Context, n *tree.DropDatabase) (planN
 		return nil, err
 	}
 
-	return &dropDatabaseNode{n: n, dbDesc: dbDesc, td: td}, nil
+	typeDescs, err := p.getTypesToDropWithDatabase(ctx, dbDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dropDatabaseNode{n: n, dbDesc: dbDesc, td: td, types: typeDescs}, nil
+}
+
+// getTypesToDropWithDatabase returns the user-defined types of a database
+// that is about to be dropped. It returns an error if one of them is used by
+// a table in another database.
+func (p *planner) getTypesToDropWithDatabase(
+	ctx context.Context, dbDesc *sqlbase.DatabaseDescriptor,
+) ([]*sqlbase.TypeDescriptor, error) {
+	descs, err := GetAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return nil, err
+	}
+	var typeDescs []*sqlbase.TypeDescriptor
+	for _, desc := range descs {
+		typDesc, ok := desc.(*sqlbase.TypeDescriptor)
+		if !ok || typDesc.ParentID != dbDesc.ID {
+			continue
+		}
+		tables, err := p.getTablesUsingType(ctx, typDesc.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range tables {
+			if table.ParentID != dbDesc.ID {
+				return nil, pgerror.Newf(pgcode.DependentObjectsStillExist,
+					"cannot drop type %q because table %q uses it", typDesc.Name, table.Name)
+			}
+		}
+		typeDescs = append(typeDescs, typDesc)
+	}
+	return typeDescs, nil
 }
 
 func (n *dropDatabaseNode) startExec(params runParams) error {
@@ -159,6 +196,14 @@ func (n *dropDatabaseNode) startExec(params runParams) error {
 		tbNameStrings = append(tbNameStrings, toDel.tn.FQString())
 	}
 
+	for _, typDesc := range n.types {
+		if err := p.dropTypeImpl(ctx, typDesc); err != nil {
+			return err
+		}
+		tn := tree.MakeTableName(tree.Name(n.dbDesc.Name), tree.Name(typDesc.Name))
+		tbNameStrings = append(tbNameStrings, tn.FQString())
+	}
+
 	_ /* zoneKey */, nameKey, descKey := getKeysForDatabaseDescriptor(n.dbDesc)
 
 	b := &client.Batch{}
@@ -75,6 +75,9 @@ func (n *dropIndexNode) startExec(params runParams) error {
 				"table descriptor for %q became unavailable within same txn",
 				tree.ErrString(index.tn))
 		}
+		if err := checkNoPrimaryKeySwap(tableDesc); err != nil {
+			return err
+		}
 
 		if err := params.p.dropIndexByName(
 			ctx, index.tn, index.idxName, tableDesc, n.n.IfExists, n.n.DropBehavior, checkIdxConstraint,
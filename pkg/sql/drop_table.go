@@ -364,7 +364,7 @@ func (p *planner) initiateDropTable(
 	if drainName {
 		// Queue up name for draining.
 		nameDetails := sqlbase.TableDescriptor_NameInfo{
-			ParentID: tableDesc.ParentID,
+			ParentID: tableDesc.GetNamespaceParentID(),
 			Name:     tableDesc.Name}
 		tableDesc.DrainingNames = append(tableDesc.DrainingNames, nameDetails)
 	}
@@ -0,0 +1,121 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+type typeToDelete struct {
+	tn   *tree.TableName
+	desc *sqlbase.TypeDescriptor
+}
+
+type dropTypeNode struct {
+	n  *tree.DropType
+	td []typeToDelete
+}
+
+// DropType drops user-defined types.
+// Privileges: DROP on type.
+func (p *planner) DropType(ctx context.Context, n *tree.DropType) (planNode, error) {
+	if n.DropBehavior == tree.DropCascade {
+		return nil, unimplemented.NewWithIssue(27793, "DROP TYPE ... CASCADE is not supported")
+	}
+
+	td := make([]typeToDelete, 0, len(n.Names))
+	for i := range n.Names {
+		tn := &n.Names[i]
+		desc, err := p.resolveTypeDesc(ctx, tn, !n.IfExists)
+		if err != nil {
+			return nil, err
+		}
+		if desc == nil {
+			// IfExists specified and the type does not exist.
+			continue
+		}
+
+		if err := p.CheckPrivilege(ctx, desc, privilege.DROP); err != nil {
+			return nil, err
+		}
+
+		tables, err := p.getTablesUsingType(ctx, desc.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(tables) > 0 {
+			return nil, errors.WithHint(
+				pgerror.Newf(pgcode.DependentObjectsStillExist,
+					"cannot drop type %q because other objects depend on it", tn.Table()),
+				"the type is used by table "+tree.NameString(tables[0].Name))
+		}
+
+		td = append(td, typeToDelete{tn: tn, desc: desc})
+	}
+
+	if len(td) == 0 {
+		return newZeroNode(nil /* columns */), nil
+	}
+	return &dropTypeNode{n: n, td: td}, nil
+}
+
+func (n *dropTypeNode) startExec(params runParams) error {
+	for _, toDel := range n.td {
+		if err := params.p.dropTypeImpl(params.ctx, toDel.desc); err != nil {
+			return err
+		}
+		// Log a Drop Type event. This is an auditable log event and is recorded
+		// in the same transaction as the type descriptor deletion.
+		if err := MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+			params.ctx,
+			params.p.txn,
+			EventLogDropType,
+			int32(toDel.desc.ID),
+			int32(params.extendedEvalCtx.NodeID),
+			struct {
+				TypeName  string
+				Statement string
+				User      string
+			}{toDel.tn.FQString(), n.n.String(), params.SessionData().User},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*dropTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropTypeNode) Values() tree.Datums          { return tree.Datums{} }
+func (*dropTypeNode) Close(context.Context)        {}
+
+// dropTypeImpl deletes the name and the descriptor of a type. Since types
+// have no data and are not leased, they can be removed right away.
+func (p *planner) dropTypeImpl(ctx context.Context, desc *sqlbase.TypeDescriptor) error {
+	b := &client.Batch{}
+	nameKey := sqlbase.NewTableKey(desc.ParentID, desc.Name).Key()
+	descKey := sqlbase.MakeDescMetadataKey(desc.ID)
+	if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+		log.VEventf(ctx, 2, "Del %s", nameKey)
+		log.VEventf(ctx, 2, "Del %s", descKey)
+	}
+	b.Del(nameKey, descKey)
+	return p.txn.Run(ctx, b)
+}
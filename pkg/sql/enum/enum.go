@@ -0,0 +1,82 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package enum generates the physical representations of the members of
+// user-defined ENUM types.
+//
+// The physical representation of a member is the byte string it is encoded
+// as, both in keys and in values. The byte order of the physical
+// representations is the sort order of the enum, which is the order in which
+// the members were declared. Since ALTER TYPE ... ADD VALUE can insert a
+// member anywhere in that order without rewriting the existing data, we need
+// to be able to generate a byte string between any two existing ones. The
+// functions in this package never generate byte strings ending with a zero
+// byte, which guarantees that this is always possible.
+package enum
+
+// GenByteStringBetween returns a byte string which sorts strictly after prev
+// and strictly before next. A nil prev stands for the beginning of the key
+// space, and a nil next for its end. prev must sort strictly before next.
+func GenByteStringBetween(prev []byte, next []byte) []byte {
+	var result []byte
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(prev) {
+			lo = int(prev[i])
+		}
+		// As long as result is a prefix of next, next is longer than result;
+		// otherwise, next would be a prefix of prev, or equal to it.
+		hi := 256
+		if next != nil {
+			hi = int(next[i])
+		}
+		if hi-lo > 1 {
+			// The midpoint is strictly between lo and hi, so it is never zero.
+			return append(result, byte((lo+hi)/2))
+		}
+		result = append(result, byte(lo))
+		if hi-lo == 1 {
+			// result now sorts before next regardless of what comes after.
+			next = nil
+		}
+	}
+}
+
+// GenerateNEvenlySpacedBytes returns n byte strings in increasing order,
+// spread evenly over the key space. This leaves as much room as possible
+// between them for members added later on.
+func GenerateNEvenlySpacedBytes(n int) [][]byte {
+	if n == 0 {
+		return nil
+	}
+	// Use enough bytes for the n values to fit strictly between zero and the
+	// end of the key space.
+	numBytes := 1
+	for space := 256; space <= n; space *= 256 {
+		numBytes++
+	}
+	step := (uint64(1) << (8 * uint(numBytes))) / uint64(n+1)
+	result := make([][]byte, n)
+	for i := range result {
+		v := step * uint64(i+1)
+		b := make([]byte, numBytes)
+		for j := numBytes - 1; j >= 0; j-- {
+			b[j] = byte(v)
+			v >>= 8
+		}
+		// Trailing zeros can be dropped without changing the order. v is never
+		// zero, so b never becomes empty.
+		for b[len(b)-1] == 0 {
+			b = b[:len(b)-1]
+		}
+		result[i] = b
+	}
+	return result
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package enum
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func checkSorted(t *testing.T, reps [][]byte) {
+	t.Helper()
+	for i := range reps {
+		if len(reps[i]) == 0 {
+			t.Fatalf("empty representation at %d", i)
+		}
+		if reps[i][len(reps[i])-1] == 0 {
+			t.Fatalf("representation %v at %d ends with a zero byte", reps[i], i)
+		}
+		if i > 0 && bytes.Compare(reps[i-1], reps[i]) >= 0 {
+			t.Fatalf("representations at %d and %d are out of order: %v, %v", i-1, i, reps[i-1], reps[i])
+		}
+	}
+}
+
+func TestGenByteStringBetween(t *testing.T) {
+	testCases := []struct {
+		prev, next []byte
+	}{
+		{nil, nil},
+		{[]byte{127}, nil},
+		{nil, []byte{127}},
+		{[]byte{255}, nil},
+		{nil, []byte{1}},
+		{[]byte{1}, []byte{2}},
+		{[]byte{1}, []byte{1, 1}},
+		{[]byte{1, 255}, []byte{2}},
+		{[]byte{1, 255, 255}, []byte{2, 0, 1}},
+		{[]byte{3}, []byte{3, 0, 0, 1}},
+	}
+	for _, tc := range testCases {
+		res := GenByteStringBetween(tc.prev, tc.next)
+		reps := [][]byte{res}
+		if tc.prev != nil {
+			reps = append([][]byte{tc.prev}, reps...)
+		}
+		if tc.next != nil {
+			reps = append(reps, tc.next)
+		}
+		checkSorted(t, reps)
+	}
+}
+
+func TestGenByteStringBetweenRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	var reps [][]byte
+	for i := 0; i < 1000; i++ {
+		pos := rng.Intn(len(reps) + 1)
+		var prev, next []byte
+		if pos > 0 {
+			prev = reps[pos-1]
+		}
+		if pos < len(reps) {
+			next = reps[pos]
+		}
+		res := GenByteStringBetween(prev, next)
+		reps = append(reps, nil)
+		copy(reps[pos+1:], reps[pos:])
+		reps[pos] = res
+		checkSorted(t, reps)
+	}
+}
+
+func TestGenerateNEvenlySpacedBytes(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 100, 254, 255, 256, 1000, 70000} {
+		reps := GenerateNEvenlySpacedBytes(n)
+		if len(reps) != n {
+			t.Fatalf("expected %d representations, got %d", n, len(reps))
+		}
+		checkSorted(t, reps)
+	}
+}
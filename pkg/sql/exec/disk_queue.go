@@ -0,0 +1,262 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/colserde"
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/execerror"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// diskQueue is a FIFO queue of coldata.Batches that is backed by a
+// diskmap.SortedDiskMap (usually the temp engine). Batches are serialized using
+// the Arrow IPC format and are keyed by the order in which they were enqueued,
+// so iterating over the map returns them in the insertion order.
+//
+// The queue has two phases: first, batches are added with enqueue, and then,
+// once finishWriting has been called, the contents of the queue can be read
+// (possibly multiple times) through Operators returned by newReader.
+type diskQueue struct {
+	typs    []coltypes.T
+	diskMap diskmap.SortedDiskMap
+	writer  diskmap.SortedDiskMapBatchWriter
+
+	converter  *colserde.ArrowBatchConverter
+	serializer *colserde.RecordBatchSerializer
+
+	// numBatches is the number of batches that have been enqueued so far. It is
+	// also used to generate the keys under which batches are stored.
+	numBatches uint64
+	// numTuples is the number of tuples that have been enqueued so far.
+	numTuples uint64
+	// readers are all of the readers created by newReader. They are tracked so
+	// that their iterators can be closed together with the queue.
+	readers []*diskQueueReader
+
+	scratch struct {
+		// batch is used to compact the selected tuples before they are
+		// serialized.
+		batch coldata.Batch
+		key   []byte
+		buf   *bytes.Buffer
+	}
+}
+
+// newDiskQueue creates a new diskQueue for batches of the given types, using
+// the given factory to create the backing storage. An error is returned if any
+// of the types cannot be serialized.
+func newDiskQueue(typs []coltypes.T, factory diskmap.Factory) (*diskQueue, error) {
+	c, err := colserde.NewArrowBatchConverter(typs)
+	if err != nil {
+		return nil, err
+	}
+	s, err := colserde.NewRecordBatchSerializer(typs)
+	if err != nil {
+		return nil, err
+	}
+	q := &diskQueue{
+		typs:       typs,
+		diskMap:    factory.NewSortedDiskMap(),
+		converter:  c,
+		serializer: s,
+	}
+	q.writer = q.diskMap.NewBatchWriter()
+	q.scratch.batch = coldata.NewMemBatch(typs)
+	q.scratch.buf = &bytes.Buffer{}
+	return q, nil
+}
+
+// CanSpillTypes returns whether all of the given types can be written to a
+// diskQueue.
+func CanSpillTypes(typs []coltypes.T) bool {
+	_, err := colserde.NewArrowBatchConverter(typs)
+	return err == nil
+}
+
+// enqueue serializes the given batch and adds it to the queue. Zero-length
+// batches are ignored.
+func (q *diskQueue) enqueue(batch coldata.Batch) {
+	n := batch.Length()
+	if sel := batch.Selection(); sel != nil {
+		q.enqueueSelected(batch, sel[:n])
+		return
+	}
+	q.enqueueDense(batch)
+}
+
+// enqueueSelected adds the tuples of the batch at the positions specified by
+// sel to the queue, ignoring the selection vector of the batch itself.
+func (q *diskQueue) enqueueSelected(batch coldata.Batch, sel []uint16) {
+	n := uint16(len(sel))
+	if n == 0 {
+		return
+	}
+	// The Arrow converter doesn't understand selection vectors, so we need to
+	// copy the selected tuples into a dense batch first.
+	for i, t := range q.typs {
+		q.scratch.batch.ColVec(i).Copy(
+			coldata.CopyArgs{
+				ColType:   t,
+				Src:       batch.ColVec(i),
+				Sel:       sel,
+				SrcEndIdx: uint64(n),
+			},
+		)
+	}
+	q.scratch.batch.SetLength(n)
+	q.enqueueDense(q.scratch.batch)
+}
+
+// enqueueDense adds the first Length() tuples of the batch to the queue.
+func (q *diskQueue) enqueueDense(batch coldata.Batch) {
+	n := batch.Length()
+	if n == 0 {
+		return
+	}
+	if q.writer == nil {
+		execerror.VectorizedInternalPanic("enqueue is called after finishWriting")
+	}
+	data, err := q.converter.BatchToArrow(batch)
+	if err != nil {
+		execerror.VectorizedInternalPanic(err)
+	}
+	q.scratch.buf.Reset()
+	if _, _, err := q.serializer.Serialize(q.scratch.buf, data); err != nil {
+		execerror.VectorizedInternalPanic(err)
+	}
+	q.scratch.key = encoding.EncodeUvarintAscending(q.scratch.key[:0], q.numBatches)
+	if err := q.writer.Put(q.scratch.key, q.scratch.buf.Bytes()); err != nil {
+		execerror.NonVectorizedPanic(err)
+	}
+	q.numBatches++
+	q.numTuples += uint64(n)
+}
+
+// finishWriting flushes all of the enqueued batches to the underlying storage.
+// enqueue must not be called afterwards.
+func (q *diskQueue) finishWriting(ctx context.Context) {
+	if q.writer == nil {
+		return
+	}
+	if err := q.writer.Close(ctx); err != nil {
+		execerror.NonVectorizedPanic(err)
+	}
+	q.writer = nil
+}
+
+// newReader returns an Operator that emits all of the batches in the queue in
+// the order in which they were enqueued. finishWriting must have been called
+// before the returned Operator is initialized.
+func (q *diskQueue) newReader() Operator {
+	r := &diskQueueReader{queue: q}
+	q.readers = append(q.readers, r)
+	return r
+}
+
+// close releases the resources held by the queue. None of the readers can be
+// used after that.
+func (q *diskQueue) close(ctx context.Context) {
+	if q.writer != nil {
+		// The error is ignored since we are discarding the data anyway.
+		_ = q.writer.Close(ctx)
+		q.writer = nil
+	}
+	for _, r := range q.readers {
+		r.close()
+	}
+	q.readers = nil
+	q.diskMap.Close(ctx)
+}
+
+// diskQueueReader is an Operator that reads the batches from a diskQueue.
+type diskQueueReader struct {
+	ZeroInputNode
+
+	queue      *diskQueue
+	converter  *colserde.ArrowBatchConverter
+	serializer *colserde.RecordBatchSerializer
+	iter       diskmap.SortedDiskMapIterator
+	// positioned indicates whether iter has been positioned at the first key.
+	positioned bool
+	done       bool
+
+	scratch struct {
+		data  []*array.Data
+		batch coldata.Batch
+	}
+}
+
+var _ Operator = &diskQueueReader{}
+
+func (r *diskQueueReader) Init() {
+	if r.queue.writer != nil {
+		execerror.VectorizedInternalPanic("diskQueueReader is initialized before finishWriting")
+	}
+	var err error
+	// Each reader needs its own converter and serializer since they keep
+	// scratch state and several readers of different queues can be active at
+	// the same time.
+	if r.converter, err = colserde.NewArrowBatchConverter(r.queue.typs); err != nil {
+		execerror.VectorizedInternalPanic(err)
+	}
+	if r.serializer, err = colserde.NewRecordBatchSerializer(r.queue.typs); err != nil {
+		execerror.VectorizedInternalPanic(err)
+	}
+	r.scratch.data = make([]*array.Data, 0, len(r.queue.typs))
+	r.scratch.batch = coldata.NewMemBatch(r.queue.typs)
+	r.iter = r.queue.diskMap.NewIterator()
+}
+
+func (r *diskQueueReader) Next(ctx context.Context) coldata.Batch {
+	if r.done {
+		r.scratch.batch.SetLength(0)
+		return r.scratch.batch
+	}
+	if !r.positioned {
+		r.iter.Rewind()
+		r.positioned = true
+	} else {
+		r.iter.Next()
+	}
+	if ok, err := r.iter.Valid(); err != nil {
+		execerror.NonVectorizedPanic(err)
+	} else if !ok {
+		r.close()
+		r.scratch.batch.SetLength(0)
+		return r.scratch.batch
+	}
+	// We're using Value rather than UnsafeValue because the deserialized batch
+	// might keep references into the serialized bytes.
+	r.scratch.data = r.scratch.data[:0]
+	if err := r.serializer.Deserialize(&r.scratch.data, r.iter.Value()); err != nil {
+		execerror.VectorizedInternalPanic(err)
+	}
+	if err := r.converter.ArrowToBatch(r.scratch.data, r.scratch.batch); err != nil {
+		execerror.VectorizedInternalPanic(err)
+	}
+	return r.scratch.batch
+}
+
+// close releases the iterator held by the reader, if any.
+func (r *diskQueueReader) close() {
+	r.done = true
+	if r.iter != nil {
+		r.iter.Close()
+		r.iter = nil
+	}
+}
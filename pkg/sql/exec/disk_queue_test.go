@@ -0,0 +1,92 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// newTestDiskFactory returns a temp engine that can be used by the disk-backed
+// operators in tests. The caller is responsible for closing it.
+func newTestDiskFactory(t *testing.T) diskmap.Factory {
+	st := cluster.MakeTestingClusterSettings()
+	tempEngine, err := engine.NewTempEngine(base.DefaultTestTempStorageConfig(st), base.DefaultTestStoreSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tempEngine
+}
+
+func TestDiskQueue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	factory := newTestDiskFactory(t)
+	defer factory.Close()
+
+	typs := []coltypes.T{coltypes.Int64, coltypes.Bytes}
+	const numBatches = 5
+	input := coldata.NewMemBatch(typs)
+	q, err := newDiskQueue(typs, factory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.close(ctx)
+
+	var expected tuples
+	for i := 0; i < numBatches; i++ {
+		ints, bytes := input.ColVec(0).Int64(), input.ColVec(1).Bytes()
+		for j := 0; j < coldata.BatchSize; j++ {
+			v := int64(i*coldata.BatchSize + j)
+			ints[j] = v
+			bytes.Set(j, []byte{byte(v)})
+		}
+		input.SetLength(coldata.BatchSize)
+		// Use a selection vector that picks every other tuple on odd batches.
+		input.SetSelection(i%2 == 1)
+		n := coldata.BatchSize
+		if i%2 == 1 {
+			sel := input.Selection()
+			n = 0
+			for j := 0; j < coldata.BatchSize; j += 2 {
+				sel[n] = uint16(j)
+				n++
+			}
+			input.SetLength(uint16(n))
+		}
+		for j := 0; j < n; j++ {
+			idx := j
+			if i%2 == 1 {
+				idx = int(input.Selection()[j])
+			}
+			expected = append(expected, tuple{ints[idx], string(bytes.Get(idx))})
+		}
+		q.enqueue(input)
+	}
+	q.finishWriting(ctx)
+
+	// The queue can be read several times.
+	for i := 0; i < 2; i++ {
+		out := newOpTestOutput(q.newReader(), []int{0, 1}, expected)
+		if err := out.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -163,8 +163,11 @@ func init() {
 		customizer := typeCustomizers[t]
 		for _, op := range binOps {
 			// Skip types that don't have associated binary ops.
+			// TODO: add support for the mixed-type binary operations on
+			// timestamps and intervals (like timestamp - timestamp = interval) once
+			// the type customizers can be keyed by (leftType, rightType).
 			switch t {
-			case coltypes.Bytes, coltypes.Bool:
+			case coltypes.Bytes, coltypes.Bool, coltypes.Timestamp, coltypes.Interval:
 				continue
 			}
 			ov := &overload{
@@ -304,6 +307,14 @@ type floatCustomizer struct{ width int }
 // intCustomizers are used for hash functions.
 type intCustomizer struct{ width int }
 
+// timestampCustomizer is necessary since time.Time doesn't have infix operators.
+type timestampCustomizer struct{}
+
+// intervalCustomizer is necessary since duration.Duration doesn't have infix
+// operators, and two intervals can be equal while having different values of
+// their fields (e.g. '1 month' and '30 days').
+type intervalCustomizer struct{}
+
 func (boolCustomizer) getCmpOpCompareFunc() compareFunc {
 	return func(target, l, r string) string {
 		args := map[string]string{"Target": target, "Left": l, "Right": r}
@@ -567,6 +578,57 @@ func (c intCustomizer) getBinOpAssignFunc() assignFunc {
 	}
 }
 
+func (timestampCustomizer) getCmpOpCompareFunc() compareFunc {
+	return func(target, l, r string) string {
+		args := map[string]string{"Target": target, "Left": l, "Right": r}
+		buf := strings.Builder{}
+		// Inline the code from tree.compareTimestamps.
+		t := template.Must(template.New("").Parse(`
+			if {{.Left}}.Before({{.Right}}) {
+				{{.Target}} = -1
+			} else if {{.Right}}.Before({{.Left}}) {
+				{{.Target}} = 1
+			} else {
+				{{.Target}} = 0
+			}
+		`))
+
+		if err := t.Execute(&buf, args); err != nil {
+			execerror.VectorizedInternalPanic(err)
+		}
+		return buf.String()
+	}
+}
+
+func (timestampCustomizer) getHashAssignFunc() assignFunc {
+	return func(op overload, target, v, _ string) string {
+		return fmt.Sprintf(`
+			unixNano := %[2]s.UnixNano()
+			%[1]s = memhash64(noescape(unsafe.Pointer(&unixNano)), %[1]s)
+		`, target, v)
+	}
+}
+
+func (intervalCustomizer) getCmpOpCompareFunc() compareFunc {
+	return func(target, l, r string) string {
+		return fmt.Sprintf("%s = %s.Compare(%s)", target, l, r)
+	}
+}
+
+func (intervalCustomizer) getHashAssignFunc() assignFunc {
+	return func(op overload, target, v, _ string) string {
+		// Only the normalized length of the interval is hashed so that the equal
+		// intervals (according to Compare) have equal hashes.
+		return fmt.Sprintf(`
+			sortNanos, _, _, err := %[2]s.Encode()
+			if err != nil {
+				execerror.NonVectorizedPanic(err)
+			}
+			%[1]s = memhash64(noescape(unsafe.Pointer(&sortNanos)), %[1]s)
+		`, target, v)
+	}
+}
+
 func registerTypeCustomizers() {
 	typeCustomizers = make(map[coltypes.T]typeCustomizer)
 	registerTypeCustomizer(coltypes.Bool, boolCustomizer{})
@@ -578,6 +640,8 @@ func registerTypeCustomizers() {
 	registerTypeCustomizer(coltypes.Int16, intCustomizer{width: 16})
 	registerTypeCustomizer(coltypes.Int32, intCustomizer{width: 32})
 	registerTypeCustomizer(coltypes.Int64, intCustomizer{width: 64})
+	registerTypeCustomizer(coltypes.Timestamp, timestampCustomizer{})
+	registerTypeCustomizer(coltypes.Interval, intervalCustomizer{})
 }
 
 // Avoid unused warning for functions which are only used in templates.
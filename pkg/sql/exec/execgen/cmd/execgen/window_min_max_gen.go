@@ -0,0 +1,59 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+func genWindowMinMax(wr io.Writer) error {
+	t, err := ioutil.ReadFile("pkg/sql/exec/vecbuiltins/window_min_max_tmpl.go")
+	if err != nil {
+		return err
+	}
+
+	s := string(t)
+	s = strings.Replace(s, "_AGG_TITLE", "{{.AggNameTitle}}", -1)
+	s = strings.Replace(s, "_TYPES_T", "coltypes.{{.LTyp}}", -1)
+	s = strings.Replace(s, "_TemplateType", "{{.LTyp}}", -1)
+
+	assignCmpRe := regexp.MustCompile(`_ASSIGN_CMP\((.*),(.*),(.*)\)`)
+	s = assignCmpRe.ReplaceAllString(s, "{{.Assign $1 $2 $3}}")
+
+	s = replaceManipulationFuncs(".LTyp", s)
+
+	tmpl, err := template.New("window_min_max").Parse(s)
+	if err != nil {
+		return err
+	}
+	data := []aggOverloads{
+		{
+			Agg:       distsqlpb.AggregatorSpec_MIN,
+			Overloads: sameTypeComparisonOpToOverloads[tree.LT],
+		},
+		{
+			Agg:       distsqlpb.AggregatorSpec_MAX,
+			Overloads: sameTypeComparisonOpToOverloads[tree.GT],
+		},
+	}
+	return tmpl.Execute(wr, data)
+}
+
+func init() {
+	registerGenerator(genWindowMinMax, "window_min_max.eg.go")
+}
@@ -0,0 +1,216 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/execerror"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
+	"github.com/pkg/errors"
+)
+
+// externalHashAggregatorState represents the state of the external hash
+// aggregator.
+type externalHashAggregatorState int
+
+const (
+	// externalHABuffering is the initial state of the external hash aggregator
+	// in which it buffers its input in memory until either the input is
+	// exhausted or the memory limit is reached.
+	externalHABuffering externalHashAggregatorState = iota
+	// externalHAAggregatingInMemory is the state in which the whole input fit
+	// within the memory limit and the aggregation is performed by the
+	// in-memory hash aggregator.
+	externalHAAggregatingInMemory
+	// externalHAAggregatingPartitions is the state in which the input has been
+	// split into partitions on disk that are aggregated one at a time by the
+	// in-memory hash aggregator.
+	externalHAAggregatingPartitions
+	// externalHAFinished is the state in which the external hash aggregator has
+	// emitted all of its output and has released its disk resources.
+	externalHAFinished
+)
+
+// externalHashAggregator is an Operator that performs a hash aggregation of
+// its input, spilling to disk when the input doesn't fit within the memory
+// limit. The input is buffered in memory first, and if it turns out to be too
+// large, it is split into partitions on disk according to the hash of the
+// grouping columns, so that all of the tuples of every group end up in the
+// same partition. Then, each partition is aggregated by the in-memory hash
+// aggregator.
+// TODO: repartition the partitions that still don't fit within
+// the memory limit.
+type externalHashAggregator struct {
+	OneInputNode
+
+	colTypes    []coltypes.T
+	aggFns      []distsqlpb.AggregatorSpec_Func
+	groupCols   []uint32
+	aggCols     [][]uint32
+	memoryLimit int
+	diskFactory diskmap.Factory
+
+	state externalHashAggregatorState
+	// inputPartition and spooler are used to buffer the input in memory.
+	inputPartition *inputPartitioningOperator
+	spooler        spooler
+	inMemAgg       Operator
+
+	partitions *hashDiskPartitions
+	// curPartitionIdx is the index of the partition that is currently being
+	// aggregated.
+	curPartitionIdx int
+	// zeroBatch is the zero-length batch that is returned once the external
+	// hash aggregator is finished.
+	zeroBatch coldata.Batch
+}
+
+var _ Operator = &externalHashAggregator{}
+var _ distsqlpb.MetadataSource = &externalHashAggregator{}
+
+// NewExternalHashAggregator returns a disk-backed hash aggregator. Its
+// arguments are the same as the ones of NewHashAggregator with the addition of
+// the memory limit (in bytes) that the input is allowed to take up before
+// spilling to the temporary storage provided by diskFactory. Scalar
+// aggregations are not supported since they don't need to buffer their input.
+func NewExternalHashAggregator(
+	input Operator,
+	colTypes []coltypes.T,
+	aggFns []distsqlpb.AggregatorSpec_Func,
+	groupCols []uint32,
+	aggCols [][]uint32,
+	memoryLimit int64,
+	diskFactory diskmap.Factory,
+) (Operator, error) {
+	if memoryLimit <= 0 {
+		return nil, errors.Errorf("external hash aggregator requires a positive memory limit, %d given", memoryLimit)
+	}
+	if len(groupCols) == 0 {
+		return nil, errors.Errorf("external hash aggregator requires grouping columns")
+	}
+	if !CanSpillTypes(colTypes) {
+		return nil, errors.Errorf("external hash aggregator doesn't support spilling of some of the types %s", colTypes)
+	}
+	// Use the in-memory hash aggregator constructor to validate the arguments.
+	if _, err := NewHashAggregator(input, colTypes, aggFns, groupCols, aggCols, false /* isScalar */); err != nil {
+		return nil, err
+	}
+	return &externalHashAggregator{
+		OneInputNode: NewOneInputNode(input),
+		colTypes:     colTypes,
+		aggFns:       aggFns,
+		groupCols:    groupCols,
+		aggCols:      aggCols,
+		memoryLimit:  int(memoryLimit),
+		diskFactory:  diskFactory,
+	}, nil
+}
+
+func (a *externalHashAggregator) Init() {
+	a.inputPartition = newInputPartitioningOperator(a.input, a.colTypes, a.memoryLimit)
+	a.spooler = newAllSpooler(a.inputPartition, a.colTypes)
+	a.spooler.init()
+}
+
+// newInMemAgg creates an in-memory hash aggregator over the given input.
+func (a *externalHashAggregator) newInMemAgg(input Operator) Operator {
+	op, err := NewHashAggregator(input, a.colTypes, a.aggFns, a.groupCols, a.aggCols, false /* isScalar */)
+	if err != nil {
+		execerror.VectorizedInternalPanic(err)
+	}
+	op.Init()
+	return op
+}
+
+func (a *externalHashAggregator) Next(ctx context.Context) coldata.Batch {
+	for {
+		switch a.state {
+		case externalHABuffering:
+			a.spooler.spool(ctx)
+			buffered := newSpooledTuplesReader(a.spooler, a.colTypes)
+			if a.inputPartition.exhausted {
+				a.inMemAgg = a.newInMemAgg(buffered)
+				a.state = externalHAAggregatingInMemory
+				continue
+			}
+			// The input doesn't fit in memory, so we need to partition it. The
+			// buffered tuples go first, and the rest of the input is read
+			// directly, bypassing the memory limit.
+			var err error
+			a.partitions, err = newHashDiskPartitions(
+				a.colTypes, a.groupCols, defaultNumDiskPartitions, a.diskFactory,
+			)
+			if err != nil {
+				execerror.VectorizedInternalPanic(err)
+			}
+			buffered.Init()
+			for b := buffered.Next(ctx); b.Length() > 0; b = buffered.Next(ctx) {
+				a.partitions.add(ctx, b)
+			}
+			for b := a.input.Next(ctx); b.Length() > 0; b = a.input.Next(ctx) {
+				a.partitions.add(ctx, b)
+			}
+			a.partitions.finishWriting(ctx)
+			a.curPartitionIdx = -1
+			a.state = externalHAAggregatingPartitions
+		case externalHAAggregatingInMemory:
+			return a.inMemAgg.Next(ctx)
+		case externalHAAggregatingPartitions:
+			if a.inMemAgg != nil {
+				b := a.inMemAgg.Next(ctx)
+				if b.Length() > 0 {
+					return b
+				}
+				// Keep the zero-length batch around so that it could be returned
+				// once all of the partitions have been aggregated.
+				a.zeroBatch = b
+				a.inMemAgg = nil
+			}
+			a.curPartitionIdx++
+			if a.curPartitionIdx == defaultNumDiskPartitions {
+				a.close(ctx)
+				a.state = externalHAFinished
+				continue
+			}
+			partition := a.partitions.queues[a.curPartitionIdx]
+			if partition.numTuples == 0 {
+				continue
+			}
+			a.inMemAgg = a.newInMemAgg(partition.newReader())
+		case externalHAFinished:
+			return a.zeroBatch
+		default:
+			execerror.VectorizedInternalPanic(fmt.Sprintf("unexpected externalHashAggregatorState %d", a.state))
+		}
+	}
+}
+
+// close releases the disk resources held by the external hash aggregator.
+func (a *externalHashAggregator) close(ctx context.Context) {
+	if a.partitions != nil {
+		a.partitions.close(ctx)
+		a.partitions = nil
+	}
+}
+
+// DrainMeta implements the MetadataSource interface. The external hash
+// aggregator doesn't produce any metadata, but it uses this hook to release
+// its disk resources in case the query has been terminated before all of the
+// output was emitted.
+func (a *externalHashAggregator) DrainMeta(ctx context.Context) []distsqlpb.ProducerMetadata {
+	a.close(ctx)
+	return nil
+}
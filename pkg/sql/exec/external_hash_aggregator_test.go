@@ -0,0 +1,67 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func TestExternalHashAggregator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	factory := newTestDiskFactory(t)
+	defer factory.Close()
+
+	rng, _ := randutil.NewPseudoRand()
+	const (
+		nTups   = 3000
+		nGroups = 500
+	)
+	tups := make(tuples, nTups)
+	sums := make([]int64, nGroups)
+	counts := make([]int64, nGroups)
+	for i := range tups {
+		g, v := rng.Int63n(nGroups), rng.Int63n(1000)
+		tups[i] = tuple{g, v}
+		sums[g] += v
+		counts[g]++
+	}
+	var expected tuples
+	for g := range sums {
+		if counts[g] > 0 {
+			expected = append(expected, tuple{int64(g), sums[g], counts[g]})
+		}
+	}
+
+	typs := []coltypes.T{coltypes.Int64, coltypes.Int64}
+	aggFns := []distsqlpb.AggregatorSpec_Func{
+		distsqlpb.AggregatorSpec_ANY_NOT_NULL,
+		distsqlpb.AggregatorSpec_SUM_INT,
+		distsqlpb.AggregatorSpec_COUNT_ROWS,
+	}
+	// A memory limit of 1 byte forces the partitioning of the input right away,
+	// while the largest limit allows the aggregation to be performed in memory.
+	for _, memoryLimit := range []int64{1, 1 << 12, 1 << 20} {
+		t.Run(fmt.Sprintf("memoryLimit=%d", memoryLimit), func(t *testing.T) {
+			runTests(t, []tuples{tups}, expected, unorderedVerifier, []int{0, 1, 2}, func(input []Operator) (Operator, error) {
+				return NewExternalHashAggregator(
+					input[0], typs, aggFns, []uint32{0}, [][]uint32{{0}, {1}, {}}, memoryLimit, factory,
+				)
+			})
+		})
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/execerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
+	"github.com/pkg/errors"
+)
+
+// externalHashJoinerState represents the state of the external hash joiner.
+type externalHashJoinerState int
+
+const (
+	// externalHJBuffering is the initial state of the external hash joiner in
+	// which it buffers the build side in memory until either the build side is
+	// exhausted or the memory limit is reached.
+	externalHJBuffering externalHashJoinerState = iota
+	// externalHJJoiningInMemory is the state in which the build side fit
+	// within the memory limit and the join is performed by the in-memory hash
+	// joiner.
+	externalHJJoiningInMemory
+	// externalHJPartitioning is the state in which both inputs are split into
+	// partitions on disk.
+	externalHJPartitioning
+	// externalHJJoiningPartitions is the state in which the partitions are
+	// joined one at a time by the in-memory hash joiner.
+	externalHJJoiningPartitions
+	// externalHJFinished is the state in which the external hash joiner has
+	// emitted all of its output and has released its disk resources.
+	externalHJFinished
+)
+
+// externalHashJoiner is an Operator that performs an equality join of its
+// inputs, spilling to disk when the build side doesn't fit within the memory
+// limit. It implements the Grace hash join algorithm:
+// 1. the build side is buffered in memory until either it is exhausted or the
+//    memory limit is reached;
+// 2. in the former case, the in-memory hash joiner is used with the buffered
+//    tuples as the build side;
+// 3. in the latter case, both inputs are split into partitions on disk
+//    according to the hash of their equality columns, so that all of the
+//    tuples that can join with each other end up in the partitions with the
+//    same index, and then each pair of partitions is joined by the in-memory
+//    hash joiner.
+// Note that all join types supported by the in-memory hash joiner are
+// preserved by such partitioning since every build tuple and every probe tuple
+// belongs to exactly one partition.
+// TODO: repartition the partitions that still don't fit within
+// the memory limit.
+type externalHashJoiner struct {
+	twoInputNode
+
+	spec        hashJoinerSpec
+	joinType    sqlbase.JoinType
+	memoryLimit int
+	diskFactory diskmap.Factory
+
+	state externalHashJoinerState
+	// buildPartition and buildSpooler are used to buffer the build side in
+	// memory.
+	buildPartition *inputPartitioningOperator
+	buildSpooler   spooler
+	inMemJoiner    Operator
+
+	buildPartitions *hashDiskPartitions
+	probePartitions *hashDiskPartitions
+	// curPartitionIdx is the index of the partitions that are currently being
+	// joined.
+	curPartitionIdx int
+	// zeroBatch is the zero-length batch that is returned once the external
+	// hash joiner is finished.
+	zeroBatch coldata.Batch
+}
+
+var _ Operator = &externalHashJoiner{}
+var _ distsqlpb.MetadataSource = &externalHashJoiner{}
+
+// NewExternalHashJoiner returns a disk-backed hash join operator. Its
+// arguments are the same as the ones of NewEqHashJoinerOp with the addition of
+// the memory limit (in bytes) that the build side is allowed to take up
+// before spilling to the temporary storage provided by diskFactory.
+func NewExternalHashJoiner(
+	leftSource Operator,
+	rightSource Operator,
+	leftEqCols []uint32,
+	rightEqCols []uint32,
+	leftOutCols []uint32,
+	rightOutCols []uint32,
+	leftTypes []coltypes.T,
+	rightTypes []coltypes.T,
+	buildRightSide bool,
+	buildDistinct bool,
+	joinType sqlbase.JoinType,
+	memoryLimit int64,
+	diskFactory diskmap.Factory,
+) (Operator, error) {
+	if memoryLimit <= 0 {
+		return nil, errors.Errorf("external hash joiner requires a positive memory limit, %d given", memoryLimit)
+	}
+	if !CanSpillTypes(leftTypes) || !CanSpillTypes(rightTypes) {
+		return nil, errors.Errorf(
+			"external hash joiner doesn't support spilling of some of the types %s and %s", leftTypes, rightTypes,
+		)
+	}
+	// Use the in-memory hash joiner constructor to validate the arguments and
+	// to adjust them according to the join type.
+	op, err := NewEqHashJoinerOp(
+		leftSource, rightSource, leftEqCols, rightEqCols, leftOutCols, rightOutCols,
+		leftTypes, rightTypes, buildRightSide, buildDistinct, joinType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &externalHashJoiner{
+		twoInputNode: newTwoInputNode(leftSource, rightSource),
+		spec:         op.(*hashJoinEqOp).spec,
+		joinType:     joinType,
+		memoryLimit:  int(memoryLimit),
+		diskFactory:  diskFactory,
+	}, nil
+}
+
+// buildSide returns the spec of the side that the hash table is built on.
+func (hj *externalHashJoiner) buildSide() *hashJoinerSourceSpec {
+	if hj.spec.buildRightSide {
+		return &hj.spec.right
+	}
+	return &hj.spec.left
+}
+
+// probeSide returns the spec of the side that probes the hash table.
+func (hj *externalHashJoiner) probeSide() *hashJoinerSourceSpec {
+	if hj.spec.buildRightSide {
+		return &hj.spec.left
+	}
+	return &hj.spec.right
+}
+
+// Init is part of the Operator interface. Note that only the build side is
+// initialized here: the probe side will be initialized either by the in-memory
+// hash joiner or right before partitioning.
+func (hj *externalHashJoiner) Init() {
+	build := hj.buildSide()
+	hj.buildPartition = newInputPartitioningOperator(build.source, build.sourceTypes, hj.memoryLimit)
+	hj.buildSpooler = newAllSpooler(hj.buildPartition, build.sourceTypes)
+	hj.buildSpooler.init()
+	hj.zeroBatch = coldata.NewMemBatchWithSize(nil /* types */, 0 /* size */)
+}
+
+// newInMemJoiner creates an in-memory hash joiner with the given sources
+// replacing the original ones.
+func (hj *externalHashJoiner) newInMemJoiner(buildSource, probeSource Operator) Operator {
+	left, right := buildSource, probeSource
+	if hj.spec.buildRightSide {
+		left, right = probeSource, buildSource
+	}
+	op, err := NewEqHashJoinerOp(
+		left, right,
+		hj.spec.left.eqCols, hj.spec.right.eqCols,
+		hj.spec.left.outCols, hj.spec.right.outCols,
+		hj.spec.left.sourceTypes, hj.spec.right.sourceTypes,
+		hj.spec.buildRightSide, hj.spec.buildDistinct, hj.joinType,
+	)
+	if err != nil {
+		execerror.VectorizedInternalPanic(err)
+	}
+	op.Init()
+	return op
+}
+
+func (hj *externalHashJoiner) Next(ctx context.Context) coldata.Batch {
+	for {
+		switch hj.state {
+		case externalHJBuffering:
+			hj.buildSpooler.spool(ctx)
+			buffered := newSpooledTuplesReader(hj.buildSpooler, hj.buildSide().sourceTypes)
+			if hj.buildPartition.exhausted {
+				hj.inMemJoiner = hj.newInMemJoiner(buffered, hj.probeSide().source)
+				hj.state = externalHJJoiningInMemory
+				continue
+			}
+			// The build side doesn't fit in memory, so we need to partition both
+			// inputs. The buffered tuples go first.
+			var err error
+			build, probe := hj.buildSide(), hj.probeSide()
+			hj.buildPartitions, err = newHashDiskPartitions(
+				build.sourceTypes, build.eqCols, defaultNumDiskPartitions, hj.diskFactory,
+			)
+			if err != nil {
+				execerror.VectorizedInternalPanic(err)
+			}
+			hj.probePartitions, err = newHashDiskPartitions(
+				probe.sourceTypes, probe.eqCols, defaultNumDiskPartitions, hj.diskFactory,
+			)
+			if err != nil {
+				execerror.VectorizedInternalPanic(err)
+			}
+			buffered.Init()
+			for b := buffered.Next(ctx); b.Length() > 0; b = buffered.Next(ctx) {
+				hj.buildPartitions.add(ctx, b)
+			}
+			hj.state = externalHJPartitioning
+		case externalHJJoiningInMemory:
+			return hj.inMemJoiner.Next(ctx)
+		case externalHJPartitioning:
+			// The rest of the build side is read directly from the input,
+			// bypassing the memory limit.
+			build := hj.buildSide().source
+			for b := build.Next(ctx); b.Length() > 0; b = build.Next(ctx) {
+				hj.buildPartitions.add(ctx, b)
+			}
+			probe := hj.probeSide().source
+			probe.Init()
+			for b := probe.Next(ctx); b.Length() > 0; b = probe.Next(ctx) {
+				hj.probePartitions.add(ctx, b)
+			}
+			hj.buildPartitions.finishWriting(ctx)
+			hj.probePartitions.finishWriting(ctx)
+			hj.curPartitionIdx = -1
+			hj.state = externalHJJoiningPartitions
+		case externalHJJoiningPartitions:
+			if hj.inMemJoiner != nil {
+				b := hj.inMemJoiner.Next(ctx)
+				if b.Length() > 0 {
+					return b
+				}
+				// Keep the zero-length batch around so that it could be returned
+				// once all of the partitions have been joined.
+				hj.zeroBatch = b
+				hj.inMemJoiner = nil
+			}
+			hj.curPartitionIdx++
+			if hj.curPartitionIdx == defaultNumDiskPartitions {
+				hj.close(ctx)
+				hj.state = externalHJFinished
+				continue
+			}
+			buildQueue := hj.buildPartitions.queues[hj.curPartitionIdx]
+			probeQueue := hj.probePartitions.queues[hj.curPartitionIdx]
+			if buildQueue.numTuples == 0 && probeQueue.numTuples == 0 {
+				continue
+			}
+			hj.inMemJoiner = hj.newInMemJoiner(buildQueue.newReader(), probeQueue.newReader())
+		case externalHJFinished:
+			return hj.zeroBatch
+		default:
+			execerror.VectorizedInternalPanic(fmt.Sprintf("unexpected externalHashJoinerState %d", hj.state))
+		}
+	}
+}
+
+// close releases the disk resources held by the external hash joiner.
+func (hj *externalHashJoiner) close(ctx context.Context) {
+	if hj.buildPartitions != nil {
+		hj.buildPartitions.close(ctx)
+		hj.buildPartitions = nil
+	}
+	if hj.probePartitions != nil {
+		hj.probePartitions.close(ctx)
+		hj.probePartitions = nil
+	}
+}
+
+// DrainMeta implements the MetadataSource interface. The external hash joiner
+// doesn't produce any metadata, but it uses this hook to release its disk
+// resources in case the query has been terminated before all of the output
+// was emitted.
+func (hj *externalHashJoiner) DrainMeta(ctx context.Context) []distsqlpb.ProducerMetadata {
+	hj.close(ctx)
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestExternalHashJoiner(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	factory := newTestDiskFactory(t)
+	defer factory.Close()
+
+	const (
+		nLeft  = 3000
+		nRight = 2000
+		// Only the keys in [0, nMatching) appear on both sides.
+		nMatching = 1000
+	)
+	var leftTups, rightTups tuples
+	for i := 0; i < nLeft; i++ {
+		leftTups = append(leftTups, tuple{i, i * 2})
+	}
+	for i := 0; i < nRight; i++ {
+		key := i
+		if i >= nMatching {
+			key = nLeft + i
+		}
+		rightTups = append(rightTups, tuple{key, i * 3})
+	}
+	// Add a NULL key on both sides that must not match.
+	leftTups = append(leftTups, tuple{nil, -1})
+	rightTups = append(rightTups, tuple{nil, -1})
+
+	typs := []coltypes.T{coltypes.Int64, coltypes.Int64}
+	for _, joinType := range []sqlbase.JoinType{
+		sqlbase.JoinType_INNER,
+		sqlbase.JoinType_LEFT_OUTER,
+		sqlbase.JoinType_FULL_OUTER,
+	} {
+		var expected tuples
+		for i := 0; i < nMatching; i++ {
+			expected = append(expected, tuple{i, i * 2, i, i * 3})
+		}
+		if joinType == sqlbase.JoinType_LEFT_OUTER || joinType == sqlbase.JoinType_FULL_OUTER {
+			for i := nMatching; i < nLeft; i++ {
+				expected = append(expected, tuple{i, i * 2, nil, nil})
+			}
+			expected = append(expected, tuple{nil, -1, nil, nil})
+		}
+		if joinType == sqlbase.JoinType_FULL_OUTER {
+			for i := nMatching; i < nRight; i++ {
+				expected = append(expected, tuple{nil, nil, nLeft + i, i * 3})
+			}
+			expected = append(expected, tuple{nil, nil, nil, -1})
+		}
+		// A memory limit of 1 byte forces the partitioning of the inputs right
+		// away, while the largest limit allows the join to be performed in
+		// memory.
+		for _, memoryLimit := range []int64{1, 1 << 12, 1 << 20} {
+			t.Run(fmt.Sprintf("%s/memoryLimit=%d", joinType, memoryLimit), func(t *testing.T) {
+				runTests(t, []tuples{leftTups, rightTups}, expected, unorderedVerifier, []int{0, 1, 2, 3},
+					func(sources []Operator) (Operator, error) {
+						return NewExternalHashJoiner(
+							sources[0], sources[1],
+							[]uint32{0}, []uint32{0},
+							[]uint32{0, 1}, []uint32{0, 1},
+							typs, typs,
+							true /* buildRightSide */, false, /* buildDistinct */
+							joinType, memoryLimit, factory,
+						)
+					})
+			})
+		}
+	}
+}
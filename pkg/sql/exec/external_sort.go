@@ -0,0 +1,188 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/execerror"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
+	"github.com/pkg/errors"
+)
+
+// externalSorterState represents the state of the external sorter.
+type externalSorterState int
+
+const (
+	// externalSorterSortingRun is the state in which the external sorter sorts
+	// the next memory-limited chunk of the input (a "run") in memory.
+	externalSorterSortingRun externalSorterState = iota
+	// externalSorterEmittingInMemory is the state in which the whole input fit
+	// within the memory limit and the in-memory sorter emits its output
+	// directly.
+	externalSorterEmittingInMemory
+	// externalSorterMerging is the state in which all of the sorted runs have
+	// been written to disk, and the external sorter sets up the merge of them.
+	externalSorterMerging
+	// externalSorterEmittingMerged is the state in which the external sorter
+	// emits the output of the merge.
+	externalSorterEmittingMerged
+	// externalSorterFinished is the state in which the external sorter has
+	// emitted all of its output and has released its disk resources.
+	externalSorterFinished
+)
+
+// externalSorter is an Operator that performs a general sort of its input,
+// spilling to disk when the input doesn't fit within the memory limit. It
+// works as follows:
+// 1. the input is split into chunks of limited size (using an
+//    inputPartitioningOperator), and each of the chunks is sorted in memory
+//    using the in-memory sorter;
+// 2. if the whole input fits within a single chunk, the output of the
+//    in-memory sorter is emitted directly;
+// 3. otherwise, every sorted chunk (a "run") is written to its own diskQueue,
+//    and, once the input has been exhausted, all of the runs are merged using
+//    an OrderedSynchronizer.
+// TODO: bound the number of runs that are merged at once and
+// perform a multi-pass merge when that number is exceeded.
+type externalSorter struct {
+	OneInputNode
+
+	inputTypes   []coltypes.T
+	orderingCols []distsqlpb.Ordering_Column
+	diskFactory  diskmap.Factory
+
+	state          externalSorterState
+	inputPartition *inputPartitioningOperator
+	inMemSorter    resettableOperator
+	runs           []*diskQueue
+	merger         Operator
+	zeroBatch      coldata.Batch
+}
+
+var _ Operator = &externalSorter{}
+var _ distsqlpb.MetadataSource = &externalSorter{}
+
+// NewExternalSorter returns a disk-backed general sort operator. It sorts its
+// input on the columns given in orderingCols, spilling to the temporary
+// storage provided by diskFactory when the input takes up more than
+// memoryLimit bytes. The inputTypes must correspond 1-1 with the columns in the
+// input operator.
+func NewExternalSorter(
+	input Operator,
+	inputTypes []coltypes.T,
+	orderingCols []distsqlpb.Ordering_Column,
+	memoryLimit int64,
+	diskFactory diskmap.Factory,
+) (Operator, error) {
+	if memoryLimit <= 0 {
+		return nil, errors.Errorf("external sorter requires a positive memory limit, %d given", memoryLimit)
+	}
+	if !CanSpillTypes(inputTypes) {
+		return nil, errors.Errorf("external sorter doesn't support spilling of some of the types %s", inputTypes)
+	}
+	inputPartition := newInputPartitioningOperator(input, inputTypes, int(memoryLimit))
+	inMemSorter, err := newSorter(newAllSpooler(inputPartition, inputTypes), inputTypes, orderingCols)
+	if err != nil {
+		return nil, err
+	}
+	return &externalSorter{
+		OneInputNode:   NewOneInputNode(inputPartition),
+		inputTypes:     inputTypes,
+		orderingCols:   orderingCols,
+		diskFactory:    diskFactory,
+		inputPartition: inputPartition,
+		inMemSorter:    inMemSorter,
+	}, nil
+}
+
+func (s *externalSorter) Init() {
+	s.inMemSorter.Init()
+	s.zeroBatch = coldata.NewMemBatchWithSize(s.inputTypes, 0 /* size */)
+}
+
+func (s *externalSorter) Next(ctx context.Context) coldata.Batch {
+	for {
+		switch s.state {
+		case externalSorterSortingRun:
+			b := s.inMemSorter.Next(ctx)
+			if s.inputPartition.exhausted && len(s.runs) == 0 {
+				// The whole input fit within the memory limit, so there is no
+				// need to spill.
+				s.state = externalSorterEmittingInMemory
+				return b
+			}
+			if b.Length() > 0 {
+				run, err := newDiskQueue(s.inputTypes, s.diskFactory)
+				if err != nil {
+					execerror.VectorizedInternalPanic(err)
+				}
+				s.runs = append(s.runs, run)
+				for ; b.Length() > 0; b = s.inMemSorter.Next(ctx) {
+					run.enqueue(b)
+				}
+				run.finishWriting(ctx)
+			}
+			if s.inputPartition.exhausted {
+				s.state = externalSorterMerging
+				continue
+			}
+			// Resetting the in-memory sorter resets the input partitioning
+			// operator as well, so the next run will consume the next chunk of
+			// the input.
+			s.inMemSorter.reset()
+		case externalSorterEmittingInMemory:
+			return s.inMemSorter.Next(ctx)
+		case externalSorterMerging:
+			readers := make([]Operator, len(s.runs))
+			for i, run := range s.runs {
+				readers[i] = run.newReader()
+			}
+			s.merger = NewOrderedSynchronizer(
+				readers, s.inputTypes, distsqlpb.ConvertToColumnOrdering(distsqlpb.Ordering{Columns: s.orderingCols}),
+			)
+			s.merger.Init()
+			s.state = externalSorterEmittingMerged
+		case externalSorterEmittingMerged:
+			b := s.merger.Next(ctx)
+			if b.Length() == 0 {
+				s.close(ctx)
+				s.state = externalSorterFinished
+			}
+			return b
+		case externalSorterFinished:
+			return s.zeroBatch
+		default:
+			execerror.VectorizedInternalPanic(fmt.Sprintf("unexpected externalSorterState %d", s.state))
+		}
+	}
+}
+
+// close releases the disk resources held by the external sorter.
+func (s *externalSorter) close(ctx context.Context) {
+	for _, run := range s.runs {
+		run.close(ctx)
+	}
+	s.runs = nil
+}
+
+// DrainMeta implements the MetadataSource interface. The external sorter
+// doesn't produce any metadata, but it uses this hook to release its disk
+// resources in case the query has been terminated before all of the output
+// was emitted.
+func (s *externalSorter) DrainMeta(ctx context.Context) []distsqlpb.ProducerMetadata {
+	s.close(ctx)
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func TestExternalSort(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	factory := newTestDiskFactory(t)
+	defer factory.Close()
+
+	rng, _ := randutil.NewPseudoRand()
+	const nTups = 2000
+	tups := make(tuples, nTups)
+	for i := range tups {
+		if rng.Intn(20) == 0 {
+			tups[i] = tuple{nil, i}
+		} else {
+			tups[i] = tuple{rng.Int63n(100), i}
+		}
+	}
+	expected := make(tuples, nTups)
+	copy(expected, tups)
+	// The second column is unique, so the expected order is well-defined.
+	sort.Slice(expected, func(i, j int) bool {
+		if expected[i][0] == nil || expected[j][0] == nil {
+			if expected[i][0] == nil && expected[j][0] == nil {
+				return expected[i][1].(int) > expected[j][1].(int)
+			}
+			return expected[i][0] == nil
+		}
+		a, b := expected[i][0].(int64), expected[j][0].(int64)
+		if a != b {
+			return a < b
+		}
+		return expected[i][1].(int) > expected[j][1].(int)
+	})
+	typs := []coltypes.T{coltypes.Int64, coltypes.Int64}
+	ordCols := []distsqlpb.Ordering_Column{
+		{ColIdx: 0},
+		{ColIdx: 1, Direction: distsqlpb.Ordering_Column_DESC},
+	}
+
+	// A memory limit of 1 byte forces a separate run for every input batch,
+	// while the largest limit allows the whole input to be sorted in memory.
+	for _, memoryLimit := range []int64{1, 1 << 12, 1 << 20} {
+		t.Run(fmt.Sprintf("memoryLimit=%d", memoryLimit), func(t *testing.T) {
+			runTests(t, []tuples{tups}, expected, orderedVerifier, []int{0, 1}, func(input []Operator) (Operator, error) {
+				return NewExternalSorter(input[0], typs, ordCols, memoryLimit, factory)
+			})
+		})
+	}
+}
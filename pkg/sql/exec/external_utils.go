@@ -0,0 +1,238 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
+)
+
+// defaultNumDiskPartitions is the number of partitions that the external
+// hash-based operators split their inputs into once they spill to disk.
+// TODO: make this a function of the memory limit and the input
+// size, and repartition recursively the partitions that still don't fit in
+// memory.
+const defaultNumDiskPartitions = 16
+
+// diskPartitionsHashSeed is the initial hash value used when assigning tuples
+// to disk partitions. It must be different from the seed that the hashTable
+// uses (see hashTable.initHash): otherwise, all of the tuples within a single
+// partition would share the low bits of their hash values, and the in-memory
+// hash table built over that partition would use only a small fraction of its
+// buckets.
+const diskPartitionsHashSeed = 0x9e3779b97f4a7c15
+
+// inputPartitioningOperator is an operator that returns the batches from its
+// input until the memory limit is reached, and then returns zero-length
+// batches. Once the operator is reset, it will emit the next chunk of the
+// input (while keeping the same limit). This allows an in-memory operator
+// placed on top of it to process the input one memory-limited chunk at a
+// time. Note that the limit is enforced on the estimated footprint of the
+// batches (see EstimateBatchSizeBytes) and that the last batch of each chunk
+// can go over the limit.
+type inputPartitioningOperator struct {
+	OneInputNode
+
+	inputTypes  []coltypes.T
+	memoryLimit int
+	// memoryUsage is the estimated footprint of the batches that have been
+	// emitted since the last reset.
+	memoryUsage int
+	// exhausted indicates whether the input has returned a zero-length batch.
+	exhausted bool
+
+	zeroBatch coldata.Batch
+}
+
+var _ resettableOperator = &inputPartitioningOperator{}
+
+func newInputPartitioningOperator(
+	input Operator, inputTypes []coltypes.T, memoryLimit int,
+) *inputPartitioningOperator {
+	return &inputPartitioningOperator{
+		OneInputNode: NewOneInputNode(input),
+		inputTypes:   inputTypes,
+		memoryLimit:  memoryLimit,
+	}
+}
+
+func (o *inputPartitioningOperator) Init() {
+	o.input.Init()
+	o.zeroBatch = coldata.NewMemBatchWithSize(o.inputTypes, 0 /* size */)
+}
+
+func (o *inputPartitioningOperator) Next(ctx context.Context) coldata.Batch {
+	// Every chunk must contain at least one batch in order to guarantee
+	// progress.
+	if o.exhausted || (o.memoryUsage > 0 && o.memoryUsage >= o.memoryLimit) {
+		return o.zeroBatch
+	}
+	b := o.input.Next(ctx)
+	if b.Length() == 0 {
+		o.exhausted = true
+		return b
+	}
+	o.memoryUsage += EstimateBatchSizeBytes(o.inputTypes, int(b.Length()))
+	return b
+}
+
+// reset resets the memory usage, so that the next chunk of the input can be
+// emitted. Note that the input is not reset.
+func (o *inputPartitioningOperator) reset() {
+	o.memoryUsage = 0
+}
+
+// spooledTuplesReader is an operator that emits all of the tuples spooled by
+// a spooler in batches of coldata.BatchSize.
+type spooledTuplesReader struct {
+	ZeroInputNode
+
+	spooler    spooler
+	inputTypes []coltypes.T
+	emitted    uint64
+	output     coldata.Batch
+}
+
+var _ Operator = &spooledTuplesReader{}
+
+func newSpooledTuplesReader(spooler spooler, inputTypes []coltypes.T) *spooledTuplesReader {
+	return &spooledTuplesReader{spooler: spooler, inputTypes: inputTypes}
+}
+
+// Init is part of the Operator interface. Note that the spooler is not
+// initialized since it is expected that it has already been spooled.
+func (r *spooledTuplesReader) Init() {
+	r.output = coldata.NewMemBatch(r.inputTypes)
+}
+
+func (r *spooledTuplesReader) Next(context.Context) coldata.Batch {
+	r.output.SetSelection(false)
+	toEmit := r.spooler.getNumTuples() - r.emitted
+	if toEmit > uint64(coldata.BatchSize) {
+		toEmit = uint64(coldata.BatchSize)
+	}
+	r.output.SetLength(uint16(toEmit))
+	if toEmit == 0 {
+		return r.output
+	}
+	for i, t := range r.inputTypes {
+		r.output.ColVec(i).Copy(
+			coldata.CopyArgs{
+				ColType:     t,
+				Src:         r.spooler.getValues(i),
+				SrcStartIdx: r.emitted,
+				SrcEndIdx:   r.emitted + toEmit,
+			},
+		)
+	}
+	r.emitted += toEmit
+	return r.output
+}
+
+// hashDiskPartitions splits the tuples it receives into a fixed number of
+// diskQueues according to the hash of the values in hashCols, so that the
+// tuples with equal values in those columns always end up in the same
+// partition.
+type hashDiskPartitions struct {
+	typs     []coltypes.T
+	hashCols []uint32
+	// ht is not fully initialized to a hashTable, only the utility methods are
+	// used.
+	ht     hashTable
+	queues []*diskQueue
+
+	scratch struct {
+		// buckets is scratch space for the computed hash value of each tuple in
+		// the current batch.
+		buckets []uint64
+		// selections is scratch space for the selection vectors of each
+		// partition.
+		selections [][]uint16
+	}
+}
+
+func newHashDiskPartitions(
+	typs []coltypes.T, hashCols []uint32, numPartitions int, factory diskmap.Factory,
+) (*hashDiskPartitions, error) {
+	p := &hashDiskPartitions{
+		typs:     typs,
+		hashCols: hashCols,
+		queues:   make([]*diskQueue, numPartitions),
+	}
+	for i := range p.queues {
+		q, err := newDiskQueue(typs, factory)
+		if err != nil {
+			p.close(context.TODO())
+			return nil, err
+		}
+		p.queues[i] = q
+	}
+	p.scratch.buckets = make([]uint64, coldata.BatchSize)
+	p.scratch.selections = make([][]uint16, numPartitions)
+	for i := range p.scratch.selections {
+		p.scratch.selections[i] = make([]uint16, 0, coldata.BatchSize)
+	}
+	return p, nil
+}
+
+// add distributes the tuples of the batch among the partitions.
+func (p *hashDiskPartitions) add(ctx context.Context, b coldata.Batch) {
+	n := b.Length()
+	if n == 0 {
+		return
+	}
+	buckets := p.scratch.buckets[:n]
+	for i := range buckets {
+		buckets[i] = diskPartitionsHashSeed
+	}
+	for _, colIdx := range p.hashCols {
+		p.ht.rehash(ctx, buckets, int(colIdx), p.typs[colIdx], b.ColVec(int(colIdx)), uint64(n), b.Selection())
+	}
+	for i := range p.scratch.selections {
+		p.scratch.selections[i] = p.scratch.selections[i][:0]
+	}
+	numPartitions := uint64(len(p.queues))
+	if sel := b.Selection(); sel != nil {
+		for i, selIdx := range sel[:n] {
+			partitionIdx := buckets[i] % numPartitions
+			p.scratch.selections[partitionIdx] = append(p.scratch.selections[partitionIdx], selIdx)
+		}
+	} else {
+		for i, hash := range buckets {
+			partitionIdx := hash % numPartitions
+			p.scratch.selections[partitionIdx] = append(p.scratch.selections[partitionIdx], uint16(i))
+		}
+	}
+	for i, q := range p.queues {
+		q.enqueueSelected(b, p.scratch.selections[i])
+	}
+}
+
+// finishWriting flushes all of the partitions. add must not be called
+// afterwards.
+func (p *hashDiskPartitions) finishWriting(ctx context.Context) {
+	for _, q := range p.queues {
+		q.finishWriting(ctx)
+	}
+}
+
+// close releases the resources held by all of the partitions.
+func (p *hashDiskPartitions) close(ctx context.Context) {
+	for _, q := range p.queues {
+		if q != nil {
+			q.close(ctx)
+		}
+	}
+	p.queues = nil
+}
@@ -12,20 +12,24 @@ package exec
 
 import (
 	"fmt"
+	"time"
 	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/execerror"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 )
 
 const (
-	sizeOfBool    = int(unsafe.Sizeof(true))
-	sizeOfInt8    = int(unsafe.Sizeof(int8(0)))
-	sizeOfInt16   = int(unsafe.Sizeof(int16(0)))
-	sizeOfInt32   = int(unsafe.Sizeof(int32(0)))
-	sizeOfInt64   = int(unsafe.Sizeof(int64(0)))
-	sizeOfFloat32 = int(unsafe.Sizeof(float32(0)))
-	sizeOfFloat64 = int(unsafe.Sizeof(float64(0)))
+	sizeOfBool     = int(unsafe.Sizeof(true))
+	sizeOfInt8     = int(unsafe.Sizeof(int8(0)))
+	sizeOfInt16    = int(unsafe.Sizeof(int16(0)))
+	sizeOfInt32    = int(unsafe.Sizeof(int32(0)))
+	sizeOfInt64    = int(unsafe.Sizeof(int64(0)))
+	sizeOfFloat32  = int(unsafe.Sizeof(float32(0)))
+	sizeOfFloat64  = int(unsafe.Sizeof(float64(0)))
+	sizeOfTime     = int(unsafe.Sizeof(time.Time{}))
+	sizeOfDuration = int(unsafe.Sizeof(duration.Duration{}))
 )
 
 // EstimateBatchSizeBytes returns an estimated amount of bytes needed to
@@ -61,6 +65,12 @@ func EstimateBatchSizeBytes(vecTypes []coltypes.T, batchLength int) int {
 			// Similar to byte arrays, we can't tell how much space is used
 			// to hold the arbitrary precision decimal objects.
 			acc += 50
+		case coltypes.Timestamp:
+			// time.Time keeps a pointer to its location, but the locations are
+			// shared, so we only account for the struct itself.
+			acc += sizeOfTime
+		case coltypes.Interval:
+			acc += sizeOfDuration
 		default:
 			execerror.VectorizedInternalPanic(fmt.Sprintf("unhandled type %s", t))
 		}
@@ -14,10 +14,12 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/execerror"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 )
 
 // maxVarLen specifies a length limit for variable length types (e.g. byte slices).
@@ -94,6 +96,16 @@ func randomVec(rng *rand.Rand, typ coltypes.T, vec coldata.Vec, n int, nullProba
 		for i := 0; i < n; i++ {
 			floats[i] = rng.Float64()
 		}
+	case coltypes.Timestamp:
+		timestamps := vec.Timestamp()
+		for i := 0; i < n; i++ {
+			timestamps[i] = time.Unix(rng.Int63n(2000000000), rng.Int63n(1000000)*1000).UTC()
+		}
+	case coltypes.Interval:
+		intervals := vec.Interval()
+		for i := 0; i < n; i++ {
+			intervals[i] = duration.MakeDuration(rng.Int63n(1000000000000), rng.Int63n(1000), rng.Int63n(100))
+		}
 	default:
 		execerror.VectorizedInternalPanic(fmt.Sprintf("unhandled type %s", typ))
 	}
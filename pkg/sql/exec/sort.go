@@ -156,6 +156,7 @@ func (p *allSpooler) getPartitionsCol() []bool {
 }
 
 func (p *allSpooler) reset() {
+	p.spooled = false
 	p.spooledTuples = 0
 	if r, ok := p.input.(resetter); ok {
 		r.reset()
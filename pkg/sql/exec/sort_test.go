@@ -17,11 +17,13 @@ import (
 	"math/rand"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/col/coldata"
 	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/execerror"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
 )
@@ -107,6 +109,24 @@ func TestSort(t *testing.T) {
 			typ:      []coltypes.T{coltypes.Float64},
 			ordCols:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
 		},
+		{
+			tuples:   tuples{{time.Unix(3, 0)}, {nil}, {time.Unix(1, 5)}, {time.Unix(1, 0)}, {time.Unix(-2, 0)}},
+			expected: tuples{{nil}, {time.Unix(-2, 0)}, {time.Unix(1, 0)}, {time.Unix(1, 5)}, {time.Unix(3, 0)}},
+			typ:      []coltypes.T{coltypes.Timestamp},
+			ordCols:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
+		},
+		{
+			tuples: tuples{
+				{duration.MakeDuration(0, 29, 0)}, {duration.MakeDuration(int64(time.Hour), 0, 0)},
+				{duration.MakeDuration(0, 0, 1)}, {nil}, {duration.MakeDuration(0, 31, 0)},
+			},
+			expected: tuples{
+				{duration.MakeDuration(0, 31, 0)}, {duration.MakeDuration(0, 0, 1)},
+				{duration.MakeDuration(0, 29, 0)}, {duration.MakeDuration(int64(time.Hour), 0, 0)}, {nil},
+			},
+			typ:     []coltypes.T{coltypes.Interval},
+			ordCols: []distsqlpb.Ordering_Column{{ColIdx: 0, Direction: distsqlpb.Ordering_Column_DESC}},
+		},
 
 		{
 			tuples:   tuples{{0, 1, 0}, {1, 2, 0}, {2, 3, 2}, {3, 7, 1}, {4, 2, 2}},
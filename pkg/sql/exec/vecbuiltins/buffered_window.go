@@ -0,0 +1,361 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vecbuiltins
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/pkg/errors"
+)
+
+// windowPartition describes a single partition of the tuples buffered by
+// bufferedWindowOp. All of the slices are indexed by the position of the tuple
+// among all of the buffered tuples (not within the partition).
+type windowPartition struct {
+	// vecs are the buffered columns.
+	vecs []coldata.Vec
+	// start and end are the (exclusive) bounds of the partition.
+	start, end int
+	// peerStarts and peerEnds contain the (exclusive) bounds of the peer group
+	// (i.e. the tuples that are equal on the ordering columns) that each tuple
+	// belongs to.
+	peerStarts, peerEnds []int
+	// frameStarts and frameEnds contain the (exclusive) bounds of the window
+	// frame of each tuple. They are populated only if the window function uses
+	// the window frame. Note that a frame can be empty in which case the start
+	// might be greater than the end.
+	frameStarts, frameEnds []int
+}
+
+// bufferedWindowFn is a window function that needs to see the whole partition
+// in order to compute the output for any of its tuples.
+type bufferedWindowFn interface {
+	// compute writes the output of the window function for every tuple of the
+	// partition p into output.
+	compute(p *windowPartition, output coldata.Vec)
+}
+
+// bufferedWindowOpState represents the state of the bufferedWindowOp.
+type bufferedWindowOpState int
+
+const (
+	// bufferedWindowBuffering is the state in which the operator buffers all of
+	// its input.
+	bufferedWindowBuffering bufferedWindowOpState = iota
+	// bufferedWindowEmitting is the state in which the operator emits the
+	// buffered tuples together with the output of the window function.
+	bufferedWindowEmitting
+)
+
+// bufferedWindowOp is an exec.Operator that computes a window function that
+// cannot be computed in a streaming fashion (unlike ROW_NUMBER and RANK). It
+// buffers all of its input, which *must* already be ordered on the partition
+// and the ordering columns, computes the window function for each of the
+// partitions, and then emits the buffered tuples with the output column
+// appended. Note that in the vectorized engine the input to the window
+// functions is fully sorted (and, thus, buffered) anyway.
+type bufferedWindowOp struct {
+	exec.OneInputNode
+
+	// inputTypes are the types of the columns of the input batches, including
+	// the temporary partition column if there is one.
+	inputTypes      []coltypes.T
+	outputType      coltypes.T
+	outputColIdx    int
+	partitionColIdx int
+	// peersCol is the output column of the chain of ordered distinct
+	// operators in which true indicates that the corresponding tuple is not a
+	// peer of the previous one. It is nil when there are no ordering columns,
+	// in which case all of the tuples of a partition are peers.
+	peersCol []bool
+	// frame is the window frame used by fn. It is nil if fn doesn't use the
+	// window frame.
+	frame *distsqlpb.WindowerSpec_Frame
+	fn    bufferedWindowFn
+
+	state     bufferedWindowOpState
+	buffered  []coldata.Vec
+	numTuples int
+	// newPeerGroup[i] is true if the ith buffered tuple starts a new peer
+	// group.
+	newPeerGroup []bool
+	output       coldata.Vec
+	emitted      int
+	batch        coldata.Batch
+}
+
+var _ exec.Operator = &bufferedWindowOp{}
+
+func newBufferedWindowOp(
+	input exec.Operator,
+	inputTyps []coltypes.T,
+	outputType coltypes.T,
+	orderingCols []uint32,
+	outputColIdx int,
+	partitionColIdx int,
+	frame *distsqlpb.WindowerSpec_Frame,
+	fn bufferedWindowFn,
+) (exec.Operator, error) {
+	typs := inputTyps
+	if partitionColIdx != -1 {
+		typs = append(typs[:len(typs):len(typs)], coltypes.Bool)
+	}
+	if outputColIdx != len(typs) {
+		return nil, errors.Errorf(
+			"window function output column %d doesn't follow the input columns %s", outputColIdx, typs,
+		)
+	}
+	var peersCol []bool
+	if len(orderingCols) > 0 {
+		var err error
+		input, peersCol, err = exec.OrderedDistinctColsToOperators(input, orderingCols, inputTyps)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buffered := make([]coldata.Vec, len(typs))
+	for i, t := range typs {
+		buffered[i] = coldata.NewMemColumn(t, 0 /* n */)
+	}
+	return &bufferedWindowOp{
+		OneInputNode:    exec.NewOneInputNode(input),
+		inputTypes:      typs,
+		outputType:      outputType,
+		outputColIdx:    outputColIdx,
+		partitionColIdx: partitionColIdx,
+		peersCol:        peersCol,
+		frame:           frame,
+		fn:              fn,
+		buffered:        buffered,
+	}, nil
+}
+
+func (w *bufferedWindowOp) Init() {
+	w.Input().Init()
+	w.batch = coldata.NewMemBatch(append(w.inputTypes[:len(w.inputTypes):len(w.inputTypes)], w.outputType))
+}
+
+func (w *bufferedWindowOp) Next(ctx context.Context) coldata.Batch {
+	if w.state == bufferedWindowBuffering {
+		w.buffer(ctx)
+		w.computeOutput()
+		w.state = bufferedWindowEmitting
+	}
+	w.batch.SetSelection(false)
+	toEmit := w.numTuples - w.emitted
+	if toEmit > int(coldata.BatchSize) {
+		toEmit = int(coldata.BatchSize)
+	}
+	w.batch.SetLength(uint16(toEmit))
+	if toEmit == 0 {
+		return w.batch
+	}
+	for i, t := range w.inputTypes {
+		w.batch.ColVec(i).Copy(
+			coldata.CopyArgs{
+				ColType:     t,
+				Src:         w.buffered[i],
+				SrcStartIdx: uint64(w.emitted),
+				SrcEndIdx:   uint64(w.emitted + toEmit),
+			},
+		)
+	}
+	w.batch.ColVec(w.outputColIdx).Copy(
+		coldata.CopyArgs{
+			ColType:     w.outputType,
+			Src:         w.output,
+			SrcStartIdx: uint64(w.emitted),
+			SrcEndIdx:   uint64(w.emitted + toEmit),
+		},
+	)
+	w.emitted += toEmit
+	return w.batch
+}
+
+// buffer reads all of the input and appends it to the buffered columns.
+func (w *bufferedWindowOp) buffer(ctx context.Context) {
+	for batch := w.Input().Next(ctx); batch.Length() > 0; batch = w.Input().Next(ctx) {
+		n := batch.Length()
+		sel := batch.Selection()
+		for i, t := range w.inputTypes {
+			w.buffered[i].Append(
+				coldata.AppendArgs{
+					ColType:   t,
+					Src:       batch.ColVec(i),
+					Sel:       sel,
+					DestIdx:   uint64(w.numTuples),
+					SrcEndIdx: n,
+				},
+			)
+		}
+		if w.peersCol != nil {
+			if sel != nil {
+				for _, i := range sel[:n] {
+					w.newPeerGroup = append(w.newPeerGroup, w.peersCol[i])
+				}
+			} else {
+				w.newPeerGroup = append(w.newPeerGroup, w.peersCol[:n]...)
+			}
+		}
+		w.numTuples += int(n)
+	}
+	if w.peersCol == nil {
+		w.newPeerGroup = make([]bool, w.numTuples)
+	}
+}
+
+// computeOutput splits the buffered tuples into partitions and computes the
+// window function for each of them.
+func (w *bufferedWindowOp) computeOutput() {
+	n := w.numTuples
+	w.output = coldata.NewMemColumn(w.outputType, n)
+	if n == 0 {
+		return
+	}
+	p := windowPartition{
+		vecs:       w.buffered,
+		peerStarts: make([]int, n),
+		peerEnds:   make([]int, n),
+	}
+	if w.frame != nil {
+		p.frameStarts = make([]int, n)
+		p.frameEnds = make([]int, n)
+	}
+	var partitionCol []bool
+	if w.partitionColIdx != -1 {
+		partitionCol = w.buffered[w.partitionColIdx].Bool()
+	}
+	for p.start = 0; p.start < n; p.start = p.end {
+		p.end = p.start + 1
+		if partitionCol != nil {
+			for p.end < n && !partitionCol[p.end] {
+				p.end++
+			}
+		} else {
+			p.end = n
+		}
+		w.computePeerGroups(&p)
+		if w.frame != nil {
+			computeFrames(w.frame, &p)
+		}
+		w.fn.compute(&p, w.output)
+	}
+}
+
+// computePeerGroups populates the bounds of the peer groups of the tuples in
+// p. The first tuple of the partition always starts a new peer group.
+func (w *bufferedWindowOp) computePeerGroups(p *windowPartition) {
+	peerStart := p.start
+	for i := p.start; i < p.end; i++ {
+		if i > p.start && w.newPeerGroup[i] {
+			for j := peerStart; j < i; j++ {
+				p.peerEnds[j] = i
+			}
+			peerStart = i
+		}
+		p.peerStarts[i] = peerStart
+	}
+	for j := peerStart; j < p.end; j++ {
+		p.peerEnds[j] = p.end
+	}
+}
+
+// checkFrameIsSupported returns an error if the window frame cannot be
+// handled by the vectorized engine. Only ROWS frames and RANGE frames without
+// offsets (both without exclusion) are currently supported. A nil frame (the
+// default one) is always supported.
+func checkFrameIsSupported(frame *distsqlpb.WindowerSpec_Frame) error {
+	if frame == nil {
+		return nil
+	}
+	if frame.Exclusion != distsqlpb.WindowerSpec_Frame_NO_EXCLUSION {
+		return errors.Errorf("window frame exclusion %s is not supported", frame.Exclusion)
+	}
+	switch frame.Mode {
+	case distsqlpb.WindowerSpec_Frame_ROWS:
+		return nil
+	case distsqlpb.WindowerSpec_Frame_RANGE:
+		isOffset := func(b distsqlpb.WindowerSpec_Frame_BoundType) bool {
+			return b == distsqlpb.WindowerSpec_Frame_OFFSET_PRECEDING ||
+				b == distsqlpb.WindowerSpec_Frame_OFFSET_FOLLOWING
+		}
+		if isOffset(frame.Bounds.Start.BoundType) ||
+			(frame.Bounds.End != nil && isOffset(frame.Bounds.End.BoundType)) {
+			return errors.Errorf("RANGE window frames with offsets are not supported")
+		}
+		return nil
+	default:
+		return errors.Errorf("window frame mode %s is not supported", frame.Mode)
+	}
+}
+
+// computeFrames populates the bounds of the window frames of the tuples in p.
+// frame must have been verified with checkFrameIsSupported. Note that the
+// bounds of the frames are non-decreasing, which some of the window functions
+// rely on.
+func computeFrames(frame *distsqlpb.WindowerSpec_Frame, p *windowPartition) {
+	rows := frame.Mode == distsqlpb.WindowerSpec_Frame_ROWS
+	// The end bound defaults to CURRENT ROW.
+	end := distsqlpb.WindowerSpec_Frame_Bound{BoundType: distsqlpb.WindowerSpec_Frame_CURRENT_ROW}
+	if frame.Bounds.End != nil {
+		end = *frame.Bounds.End
+	}
+	for i := p.start; i < p.end; i++ {
+		switch frame.Bounds.Start.BoundType {
+		case distsqlpb.WindowerSpec_Frame_UNBOUNDED_PRECEDING:
+			p.frameStarts[i] = p.start
+		case distsqlpb.WindowerSpec_Frame_OFFSET_PRECEDING:
+			p.frameStarts[i] = p.start
+			if offset := frame.Bounds.Start.IntOffset; offset < uint64(i-p.start) {
+				p.frameStarts[i] = i - int(offset)
+			}
+		case distsqlpb.WindowerSpec_Frame_CURRENT_ROW:
+			p.frameStarts[i] = i
+			if !rows {
+				p.frameStarts[i] = p.peerStarts[i]
+			}
+		case distsqlpb.WindowerSpec_Frame_OFFSET_FOLLOWING:
+			p.frameStarts[i] = p.end
+			if offset := frame.Bounds.Start.IntOffset; offset < uint64(p.end-i) {
+				p.frameStarts[i] = i + int(offset)
+			}
+		default:
+			// UNBOUNDED FOLLOWING is not allowed as the start bound.
+			p.frameStarts[i] = p.end
+		}
+		switch end.BoundType {
+		case distsqlpb.WindowerSpec_Frame_UNBOUNDED_PRECEDING:
+			// UNBOUNDED PRECEDING is not allowed as the end bound.
+			p.frameEnds[i] = p.start
+		case distsqlpb.WindowerSpec_Frame_OFFSET_PRECEDING:
+			p.frameEnds[i] = p.start
+			if offset := end.IntOffset; offset <= uint64(i-p.start) {
+				p.frameEnds[i] = i - int(offset) + 1
+			}
+		case distsqlpb.WindowerSpec_Frame_CURRENT_ROW:
+			p.frameEnds[i] = i + 1
+			if !rows {
+				p.frameEnds[i] = p.peerEnds[i]
+			}
+		case distsqlpb.WindowerSpec_Frame_OFFSET_FOLLOWING:
+			p.frameEnds[i] = p.end
+			if offset := end.IntOffset; offset < uint64(p.end-i-1) {
+				p.frameEnds[i] = i + int(offset) + 1
+			}
+		case distsqlpb.WindowerSpec_Frame_UNBOUNDED_FOLLOWING:
+			p.frameEnds[i] = p.end
+		}
+	}
+}
@@ -0,0 +1,618 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vecbuiltins
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/execerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/pkg/errors"
+)
+
+// defaultFrame is the window frame that is used when none is specified: RANGE
+// BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW.
+var defaultFrame = distsqlpb.WindowerSpec_Frame{
+	Mode: distsqlpb.WindowerSpec_Frame_RANGE,
+	Bounds: distsqlpb.WindowerSpec_Frame_Bounds{
+		Start: distsqlpb.WindowerSpec_Frame_Bound{BoundType: distsqlpb.WindowerSpec_Frame_UNBOUNDED_PRECEDING},
+		End:   &distsqlpb.WindowerSpec_Frame_Bound{BoundType: distsqlpb.WindowerSpec_Frame_CURRENT_ROW},
+	},
+}
+
+// NewBufferedWindowOperator creates a new exec.Operator that computes one of
+// the window functions that need to buffer the whole partition: PERCENT_RANK,
+// CUME_DIST, NTILE, LAG, LEAD, FIRST_VALUE, LAST_VALUE, or one of the SUM, AVG,
+// MIN, and MAX aggregate functions over the window frame. input *must* already
+// be ordered on the partition and the ordering columns. argsIdxs specify the
+// columns of the arguments of the function, and outputType is the type of its
+// result. outputColIdx specifies in which exec.Vec the operator should put its
+// output, and it must be equal to the number of the input columns (including
+// the temporary partition column if there is one).
+func NewBufferedWindowOperator(
+	input exec.Operator,
+	inputTyps []coltypes.T,
+	windowFn distsqlpb.WindowerSpec_Func,
+	argsIdxs []uint32,
+	orderingCols []uint32,
+	frame *distsqlpb.WindowerSpec_Frame,
+	outputType coltypes.T,
+	outputColIdx int,
+	partitionColIdx int,
+) (exec.Operator, error) {
+	argTypes := make([]coltypes.T, len(argsIdxs))
+	for i, idx := range argsIdxs {
+		if int(idx) >= len(inputTyps) {
+			return nil, errors.Errorf("window function argument column %d is out of range", idx)
+		}
+		argTypes[i] = inputTyps[idx]
+	}
+	if err := checkFrameIsSupported(frame); err != nil {
+		return nil, err
+	}
+	if frame == nil {
+		frame = &defaultFrame
+	}
+	var fn bufferedWindowFn
+	// usesFrame indicates whether the window function depends on the window
+	// frame (the ones that don't are computed over the whole partition).
+	usesFrame := false
+	if windowFn.AggregateFunc != nil {
+		if len(argsIdxs) != 1 {
+			return nil, errors.Errorf("aggregate window function %s expects a single argument", windowFn.AggregateFunc)
+		}
+		usesFrame = true
+		switch *windowFn.AggregateFunc {
+		case distsqlpb.AggregatorSpec_SUM, distsqlpb.AggregatorSpec_AVG:
+			avg := *windowFn.AggregateFunc == distsqlpb.AggregatorSpec_AVG
+			switch argTypes[0] {
+			case coltypes.Int16, coltypes.Int32, coltypes.Int64, coltypes.Decimal, coltypes.Float64:
+			default:
+				return nil, errors.Errorf("%s window function over %s is not supported", windowFn.AggregateFunc, argTypes[0])
+			}
+			fn = &sumAvgWindowFn{argIdx: int(argsIdxs[0]), argType: argTypes[0], avg: avg}
+		case distsqlpb.AggregatorSpec_MIN, distsqlpb.AggregatorSpec_MAX:
+			fn = &minMaxWindowFn{
+				argIdx:  int(argsIdxs[0]),
+				argType: argTypes[0],
+				max:     *windowFn.AggregateFunc == distsqlpb.AggregatorSpec_MAX,
+			}
+		default:
+			return nil, errors.Errorf("aggregate window function %s is not supported", windowFn.AggregateFunc)
+		}
+	} else if windowFn.WindowFunc != nil {
+		switch *windowFn.WindowFunc {
+		case distsqlpb.WindowerSpec_PERCENT_RANK:
+			fn = percentRankWindowFn{}
+		case distsqlpb.WindowerSpec_CUME_DIST:
+			fn = cumeDistWindowFn{}
+		case distsqlpb.WindowerSpec_NTILE:
+			if len(argsIdxs) != 1 || argTypes[0] != coltypes.Int64 {
+				return nil, errors.Errorf("ntile expects a single INT argument")
+			}
+			fn = ntileWindowFn{argIdx: int(argsIdxs[0])}
+		case distsqlpb.WindowerSpec_LAG, distsqlpb.WindowerSpec_LEAD:
+			if len(argsIdxs) == 0 || len(argsIdxs) > 3 {
+				return nil, errors.Errorf("%s expects between one and three arguments", windowFn.WindowFunc)
+			}
+			f := &leadLagWindowFn{
+				forward:    *windowFn.WindowFunc == distsqlpb.WindowerSpec_LEAD,
+				argIdx:     int(argsIdxs[0]),
+				argType:    argTypes[0],
+				offsetIdx:  -1,
+				defaultIdx: -1,
+			}
+			if len(argsIdxs) > 1 {
+				if argTypes[1] != coltypes.Int64 {
+					return nil, errors.Errorf("%s expects an INT offset", windowFn.WindowFunc)
+				}
+				f.offsetIdx = int(argsIdxs[1])
+			}
+			if len(argsIdxs) > 2 {
+				if argTypes[2] != argTypes[0] {
+					return nil, errors.Errorf("%s expects the default value of type %s", windowFn.WindowFunc, argTypes[0])
+				}
+				f.defaultIdx = int(argsIdxs[2])
+			}
+			fn = f
+		case distsqlpb.WindowerSpec_FIRST_VALUE, distsqlpb.WindowerSpec_LAST_VALUE:
+			if len(argsIdxs) != 1 {
+				return nil, errors.Errorf("%s expects a single argument", windowFn.WindowFunc)
+			}
+			usesFrame = true
+			fn = &firstLastValueWindowFn{
+				last:    *windowFn.WindowFunc == distsqlpb.WindowerSpec_LAST_VALUE,
+				argIdx:  int(argsIdxs[0]),
+				argType: argTypes[0],
+			}
+		default:
+			return nil, errors.Errorf("window function %s is not supported", windowFn.WindowFunc)
+		}
+	} else {
+		return nil, errors.Errorf("function is neither an aggregate nor a window function")
+	}
+	if !usesFrame {
+		frame = nil
+	}
+	return newBufferedWindowOp(
+		input, inputTyps, outputType, orderingCols, outputColIdx, partitionColIdx, frame, fn,
+	)
+}
+
+// percentRankWindowFn computes the relative rank of the tuples: (rank - 1) /
+// (number of tuples in the partition - 1).
+type percentRankWindowFn struct{}
+
+func (percentRankWindowFn) compute(p *windowPartition, output coldata.Vec) {
+	outputCol := output.Float64()
+	if p.end-p.start <= 1 {
+		for i := p.start; i < p.end; i++ {
+			outputCol[i] = 0
+		}
+		return
+	}
+	denominator := float64(p.end - p.start - 1)
+	for i := p.start; i < p.end; i++ {
+		// The rank of the tuple is one plus the number of tuples that precede
+		// its peer group, so we don't need to subtract anything.
+		outputCol[i] = float64(p.peerStarts[i]-p.start) / denominator
+	}
+}
+
+// cumeDistWindowFn computes the relative rank of the tuples: (number of tuples
+// preceding or peer with the current tuple) / (number of tuples in the
+// partition).
+type cumeDistWindowFn struct{}
+
+func (cumeDistWindowFn) compute(p *windowPartition, output coldata.Vec) {
+	outputCol := output.Float64()
+	denominator := float64(p.end - p.start)
+	for i := p.start; i < p.end; i++ {
+		outputCol[i] = float64(p.peerEnds[i]-p.start) / denominator
+	}
+}
+
+var errInvalidArgumentForNtile = pgerror.Newf(
+	pgcode.InvalidParameterValue, "argument of ntile() must be greater than zero")
+
+// ntileWindowFn divides the partition as evenly as possible into the given
+// number of buckets and computes the bucket of each tuple. The number of
+// buckets is taken from the first tuple that has a non-null argument, and all
+// of the tuples before it are assigned NULL.
+type ntileWindowFn struct {
+	argIdx int
+}
+
+func (f ntileWindowFn) compute(p *windowPartition, output coldata.Vec) {
+	outputCol, outputNulls := output.Int64(), output.Nulls()
+	arg := p.vecs[f.argIdx]
+	argCol, argNulls := arg.Int64(), arg.Nulls()
+	i := p.start
+	for ; i < p.end && argNulls.NullAt64(uint64(i)); i++ {
+		outputNulls.SetNull64(uint64(i))
+	}
+	if i == p.end {
+		return
+	}
+	numBuckets := argCol[i]
+	if numBuckets <= 0 {
+		execerror.NonVectorizedPanic(errInvalidArgumentForNtile)
+	}
+	// Note that we're using the size of the whole partition (rather than the
+	// number of the remaining tuples) in order to be consistent with the row
+	// engine.
+	total := int64(p.end - p.start)
+	boundary, remainder := total/numBuckets, int64(0)
+	if boundary <= 0 {
+		boundary = 1
+	} else {
+		// If the total number is not divisible, add 1 row to leading buckets.
+		remainder = total % numBuckets
+		if remainder != 0 {
+			boundary++
+		}
+	}
+	ntile, curBucketCount := int64(1), int64(0)
+	for ; i < p.end; i++ {
+		curBucketCount++
+		if boundary < curBucketCount {
+			// Move to the next ntile bucket.
+			if remainder != 0 && ntile == remainder {
+				remainder = 0
+				boundary--
+			}
+			ntile++
+			curBucketCount = 1
+		}
+		outputCol[i] = ntile
+	}
+}
+
+// leadLagWindowFn returns the value of the argument evaluated at the tuple
+// that is offset tuples after (LEAD) or before (LAG) the current one within
+// the partition. If there is no such tuple, the default value (or NULL if it
+// is not given) is returned instead. If the offset is NULL, the result is
+// NULL.
+type leadLagWindowFn struct {
+	forward bool
+	argIdx  int
+	argType coltypes.T
+	// offsetIdx and defaultIdx are the indices of the columns with the offset
+	// and the default value, or -1 if those are not given (in which case the
+	// offset is 1 and the default value is NULL).
+	offsetIdx  int
+	defaultIdx int
+
+	scratch struct {
+		sel  []uint64
+		nils []bool
+		// useDefault contains the indices of the tuples for which the default
+		// value must be used.
+		useDefault []int
+	}
+}
+
+func (f *leadLagWindowFn) compute(p *windowPartition, output coldata.Vec) {
+	n := p.end - p.start
+	f.scratch.sel = ensureUint64s(f.scratch.sel, n)
+	f.scratch.nils = ensureBools(f.scratch.nils, n)
+	f.scratch.useDefault = f.scratch.useDefault[:0]
+	var offsetCol []int64
+	var offsetNulls *coldata.Nulls
+	if f.offsetIdx != -1 {
+		offsetCol, offsetNulls = p.vecs[f.offsetIdx].Int64(), p.vecs[f.offsetIdx].Nulls()
+	}
+	for i := p.start; i < p.end; i++ {
+		f.scratch.sel[i-p.start], f.scratch.nils[i-p.start] = uint64(i), true
+		offset := int64(1)
+		if offsetCol != nil {
+			if offsetNulls.NullAt64(uint64(i)) {
+				continue
+			}
+			offset = offsetCol[i]
+		}
+		if !f.forward {
+			offset = -offset
+		}
+		// The offset can be arbitrary, so we need to be careful to not overflow
+		// when computing the index of the target tuple.
+		if (offset >= 0 && offset < int64(p.end-i)) || (offset < 0 && offset >= -int64(i-p.start)) {
+			f.scratch.sel[i-p.start], f.scratch.nils[i-p.start] = uint64(int64(i)+offset), false
+		} else if f.defaultIdx != -1 {
+			f.scratch.useDefault = append(f.scratch.useDefault, i)
+		}
+	}
+	output.Copy(
+		coldata.CopyArgs{
+			ColType:   f.argType,
+			Src:       p.vecs[f.argIdx],
+			Sel64:     f.scratch.sel,
+			Nils:      f.scratch.nils,
+			DestIdx:   uint64(p.start),
+			SrcEndIdx: uint64(n),
+		},
+	)
+	for _, i := range f.scratch.useDefault {
+		output.Copy(
+			coldata.CopyArgs{
+				ColType:     f.argType,
+				Src:         p.vecs[f.defaultIdx],
+				DestIdx:     uint64(i),
+				SrcStartIdx: uint64(i),
+				SrcEndIdx:   uint64(i + 1),
+			},
+		)
+	}
+}
+
+// firstLastValueWindowFn returns the value of the argument evaluated at the
+// first (FIRST_VALUE) or the last (LAST_VALUE) tuple of the window frame, or
+// NULL if the frame is empty.
+type firstLastValueWindowFn struct {
+	last    bool
+	argIdx  int
+	argType coltypes.T
+
+	scratch struct {
+		sel  []uint64
+		nils []bool
+	}
+}
+
+func (f *firstLastValueWindowFn) compute(p *windowPartition, output coldata.Vec) {
+	n := p.end - p.start
+	f.scratch.sel = ensureUint64s(f.scratch.sel, n)
+	f.scratch.nils = ensureBools(f.scratch.nils, n)
+	for i := p.start; i < p.end; i++ {
+		start, end := p.frameStarts[i], p.frameEnds[i]
+		f.scratch.sel[i-p.start], f.scratch.nils[i-p.start] = uint64(i), start >= end
+		if start < end {
+			f.scratch.sel[i-p.start] = uint64(start)
+			if f.last {
+				f.scratch.sel[i-p.start] = uint64(end - 1)
+			}
+		}
+	}
+	output.Copy(
+		coldata.CopyArgs{
+			ColType:   f.argType,
+			Src:       p.vecs[f.argIdx],
+			Sel64:     f.scratch.sel,
+			Nils:      f.scratch.nils,
+			DestIdx:   uint64(p.start),
+			SrcEndIdx: uint64(n),
+		},
+	)
+}
+
+// sumAvgWindowFn computes SUM or AVG of the argument over the window frame.
+// Integer arguments are summed up as decimals (like in the row engine). It
+// keeps a running sum of the frame, adding the values that enter it and
+// subtracting the ones that leave it, in the same order as the row engine does
+// so that both produce the same results. NaNs and infinities are counted
+// rather than summed, since they can't be subtracted once they leave the
+// frame.
+type sumAvgWindowFn struct {
+	argIdx  int
+	argType coltypes.T
+	avg     bool
+
+	scratch struct {
+		// counts[i] is the number of non-null values among the first i values
+		// of the partition.
+		counts []int
+	}
+}
+
+func (f *sumAvgWindowFn) compute(p *windowPartition, output coldata.Vec) {
+	n := p.end - p.start
+	arg := p.vecs[f.argIdx]
+	nulls := arg.Nulls()
+	counts := ensureInts(f.scratch.counts, n+1)
+	f.scratch.counts = counts
+	for i := p.start; i < p.end; i++ {
+		counts[i-p.start+1] = counts[i-p.start]
+		if !nulls.NullAt64(uint64(i)) {
+			counts[i-p.start+1]++
+		}
+	}
+	if f.argType == coltypes.Float64 {
+		f.computeFloat(p, output)
+	} else {
+		f.computeDecimal(p, output)
+	}
+}
+
+// nonFiniteCounts are the numbers of NaNs and infinities in the window frame.
+type nonFiniteCounts struct {
+	nans, posInfs, negInfs int
+}
+
+// update adds delta to the count of the kind of a value that isn't finite, and
+// returns false if the value is finite.
+func (c *nonFiniteCounts) update(nan, inf, negative bool, delta int) bool {
+	switch {
+	case nan:
+		c.nans += delta
+	case inf && negative:
+		c.negInfs += delta
+	case inf:
+		c.posInfs += delta
+	default:
+		return false
+	}
+	return true
+}
+
+// floatWindowSum is the running sum of the FLOAT values in a window frame.
+type floatWindowSum struct {
+	sum       float64
+	nonFinite nonFiniteCounts
+}
+
+// add adds v to the sum if delta is 1, and subtracts it if delta is -1.
+func (s *floatWindowSum) add(v float64, delta int) {
+	if !s.nonFinite.update(math.IsNaN(v), math.IsInf(v, 0), v < 0, delta) {
+		if delta > 0 {
+			s.sum += v
+		} else {
+			s.sum -= v
+		}
+	}
+}
+
+func (s *floatWindowSum) result() float64 {
+	switch c := s.nonFinite; {
+	case c.nans > 0 || (c.posInfs > 0 && c.negInfs > 0):
+		return math.NaN()
+	case c.posInfs > 0:
+		return math.Inf(1)
+	case c.negInfs > 0:
+		return math.Inf(-1)
+	}
+	return s.sum
+}
+
+// decimalWindowSum is the running sum of the DECIMAL values in a window frame.
+type decimalWindowSum struct {
+	sum       apd.Decimal
+	nonFinite nonFiniteCounts
+}
+
+// add adds v to the sum if delta is 1, and subtracts it if delta is -1.
+func (s *decimalWindowSum) add(v *apd.Decimal, delta int) {
+	if s.nonFinite.update(
+		v.Form == apd.NaN || v.Form == apd.NaNSignaling, v.Form == apd.Infinite, v.Negative, delta,
+	) {
+		return
+	}
+	var err error
+	if delta > 0 {
+		_, err = tree.ExactCtx.Add(&s.sum, &s.sum, v)
+	} else {
+		_, err = tree.ExactCtx.Sub(&s.sum, &s.sum, v)
+	}
+	if err != nil {
+		execerror.NonVectorizedPanic(err)
+	}
+}
+
+func (s *decimalWindowSum) result(d *apd.Decimal) {
+	switch c := s.nonFinite; {
+	case c.nans > 0 || (c.posInfs > 0 && c.negInfs > 0):
+		*d = apd.Decimal{Form: apd.NaN}
+	case c.posInfs > 0 || c.negInfs > 0:
+		*d = apd.Decimal{Form: apd.Infinite, Negative: c.negInfs > 0}
+	default:
+		d.Set(&s.sum)
+	}
+}
+
+func (f *sumAvgWindowFn) computeFloat(p *windowPartition, output coldata.Vec) {
+	arg := p.vecs[f.argIdx]
+	col, nulls := arg.Float64(), arg.Nulls()
+	var sum floatWindowSum
+	prevStart, prevEnd := p.start, p.start
+	outputCol, outputNulls := output.Float64(), output.Nulls()
+	for i := p.start; i < p.end; i++ {
+		start, end := p.frameStarts[i], p.frameEnds[i]
+		for j := prevStart; j < start && j < prevEnd; j++ {
+			if !nulls.NullAt64(uint64(j)) {
+				sum.add(col[j], -1)
+			}
+		}
+		for j := maxInt(prevEnd, start); j < end; j++ {
+			if !nulls.NullAt64(uint64(j)) {
+				sum.add(col[j], 1)
+			}
+		}
+		prevStart, prevEnd = start, end
+		if start >= end || f.scratch.counts[end-p.start] == f.scratch.counts[start-p.start] {
+			outputNulls.SetNull64(uint64(i))
+			continue
+		}
+		outputCol[i] = sum.result()
+		if f.avg {
+			outputCol[i] /= float64(f.scratch.counts[end-p.start] - f.scratch.counts[start-p.start])
+		}
+	}
+}
+
+func (f *sumAvgWindowFn) computeDecimal(p *windowPartition, output coldata.Vec) {
+	arg := p.vecs[f.argIdx]
+	nulls := arg.Nulls()
+	var sum decimalWindowSum
+	var v apd.Decimal
+	add := func(j int, delta int) {
+		switch f.argType {
+		case coltypes.Int16:
+			v.SetFinite(int64(arg.Int16()[j]), 0)
+		case coltypes.Int32:
+			v.SetFinite(int64(arg.Int32()[j]), 0)
+		case coltypes.Int64:
+			v.SetFinite(arg.Int64()[j], 0)
+		case coltypes.Decimal:
+			v.Set(&arg.Decimal()[j])
+		default:
+			execerror.VectorizedInternalPanic(fmt.Sprintf("unsupported type %s", f.argType))
+		}
+		sum.add(&v, delta)
+	}
+	prevStart, prevEnd := p.start, p.start
+	outputCol, outputNulls := output.Decimal(), output.Nulls()
+	for i := p.start; i < p.end; i++ {
+		start, end := p.frameStarts[i], p.frameEnds[i]
+		for j := prevStart; j < start && j < prevEnd; j++ {
+			if !nulls.NullAt64(uint64(j)) {
+				add(j, -1)
+			}
+		}
+		for j := maxInt(prevEnd, start); j < end; j++ {
+			if !nulls.NullAt64(uint64(j)) {
+				add(j, 1)
+			}
+		}
+		prevStart, prevEnd = start, end
+		if start >= end || f.scratch.counts[end-p.start] == f.scratch.counts[start-p.start] {
+			outputNulls.SetNull64(uint64(i))
+			continue
+		}
+		sum.result(&outputCol[i])
+		if f.avg {
+			count := apd.New(int64(f.scratch.counts[end-p.start]-f.scratch.counts[start-p.start]), 0)
+			if _, err := tree.DecimalCtx.Quo(&outputCol[i], &outputCol[i], count); err != nil {
+				execerror.NonVectorizedPanic(err)
+			}
+		}
+	}
+}
+
+// minMaxWindowFn computes MIN or MAX of the argument over the window frame.
+type minMaxWindowFn struct {
+	argIdx  int
+	argType coltypes.T
+	max     bool
+	deque   []int
+}
+
+func (f *minMaxWindowFn) compute(p *windowPartition, output coldata.Vec) {
+	if f.max {
+		f.deque = computeMaxOverFrames(f.argType, p.vecs[f.argIdx], output, p, f.deque)
+	} else {
+		f.deque = computeMinOverFrames(f.argType, p.vecs[f.argIdx], output, p, f.deque)
+	}
+}
+
+// ensureInts returns a zeroed slice of ints of length n, reusing s if it has
+// enough capacity.
+func ensureInts(s []int, n int) []int {
+	if cap(s) < n {
+		return make([]int, n)
+	}
+	s = s[:n]
+	for i := range s {
+		s[i] = 0
+	}
+	return s
+}
+
+// ensureUint64s returns a slice of uint64s of length n, reusing s if it has
+// enough capacity.
+func ensureUint64s(s []uint64, n int) []uint64 {
+	if cap(s) < n {
+		return make([]uint64, n)
+	}
+	return s[:n]
+}
+
+// ensureBools returns a slice of bools of length n, reusing s if it has enough
+// capacity.
+func ensureBools(s []bool, n int) []bool {
+	if cap(s) < n {
+		return make([]bool, n)
+	}
+	return s[:n]
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
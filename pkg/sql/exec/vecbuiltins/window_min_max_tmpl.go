@@ -0,0 +1,119 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// {{/*
+// +build execgen_template
+//
+// This file is the execgen template for window_min_max.eg.go. It's formatted
+// in a special way, so it's both valid Go and a valid text/template input.
+// This permits editing this file with editor support.
+//
+// */}}
+
+package vecbuiltins
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/col/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/execerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/execgen"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// {{/*
+
+// Dummy import to pull in "bytes" package.
+var _ = bytes.Equal
+
+// Dummy import to pull in "tree" package.
+var _ tree.Datum
+
+// Dummy import to pull in "math" package.
+var _ = math.MaxInt64
+
+// _TYPES_T is the template type variable for coltypes.T. It will be replaced by
+// coltypes.Foo for each type Foo in the coltypes.T type.
+const _TYPES_T = coltypes.Unhandled
+
+// _ASSIGN_CMP is the template function for assigning true to the first input
+// if the second input compares successfully to the third input. The comparison
+// operator is tree.LT for MIN and is tree.GT for MAX.
+func _ASSIGN_CMP(_, _, _ string) bool {
+	execerror.VectorizedInternalPanic("")
+}
+
+// */}}
+
+// Use execgen package to remove unused import warning.
+var _ interface{} = execgen.GET
+
+// {{range .}} {{/* for each aggregation (min and max) */}}
+
+// compute_AGG_TITLEOverFrames writes the result of the aggregate function over
+// the window frame of every tuple of p into output. The frames must be
+// non-decreasing (i.e. neither the start nor the end of the frame moves
+// backwards when advancing to the next tuple), which allows us to maintain a
+// monotonic deque of the indices of the candidates for the result, so that
+// every value is looked at a constant number of times. deque is the scratch
+// space for the deque, and it is returned so that it could be reused.
+func compute_AGG_TITLEOverFrames(
+	t coltypes.T, vec coldata.Vec, output coldata.Vec, p *windowPartition, deque []int,
+) []int {
+	nulls, outputNulls := vec.Nulls(), output.Nulls()
+	switch t {
+	// {{range .Overloads}}
+	case _TYPES_T:
+		col, outputCol := vec._TemplateType(), output._TemplateType()
+		// deque[head:] contains the indices of the values in the current frame
+		// that can still become the result. The values at those indices are
+		// strictly monotonic.
+		deque = deque[:0]
+		head := 0
+		// next is the index of the next tuple to be added to the deque.
+		next := p.start
+		for i := p.start; i < p.end; i++ {
+			for ; next < p.frameEnds[i]; next++ {
+				if nulls.NullAt64(uint64(next)) {
+					continue
+				}
+				v := execgen.GET(col, next)
+				for len(deque) > head {
+					var keep bool
+					last := execgen.GET(col, deque[len(deque)-1])
+					_ASSIGN_CMP("keep", "last", "v")
+					if keep {
+						break
+					}
+					deque = deque[:len(deque)-1]
+				}
+				deque = append(deque, next)
+			}
+			for len(deque) > head && deque[head] < p.frameStarts[i] {
+				head++
+			}
+			if len(deque) == head {
+				outputNulls.SetNull64(uint64(i))
+				continue
+			}
+			v := execgen.GET(col, deque[head])
+			execgen.SET(outputCol, i, v)
+		}
+	// {{end}}
+	default:
+		execerror.VectorizedInternalPanic(fmt.Sprintf("unsupported type %s", t))
+	}
+	return deque
+}
+
+// {{end}}
@@ -789,6 +789,7 @@ func checkResultType(typ *types.T) error {
 	case types.UuidFamily:
 	case types.INetFamily:
 	case types.OidFamily:
+	case types.EnumFamily:
 	case types.TupleFamily:
 	case types.ArrayFamily:
 		if typ.ArrayContents().Family() == types.ArrayFamily {
@@ -1825,7 +1826,15 @@ func (m *sessionDataMutator) SetSafeUpdates(val bool) {
 }
 
 func (m *sessionDataMutator) SetSearchPath(val sessiondata.SearchPath) {
-	m.data.SearchPath = val
+	// Setting the search path does not affect the resolution of the pg_temp
+	// alias to the session's temporary schema.
+	m.data.SearchPath = val.WithTemporarySchemaName(m.data.SearchPath.GetTemporarySchemaName())
+}
+
+// SetTemporarySchemaName makes the pg_temp alias of the search path refer to
+// the session's temporary schema, and the schema be searched implicitly.
+func (m *sessionDataMutator) SetTemporarySchemaName(scName string) {
+	m.data.SearchPath = m.data.SearchPath.WithTemporarySchemaName(scName)
 }
 
 func (m *sessionDataMutator) SetLocation(loc *time.Location) {
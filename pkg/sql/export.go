@@ -13,6 +13,7 @@ package sql
 import (
 	"context"
 	"strconv"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
@@ -28,9 +29,12 @@ type exportNode struct {
 
 	source planNode
 
-	fileName  string
-	csvOpts   roachpb.CSVOptions
-	chunkSize int
+	fileName       string
+	format         roachpb.IOFileFormat_FileFormat
+	csvOpts        roachpb.CSVOptions
+	parquetOpts    roachpb.ParquetOptions
+	chunkSize      int
+	filePatternExt string
 }
 
 func (e *exportNode) startExec(params runParams) error {
@@ -54,18 +58,37 @@ const (
 	exportOptionNullAs    = "nullas"
 	exportOptionChunkSize = "chunk_rows"
 	exportOptionFileName  = "filename"
+	// exportOptionCompression is the compression codec of PARQUET files.
+	exportOptionCompression = "compression"
 )
 
 var exportOptionExpectValues = map[string]KVStringOptValidate{
-	exportOptionChunkSize: KVStringOptRequireValue,
-	exportOptionDelimiter: KVStringOptRequireValue,
-	exportOptionFileName:  KVStringOptRequireValue,
-	exportOptionNullAs:    KVStringOptRequireValue,
+	exportOptionChunkSize:   KVStringOptRequireValue,
+	exportOptionDelimiter:   KVStringOptRequireValue,
+	exportOptionFileName:    KVStringOptRequireValue,
+	exportOptionNullAs:      KVStringOptRequireValue,
+	exportOptionCompression: KVStringOptRequireValue,
+}
+
+// exportFormats maps the names of the formats accepted by EXPORT to the
+// extension of the files written in each.
+var exportFormats = map[string]struct {
+	format roachpb.IOFileFormat_FileFormat
+	ext    string
+}{
+	"CSV":     {roachpb.IOFileFormat_CSV, ".csv"},
+	"JSONL":   {roachpb.IOFileFormat_JSONL, ".jsonl"},
+	"PARQUET": {roachpb.IOFileFormat_Parquet, ".parquet"},
+}
+
+var exportParquetCompression = map[string]roachpb.ParquetOptions_Compression{
+	"snappy": roachpb.ParquetOptions_Snappy,
+	"gzip":   roachpb.ParquetOptions_Gzip,
+	"none":   roachpb.ParquetOptions_Uncompressed,
 }
 
 const exportChunkSizeDefault = 100000
 const exportFilePatternPart = "%part%"
-const exportFilePatternDefault = exportFilePatternPart + ".csv"
 
 // ConstructExport is part of the exec.Factory interface.
 func (ef *execFactory) ConstructExport(
@@ -75,7 +98,8 @@ func (ef *execFactory) ConstructExport(
 		return nil, errors.Errorf("EXPORT cannot be used inside a transaction")
 	}
 
-	if fileFormat != "CSV" {
+	format, ok := exportFormats[fileFormat]
+	if !ok {
 		return nil, errors.Errorf("unsupported export format: %q", fileFormat)
 	}
 
@@ -93,6 +117,17 @@ func (ef *execFactory) ConstructExport(
 		return nil, err
 	}
 
+	for _, opt := range []string{exportOptionDelimiter, exportOptionNullAs} {
+		if _, ok := optVals[opt]; ok && format.format != roachpb.IOFileFormat_CSV {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"%s option is only supported for CSV", opt)
+		}
+	}
+	if _, ok := optVals[exportOptionCompression]; ok && format.format != roachpb.IOFileFormat_Parquet {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s option is only supported for PARQUET", exportOptionCompression)
+	}
+
 	csvOpts := roachpb.CSVOptions{}
 
 	if override, ok := optVals[exportOptionDelimiter]; ok {
@@ -106,6 +141,15 @@ func (ef *execFactory) ConstructExport(
 		csvOpts.NullEncoding = &override
 	}
 
+	parquetOpts := roachpb.ParquetOptions{}
+	if override, ok := optVals[exportOptionCompression]; ok {
+		parquetOpts.Compression, ok = exportParquetCompression[strings.ToLower(override)]
+		if !ok {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"unsupported compression %q: expected snappy, gzip or none", override)
+		}
+	}
+
 	chunkSize := exportChunkSizeDefault
 	if override, ok := optVals[exportOptionChunkSize]; ok {
 		chunkSize, err = strconv.Atoi(override)
@@ -113,14 +157,17 @@ func (ef *execFactory) ConstructExport(
 			return nil, pgerror.New(pgcode.InvalidParameterValue, err.Error())
 		}
 		if chunkSize < 1 {
-			return nil, pgerror.New(pgcode.InvalidParameterValue, "invalid csv chunk size")
+			return nil, pgerror.New(pgcode.InvalidParameterValue, "invalid chunk size")
 		}
 	}
 
 	return &exportNode{
-		source:    input.(planNode),
-		fileName:  string(*fileNameStr),
-		csvOpts:   csvOpts,
-		chunkSize: chunkSize,
+		source:         input.(planNode),
+		fileName:       string(*fileNameStr),
+		format:         format.format,
+		csvOpts:        csvOpts,
+		parquetOpts:    parquetOpts,
+		chunkSize:      chunkSize,
+		filePatternExt: format.ext,
 	}, nil
 }
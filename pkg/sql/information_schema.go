@@ -1406,7 +1406,8 @@ CREATE TABLE information_schema.views (
 	},
 }
 
-// forEachSchemaName iterates over the physical and virtual schemas.
+// forEachSchemaName iterates over the physical, temporary and virtual
+// schemas.
 func forEachSchemaName(
 	ctx context.Context, p *planner, db *sqlbase.DatabaseDescriptor, fn func(string) error,
 ) error {
@@ -1415,6 +1416,16 @@ func forEachSchemaName(
 	for _, schema := range p.getVirtualTabler().getEntries() {
 		scNames = append(scNames, schema.desc.Name)
 	}
+	// Handle temporary schemas.
+	tempSchemas, err := p.getTemporarySchemas(ctx)
+	if err != nil {
+		return err
+	}
+	for _, sc := range tempSchemas {
+		if sc.dbID == db.ID {
+			scNames = append(scNames, sc.name)
+		}
+	}
 	sort.Strings(scNames)
 	for _, sc := range scNames {
 		if err := fn(sc); err != nil {
@@ -1456,6 +1467,43 @@ func forEachDatabaseDesc(
 	return nil
 }
 
+// forEachTypeDesc calls fn on the descriptors of the user-defined types of
+// the given database, or of all the databases the user can see if dbContext
+// is nil.
+func forEachTypeDesc(
+	ctx context.Context,
+	p *planner,
+	dbContext *DatabaseDescriptor,
+	fn func(*sqlbase.DatabaseDescriptor, *sqlbase.TypeDescriptor) error,
+) error {
+	descs, err := p.Tables().getAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return err
+	}
+
+	dbDescs := make(map[sqlbase.ID]*sqlbase.DatabaseDescriptor)
+	for _, desc := range descs {
+		if dbDesc, ok := desc.(*sqlbase.DatabaseDescriptor); ok &&
+			(dbContext == nil || dbContext.ID == dbDesc.ID) &&
+			userCanSeeDatabase(ctx, p, dbDesc) {
+			dbDescs[dbDesc.ID] = dbDesc
+		}
+	}
+
+	for _, desc := range descs {
+		typDesc, ok := desc.(*sqlbase.TypeDescriptor)
+		if !ok {
+			continue
+		}
+		if dbDesc, ok := dbDescs[typDesc.ParentID]; ok {
+			if err := fn(dbDesc, typDesc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // forEachTableDesc retrieves all table descriptors from the current
 // database and all system databases and iterates through them. For
 // each table, the function will call fn with its respective database
@@ -1602,13 +1650,33 @@ func forEachTableDescWithTableLookupInternal(
 	}
 
 	// Physical descriptors next.
+	// The names of the temporary schemas are only looked up if needed.
+	var tempSchemaNames map[sqlbase.ID]string
 	for _, tbID := range lCtx.tbIDs {
 		table := lCtx.tbDescs[tbID]
 		dbDesc, parentExists := lCtx.dbDescs[table.GetParentID()]
 		if table.Dropped() || !userCanSeeTable(ctx, p, table, allowAdding) || !parentExists {
 			continue
 		}
-		if err := fn(dbDesc, tree.PublicSchema, table, lCtx); err != nil {
+		scName := tree.PublicSchema
+		if table.IsTemporary() {
+			if tempSchemaNames == nil {
+				tempSchemas, err := p.getTemporarySchemas(ctx)
+				if err != nil {
+					return err
+				}
+				tempSchemaNames = make(map[sqlbase.ID]string, len(tempSchemas))
+				for _, sc := range tempSchemas {
+					tempSchemaNames[sc.id] = sc.name
+				}
+			}
+			var ok bool
+			if scName, ok = tempSchemaNames[table.TemporarySchemaID]; !ok {
+				// The temporary schema is being cleaned up.
+				continue
+			}
+		}
+		if err := fn(dbDesc, scName, table, lCtx); err != nil {
 			return err
 		}
 	}
@@ -47,6 +47,8 @@ func buildOpaque(
 		plan, err = p.AlterTable(ctx, n)
 	case *tree.AlterSequence:
 		plan, err = p.AlterSequence(ctx, n)
+	case *tree.AlterType:
+		plan, err = p.AlterType(ctx, n)
 	case *tree.AlterUserSetPassword:
 		plan, err = p.AlterUserSetPassword(ctx, n)
 	case *tree.CommentOnColumn:
@@ -55,6 +57,8 @@ func buildOpaque(
 		plan, err = p.CommentOnDatabase(ctx, n)
 	case *tree.CommentOnTable:
 		plan, err = p.CommentOnTable(ctx, n)
+	case *tree.ControlSchedules:
+		plan, err = p.ControlSchedules(ctx, n)
 	case *tree.CreateDatabase:
 		plan, err = p.CreateDatabase(ctx, n)
 	case *tree.CreateIndex:
@@ -65,6 +69,8 @@ func buildOpaque(
 		plan, err = p.CreateSequence(ctx, n)
 	case *tree.CreateStats:
 		plan, err = p.CreateStatistics(ctx, n)
+	case *tree.CreateType:
+		plan, err = p.CreateType(ctx, n)
 	case *tree.Deallocate:
 		plan, err = p.Deallocate(ctx, n)
 	case *tree.Discard:
@@ -79,6 +85,8 @@ func buildOpaque(
 		plan, err = p.DropView(ctx, n)
 	case *tree.DropSequence:
 		plan, err = p.DropSequence(ctx, n)
+	case *tree.DropType:
+		plan, err = p.DropType(ctx, n)
 	case *tree.DropUser:
 		plan, err = p.DropUser(ctx, n)
 	case *tree.Grant:
@@ -146,14 +154,17 @@ func init() {
 		&tree.AlterIndex{},
 		&tree.AlterTable{},
 		&tree.AlterSequence{},
+		&tree.AlterType{},
 		&tree.CommentOnColumn{},
 		&tree.CommentOnDatabase{},
 		&tree.CommentOnTable{},
+		&tree.ControlSchedules{},
 		&tree.CreateDatabase{},
 		&tree.CreateIndex{},
 		&tree.CreateUser{},
 		&tree.CreateSequence{},
 		&tree.CreateStats{},
+		&tree.CreateType{},
 		&tree.Deallocate{},
 		&tree.Discard{},
 		&tree.DropDatabase{},
@@ -161,6 +172,7 @@ func init() {
 		&tree.DropTable{},
 		&tree.DropView{},
 		&tree.DropSequence{},
+		&tree.DropType{},
 		&tree.DropUser{},
 		&tree.Grant{},
 		&tree.RenameColumn{},
@@ -193,6 +205,7 @@ func init() {
 		&tree.GrantRole{},
 		&tree.RevokeRole{},
 		&tree.Import{},
+		&tree.ScheduledBackup{},
 	} {
 		typ := optbuilder.OpaqueReadOnly
 		if tree.CanModifySchema(stmt) {
@@ -42,6 +42,7 @@ func (f *stubFactory) ConstructScan(
 	maxResults uint64,
 	reqOrdering exec.OutputOrdering,
 	rowCount float64,
+	locking *tree.LockingItem,
 ) (exec.Node, error) {
 	return struct{}{}, nil
 }
@@ -347,6 +348,7 @@ func (f *stubFactory) ConstructCancelSessions(input exec.Node, ifExists bool) (e
 func (f *stubFactory) ConstructCreateView(
 	schema cat.Schema,
 	viewName string,
+	temporary bool,
 	viewQuery string,
 	columns sqlbase.ResultColumns,
 	deps opt.ViewDeps,
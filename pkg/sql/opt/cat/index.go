@@ -51,6 +51,13 @@ type Index interface {
 	// IsInverted returns true if this is a JSON inverted index.
 	IsInverted() bool
 
+	// Predicate returns the predicate expression and true if the index is a
+	// partial index. A partial index only contains entries for the rows which
+	// satisfy its predicate, so it can only be scanned by queries whose filters
+	// imply the predicate. If the index is not partial, the empty string and
+	// false are returned.
+	Predicate() (string, bool)
+
 	// ColumnCount returns the number of columns in the index. This includes
 	// columns that were part of the index definition (including the STORING
 	// clause), as well as implicitly added primary key columns.
@@ -438,6 +438,11 @@ func (b *Builder) buildScan(scan *memo.ScanExpr) (execPlan, error) {
 		var err error
 		if idx.IsInverted() {
 			err = fmt.Errorf("index \"%s\" is inverted and cannot be used for this query", idx.Name())
+		} else if _, isPartial := idx.Predicate(); isPartial {
+			err = fmt.Errorf(
+				"index \"%s\" is a partial index whose predicate is not implied by the query filters",
+				idx.Name(),
+			)
 		} else {
 			// This should never happen.
 			err = fmt.Errorf("index \"%s\" cannot be used for this query", idx.Name())
@@ -466,6 +471,7 @@ func (b *Builder) buildScan(scan *memo.ScanExpr) (execPlan, error) {
 		b.indexConstraintMaxResults(scan),
 		res.reqOrdering(scan),
 		rowCount,
+		scan.Locking,
 	)
 	if err != nil {
 		return execPlan{}, err
@@ -54,6 +54,8 @@ type Factory interface {
 	//     the scan.
 	//   - If maxResults > 0, the scan is guaranteed to return at most maxResults
 	//     rows.
+	//   - If locking is not nil, the scan acquires row-level locks on the rows
+	//     that it returns, with the given strength and wait policy.
 	ConstructScan(
 		table cat.Table,
 		index cat.Index,
@@ -64,6 +66,7 @@ type Factory interface {
 		maxResults uint64,
 		reqOrdering OutputOrdering,
 		rowCount float64,
+		locking *tree.LockingItem,
 	) (Node, error)
 
 	// ConstructVirtualScan returns a node that represents the scan of a virtual
@@ -408,6 +411,7 @@ type Factory interface {
 	ConstructCreateView(
 		schema cat.Schema,
 		viewName string,
+		temporary bool,
 		viewQuery string,
 		columns sqlbase.ResultColumns,
 		deps opt.ViewDeps,
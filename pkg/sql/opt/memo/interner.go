@@ -475,6 +475,13 @@ func (h *hasher) HashScanFlags(val ScanFlags) {
 	h.HashUint64(uint64(val.Index))
 }
 
+func (h *hasher) HashLockingItem(val *tree.LockingItem) {
+	if val != nil {
+		h.HashUint64(uint64(val.Strength))
+		h.HashUint64(uint64(val.WaitPolicy))
+	}
+}
+
 func (h *hasher) HashJoinFlags(val JoinFlags) {
 	h.HashBool(val.DisallowHashJoin)
 	h.HashBool(val.DisallowMergeJoin)
@@ -793,6 +800,13 @@ func (h *hasher) IsScanFlagsEqual(l, r ScanFlags) bool {
 	return l == r
 }
 
+func (h *hasher) IsLockingItemEqual(l, r *tree.LockingItem) bool {
+	if l == nil || r == nil {
+		return l == r
+	}
+	return l.Strength == r.Strength && l.WaitPolicy == r.WaitPolicy
+}
+
 func (h *hasher) IsJoinFlagsEqual(l, r JoinFlags) bool {
 	return l == r
 }
@@ -347,6 +347,14 @@ func TestInterner(t *testing.T) {
 			{val1: ScanFlags{NoIndexJoin: true, Index: 1}, val2: ScanFlags{NoIndexJoin: false, Index: 1}, equal: false},
 		}},
 
+		{hashFn: in.hasher.HashLockingItem, eqFn: in.hasher.IsLockingItemEqual, variations: []testVariation{
+			{val1: (*tree.LockingItem)(nil), val2: (*tree.LockingItem)(nil), equal: true},
+			{val1: (*tree.LockingItem)(nil), val2: &tree.LockingItem{Strength: tree.ForUpdate}, equal: false},
+			{val1: &tree.LockingItem{Strength: tree.ForShare}, val2: &tree.LockingItem{Strength: tree.ForShare}, equal: true},
+			{val1: &tree.LockingItem{Strength: tree.ForShare}, val2: &tree.LockingItem{Strength: tree.ForUpdate}, equal: false},
+			{val1: &tree.LockingItem{Strength: tree.ForUpdate}, val2: &tree.LockingItem{Strength: tree.ForUpdate, WaitPolicy: tree.LockWaitError}, equal: false},
+		}},
+
 		{hashFn: in.hasher.HashPointer, eqFn: in.hasher.IsPointerEqual, variations: []testVariation{
 			{val1: unsafe.Pointer((*tree.Subquery)(nil)), val2: unsafe.Pointer((*tree.Subquery)(nil)), equal: true},
 			{val1: unsafe.Pointer(&tree.Subquery{}), val2: unsafe.Pointer(&tree.Subquery{}), equal: false},
@@ -1439,6 +1439,12 @@ func MakeTableFuncDep(md *opt.Metadata, tabID opt.TableID) *props.FuncDepSet {
 			continue
 		}
 
+		if _, isPartial := index.Predicate(); isPartial {
+			// Partial indexes only contain a subset of the table rows, so their
+			// keys are not keys of the table.
+			continue
+		}
+
 		// If index has a separate lax key, add a lax key FD. Otherwise, add a
 		// strict key. See the comment for cat.Index.LaxKeyColumnCount.
 		for col := 0; col < index.LaxKeyColumnCount(); col++ {
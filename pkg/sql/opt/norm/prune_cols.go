@@ -86,6 +86,21 @@ func neededMutationFetchCols(
 	var cols opt.ColSet
 	tabMeta := mem.Metadata().TableMeta(private.Table)
 
+	// Whether a row has an entry in a partial index depends on the columns
+	// referenced by the index predicate, so they are needed in order to update
+	// or delete the entry. Conservatively keep all fetch columns when the table
+	// has any partial index, including those being added or dropped.
+	for i, n := 0, tabMeta.Table.DeletableIndexCount(); i < n; i++ {
+		if _, isPartial := tabMeta.Table.Index(i).Predicate(); isPartial {
+			for ord, col := range private.FetchCols {
+				if col != 0 {
+					cols.Add(tabMeta.MetaID.ColumnID(ord))
+				}
+			}
+			return cols
+		}
+	}
+
 	// familyCols returns the columns in the given family.
 	familyCols := func(fam cat.Family) opt.ColSet {
 		var colSet opt.ColSet
@@ -171,6 +186,14 @@ func neededMutationFetchCols(
 		for i, n := 0, tabMeta.Table.DeletableIndexCount(); i < n; i++ {
 			cols.UnionWith(tabMeta.IndexKeyColumns(i))
 		}
+
+		// An index that is being added or dropped as part of a primary key
+		// change is encoded like a primary index, with one row per column
+		// family. Deleting its rows requires knowing which families are
+		// present, so conservatively fetch all the columns of mutation indexes.
+		for i, n := tabMeta.Table.IndexCount(), tabMeta.Table.DeletableIndexCount(); i < n; i++ {
+			cols.UnionWith(tabMeta.IndexColumns(i))
+		}
 	}
 
 	return cols
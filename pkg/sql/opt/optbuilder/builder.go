@@ -235,10 +235,10 @@ func (b *Builder) buildStmt(
 		return b.buildInsert(stmt, inScope)
 
 	case *tree.ParenSelect:
-		return b.buildSelect(stmt.Select, desiredTypes, inScope)
+		return b.buildSelect(stmt.Select, noRowLocking, desiredTypes, inScope)
 
 	case *tree.Select:
-		return b.buildSelect(stmt, desiredTypes, inScope)
+		return b.buildSelect(stmt, noRowLocking, desiredTypes, inScope)
 
 	case *tree.Update:
 		return b.buildUpdate(stmt, inScope)
@@ -26,7 +26,8 @@ import (
 // statement.
 func (b *Builder) buildCreateTable(ct *tree.CreateTable, inScope *scope) (outScope *scope) {
 	b.DisableMemoReuse = true
-	sch, resName := b.resolveSchemaForCreate(&ct.Table)
+	sch, resName, temporary := b.resolveSchemaForCreate(&ct.Table, ct.Temporary)
+	ct.Temporary = temporary
 	// TODO(radu): we are modifying the AST in-place here. We should be storing
 	// the resolved name separately.
 	ct.Table.TableNamePrefix = resName
@@ -49,7 +50,7 @@ func (b *Builder) buildCreateTable(ct *tree.CreateTable, inScope *scope) (outSco
 		}()
 
 		// Build the input query.
-		outScope := b.buildSelect(ct.AsSource, nil /* desiredTypes */, inScope)
+		outScope := b.buildSelect(ct.AsSource, noRowLocking, nil /* desiredTypes */, inScope)
 
 		numColNames := 0
 		for i := 0; i < len(ct.Defs); i++ {
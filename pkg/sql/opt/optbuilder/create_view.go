@@ -21,7 +21,7 @@ import (
 
 func (b *Builder) buildCreateView(cv *tree.CreateView, inScope *scope) (outScope *scope) {
 	b.DisableMemoReuse = true
-	sch, _ := b.resolveSchemaForCreate(&cv.Name)
+	sch, _, temporary := b.resolveSchemaForCreate(&cv.Name, cv.Temporary)
 	schID := b.factory.Metadata().AddSchema(sch)
 
 	// We build the select statement to:
@@ -39,7 +39,7 @@ func (b *Builder) buildCreateView(cv *tree.CreateView, inScope *scope) (outScope
 		b.qualifyDataSourceNamesInAST = false
 	}()
 
-	defScope := b.buildSelect(cv.AsSource, nil /* desiredTypes */, inScope)
+	defScope := b.buildSelect(cv.AsSource, noRowLocking, nil /* desiredTypes */, inScope)
 
 	p := defScope.makePhysicalProps().Presentation
 	if len(cv.ColumnNames) != 0 {
@@ -60,6 +60,7 @@ func (b *Builder) buildCreateView(cv *tree.CreateView, inScope *scope) (outScope
 		&memo.CreateViewPrivate{
 			Schema:    schID,
 			ViewName:  cv.Name.Table(),
+			Temporary: temporary,
 			ViewQuery: tree.AsStringWithFlags(cv.AsSource, tree.FmtParsable),
 			Columns:   p,
 			Deps:      b.viewDeps,
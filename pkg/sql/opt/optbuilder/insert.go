@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -550,7 +551,7 @@ func (mb *mutationBuilder) buildInputForInsert(inScope *scope, inputRows *tree.S
 		}
 	}
 
-	mb.outScope = mb.b.buildSelect(inputRows, desiredTypes, inScope)
+	mb.outScope = mb.b.buildSelect(inputRows, noRowLocking, desiredTypes, inScope)
 
 	if len(mb.targetColList) != 0 {
 		// Target columns already exist, so ensure that the number of input
@@ -662,6 +663,7 @@ func (mb *mutationBuilder) buildInputForDoNothing(inScope *scope, onConflict *tr
 			mb.b.addTable(mb.tab, &mb.alias),
 			nil, /* ordinals */
 			nil, /* indexFlags */
+			noRowLocking,
 			excludeMutations,
 			inScope,
 		)
@@ -689,6 +691,25 @@ func (mb *mutationBuilder) buildInputForDoNothing(inScope *scope, onConflict *tr
 			on = append(on, memo.FiltersItem{Condition: condition})
 		}
 
+		// A partial unique index only enforces uniqueness among the rows which
+		// satisfy its predicate, so there is a conflict only if both the insert
+		// row and the existing row satisfy it.
+		if pred, isPartial := index.Predicate(); isPartial {
+			expr, err := parser.ParseExpr(pred)
+			if err != nil {
+				panic(err)
+			}
+			texpr := scanScope.resolveAndRequireType(expr, types.Bool)
+			on = append(on, memo.FiltersItem{
+				Condition: mb.b.buildScalar(texpr, scanScope, nil, nil, nil),
+			})
+			insScope := mb.insertColsScope()
+			texpr = insScope.resolveAndRequireType(expr, types.Bool)
+			on = append(on, memo.FiltersItem{
+				Condition: mb.b.buildScalar(texpr, insScope, nil, nil, nil),
+			})
+		}
+
 		// Construct the left join + filter.
 		// TODO(andyk): Convert this to use anti-join once we have support for
 		// lookup anti-joins.
@@ -744,6 +765,7 @@ func (mb *mutationBuilder) buildInputForUpsert(
 		mb.b.addTable(mb.tab, &mb.alias),
 		nil, /* ordinals */
 		nil, /* indexFlags */
+		noRowLocking,
 		includeMutations,
 		inScope,
 	)
@@ -969,6 +991,12 @@ func (mb *mutationBuilder) ensureUniqueConflictCols(cols tree.NameList) cat.Inde
 			continue
 		}
 
+		// Partial indexes only guarantee uniqueness among the rows which satisfy
+		// their predicate, so they can't arbitrate conflicts.
+		if _, isPartial := index.Predicate(); isPartial {
+			continue
+		}
+
 		found := true
 		for col, colCount := 0, index.LaxKeyColumnCount(); col < colCount; col++ {
 			if cols[col] != index.Column(col).ColName() {
@@ -985,6 +1013,25 @@ func (mb *mutationBuilder) ensureUniqueConflictCols(cols tree.NameList) cat.Inde
 		"there is no unique or exclusion constraint matching the ON CONFLICT specification"))
 }
 
+// insertColsScope returns a scope with one column for each table column, named
+// after the table column and referencing the corresponding insert column. It
+// is used to build expressions over the table columns, like partial index
+// predicates, which apply to the insert rows.
+func (mb *mutationBuilder) insertColsScope() *scope {
+	s := mb.b.allocScope()
+	s.cols = make([]scopeColumn, 0, mb.tab.ColumnCount())
+	for i, n := 0, mb.tab.ColumnCount(); i < n; i++ {
+		tabCol := mb.tab.Column(i)
+		s.cols = append(s.cols, scopeColumn{
+			name:  tabCol.ColName(),
+			table: mb.alias,
+			typ:   tabCol.DatumType(),
+			id:    mb.insertColID(i),
+		})
+	}
+	return s
+}
+
 // getPrimaryKeyColumnNames returns the names of all primary key columns in the
 // target table.
 func (mb *mutationBuilder) getPrimaryKeyColumnNames() tree.NameList {
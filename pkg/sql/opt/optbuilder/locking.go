@@ -0,0 +1,187 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// lockingSpec maintains a collection of FOR [KEY] UPDATE/SHARE items that
+// apply to a given scope. Locking clauses can be applied to the lockingSpec
+// as they come into scope in the AST. The lockingSpec can then be consulted
+// to determine whether a base table data source should be scanned with row
+// locking and, if so, with what strength and wait policy.
+//
+// Each item in the lockingSpec may or may not contain a list of targets. A
+// locking item without any targets applies to every base table in its scope.
+// A locking item with targets only applies to the data sources whose names
+// (or aliases) match one of the targets.
+type lockingSpec []*tree.LockingItem
+
+// noRowLocking indicates that no row-level locking has been specified.
+var noRowLocking lockingSpec
+
+// isSet returns whether the spec contains any row-level locking modes.
+func (lm lockingSpec) isSet() bool {
+	return len(lm) != 0
+}
+
+// get returns the combined row-level locking mode that applies to the data
+// source that the spec was filtered down to, or nil if no locking applies.
+// When multiple items apply, the strongest strength and the most restrictive
+// wait policy take precedence, which matches Postgres.
+func (lm lockingSpec) get() *tree.LockingItem {
+	if !lm.isSet() {
+		return nil
+	}
+	var res tree.LockingItem
+	for _, li := range lm {
+		res.Strength = res.Strength.Max(li.Strength)
+		res.WaitPolicy = res.WaitPolicy.Max(li.WaitPolicy)
+	}
+	return &res
+}
+
+// apply merges the locking clause into the current locking spec. The effect
+// of applying new locking clauses to an existing spec is always to strengthen
+// the locking approaches it represents, either through increasing locking
+// strength or using more aggressive wait policies.
+func (lm lockingSpec) apply(locking tree.LockingClause) lockingSpec {
+	if len(locking) == 0 {
+		return lm
+	}
+	// Don't mutate the spec in place, as it may be shared with a parent scope.
+	res := make(lockingSpec, 0, len(lm)+len(locking))
+	res = append(res, lm...)
+	return append(res, locking...)
+}
+
+// filter returns the subset of the spec that applies to a data source with
+// the given name. Items without targets always apply. Items with targets
+// apply only if one of the targets matches the name, in which case the items
+// are returned without their targets, since they now apply to everything
+// within the data source (e.g. all base tables referenced by a subquery).
+func (lm lockingSpec) filter(alias tree.Name) lockingSpec {
+	var res lockingSpec
+	for _, li := range lm {
+		if len(li.Targets) == 0 {
+			res = append(res, li)
+			continue
+		}
+		for i := range li.Targets {
+			if li.Targets[i].TableName == alias {
+				res = append(res, &tree.LockingItem{
+					Strength:   li.Strength,
+					WaitPolicy: li.WaitPolicy,
+				})
+				break
+			}
+		}
+	}
+	return res
+}
+
+// withoutTargets returns the subset of the spec whose items do not name any
+// targets, i.e. the items that apply to every data source in scope.
+func (lm lockingSpec) withoutTargets() lockingSpec {
+	var res lockingSpec
+	for _, li := range lm {
+		if len(li.Targets) == 0 {
+			res = append(res, li)
+		}
+	}
+	return res
+}
+
+// hasTargetMatching returns whether any item in the spec explicitly names the
+// given data source as one of its targets.
+func (lm lockingSpec) hasTargetMatching(alias tree.Name) bool {
+	for _, li := range lm {
+		for i := range li.Targets {
+			if li.Targets[i].TableName == alias {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rejectIfLocking panics if row-level locking has been specified, since it
+// cannot be applied to the query being built. The format string is passed the
+// name of the locking strength.
+func (b *Builder) rejectIfLocking(locking lockingSpec, format string) {
+	if locking.isSet() {
+		panic(pgerror.Newf(pgcode.FeatureNotSupported, format, locking.strengthName()))
+	}
+}
+
+// strengthName returns the name of the strongest locking strength in the
+// spec, for use in error messages.
+func (lm lockingSpec) strengthName() string {
+	return lm.get().Strength.String()
+}
+
+// validateLockingInFrom checks that every target of the locking spec refers to
+// a data source in the FROM clause, which has been built into fromScope. Only
+// the locking clause of the SELECT statement being built can contain targets,
+// since targets are stripped when locking is propagated into data sources.
+func (b *Builder) validateLockingInFrom(locking lockingSpec, fromScope *scope) {
+	for _, li := range locking {
+		for i := range li.Targets {
+			target := li.Targets[i].TableName
+			found := false
+			for j := range fromScope.cols {
+				if fromScope.cols[j].table.TableName == target {
+					found = true
+					break
+				}
+			}
+			if !found {
+				panic(pgerror.Newf(pgcode.UndefinedTable,
+					"relation %q in %s clause not found in FROM clause",
+					tree.ErrString(&target), li.Strength,
+				))
+			}
+		}
+	}
+}
+
+// validateLockingInSelectClause checks that row-level locking can be applied
+// to the given SELECT clause. Row-level locking requires that every output
+// row can be traced back to a unique row in a base table, so it cannot be
+// combined with DISTINCT, GROUP BY, HAVING, aggregates, window functions or
+// set-returning functions.
+func (b *Builder) validateLockingInSelectClause(
+	locking lockingSpec, sel *tree.SelectClause, fromScope *scope, needsAgg bool,
+) {
+	var clause string
+	switch {
+	case sel.Distinct:
+		clause = "DISTINCT clause"
+	case len(sel.GroupBy) > 0:
+		clause = "GROUP BY clause"
+	case sel.Having != nil:
+		clause = "HAVING clause"
+	case needsAgg:
+		clause = "aggregate functions"
+	case len(fromScope.windows) > 0:
+		clause = "window functions"
+	case len(fromScope.srfs) > 0:
+		clause = "set-returning functions in the target list"
+	default:
+		return
+	}
+	panic(pgerror.Newf(pgcode.FeatureNotSupported,
+		"%s is not allowed with %s", locking.strengthName(), clause,
+	))
+}
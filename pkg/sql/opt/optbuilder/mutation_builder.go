@@ -220,6 +220,7 @@ func (mb *mutationBuilder) buildInputForUpdate(
 		mb.b.addTable(mb.tab, &mb.alias),
 		nil, /* ordinals */
 		nil, /* indexFlags */
+		noRowLocking,
 		includeMutations,
 		inScope,
 	)
@@ -230,7 +231,7 @@ func (mb *mutationBuilder) buildInputForUpdate(
 	// If there is a FROM clause present, we must join all the tables
 	// together with the table being updated.
 	if fromClausePresent {
-		fromScope := mb.b.buildFromTables(from, inScope)
+		fromScope := mb.b.buildFromTables(from, noRowLocking, inScope)
 
 		// Check that the same table name is not used multiple times.
 		mb.b.validateJoinTableNames(mb.outScope, fromScope)
@@ -316,6 +317,7 @@ func (mb *mutationBuilder) buildInputForDelete(
 		mb.b.addTable(mb.tab, &mb.alias),
 		nil, /* ordinals */
 		nil, /* indexFlags */
+		noRowLocking,
 		includeMutations,
 		inScope,
 	)
@@ -930,6 +932,7 @@ func (mb *mutationBuilder) buildFKChecksForInsert() {
 			refTabMeta,
 			refOrdinals,
 			&tree.IndexFlags{IgnoreForeignKeys: true},
+			noRowLocking,
 			includeMutations,
 			mb.b.allocScope(),
 		)
@@ -1089,6 +1092,7 @@ func (mb *mutationBuilder) buildFKChecksForDelete() {
 			origTabMeta,
 			origOrdinals,
 			&tree.IndexFlags{IgnoreForeignKeys: true},
+			noRowLocking,
 			includeMutations,
 			mb.b.allocScope(),
 		)
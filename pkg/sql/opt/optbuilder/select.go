@@ -12,6 +12,7 @@ package optbuilder
 
 import (
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
@@ -42,7 +43,7 @@ const (
 // See Builder.buildStmt for a description of the remaining input and
 // return values.
 func (b *Builder) buildDataSource(
-	texpr tree.TableExpr, indexFlags *tree.IndexFlags, inScope *scope,
+	texpr tree.TableExpr, indexFlags *tree.IndexFlags, locking lockingSpec, inScope *scope,
 ) (outScope *scope) {
 	// NB: The case statements are sorted lexicographically.
 	switch source := texpr.(type) {
@@ -51,8 +52,13 @@ func (b *Builder) buildDataSource(
 			telemetry.Inc(sqltelemetry.IndexHintUseCounter)
 			indexFlags = source.IndexFlags
 		}
+		if source.As.Alias != "" {
+			// The alias hides the name of the underlying data source, so the
+			// locking targets must refer to the alias.
+			locking = locking.filter(source.As.Alias)
+		}
 
-		outScope = b.buildDataSource(source.Expr, indexFlags, inScope)
+		outScope = b.buildDataSource(source.Expr, indexFlags, locking, inScope)
 
 		if source.Ordinality {
 			outScope = b.buildWithOrdinality("ordinality", outScope)
@@ -64,13 +70,17 @@ func (b *Builder) buildDataSource(
 		return outScope
 
 	case *tree.JoinTableExpr:
-		return b.buildJoin(source, inScope)
+		return b.buildJoin(source, locking, inScope)
 
 	case *tree.TableName:
 		tn := source
 
 		// CTEs take precedence over other data sources.
 		if cte := inScope.resolveCTE(tn); cte != nil {
+			if locking.hasTargetMatching(tn.TableName) {
+				panic(pgerror.Newf(pgcode.FeatureNotSupported,
+					"%s cannot be applied to a WITH query", locking.strengthName()))
+			}
 			outScope = inScope.push()
 
 			inCols := make(opt.ColList, len(cte.cols))
@@ -98,28 +108,36 @@ func (b *Builder) buildDataSource(
 		}
 
 		ds, resName := b.resolveDataSource(tn, privilege.SELECT)
+		locking = locking.filter(tn.TableName)
 		switch t := ds.(type) {
 		case cat.Table:
 			tabMeta := b.addTable(t, &resName)
-			return b.buildScan(tabMeta, nil /* ordinals */, indexFlags, excludeMutations, inScope)
+			return b.buildScan(tabMeta, nil /* ordinals */, indexFlags, locking, excludeMutations, inScope)
 
 		case cat.Sequence:
+			if locking.isSet() {
+				panic(pgerror.Newf(pgcode.WrongObjectType,
+					"%s cannot be applied to sequence %q", locking.strengthName(), tree.ErrString(tn)))
+			}
 			return b.buildSequenceSelect(t, &resName, inScope)
 
 		case cat.View:
-			return b.buildView(t, &resName, inScope)
+			return b.buildView(t, &resName, locking, inScope)
 		default:
 			panic(errors.AssertionFailedf("unknown DataSource type %T", ds))
 		}
 
 	case *tree.ParenTableExpr:
-		return b.buildDataSource(source.Expr, indexFlags, inScope)
+		return b.buildDataSource(source.Expr, indexFlags, locking, inScope)
 
 	case *tree.RowsFromExpr:
 		return b.buildZip(source.Items, inScope)
 
 	case *tree.Subquery:
-		outScope = b.buildStmt(source.Select, nil /* desiredTypes */, inScope)
+		// Locking applies to the base tables referenced by a subquery in the FROM
+		// clause, so only the locking items that explicitly target other data
+		// sources are dropped.
+		outScope = b.buildSelectStmt(source.Select, locking.withoutTargets(), nil /* desiredTypes */, inScope)
 
 		// Treat the subquery result as an anonymous data source (i.e. column names
 		// are not qualified). Remove hidden columns, as they are not accessible
@@ -141,7 +159,7 @@ func (b *Builder) buildDataSource(
 		ds := b.resolveDataSourceRef(source, privilege.SELECT)
 		switch t := ds.(type) {
 		case cat.Table:
-			outScope = b.buildScanFromTableRef(t, source, indexFlags, inScope)
+			outScope = b.buildScanFromTableRef(t, source, indexFlags, locking, inScope)
 		case cat.View:
 			if source.Columns != nil {
 				panic(pgerror.Newf(pgcode.FeatureNotSupported,
@@ -149,7 +167,7 @@ func (b *Builder) buildDataSource(
 			}
 			tn := tree.MakeUnqualifiedTableName(t.Name())
 
-			outScope = b.buildView(t, &tn, inScope)
+			outScope = b.buildView(t, &tn, locking, inScope)
 		case cat.Sequence:
 			tn := tree.MakeUnqualifiedTableName(t.Name())
 			// Any explicitly listed columns are ignored.
@@ -167,7 +185,7 @@ func (b *Builder) buildDataSource(
 
 // buildView parses the view query text and builds it as a Select expression.
 func (b *Builder) buildView(
-	view cat.View, viewName *tree.TableName, inScope *scope,
+	view cat.View, viewName *tree.TableName, locking lockingSpec, inScope *scope,
 ) (outScope *scope) {
 	// Cache the AST so that multiple references won't need to reparse.
 	if b.views == nil {
@@ -212,7 +230,7 @@ func (b *Builder) buildView(
 		defer func() { b.trackViewDeps = true }()
 	}
 
-	outScope = b.buildSelect(sel, nil /* desiredTypes */, &scope{builder: b})
+	outScope = b.buildSelect(sel, locking, nil /* desiredTypes */, &scope{builder: b})
 
 	// Update data source name to be the name of the view. And if view columns
 	// are specified, then update names of output columns.
@@ -297,7 +315,11 @@ func (b *Builder) renameSource(as tree.AliasClause, scope *scope) {
 // Note, the query SELECT * FROM [53() as t] is unsupported. Column lists must
 // be non-empty
 func (b *Builder) buildScanFromTableRef(
-	tab cat.Table, ref *tree.TableRef, indexFlags *tree.IndexFlags, inScope *scope,
+	tab cat.Table,
+	ref *tree.TableRef,
+	indexFlags *tree.IndexFlags,
+	locking lockingSpec,
+	inScope *scope,
 ) (outScope *scope) {
 	if ref.Columns != nil && len(ref.Columns) == 0 {
 		panic(pgerror.Newf(pgcode.Syntax,
@@ -331,7 +353,7 @@ func (b *Builder) buildScanFromTableRef(
 
 	tn := tree.MakeUnqualifiedTableName(tab.Name())
 	tabMeta := b.addTable(tab, &tn)
-	return b.buildScan(tabMeta, ordinals, indexFlags, excludeMutations, inScope)
+	return b.buildScan(tabMeta, ordinals, indexFlags, locking, excludeMutations, inScope)
 }
 
 // addTable adds a table to the metadata and returns the TableMeta. The table
@@ -356,6 +378,7 @@ func (b *Builder) buildScan(
 	tabMeta *opt.TableMeta,
 	ordinals []int,
 	indexFlags *tree.IndexFlags,
+	locking lockingSpec,
 	scanMutationCols bool,
 	inScope *scope,
 ) (outScope *scope) {
@@ -416,6 +439,17 @@ func (b *Builder) buildScan(
 	} else {
 		private := memo.ScanPrivate{Table: tabID, Cols: tabColIDs}
 
+		if locking.isSet() {
+			private.Locking = locking.get()
+			if private.Locking.WaitPolicy == tree.LockWaitSkip && tab.FamilyCount() > 1 {
+				// SKIP LOCKED is implemented by skipping locked keys, which could
+				// return partial rows for tables with multiple column families.
+				panic(unimplemented.NewWithIssuef(6583,
+					"SKIP LOCKED is not supported on table %q with multiple column families",
+					tree.ErrString(&tabMeta.Alias)))
+			}
+		}
+
 		if indexFlags != nil {
 			private.Flags.NoIndexJoin = indexFlags.NoIndexJoin
 			if indexFlags.Index != "" || indexFlags.IndexID != 0 {
@@ -443,6 +477,7 @@ func (b *Builder) buildScan(
 		}
 		outScope.expr = b.factory.ConstructScan(&private)
 		b.addCheckConstraintsToScan(outScope, tabMeta)
+		b.addPartialIndexPredicatesToTable(outScope, tabMeta)
 
 		if b.trackViewDeps {
 			dep := opt.ViewDep{DataSource: tab}
@@ -484,6 +519,26 @@ func (b *Builder) addCheckConstraintsToScan(scope *scope, tabMeta *opt.TableMeta
 	}
 }
 
+// addPartialIndexPredicatesToTable finds all the partial indexes of the table
+// and adds their predicates to the table metadata. To do this, the scalar
+// expressions of the predicates are built here.
+func (b *Builder) addPartialIndexPredicatesToTable(scope *scope, tabMeta *opt.TableMeta) {
+	tab := tabMeta.Table
+	for i, n := 0, tab.IndexCount(); i < n; i++ {
+		pred, isPartial := tab.Index(i).Predicate()
+		if !isPartial {
+			continue
+		}
+		expr, err := parser.ParseExpr(pred)
+		if err != nil {
+			panic(err)
+		}
+
+		texpr := scope.resolveAndRequireType(expr, types.Bool)
+		tabMeta.AddPartialIndexPredicate(i, b.buildScalar(texpr, scope, nil, nil, nil))
+	}
+}
+
 func (b *Builder) buildSequenceSelect(
 	seq cat.Sequence, seqName *tree.TableName, inScope *scope,
 ) (outScope *scope) {
@@ -643,20 +698,22 @@ func (b *Builder) wrapWithCTEs(expr memo.RelExpr, ctes []cteSource) memo.RelExpr
 // See Builder.buildStmt for a description of the remaining input and
 // return values.
 func (b *Builder) buildSelectStmt(
-	stmt tree.SelectStatement, desiredTypes []*types.T, inScope *scope,
+	stmt tree.SelectStatement, locking lockingSpec, desiredTypes []*types.T, inScope *scope,
 ) (outScope *scope) {
 	// NB: The case statements are sorted lexicographically.
 	switch stmt := stmt.(type) {
 	case *tree.ParenSelect:
-		return b.buildSelect(stmt.Select, desiredTypes, inScope)
+		return b.buildSelect(stmt.Select, locking, desiredTypes, inScope)
 
 	case *tree.SelectClause:
-		return b.buildSelectClause(stmt, nil /* orderBy */, desiredTypes, inScope)
+		return b.buildSelectClause(stmt, nil /* orderBy */, locking, desiredTypes, inScope)
 
 	case *tree.UnionClause:
+		b.rejectIfLocking(locking, "%s is not allowed with UNION/INTERSECT/EXCEPT")
 		return b.buildUnion(stmt, desiredTypes, inScope)
 
 	case *tree.ValuesClause:
+		b.rejectIfLocking(locking, "%s cannot be applied to VALUES")
 		return b.buildValuesClause(stmt, desiredTypes, inScope)
 
 	default:
@@ -670,12 +727,13 @@ func (b *Builder) buildSelectStmt(
 // See Builder.buildStmt for a description of the remaining input and
 // return values.
 func (b *Builder) buildSelect(
-	stmt *tree.Select, desiredTypes []*types.T, inScope *scope,
+	stmt *tree.Select, locking lockingSpec, desiredTypes []*types.T, inScope *scope,
 ) (outScope *scope) {
 	wrapped := stmt.Select
 	orderBy := stmt.OrderBy
 	limit := stmt.Limit
 	with := stmt.With
+	lockingClause := stmt.Locking
 
 	for s, ok := wrapped.(*tree.ParenSelect); ok; s, ok = wrapped.(*tree.ParenSelect) {
 		stmt = s.Select
@@ -705,7 +763,18 @@ func (b *Builder) buildSelect(
 			}
 			limit = stmt.Limit
 		}
+		lockingClause = append(lockingClause, stmt.Locking...)
+	}
+	if len(lockingClause) > 0 &&
+		!b.evalCtx.Settings.Version.IsActive(cluster.VersionRowLevelLocking) {
+		// Nodes at older versions ignore the locking fields of scan requests, so
+		// the rows would not be locked.
+		panic(pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			`%s requires all nodes to be upgraded to %s`,
+			lockingClause[0].Strength, cluster.VersionByKey(cluster.VersionRowLevelLocking),
+		))
 	}
+	locking = locking.apply(lockingClause)
 
 	var ctes []cteSource
 	if with != nil {
@@ -715,12 +784,14 @@ func (b *Builder) buildSelect(
 	// NB: The case statements are sorted lexicographically.
 	switch t := stmt.Select.(type) {
 	case *tree.SelectClause:
-		outScope = b.buildSelectClause(t, orderBy, desiredTypes, inScope)
+		outScope = b.buildSelectClause(t, orderBy, locking, desiredTypes, inScope)
 
 	case *tree.UnionClause:
+		b.rejectIfLocking(locking, "%s is not allowed with UNION/INTERSECT/EXCEPT")
 		outScope = b.buildUnion(t, desiredTypes, inScope)
 
 	case *tree.ValuesClause:
+		b.rejectIfLocking(locking, "%s cannot be applied to VALUES")
 		outScope = b.buildValuesClause(t, desiredTypes, inScope)
 
 	default:
@@ -761,9 +832,14 @@ func (b *Builder) buildSelect(
 // See Builder.buildStmt for a description of the remaining input and
 // return values.
 func (b *Builder) buildSelectClause(
-	sel *tree.SelectClause, orderBy tree.OrderBy, desiredTypes []*types.T, inScope *scope,
+	sel *tree.SelectClause,
+	orderBy tree.OrderBy,
+	locking lockingSpec,
+	desiredTypes []*types.T,
+	inScope *scope,
 ) (outScope *scope) {
-	fromScope := b.buildFrom(sel.From, inScope)
+	fromScope := b.buildFrom(sel.From, locking, inScope)
+	b.validateLockingInFrom(locking, fromScope)
 	b.processWindowDefs(sel, fromScope)
 	b.buildWhere(sel.Where, fromScope)
 
@@ -795,6 +871,9 @@ func (b *Builder) buildSelectClause(
 	b.buildProjectionList(fromScope, projectionsScope)
 	b.buildOrderBy(fromScope, projectionsScope, orderByScope)
 	b.buildDistinctOnArgs(fromScope, projectionsScope, distinctOnScope)
+	if locking.isSet() {
+		b.validateLockingInSelectClause(locking, sel, fromScope, needsAgg)
+	}
 	b.buildProjectSet(fromScope)
 
 	if needsAgg {
@@ -826,7 +905,7 @@ func (b *Builder) buildSelectClause(
 //
 // See Builder.buildStmt for a description of the remaining input and return
 // values.
-func (b *Builder) buildFrom(from tree.From, inScope *scope) (outScope *scope) {
+func (b *Builder) buildFrom(from tree.From, locking lockingSpec, inScope *scope) (outScope *scope) {
 	// The root AS OF clause is recognized and handled by the executor. The only
 	// thing that must be done at this point is to ensure that if any timestamps
 	// are specified, the root SELECT was an AS OF SYSTEM TIME and that the time
@@ -836,7 +915,7 @@ func (b *Builder) buildFrom(from tree.From, inScope *scope) (outScope *scope) {
 	}
 
 	if len(from.Tables) > 0 {
-		outScope = b.buildFromTables(from.Tables, inScope)
+		outScope = b.buildFromTables(from.Tables, locking, inScope)
 	} else {
 		outScope = inScope.push()
 		outScope.expr = b.factory.ConstructValues(memo.ScalarListWithEmptyTuple, &memo.ValuesPrivate{
@@ -892,15 +971,17 @@ func (b *Builder) buildWhere(where *tree.Where, inScope *scope) {
 //
 // See Builder.buildStmt for a description of the remaining input and
 // return values.
-func (b *Builder) buildFromTables(tables tree.TableExprs, inScope *scope) (outScope *scope) {
+func (b *Builder) buildFromTables(
+	tables tree.TableExprs, locking lockingSpec, inScope *scope,
+) (outScope *scope) {
 	// If there are any lateral data sources, we need to build the join tree
 	// left-deep instead of right-deep.
 	for i := range tables {
 		if b.exprIsLateral(tables[i]) {
-			return b.buildFromWithLateral(tables, inScope)
+			return b.buildFromWithLateral(tables, locking, inScope)
 		}
 	}
-	return b.buildFromTablesRightDeep(tables, inScope)
+	return b.buildFromTablesRightDeep(tables, locking, inScope)
 }
 
 // buildFromTablesRightDeep recursively builds a series of InnerJoin
@@ -921,16 +1002,16 @@ func (b *Builder) buildFromTables(tables tree.TableExprs, inScope *scope) (outSc
 // See Builder.buildStmt for a description of the remaining input and
 // return values.
 func (b *Builder) buildFromTablesRightDeep(
-	tables tree.TableExprs, inScope *scope,
+	tables tree.TableExprs, locking lockingSpec, inScope *scope,
 ) (outScope *scope) {
-	outScope = b.buildDataSource(tables[0], nil /* indexFlags */, inScope)
+	outScope = b.buildDataSource(tables[0], nil /* indexFlags */, locking, inScope)
 
 	// Recursively build table join.
 	tables = tables[1:]
 	if len(tables) == 0 {
 		return outScope
 	}
-	tableScope := b.buildFromTablesRightDeep(tables, inScope)
+	tableScope := b.buildFromTablesRightDeep(tables, locking, inScope)
 
 	// Check that the same table name is not used multiple times.
 	b.validateJoinTableNames(outScope, tableScope)
@@ -969,8 +1050,10 @@ func (b *Builder) exprIsLateral(t tree.TableExpr) bool {
 //
 //   buildFromTablesRightDeep: a JOIN (b JOIN c)
 //   buildFromWithLateral:     (a JOIN b) JOIN c
-func (b *Builder) buildFromWithLateral(tables tree.TableExprs, inScope *scope) (outScope *scope) {
-	outScope = b.buildDataSource(tables[0], nil /* indexFlags */, inScope)
+func (b *Builder) buildFromWithLateral(
+	tables tree.TableExprs, locking lockingSpec, inScope *scope,
+) (outScope *scope) {
+	outScope = b.buildDataSource(tables[0], nil /* indexFlags */, locking, inScope)
 	for i := 1; i < len(tables); i++ {
 		scope := inScope
 		// Lateral expressions need to be able to refer to the expressions that
@@ -978,7 +1061,7 @@ func (b *Builder) buildFromWithLateral(tables tree.TableExprs, inScope *scope) (
 		if b.exprIsLateral(tables[i]) {
 			scope = outScope
 		}
-		tableScope := b.buildDataSource(tables[i], nil /* indexFlags */, scope)
+		tableScope := b.buildDataSource(tables[i], nil /* indexFlags */, locking, scope)
 
 		// Check that the same table name is not used multiple times.
 		b.validateJoinTableNames(outScope, tableScope)
@@ -412,9 +412,18 @@ func (b *Builder) resolveAndBuildScalar(
 }
 
 // resolveSchemaForCreate returns the schema that will contain a newly created
-// catalog object with the given name. If the current user does not have the
-// CREATE privilege, then resolveSchemaForCreate raises an error.
-func (b *Builder) resolveSchemaForCreate(name *tree.TableName) (cat.Schema, cat.SchemaName) {
+// catalog object with the given name, along with whether the object is
+// temporary, in which case the schema is the session's temporary schema. If
+// the current user does not have the CREATE privilege, then
+// resolveSchemaForCreate raises an error.
+func (b *Builder) resolveSchemaForCreate(
+	name *tree.TableName, temporary bool,
+) (cat.Schema, cat.SchemaName, bool) {
+	temporary, err := name.QualifyTemporaryTarget(temporary, b.evalCtx.SessionData.TemporarySchemaName)
+	if err != nil {
+		panic(err)
+	}
+
 	flags := cat.Flags{AvoidDescriptorCaches: true}
 	sch, resName, err := b.catalog.ResolveSchema(b.ctx, flags, &name.TableNamePrefix)
 	if err != nil {
@@ -432,8 +441,9 @@ func (b *Builder) resolveSchemaForCreate(name *tree.TableName) (cat.Schema, cat.
 		panic(err)
 	}
 
-	// Only allow creation of objects in the public schema.
-	if resName.Schema() != tree.PublicSchema {
+	// Only allow creation of objects in the public schema or in the session's
+	// temporary schema.
+	if resName.Schema() != tree.PublicSchema && !temporary {
 		panic(pgerror.Newf(pgcode.InvalidName,
 			"schema cannot be modified: %q", tree.ErrString(&resName)))
 	}
@@ -442,7 +452,7 @@ func (b *Builder) resolveSchemaForCreate(name *tree.TableName) (cat.Schema, cat.
 		panic(err)
 	}
 
-	return sch, resName
+	return sch, resName, temporary
 }
 
 // resolveTable returns the data source in the catalog with the given name. If
@@ -156,6 +156,7 @@ func newMetadata(compiled *lang.CompiledExpr, pkg string) *metadata {
 		"TupleOrdinal":   {fullName: "memo.TupleOrdinal", passByVal: true},
 		"ScanLimit":      {fullName: "memo.ScanLimit", passByVal: true},
 		"ScanFlags":      {fullName: "memo.ScanFlags", passByVal: true},
+		"LockingItem":    {fullName: "*tree.LockingItem", isPointer: true},
 		"JoinFlags":      {fullName: "memo.JoinFlags", passByVal: true},
 		"WindowFrame":    {fullName: "memo.WindowFrame", passByVal: true},
 		"ExplainOptions": {fullName: "tree.ExplainOptions", passByVal: true},
@@ -140,6 +140,12 @@ type TableMeta struct {
 	// in certain queries. See comment above GenerateConstrainedScans for more
 	// detail.
 	constraints []ScalarExpr
+
+	// partialIndexPredicates maps the ordinals of the table's partial indexes
+	// to their predicates, stored in the ScalarExpr form so that they can be
+	// compared with query filters. A partial index can only be scanned when the
+	// filters imply its predicate.
+	partialIndexPredicates map[cat.IndexOrdinal]ScalarExpr
 }
 
 // clearAnnotations resets all the table annotations; used when copying a
@@ -194,6 +200,22 @@ func (tm *TableMeta) AddConstraint(constraint ScalarExpr) {
 	tm.constraints = append(tm.constraints, constraint)
 }
 
+// AddPartialIndexPredicate adds the predicate of the partial index with the
+// given ordinal to the table's metadata.
+func (tm *TableMeta) AddPartialIndexPredicate(ord cat.IndexOrdinal, pred ScalarExpr) {
+	if tm.partialIndexPredicates == nil {
+		tm.partialIndexPredicates = make(map[cat.IndexOrdinal]ScalarExpr)
+	}
+	tm.partialIndexPredicates[ord] = pred
+}
+
+// PartialIndexPredicate returns the predicate of the partial index with the
+// given ordinal, and false if the index is not partial.
+func (tm *TableMeta) PartialIndexPredicate(ord cat.IndexOrdinal) (ScalarExpr, bool) {
+	pred, ok := tm.partialIndexPredicates[ord]
+	return pred, ok
+}
+
 // TableAnnotation returns the given annotation that is associated with the
 // given table. If the table has no such annotation, TableAnnotation returns
 // nil.
@@ -397,6 +397,9 @@ func (tt *Table) addIndex(def *tree.IndexTableDef, typ indexType) *Index {
 		table:       tt,
 		partitionBy: def.PartitionBy,
 	}
+	if def.Predicate != nil {
+		idx.predicate = tree.Serialize(def.Predicate)
+	}
 
 	// Look for name suffixes indicating this is a mutation index.
 	if name, ok := extractWriteOnlyIndex(def); ok {
@@ -706,6 +706,10 @@ type Index struct {
 	// Inverted is true when this index is an inverted index.
 	Inverted bool
 
+	// predicate is the partial index predicate, or the empty string if the
+	// index is not partial.
+	predicate string
+
 	Columns []cat.IndexColumn
 
 	// IdxZone is the zone associated with the index. This may be inherited from
@@ -753,6 +757,11 @@ func (ti *Index) IsInverted() bool {
 	return ti.Inverted
 }
 
+// Predicate is part of the cat.Index interface.
+func (ti *Index) Predicate() (string, bool) {
+	return ti.predicate, ti.predicate != ""
+}
+
 // ColumnCount is part of the cat.Index interface.
 func (ti *Index) ColumnCount() int {
 	return len(ti.Columns)
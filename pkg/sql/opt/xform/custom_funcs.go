@@ -498,7 +498,7 @@ func (c *CustomFuncs) GenerateConstrainedScans(
 	var iter scanIndexIter
 	md := c.e.mem.Metadata()
 	tabMeta := md.TableMeta(scanPrivate.Table)
-	iter.init(c.e.mem, scanPrivate)
+	iter.initWithFilters(c.e.mem, c.e.evalCtx, scanPrivate, explicitFilters)
 	for iter.next() {
 		var isIndexPartitioned bool
 		indexColumns := tabMeta.IndexKeyColumns(iter.indexOrdinal)
@@ -530,7 +530,14 @@ func (c *CustomFuncs) GenerateConstrainedScans(
 		constraint, remainingFilters, ok := c.tryConstrainIndex(
 			filters, scanPrivate.Table, iter.indexOrdinal, false /* isInverted */)
 		if !ok {
-			continue
+			if _, isPartial := iter.index.Predicate(); !isPartial {
+				continue
+			}
+			// The filters imply the predicate of the partial index, so the whole
+			// index can be scanned even though it can't be constrained. This is
+			// the only place where such a scan is generated.
+			constraint, remainingFilters = nil, explicitFilters
+			isIndexPartitioned = false
 		}
 
 		// If the index is partitioned (by list), then the constraints above only
@@ -1106,6 +1113,10 @@ func (c *CustomFuncs) GenerateLookupJoins(
 	if joinPrivate.Flags.DisallowLookupJoin {
 		return
 	}
+	if scanPrivate.Locking != nil {
+		// Lookup joins do not acquire row-level locks.
+		return
+	}
 	inputProps := input.Relational()
 
 	leftEq, rightEq := memo.ExtractJoinEqualityColumns(inputProps.OutputCols, scanPrivate.Cols, on)
@@ -1396,8 +1407,9 @@ func (c *CustomFuncs) GenerateZigzagJoins(
 	grp memo.RelExpr, scanPrivate *memo.ScanPrivate, filters memo.FiltersExpr,
 ) {
 
-	// Short circuit unless zigzag joins are explicitly enabled.
-	if !c.e.evalCtx.SessionData.ZigzagJoinEnabled {
+	// Short circuit unless zigzag joins are explicitly enabled. Zigzag joins
+	// do not acquire row-level locks.
+	if !c.e.evalCtx.SessionData.ZigzagJoinEnabled || scanPrivate.Locking != nil {
 		return
 	}
 
@@ -1620,8 +1632,9 @@ func (c *CustomFuncs) GenerateZigzagJoins(
 func (c *CustomFuncs) GenerateInvertedIndexZigzagJoins(
 	grp memo.RelExpr, scanPrivate *memo.ScanPrivate, filters memo.FiltersExpr,
 ) {
-	// Short circuit unless zigzag joins are explicitly enabled.
-	if !c.e.evalCtx.SessionData.ZigzagJoinEnabled {
+	// Short circuit unless zigzag joins are explicitly enabled. Zigzag joins
+	// do not acquire row-level locks.
+	if !c.e.evalCtx.SessionData.ZigzagJoinEnabled || scanPrivate.Locking != nil {
 		return
 	}
 
@@ -1952,9 +1965,13 @@ func (c *CustomFuncs) MakeOrderingChoiceFromColumn(
 //     doSomething(iter.indexOrdinal)
 //   }
 //
+// Partial indexes are only enumerated if the iterator was initialized with
+// initWithFilters, and only when the filters imply the index predicate.
 type scanIndexIter struct {
 	mem          *memo.Memo
+	evalCtx      *tree.EvalContext
 	scanPrivate  *memo.ScanPrivate
+	filters      memo.FiltersExpr
 	tab          cat.Table
 	indexOrdinal cat.IndexOrdinal
 	index        cat.Index
@@ -1969,12 +1986,27 @@ func (it *scanIndexIter) init(mem *memo.Memo, scanPrivate *memo.ScanPrivate) {
 	it.index = nil
 }
 
+// initWithFilters is like init, but also enumerates the partial indexes whose
+// predicate is implied by the given filters.
+func (it *scanIndexIter) initWithFilters(
+	mem *memo.Memo,
+	evalCtx *tree.EvalContext,
+	scanPrivate *memo.ScanPrivate,
+	filters memo.FiltersExpr,
+) {
+	it.init(mem, scanPrivate)
+	it.evalCtx = evalCtx
+	it.filters = filters
+}
+
 // next advances iteration to the next index of the Scan operator's table. This
 // is the primary index if it's the first time next is called, or a secondary
-// index thereafter. Inverted index are skipped. If the ForceIndex flag is set,
-// then all indexes except the forced index are skipped. When there are no more
-// indexes to enumerate, next returns false. The current index is accessible via
-// the iterator's "index" field.
+// index thereafter. Inverted index are skipped, as are partial indexes that
+// can't be used (see scanIndexIter). If the ForceIndex flag is set, then all
+// indexes except the forced index are skipped. Secondary indexes are skipped
+// for scans that perform row-level locking. When there are no more indexes to
+// enumerate, next returns false. The current index is accessible via the
+// iterator's "index" field.
 func (it *scanIndexIter) next() bool {
 	for {
 		it.indexOrdinal++
@@ -1986,6 +2018,14 @@ func (it *scanIndexIter) next() bool {
 		if it.index.IsInverted() {
 			continue
 		}
+		if !it.canUsePartialIndex() {
+			continue
+		}
+		if it.scanPrivate.Locking != nil && it.indexOrdinal != cat.PrimaryIndex {
+			// Row-level locks are acquired on the primary index, so locking scans
+			// cannot be replaced by scans over secondary indexes.
+			continue
+		}
 		if it.scanPrivate.Flags.ForceIndex && it.scanPrivate.Flags.Index != it.indexOrdinal {
 			// If we are forcing a specific index, ignore the others.
 			continue
@@ -2011,6 +2051,13 @@ func (it *scanIndexIter) nextInverted() bool {
 		if !it.index.IsInverted() {
 			continue
 		}
+		if !it.canUsePartialIndex() {
+			continue
+		}
+		if it.scanPrivate.Locking != nil {
+			// Row-level locks are acquired on the primary index.
+			continue
+		}
 		if it.scanPrivate.Flags.ForceIndex && it.scanPrivate.Flags.Index != it.indexOrdinal {
 			// If we are forcing a specific index, ignore the others.
 			continue
@@ -2020,6 +2067,85 @@ func (it *scanIndexIter) nextInverted() bool {
 	}
 }
 
+// canUsePartialIndex returns false if the current index is a partial index
+// whose predicate is not implied by the iterator's filters. A partial index
+// only contains the rows which satisfy its predicate, so scanning it instead
+// of the primary index could otherwise miss rows.
+func (it *scanIndexIter) canUsePartialIndex() bool {
+	if _, isPartial := it.index.Predicate(); !isPartial {
+		return true
+	}
+	if len(it.filters) == 0 {
+		return false
+	}
+	tabMeta := it.mem.Metadata().TableMeta(it.scanPrivate.Table)
+	pred, ok := tabMeta.PartialIndexPredicate(it.indexOrdinal)
+	if !ok {
+		return false
+	}
+	return filtersImplyPredicate(it.mem, it.evalCtx, it.filters, pred)
+}
+
+// filtersImplyPredicate returns true if every row which satisfies the given
+// filters is guaranteed to also satisfy the given partial index predicate.
+// The check is conservative: each conjunct of the predicate must either be
+// identical to one of the filter conditions (expressions are interned by the
+// memo), or be exactly described by its constraints, which in turn contain the
+// constraints derived from the filters. For example, the filter a > 10 implies
+// the predicate a > 0.
+func filtersImplyPredicate(
+	mem *memo.Memo, evalCtx *tree.EvalContext, filters memo.FiltersExpr, pred opt.ScalarExpr,
+) bool {
+	for i := range filters {
+		if filters[i].Condition == pred {
+			return true
+		}
+	}
+	if and, ok := pred.(*memo.AndExpr); ok {
+		return filtersImplyPredicate(mem, evalCtx, filters, and.Left) &&
+			filtersImplyPredicate(mem, evalCtx, filters, and.Right)
+	}
+
+	predItem := memo.FiltersItem{Condition: pred}
+	predProps := predItem.ScalarProps(mem)
+	if predProps.Constraints == nil || !predProps.TightConstraints {
+		return false
+	}
+
+	filterConstraints := constraint.Unconstrained
+	for i := range filters {
+		if c := filters[i].ScalarProps(mem).Constraints; c != nil {
+			filterConstraints = filterConstraints.Intersect(evalCtx, c)
+		}
+	}
+	if filterConstraints == constraint.Contradiction {
+		// The filters are never satisfied.
+		return true
+	}
+
+	for i, n := 0, predProps.Constraints.Length(); i < n; i++ {
+		predConstraint := predProps.Constraints.Constraint(i)
+		implied := false
+		for j, m := 0, filterConstraints.Length(); j < m && !implied; j++ {
+			filterConstraint := filterConstraints.Constraint(j)
+			if !filterConstraint.Columns.Equals(&predConstraint.Columns) {
+				continue
+			}
+			implied = true
+			for k, l := 0, filterConstraint.Spans.Count(); k < l; k++ {
+				if !predConstraint.ContainsSpan(evalCtx, filterConstraint.Spans.Get(k)) {
+					implied = false
+					break
+				}
+			}
+		}
+		if !implied {
+			return false
+		}
+	}
+	return true
+}
+
 // indexCols returns the set of columns contained in the current index.
 func (it *scanIndexIter) indexCols() opt.ColSet {
 	if it.cols.Empty() {
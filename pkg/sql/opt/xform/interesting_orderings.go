@@ -66,6 +66,9 @@ func interestingOrderingsForScan(scan *memo.ScanExpr) opt.OrderingSet {
 		if index.IsInverted() {
 			continue
 		}
+		if _, isPartial := index.Predicate(); isPartial {
+			continue
+		}
 		numIndexCols := index.KeyColumnCount()
 		var o opt.Ordering
 		for j := 0; j < numIndexCols; j++ {
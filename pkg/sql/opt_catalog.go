@@ -903,6 +903,11 @@ func (oi *optIndex) IsInverted() bool {
 	return oi.desc.Type == sqlbase.IndexDescriptor_INVERTED
 }
 
+// Predicate is part of the cat.Index interface.
+func (oi *optIndex) Predicate() (string, bool) {
+	return oi.desc.Predicate, oi.desc.IsPartial()
+}
+
 // ColumnCount is part of the cat.Index interface.
 func (oi *optIndex) ColumnCount() int {
 	return oi.numCols
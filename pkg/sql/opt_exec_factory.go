@@ -77,6 +77,7 @@ func (ef *execFactory) ConstructScan(
 	maxResults uint64,
 	reqOrdering exec.OutputOrdering,
 	rowCount float64,
+	locking *tree.LockingItem,
 ) (exec.Node, error) {
 	tabDesc := table.(*optTable).desc
 	indexDesc := index.(*optIndex).desc
@@ -123,6 +124,10 @@ func (ef *execFactory) ConstructScan(
 	scan.props.ordering = sqlbase.ColumnOrdering(reqOrdering)
 	scan.estimatedRowCount = uint64(rowCount)
 	scan.createdByOpt = true
+	if locking != nil {
+		scan.lockingStrength = locking.Strength
+		scan.lockingWaitPolicy = locking.WaitPolicy
+	}
 	return scan, nil
 }
 
@@ -1802,6 +1807,7 @@ func (ef *execFactory) ConstructCreateTable(
 func (ef *execFactory) ConstructCreateView(
 	schema cat.Schema,
 	viewName string,
+	temporary bool,
 	viewQuery string,
 	columns sqlbase.ResultColumns,
 	deps opt.ViewDeps,
@@ -1832,6 +1838,7 @@ func (ef *execFactory) ConstructCreateView(
 
 	return &createViewNode{
 		viewName:  tree.Name(viewName),
+		temporary: temporary,
 		viewQuery: viewQuery,
 		dbDesc:    schema.(*optSchema).desc,
 		columns:   columns,
@@ -195,6 +195,9 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *controlJobsNode:
 		p.setUnlimited(n.rows)
 
+	case *controlSchedulesNode:
+		p.setUnlimited(n.rows)
+
 	case *errorIfRowsNode:
 		p.setUnlimited(n.plan)
 
@@ -209,6 +212,7 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *alterIndexNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterTypeNode:
 	case *alterUserSetPasswordNode:
 	case *deleteRangeNode:
 	case *renameColumnNode:
@@ -227,11 +231,13 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *createViewNode:
 	case *createSequenceNode:
 	case *createStatsNode:
+	case *createTypeNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
+	case *dropTypeNode:
 	case *DropUserNode:
 	case *explainVecNode:
 	case *zeroNode:
@@ -63,6 +63,10 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER SEQUENCE blah RENAME ??`, `ALTER SEQUENCE`},
 		{`ALTER SEQUENCE blah RENAME TO blih ??`, `ALTER SEQUENCE`},
 
+		{`ALTER TYPE ??`, `ALTER TYPE`},
+		{`ALTER TYPE blah ADD VALUE ??`, `ALTER TYPE`},
+		{`ALTER TYPE blah ADD VALUE 'foo' BEFORE ??`, `ALTER TYPE`},
+
 		{`ALTER USER IF ??`, `ALTER USER`},
 		{`ALTER USER foo WITH PASSWORD ??`, `ALTER USER`},
 
@@ -109,8 +113,14 @@ func TestContextualHelp(t *testing.T) {
 
 		{`CREATE SEQUENCE ??`, `CREATE SEQUENCE`},
 
+		{`CREATE TYPE blah AS ENUM ??`, `CREATE TYPE`},
+		{`CREATE TYPE blah AS ENUM (??`, `CREATE TYPE`},
+
 		{`CREATE STATISTICS ??`, `CREATE STATISTICS`},
 
+		{`CREATE SCHEDULE ??`, `CREATE SCHEDULE FOR BACKUP`},
+		{`CREATE SCHEDULE FOR BACKUP ??`, `CREATE SCHEDULE FOR BACKUP`},
+
 		{`CREATE TABLE blah (??`, `CREATE TABLE`},
 		{`CREATE TABLE IF NOT ??`, `CREATE TABLE`},
 		{`CREATE TABLE blah (x, y) AS ??`, `CREATE TABLE`},
@@ -139,10 +149,15 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP ROLE IF ??`, `DROP ROLE`},
 		{`DROP ROLE IF EXISTS bluh ??`, `DROP ROLE`},
 
+		{`DROP SCHEDULE ??`, `DROP SCHEDULES`},
+
 		{`DROP SEQUENCE blah ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF ??`, `DROP SEQUENCE`},
 		{`DROP SEQUENCE IF EXISTS blih, bloh ??`, `DROP SEQUENCE`},
 
+		{`DROP TYPE ??`, `DROP TYPE`},
+		{`DROP TYPE IF EXISTS blih, bloh ??`, `DROP TYPE`},
+
 		{`DROP TABLE blah ??`, `DROP TABLE`},
 		{`DROP TABLE IF ??`, `DROP TABLE`},
 		{`DROP TABLE IF EXISTS blih, bloh ??`, `DROP TABLE`},
@@ -245,6 +260,8 @@ func TestContextualHelp(t *testing.T) {
 		{`SHOW JOBS ??`, `SHOW JOBS`},
 		{`SHOW AUTOMATIC JOBS ??`, `SHOW JOBS`},
 
+		{`SHOW SCHEDULES ??`, `SHOW SCHEDULES`},
+
 		{`SHOW BACKUP 'foo' ??`, `SHOW BACKUP`},
 
 		{`SHOW CLUSTER SETTING all ??`, `SHOW CLUSTER SETTING`},
@@ -85,11 +85,14 @@ func TestParse(t *testing.T) {
 		{`CREATE INDEX ON a (b) INTERLEAVE IN PARENT c (d)`},
 		{`CREATE INDEX ON a (b) INTERLEAVE IN PARENT c.d (e)`},
 		{`CREATE INDEX ON a (b ASC, c DESC)`},
+		{`CREATE INDEX a ON b (c) WHERE d > 0`},
+		{`CREATE INDEX a ON b (c) STORING (d) WHERE e IS NULL`},
 		{`CREATE UNIQUE INDEX a ON b (c)`},
 		{`CREATE UNIQUE INDEX a ON b (c) STORING (d)`},
 		{`CREATE UNIQUE INDEX a ON b (c) INTERLEAVE IN PARENT d (e, f)`},
 		{`CREATE UNIQUE INDEX a ON b (c) INTERLEAVE IN PARENT d.e (f, g)`},
 		{`CREATE UNIQUE INDEX a ON b.c (d)`},
+		{`CREATE UNIQUE INDEX a ON b (c) WHERE deleted_at IS NULL`},
 		{`CREATE INVERTED INDEX a ON b (c)`},
 		{`CREATE INVERTED INDEX a ON b.c (d)`},
 		{`CREATE INVERTED INDEX a ON b (c) STORING (d)`},
@@ -168,7 +171,9 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT d UNIQUE (b, c) INTERLEAVE IN PARENT d (e, f))`},
 		{`CREATE TABLE a (b INT8, UNIQUE (b))`},
 		{`CREATE TABLE a (b INT8, UNIQUE (b) STORING (c))`},
+		{`CREATE TABLE a (b INT8, c INT8, UNIQUE (b) WHERE c > 0)`},
 		{`CREATE TABLE a (b INT8, INDEX (b))`},
+		{`CREATE TABLE a (b INT8, c INT8, INDEX (b) WHERE c IS NULL)`},
 		{`CREATE TABLE a (b INT8, INVERTED INDEX (b))`},
 		{`CREATE TABLE a (b INT8, c INT8 REFERENCES foo)`},
 		{`CREATE TABLE a (b INT8, c INT8 REFERENCES foo ON UPDATE RESTRICT)`},
@@ -205,6 +210,8 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT8) INTERLEAVE IN PARENT foo (c) CASCADE`},
 		{`CREATE TABLE a.b (b INT8)`},
 		{`CREATE TABLE IF NOT EXISTS a (b INT8)`},
+		{`CREATE TEMPORARY TABLE a (b INT8)`},
+		{`CREATE TEMPORARY TABLE IF NOT EXISTS a (b INT8)`},
 		{`CREATE TABLE a (b INT8 AS (a + b) STORED)`},
 		{`CREATE TABLE view (view INT8)`},
 
@@ -240,6 +247,7 @@ func TestParse(t *testing.T) {
 		{`ALTER INDEX a@idx PARTITION BY LIST (b) (PARTITION p1 VALUES IN (1))`},
 
 		{`CREATE TABLE a AS SELECT * FROM b`},
+		{`CREATE TEMPORARY TABLE a AS SELECT * FROM b`},
 		{`CREATE TABLE IF NOT EXISTS a AS SELECT * FROM b`},
 		{`CREATE TABLE a AS SELECT * FROM b ORDER BY c`},
 		{`CREATE TABLE IF NOT EXISTS a AS SELECT * FROM b ORDER BY c`},
@@ -268,6 +276,7 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b STRING(3)[] COLLATE de)`},
 
 		{`CREATE VIEW a AS SELECT * FROM b`},
+		{`CREATE TEMPORARY VIEW a AS SELECT * FROM b`},
 		{`EXPLAIN CREATE VIEW a AS SELECT * FROM b`},
 		{`CREATE VIEW a AS SELECT b.* FROM b LIMIT 5`},
 		{`CREATE VIEW a AS (SELECT c, d FROM b WHERE c > 0 ORDER BY c)`},
@@ -276,6 +285,13 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
 
+		{`CREATE TYPE a AS ENUM ()`},
+		{`CREATE TYPE a AS ENUM ('b', 'c')`},
+		{`CREATE TYPE a.b AS ENUM ('c')`},
+		{`EXPLAIN CREATE TYPE a AS ENUM ('b')`},
+		{`CREATE TABLE a (b c)`},
+		{`CREATE TABLE a (b "C")`},
+
 		{`CREATE SEQUENCE a`},
 		{`EXPLAIN CREATE SEQUENCE a`},
 		{`CREATE SEQUENCE IF NOT EXISTS a`},
@@ -362,6 +378,15 @@ func TestParse(t *testing.T) {
 		{`DROP SEQUENCE IF EXISTS a`},
 		{`DROP SEQUENCE a RESTRICT`},
 		{`DROP SEQUENCE IF EXISTS a, b RESTRICT`},
+		{`DROP TYPE a`},
+		{`DROP TYPE a.b`},
+		{`DROP TYPE IF EXISTS a, b`},
+		{`DROP TYPE a RESTRICT`},
+		{`DROP TYPE a, b CASCADE`},
+		{`ALTER TYPE a ADD VALUE 'b'`},
+		{`ALTER TYPE a.b ADD VALUE IF NOT EXISTS 'c'`},
+		{`ALTER TYPE a ADD VALUE 'b' BEFORE 'c'`},
+		{`ALTER TYPE a ADD VALUE IF NOT EXISTS 'b' AFTER 'c'`},
 		{`DROP SEQUENCE a.b CASCADE`},
 		{`DROP SEQUENCE a, b CASCADE`},
 
@@ -377,6 +402,9 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN RESUME JOBS SELECT a`},
 		{`PAUSE JOBS SELECT a`},
 		{`EXPLAIN PAUSE JOBS SELECT a`},
+		{`PAUSE SCHEDULES SELECT a`},
+		{`RESUME SCHEDULES SELECT a`},
+		{`DROP SCHEDULES SELECT a`},
 		{`SHOW JOBS SELECT a`},
 		{`EXPLAIN SHOW JOBS SELECT a`},
 
@@ -429,6 +457,7 @@ func TestParse(t *testing.T) {
 		{`SHOW USERS`},
 		{`EXPLAIN SHOW USERS`},
 		{`SHOW JOBS`},
+		{`SHOW SCHEDULES`},
 		{`EXPLAIN SHOW JOBS`},
 		{`SHOW AUTOMATIC JOBS`},
 		{`EXPLAIN SHOW AUTOMATIC JOBS`},
@@ -749,6 +778,9 @@ func TestParse(t *testing.T) {
 		{`SELECT "FROM" FROM t`},
 		{`SELECT CAST(1 AS STRING)`},
 		{`SELECT ANNOTATE_TYPE(1, STRING)`},
+		{`SELECT CAST(1 AS a)`},
+		{`SELECT ANNOTATE_TYPE('b', a)`},
+		{`SELECT 'b'::a`},
 		{`SELECT a FROM t AS bar`},
 		{`SELECT a FROM t AS bar (bar1)`},
 		{`SELECT a FROM t AS bar (bar1, bar2, bar3)`},
@@ -977,6 +1009,22 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t OFFSET b`},
 		{`SELECT a FROM t LIMIT a OFFSET b`},
 		{`SELECT DISTINCT * FROM t`},
+
+		{`SELECT * FROM t FOR UPDATE`},
+		{`SELECT * FROM t FOR NO KEY UPDATE`},
+		{`SELECT * FROM t FOR SHARE`},
+		{`SELECT * FROM t FOR KEY SHARE`},
+		{`SELECT * FROM t FOR UPDATE OF t`},
+		{`SELECT * FROM t, u FOR SHARE OF t, u`},
+		{`SELECT * FROM t FOR UPDATE NOWAIT`},
+		{`SELECT * FROM t FOR UPDATE SKIP LOCKED`},
+		{`SELECT * FROM t FOR UPDATE OF t SKIP LOCKED`},
+		{`SELECT * FROM t, u FOR UPDATE OF t FOR SHARE OF u NOWAIT`},
+		{`SELECT * FROM t ORDER BY a FOR UPDATE`},
+		{`SELECT * FROM t LIMIT 1 FOR UPDATE`},
+		{`SELECT * FROM t ORDER BY a LIMIT 1 OFFSET 2 FOR SHARE SKIP LOCKED`},
+		{`WITH a AS (SELECT 1) SELECT * FROM t FOR UPDATE`},
+		{`SELECT * FROM (SELECT * FROM t FOR UPDATE) AS s`},
 		{`SELECT DISTINCT a, b FROM t`},
 		{`SELECT DISTINCT ON (a, b) c FROM t`},
 
@@ -1105,6 +1153,8 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a DROP CONSTRAINT b CASCADE`},
 		{`ALTER TABLE a DROP CONSTRAINT IF EXISTS b RESTRICT`},
 		{`ALTER TABLE a VALIDATE CONSTRAINT a`},
+		{`ALTER TABLE a ALTER PRIMARY KEY USING COLUMNS (b)`},
+		{`ALTER TABLE a ALTER PRIMARY KEY USING COLUMNS (b DESC, c ASC)`},
 
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT 42`},
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT NULL`},
@@ -1273,6 +1323,11 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN SHOW BACKUP 'bar'`},
 		{`SHOW BACKUP RANGES 'bar'`},
 		{`SHOW BACKUP FILES 'bar'`},
+		{`SHOW BACKUP 'bar' WITH encryption_passphrase = 'secret'`},
+		{`SHOW BACKUP SCHEMAS 'bar' WITH encryption_passphrase = 'secret'`},
+		{`SHOW BACKUP 'bar' WITH check_files`},
+		{`SHOW BACKUP 'foo', 'bar', 'baz' WITH check_files`},
+		{`SHOW BACKUP $1, $2 WITH check_files`},
 
 		{`BACKUP TABLE foo TO 'bar' AS OF SYSTEM TIME '1' INCREMENTAL FROM 'baz'`},
 		{`BACKUP TABLE foo TO $1 INCREMENTAL FROM 'bar', $2, 'baz'`},
@@ -1304,6 +1359,9 @@ func TestParse(t *testing.T) {
 		{`RESTORE DATABASE foo FROM ($1, $2), ($3, $4) AS OF SYSTEM TIME '1'`},
 
 		{`BACKUP TABLE foo TO 'bar' WITH key1, key2 = 'value'`},
+		{`CREATE SCHEDULE FOR BACKUP TABLE foo TO 'bar' RECURRING '@hourly'`},
+		{`CREATE SCHEDULE 'baz' FOR BACKUP DATABASE foo TO 'bar' WITH revision_history RECURRING '@hourly' FULL BACKUP '@daily'`},
+		{`CREATE SCHEDULE $1 FOR BACKUP TABLE foo TO $2 RECURRING $3 FULL BACKUP ALWAYS`},
 		{`RESTORE TABLE foo FROM 'bar' WITH key1, key2 = 'value'`},
 
 		{`IMPORT TABLE foo CREATE USING 'nodelocal:///some/file' CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
@@ -1334,6 +1392,9 @@ func TestParse(t *testing.T) {
 		// {`CREATE CHANGEFEED FOR TABLE foo PARTITION bar, baz INTO 'sink'`},
 		// {`CREATE CHANGEFEED FOR DATABASE foo INTO 'sink'`},
 		{`CREATE CHANGEFEED FOR TABLE foo INTO 'sink' WITH bar = 'baz'`},
+		{`CREATE CHANGEFEED AS SELECT * FROM foo`},
+		{`CREATE CHANGEFEED INTO 'sink' AS SELECT a, b + 1 AS c FROM db.foo WHERE a > 1`},
+		{`CREATE CHANGEFEED INTO 'sink' WITH bar = 'baz' AS SELECT a FROM foo WHERE b = 'x'`},
 
 		// Regression for #15926
 		{`SELECT * FROM ((t1 NATURAL JOIN t2 WITH ORDINALITY AS o1)) WITH ORDINALITY AS o2`},
@@ -1364,6 +1425,14 @@ func TestParse2(t *testing.T) {
 	}{
 		{`CREATE DATABASE a WITH ENCODING = 'foo'`,
 			`CREATE DATABASE a ENCODING = 'foo'`},
+		{`CREATE TEMP TABLE a (b INT8)`,
+			`CREATE TEMPORARY TABLE a (b INT8)`},
+		{`CREATE LOCAL TEMP TABLE a (b INT8)`,
+			`CREATE TEMPORARY TABLE a (b INT8)`},
+		{`CREATE GLOBAL TEMPORARY TABLE a AS SELECT * FROM b`,
+			`CREATE TEMPORARY TABLE a AS SELECT * FROM b`},
+		{`CREATE TEMP VIEW a AS SELECT * FROM b`,
+			`CREATE TEMPORARY VIEW a AS SELECT * FROM b`},
 		{`CREATE DATABASE a TEMPLATE = template0`,
 			`CREATE DATABASE a TEMPLATE = 'template0'`},
 		{`CREATE DATABASE a TEMPLATE = invalid`,
@@ -1374,6 +1443,8 @@ func TestParse2(t *testing.T) {
 			`CREATE TABLE a (b INT8, CONSTRAINT foo UNIQUE (b) INTERLEAVE IN PARENT c (d))`},
 		{`CREATE TABLE a (UNIQUE INDEX (b) PARTITION BY LIST (c) (PARTITION d VALUES IN (1)))`,
 			`CREATE TABLE a (UNIQUE (b) PARTITION BY LIST (c) (PARTITION d VALUES IN (1)))`},
+		{`CREATE TABLE a (b INT, c INT, UNIQUE INDEX foo (b) WHERE c IS NULL)`,
+			`CREATE TABLE a (b INT8, c INT8, CONSTRAINT foo UNIQUE (b) WHERE c IS NULL)`},
 		{`CREATE INDEX ON a (b) COVERING (c)`, `CREATE INDEX ON a (b) STORING (c)`},
 
 		{`CREATE INDEX a ON b USING GIN (c)`,
@@ -1756,6 +1827,9 @@ func TestParse2(t *testing.T) {
 		{`RESUME JOB a`, `RESUME JOBS VALUES (a)`},
 		{`EXPLAIN RESUME JOB a`, `EXPLAIN RESUME JOBS VALUES (a)`},
 		{`PAUSE JOB a`, `PAUSE JOBS VALUES (a)`},
+		{`PAUSE SCHEDULE a`, `PAUSE SCHEDULES VALUES (a)`},
+		{`RESUME SCHEDULE a`, `RESUME SCHEDULES VALUES (a)`},
+		{`DROP SCHEDULE a`, `DROP SCHEDULES VALUES (a)`},
 		{`EXPLAIN PAUSE JOB a`, `EXPLAIN PAUSE JOBS VALUES (a)`},
 		{`SHOW JOB a`, `SHOW JOBS VALUES (a)`},
 		{`EXPLAIN SHOW JOB a`, `EXPLAIN SHOW JOBS VALUES (a)`},
@@ -2324,11 +2398,6 @@ DETAIL: source SQL:
 SELECT 1e-
        ^
 HINT: try \h SELECT`},
-		{"SELECT foo''",
-			`at or near "": syntax error: type does not exist
-DETAIL: source SQL:
-SELECT foo''
-          ^`},
 		{
 			`SELECT 0x FROM t`,
 			`lexical error: invalid hexadecimal numeric literal
@@ -2435,20 +2504,6 @@ ALTER TABLE t RENAME COLUMN x TO family
                                  ^
 HINT: try \h ALTER TABLE`,
 		},
-		{
-			`SELECT CAST(1.2+2.3 AS notatype)`,
-			`at or near "notatype": syntax error: type does not exist
-DETAIL: source SQL:
-SELECT CAST(1.2+2.3 AS notatype)
-                       ^`,
-		},
-		{
-			`SELECT ANNOTATE_TYPE(1.2+2.3, notatype)`,
-			`at or near "notatype": syntax error: type does not exist
-DETAIL: source SQL:
-SELECT ANNOTATE_TYPE(1.2+2.3, notatype)
-                              ^`,
-		},
 		{
 			`CREATE USER foo WITH PASSWORD`,
 			`at or near "EOF": syntax error
@@ -2515,13 +2570,6 @@ DETAIL: source SQL:
 SELECT 1 + ANY ARRAY[1, 2, 3]
                              ^`,
 		},
-		{
-			`SELECT 'f'::"blah"`,
-			`at or near "blah": syntax error: type does not exist
-DETAIL: source SQL:
-SELECT 'f'::"blah"
-            ^`,
-		},
 		// Ensure that the support for ON ROLE <namelist> doesn't leak
 		// where it should not be recognized.
 		{
@@ -2960,7 +3008,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`DROP SUBSCRIPTION a`, 0, `drop subscription`},
 		{`DROP TEXT SEARCH a`, 7821, `drop text`},
 		{`DROP TRIGGER a`, 28296, `drop`},
-		{`DROP TYPE a`, 27793, `drop type`},
 
 		{`DISCARD PLANS`, 0, `discard plans`},
 		{`DISCARD SEQUENCES`, 0, `discard sequences`},
@@ -2971,9 +3018,7 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`SET LOCAL foo = bar`, 32562, ``},
 		{`SET foo FROM CURRENT`, 0, `set from current`},
 
-		{`CREATE TEMP TABLE a(b INT8)`, 5807, ``},
 		{`CREATE UNLOGGED TABLE a(b INT8)`, 0, `create unlogged`},
-		{`CREATE TEMP VIEW a AS SELECT b`, 5807, ``},
 		{`CREATE TEMP SEQUENCE a`, 5807, ``},
 
 		{`CREATE TABLE a(x INT[][])`, 32552, ``},
@@ -3006,13 +3051,11 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE RECURSIVE VIEW a AS SELECT b`, 0, `create recursive view`},
 
 		{`CREATE TYPE a AS (b)`, 27792, ``},
-		{`CREATE TYPE a AS ENUM (b)`, 24873, ``},
 		{`CREATE TYPE a AS RANGE b`, 27791, ``},
 		{`CREATE TYPE a (b)`, 27793, `base`},
 		{`CREATE TYPE a`, 27793, `shell`},
 		{`CREATE DOMAIN a`, 27796, `create`},
 
-		{`CREATE INDEX a ON b(c) WHERE d > 0`, 9683, ``},
 		{`CREATE INDEX a ON b USING HASH (c)`, 0, `index using hash`},
 		{`CREATE INDEX a ON b USING GIST (c)`, 0, `index using gist`},
 		{`CREATE INDEX a ON b USING SPGIST (c)`, 0, `index using spgist`},
@@ -3025,7 +3068,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`INSERT INTO foo(a, a.b) VALUES (1,2)`, 27792, ``},
 		{`INSERT INTO foo VALUES (1,2) ON CONFLICT ON CONSTRAINT a DO NOTHING`, 28161, ``},
 
-		{`SELECT * FROM a FOR UPDATE`, 6583, ``},
 		{`SELECT * FROM ROWS FROM (a(b) AS (d))`, 0, `ROWS FROM with col_def_list`},
 
 		{`SELECT 'a'::INTERVAL SECOND`, 0, `interval with unit qualifier`},
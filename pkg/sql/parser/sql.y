@@ -299,6 +299,18 @@ func (u *sqlSymUnion) updateExprs() tree.UpdateExprs {
 func (u *sqlSymUnion) limit() *tree.Limit {
     return u.val.(*tree.Limit)
 }
+func (u *sqlSymUnion) lockingClause() tree.LockingClause {
+    return u.val.(tree.LockingClause)
+}
+func (u *sqlSymUnion) lockingItem() *tree.LockingItem {
+    return u.val.(*tree.LockingItem)
+}
+func (u *sqlSymUnion) lockingStrength() tree.LockingStrength {
+    return u.val.(tree.LockingStrength)
+}
+func (u *sqlSymUnion) lockingWaitPolicy() tree.LockingWaitPolicy {
+    return u.val.(tree.LockingWaitPolicy)
+}
 func (u *sqlSymUnion) targetList() tree.TargetList {
     return u.val.(tree.TargetList)
 }
@@ -458,9 +470,15 @@ func (u *sqlSymUnion) rowsFromExpr() *tree.RowsFromExpr {
 func (u *sqlSymUnion) partitionedBackup() tree.PartitionedBackup {
     return u.val.(tree.PartitionedBackup)
 }
+func (u *sqlSymUnion) alterTypeAddValuePlacement() *tree.AlterTypeAddValuePlacement {
+    return u.val.(*tree.AlterTypeAddValuePlacement)
+}
 func (u *sqlSymUnion) partitionedBackups() []tree.PartitionedBackup {
     return u.val.([]tree.PartitionedBackup)
 }
+func (u *sqlSymUnion) fullBackupClause() *tree.FullBackupClause {
+    return u.val.(*tree.FullBackupClause)
+}
 func newNameFromStr(s string) *tree.Name {
     return (*tree.Name)(&s)
 }
@@ -483,11 +501,11 @@ func newNameFromStr(s string) *tree.Name {
 // below; search this file for "Keyword category lists".
 
 // Ordinary key words in alphabetical order.
-%token <str> ABORT ACTION ADD ADMIN AGGREGATE
-%token <str> ALL ALTER ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
+%token <str> ABORT ACTION ADD ADMIN AFTER AGGREGATE
+%token <str> ALL ALTER ALWAYS ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
 %token <str> ASYMMETRIC AT AUTOMATIC
 
-%token <str> BACKUP BEGIN BETWEEN BIGINT BIGSERIAL BIT
+%token <str> BACKUP BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BIT
 %token <str> BLOB BOOL BOOLEAN BOTH BY BYTEA BYTES
 
 %token <str> CACHE CANCEL CASCADE CASE CAST CHANGEFEED CHAR
@@ -529,12 +547,12 @@ func newNameFromStr(s string) *tree.Name {
 
 %token <str> LANGUAGE LATERAL LC_CTYPE LC_COLLATE
 %token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT LIST LOCAL
-%token <str> LOCALTIME LOCALTIMESTAMP LOOKUP LOW LSHIFT
+%token <str> LOCALTIME LOCALTIMESTAMP LOCKED LOOKUP LOW LSHIFT
 
 %token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE MINUTE MONTH
 
 %token <str> NAN NAME NAMES NATURAL NEXT NO NO_INDEX_JOIN NORMAL
-%token <str> NOT NOTHING NOTNULL NULL NULLIF NUMERIC
+%token <str> NOT NOTHING NOTNULL NOWAIT NULL NULLIF NUMERIC
 
 %token <str> OF OFF OFFSET OID OIDS OIDVECTOR ON ONLY OPT OPTION OPTIONS OR
 %token <str> ORDER ORDINALITY OTHERS OUT OUTER OVER OVERLAPS OVERLAY OWNED OPERATOR
@@ -545,16 +563,16 @@ func newNameFromStr(s string) *tree.Name {
 
 %token <str> QUERIES QUERY
 
-%token <str> RANGE RANGES READ REAL RECURSIVE REF REFERENCES
+%token <str> RANGE RANGES READ REAL RECURRING RECURSIVE REF REFERENCES
 %token <str> REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
 %token <str> REMOVE_PATH RENAME REPEATABLE REPLACE
 %token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT RULE
 
-%token <str> SAVEPOINT SCATTER SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
 %token <str> SERIAL SERIAL2 SERIAL4 SERIAL8
 %token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
-%token <str> SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
+%token <str> SHARE SHOW SIMILAR SIMPLE SKIP SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 
 %token <str> START STATISTICS STATUS STDIN STRICT STRING STORE STORED STORING SUBSTRING
 %token <str> SYMMETRIC SYNTAX SYSTEM SUBSCRIPTION
@@ -601,6 +619,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> alter_index_stmt
 %type <tree.Statement> alter_view_stmt
 %type <tree.Statement> alter_sequence_stmt
+%type <tree.Statement> alter_type_stmt
 %type <tree.Statement> alter_database_stmt
 %type <tree.Statement> alter_user_stmt
 %type <tree.Statement> alter_range_stmt
@@ -674,6 +693,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> create_view_stmt
 %type <tree.Statement> create_sequence_stmt
 
+%type <tree.Statement> create_schedule_for_backup_stmt
 %type <tree.Statement> create_stats_stmt
 %type <*tree.CreateStatsOptions> opt_create_stats_options
 %type <*tree.CreateStatsOptions> create_stats_option_list
@@ -688,10 +708,12 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> drop_database_stmt
 %type <tree.Statement> drop_index_stmt
 %type <tree.Statement> drop_role_stmt
+%type <tree.Statement> drop_schedule_stmt
 %type <tree.Statement> drop_table_stmt
 %type <tree.Statement> drop_user_stmt
 %type <tree.Statement> drop_view_stmt
 %type <tree.Statement> drop_sequence_stmt
+%type <tree.Statement> drop_type_stmt
 
 %type <tree.Statement> explain_stmt
 %type <tree.Statement> prepare_stmt
@@ -738,6 +760,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> show_indexes_stmt
 %type <tree.Statement> show_partitions_stmt
 %type <tree.Statement> show_jobs_stmt
+%type <tree.Statement> show_schedules_stmt
 %type <tree.Statement> show_queries_stmt
 %type <tree.Statement> show_ranges_stmt
 %type <tree.Statement> show_roles_stmt
@@ -763,6 +786,8 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> use_stmt
 
 %type <[]string> opt_incremental
+%type <[]string> opt_enum_val_list enum_val_list
+%type <*tree.AlterTypeAddValuePlacement> opt_add_val_placement
 %type <tree.KVOption> kv_option
 %type <[]tree.KVOption> kv_option_list opt_with_options var_set_list
 %type <str> import_format
@@ -844,6 +869,11 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.ArraySubscripts> array_subscripts
 %type <tree.GroupBy> group_clause
 %type <*tree.Limit> select_limit
+%type <tree.LockingClause> opt_for_locking_clause for_locking_items
+%type <*tree.LockingItem> for_locking_item
+%type <tree.LockingStrength> for_locking_strength
+%type <tree.LockingWaitPolicy> opt_nowait_or_skip
+%type <tree.TableNames> opt_locked_rels
 %type <tree.TableNames> relation_expr_list
 %type <tree.ReturningClause> returning_clause
 
@@ -867,6 +897,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Expr> overlay_placing
 
 %type <bool> opt_unique opt_cluster
+%type <bool> opt_temp
 %type <bool> opt_using_gin_btree
 
 %type <*tree.Limit> limit_clause offset_clause opt_limit_clause
@@ -948,6 +979,9 @@ func newNameFromStr(s string) *tree.Name {
 %type <str> non_reserved_word_or_sconst
 %type <tree.Expr> zone_value
 %type <tree.Expr> string_or_placeholder
+%type <tree.Expr> sconst_or_placeholder
+%type <tree.Expr> opt_schedule_label
+%type <*tree.FullBackupClause> opt_full_backup_clause
 %type <tree.Expr> string_or_placeholder_list
 
 %type <str> unreserved_keyword type_func_name_keyword cockroachdb_extra_type_func_name_keyword
@@ -1089,7 +1123,7 @@ stmt:
 
 // %Help: ALTER
 // %Category: Group
-// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER SEQUENCE, ALTER DATABASE, ALTER USER
+// %Text: ALTER TABLE, ALTER INDEX, ALTER VIEW, ALTER SEQUENCE, ALTER DATABASE, ALTER USER, ALTER TYPE
 alter_stmt:
   alter_ddl_stmt      // help texts in sub-rule
 | alter_user_stmt     // EXTEND WITH HELP: ALTER USER
@@ -1102,6 +1136,7 @@ alter_ddl_stmt:
 | alter_sequence_stmt // EXTEND WITH HELP: ALTER SEQUENCE
 | alter_database_stmt // EXTEND WITH HELP: ALTER DATABASE
 | alter_range_stmt    // EXTEND WITH HELP: ALTER RANGE
+| alter_type_stmt     // EXTEND WITH HELP: ALTER TYPE
 
 // %Help: ALTER TABLE - change the definition of a table
 // %Category: DDL
@@ -1117,6 +1152,7 @@ alter_ddl_stmt:
 //   ALTER TABLE ... ALTER [COLUMN] <colname> DROP NOT NULL
 //   ALTER TABLE ... ALTER [COLUMN] <colname> DROP STORED
 //   ALTER TABLE ... ALTER [COLUMN] <colname> [SET DATA] TYPE <type> [COLLATE <collation>]
+//   ALTER TABLE ... ALTER PRIMARY KEY USING COLUMNS ( <colnames...> )
 //   ALTER TABLE ... RENAME TO <newname>
 //   ALTER TABLE ... RENAME [COLUMN] <colname> TO <newname>
 //   ALTER TABLE ... VALIDATE CONSTRAINT <constraintname>
@@ -1194,6 +1230,50 @@ alter_sequence_options_stmt:
     $$.val = &tree.AlterSequence{Name: $5.unresolvedObjectName(), Options: $6.seqOpts(), IfExists: true}
   }
 
+// %Help: ALTER TYPE - change the definition of a type
+// %Category: DDL
+// %Text:
+// ALTER TYPE <typename> ADD VALUE [IF NOT EXISTS] <value> [{BEFORE | AFTER} <existing_value>]
+// %SeeAlso: CREATE TYPE, DROP TYPE
+alter_type_stmt:
+  ALTER TYPE type_name ADD VALUE SCONST opt_add_val_placement
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeAddValue{
+        NewVal: $6,
+        IfNotExists: false,
+        Placement: $7.alterTypeAddValuePlacement(),
+      },
+    }
+  }
+| ALTER TYPE type_name ADD VALUE IF NOT EXISTS SCONST opt_add_val_placement
+  {
+    $$.val = &tree.AlterType{
+      Type: $3.unresolvedObjectName(),
+      Cmd: &tree.AlterTypeAddValue{
+        NewVal: $9,
+        IfNotExists: true,
+        Placement: $10.alterTypeAddValuePlacement(),
+      },
+    }
+  }
+| ALTER TYPE error // SHOW HELP: ALTER TYPE
+
+opt_add_val_placement:
+  BEFORE SCONST
+  {
+    $$.val = &tree.AlterTypeAddValuePlacement{Before: true, ExistingVal: $2}
+  }
+| AFTER SCONST
+  {
+    $$.val = &tree.AlterTypeAddValuePlacement{Before: false, ExistingVal: $2}
+  }
+| /* EMPTY */
+  {
+    $$.val = (*tree.AlterTypeAddValuePlacement)(nil)
+  }
+
 // %Help: ALTER USER - change user properties
 // %Category: Priv
 // %Text:
@@ -1598,6 +1678,11 @@ alter_table_cmd:
       Using: $8.expr(),
     }
   }
+  // ALTER TABLE <name> ALTER PRIMARY KEY USING COLUMNS ( <colnames...> )
+| ALTER PRIMARY KEY USING COLUMNS '(' index_params ')'
+  {
+    $$.val = &tree.AlterTableAlterPrimaryKey{Columns: $7.idxElems()}
+  }
   // ALTER TABLE <name> ADD CONSTRAINT ...
 | ADD table_constraint opt_validate_behavior
   {
@@ -1825,6 +1910,8 @@ import_format:
 //    MYSQLDUMP
 //    PGCOPY
 //    PGDUMP
+//    AVRO
+//    JSONL
 //
 // Options:
 //    distributed = '...'
@@ -1833,6 +1920,8 @@ import_format:
 //    delimiter = '...'      [CSV, PGCOPY-specific]
 //    nullif = '...'         [CSV, PGCOPY-specific]
 //    comment = '...'        [CSV-specific]
+//    strict_validation      [AVRO-specific]
+//    column_mapping = '...' [JSONL-specific]
 //
 // %SeeAlso: CREATE TABLE
 import_stmt:
@@ -1885,9 +1974,14 @@ import_stmt:
 //
 // Formats:
 //    CSV
+//    JSONL
+//    PARQUET
 //
 // Options:
 //    delimiter = '...'   [CSV-specific]
+//    nullas = '...'      [CSV-specific]
+//    compression = '...' [PARQUET-specific: snappy (default), gzip or none]
+//    chunk_rows = '...'
 //
 // %SeeAlso: SELECT
 export_stmt:
@@ -1909,6 +2003,18 @@ string_or_placeholder:
     $$.val = p
   }
 
+sconst_or_placeholder:
+  SCONST
+  {
+    $$.val = tree.NewStrVal($1)
+  }
+| PLACEHOLDER
+  {
+    p := $1.placeholder()
+    sqllex.(*lexer).UpdateNumPlaceholders(p)
+    $$.val = p
+  }
+
 string_or_placeholder_list:
   string_or_placeholder
   {
@@ -2138,6 +2244,7 @@ create_stmt:
 | create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_ddl_stmt      // help texts in sub-rule
 | create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
+| create_schedule_for_backup_stmt // EXTEND WITH HELP: CREATE SCHEDULE FOR BACKUP
 | create_unsupported   {}
 | CREATE error         // SHOW HELP: CREATE
 
@@ -2195,7 +2302,6 @@ drop_unsupported:
 | DROP SERVER error { return unimplemented(sqllex, "drop server") }
 | DROP SUBSCRIPTION error { return unimplemented(sqllex, "drop subscription") }
 | DROP TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "drop text") }
-| DROP TYPE error { return unimplementedWithIssueDetail(sqllex, 27793, "drop type") }
 | DROP TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "drop") }
 
 create_ddl_stmt:
@@ -2206,10 +2312,73 @@ create_ddl_stmt:
 | create_table_as_stmt // EXTEND WITH HELP: CREATE TABLE
 // Error case for both CREATE TABLE and CREATE TABLE ... AS in one
 | CREATE opt_temp TABLE error   // SHOW HELP: CREATE TABLE
-| create_type_stmt     { /* SKIP DOC */ }
+| create_type_stmt     // EXTEND WITH HELP: CREATE TYPE
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
 
+// %Help: CREATE SCHEDULE FOR BACKUP - backup data periodically
+// %Category: CCL
+// %Text:
+// CREATE SCHEDULE [<description>]
+// FOR BACKUP <targets...> TO <location>
+// [WITH <option> [= <value>] [, ...]]
+// RECURRING '<cron expression>'
+// [FULL BACKUP {'<cron expression>' | ALWAYS}]
+//
+// Targets:
+//    TABLE <pattern> [, ...]
+//    DATABASE <databasename> [, ...]
+//
+// Location:
+//    "[scheme]://[host]/[path to collection]?[parameters]"
+//    Each backup is written to its own directory under the location.
+//
+// Cron expression:
+//    "<minute> <hour> <day of month> <month> <day of week>", or one of
+//    @hourly, @daily, @weekly, @monthly or @yearly.
+//
+// If FULL BACKUP is not specified, every backup is a full backup.
+// Otherwise, backups are incremental on top of the latest full backup,
+// and a full backup is taken at the times the FULL BACKUP expression
+// specifies.
+//
+// %SeeAlso: BACKUP, SHOW SCHEDULES, PAUSE JOBS, RESUME JOBS
+create_schedule_for_backup_stmt:
+  CREATE SCHEDULE opt_schedule_label FOR BACKUP targets TO string_or_placeholder opt_with_options RECURRING sconst_or_placeholder opt_full_backup_clause
+  {
+    $$.val = &tree.ScheduledBackup{
+      ScheduleName: $3.expr(),
+      Targets: $6.targetList(),
+      To: $8.expr(),
+      BackupOptions: $9.kvOptions(),
+      Recurrence: $11.expr(),
+      FullBackup: $12.fullBackupClause(),
+    }
+  }
+| CREATE SCHEDULE error // SHOW HELP: CREATE SCHEDULE FOR BACKUP
+
+opt_schedule_label:
+  sconst_or_placeholder
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
+
+opt_full_backup_clause:
+  FULL BACKUP sconst_or_placeholder
+  {
+    $$.val = &tree.FullBackupClause{Recurrence: $3.expr()}
+  }
+| FULL BACKUP ALWAYS
+  {
+    $$.val = &tree.FullBackupClause{AlwaysFull: true}
+  }
+| /* EMPTY */
+  {
+    var clause *tree.FullBackupClause
+    $$.val = clause
+  }
+
 // %Help: CREATE STATISTICS - create a new table statistic
 // %Category: Misc
 // %Text:
@@ -2322,6 +2491,20 @@ create_changefeed_stmt:
       Options: $5.kvOptions(),
     }
   }
+| CREATE CHANGEFEED opt_changefeed_sink opt_with_options AS SELECT target_list FROM table_name opt_where_clause
+  {
+    name := $9.unresolvedObjectName().ToTableName()
+    $$.val = &tree.CreateChangefeed{
+      Targets: tree.TargetList{Tables: tree.TablePatterns{$9.unresolvedObjectName().ToUnresolvedName()}},
+      SinkURI: $3.expr(),
+      Options: $4.kvOptions(),
+      Select: &tree.SelectClause{
+        Exprs: $7.selExprs(),
+        From:  tree.From{Tables: tree.TableExprs{&tree.AliasedTableExpr{Expr: &name}}},
+        Where: tree.NewWhere(tree.AstWhere, $10.expr()),
+      },
+    }
+  }
 
 changefeed_targets:
   single_table_pattern_list
@@ -2394,10 +2577,11 @@ discard_stmt:
 // %Category: Group
 // %Text:
 // DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE,
-// DROP USER, DROP ROLE
+// DROP TYPE, DROP USER, DROP ROLE, DROP SCHEDULES
 drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
+| drop_schedule_stmt // EXTEND WITH HELP: DROP SCHEDULES
 | drop_user_stmt     // EXTEND WITH HELP: DROP USER
 | drop_unsupported   {}
 | DROP error         // SHOW HELP: DROP
@@ -2408,6 +2592,7 @@ drop_ddl_stmt:
 | drop_table_stmt    // EXTEND WITH HELP: DROP TABLE
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
+| drop_type_stmt     // EXTEND WITH HELP: DROP TYPE
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
@@ -2439,6 +2624,21 @@ drop_sequence_stmt:
   }
 | DROP SEQUENCE error // SHOW HELP: DROP VIEW
 
+// %Help: DROP TYPE - remove a type
+// %Category: DDL
+// %Text: DROP TYPE [IF EXISTS] <typename> [, ...] [CASCADE | RESTRICT]
+// %SeeAlso: CREATE TYPE, ALTER TYPE
+drop_type_stmt:
+  DROP TYPE table_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropType{Names: $3.tableNames(), IfExists: false, DropBehavior: $4.dropBehavior()}
+  }
+| DROP TYPE IF EXISTS table_name_list opt_drop_behavior
+  {
+    $$.val = &tree.DropType{Names: $5.tableNames(), IfExists: true, DropBehavior: $6.dropBehavior()}
+  }
+| DROP TYPE error // SHOW HELP: DROP TYPE
+
 // %Help: DROP TABLE - remove a table
 // %Category: DDL
 // %Text: DROP TABLE [IF EXISTS] <tablename> [, ...] [CASCADE | RESTRICT]
@@ -2515,6 +2715,28 @@ drop_user_stmt:
   }
 | DROP USER error // SHOW HELP: DROP USER
 
+// %Help: DROP SCHEDULES - remove periodic schedules
+// %Category: Misc
+// %Text:
+// DROP SCHEDULES <selectclause>
+// DROP SCHEDULE <scheduleid>
+// %SeeAlso: SHOW SCHEDULES, PAUSE JOBS, RESUME JOBS
+drop_schedule_stmt:
+  DROP SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{
+      Schedules: &tree.Select{
+        Select: &tree.ValuesClause{Rows: []tree.Exprs{tree.Exprs{$3.expr()}}},
+      },
+      Command: tree.DropSchedule,
+    }
+  }
+| DROP SCHEDULES select_stmt
+  {
+    $$.val = &tree.ControlSchedules{Schedules: $3.slct(), Command: tree.DropSchedule}
+  }
+| DROP SCHEDULE error // SHOW HELP: DROP SCHEDULES
+
 // %Help: DROP ROLE - remove a role
 // %Category: Priv
 // %Text: DROP ROLE [IF EXISTS] <role> [, ...]
@@ -3239,6 +3461,7 @@ show_stmt:
 | show_indexes_stmt         // EXTEND WITH HELP: SHOW INDEXES
 | show_partitions_stmt      // EXTEND WITH HELP: SHOW PARTITIONS
 | show_jobs_stmt            // EXTEND WITH HELP: SHOW JOBS
+| show_schedules_stmt       // EXTEND WITH HELP: SHOW SCHEDULES
 | show_queries_stmt         // EXTEND WITH HELP: SHOW QUERIES
 | show_ranges_stmt          // EXTEND WITH HELP: SHOW RANGES
 | show_roles_stmt           // EXTEND WITH HELP: SHOW ROLES
@@ -3322,38 +3545,57 @@ show_histogram_stmt:
 
 // %Help: SHOW BACKUP - list backup contents
 // %Category: CCL
-// %Text: SHOW BACKUP [SCHEMAS|FILES|RANGES] <location>
+// %Text:
+// SHOW BACKUP [SCHEMAS|FILES|RANGES] <location> [WITH <option> [= <value>] [, ...]]
+// SHOW BACKUP <location> [, ...] WITH check_files
+//
+// Options:
+//    check_files: verify the files of the last listed backup and the chain
+//                 of backups it is incremental on, in the order they would
+//                 be given to RESTORE, instead of listing its contents
+//
 // %SeeAlso: WEBDOCS/show-backup.html
 show_backup_stmt:
-  SHOW BACKUP string_or_placeholder
+  SHOW BACKUP string_or_placeholder_list opt_with_options
   {
+    locations := $3.exprs()
+    n := len(locations) - 1
+    var incrementalFrom tree.Exprs
+    if n > 0 {
+      incrementalFrom = locations[:n]
+    }
     $$.val = &tree.ShowBackup{
       Details: tree.BackupDefaultDetails,
-      Path:    $3.expr(),
+      Path:    locations[n],
+      IncrementalFrom: incrementalFrom,
+      Options: $4.kvOptions(),
     }
   }
-| SHOW BACKUP SCHEMAS string_or_placeholder
+| SHOW BACKUP SCHEMAS string_or_placeholder opt_with_options
   {
     $$.val = &tree.ShowBackup{
       Details: tree.BackupDefaultDetails,
       ShouldIncludeSchemas: true,
       Path:    $4.expr(),
+      Options: $5.kvOptions(),
     }
   }
-| SHOW BACKUP RANGES string_or_placeholder
+| SHOW BACKUP RANGES string_or_placeholder opt_with_options
   {
     /* SKIP DOC */
     $$.val = &tree.ShowBackup{
       Details: tree.BackupRangeDetails,
       Path:    $4.expr(),
+      Options: $5.kvOptions(),
     }
   }
-| SHOW BACKUP FILES string_or_placeholder
+| SHOW BACKUP FILES string_or_placeholder opt_with_options
   {
     /* SKIP DOC */
     $$.val = &tree.ShowBackup{
       Details: tree.BackupFileDetails,
       Path:    $4.expr(),
+      Options: $5.kvOptions(),
     }
   }
 | SHOW BACKUP error // SHOW HELP: SHOW BACKUP
@@ -3560,6 +3802,18 @@ show_jobs_stmt:
   }
 | SHOW JOB error // SHOW HELP: SHOW JOBS
 
+// %Help: SHOW SCHEDULES - list periodic schedules
+// %Category: Misc
+// %Text:
+// SHOW SCHEDULES
+// %SeeAlso: CREATE SCHEDULE FOR BACKUP, PAUSE JOBS, RESUME JOBS
+show_schedules_stmt:
+  SHOW SCHEDULES
+  {
+    $$.val = &tree.ShowSchedules{}
+  }
+| SHOW SCHEDULES error // SHOW HELP: SHOW SCHEDULES
+
 // %Help: SHOW TRACE - display an execution trace
 // %Category: Misc
 // %Text:
@@ -4034,7 +4288,9 @@ for_grantee_clause:
 // %Text:
 // PAUSE JOBS <selectclause>
 // PAUSE JOB <jobid>
-// %SeeAlso: SHOW JOBS, CANCEL JOBS, RESUME JOBS
+// PAUSE SCHEDULES <selectclause>
+// PAUSE SCHEDULE <scheduleid>
+// %SeeAlso: SHOW JOBS, CANCEL JOBS, RESUME JOBS, SHOW SCHEDULES
 pause_stmt:
   PAUSE JOB a_expr
   {
@@ -4049,13 +4305,26 @@ pause_stmt:
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.PauseJob}
   }
+| PAUSE SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{
+      Schedules: &tree.Select{
+        Select: &tree.ValuesClause{Rows: []tree.Exprs{tree.Exprs{$3.expr()}}},
+      },
+      Command: tree.PauseSchedule,
+    }
+  }
+| PAUSE SCHEDULES select_stmt
+  {
+    $$.val = &tree.ControlSchedules{Schedules: $3.slct(), Command: tree.PauseSchedule}
+  }
 | PAUSE error // SHOW HELP: PAUSE JOBS
 
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
 // %Text:
-// CREATE TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>]
-// CREATE TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
+// CREATE [TEMPORARY] TABLE [IF NOT EXISTS] <tablename> ( <elements...> ) [<interleave>]
+// CREATE [TEMPORARY] TABLE [IF NOT EXISTS] <tablename> [( <colnames...> )] AS <source>
 //
 // Table elements:
 //    <name> <type> [<qualifiers...>]
@@ -4090,6 +4359,7 @@ create_table_stmt:
     $$.val = &tree.CreateTable{
       Table: name,
       IfNotExists: false,
+      Temporary: $2.bool(),
       Interleave: $8.interleave(),
       Defs: $6.tblDefs(),
       AsSource: nil,
@@ -4102,6 +4372,7 @@ create_table_stmt:
     $$.val = &tree.CreateTable{
       Table: name,
       IfNotExists: true,
+      Temporary: $2.bool(),
       Interleave: $11.interleave(),
       Defs: $9.tblDefs(),
       AsSource: nil,
@@ -4121,6 +4392,7 @@ create_table_as_stmt:
     $$.val = &tree.CreateTable{
       Table: name,
       IfNotExists: false,
+      Temporary: $2.bool(),
       Interleave: nil,
       Defs: $5.tblDefs(),
       AsSource: $8.slct(),
@@ -4132,6 +4404,7 @@ create_table_as_stmt:
     $$.val = &tree.CreateTable{
       Table: name,
       IfNotExists: true,
+      Temporary: $2.bool(),
       Interleave: nil,
       Defs: $8.tblDefs(),
       AsSource: $11.slct(),
@@ -4155,14 +4428,14 @@ opt_create_as_data:
  * so we'll probably continue to treat LOCAL as a noise word.
  */
 opt_temp:
-  TEMPORARY         { return unimplementedWithIssue(sqllex, 5807) }
-| TEMP              { return unimplementedWithIssue(sqllex, 5807) }
-| LOCAL TEMPORARY   { return unimplementedWithIssue(sqllex, 5807) }
-| LOCAL TEMP        { return unimplementedWithIssue(sqllex, 5807) }
-| GLOBAL TEMPORARY  { return unimplementedWithIssue(sqllex, 5807) }
-| GLOBAL TEMP       { return unimplementedWithIssue(sqllex, 5807) }
+  TEMPORARY         { $$.val = true }
+| TEMP              { $$.val = true }
+| LOCAL TEMPORARY   { $$.val = true }
+| LOCAL TEMP        { $$.val = true }
+| GLOBAL TEMPORARY  { $$.val = true }
+| GLOBAL TEMP       { $$.val = true }
 | UNLOGGED          { return unimplemented(sqllex, "create unlogged") }
-| /*EMPTY*/         { /* no error */ }
+| /*EMPTY*/         { $$.val = false }
 
 opt_table_elem_list:
   table_elem_list
@@ -4423,7 +4696,7 @@ col_qualification_elem:
  }
 
 index_def:
-  INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by
+  INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by opt_where_clause
   {
     $$.val = &tree.IndexTableDef{
       Name:    tree.Name($2),
@@ -4431,9 +4704,10 @@ index_def:
       Storing: $6.nameList(),
       Interleave: $7.interleave(),
       PartitionBy: $8.partitionBy(),
+      Predicate: $9.expr(),
     }
   }
-| UNIQUE INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by
+| UNIQUE INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by opt_where_clause
   {
     $$.val = &tree.UniqueConstraintTableDef{
       IndexTableDef: tree.IndexTableDef {
@@ -4442,6 +4716,7 @@ index_def:
         Storing: $7.nameList(),
         Interleave: $8.interleave(),
         PartitionBy: $9.partitionBy(),
+        Predicate: $10.expr(),
       },
     }
   }
@@ -4484,7 +4759,7 @@ constraint_elem:
       Expr: $3.expr(),
     }
   }
-| UNIQUE '(' index_params ')' opt_storing opt_interleave opt_partition_by  opt_deferrable
+| UNIQUE '(' index_params ')' opt_storing opt_interleave opt_partition_by opt_deferrable opt_where_clause
   {
     $$.val = &tree.UniqueConstraintTableDef{
       IndexTableDef: tree.IndexTableDef{
@@ -4492,6 +4767,7 @@ constraint_elem:
         Storing: $5.nameList(),
         Interleave: $6.interleave(),
         PartitionBy: $7.partitionBy(),
+        Predicate: $9.expr(),
       },
     }
   }
@@ -4785,6 +5061,9 @@ numeric_only:
 create_sequence_stmt:
   CREATE opt_temp SEQUENCE sequence_name opt_sequence_option_list
   {
+    if $2.bool() {
+      return unimplementedWithIssue(sqllex, 5807)
+    }
     name := $4.unresolvedObjectName().ToTableName()
     $$.val = &tree.CreateSequence{Name: name, Options: $5.seqOpts()}
   }
@@ -4887,7 +5166,7 @@ role_or_group:
 
 // %Help: CREATE VIEW - create a new view
 // %Category: DDL
-// %Text: CREATE VIEW <viewname> [( <colnames...> )] AS <source>
+// %Text: CREATE [TEMPORARY] VIEW <viewname> [( <colnames...> )] AS <source>
 // %SeeAlso: CREATE TABLE, SHOW CREATE, WEBDOCS/create-view.html
 create_view_stmt:
   CREATE opt_temp opt_view_recursive VIEW view_name opt_column_list AS select_stmt
@@ -4895,6 +5174,7 @@ create_view_stmt:
     name := $5.unresolvedObjectName().ToTableName()
     $$.val = &tree.CreateView{
       Name: name,
+      Temporary: $2.bool(),
       ColumnNames: $6.nameList(),
       AsSource: $8.slct(),
     }
@@ -4902,17 +5182,48 @@ create_view_stmt:
 | CREATE OR REPLACE opt_temp opt_view_recursive VIEW error { return unimplementedWithIssue(sqllex, 24897) }
 | CREATE opt_temp opt_view_recursive VIEW error // SHOW HELP: CREATE VIEW
 
+opt_enum_val_list:
+  enum_val_list
+  {
+    $$.val = $1.strs()
+  }
+| /* EMPTY */
+  {
+    $$.val = []string(nil)
+  }
+
+enum_val_list:
+  SCONST
+  {
+    $$.val = []string{$1}
+  }
+| enum_val_list ',' SCONST
+  {
+    $$.val = append($1.strs(), $3)
+  }
+
 opt_view_recursive:
   /* EMPTY */ { /* no error */ }
 | RECURSIVE { return unimplemented(sqllex, "create recursive view") }
 
-// CREATE TYPE/DOMAIN is not yet supported by CockroachDB but we
-// want to report it with the right issue number.
+// %Help: CREATE TYPE - create a type
+// %Category: DDL
+// %Text: CREATE TYPE <typename> AS ENUM (...)
+// %SeeAlso: ALTER TYPE, DROP TYPE
 create_type_stmt:
-  // Record/Composite types.
-  CREATE TYPE type_name AS '(' error      { return unimplementedWithIssue(sqllex, 27792) }
   // Enum types.
-| CREATE TYPE type_name AS ENUM '(' error { return unimplementedWithIssue(sqllex, 24873) }
+  CREATE TYPE type_name AS ENUM '(' opt_enum_val_list ')'
+  {
+    $$.val = &tree.CreateType{
+      TypeName: $3.unresolvedObjectName().ToTableName(),
+      EnumLabels: $7.strs(),
+    }
+  }
+| CREATE TYPE type_name AS ENUM error // SHOW HELP: CREATE TYPE
+  // Only ENUM types are supported. The other kinds of types and domains are
+  // reported with the right issue number.
+  // Record/Composite types.
+| CREATE TYPE type_name AS '(' error      { return unimplementedWithIssue(sqllex, 27792) }
   // Range types.
 | CREATE TYPE type_name AS RANGE error    { return unimplementedWithIssue(sqllex, 27791) }
   // Base (primitive) types.
@@ -4928,6 +5239,7 @@ create_type_stmt:
 // CREATE [UNIQUE | INVERTED] INDEX [IF NOT EXISTS] [<idxname>]
 //        ON <tablename> ( <colname> [ASC | DESC] [, ...] )
 //        [STORING ( <colnames...> )] [<interleave>]
+//        [WHERE <expr>]
 //
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
@@ -4935,7 +5247,7 @@ create_type_stmt:
 // %SeeAlso: CREATE TABLE, SHOW INDEXES, SHOW CREATE,
 // WEBDOCS/create-index.html
 create_index_stmt:
-  CREATE opt_unique INDEX opt_index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by opt_idx_where
+  CREATE opt_unique INDEX opt_index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by opt_where_clause
   {
     table := $6.unresolvedObjectName().ToTableName()
     $$.val = &tree.CreateIndex{
@@ -4947,9 +5259,10 @@ create_index_stmt:
       Interleave: $12.interleave(),
       PartitionBy: $13.partitionBy(),
       Inverted: $7.bool(),
+      Predicate: $14.expr(),
     }
   }
-| CREATE opt_unique INDEX IF NOT EXISTS index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by opt_idx_where
+| CREATE opt_unique INDEX IF NOT EXISTS index_name ON table_name opt_using_gin_btree '(' index_params ')' opt_storing opt_interleave opt_partition_by opt_where_clause
   {
     table := $9.unresolvedObjectName().ToTableName()
     $$.val = &tree.CreateIndex{
@@ -4962,9 +5275,10 @@ create_index_stmt:
       Interleave:  $15.interleave(),
       PartitionBy: $16.partitionBy(),
       Inverted:    $10.bool(),
+      Predicate:   $17.expr(),
     }
   }
-| CREATE opt_unique INVERTED INDEX opt_index_name ON table_name '(' index_params ')' opt_storing opt_interleave opt_partition_by opt_idx_where
+| CREATE opt_unique INVERTED INDEX opt_index_name ON table_name '(' index_params ')' opt_storing opt_interleave opt_partition_by opt_where_clause
   {
     table := $7.unresolvedObjectName().ToTableName()
     $$.val = &tree.CreateIndex{
@@ -4976,9 +5290,10 @@ create_index_stmt:
       Storing:     $11.nameList(),
       Interleave:  $12.interleave(),
       PartitionBy: $13.partitionBy(),
+      Predicate:   $14.expr(),
     }
   }
-| CREATE opt_unique INVERTED INDEX IF NOT EXISTS index_name ON table_name '(' index_params ')' opt_storing opt_interleave opt_partition_by opt_idx_where
+| CREATE opt_unique INVERTED INDEX IF NOT EXISTS index_name ON table_name '(' index_params ')' opt_storing opt_interleave opt_partition_by opt_where_clause
   {
     table := $10.unresolvedObjectName().ToTableName()
     $$.val = &tree.CreateIndex{
@@ -4991,14 +5306,11 @@ create_index_stmt:
       Storing:     $14.nameList(),
       Interleave:  $15.interleave(),
       PartitionBy: $16.partitionBy(),
+      Predicate:   $17.expr(),
     }
   }
 | CREATE opt_unique INDEX error // SHOW HELP: CREATE INDEX
 
-opt_idx_where:
-  /* EMPTY */ { /* no error */ }
-| WHERE error { return unimplementedWithIssue(sqllex, 9683) }
-
 opt_using_gin_btree:
   USING name
   {
@@ -5166,7 +5478,9 @@ release_stmt:
 // %Text:
 // RESUME JOBS <selectclause>
 // RESUME JOB <jobid>
-// %SeeAlso: SHOW JOBS, CANCEL JOBS, PAUSE JOBS
+// RESUME SCHEDULES <selectclause>
+// RESUME SCHEDULE <scheduleid>
+// %SeeAlso: SHOW JOBS, CANCEL JOBS, PAUSE JOBS, SHOW SCHEDULES
 resume_stmt:
   RESUME JOB a_expr
   {
@@ -5181,6 +5495,19 @@ resume_stmt:
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.ResumeJob}
   }
+| RESUME SCHEDULE a_expr
+  {
+    $$.val = &tree.ControlSchedules{
+      Schedules: &tree.Select{
+        Select: &tree.ValuesClause{Rows: []tree.Exprs{tree.Exprs{$3.expr()}}},
+      },
+      Command: tree.ResumeSchedule,
+    }
+  }
+| RESUME SCHEDULES select_stmt
+  {
+    $$.val = &tree.ControlSchedules{Schedules: $3.slct(), Command: tree.ResumeSchedule}
+  }
 | RESUME error // SHOW HELP: RESUME JOBS
 
 // %Help: SAVEPOINT - start a retryable block
@@ -5713,34 +6040,105 @@ select_with_parens:
 //      clause.
 //      - 2002-08-28 bjm
 select_no_parens:
-  simple_select opt_for
+  simple_select opt_for_locking_clause
   {
-    $$.val = &tree.Select{Select: $1.selectStmt()}
+    $$.val = &tree.Select{Select: $1.selectStmt(), Locking: $2.lockingClause()}
   }
-| select_clause sort_clause opt_for
+| select_clause sort_clause opt_for_locking_clause
   {
-    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy()}
+    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Locking: $3.lockingClause()}
   }
-| select_clause opt_sort_clause select_limit opt_for
+| select_clause opt_sort_clause select_limit opt_for_locking_clause
   {
-    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Limit: $3.limit()}
+    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Limit: $3.limit(), Locking: $4.lockingClause()}
   }
-| with_clause select_clause opt_for
+| with_clause select_clause opt_for_locking_clause
   {
-    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt()}
+    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), Locking: $3.lockingClause()}
   }
-| with_clause select_clause sort_clause opt_for
+| with_clause select_clause sort_clause opt_for_locking_clause
   {
-    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy()}
+    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Locking: $4.lockingClause()}
   }
-| with_clause select_clause opt_sort_clause select_limit opt_for
+| with_clause select_clause opt_sort_clause select_limit opt_for_locking_clause
   {
-    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit()}
+    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit(), Locking: $5.lockingClause()}
   }
 
-opt_for:
-  /* EMPTY */ { /* no error */ }
-| FOR error { return unimplementedWithIssue(sqllex, 6583) }
+// The locking clause acquires row-level locks on the rows returned by a
+// SELECT statement. Locks are held until the end of the enclosing
+// transaction.
+opt_for_locking_clause:
+  for_locking_items
+  {
+    $$.val = $1.lockingClause()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.LockingClause(nil)
+  }
+
+for_locking_items:
+  for_locking_item
+  {
+    $$.val = tree.LockingClause{$1.lockingItem()}
+  }
+| for_locking_items for_locking_item
+  {
+    $$.val = append($1.lockingClause(), $2.lockingItem())
+  }
+
+for_locking_item:
+  for_locking_strength opt_locked_rels opt_nowait_or_skip
+  {
+    $$.val = &tree.LockingItem{
+      Strength:   $1.lockingStrength(),
+      Targets:    $2.tableNames(),
+      WaitPolicy: $3.lockingWaitPolicy(),
+    }
+  }
+
+for_locking_strength:
+  FOR UPDATE
+  {
+    $$.val = tree.ForUpdate
+  }
+| FOR NO KEY UPDATE
+  {
+    $$.val = tree.ForNoKeyUpdate
+  }
+| FOR SHARE
+  {
+    $$.val = tree.ForShare
+  }
+| FOR KEY SHARE
+  {
+    $$.val = tree.ForKeyShare
+  }
+
+opt_locked_rels:
+  /* EMPTY */
+  {
+    $$.val = tree.TableNames{}
+  }
+| OF table_name_list
+  {
+    $$.val = $2.tableNames()
+  }
+
+opt_nowait_or_skip:
+  /* EMPTY */
+  {
+    $$.val = tree.LockWaitBlock
+  }
+| SKIP LOCKED
+  {
+    $$.val = tree.LockWaitSkip
+  }
+| NOWAIT
+  {
+    $$.val = tree.LockWaitError
+  }
 
 select_clause:
 // We only provide help if an open parenthesis is provided, because
@@ -5799,6 +6197,7 @@ simple_select:
 //        [ ORDER BY <expr> [ ASC | DESC ] [, ...] ]
 //        [ LIMIT { <expr> | ALL } ]
 //        [ OFFSET <expr> [ ROW | ROWS ] ]
+//        [ FOR { UPDATE | NO KEY UPDATE | SHARE | KEY SHARE } [ OF <tablename> [, ...] ] [ NOWAIT | SKIP LOCKED ] [...] ]
 // %SeeAlso: WEBDOCS/select-clause.html
 simple_select_clause:
   SELECT opt_all_clause target_list
@@ -6814,8 +7213,8 @@ const_typename:
     // See https://www.postgresql.org/docs/9.1/static/datatype-character.html
     // Postgres supports a special character type named "char" (with the quotes)
     // that is a single-character column type. It's used by system tables.
-    // Eventually this clause will be used to parse user-defined types as well,
-    // since their names can be quoted.
+    // This clause also parses user-defined types, since their names can be
+    // quoted.
     if $1 == "char" {
       $$.val = types.MakeQChar(0)
     } else {
@@ -6825,10 +7224,10 @@ const_typename:
       if !ok {
           switch unimp {
               case 0:
-                // Note: we can only report an unimplemented error for specific
-                // known type names. Anything else may return PII.
-                sqllex.Error("type does not exist")
-                return 1
+                // Any other name may refer to a user-defined type. It is
+                // resolved against the type descriptors during semantic
+                // analysis.
+                $$.val = types.MakeUserDefinedTypeReference($1)
               case -1:
                 return unimplemented(sqllex, "type name " + $1)
               default:
@@ -9119,11 +9518,14 @@ unreserved_keyword:
 | ACTION
 | ADD
 | ADMIN
+| AFTER
 | AGGREGATE
 | ALTER
+| ALWAYS
 | AT
 | AUTOMATIC
 | BACKUP
+| BEFORE
 | BEGIN
 | BIGSERIAL
 | BLOB
@@ -9225,6 +9627,7 @@ unreserved_keyword:
 | LEVEL
 | LIST
 | LOCAL
+| LOCKED
 | LOOKUP
 | LOW
 | MATCH
@@ -9241,6 +9644,7 @@ unreserved_keyword:
 | NO
 | NORMAL
 | NO_INDEX_JOIN
+| NOWAIT
 | IGNORE_FOREIGN_KEYS
 | OF
 | OFF
@@ -9273,6 +9677,7 @@ unreserved_keyword:
 | RANGE
 | RANGES
 | READ
+| RECURRING
 | RECURSIVE
 | REF
 | REGCLASS
@@ -9300,6 +9705,8 @@ unreserved_keyword:
 | STATUS
 | SAVEPOINT
 | SCATTER
+| SCHEDULE
+| SCHEDULES
 | SCHEMA
 | SCHEMAS
 | SCRUB
@@ -9316,8 +9723,10 @@ unreserved_keyword:
 | SESSION
 | SESSIONS
 | SET
+| SHARE
 | SHOW
 | SIMPLE
+| SKIP
 | SMALLSERIAL
 | SNAPSHOT
 | SQL
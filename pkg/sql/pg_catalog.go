@@ -22,6 +22,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -1191,7 +1192,7 @@ CREATE TABLE pg_catalog.pg_shdescription (
 }
 
 var pgCatalogEnumTable = virtualSchemaTable{
-	comment: `enum types and labels (empty - feature does not exist)
+	comment: `enum types and labels
 https://www.postgresql.org/docs/9.5/catalog-pg-enum.html`,
 	schema: `
 CREATE TABLE pg_catalog.pg_enum (
@@ -1200,9 +1201,23 @@ CREATE TABLE pg_catalog.pg_enum (
   enumsortorder FLOAT4,
   enumlabel STRING
 )`,
-	populate: func(_ context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
-		// Enum types are not currently supported.
-		return nil
+	populate: func(ctx context.Context, p *planner, dbContext *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		h := makeOidHasher()
+		return forEachTypeDesc(ctx, p, dbContext, func(_ *DatabaseDescriptor, typ *sqlbase.TypeDescriptor) error {
+			typOid := tree.NewDOid(tree.DInt(typ.MakeTypesT().Oid()))
+			for i := range typ.EnumMembers {
+				label := typ.EnumMembers[i].LogicalRepresentation
+				if err := addRow(
+					h.EnumMemberOid(typ.ID, label), // oid
+					typOid,                         // enumtypid
+					tree.NewDFloat(tree.DFloat(float64(i+1))), // enumsortorder
+					tree.NewDString(label),                    // enumlabel
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 	},
 }
 
@@ -1361,6 +1376,10 @@ CREATE TABLE pg_catalog.pg_index (
 					if err != nil {
 						return err
 					}
+					indpred := tree.DNull
+					if index.IsPartial() {
+						indpred = tree.NewDString(index.Predicate)
+					}
 					return addRow(
 						h.IndexOid(db, scName, table, index), // indexrelid
 						tableOid,                             // indrelid
@@ -1380,7 +1399,7 @@ CREATE TABLE pg_catalog.pg_index (
 						indclass,                                 // indclass
 						indoption,                                // indoption
 						tree.DNull,                               // indexprs
-						tree.DNull,                               // indpred
+						indpred,                                  // indpred
 					)
 				})
 			})
@@ -1480,6 +1499,13 @@ func indexDefFromDescriptor(
 		}
 		indexDef.Interleave = intlDef
 	}
+	if index.IsPartial() {
+		pred, err := parser.ParseExpr(index.Predicate)
+		if err != nil {
+			return "", err
+		}
+		indexDef.Predicate = pred
+	}
 	return indexDef.String(), nil
 }
 
@@ -2228,7 +2254,6 @@ var (
 	// Avoid unused warning for constants.
 	_ = typTypeComposite
 	_ = typTypeDomain
-	_ = typTypeEnum
 	_ = typTypePseudo
 	_ = typTypeRange
 
@@ -2251,7 +2276,6 @@ var (
 
 	// Avoid unused warning for constants.
 	_ = typCategoryComposite
-	_ = typCategoryEnum
 	_ = typCategoryGeometric
 	_ = typCategoryRange
 	_ = typCategoryBitString
@@ -2298,7 +2322,7 @@ CREATE TABLE pg_catalog.pg_type (
 )`,
 	populate: func(ctx context.Context, p *planner, dbContext *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
 		h := makeOidHasher()
-		return forEachDatabaseDesc(ctx, p, dbContext, func(db *DatabaseDescriptor) error {
+		if err := forEachDatabaseDesc(ctx, p, dbContext, func(db *DatabaseDescriptor) error {
 			nspOid := h.NamespaceOid(db, pgCatalogName)
 
 			for o, typ := range types.OidToType {
@@ -2373,6 +2397,46 @@ CREATE TABLE pg_catalog.pg_type (
 				}
 			}
 			return nil
+		}); err != nil {
+			return err
+		}
+
+		// Now add the user-defined types.
+		return forEachTypeDesc(ctx, p, dbContext, func(db *DatabaseDescriptor, desc *sqlbase.TypeDescriptor) error {
+			typ := desc.MakeTypesT()
+			return addRow(
+				tree.NewDOid(tree.DInt(typ.Oid())),    // oid
+				tree.NewDName(desc.Name),              // typname
+				h.NamespaceOid(db, tree.PublicSchema), // typnamespace
+				tree.DNull,                            // typowner
+				negOneVal,                             // typlen
+				tree.DBoolFalse,                       // typbyval
+				typTypeEnum,                           // typtype
+				typCategoryEnum,                       // typcategory
+				tree.DBoolFalse,                       // typispreferred
+				tree.DBoolTrue,                        // typisdefined
+				typDelim,                              // typdelim
+				oidZero,                               // typrelid
+				oidZero,                               // typelem
+				oidZero,                               // typarray
+				h.RegProc("enum_in"),                  // typinput
+				h.RegProc("enum_out"),                 // typoutput
+				h.RegProc("enum_recv"),                // typreceive
+				h.RegProc("enum_send"),                // typsend
+				oidZero,                               // typmodin
+				oidZero,                               // typmodout
+				oidZero,                               // typanalyze
+				tree.DNull,                            // typalign
+				tree.DNull,                            // typstorage
+				tree.DBoolFalse,                       // typnotnull
+				oidZero,                               // typbasetype
+				negOneVal,                             // typtypmod
+				zeroVal,                               // typndims
+				oidZero,                               // typcollation
+				tree.DNull,                            // typdefaultbin
+				tree.DNull,                            // typdefault
+				tree.DNull,                            // typacl
+			)
 		})
 	},
 }
@@ -2556,6 +2620,7 @@ var datumToTypeCategory = map[types.Family]*tree.DString{
 	types.UuidFamily:        typCategoryUserDefined,
 	types.INetFamily:        typCategoryNetworkAddr,
 	types.UnknownFamily:     typCategoryUnknown,
+	types.EnumFamily:        typCategoryEnum,
 }
 
 func typCategory(typ *types.T) tree.Datum {
@@ -2674,6 +2739,7 @@ const (
 	userTypeTag
 	collationTypeTag
 	operatorTypeTag
+	enumMemberTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -2810,6 +2876,13 @@ func (h oidHasher) RegProc(name string) tree.Datum {
 	return h.BuiltinOid(name, &overloads[0]).AsRegProc(name)
 }
 
+func (h oidHasher) EnumMemberOid(typeID sqlbase.ID, label string) *tree.DOid {
+	h.writeTypeTag(enumMemberTypeTag)
+	h.writeUInt32(uint32(typeID))
+	h.writeStr(label)
+	return h.getOid()
+}
+
 func (h oidHasher) UserOid(username string) *tree.DOid {
 	h.writeTypeTag(userTypeTag)
 	h.writeStr(username)
@@ -140,6 +140,9 @@ func (b *writeBuffer) writeTextDatum(
 	case *tree.DCollatedString:
 		b.writeLengthPrefixedString(v.Contents)
 
+	case *tree.DEnum:
+		b.writeLengthPrefixedString(v.LogicalRep)
+
 	case *tree.DDate:
 		s := v.Date.String()
 		b.putInt32(int32(len(s)))
@@ -399,6 +402,9 @@ func (b *writeBuffer) writeBinaryDatum(
 	case *tree.DCollatedString:
 		b.writeLengthPrefixedString(v.Contents)
 
+	case *tree.DEnum:
+		b.writeLengthPrefixedString(v.LogicalRep)
+
 	case *tree.DTimestamp:
 		b.putInt32(8)
 		b.putInt64(timeToPgBinary(v.Time, nil))
@@ -75,8 +75,9 @@ func (a UncachedPhysicalAccessor) GetDatabaseDesc(
 
 // IsValidSchema implements the SchemaAccessor interface.
 func (a UncachedPhysicalAccessor) IsValidSchema(dbDesc *DatabaseDescriptor, scName string) bool {
-	// At this point, only the public schema is recognized.
-	return scName == tree.PublicSchema
+	// At this point, only the public schema and the temporary schemas are
+	// recognized.
+	return scName == tree.PublicSchema || isTemporarySchemaName(scName)
 }
 
 // GetObjectNames implements the SchemaAccessor interface.
@@ -95,8 +96,20 @@ func (a UncachedPhysicalAccessor) GetObjectNames(
 		return nil, nil
 	}
 
+	// Objects in the public schema are registered in system.namespace under
+	// the ID of the database, and temporary objects under the ID of their
+	// schema.
+	parentID := dbDesc.ID
+	if isTemporarySchemaName(scName) {
+		var err error
+		parentID, err = getTemporarySchemaID(ctx, txn, dbDesc.ID, scName)
+		if err != nil || parentID == sqlbase.InvalidID {
+			return nil, err
+		}
+	}
+
 	log.Eventf(ctx, "fetching list of objects for %q", dbDesc.Name)
-	prefix := sqlbase.MakeNameMetadataKey(dbDesc.ID, "")
+	prefix := sqlbase.MakeNameMetadataKey(parentID, "")
 	sr, err := txn.Scan(ctx, prefix, prefix.PrefixEnd(), 0)
 	if err != nil {
 		return nil, err
@@ -109,7 +122,12 @@ func (a UncachedPhysicalAccessor) GetObjectNames(
 		if err != nil {
 			return nil, err
 		}
-		tn := tree.MakeTableName(tree.Name(dbDesc.Name), tree.Name(tableName))
+		if parentID == dbDesc.ID && isTemporarySchemaName(tableName) {
+			// The temporary schemas of the database are registered alongside
+			// the objects in its public schema.
+			continue
+		}
+		tn := tree.MakeTableNameWithSchema(tree.Name(dbDesc.Name), tree.Name(scName), tree.Name(tableName))
 		tn.ExplicitCatalog = flags.explicitPrefix
 		tn.ExplicitSchema = flags.explicitPrefix
 		tableNames = append(tableNames, tn)
@@ -121,8 +139,9 @@ func (a UncachedPhysicalAccessor) GetObjectNames(
 func (a UncachedPhysicalAccessor) GetObjectDesc(
 	ctx context.Context, txn *client.Txn, name *ObjectName, flags ObjectLookupFlags,
 ) (ObjectDescriptor, error) {
-	// At this point, only the public schema is recognized.
-	if name.Schema() != tree.PublicSchema {
+	// At this point, only the public schema and the temporary schemas are
+	// recognized.
+	if !a.IsValidSchema(nil /* dbDesc */, name.Schema()) {
 		if flags.required {
 			return nil, sqlbase.NewUnsupportedSchemaUsageError(tree.ErrString(name))
 		}
@@ -136,13 +155,32 @@ func (a UncachedPhysicalAccessor) GetObjectDesc(
 		return nil, err
 	}
 
+	// Temporary objects are registered in system.namespace under the ID of
+	// their schema, whose name is in turn registered under the ID of the
+	// database.
+	parentID := dbID
+	if isTemporarySchemaName(name.Schema()) {
+		parentID, err = getTemporarySchemaID(ctx, txn, dbID, name.Schema())
+		if err != nil {
+			return nil, err
+		}
+	} else if isTemporarySchemaName(name.Table()) {
+		parentID = sqlbase.InvalidID
+	}
+	if parentID == sqlbase.InvalidID {
+		if flags.required {
+			return nil, sqlbase.NewUndefinedRelationError(name)
+		}
+		return nil, nil
+	}
+
 	// Try to use the system name resolution bypass. This avoids a hotspot.
 	// Note: we can only bypass name to ID resolution. The desc
 	// lookup below must still go through KV because system descriptors
 	// can be modified on a running cluster.
-	descID := sqlbase.LookupSystemTableDescriptorID(dbID, name.Table())
+	descID := sqlbase.LookupSystemTableDescriptorID(parentID, name.Table())
 	if descID == sqlbase.InvalidID {
-		descID, err = getDescriptorID(ctx, txn, sqlbase.NewTableKey(dbID, name.Table()))
+		descID, err = getDescriptorID(ctx, txn, sqlbase.NewTableKey(parentID, name.Table()))
 		if err != nil {
 			return nil, err
 		}
@@ -158,7 +196,13 @@ func (a UncachedPhysicalAccessor) GetObjectDesc(
 	// Look up the table using the discovered database descriptor.
 	desc := &sqlbase.TableDescriptor{}
 	err = getDescriptorByID(ctx, txn, descID, desc)
-	if err != nil {
+	if err == errDescriptorIsType {
+		// The name refers to a user-defined type, not a relation.
+		if flags.required {
+			return nil, sqlbase.NewUndefinedRelationError(name)
+		}
+		return nil, nil
+	} else if err != nil {
 		return nil, err
 	}
 
@@ -128,6 +128,7 @@ type planNodeFastPath interface {
 var _ planNode = &alterIndexNode{}
 var _ planNode = &alterSequenceNode{}
 var _ planNode = &alterTableNode{}
+var _ planNode = &alterTypeNode{}
 var _ planNode = &bufferNode{}
 var _ planNode = &cancelQueriesNode{}
 var _ planNode = &cancelSessionsNode{}
@@ -137,6 +138,7 @@ var _ planNode = &createIndexNode{}
 var _ planNode = &createSequenceNode{}
 var _ planNode = &createStatsNode{}
 var _ planNode = &createTableNode{}
+var _ planNode = &createTypeNode{}
 var _ planNode = &CreateUserNode{}
 var _ planNode = &createViewNode{}
 var _ planNode = &delayedNode{}
@@ -147,6 +149,7 @@ var _ planNode = &dropDatabaseNode{}
 var _ planNode = &dropIndexNode{}
 var _ planNode = &dropSequenceNode{}
 var _ planNode = &dropTableNode{}
+var _ planNode = &dropTypeNode{}
 var _ planNode = &DropUserNode{}
 var _ planNode = &dropViewNode{}
 var _ planNode = &errorIfRowsNode{}
@@ -199,6 +202,7 @@ var _ planNodeFastPath = &rowCountNode{}
 var _ planNodeFastPath = &serializeNode{}
 var _ planNodeFastPath = &setZoneConfigNode{}
 var _ planNodeFastPath = &controlJobsNode{}
+var _ planNodeFastPath = &controlSchedulesNode{}
 
 // planNodeRequireSpool serves as marker for nodes whose parent must
 // ensure that the node is fully run to completion (and the results
@@ -430,6 +434,8 @@ func (p *planner) newPlan(
 		return p.AlterTable(ctx, n)
 	case *tree.AlterSequence:
 		return p.AlterSequence(ctx, n)
+	case *tree.AlterType:
+		return p.AlterType(ctx, n)
 	case *tree.AlterUserSetPassword:
 		return p.AlterUserSetPassword(ctx, n)
 	case *tree.CancelQueries:
@@ -444,6 +450,8 @@ func (p *planner) newPlan(
 		return p.CommentOnTable(ctx, n)
 	case *tree.ControlJobs:
 		return p.ControlJobs(ctx, n)
+	case *tree.ControlSchedules:
+		return p.ControlSchedules(ctx, n)
 	case *tree.Scrub:
 		return p.Scrub(ctx, n)
 	case *tree.CreateDatabase:
@@ -460,6 +468,8 @@ func (p *planner) newPlan(
 		return p.CreateSequence(ctx, n)
 	case *tree.CreateStats:
 		return p.CreateStatistics(ctx, n)
+	case *tree.CreateType:
+		return p.CreateType(ctx, n)
 	case *tree.Deallocate:
 		return p.Deallocate(ctx, n)
 	case *tree.Delete:
@@ -476,6 +486,8 @@ func (p *planner) newPlan(
 		return p.DropView(ctx, n)
 	case *tree.DropSequence:
 		return p.DropSequence(ctx, n)
+	case *tree.DropType:
+		return p.DropType(ctx, n)
 	case *tree.DropUser:
 		return p.DropUser(ctx, n)
 	case *tree.Grant:
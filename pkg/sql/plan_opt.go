@@ -53,14 +53,14 @@ func (p *planner) prepareUsingOptimizer(ctx context.Context) (planFlags, error)
 	// code that can introduce unnecessary txn retries (because of looking up
 	// descriptors and such).
 	switch stmt.AST.(type) {
-	case *tree.AlterIndex, *tree.AlterTable, *tree.AlterSequence,
+	case *tree.AlterIndex, *tree.AlterTable, *tree.AlterSequence, *tree.AlterType,
 		*tree.BeginTransaction,
 		*tree.CommentOnColumn, *tree.CommentOnDatabase, *tree.CommentOnTable, *tree.CommitTransaction,
 		*tree.CopyFrom, *tree.CreateDatabase, *tree.CreateIndex, *tree.CreateView,
 		*tree.CreateSequence,
-		*tree.CreateStats,
+		*tree.CreateStats, *tree.CreateType,
 		*tree.Deallocate, *tree.Discard, *tree.DropDatabase, *tree.DropIndex,
-		*tree.DropTable, *tree.DropView, *tree.DropSequence, *tree.DropRole,
+		*tree.DropTable, *tree.DropView, *tree.DropSequence, *tree.DropType, *tree.DropRole,
 		*tree.Execute,
 		*tree.Grant, *tree.GrantRole,
 		*tree.Prepare,
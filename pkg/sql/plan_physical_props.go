@@ -98,6 +98,7 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *alterIndexNode:
 	case *alterSequenceNode:
 	case *alterTableNode:
+	case *alterTypeNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueriesNode:
 	case *cancelSessionsNode:
@@ -106,11 +107,13 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *commentOnColumnNode:
 	case *commentOnDatabaseNode:
 	case *controlJobsNode:
+	case *controlSchedulesNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
 	case *createSequenceNode:
 	case *createStatsNode:
 	case *createTableNode:
+	case *createTypeNode:
 	case *createViewNode:
 	case *delayedNode:
 	case *deleteRangeNode:
@@ -118,6 +121,7 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *dropIndexNode:
 	case *dropSequenceNode:
 	case *dropTableNode:
+	case *dropTypeNode:
 	case *dropViewNode:
 	case *errorIfRowsNode:
 	case *exportNode:
@@ -156,6 +156,29 @@ func (p *planner) renameColumn(
 		}
 	}
 
+	// Rename the column in the predicates of partial indexes, including the
+	// ones which are being added.
+	renameInPredicate := func(idx *sqlbase.IndexDescriptor) error {
+		if !idx.IsPartial() {
+			return nil
+		}
+		var err error
+		idx.Predicate, err = renameIn(idx.Predicate)
+		return err
+	}
+	for i := range tableDesc.Indexes {
+		if err := renameInPredicate(&tableDesc.Indexes[i]); err != nil {
+			return false, err
+		}
+	}
+	for _, m := range tableDesc.Mutations {
+		if idx := m.GetIndex(); idx != nil {
+			if err := renameInPredicate(idx); err != nil {
+				return false, err
+			}
+		}
+	}
+
 	// Rename the column in the indexes.
 	tableDesc.RenameColumnDescriptor(col, string(*newName))
 
@@ -16,6 +16,8 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -79,17 +81,35 @@ func (n *renameTableNode) startExec(params runParams) error {
 	newTn := n.newTn
 	tableDesc := n.tableDesc
 
-	prevDbDesc, err := p.ResolveUncachedDatabase(ctx, oldTn)
+	// Temporary objects stay in the temporary schema they were created in.
+	temporary := tableDesc.IsTemporary()
+	if temporary {
+		if !newTn.ExplicitSchema {
+			newTn.SchemaName = oldTn.SchemaName
+			newTn.ExplicitSchema = true
+		}
+		newTn.SchemaName = tree.Name(p.resolveTemporarySchemaAlias(newTn.Schema()))
+		if newTn.Schema() != oldTn.Schema() {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"cannot move temporary relation %q to another schema", tree.ErrString(oldTn))
+		}
+	}
+
+	prevDbDesc, err := p.resolveUncachedTargetDatabase(ctx, oldTn, temporary)
 	if err != nil {
 		return err
 	}
 
 	// Check if target database exists.
 	// We also look at uncached descriptors here.
-	targetDbDesc, err := p.ResolveUncachedDatabase(ctx, newTn)
+	targetDbDesc, err := p.resolveUncachedTargetDatabase(ctx, newTn, temporary)
 	if err != nil {
 		return err
 	}
+	if temporary && targetDbDesc.ID != prevDbDesc.ID {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot move temporary relation %q to another database", tree.ErrString(oldTn))
+	}
 
 	if err := p.CheckPrivilege(ctx, targetDbDesc, privilege.CREATE); err != nil {
 		return err
@@ -103,10 +123,11 @@ func (n *renameTableNode) startExec(params runParams) error {
 		return nil
 	}
 
+	prevParentID := tableDesc.GetNamespaceParentID()
 	tableDesc.SetName(newTn.Table())
 	tableDesc.ParentID = targetDbDesc.ID
 
-	newTbKey := sqlbase.NewTableKey(targetDbDesc.ID, newTn.Table()).Key()
+	newTbKey := sqlbase.NewTableKey(tableDesc.GetNamespaceParentID(), newTn.Table()).Key()
 
 	if err := tableDesc.Validate(ctx, p.txn); err != nil {
 		return err
@@ -115,7 +136,7 @@ func (n *renameTableNode) startExec(params runParams) error {
 	descID := tableDesc.GetID()
 
 	renameDetails := sqlbase.TableDescriptor_NameInfo{
-		ParentID: prevDbDesc.ID,
+		ParentID: prevParentID,
 		Name:     oldTn.Table()}
 	tableDesc.DrainingNames = append(tableDesc.DrainingNames, renameDetails)
 	if err := p.writeSchemaChange(ctx, tableDesc, sqlbase.InvalidMutationID); err != nil {
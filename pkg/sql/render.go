@@ -94,9 +94,11 @@ func (p *planner) Select(
 	limit := n.Limit
 	orderBy := n.OrderBy
 	with := n.With
+	hasLocking := len(n.Locking) > 0
 
 	for s, ok := wrapped.(*tree.ParenSelect); ok; s, ok = wrapped.(*tree.ParenSelect) {
 		wrapped = s.Select.Select
+		hasLocking = hasLocking || len(s.Select.Locking) > 0
 		if s.Select.With != nil {
 			if with != nil {
 				return nil, unimplemented.NewWithIssue(24303,
@@ -122,6 +124,12 @@ func (p *planner) Select(
 		}
 	}
 
+	if hasLocking {
+		// Row-level locking is only implemented by the cost-based optimizer.
+		return nil, unimplemented.NewWithIssue(6583,
+			"SELECT FOR UPDATE/SHARE is not supported by the heuristic planner")
+	}
+
 	switch s := wrapped.(type) {
 	case *tree.SelectClause:
 		// Select can potentially optimize index selection if it's being ordered,
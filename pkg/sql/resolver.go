@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/errors"
 )
 
@@ -215,6 +216,16 @@ func (p *planner) ResolveUncachedTableDescriptor(
 // resolution.
 func ResolveTargetObject(
 	ctx context.Context, sc SchemaResolver, tn *ObjectName,
+) (res *DatabaseDescriptor, err error) {
+	return resolveTargetObject(ctx, sc, tn, false /* temporary */)
+}
+
+// resolveTargetObject is the implementation of ResolveTargetObject. If
+// temporary is set, the name must have been qualified with the session's
+// temporary schema by QualifyTemporaryTarget, which is then accepted as a
+// valid target schema.
+func resolveTargetObject(
+	ctx context.Context, sc SchemaResolver, tn *ObjectName, temporary bool,
 ) (res *DatabaseDescriptor, err error) {
 	found, descI, err := tn.ResolveTarget(ctx, sc, sc.CurrentDatabase(), sc.CurrentSearchPath())
 	if err != nil {
@@ -230,7 +241,7 @@ func ResolveTargetObject(
 		err = errors.WithHint(err, "verify that the current database and search_path are valid and/or the target database exists")
 		return nil, err
 	}
-	if tn.Schema() != tree.PublicSchema {
+	if tn.Schema() != tree.PublicSchema && !temporary {
 		return nil, pgerror.Newf(pgcode.InvalidName,
 			"schema cannot be modified: %q", tree.ErrString(&tn.TableNamePrefix))
 	}
@@ -239,13 +250,78 @@ func ResolveTargetObject(
 
 func (p *planner) ResolveUncachedDatabase(
 	ctx context.Context, tn *ObjectName,
+) (res *UncachedDatabaseDescriptor, err error) {
+	return p.resolveUncachedTargetDatabase(ctx, tn, false /* temporary */)
+}
+
+// resolveUncachedTargetDatabase is like ResolveUncachedDatabase, but also
+// accepts the session's temporary schema as the target of temporary objects.
+// See resolveTargetObject.
+func (p *planner) resolveUncachedTargetDatabase(
+	ctx context.Context, tn *ObjectName, temporary bool,
 ) (res *UncachedDatabaseDescriptor, err error) {
 	p.runWithOptions(resolveFlags{skipCache: true}, func() {
-		res, err = ResolveTargetObject(ctx, p, tn)
+		res, err = resolveTargetObject(ctx, p, tn, temporary)
 	})
 	return res, err
 }
 
+// ResolveTypeByName implements the tree.TypeReferenceResolver interface.
+// User-defined types are looked up in the public schema of the current
+// database.
+func (p *planner) ResolveTypeByName(name string) (*types.T, error) {
+	tn := tree.MakeUnqualifiedTableName(tree.Name(name))
+	if p.CurrentDatabase() == "" {
+		return nil, sqlbase.NewUndefinedTypeError(&tn)
+	}
+	desc, err := p.resolveTypeDesc(p.EvalContext().Context, &tn, true /* required */)
+	if err != nil {
+		return nil, err
+	}
+	// Older nodes can't decode the values of a user-defined type, e.g. in the
+	// columns of a table.
+	if err := p.checkEnumsVersion(); err != nil {
+		return nil, err
+	}
+	return desc.MakeTypesT(), nil
+}
+
+// resolveTypeDesc looks up the descriptor of a user-defined type. If
+// required is false, a nil descriptor is returned when the type does not
+// exist. The name is qualified in-place.
+func (p *planner) resolveTypeDesc(
+	ctx context.Context, tn *ObjectName, required bool,
+) (*sqlbase.TypeDescriptor, error) {
+	// Report errors using the name as it was written.
+	origName := *tn
+	dbDesc, err := p.ResolveUncachedDatabase(ctx, tn)
+	if err != nil {
+		return nil, err
+	}
+	id, err := getDescriptorID(ctx, p.txn, sqlbase.NewTableKey(dbDesc.ID, tn.Table()))
+	if err != nil {
+		return nil, err
+	}
+	if id == sqlbase.InvalidID {
+		if required {
+			return nil, sqlbase.NewUndefinedTypeError(&origName)
+		}
+		return nil, nil
+	}
+	desc := &sqlbase.Descriptor{}
+	if err := p.txn.GetProto(ctx, sqlbase.MakeDescMetadataKey(id), desc); err != nil {
+		return nil, err
+	}
+	typ := desc.GetType()
+	if typ == nil {
+		if required {
+			return nil, sqlbase.NewWrongObjectTypeError(&origName, "type")
+		}
+		return nil, nil
+	}
+	return typ, nil
+}
+
 // ResolveRequiredType can be passed to the ResolveExistingObject function to
 // require the returned descriptor to be of a specific type.
 type ResolveRequiredType int
@@ -270,6 +346,7 @@ var requiredTypeNames = [...]string{
 func (p *planner) LookupSchema(
 	ctx context.Context, dbName, scName string,
 ) (found bool, scMeta tree.SchemaMeta, err error) {
+	scName = p.resolveTemporarySchemaAlias(scName)
 	sc := p.LogicalSchemaAccessor()
 	dbDesc, err := sc.GetDatabaseDesc(ctx, p.txn, dbName, p.CommonLookupFlags(false /*required*/))
 	if err != nil || dbDesc == nil {
@@ -282,6 +359,7 @@ func (p *planner) LookupSchema(
 func (p *planner) LookupObject(
 	ctx context.Context, requireMutable bool, dbName, scName, tbName string,
 ) (found bool, objMeta tree.NameResolutionResult, err error) {
+	scName = p.resolveTemporarySchemaAlias(scName)
 	sc := p.LogicalSchemaAccessor()
 	p.tableName = tree.MakeTableNameWithSchema(tree.Name(dbName), tree.Name(scName), tree.Name(tbName))
 	objDesc, err := sc.GetObjectDesc(ctx, p.txn, &p.tableName, p.ObjectLookupFlags(false /*required*/, requireMutable))
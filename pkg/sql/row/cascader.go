@@ -372,6 +372,8 @@ func (c *cascader) addIndexPKRowFetcher(
 		false, /* reverse */
 		false, /* returnRangeInfo */
 		false, /* isCheck */
+		roachpb.KEY_LOCKING_NONE,
+		roachpb.LOCK_WAIT_BLOCK,
 		c.alloc,
 		FetcherTableArgs{
 			Desc:             table,
@@ -435,6 +437,8 @@ func (c *cascader) addRowDeleter(
 		false, /* reverse */
 		false, /* returnRangeInfo */
 		false, /* isCheck */
+		roachpb.KEY_LOCKING_NONE,
+		roachpb.LOCK_WAIT_BLOCK,
 		c.alloc,
 		tableArgs,
 	); err != nil {
@@ -495,6 +499,8 @@ func (c *cascader) addRowUpdater(
 		false, /* reverse */
 		false, /* returnRangeInfo */
 		false, /* isCheck */
+		roachpb.KEY_LOCKING_NONE,
+		roachpb.LOCK_WAIT_BLOCK,
 		c.alloc,
 		tableArgs,
 	); err != nil {
@@ -187,6 +187,11 @@ type CFetcher struct {
 	// If set, GetRangesInfo() can be used to retrieve the accumulated info.
 	returnRangeInfo bool
 
+	// lockStr and lockWaitPolicy describe the row-level locking mode, if any,
+	// that the CFetcher's scans acquire on the rows they read.
+	lockStr        roachpb.KeyLockingStrength
+	lockWaitPolicy roachpb.LockWaitPolicy
+
 	// traceKV indicates whether or not session tracing is enabled. It is set
 	// when beginning a new scan.
 	traceKV bool
@@ -235,10 +240,15 @@ type CFetcher struct {
 
 // Init sets up a Fetcher for a given table and index. If we are using a
 // non-primary index, tables.ValNeededForCol can only refer to columns in the
-// index.
+// index. lockStr and lockWaitPolicy configure the row-level locking, if any,
+// that scans performed by the CFetcher acquire.
 func (rf *CFetcher) Init(
 	reverse,
-	returnRangeInfo bool, isCheck bool, tables ...FetcherTableArgs,
+	returnRangeInfo bool,
+	isCheck bool,
+	lockStr roachpb.KeyLockingStrength,
+	lockWaitPolicy roachpb.LockWaitPolicy,
+	tables ...FetcherTableArgs,
 ) error {
 	if len(tables) == 0 {
 		return errors.AssertionFailedf("no tables to fetch from")
@@ -246,6 +256,8 @@ func (rf *CFetcher) Init(
 
 	rf.reverse = reverse
 	rf.returnRangeInfo = returnRangeInfo
+	rf.lockStr = lockStr
+	rf.lockWaitPolicy = lockWaitPolicy
 
 	if len(tables) > 1 {
 		return errors.New("multiple tables not supported in cfetcher")
@@ -448,7 +460,9 @@ func (rf *CFetcher) StartScan(
 		firstBatchLimit++
 	}
 
-	f, err := makeKVBatchFetcher(txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo)
+	f, err := makeKVBatchFetcher(txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo,
+		rf.lockStr, rf.lockWaitPolicy,
+	)
 	if err != nil {
 		return err
 	}
@@ -105,6 +105,21 @@ func makeRowDeleterWithoutCascader(
 				return Deleter{}, err
 			}
 		}
+		// An index with the primary index encoding has one entry per non-NULL
+		// column family, so all of its columns are needed to find its entries.
+		if index.EncodingType == sqlbase.PrimaryIndexEncoding {
+			for _, colID := range index.StoreColumnIDs {
+				if err := maybeAddCol(colID); err != nil {
+					return Deleter{}, err
+				}
+			}
+		}
+		// The predicate columns of partial indexes are needed to tell whether
+		// the row has an entry in the index: the key of a unique partial index
+		// could otherwise belong to another row.
+		if err := maybeAddPredicateCols(tableDesc, &index, maybeAddCol); err != nil {
+			return Deleter{}, err
+		}
 	}
 
 	rd := Deleter{
@@ -142,8 +157,18 @@ func (rd *Deleter) DeleteRow(
 	// Delete the row from any secondary indices.
 	for i := range secondaryIndexEntries {
 		secondaryIndexEntry := &secondaryIndexEntries[i]
+		if secondaryIndexEntry.Key == nil {
+			// The row does not satisfy the predicate of this partial index.
+			continue
+		}
 		if traceKV {
-			log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(rd.Helper.secIndexValDirs[i], secondaryIndexEntry.Key))
+			if i < len(rd.Helper.secIndexValDirs) {
+				log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(rd.Helper.secIndexValDirs[i], secondaryIndexEntry.Key))
+			} else {
+				// Additional entries of inverted indexes and of indexes encoded like
+				// a primary index.
+				log.VEventf(ctx, 2, "Del %s", secondaryIndexEntry.Key)
+			}
 		}
 		b.Del(&secondaryIndexEntry.Key)
 	}
@@ -109,7 +109,8 @@ func NewUniquenessConstraintViolationError(
 		ValNeededForCol:  valNeededForCol,
 	}
 	if err := rf.Init(
-		false /* reverse */, false /* returnRangeInfo */, false /* isCheck */, &sqlbase.DatumAlloc{}, tableArgs,
+		false /* reverse */, false /* returnRangeInfo */, false, /* isCheck */
+		roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, &sqlbase.DatumAlloc{}, tableArgs,
 	); err != nil {
 		return err
 	}
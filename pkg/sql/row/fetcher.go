@@ -198,6 +198,11 @@ type Fetcher struct {
 	// If set, GetRangesInfo() can be used to retrieve the accumulated info.
 	returnRangeInfo bool
 
+	// lockStr and lockWaitPolicy describe the row-level locking mode, if any,
+	// that the Fetcher's scans acquire on the rows they read.
+	lockStr        roachpb.KeyLockingStrength
+	lockWaitPolicy roachpb.LockWaitPolicy
+
 	// traceKV indicates whether or not session tracing is enabled. It is set
 	// when beginning a new scan.
 	traceKV bool
@@ -240,10 +245,13 @@ func (rf *Fetcher) Reset() {
 
 // Init sets up a Fetcher for a given table and index. If we are using a
 // non-primary index, tables.ValNeededForCol can only refer to columns in the
-// index.
+// index. lockStr and lockWaitPolicy configure the row-level locking, if any,
+// that scans performed by the Fetcher acquire.
 func (rf *Fetcher) Init(
 	reverse, returnRangeInfo bool,
 	isCheck bool,
+	lockStr roachpb.KeyLockingStrength,
+	lockWaitPolicy roachpb.LockWaitPolicy,
 	alloc *sqlbase.DatumAlloc,
 	tables ...FetcherTableArgs,
 ) error {
@@ -253,6 +261,8 @@ func (rf *Fetcher) Init(
 
 	rf.reverse = reverse
 	rf.returnRangeInfo = returnRangeInfo
+	rf.lockStr = lockStr
+	rf.lockWaitPolicy = lockWaitPolicy
 	rf.alloc = alloc
 	rf.isCheck = isCheck
 
@@ -449,6 +459,7 @@ func (rf *Fetcher) StartScan(
 	rf.traceKV = traceKV
 	f, err := makeKVBatchFetcher(
 		txn, spans, rf.reverse, limitBatches, rf.firstBatchLimit(limitHint), rf.returnRangeInfo,
+		rf.lockStr, rf.lockWaitPolicy,
 	)
 	if err != nil {
 		return err
@@ -528,6 +539,9 @@ func (rf *Fetcher) StartInconsistentScan(
 		limitBatches,
 		rf.firstBatchLimit(limitHint),
 		rf.returnRangeInfo,
+		// Inconsistent scans never acquire row-level locks.
+		roachpb.KEY_LOCKING_NONE,
+		roachpb.LOCK_WAIT_BLOCK,
 	)
 	if err != nil {
 		return err
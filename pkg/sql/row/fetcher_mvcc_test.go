@@ -106,7 +106,8 @@ func TestRowFetcherMVCCMetadata(t *testing.T) {
 	}
 	var rf row.Fetcher
 	if err := rf.Init(
-		false /* reverse */, false /* returnRangeInfo */, true /* isCheck */, &sqlbase.DatumAlloc{},
+		false /* reverse */, false /* returnRangeInfo */, true, /* isCheck */
+		roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, &sqlbase.DatumAlloc{},
 		args...,
 	); err != nil {
 		t.Fatal(err)
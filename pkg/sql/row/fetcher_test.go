@@ -71,7 +71,7 @@ func initFetcher(
 	fetcherArgs := makeFetcherArgs(entries)
 
 	if err := fetcher.Init(reverseScan, false /*reverse*/, false, /* isCheck */
-		alloc, fetcherArgs...); err != nil {
+		roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, alloc, fetcherArgs...); err != nil {
 		return nil, err
 	}
 
@@ -1039,7 +1039,7 @@ func TestRowFetcherReset(t *testing.T) {
 
 	fetcherArgs := makeFetcherArgs(args)
 	if err := resetFetcher.Init(false, false /*reverse*/, false, /* isCheck */
-		&da, fetcherArgs...); err != nil {
+		roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, &da, fetcherArgs...); err != nil {
 		t.Fatal(err)
 	}
 
@@ -26,6 +26,12 @@ type rowHelper struct {
 	Indexes      []sqlbase.IndexDescriptor
 	indexEntries []sqlbase.IndexEntry
 
+	// partialIndexPreds holds the predicates of the partial indexes among
+	// Indexes, or nil if there are none. It is built lazily on the first call
+	// to encodeSecondaryIndexes.
+	partialIndexPreds     *sqlbase.PartialIndexPredicates
+	partialIndexPredsInit bool
+
 	// Computed during initialization for pretty-printing.
 	primIndexValDirs []encoding.Direction
 	secIndexValDirs  [][]encoding.Direction
@@ -77,21 +83,49 @@ func (rh *rowHelper) encodeIndexes(
 
 // encodeSecondaryIndexes encodes the secondary index keys. The
 // secondaryIndexEntries are only valid until the next call to encodeIndexes or
-// encodeSecondaryIndexes.
+// encodeSecondaryIndexes. The entries of partial indexes whose predicate is not
+// satisfied by the row have a nil Key.
 func (rh *rowHelper) encodeSecondaryIndexes(
 	colIDtoRowIndex map[sqlbase.ColumnID]int, values []tree.Datum,
 ) (secondaryIndexEntries []sqlbase.IndexEntry, err error) {
 	if len(rh.indexEntries) != len(rh.Indexes) {
 		rh.indexEntries = make([]sqlbase.IndexEntry, len(rh.Indexes))
 	}
+	if !rh.partialIndexPredsInit {
+		rh.partialIndexPreds, err = sqlbase.NewPartialIndexPredicates(rh.TableDesc.TableDesc(), rh.Indexes)
+		if err != nil {
+			return nil, err
+		}
+		rh.partialIndexPredsInit = true
+	}
 	rh.indexEntries, err = sqlbase.EncodeSecondaryIndexes(
-		rh.TableDesc.TableDesc(), rh.Indexes, colIDtoRowIndex, values, rh.indexEntries)
+		rh.TableDesc.TableDesc(), rh.Indexes, colIDtoRowIndex, values, rh.indexEntries,
+		rh.partialIndexPreds)
 	if err != nil {
 		return nil, err
 	}
 	return rh.indexEntries, nil
 }
 
+// maybeAddPredicateCols calls maybeAddCol with each of the columns referenced
+// by the predicate of the given index, if it is a partial index.
+func maybeAddPredicateCols(
+	tableDesc *sqlbase.ImmutableTableDescriptor,
+	index *sqlbase.IndexDescriptor,
+	maybeAddCol func(sqlbase.ColumnID) error,
+) error {
+	colIDs, err := index.PredicateColumnIDs(tableDesc.TableDesc())
+	if err != nil {
+		return err
+	}
+	for _, colID := range colIDs {
+		if err := maybeAddCol(colID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // skipColumnInPK returns true if the value at column colID does not need
 // to be encoded because it is already part of the primary key. Composite
 // datums are considered too, so a composite datum in a PK will return false.
@@ -176,6 +176,10 @@ func (ri *Inserter) InsertRow(
 	putFn = insertInvertedPutFn
 	for i := range secondaryIndexEntries {
 		e := &secondaryIndexEntries[i]
+		if e.Key == nil {
+			// The row does not satisfy the predicate of this partial index.
+			continue
+		}
 		putFn(ctx, b, &e.Key, &e.Value, traceKV)
 	}
 
@@ -16,6 +16,8 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -55,6 +57,11 @@ type txnKVFetcher struct {
 	// returnRangeInfo, if set, causes the kvBatchFetcher to populate rangeInfos.
 	// See also rowFetcher.returnRangeInfo.
 	returnRangeInfo bool
+	// lockStr and lockWaitPolicy configure the row-level locking mode, if
+	// any, that the scans issued by the fetcher acquire on the keys they
+	// return.
+	lockStr        roachpb.KeyLockingStrength
+	lockWaitPolicy roachpb.LockWaitPolicy
 
 	fetchEnd bool
 	batchIdx int
@@ -138,6 +145,9 @@ func (f *txnKVFetcher) getBatchSizeForIdx(batchIdx int) int64 {
 // Subsequent batches are larger, up to kvBatchSize.
 //
 // Batch limits can only be used if the spans are ordered.
+//
+// lockStr and lockWaitPolicy determine whether the scans acquire row-level
+// locks on the keys they return and how they handle conflicting locks.
 func makeKVBatchFetcher(
 	txn *client.Txn,
 	spans roachpb.Spans,
@@ -145,6 +155,8 @@ func makeKVBatchFetcher(
 	useBatchLimit bool,
 	firstBatchLimit int64,
 	returnRangeInfo bool,
+	lockStr roachpb.KeyLockingStrength,
+	lockWaitPolicy roachpb.LockWaitPolicy,
 ) (txnKVFetcher, error) {
 	sendFn := func(ctx context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, error) {
 		res, err := txn.Send(ctx, ba)
@@ -155,6 +167,7 @@ func makeKVBatchFetcher(
 	}
 	return makeKVBatchFetcherWithSendFunc(
 		sendFn, spans, reverse, useBatchLimit, firstBatchLimit, returnRangeInfo,
+		lockStr, lockWaitPolicy,
 	)
 }
 
@@ -167,6 +180,8 @@ func makeKVBatchFetcherWithSendFunc(
 	useBatchLimit bool,
 	firstBatchLimit int64,
 	returnRangeInfo bool,
+	lockStr roachpb.KeyLockingStrength,
+	lockWaitPolicy roachpb.LockWaitPolicy,
 ) (txnKVFetcher, error) {
 	if firstBatchLimit < 0 || (!useBatchLimit && firstBatchLimit != 0) {
 		return txnKVFetcher{}, errors.Errorf("invalid batch limit %d (useBatchLimit: %t)",
@@ -218,6 +233,8 @@ func makeKVBatchFetcherWithSendFunc(
 		useBatchLimit:   useBatchLimit,
 		firstBatchLimit: firstBatchLimit,
 		returnRangeInfo: returnRangeInfo,
+		lockStr:         lockStr,
+		lockWaitPolicy:  lockWaitPolicy,
 	}, nil
 }
 
@@ -232,6 +249,8 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 		for i := range f.spans {
 			scans[i].ScanFormat = roachpb.BATCH_RESPONSE
 			scans[i].SetSpan(f.spans[i])
+			scans[i].KeyLocking = f.lockStr
+			scans[i].LockWaitPolicy = f.lockWaitPolicy
 			ba.Requests[i].MustSetInner(&scans[i])
 		}
 	} else {
@@ -239,6 +258,8 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 		for i := range f.spans {
 			scans[i].ScanFormat = roachpb.BATCH_RESPONSE
 			scans[i].SetSpan(f.spans[i])
+			scans[i].KeyLocking = f.lockStr
+			scans[i].LockWaitPolicy = f.lockWaitPolicy
 			ba.Requests[i].MustSetInner(&scans[i])
 		}
 	}
@@ -265,6 +286,11 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 
 	br, err := f.sendFn(ctx, ba)
 	if err != nil {
+		if _, ok := err.(*roachpb.WriteIntentError); ok && f.lockWaitPolicy == roachpb.LOCK_WAIT_ERROR {
+			// With a NOWAIT wait policy, the scan does not wait for conflicting
+			// locks to be released and instead surfaces the conflict.
+			return pgerror.Wrapf(err, pgcode.LockNotAvailable, "could not obtain lock on row")
+		}
 		return err
 	}
 	if br != nil {
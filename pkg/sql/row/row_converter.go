@@ -12,13 +12,16 @@ package row
 
 import (
 	"context"
+	"math/rand"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -164,8 +167,11 @@ func GenerateInsertRow(
 type KVBatch struct {
 	// Source is where the row data in the batch came from.
 	Source int32
-	// LastRow is the index of the last converted row in source in this batch.
-	LastRow uint64
+	// FirstRow and LastRow are the range of rows in source, numbered from 1,
+	// that this batch covers. LastRow is zero if the rows are not tracked.
+	FirstRow, LastRow uint64
+	// EndOffset, if non-zero, is the byte offset in source just past LastRow.
+	EndOffset int64
 	// Progress represents the fraction of the input that generated this row.
 	Progress float32
 	// KVs is the actual converted KV data.
@@ -191,18 +197,35 @@ type DatumRowConverter struct {
 	IsTargetCol map[int]struct{}
 
 	// The rest of these are derived from tableDesc, just cached here.
-	hidden                int
 	ri                    Inserter
 	EvalCtx               *tree.EvalContext
 	cols                  []sqlbase.ColumnDescriptor
 	VisibleCols           []sqlbase.ColumnDescriptor
 	VisibleColTypes       []*types.T
 	defaultExprs          []tree.TypedExpr
+	computeExprs          []tree.TypedExpr
+	computedCols          []sqlbase.ColumnDescriptor
 	computedIVarContainer sqlbase.RowIndexedVarContainer
 
+	// numTargetCols is the number of leading Datums that are populated by the
+	// caller. The remaining Datums are for the non-target columns that have
+	// DEFAULT expressions or are computed, and are populated by Row.
+	numTargetCols int
+	// rowIDCols are the indexes of the columns whose DEFAULT expression is
+	// unique_rowid(), such as the hidden rowid primary key.
+	rowIDCols []int
+	// rng is the source of the volatile functions in DEFAULT expressions,
+	// seeded for each row by rowSeed. It is nil if there are none.
+	rng     *rand.Rand
+	rowSeed int64
+
+	// rowNum and rowEndOffset are the position in the source of the row that
+	// is being converted, if set by SetRowPosition.
+	rowNum       uint64
+	rowEndOffset int64
+
 	// FractionFn is used to set the progress header in KVBatches.
-	CompletedRowFn func() uint64
-	FractionFn     func() float32
+	FractionFn func() float32
 }
 
 const kvDatumRowConverterBatchSize = 5000
@@ -249,11 +272,21 @@ func NewDatumRowConverter(
 	}
 
 	var txCtx transform.ExprTransformContext
-	// We do not currently support DEFAULT expressions on target or non-target
-	// columns. All non-target columns must be nullable and will be set to NULL
-	// during import. We do however support DEFAULT on hidden columns (which is
-	// only the default _rowid one). This allows those expressions to run.
-	cols, defaultExprs, err := sqlbase.ProcessDefaultColumns(targetColDescriptors, immutDesc, &txCtx, c.EvalCtx)
+	// Non-target columns are populated with their DEFAULT expressions, or NULL
+	// if they have none, and computed columns with their computed expressions.
+	// Computed columns can't be written to directly.
+	for i := range targetColDescriptors {
+		if targetColDescriptors[i].IsComputed() {
+			return nil, sqlbase.CannotWriteToComputedColError(targetColDescriptors[i].Name)
+		}
+	}
+	tn := tree.MakeUnqualifiedTableName(tree.Name(immutDesc.Name))
+	cols, computedCols, computeExprs, err := sqlbase.ProcessComputedColumns(
+		evalCtx.Ctx(), targetColDescriptors, &tn, immutDesc, &txCtx, c.EvalCtx)
+	if err != nil {
+		return nil, errors.Wrap(err, "process computed columns")
+	}
+	cols, defaultExprs, err := sqlbase.ProcessDefaultColumns(cols, immutDesc, &txCtx, c.EvalCtx)
 	if err != nil {
 		return nil, errors.Wrap(err, "process default columns")
 	}
@@ -267,6 +300,8 @@ func NewDatumRowConverter(
 	c.ri = ri
 	c.cols = cols
 	c.defaultExprs = defaultExprs
+	c.computeExprs = computeExprs
+	c.computedCols = computedCols
 
 	c.VisibleCols = immutDesc.VisibleColumns()
 	c.VisibleColTypes = make([]*types.T, len(c.VisibleCols))
@@ -274,23 +309,21 @@ func NewDatumRowConverter(
 		c.VisibleColTypes[i] = c.VisibleCols[i].DatumType()
 	}
 
-	c.Datums = make([]tree.Datum, len(targetColDescriptors), len(cols))
-
-	// Check for a hidden column. This should be the unique_rowid PK if present.
-	c.hidden = -1
-	for i := range cols {
-		col := &cols[i]
-		if col.Hidden {
-			if col.DefaultExpr == nil || *col.DefaultExpr != "unique_rowid()" || c.hidden != -1 {
-				return nil, errors.New("unexpected hidden column")
+	c.numTargetCols = len(targetColDescriptors)
+	c.Datums = make([]tree.Datum, len(cols))
+	for i := c.numTargetCols; i < len(cols); i++ {
+		if def := cols[i].DefaultExpr; def != nil && *def == "unique_rowid()" {
+			c.rowIDCols = append(c.rowIDCols, i)
+			// Row generates the values of these columns itself.
+			c.defaultExprs[i] = nil
+			continue
+		}
+		if c.defaultExprs != nil && c.defaultExprs[i] != nil {
+			if c.defaultExprs[i], err = c.makeDeterministic(c.defaultExprs[i], &cols[i]); err != nil {
+				return nil, err
 			}
-			c.hidden = i
-			c.Datums = append(c.Datums, nil)
 		}
 	}
-	if len(c.Datums) != len(cols) {
-		return nil, errors.New("unexpected hidden column")
-	}
 
 	padding := 2 * (len(immutDesc.Indexes) + len(immutDesc.Families))
 	c.BatchCap = kvDatumRowConverterBatchSize + padding
@@ -303,32 +336,141 @@ func NewDatumRowConverter(
 	return c, nil
 }
 
+// importSeededFuncs are the volatile functions which IMPORT supports in the
+// DEFAULT expressions of non-target columns. Their results are derived from
+// the position of the row in the source, so that a row imported again when the
+// import is resumed or retried gets the same values, and thus the same key.
+var importSeededFuncs = map[string]func(c *DatumRowConverter) tree.Datum{
+	"unique_rowid": func(c *DatumRowConverter) tree.Datum {
+		return tree.NewDInt(tree.DInt(c.rowSeed))
+	},
+	"random": func(c *DatumRowConverter) tree.Datum {
+		return tree.NewDFloat(tree.DFloat(c.rng.Float64()))
+	},
+	"gen_random_uuid": func(c *DatumRowConverter) tree.Datum {
+		return tree.NewDUuid(tree.DUuid{UUID: c.randomUUID()})
+	},
+	"uuid_v4": func(c *DatumRowConverter) tree.Datum {
+		return tree.NewDBytes(tree.DBytes(c.randomUUID().GetBytes()))
+	},
+}
+
+// importTimestampFuncs are the volatile functions which IMPORT supports in
+// the DEFAULT expressions of non-target columns as they are evaluated as of
+// the timestamp of the import.
+var importTimestampFuncs = map[string]struct{}{
+	"now":                   {},
+	"current_timestamp":     {},
+	"transaction_timestamp": {},
+	"statement_timestamp":   {},
+	"current_date":          {},
+}
+
+// seededFuncExpr replaces a call to one of the importSeededFuncs.
+type seededFuncExpr struct {
+	*tree.FuncExpr
+	c   *DatumRowConverter
+	gen func(c *DatumRowConverter) tree.Datum
+}
+
+// Eval is part of the tree.TypedExpr interface.
+func (e *seededFuncExpr) Eval(*tree.EvalContext) (tree.Datum, error) {
+	return e.gen(e.c), nil
+}
+
+// makeDeterministic returns the DEFAULT expression of the given column with
+// the calls to the importSeededFuncs replaced by their deterministic versions,
+// or an error if it calls other volatile functions.
+func (c *DatumRowConverter) makeDeterministic(
+	expr tree.TypedExpr, col *sqlbase.ColumnDescriptor,
+) (tree.TypedExpr, error) {
+	newExpr, err := tree.SimpleVisit(expr, func(e tree.Expr) (bool, tree.Expr, error) {
+		fn, ok := e.(*tree.FuncExpr)
+		if !ok || !fn.IsImpure() {
+			return true, e, nil
+		}
+		def, err := fn.Func.Resolve(sessiondata.SearchPath{})
+		if err != nil {
+			return false, nil, err
+		}
+		if _, ok := importTimestampFuncs[def.Name]; ok {
+			return true, e, nil
+		}
+		gen, ok := importSeededFuncs[def.Name]
+		if !ok {
+			return false, nil, errors.Errorf(
+				"IMPORT does not support the volatile function %s() in the DEFAULT expression of column %q",
+				def.Name, col.Name)
+		}
+		if c.rng == nil {
+			c.rng = rand.New(rand.NewSource(0))
+		}
+		return false, &seededFuncExpr{FuncExpr: fn, c: c, gen: gen}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newExpr.(tree.TypedExpr), nil
+}
+
+// randomUUID returns a version 4 UUID drawn from the random source of the row.
+func (c *DatumRowConverter) randomUUID() uuid.UUID {
+	u, err := uuid.NewGenWithReader(c.rng).NewV4()
+	if err != nil {
+		// Reading from a rand.Rand never fails.
+		panic(err)
+	}
+	return u
+}
+
+// SetRowPosition records the position in the source of the row that is passed
+// to the next call to Row, which the KvBatch then covers: row is its number,
+// from 1, and endOffset, if non-zero, is the byte offset just past it.
+func (c *DatumRowConverter) SetRowPosition(row uint64, endOffset int64) {
+	c.rowNum = row
+	c.rowEndOffset = endOffset
+}
+
 // Row inserts kv operations into the current kv batch, and triggers a SendBatch
 // if necessary.
 func (c *DatumRowConverter) Row(ctx context.Context, fileIndex int32, rowIndex int64) error {
-	if c.hidden >= 0 {
-		// We don't want to call unique_rowid() for the hidden PK column because
-		// it is not idempotent. The sampling from the first stage will be useless
-		// during the read phase, producing a single range split with all of the
-		// data. Instead, we will call our own function that mimics that function,
-		// but more-or-less guarantees that it will not interfere with the numbers
-		// that will be produced by it. The lower 15 bits mimic the node id, but as
-		// the CSV file number. The upper 48 bits are the line number and mimic the
+	c.rowSeed = int64(builtins.GenerateUniqueID(fileIndex, uint64(rowIndex)))
+	if c.rng != nil {
+		c.rng.Seed(c.rowSeed)
+	}
+	for i := c.numTargetCols; i < len(c.cols); i++ {
+		if c.defaultExprs == nil || c.defaultExprs[i] == nil {
+			c.Datums[i] = tree.DNull
+			continue
+		}
+		d, err := c.defaultExprs[i].Eval(c.EvalCtx)
+		if err != nil {
+			return errors.Wrapf(err, "default expression for column %s",
+				tree.ErrString((*tree.Name)(&c.cols[i].Name)))
+		}
+		c.Datums[i] = d
+	}
+	for _, i := range c.rowIDCols {
+		// We don't want to call unique_rowid() for columns that default to it,
+		// such as the hidden PK column, because it is not idempotent. The
+		// sampling from the first stage will be useless during the read phase,
+		// producing a single range split with all of the data. Instead, we will
+		// call our own function that mimics that function, but more-or-less
+		// guarantees that it will not interfere with the numbers that will be
+		// produced by it. The lower 15 bits mimic the node id, but as the CSV
+		// file number. The upper 48 bits are the line number and mimic the
 		// timestamp. It would take a file with many more than 2**32 lines to even
 		// begin approaching what unique_rowid would return today, so we assume it
 		// to be safe. Since the timestamp is won't overlap, it is safe to use any
-		// number in the node id portion. The 15 bits in that portion should account
-		// for up to 32k CSV files in a single IMPORT. In the case of > 32k files,
-		// the data is xor'd so the final bits are flipped instead of set.
-		c.Datums[c.hidden] = tree.NewDInt(builtins.GenerateUniqueID(fileIndex, uint64(rowIndex)))
+		// number in the node id portion. The 15 bits in that portion should
+		// account for up to 32k CSV files in a single IMPORT. In the case of >
+		// 32k files, the data is xor'd so the final bits are flipped instead of
+		// set.
+		c.Datums[i] = tree.NewDInt(tree.DInt(c.rowSeed))
 	}
 
-	// TODO(justin): we currently disallow computed columns in import statements.
-	var computeExprs []tree.TypedExpr
-	var computedCols []sqlbase.ColumnDescriptor
-
 	insertRow, err := GenerateInsertRow(
-		c.defaultExprs, computeExprs, c.cols, computedCols, c.EvalCtx, c.tableDesc, c.Datums, &c.computedIVarContainer)
+		c.defaultExprs, c.computeExprs, c.cols, c.computedCols, c.EvalCtx, c.tableDesc, c.Datums, &c.computedIVarContainer)
 	if err != nil {
 		return errors.Wrap(err, "generate insert row")
 	}
@@ -345,6 +487,11 @@ func (c *DatumRowConverter) Row(ctx context.Context, fileIndex int32, rowIndex i
 	); err != nil {
 		return errors.Wrap(err, "insert row")
 	}
+	if c.rowNum != 0 {
+		c.KvBatch.LastRow = c.rowNum
+		c.KvBatch.EndOffset = c.rowEndOffset
+		c.rowNum, c.rowEndOffset = 0, 0
+	}
 	// If our batch is full, flush it and start a new one.
 	if len(c.KvBatch.KVs) >= kvDatumRowConverterBatchSize {
 		if err := c.SendBatch(ctx); err != nil {
@@ -363,14 +510,16 @@ func (c *DatumRowConverter) SendBatch(ctx context.Context) error {
 	if c.FractionFn != nil {
 		c.KvBatch.Progress = c.FractionFn()
 	}
-	if c.CompletedRowFn != nil {
-		c.KvBatch.LastRow = c.CompletedRowFn()
-	}
 	select {
 	case c.KvCh <- c.KvBatch:
 	case <-ctx.Done():
 		return ctx.Err()
 	}
 	c.KvBatch.KVs = make([]roachpb.KeyValue, 0, c.BatchCap)
+	// The next batch starts right after the rows of this one.
+	if c.KvBatch.LastRow != 0 {
+		c.KvBatch.FirstRow = c.KvBatch.LastRow + 1
+	}
+	c.KvBatch.LastRow, c.KvBatch.EndOffset = 0, 0
 	return nil
 }
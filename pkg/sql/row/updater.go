@@ -137,12 +137,28 @@ func makeUpdaterWithoutCascader(
 		if primaryKeyColChange {
 			return true
 		}
-		return index.RunOverAllColumns(func(id sqlbase.ColumnID) error {
+		if index.RunOverAllColumns(func(id sqlbase.ColumnID) error {
 			if _, ok := updateColIDtoRowIndex[id]; ok {
 				return returnTruePseudoError
 			}
 			return nil
-		}) != nil
+		}) != nil {
+			return true
+		}
+		// A partial index also needs updating if the row may start or stop
+		// satisfying its predicate.
+		predCols, err := index.PredicateColumnIDs(tableDesc.TableDesc())
+		if err != nil {
+			// Be conservative if the predicate can't be analyzed; the error will
+			// surface when the predicate is evaluated.
+			return true
+		}
+		for _, id := range predCols {
+			if _, ok := updateColIDtoRowIndex[id]; ok {
+				return true
+			}
+		}
+		return false
 	}
 
 	writableIndexes := tableDesc.WritableIndexes()
@@ -252,11 +268,17 @@ func makeUpdaterWithoutCascader(
 			if err := index.RunOverAllColumns(maybeAddCol); err != nil {
 				return Updater{}, err
 			}
+			if err := maybeAddPredicateCols(tableDesc, &index, maybeAddCol); err != nil {
+				return Updater{}, err
+			}
 		}
 		for _, index := range deleteOnlyIndexes {
 			if err := index.RunOverAllColumns(maybeAddCol); err != nil {
 				return Updater{}, err
 			}
+			if err := maybeAddPredicateCols(tableDesc, &index, maybeAddCol); err != nil {
+				return Updater{}, err
+			}
 		}
 	}
 
@@ -427,10 +449,17 @@ func (ru *Updater) UpdateRow(
 		var expValue interface{}
 		if !bytes.Equal(newSecondaryIndexEntry.Key, oldSecondaryIndexEntry.Key) {
 			ru.Fks.addCheckForIndex(ru.Helper.Indexes[i].ID, ru.Helper.Indexes[i].Type)
-			if traceKV {
-				log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(ru.Helper.secIndexValDirs[i], oldSecondaryIndexEntry.Key))
+			// The entries of a partial index are empty if the old or the new row
+			// does not satisfy the index predicate.
+			if oldSecondaryIndexEntry.Key != nil {
+				if traceKV {
+					log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(ru.Helper.secIndexValDirs[i], oldSecondaryIndexEntry.Key))
+				}
+				batch.Del(oldSecondaryIndexEntry.Key)
+			}
+			if newSecondaryIndexEntry.Key == nil {
+				continue
 			}
-			batch.Del(oldSecondaryIndexEntry.Key)
 		} else if !newSecondaryIndexEntry.Value.EqualData(oldSecondaryIndexEntry.Value) {
 			expValue = &oldSecondaryIndexEntry.Value
 		} else {
@@ -453,6 +482,9 @@ func (ru *Updater) UpdateRow(
 	// indexed will be handled separately.
 	if ru.DeleteHelper != nil {
 		for _, deletedSecondaryIndexEntry := range deleteOldSecondaryIndexEntries {
+			if deletedSecondaryIndexEntry.Key == nil {
+				continue
+			}
 			if traceKV {
 				log.VEventf(ctx, 2, "Del %s", deletedSecondaryIndexEntry.Key)
 			}
@@ -124,6 +124,11 @@ type scanNode struct {
 	// output. When there are no statistics to make the estimation, it will be
 	// set to zero.
 	estimatedRowCount uint64
+
+	// lockingStrength and lockingWaitPolicy represent the row-level locking
+	// mode of the Scan, as specified by a FOR UPDATE/SHARE clause.
+	lockingStrength   tree.LockingStrength
+	lockingWaitPolicy tree.LockingWaitPolicy
 }
 
 // scanVisibility represents which table columns should be included in a scan.
@@ -137,6 +142,40 @@ const (
 	publicAndNonPublicColumns scanVisibility = 1
 )
 
+// toDistSQLScanLockingStrength converts a tree.LockingStrength to its
+// corresponding distsqlpb.ScanLockingStrength.
+func toDistSQLScanLockingStrength(s tree.LockingStrength) distsqlpb.ScanLockingStrength {
+	switch s {
+	case tree.ForNone:
+		return distsqlpb.ScanLockingStrength_FOR_NONE
+	case tree.ForKeyShare:
+		return distsqlpb.ScanLockingStrength_FOR_KEY_SHARE
+	case tree.ForShare:
+		return distsqlpb.ScanLockingStrength_FOR_SHARE
+	case tree.ForNoKeyUpdate:
+		return distsqlpb.ScanLockingStrength_FOR_NO_KEY_UPDATE
+	case tree.ForUpdate:
+		return distsqlpb.ScanLockingStrength_FOR_UPDATE
+	default:
+		panic(fmt.Sprintf("unknown locking strength %s", s))
+	}
+}
+
+// toDistSQLScanLockingWaitPolicy converts a tree.LockingWaitPolicy to its
+// corresponding distsqlpb.ScanLockingWaitPolicy.
+func toDistSQLScanLockingWaitPolicy(p tree.LockingWaitPolicy) distsqlpb.ScanLockingWaitPolicy {
+	switch p {
+	case tree.LockWaitBlock:
+		return distsqlpb.ScanLockingWaitPolicy_BLOCK
+	case tree.LockWaitSkip:
+		return distsqlpb.ScanLockingWaitPolicy_SKIP
+	case tree.LockWaitError:
+		return distsqlpb.ScanLockingWaitPolicy_ERROR
+	default:
+		panic(fmt.Sprintf("unknown locking wait policy %s", p))
+	}
+}
+
 func (s scanVisibility) toDistSQLScanVisibility() distsqlpb.ScanVisibility {
 	switch s {
 	case publicColumns:
@@ -38,6 +38,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -708,6 +709,55 @@ func (sc *SchemaChanger) maybeMakeAddTablePublic(
 	return nil
 }
 
+// maybePromoteEnumMembers makes the members that ALTER TYPE ... ADD VALUE
+// added as READ_ONLY to the types of the columns of the table writable,
+// once all the nodes are known to use a version of the table that includes
+// them. When a member is writable in all the tables that use its type, it is
+// made writable in the type descriptor as well.
+func (sc *SchemaChanger) maybePromoteEnumMembers(
+	ctx context.Context, table *sqlbase.TableDescriptor,
+) error {
+	if !table.HasReadOnlyEnumMembers() {
+		return nil
+	}
+
+	var typeIDs []sqlbase.ID
+	_, err := sc.leaseMgr.Publish(
+		ctx,
+		table.ID,
+		func(tbl *sqlbase.MutableTableDescriptor) error {
+			typeIDs = typeIDs[:0]
+			if !tbl.HasReadOnlyEnumMembers() {
+				return errDidntUpdateDescriptor
+			}
+			promote := func(col *sqlbase.ColumnDescriptor) {
+				if sqlbase.EnumHasReadOnlyMembers(&col.Type) {
+					col.Type = *sqlbase.MakeEnumMembersWritable(&col.Type)
+					typeIDs = append(typeIDs, sqlbase.ID(types.UserDefinedTypeOIDToID(col.Type.Oid())))
+				}
+			}
+			for i := range tbl.Columns {
+				promote(&tbl.Columns[i])
+			}
+			for i := range tbl.Mutations {
+				if col := tbl.Mutations[i].GetColumn(); col != nil {
+					promote(col)
+				}
+			}
+			return nil
+		},
+		func(txn *client.Txn) error {
+			for _, id := range typeIDs {
+				if err := promoteEnumMembers(ctx, txn, id); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+	return err
+}
+
 func (sc *SchemaChanger) maybeGCMutations(
 	ctx context.Context, inSession bool, table *sqlbase.TableDescriptor,
 ) error {
@@ -937,6 +987,10 @@ func (sc *SchemaChanger) exec(
 		return err
 	}
 
+	if err := sc.maybePromoteEnumMembers(ctx, tableDesc); err != nil {
+		return err
+	}
+
 	if err := sc.maybeGCMutations(ctx, inSession, tableDesc); err != nil {
 		return err
 	}
@@ -1212,6 +1266,9 @@ func (sc *SchemaChanger) waitToUpdateLeases(ctx context.Context, tableID sqlbase
 func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.ImmutableTableDescriptor, error) {
 	isRollback := false
 	jobSucceeded := true
+	// cleanupMutationID is set when completing the mutations queues up
+	// follow-up mutations which are run as part of the same job.
+	cleanupMutationID := sqlbase.InvalidMutationID
 	now := timeutil.Now().UnixNano()
 
 	// Get the other tables whose foreign key backreferences need to be removed.
@@ -1254,6 +1311,7 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.ImmutableTableDescr
 		// Reset vars here because update function can be called multiple times in a retry.
 		isRollback = false
 		jobSucceeded = true
+		cleanupMutationID = sqlbase.InvalidMutationID
 
 		i := 0
 		scDesc, ok := descs[sc.tableID]
@@ -1291,6 +1349,12 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.ImmutableTableDescr
 				}
 				backrefTable.InboundFKs = append(backrefTable.InboundFKs, constraint.ForeignKey)
 			}
+			if swap := mutation.GetPrimaryKeySwap(); swap != nil &&
+				mutation.Direction == sqlbase.DescriptorMutation_ADD {
+				// Completing a primary key swap queues up the drop of the old
+				// indexes under the next mutation ID.
+				cleanupMutationID = scDesc.ClusterVersion.NextMutationID
+			}
 			if err := scDesc.MakeMutationComplete(mutation); err != nil {
 				return err
 			}
@@ -1311,16 +1375,21 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.ImmutableTableDescr
 				break
 			}
 		}
+		if cleanupMutationID != sqlbase.InvalidMutationID {
+			scDesc.MutationJobs = append(scDesc.MutationJobs, sqlbase.TableDescriptor_MutationJob{
+				MutationID: cleanupMutationID, JobID: *sc.job.ID(),
+			})
+		}
 		return nil
 	}
 
 	descs, err := sc.leaseMgr.PublishMultiple(ctx, tableIDsToUpdate, update, func(txn *client.Txn) error {
-		if jobSucceeded {
+		if jobSucceeded && cleanupMutationID == sqlbase.InvalidMutationID {
 			if err := sc.job.WithTxn(txn).Succeeded(ctx, jobs.NoopFn); err != nil {
 				return errors.Wrapf(err,
 					"failed to mark job %d as successful", errors.Safe(*sc.job.ID()))
 			}
-		} else {
+		} else if !jobSucceeded {
 			if err := sc.job.WithTxn(txn).RunningStatus(ctx, func(ctx context.Context, details jobspb.Details) (jobs.RunningStatus, error) {
 				return RunningStatusWaitingGC, nil
 			}); err != nil {
@@ -1401,8 +1470,22 @@ func (sc *SchemaChanger) runStateMachineAndBackfill(
 	}
 
 	// Mark the mutations as completed.
-	_, err := sc.done(ctx)
-	return err
+	desc, err := sc.done(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Run the follow-up mutations queued up by the completed ones, if any.
+	if len(desc.Mutations) > 0 {
+		nextMutationID := desc.Mutations[0].MutationID
+		for _, g := range desc.MutationJobs {
+			if g.MutationID == nextMutationID && g.JobID == *sc.job.ID() {
+				sc.mutationID = nextMutationID
+				return sc.runStateMachineAndBackfill(ctx, lease, evalCtx)
+			}
+		}
+	}
+	return nil
 }
 
 func (sc *SchemaChanger) refreshStats() {
@@ -2144,7 +2227,8 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 
 						// Keep track of outstanding schema changes.
 						pendingChanges := table.Adding() ||
-							table.HasDrainingNames() || len(table.Mutations) > 0
+							table.HasDrainingNames() || len(table.Mutations) > 0 ||
+							table.HasReadOnlyEnumMembers()
 						if pendingChanges {
 							if log.V(2) {
 								log.Infof(ctx, "%s: queue up pending schema change; table: %d, version: %d",
@@ -13,6 +13,7 @@ package builtins
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/cockroachdb/apd"
@@ -203,6 +204,11 @@ type slidingWindowSumFunc struct {
 	// sliding window so far. noNonNullSeen indicates non-null values are yet to
 	// be seen.
 	lastNonNullIdx int
+
+	// nans, posInfs, and negInfs are the numbers of NaNs and infinities in the
+	// window frame. They are kept out of the running sum, since they couldn't
+	// be subtracted from it once they leave the frame.
+	nans, posInfs, negInfs int
 }
 
 const noNonNullSeen = -1
@@ -214,6 +220,56 @@ func newSlidingWindowSumFunc(agg tree.AggregateFunc) *slidingWindowSumFunc {
 	}
 }
 
+// updateNonFinite adds delta to the count of the kind of value if it is a NaN
+// or an infinity, and returns whether it did so.
+func (w *slidingWindowSumFunc) updateNonFinite(value tree.Datum, delta int) bool {
+	var nan, inf, negative bool
+	switch v := value.(type) {
+	case *tree.DFloat:
+		f := float64(*v)
+		nan, inf, negative = math.IsNaN(f), math.IsInf(f, 0), f < 0
+	case *tree.DDecimal:
+		nan = v.Form == apd.NaN || v.Form == apd.NaNSignaling
+		inf, negative = v.Form == apd.Infinite, v.Negative
+	}
+	switch {
+	case nan:
+		w.nans += delta
+	case inf && negative:
+		w.negInfs += delta
+	case inf:
+		w.posInfs += delta
+	default:
+		return false
+	}
+	return true
+}
+
+// nonFiniteSum returns the sum of the window frame if it has NaNs or
+// infinities, and nil otherwise.
+func (w *slidingWindowSumFunc) nonFiniteSum() tree.Datum {
+	var d apd.Decimal
+	switch {
+	case w.nans > 0 || (w.posInfs > 0 && w.negInfs > 0):
+		d.Form = apd.NaN
+	case w.posInfs > 0 || w.negInfs > 0:
+		d.Form, d.Negative = apd.Infinite, w.negInfs > 0
+	default:
+		return nil
+	}
+	if _, ok := w.agg.(*floatSumAggregate); ok {
+		switch {
+		case d.Form == apd.NaN:
+			return tree.NewDFloat(tree.DFloat(math.NaN()))
+		case d.Negative:
+			return tree.NewDFloat(tree.DFloat(math.Inf(-1)))
+		default:
+			return tree.NewDFloat(tree.DFloat(math.Inf(1)))
+		}
+	}
+	return &tree.DDecimal{Decimal: d}
+}
+
 // removeAllBefore subtracts the values from all the rows that are no longer in
 // the frame.
 func (w *slidingWindowSumFunc) removeAllBefore(
@@ -239,6 +295,9 @@ func (w *slidingWindowSumFunc) removeAllBefore(
 			// to subtract once they leave the window frame.
 			continue
 		}
+		if w.updateNonFinite(value, -1) {
+			continue
+		}
 		switch v := value.(type) {
 		case *tree.DInt:
 			err = w.agg.Add(ctx, tree.NewDInt(-*v))
@@ -314,6 +373,9 @@ func (w *slidingWindowSumFunc) Compute(
 		}
 		if args[0] != tree.DNull {
 			w.lastNonNullIdx = idx
+			if w.updateNonFinite(args[0], 1) {
+				continue
+			}
 			err = w.agg.Add(ctx, args[0])
 			if err != nil {
 				return nil, err
@@ -332,6 +394,9 @@ func (w *slidingWindowSumFunc) Compute(
 		// so we return NULL as per spec.
 		return tree.DNull, nil
 	}
+	if sum := w.nonFiniteSum(); sum != nil {
+		return sum, nil
+	}
 	return w.agg.Result()
 }
 
@@ -340,6 +405,7 @@ func (w *slidingWindowSumFunc) Reset(ctx context.Context) {
 	w.prevStart = 0
 	w.prevEnd = 0
 	w.lastNonNullIdx = noNonNullSeen
+	w.nans, w.posInfs, w.negInfs = 0, 0, 0
 	w.agg.Reset(ctx)
 }
 
@@ -53,6 +53,7 @@ type AlterTableCmd interface {
 func (*AlterTableAddColumn) alterTableCmd()          {}
 func (*AlterTableAddConstraint) alterTableCmd()      {}
 func (*AlterTableAlterColumnType) alterTableCmd()    {}
+func (*AlterTableAlterPrimaryKey) alterTableCmd()    {}
 func (*AlterTableDropColumn) alterTableCmd()         {}
 func (*AlterTableDropConstraint) alterTableCmd()     {}
 func (*AlterTableDropNotNull) alterTableCmd()        {}
@@ -70,6 +71,7 @@ func (*AlterTableInjectStats) alterTableCmd()        {}
 var _ AlterTableCmd = &AlterTableAddColumn{}
 var _ AlterTableCmd = &AlterTableAddConstraint{}
 var _ AlterTableCmd = &AlterTableAlterColumnType{}
+var _ AlterTableCmd = &AlterTableAlterPrimaryKey{}
 var _ AlterTableCmd = &AlterTableDropColumn{}
 var _ AlterTableCmd = &AlterTableDropConstraint{}
 var _ AlterTableCmd = &AlterTableDropNotNull{}
@@ -224,6 +226,18 @@ func (node *AlterTableDropColumn) Format(ctx *FmtCtx) {
 	}
 }
 
+// AlterTableAlterPrimaryKey represents an ALTER PRIMARY KEY command.
+type AlterTableAlterPrimaryKey struct {
+	Columns IndexElemList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableAlterPrimaryKey) Format(ctx *FmtCtx) {
+	ctx.WriteString(" ALTER PRIMARY KEY USING COLUMNS (")
+	ctx.FormatNode(&node.Columns)
+	ctx.WriteString(")")
+}
+
 // AlterTableDropConstraint represents a DROP CONSTRAINT command.
 type AlterTableDropConstraint struct {
 	IfExists     bool
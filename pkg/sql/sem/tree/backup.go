@@ -41,6 +41,53 @@ func (node *Backup) Format(ctx *FmtCtx) {
 	}
 }
 
+// FullBackupClause describes how often a scheduled backup takes a full backup
+// rather than an incremental one.
+type FullBackupClause struct {
+	AlwaysFull bool
+	Recurrence Expr
+}
+
+// ScheduledBackup represents a CREATE SCHEDULE FOR BACKUP statement.
+type ScheduledBackup struct {
+	ScheduleName  Expr
+	Targets       TargetList
+	To            Expr
+	BackupOptions KVOptions
+	Recurrence    Expr
+	// FullBackup is nil if the schedule only takes full backups.
+	FullBackup *FullBackupClause
+}
+
+var _ Statement = &ScheduledBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *ScheduledBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE SCHEDULE ")
+	if node.ScheduleName != nil {
+		ctx.FormatNode(node.ScheduleName)
+		ctx.WriteString(" ")
+	}
+	ctx.WriteString("FOR BACKUP ")
+	ctx.FormatNode(&node.Targets)
+	ctx.WriteString(" TO ")
+	ctx.FormatNode(node.To)
+	if node.BackupOptions != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.BackupOptions)
+	}
+	ctx.WriteString(" RECURRING ")
+	ctx.FormatNode(node.Recurrence)
+	if node.FullBackup != nil {
+		ctx.WriteString(" FULL BACKUP ")
+		if node.FullBackup.AlwaysFull {
+			ctx.WriteString("ALWAYS")
+		} else {
+			ctx.FormatNode(node.FullBackup.Recurrence)
+		}
+	}
+}
+
 // Restore represents a RESTORE statement.
 type Restore struct {
 	Targets TargetList
@@ -15,12 +15,30 @@ type CreateChangefeed struct {
 	Targets TargetList
 	SinkURI Expr
 	Options KVOptions
+	// Select, if non-nil, is the projection and filter of a changefeed created
+	// with CREATE CHANGEFEED ... AS SELECT. Its FROM clause is the single table
+	// in Targets.
+	Select *SelectClause
 }
 
 var _ Statement = &CreateChangefeed{}
 
 // Format implements the NodeFormatter interface.
 func (node *CreateChangefeed) Format(ctx *FmtCtx) {
+	if node.Select != nil {
+		ctx.WriteString("CREATE CHANGEFEED")
+		if node.SinkURI != nil {
+			ctx.WriteString(" INTO ")
+			ctx.FormatNode(node.SinkURI)
+		}
+		if node.Options != nil {
+			ctx.WriteString(" WITH ")
+			ctx.FormatNode(&node.Options)
+		}
+		ctx.WriteString(" AS ")
+		ctx.FormatNode(node.Select)
+		return
+	}
 	if node.SinkURI != nil {
 		ctx.WriteString("CREATE ")
 	} else {
@@ -107,6 +107,9 @@ type CreateIndex struct {
 	Storing     NameList
 	Interleave  *InterleaveDef
 	PartitionBy *PartitionBy
+	// Predicate restricts the index to the rows which satisfy it. It is nil
+	// unless the index is partial.
+	Predicate Expr
 }
 
 // Format implements the NodeFormatter interface.
@@ -142,6 +145,10 @@ func (node *CreateIndex) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
 }
 
 // TableDef represents a column, index or constraint definition within a CREATE
@@ -535,6 +542,9 @@ type IndexTableDef struct {
 	Interleave  *InterleaveDef
 	Inverted    bool
 	PartitionBy *PartitionBy
+	// Predicate restricts the index to the rows which satisfy it. It is nil
+	// unless the index is partial.
+	Predicate Expr
 }
 
 // SetName implements the TableDef interface.
@@ -566,6 +576,10 @@ func (node *IndexTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
 }
 
 // ConstraintTableDef represents a constraint definition within a CREATE TABLE
@@ -614,6 +628,10 @@ func (node *UniqueConstraintTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
 }
 
 // ReferenceAction is the method used to maintain referential integrity through
@@ -891,6 +909,7 @@ func (node *RangePartition) Format(ctx *FmtCtx) {
 type CreateTable struct {
 	IfNotExists bool
 	Table       TableName
+	Temporary   bool
 	Interleave  *InterleaveDef
 	PartitionBy *PartitionBy
 	// In CREATE...AS queries, Defs represents a list of ColumnTableDefs, one for
@@ -923,7 +942,11 @@ func (node *CreateTable) AsHasUserSpecifiedPrimaryKey() bool {
 
 // Format implements the NodeFormatter interface.
 func (node *CreateTable) Format(ctx *FmtCtx) {
-	ctx.WriteString("CREATE TABLE ")
+	ctx.WriteString("CREATE ")
+	if node.Temporary {
+		ctx.WriteString("TEMPORARY ")
+	}
+	ctx.WriteString("TABLE ")
 	if node.IfNotExists {
 		ctx.WriteString("IF NOT EXISTS ")
 	}
@@ -1031,6 +1054,28 @@ func (node *CreateSequence) Format(ctx *FmtCtx) {
 	ctx.FormatNode(&node.Options)
 }
 
+// CreateType represents a CREATE TYPE statement. Only ENUM types are
+// currently supported.
+type CreateType struct {
+	TypeName TableName
+	// EnumLabels are the members of the ENUM type, in order.
+	EnumLabels []string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateType) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE TYPE ")
+	ctx.FormatNode(&node.TypeName)
+	ctx.WriteString(" AS ENUM (")
+	for i, label := range node.EnumLabels {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		lex.EncodeSQLString(&ctx.Buffer, label)
+	}
+	ctx.WriteString(")")
+}
+
 // SequenceOptions represents a list of sequence options.
 type SequenceOptions []SequenceOption
 
@@ -1175,11 +1220,16 @@ type CreateView struct {
 	Name        TableName
 	ColumnNames NameList
 	AsSource    *Select
+	Temporary   bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *CreateView) Format(ctx *FmtCtx) {
-	ctx.WriteString("CREATE VIEW ")
+	ctx.WriteString("CREATE ")
+	if node.Temporary {
+		ctx.WriteString("TEMPORARY ")
+	}
+	ctx.WriteString("VIEW ")
 	ctx.FormatNode(&node.Name)
 
 	if len(node.ColumnNames) > 0 {
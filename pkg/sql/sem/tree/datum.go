@@ -1485,6 +1485,175 @@ func (d *DUuid) Size() uintptr {
 	return unsafe.Sizeof(*d)
 }
 
+// DEnum is the Datum for a value of a user-defined ENUM type. The value is
+// stored both in its physical form, which determines its sort order and is
+// used for encoding, and in its logical form, which is what users see.
+type DEnum struct {
+	// EnumTyp is the ENUM type this value belongs to.
+	EnumTyp *types.T
+	// PhysicalRep is the encoded form of the value.
+	PhysicalRep []byte
+	// LogicalRep is the label of the value.
+	LogicalRep string
+}
+
+// MakeDEnumFromPhysicalRepresentation creates a DEnum of the given type from
+// the encoded form of one of its members.
+func MakeDEnumFromPhysicalRepresentation(typ *types.T, rep []byte) (*DEnum, error) {
+	idx, err := enumMemberIndexFromPhysicalRep(typ, rep)
+	if err != nil {
+		return nil, err
+	}
+	return &DEnum{
+		EnumTyp:     typ,
+		PhysicalRep: typ.EnumPhysicalRepresentations()[idx],
+		LogicalRep:  typ.EnumLogicalRepresentations()[idx],
+	}, nil
+}
+
+// MakeDEnumFromLogicalRepresentation creates a DEnum of the given type from
+// the label of one of its members. Members that are still being added to the
+// type cannot be written yet, so an error is returned for them.
+func MakeDEnumFromLogicalRepresentation(typ *types.T, rep string) (*DEnum, error) {
+	idx, err := enumMemberIndexFromLogicalRep(typ, rep)
+	if err != nil {
+		return nil, err
+	}
+	if typ.EnumMemberIsReadOnly(idx) {
+		return nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"enum value %q is not yet public", rep)
+	}
+	return &DEnum{
+		EnumTyp:     typ,
+		PhysicalRep: typ.EnumPhysicalRepresentations()[idx],
+		LogicalRep:  typ.EnumLogicalRepresentations()[idx],
+	}, nil
+}
+
+func enumMemberIndexFromPhysicalRep(typ *types.T, rep []byte) (int, error) {
+	reps := typ.EnumPhysicalRepresentations()
+	for i := range reps {
+		if bytes.Equal(reps[i], rep) {
+			return i, nil
+		}
+	}
+	return 0, errors.AssertionFailedf(
+		"could not find encoded enum value %x in type %s", rep, typ.TypeName())
+}
+
+func enumMemberIndexFromLogicalRep(typ *types.T, rep string) (int, error) {
+	reps := typ.EnumLogicalRepresentations()
+	for i := range reps {
+		if reps[i] == rep {
+			return i, nil
+		}
+	}
+	return 0, pgerror.Newf(pgcode.InvalidTextRepresentation,
+		"invalid input value for enum %s: %q", typ.TypeName(), rep)
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (d *DEnum) ResolvedType() *types.T {
+	return d.EnumTyp
+}
+
+// Compare implements the Datum interface.
+func (d *DEnum) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := UnwrapDatum(ctx, other).(*DEnum)
+	if !ok || d.EnumTyp.Oid() != v.EnumTyp.Oid() {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	return bytes.Compare(d.PhysicalRep, v.PhysicalRep)
+}
+
+// Prev implements the Datum interface.
+func (d *DEnum) Prev(_ *EvalContext) (Datum, bool) {
+	idx, err := enumMemberIndexFromPhysicalRep(d.EnumTyp, d.PhysicalRep)
+	if err != nil || idx == 0 {
+		return nil, false
+	}
+	res, err := MakeDEnumFromPhysicalRepresentation(
+		d.EnumTyp, d.EnumTyp.EnumPhysicalRepresentations()[idx-1])
+	if err != nil {
+		return nil, false
+	}
+	return res, true
+}
+
+// Next implements the Datum interface.
+func (d *DEnum) Next(_ *EvalContext) (Datum, bool) {
+	idx, err := enumMemberIndexFromPhysicalRep(d.EnumTyp, d.PhysicalRep)
+	if err != nil || idx == len(d.EnumTyp.EnumPhysicalRepresentations())-1 {
+		return nil, false
+	}
+	res, err := MakeDEnumFromPhysicalRepresentation(
+		d.EnumTyp, d.EnumTyp.EnumPhysicalRepresentations()[idx+1])
+	if err != nil {
+		return nil, false
+	}
+	return res, true
+}
+
+// IsMax implements the Datum interface.
+func (d *DEnum) IsMax(_ *EvalContext) bool {
+	reps := d.EnumTyp.EnumPhysicalRepresentations()
+	return len(reps) > 0 && bytes.Equal(d.PhysicalRep, reps[len(reps)-1])
+}
+
+// IsMin implements the Datum interface.
+func (d *DEnum) IsMin(_ *EvalContext) bool {
+	reps := d.EnumTyp.EnumPhysicalRepresentations()
+	return len(reps) > 0 && bytes.Equal(d.PhysicalRep, reps[0])
+}
+
+// Min implements the Datum interface.
+func (d *DEnum) Min(_ *EvalContext) (Datum, bool) {
+	reps := d.EnumTyp.EnumPhysicalRepresentations()
+	if len(reps) == 0 {
+		return nil, false
+	}
+	res, err := MakeDEnumFromPhysicalRepresentation(d.EnumTyp, reps[0])
+	if err != nil {
+		return nil, false
+	}
+	return res, true
+}
+
+// Max implements the Datum interface.
+func (d *DEnum) Max(_ *EvalContext) (Datum, bool) {
+	reps := d.EnumTyp.EnumPhysicalRepresentations()
+	if len(reps) == 0 {
+		return nil, false
+	}
+	res, err := MakeDEnumFromPhysicalRepresentation(d.EnumTyp, reps[len(reps)-1])
+	if err != nil {
+		return nil, false
+	}
+	return res, true
+}
+
+// AmbiguousFormat implements the Datum interface. Enum values are formatted
+// as plain string literals, without a type annotation, so that serialized
+// expressions stored in descriptors (DEFAULT, computed columns, CHECK
+// constraints) can be re-type-checked against the column type without having
+// to resolve the type by name.
+func (*DEnum) AmbiguousFormat() bool { return false }
+
+// Format implements the NodeFormatter interface.
+func (d *DEnum) Format(ctx *FmtCtx) {
+	s := DString(d.LogicalRep)
+	s.Format(ctx)
+}
+
+// Size implements the Datum interface.
+func (d *DEnum) Size() uintptr {
+	return unsafe.Sizeof(*d) + uintptr(len(d.PhysicalRep)) + uintptr(len(d.LogicalRep))
+}
+
 // DIPAddr is the IPAddr Datum.
 type DIPAddr struct {
 	ipaddr.IPAddr
@@ -3669,6 +3838,7 @@ var baseDatumTypeSizes = map[types.Family]struct {
 	types.IntervalFamily:       {unsafe.Sizeof(DInterval{}), fixedSize},
 	types.JsonFamily:           {unsafe.Sizeof(DJSON{}), variableSize},
 	types.UuidFamily:           {unsafe.Sizeof(DUuid{}), fixedSize},
+	types.EnumFamily:           {unsafe.Sizeof(DEnum{}), variableSize},
 	types.INetFamily:           {unsafe.Sizeof(DIPAddr{}), fixedSize},
 	types.OidFamily:            {unsafe.Sizeof(DInt(0)), fixedSize},
 
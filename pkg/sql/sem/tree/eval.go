@@ -1745,6 +1745,7 @@ var CmpOps = cmpOpFixups(map[ComparisonOperator]cmpOpOverload{
 		makeEqFn(types.Date, types.Date),
 		makeEqFn(types.Decimal, types.Decimal),
 		makeEqFn(types.AnyCollatedString, types.AnyCollatedString),
+		makeEqFn(types.AnyEnum, types.AnyEnum),
 		makeEqFn(types.Float, types.Float),
 		makeEqFn(types.INet, types.INet),
 		makeEqFn(types.Int, types.Int),
@@ -1789,6 +1790,7 @@ var CmpOps = cmpOpFixups(map[ComparisonOperator]cmpOpOverload{
 		makeLtFn(types.Date, types.Date),
 		makeLtFn(types.Decimal, types.Decimal),
 		makeLtFn(types.AnyCollatedString, types.AnyCollatedString),
+		makeLtFn(types.AnyEnum, types.AnyEnum),
 		makeLtFn(types.Float, types.Float),
 		makeLtFn(types.INet, types.INet),
 		makeLtFn(types.Int, types.Int),
@@ -1832,6 +1834,7 @@ var CmpOps = cmpOpFixups(map[ComparisonOperator]cmpOpOverload{
 		makeLeFn(types.Date, types.Date),
 		makeLeFn(types.Decimal, types.Decimal),
 		makeLeFn(types.AnyCollatedString, types.AnyCollatedString),
+		makeLeFn(types.AnyEnum, types.AnyEnum),
 		makeLeFn(types.Float, types.Float),
 		makeLeFn(types.INet, types.INet),
 		makeLeFn(types.Int, types.Int),
@@ -1883,6 +1886,7 @@ var CmpOps = cmpOpFixups(map[ComparisonOperator]cmpOpOverload{
 		makeIsFn(types.Date, types.Date),
 		makeIsFn(types.Decimal, types.Decimal),
 		makeIsFn(types.AnyCollatedString, types.AnyCollatedString),
+		makeIsFn(types.AnyEnum, types.AnyEnum),
 		makeIsFn(types.Float, types.Float),
 		makeIsFn(types.INet, types.INet),
 		makeIsFn(types.Int, types.Int),
@@ -1930,6 +1934,7 @@ var CmpOps = cmpOpFixups(map[ComparisonOperator]cmpOpOverload{
 		makeEvalTupleIn(types.Date),
 		makeEvalTupleIn(types.Decimal),
 		makeEvalTupleIn(types.AnyCollatedString),
+		makeEvalTupleIn(types.AnyEnum),
 		makeEvalTupleIn(types.AnyTuple),
 		makeEvalTupleIn(types.Float),
 		makeEvalTupleIn(types.INet),
@@ -3269,6 +3274,8 @@ func PerformCast(ctx *EvalContext, d Datum, t *types.T) (Datum, error) {
 			s = t.name
 		case *DJSON:
 			s = t.JSON.String()
+		case *DEnum:
+			s = t.LogicalRep
 		}
 		switch t.Family() {
 		case types.StringFamily:
@@ -3315,6 +3322,18 @@ func PerformCast(ctx *EvalContext, d Datum, t *types.T) (Datum, error) {
 			return d, nil
 		}
 
+	case types.EnumFamily:
+		switch v := d.(type) {
+		case *DString:
+			return MakeDEnumFromLogicalRepresentation(t, string(*v))
+		case *DCollatedString:
+			return MakeDEnumFromLogicalRepresentation(t, v.Contents)
+		case *DEnum:
+			if v.EnumTyp.Oid() == t.Oid() {
+				return d, nil
+			}
+		}
+
 	case types.INetFamily:
 		switch t := d.(type) {
 		case *DString:
@@ -4054,6 +4073,11 @@ func (t *DUuid) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
 }
 
+// Eval implements the TypedExpr interface.
+func (t *DEnum) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
 // Eval implements the TypedExpr interface.
 func (t *DIPAddr) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
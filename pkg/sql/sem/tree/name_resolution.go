@@ -366,6 +366,40 @@ func (t *TableName) ResolveTarget(
 	return found, scMeta, err
 }
 
+// QualifyTemporaryTarget qualifies the name of an object that is about to be
+// created with the session's temporary schema, before the name undergoes
+// ResolveTarget, if the object is to be temporary. An object is temporary if
+// it is explicitly declared so, or if its name explicitly refers to the
+// pg_temp alias or to the temporary schema itself. It returns whether the
+// object is temporary.
+func (t *TableName) QualifyTemporaryTarget(
+	temporary bool, tempSchemaName string,
+) (isTemporary bool, err error) {
+	if t.ExplicitSchema {
+		switch t.Schema() {
+		case sessiondata.PgTempSchemaName:
+			temporary = true
+		case tempSchemaName:
+			temporary = tempSchemaName != ""
+		default:
+			if temporary {
+				return false, pgerror.New(pgcode.InvalidTableDefinition,
+					"cannot create temporary relation in non-temporary schema")
+			}
+		}
+	}
+	if !temporary {
+		return false, nil
+	}
+	if tempSchemaName == "" {
+		return false, pgerror.New(pgcode.FeatureNotSupported,
+			"temporary objects cannot be created in this session")
+	}
+	t.SchemaName = Name(tempSchemaName)
+	t.ExplicitSchema = true
+	return true, nil
+}
+
 // Resolve is used for table prefixes. This is adequate for table
 // patterns with stars, e.g. AllTablesSelector.
 func (tp *TableNamePrefix) Resolve(
@@ -777,6 +777,11 @@ func checkReturn(
 		p := o.params()
 		for _, i := range s.constIdxs {
 			des := p.GetAt(i)
+			if des != nil && des.Family() == types.EnumFamily && des.IsAmbiguous() {
+				// A constant can only become a value of a particular ENUM type, so
+				// take the type from the other arguments if there is one.
+				des = resolvedEnumType(s, des)
+			}
 			typ, err := s.exprs[i].TypeCheck(ctx, des)
 			if err != nil {
 				return false, s.typedExprs, nil, pgerror.Wrapf(
@@ -811,6 +816,17 @@ func checkReturn(
 	}
 }
 
+// resolvedEnumType returns the type of the first resolved argument that is a
+// value of a user-defined ENUM type, or def if there is no such argument.
+func resolvedEnumType(s *typeCheckOverloadState, def *types.T) *types.T {
+	for _, i := range s.resolvableIdxs {
+		if typ := s.typedExprs[i].ResolvedType(); typ.Family() == types.EnumFamily && !typ.IsAmbiguous() {
+			return typ
+		}
+	}
+	return def
+}
+
 func formatCandidates(prefix string, candidates []overloadImpl) string {
 	var buf bytes.Buffer
 	for _, candidate := range candidates {
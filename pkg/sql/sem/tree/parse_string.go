@@ -13,6 +13,8 @@ package tree
 import (
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/errors"
 )
@@ -68,6 +70,12 @@ func parseStringAs(t *types.T, s string, ctx ParseTimeContext) (Datum, error) {
 		return ParseDDate(ctx, s)
 	case types.DecimalFamily:
 		return ParseDDecimal(s)
+	case types.EnumFamily:
+		if t.IsAmbiguous() {
+			return nil, pgerror.Newf(pgcode.IndeterminateDatatype,
+				"could not parse %q as a value of an unspecified ENUM type", s)
+		}
+		return MakeDEnumFromLogicalRepresentation(t, s)
 	case types.FloatFamily:
 		return ParseDFloat(s)
 	case types.INetFamily:
@@ -507,6 +507,55 @@ func (node *Limit) docTable(p *PrettyCfg) []pretty.TableRow {
 	return res
 }
 
+func (node LockingClause) docTable(p *PrettyCfg) []pretty.TableRow {
+	items := make([]pretty.TableRow, len(node))
+	for i, n := range node {
+		items[i] = p.row("", p.Doc(n))
+	}
+	return items
+}
+
+func (node *LockingItem) doc(p *PrettyCfg) pretty.Doc {
+	return p.rlTable(node.docTable(p)...)
+}
+
+func (node *LockingItem) docTable(p *PrettyCfg) []pretty.TableRow {
+	if node.Strength == ForNone {
+		return nil
+	}
+	items := make([]pretty.TableRow, 0, 3)
+	items = append(items, node.Strength.docTable(p)...)
+	if len(node.Targets) > 0 {
+		items = append(items, p.row("OF", p.Doc(&node.Targets)))
+	}
+	items = append(items, node.WaitPolicy.docTable(p)...)
+	return items
+}
+
+func (node LockingStrength) doc(p *PrettyCfg) pretty.Doc {
+	return p.rlTable(node.docTable(p)...)
+}
+
+func (node LockingStrength) docTable(p *PrettyCfg) []pretty.TableRow {
+	str := node.String()
+	if str == "" {
+		return nil
+	}
+	return []pretty.TableRow{p.row("", pretty.Keyword(str))}
+}
+
+func (node LockingWaitPolicy) doc(p *PrettyCfg) pretty.Doc {
+	return p.rlTable(node.docTable(p)...)
+}
+
+func (node LockingWaitPolicy) docTable(p *PrettyCfg) []pretty.TableRow {
+	str := node.String()
+	if str == "" {
+		return nil
+	}
+	return []pretty.TableRow{p.row("", pretty.Keyword(str))}
+}
+
 func (node *OrderBy) doc(p *PrettyCfg) pretty.Doc {
 	return p.unrow(node.docRow(p))
 }
@@ -538,6 +587,7 @@ func (node *Select) docTable(p *PrettyCfg) []pretty.TableRow {
 	}
 	items = append(items, node.OrderBy.docRow(p))
 	items = append(items, node.Limit.docTable(p)...)
+	items = append(items, node.Locking.docTable(p)...)
 	return items
 }
 
@@ -1106,12 +1156,16 @@ func (node *UpdateExpr) doc(p *PrettyCfg) pretty.Doc {
 func (node *CreateTable) doc(p *PrettyCfg) pretty.Doc {
 	// Final layout:
 	//
-	// CREATE TABLE [IF NOT EXISTS] name ( .... ) [AS]
+	// CREATE [TEMPORARY] TABLE [IF NOT EXISTS] name ( .... ) [AS]
 	//     [SELECT ...] - for CREATE TABLE AS
 	//     [INTERLEAVE ...]
 	//     [PARTITION BY ...]
 	//
-	title := pretty.Keyword("CREATE TABLE")
+	title := pretty.Keyword("CREATE")
+	if node.Temporary {
+		title = pretty.ConcatSpace(title, pretty.Keyword("TEMPORARY"))
+	}
+	title = pretty.ConcatSpace(title, pretty.Keyword("TABLE"))
 	if node.IfNotExists {
 		title = pretty.ConcatSpace(title, pretty.Keyword("IF NOT EXISTS"))
 	}
@@ -1148,13 +1202,15 @@ func (node *CreateTable) doc(p *PrettyCfg) pretty.Doc {
 func (node *CreateView) doc(p *PrettyCfg) pretty.Doc {
 	// Final layout:
 	//
-	// CREATE VIEW name ( ... ) AS
+	// CREATE [TEMPORARY] VIEW name ( ... ) AS
 	//     SELECT ...
 	//
-	d := pretty.ConcatSpace(
-		pretty.Keyword("CREATE VIEW"),
-		p.Doc(&node.Name),
-	)
+	d := pretty.Keyword("CREATE")
+	if node.Temporary {
+		d = pretty.ConcatSpace(d, pretty.Keyword("TEMPORARY"))
+	}
+	d = pretty.ConcatSpace(d, pretty.Keyword("VIEW"))
+	d = pretty.ConcatSpace(d, p.Doc(&node.Name))
 	if len(node.ColumnNames) > 0 {
 		d = pretty.ConcatSpace(
 			d,
@@ -1389,6 +1445,7 @@ func (node *CreateIndex) doc(p *PrettyCfg) pretty.Doc {
 	//    [STORING ( ... )]
 	//    [INTERLEAVE ...]
 	//    [PARTITION BY ...]
+	//    [WHERE ...]
 	//
 	title := make([]pretty.Doc, 0, 6)
 	title = append(title, pretty.Keyword("CREATE"))
@@ -1425,6 +1482,9 @@ func (node *CreateIndex) doc(p *PrettyCfg) pretty.Doc {
 	if node.PartitionBy != nil {
 		clauses = append(clauses, p.Doc(node.PartitionBy))
 	}
+	if node.Predicate != nil {
+		clauses = append(clauses, p.nestUnder(pretty.Keyword("WHERE"), p.Doc(node.Predicate)))
+	}
 	return p.nestUnder(
 		pretty.Fold(pretty.ConcatSpace, title...),
 		pretty.Group(pretty.Stack(clauses...)))
@@ -1466,6 +1526,7 @@ func (node *IndexTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    [STORING ( ... )]
 	//    [INTERLEAVE ...]
 	//    [PARTITION BY ...]
+	//    [WHERE ...]
 	//
 	title := pretty.Keyword("INDEX")
 	if node.Name != "" {
@@ -1489,6 +1550,9 @@ func (node *IndexTableDef) doc(p *PrettyCfg) pretty.Doc {
 	if node.PartitionBy != nil {
 		clauses = append(clauses, p.Doc(node.PartitionBy))
 	}
+	if node.Predicate != nil {
+		clauses = append(clauses, p.nestUnder(pretty.Keyword("WHERE"), p.Doc(node.Predicate)))
+	}
 
 	if len(clauses) == 0 {
 		return title
@@ -1503,6 +1567,7 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    [STORING ( ... )]
 	//    [INTERLEAVE ...]
 	//    [PARTITION BY ...]
+	//    [WHERE ...]
 	//
 	// or (no constraint name):
 	//
@@ -1510,6 +1575,7 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	//    [STORING ( ... )]
 	//    [INTERLEAVE ...]
 	//    [PARTITION BY ...]
+	//    [WHERE ...]
 	//
 	clauses := make([]pretty.Doc, 0, 4)
 	var title pretty.Doc
@@ -1535,6 +1601,9 @@ func (node *UniqueConstraintTableDef) doc(p *PrettyCfg) pretty.Doc {
 	if node.PartitionBy != nil {
 		clauses = append(clauses, p.Doc(node.PartitionBy))
 	}
+	if node.Predicate != nil {
+		clauses = append(clauses, p.nestUnder(pretty.Keyword("WHERE"), p.Doc(node.Predicate)))
+	}
 
 	if len(clauses) == 0 {
 		return title
@@ -75,9 +75,14 @@ const (
 
 // ShowBackup represents a SHOW BACKUP statement.
 type ShowBackup struct {
-	Path                 Expr
+	Path Expr
+	// IncrementalFrom lists the backups that the backup at Path is
+	// incremental on, when verifying a backup chain. They are listed before
+	// Path, in the same order as they would be given to RESTORE.
+	IncrementalFrom      Exprs
 	Details              BackupDetails
 	ShouldIncludeSchemas bool
+	Options              KVOptions
 }
 
 // Format implements the NodeFormatter interface.
@@ -91,7 +96,15 @@ func (node *ShowBackup) Format(ctx *FmtCtx) {
 	if node.ShouldIncludeSchemas {
 		ctx.WriteString("SCHEMAS ")
 	}
+	if node.IncrementalFrom != nil {
+		ctx.FormatNode(&node.IncrementalFrom)
+		ctx.WriteString(", ")
+	}
 	ctx.FormatNode(node.Path)
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
 }
 
 // ShowColumns represents a SHOW COLUMNS statement.
@@ -221,6 +234,14 @@ func (node *ShowJobs) Format(ctx *FmtCtx) {
 	}
 }
 
+// ShowSchedules represents a SHOW SCHEDULES statement.
+type ShowSchedules struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowSchedules) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW SCHEDULES")
+}
+
 // ShowSessions represents a SHOW SESSIONS statement
 type ShowSessions struct {
 	All     bool
@@ -172,6 +172,7 @@ var _ CCLOnlyStatement = &RevokeRole{}
 var _ CCLOnlyStatement = &CreateChangefeed{}
 var _ CCLOnlyStatement = &Import{}
 var _ CCLOnlyStatement = &Export{}
+var _ CCLOnlyStatement = &ScheduledBackup{}
 
 // StatementType implements the Statement interface.
 func (*AlterIndex) StatementType() StatementType { return DDL }
@@ -195,6 +196,12 @@ func (*AlterSequence) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*AlterSequence) StatementTag() string { return "ALTER SEQUENCE" }
 
+// StatementType implements the Statement interface.
+func (*AlterType) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterType) StatementTag() string { return "ALTER TYPE" }
+
 // StatementType implements the Statement interface.
 func (*AlterUserSetPassword) StatementType() StatementType { return RowsAffected }
 
@@ -227,6 +234,14 @@ func (n *ControlJobs) StatementTag() string {
 	return fmt.Sprintf("%s JOBS", JobCommandToStatement[n.Command])
 }
 
+// StatementType implements the Statement interface.
+func (*ControlSchedules) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (n *ControlSchedules) StatementTag() string {
+	return fmt.Sprintf("%s SCHEDULES", ScheduleCommandToStatement[n.Command])
+}
+
 // StatementType implements the Statement interface.
 func (*CancelQueries) StatementType() StatementType { return RowsAffected }
 
@@ -344,6 +359,12 @@ func (*CreateSequence) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*CreateSequence) StatementTag() string { return "CREATE SEQUENCE" }
 
+// StatementType implements the Statement interface.
+func (*CreateType) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateType) StatementTag() string { return "CREATE TYPE" }
+
 // StatementType implements the Statement interface.
 func (*CreateStats) StatementType() StatementType { return DDL }
 
@@ -404,6 +425,12 @@ func (*DropSequence) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*DropSequence) StatementTag() string { return "DROP SEQUENCE" }
 
+// StatementType implements the Statement interface.
+func (*DropType) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropType) StatementTag() string { return "DROP TYPE" }
+
 // StatementType implements the Statement interface.
 func (*DropUser) StatementType() StatementType { return RowsAffected }
 
@@ -574,6 +601,16 @@ func (*Scatter) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*Scatter) StatementTag() string { return "SCATTER" }
 
+// StatementType implements the Statement interface.
+func (*ScheduledBackup) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ScheduledBackup) StatementTag() string { return "CREATE SCHEDULE FOR BACKUP" }
+
+func (*ScheduledBackup) cclOnlyStatement() {}
+
+func (*ScheduledBackup) hiddenFromShowQueries() {}
+
 // StatementType implements the Statement interface.
 func (*Scrub) StatementType() StatementType { return Rows }
 
@@ -717,6 +754,12 @@ func (*ShowJobs) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*ShowJobs) StatementTag() string { return "SHOW JOBS" }
 
+// StatementType implements the Statement interface.
+func (*ShowSchedules) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowSchedules) StatementTag() string { return "SHOW SCHEDULES" }
+
 // StatementType implements the Statement interface.
 func (*ShowRoleGrants) StatementType() StatementType { return Rows }
 
@@ -856,6 +899,7 @@ func (n *AlterTableCmds) String() string            { return AsString(n) }
 func (n *AlterTableAddColumn) String() string       { return AsString(n) }
 func (n *AlterTableAddConstraint) String() string   { return AsString(n) }
 func (n *AlterTableAlterColumnType) String() string { return AsString(n) }
+func (n *AlterTableAlterPrimaryKey) String() string { return AsString(n) }
 func (n *AlterTableDropColumn) String() string      { return AsString(n) }
 func (n *AlterTableDropConstraint) String() string  { return AsString(n) }
 func (n *AlterTableDropNotNull) String() string     { return AsString(n) }
@@ -864,9 +908,12 @@ func (n *AlterTableSetDefault) String() string      { return AsString(n) }
 func (n *AlterTableSetNotNull) String() string      { return AsString(n) }
 func (n *AlterUserSetPassword) String() string      { return AsString(n) }
 func (n *AlterSequence) String() string             { return AsString(n) }
+func (n *AlterType) String() string                 { return AsString(n) }
+func (n *AlterTypeAddValue) String() string         { return AsString(n) }
 func (n *Backup) String() string                    { return AsString(n) }
 func (n *BeginTransaction) String() string          { return AsString(n) }
 func (n *ControlJobs) String() string               { return AsString(n) }
+func (n *ControlSchedules) String() string          { return AsString(n) }
 func (n *CancelQueries) String() string             { return AsString(n) }
 func (n *CancelSessions) String() string            { return AsString(n) }
 func (n *CannedOptPlan) String() string             { return AsString(n) }
@@ -881,6 +928,7 @@ func (n *CreateIndex) String() string               { return AsString(n) }
 func (n *CreateRole) String() string                { return AsString(n) }
 func (n *CreateTable) String() string               { return AsString(n) }
 func (n *CreateSequence) String() string            { return AsString(n) }
+func (n *CreateType) String() string                { return AsString(n) }
 func (n *CreateStats) String() string               { return AsString(n) }
 func (n *CreateUser) String() string                { return AsString(n) }
 func (n *CreateView) String() string                { return AsString(n) }
@@ -892,6 +940,7 @@ func (n *DropRole) String() string                  { return AsString(n) }
 func (n *DropTable) String() string                 { return AsString(n) }
 func (n *DropView) String() string                  { return AsString(n) }
 func (n *DropSequence) String() string              { return AsString(n) }
+func (n *DropType) String() string                  { return AsString(n) }
 func (n *DropUser) String() string                  { return AsString(n) }
 func (n *Execute) String() string                   { return AsString(n) }
 func (n *Explain) String() string                   { return AsString(n) }
@@ -915,6 +964,7 @@ func (n *RollbackToSavepoint) String() string       { return AsString(n) }
 func (n *RollbackTransaction) String() string       { return AsString(n) }
 func (n *Savepoint) String() string                 { return AsString(n) }
 func (n *Scatter) String() string                   { return AsString(n) }
+func (n *ScheduledBackup) String() string           { return AsString(n) }
 func (n *Scrub) String() string                     { return AsString(n) }
 func (n *Select) String() string                    { return AsString(n) }
 func (n *SelectClause) String() string              { return AsString(n) }
@@ -940,6 +990,7 @@ func (n *ShowJobs) String() string                  { return AsString(n) }
 func (n *ShowQueries) String() string               { return AsString(n) }
 func (n *ShowRanges) String() string                { return AsString(n) }
 func (n *ShowRoleGrants) String() string            { return AsString(n) }
+func (n *ShowSchedules) String() string             { return AsString(n) }
 func (n *ShowRoles) String() string                 { return AsString(n) }
 func (n *ShowSchemas) String() string               { return AsString(n) }
 func (n *ShowSequences) String() string             { return AsString(n) }
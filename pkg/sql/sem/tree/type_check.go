@@ -59,9 +59,33 @@ type SemaContext struct {
 	// globally for the entire txn and this field would not be needed.
 	AsOfTimestamp *hlc.Timestamp
 
+	// TypeResolver is used to resolve references to user-defined types. If it
+	// is nil, user-defined types cannot be used.
+	TypeResolver TypeReferenceResolver
+
 	Properties SemaProperties
 }
 
+// TypeReferenceResolver resolves the names of user-defined types.
+type TypeReferenceResolver interface {
+	// ResolveTypeByName returns the user-defined type with the given name.
+	ResolveTypeByName(name string) (*types.T, error)
+}
+
+// ResolveTypeReference returns the type named by typ if typ is a reference to
+// a user-defined type produced by the parser. Other types are returned
+// unchanged.
+func ResolveTypeReference(ctx *SemaContext, typ *types.T) (*types.T, error) {
+	if !typ.IsUnresolvedReference() {
+		return typ, nil
+	}
+	if ctx == nil || ctx.TypeResolver == nil {
+		return nil, pgerror.Newf(pgcode.UndefinedObject,
+			"type %q does not exist", typ.TypeName())
+	}
+	return ctx.TypeResolver.ResolveTypeByName(typ.TypeName())
+}
+
 // SemaProperties is a holder for required and derived properties
 // during semantic analysis. It provides scoping semantics via its
 // Restore() method, see below.
@@ -428,6 +452,11 @@ func isCastDeepValid(castFrom, castTo *types.T) (bool, telemetry.Counter) {
 		}
 		return ok, c
 	}
+	if castTo.Family() == types.EnumFamily && castFrom.Family() == types.EnumFamily &&
+		!castFrom.Equivalent(castTo) {
+		// Values of one user-defined ENUM type cannot be cast to another.
+		return false, nil
+	}
 	for _, t := range validCastTypes(castTo) {
 		if castFrom.Family() == t.fromT.Family() {
 			return true, t.counter
@@ -443,6 +472,12 @@ func isEmptyArray(expr Expr) bool {
 
 // TypeCheck implements the Expr interface.
 func (expr *CastExpr) TypeCheck(ctx *SemaContext, _ *types.T) (TypedExpr, error) {
+	typ, err := ResolveTypeReference(ctx, expr.Type)
+	if err != nil {
+		return nil, err
+	}
+	expr.Type = typ
+
 	// The desired type provided to a CastExpr is ignored. Instead,
 	// types.Any is passed to the child of the cast. There are two
 	// exceptions, described below.
@@ -530,6 +565,12 @@ func (expr *IndirectionExpr) TypeCheck(ctx *SemaContext, desired *types.T) (Type
 
 // TypeCheck implements the Expr interface.
 func (expr *AnnotateTypeExpr) TypeCheck(ctx *SemaContext, desired *types.T) (TypedExpr, error) {
+	typ, err := ResolveTypeReference(ctx, expr.Type)
+	if err != nil {
+		return nil, err
+	}
+	expr.Type = typ
+
 	subExpr, err := typeCheckAndRequire(ctx, expr.Expr, expr.Type,
 		fmt.Sprintf("type annotation for %v as %s, found", expr.Expr, expr.Type))
 	if err != nil {
@@ -1358,6 +1399,10 @@ func (d *DBytes) TypeCheck(_ *SemaContext, _ *types.T) (TypedExpr, error) { retu
 // identity function for Datum.
 func (d *DUuid) TypeCheck(_ *SemaContext, _ *types.T) (TypedExpr, error) { return d, nil }
 
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DEnum) TypeCheck(_ *SemaContext, _ *types.T) (TypedExpr, error) { return d, nil }
+
 // TypeCheck implements the Expr interface. It is implemented as an idempotent
 // identity function for Datum.
 func (d *DIPAddr) TypeCheck(_ *SemaContext, _ *types.T) (TypedExpr, error) { return d, nil }
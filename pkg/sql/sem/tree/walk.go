@@ -627,6 +627,9 @@ func (expr *DJSON) Walk(_ Visitor) Expr { return expr }
 // Walk implements the Expr interface.
 func (expr *DUuid) Walk(_ Visitor) Expr { return expr }
 
+// Walk implements the Expr interface.
+func (expr *DEnum) Walk(_ Visitor) Expr { return expr }
+
 // Walk implements the Expr interface.
 func (expr *DIPAddr) Walk(_ Visitor) Expr { return expr }
 
@@ -1030,6 +1033,7 @@ func (stmt *Select) copyNode() *Select {
 		lCopy := *stmt.Limit
 		stmtCopy.Limit = &lCopy
 	}
+	stmtCopy.Locking = append(LockingClause(nil), stmt.Locking...)
 	return &stmtCopy
 }
 
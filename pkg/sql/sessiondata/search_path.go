@@ -22,29 +22,52 @@ const DefaultDatabaseName = "defaultdb"
 // PgCatalogName is the name of the pg_catalog system schema.
 const PgCatalogName = "pg_catalog"
 
+// PgTempSchemaName is the alias for the current session's temporary schema.
+const PgTempSchemaName = "pg_temp"
+
 // SearchPath represents a list of namespaces to search builtins in.
 // The names must be normalized (as per Name.Normalize) already.
 type SearchPath struct {
-	paths             []string
-	containsPgCatalog bool
+	paths                []string
+	containsPgCatalog    bool
+	containsPgTempSchema bool
+	tempSchemaName       string
 }
 
 // MakeSearchPath returns a new immutable SearchPath struct. The paths slice
 // must not be modified after hand-off to MakeSearchPath.
 func MakeSearchPath(paths []string) SearchPath {
 	containsPgCatalog := false
+	containsPgTempSchema := false
 	for _, e := range paths {
-		if e == PgCatalogName {
+		switch e {
+		case PgCatalogName:
 			containsPgCatalog = true
-			break
+		case PgTempSchemaName:
+			containsPgTempSchema = true
 		}
 	}
 	return SearchPath{
-		paths:             paths,
-		containsPgCatalog: containsPgCatalog,
+		paths:                paths,
+		containsPgCatalog:    containsPgCatalog,
+		containsPgTempSchema: containsPgTempSchema,
 	}
 }
 
+// WithTemporarySchemaName returns a copy of the SearchPath in which the
+// pg_temp alias refers to the provided temporary schema. An empty name
+// indicates that the session has no temporary schema.
+func (s SearchPath) WithTemporarySchemaName(tempSchemaName string) SearchPath {
+	s.tempSchemaName = tempSchemaName
+	return s
+}
+
+// GetTemporarySchemaName returns the name of the temporary schema that the
+// pg_temp alias refers to, or the empty string if there is none.
+func (s SearchPath) GetTemporarySchemaName() string {
+	return s.tempSchemaName
+}
+
 // Iter returns an iterator through the search path. We must include the
 // implicit pg_catalog at the beginning of the search path, unless it has been
 // explicitly set later by the user.
@@ -53,17 +76,22 @@ func MakeSearchPath(paths []string) SearchPath {
 // searched in the specified order. If pg_catalog is not in the path then it
 // will be searched before searching any of the path items."
 // - https://www.postgresql.org/docs/9.1/static/runtime-config-client.html
+//
+// Similarly, the session's temporary schema is searched first if it exists
+// and is not mentioned in the path through the pg_temp alias.
 func (s SearchPath) Iter() SearchPathIter {
-	if s.containsPgCatalog {
-		return SearchPathIter{paths: s.paths, i: 0}
+	return SearchPathIter{
+		paths:                s.paths,
+		implicitPgCatalog:    !s.containsPgCatalog,
+		implicitPgTempSchema: s.tempSchemaName != "" && !s.containsPgTempSchema,
+		tempSchemaName:       s.tempSchemaName,
 	}
-	return SearchPathIter{paths: s.paths, i: -1}
 }
 
 // IterWithoutImplicitPGCatalog is the same as Iter, but does not include the
-// implicit pg_catalog.
+// implicit pg_catalog nor the implicit temporary schema.
 func (s SearchPath) IterWithoutImplicitPGCatalog() SearchPathIter {
-	return SearchPathIter{paths: s.paths, i: 0}
+	return SearchPathIter{paths: s.paths, tempSchemaName: s.tempSchemaName}
 }
 
 // GetPathArray returns the underlying path array of this SearchPath. The
@@ -77,6 +105,9 @@ func (s SearchPath) Equals(other *SearchPath) bool {
 	if s.containsPgCatalog != other.containsPgCatalog {
 		return false
 	}
+	if s.tempSchemaName != other.tempSchemaName {
+		return false
+	}
 	if len(s.paths) != len(other.paths) {
 		return false
 	}
@@ -100,19 +131,34 @@ func (s SearchPath) String() string {
 // iterator, and then repeatedly call the Next method in order to iterate over
 // each search path.
 type SearchPathIter struct {
-	paths []string
-	i     int
+	paths                []string
+	implicitPgCatalog    bool
+	implicitPgTempSchema bool
+	tempSchemaName       string
+	i                    int
 }
 
 // Next returns the next search path, or false if there are no remaining paths.
+// The pg_temp alias is replaced by the name of the session's temporary schema,
+// and skipped if the session has none.
 func (iter *SearchPathIter) Next() (path string, ok bool) {
-	if iter.i == -1 {
-		iter.i++
+	if iter.implicitPgTempSchema {
+		iter.implicitPgTempSchema = false
+		return iter.tempSchemaName, true
+	}
+	if iter.implicitPgCatalog {
+		iter.implicitPgCatalog = false
 		return PgCatalogName, true
 	}
-	if iter.i < len(iter.paths) {
+	for iter.i < len(iter.paths) {
 		iter.i++
-		return iter.paths[iter.i-1], true
+		path := iter.paths[iter.i-1]
+		if path != PgTempSchemaName {
+			return path, true
+		}
+		if iter.tempSchemaName != "" {
+			return iter.tempSchemaName, true
+		}
 	}
 	return "", false
 }
@@ -77,3 +77,41 @@ func TestSearchPathEquals(t *testing.T) {
 	d := MakeSearchPath([]string{"x"})
 	assert.False(t, a1.Equals(&d))
 }
+
+func TestSearchPathTemporarySchema(t *testing.T) {
+	testCases := []struct {
+		explicitSearchPath                         []string
+		tempSchemaName                             string
+		expectedSearchPath                         []string
+		expectedSearchPathWithoutImplicitPgCatalog []string
+	}{
+		{[]string{`foobar`}, ``, []string{`pg_catalog`, `foobar`}, []string{`foobar`}},
+		{[]string{`foobar`}, `pg_temp_1`, []string{`pg_temp_1`, `pg_catalog`, `foobar`}, []string{`foobar`}},
+		{[]string{`foobar`, `pg_temp`}, ``, []string{`pg_catalog`, `foobar`}, []string{`foobar`}},
+		{[]string{`foobar`, `pg_temp`}, `pg_temp_1`, []string{`pg_catalog`, `foobar`, `pg_temp_1`}, []string{`foobar`, `pg_temp_1`}},
+		{[]string{`pg_temp`, `pg_catalog`}, `pg_temp_1`, []string{`pg_temp_1`, `pg_catalog`}, []string{`pg_temp_1`, `pg_catalog`}},
+	}
+
+	for _, tc := range testCases {
+		searchPath := MakeSearchPath(tc.explicitSearchPath).WithTemporarySchemaName(tc.tempSchemaName)
+		t.Run(strings.Join(tc.explicitSearchPath, ",")+"/"+tc.tempSchemaName, func(t *testing.T) {
+			actualSearchPath := make([]string, 0)
+			iter := searchPath.Iter()
+			for p, ok := iter.Next(); ok; p, ok = iter.Next() {
+				actualSearchPath = append(actualSearchPath, p)
+			}
+			if !reflect.DeepEqual(tc.expectedSearchPath, actualSearchPath) {
+				t.Errorf(`Expected search path to be %#v, but was %#v.`, tc.expectedSearchPath, actualSearchPath)
+			}
+
+			actualSearchPath = make([]string, 0)
+			iter = searchPath.IterWithoutImplicitPGCatalog()
+			for p, ok := iter.Next(); ok; p, ok = iter.Next() {
+				actualSearchPath = append(actualSearchPath, p)
+			}
+			if !reflect.DeepEqual(tc.expectedSearchPathWithoutImplicitPgCatalog, actualSearchPath) {
+				t.Errorf(`Expected search path to be %#v, but was %#v.`, tc.expectedSearchPathWithoutImplicitPgCatalog, actualSearchPath)
+			}
+		})
+	}
+}
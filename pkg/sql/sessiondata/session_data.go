@@ -45,6 +45,10 @@ type SessionData struct {
 	SerialNormalizationMode SerialNormalizationMode
 	// SearchPath is a list of namespaces to search builtins in.
 	SearchPath SearchPath
+	// TemporarySchemaName is the name of the schema in which the session
+	// creates its temporary tables and views. It is empty if the session is
+	// not allowed to create temporary objects.
+	TemporarySchemaName string
 	// StmtTimeout is the duration a query is permitted to run before it is
 	// canceled by the session. If set to 0, there is no timeout.
 	StmtTimeout time.Duration
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -55,6 +56,7 @@ var supportedZoneConfigOptions = map[tree.Name]struct {
 	"range_min_bytes": {types.Int, func(c *config.ZoneConfig, d tree.Datum) { c.RangeMinBytes = proto.Int64(int64(tree.MustBeDInt(d))) }},
 	"range_max_bytes": {types.Int, func(c *config.ZoneConfig, d tree.Datum) { c.RangeMaxBytes = proto.Int64(int64(tree.MustBeDInt(d))) }},
 	"num_replicas":    {types.Int, func(c *config.ZoneConfig, d tree.Datum) { c.NumReplicas = proto.Int32(int32(tree.MustBeDInt(d))) }},
+	"num_voters":      {types.Int, func(c *config.ZoneConfig, d tree.Datum) { c.NumVoters = proto.Int32(int32(tree.MustBeDInt(d))) }},
 	"gc.ttlseconds": {types.Int, func(c *config.ZoneConfig, d tree.Datum) {
 		c.GC = &config.GCPolicy{TTLSeconds: int32(tree.MustBeDInt(d))}
 	}},
@@ -67,6 +69,11 @@ var supportedZoneConfigOptions = map[tree.Name]struct {
 		c.Constraints = constraintsList.Constraints
 		c.InheritedConstraints = false
 	}},
+	"voter_constraints": {types.String, func(c *config.ZoneConfig, d tree.Datum) {
+		var constraintsList config.ConstraintsList
+		loadYAML(&constraintsList, string(tree.MustBeDString(d)))
+		c.VoterConstraints = constraintsList.Constraints
+	}},
 	"lease_preferences": {types.String, func(c *config.ZoneConfig, d tree.Datum) {
 		loadYAML(&c.LeasePreferences, string(tree.MustBeDString(d)))
 		c.InheritedLeasePreferences = false
@@ -466,12 +473,20 @@ func (n *setZoneConfigNode) startExec(params runParams) error {
 	execConfig := params.extendedEvalCtx.ExecCfg
 	zoneToWrite := partialZone
 
+	if zoneToWrite.ConfiguresNonVoters() &&
+		!params.p.ExecCfg().Settings.Version.IsActive(cluster.VersionNonVotingReplicas) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"num_voters and voter_constraints require all nodes to be upgraded to %s",
+			cluster.VersionByKey(cluster.VersionNonVotingReplicas))
+	}
+
 	// Finally check for the extra protection partial zone configs would
 	// require from changes made to parent zones. The extra protections are:
 	//
 	// RangeMinBytes and RangeMaxBytes must be set together
 	// LeasePreferences cannot be set unless Constraints are explicitly set
 	// Per-replica constraints cannot be set unless num_replicas is explicitly set
+	// Voter constraints cannot be set unless num_voters is explicitly set
 	if err := zoneToWrite.ValidateTandemFields(); err != nil {
 		err = errors.Wrap(err, "could not validate zone config")
 		err = pgerror.WithCandidateCode(err, pgcode.InvalidParameterValue)
@@ -526,7 +541,9 @@ type nodeGetter func(context.Context, *serverpb.NodesRequest) (*serverpb.NodesRe
 // will be rejected. Additionally, invalid constraints such as
 // [+region=us-east1, -region=us-east1] will also be rejected.
 func validateNoRepeatKeysInZone(zone *config.ZoneConfig) error {
-	for _, constraints := range zone.Constraints {
+	allConstraints := append(
+		append([]config.Constraints(nil), zone.Constraints...), zone.VoterConstraints...)
+	for _, constraints := range allConstraints {
 		// Because we expect to have a small number of constraints, a nested
 		// loop is probably better than allocating a map.
 		for i, curr := range constraints.Constraints {
@@ -567,7 +584,8 @@ func validateNoRepeatKeysInZone(zone *config.ZoneConfig) error {
 func validateZoneAttrsAndLocalities(
 	ctx context.Context, getNodes nodeGetter, zone *config.ZoneConfig,
 ) error {
-	if len(zone.Constraints) == 0 && len(zone.LeasePreferences) == 0 {
+	if len(zone.Constraints) == 0 && len(zone.VoterConstraints) == 0 &&
+		len(zone.LeasePreferences) == 0 {
 		return nil
 	}
 
@@ -597,6 +615,11 @@ func validateZoneAttrsAndLocalities(
 			addToValidate(constraint)
 		}
 	}
+	for _, constraints := range zone.VoterConstraints {
+		for _, constraint := range constraints.Constraints {
+			addToValidate(constraint)
+		}
+	}
 	for _, leasePreferences := range zone.LeasePreferences {
 		for _, constraint := range leasePreferences.Constraints {
 			addToValidate(constraint)
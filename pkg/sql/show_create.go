@@ -134,6 +134,10 @@ func ShowCreateTable(
 			); err != nil {
 				return "", err
 			}
+			if idx.IsPartial() {
+				f.WriteString(" WHERE ")
+				f.WriteString(idx.Predicate)
+			}
 		}
 	}
 
@@ -229,11 +229,28 @@ func generateZoneConfigIntrospectionValues(
 			f.Printf("\tnum_replicas = %d", *zone.NumReplicas)
 			useComma = true
 		}
+		if zone.NumVoters != nil {
+			writeComma(f, useComma)
+			f.Printf("\tnum_voters = %d", *zone.NumVoters)
+			useComma = true
+		}
 		if !zone.InheritedConstraints {
 			writeComma(f, useComma)
 			f.Printf("\tconstraints = %s", lex.EscapeSQLString(constraints))
 			useComma = true
 		}
+		if len(zone.VoterConstraints) > 0 {
+			voterConstraints, err := yamlMarshalFlow(config.ConstraintsList{
+				Constraints: zone.VoterConstraints,
+			})
+			if err != nil {
+				return err
+			}
+			writeComma(f, useComma)
+			f.Printf("\tvoter_constraints = %s",
+				lex.EscapeSQLString(strings.TrimSpace(voterConstraints)))
+			useComma = true
+		}
 		if !zone.InheritedLeasePreferences {
 			writeComma(f, useComma)
 			f.Printf("\tlease_preferences = %s", lex.EscapeSQLString(prefs))
@@ -121,6 +121,11 @@ func EncodeTableKey(b []byte, val tree.Datum, dir encoding.Direction) ([]byte, e
 			return encoding.EncodeBytesAscending(b, t.GetBytes()), nil
 		}
 		return encoding.EncodeBytesDescending(b, t.GetBytes()), nil
+	case *tree.DEnum:
+		if dir == encoding.Ascending {
+			return encoding.EncodeBytesAscending(b, t.PhysicalRep), nil
+		}
+		return encoding.EncodeBytesDescending(b, t.PhysicalRep), nil
 	case *tree.DIPAddr:
 		data := t.ToBuffer(nil)
 		if dir == encoding.Ascending {
@@ -302,6 +307,18 @@ func DecodeTableKey(
 		}
 		u, err := uuid.FromBytes(r)
 		return a.NewDUuid(tree.DUuid{UUID: u}), rkey, err
+	case types.EnumFamily:
+		var r []byte
+		if dir == encoding.Ascending {
+			rkey, r, err = encoding.DecodeBytesAscending(key, nil)
+		} else {
+			rkey, r, err = encoding.DecodeBytesDescending(key, nil)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		d, err := tree.MakeDEnumFromPhysicalRepresentation(valType, r)
+		return d, rkey, err
 	case types.INetFamily:
 		var r []byte
 		if dir == encoding.Ascending {
@@ -372,6 +389,8 @@ func EncodeTableValue(
 		return encoding.EncodeDurationValue(appendTo, uint32(colID), t.Duration), nil
 	case *tree.DUuid:
 		return encoding.EncodeUUIDValue(appendTo, uint32(colID), t.UUID), nil
+	case *tree.DEnum:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), t.PhysicalRep), nil
 	case *tree.DIPAddr:
 		return encoding.EncodeIPAddrValue(appendTo, uint32(colID), t.IPAddr), nil
 	case *tree.DJSON:
@@ -498,6 +517,13 @@ func decodeUntaggedDatum(a *DatumAlloc, t *types.T, buf []byte) (tree.Datum, []b
 	case types.UuidFamily:
 		b, data, err := encoding.DecodeUntaggedUUIDValue(buf)
 		return a.NewDUuid(tree.DUuid{UUID: data}), b, err
+	case types.EnumFamily:
+		b, data, err := encoding.DecodeUntaggedBytesValue(buf)
+		if err != nil {
+			return nil, b, err
+		}
+		d, err := tree.MakeDEnumFromPhysicalRepresentation(t, data)
+		return d, b, err
 	case types.INetFamily:
 		b, data, err := encoding.DecodeUntaggedIPAddrValue(buf)
 		return a.NewDIPAddr(tree.DIPAddr{IPAddr: data}), b, err
@@ -630,6 +656,11 @@ func MarshalColumnValue(col *ColumnDescriptor, val tree.Datum) (roachpb.Value, e
 			r.SetBytes(v.GetBytes())
 			return r, nil
 		}
+	case types.EnumFamily:
+		if v, ok := val.(*tree.DEnum); ok {
+			r.SetBytes(v.PhysicalRep)
+			return r, nil
+		}
 	case types.INetFamily:
 		if v, ok := val.(*tree.DIPAddr); ok {
 			data := v.ToBuffer(nil)
@@ -785,6 +816,12 @@ func UnmarshalColumnValue(a *DatumAlloc, typ *types.T, value roachpb.Value) (tre
 			return nil, err
 		}
 		return a.NewDUuid(tree.DUuid{UUID: u}), nil
+	case types.EnumFamily:
+		v, err := value.GetBytes()
+		if err != nil {
+			return nil, err
+		}
+		return tree.MakeDEnumFromPhysicalRepresentation(typ, v)
 	case types.INetFamily:
 		v, err := value.GetBytes()
 		if err != nil {
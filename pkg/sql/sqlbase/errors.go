@@ -110,6 +110,16 @@ func NewRelationAlreadyExistsError(name string) error {
 	return pgerror.Newf(pgcode.DuplicateRelation, "relation %q already exists", name)
 }
 
+// NewTypeAlreadyExistsError creates an error for a preexisting type.
+func NewTypeAlreadyExistsError(name string) error {
+	return pgerror.Newf(pgcode.DuplicateObject, "type %q already exists", name)
+}
+
+// NewUndefinedTypeError creates an error that represents a missing type.
+func NewUndefinedTypeError(name tree.NodeFormatter) error {
+	return pgerror.Newf(pgcode.UndefinedObject, "type %q does not exist", tree.ErrString(name))
+}
+
 // NewWrongObjectTypeError creates a wrong object type error.
 func NewWrongObjectTypeError(name *tree.TableName, desiredObjType string) error {
 	return pgerror.Newf(pgcode.WrongObjectType, "%q is not a %s",
@@ -757,13 +757,19 @@ func EncodeInvertedIndexTableKeys(val tree.Datum, inKey []byte) (key [][]byte, e
 // EncodeSecondaryIndex encodes key/values for a secondary
 // index. colMap maps ColumnIDs to indices in `values`. This returns a
 // slice of IndexEntry. Forward indexes will return one value, while
-// inverted indices can return multiple values.
+// inverted indices can return multiple values. Indexes with the
+// PrimaryIndexEncoding return one value per column family, the first
+// one being the entry of column family 0.
 func EncodeSecondaryIndex(
 	tableDesc *TableDescriptor,
 	secondaryIndex *IndexDescriptor,
 	colMap map[ColumnID]int,
 	values []tree.Datum,
 ) ([]IndexEntry, error) {
+	if secondaryIndex.EncodingType == PrimaryIndexEncoding {
+		return encodePrimaryIndex(tableDesc, secondaryIndex, colMap, values)
+	}
+
 	secondaryIndexKeyPrefix := MakeIndexKeyPrefix(tableDesc, secondaryIndex.ID)
 
 	var containsNull = false
@@ -851,21 +857,113 @@ func EncodeSecondaryIndex(
 	return entries, nil
 }
 
+// encodePrimaryIndex encodes the key/values of an index which uses the
+// PrimaryIndexEncoding, i.e. which is encoded like the primary index of the
+// table: there is one KV per column family, keyed by the index columns and the
+// family ID, whose value holds the non-NULL columns of the family. The KV of
+// family 0 is always present and acts as the row sentinel; it is the first of
+// the returned entries.
+func encodePrimaryIndex(
+	tableDesc *TableDescriptor, index *IndexDescriptor, colMap map[ColumnID]int, values []tree.Datum,
+) ([]IndexEntry, error) {
+	indexKey, _, err := EncodeIndexKey(
+		tableDesc, index, colMap, values, MakeIndexKeyPrefix(tableDesc, index.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	// The key columns are only stored in the values when their encoding is
+	// composite, as their values can't be recovered from the key alone.
+	skipKeyColumn := func(colID ColumnID, val tree.Datum) bool {
+		for _, id := range index.ColumnIDs {
+			if id == colID {
+				if cdatum, ok := val.(tree.CompositeDatum); ok {
+					return !cdatum.IsComposite()
+				}
+				return true
+			}
+		}
+		return false
+	}
+
+	entries := make([]IndexEntry, 0, len(tableDesc.Families))
+	for i := range tableDesc.Families {
+		family := &tableDesc.Families[i]
+		// MakeFamilyKey appends to its argument; make sure that the keys of the
+		// different families don't share their backing array.
+		familyKey := keys.MakeFamilyKey(indexKey[:len(indexKey):len(indexKey)], uint32(family.ID))
+
+		if family.ID != 0 && len(family.ColumnIDs) == 1 && family.ColumnIDs[0] == family.DefaultColumnID {
+			// Like in the primary index, the single column of the family is
+			// stored directly as the value.
+			val := findColumnValue(family.DefaultColumnID, colMap, values)
+			if val == tree.DNull {
+				continue
+			}
+			col, err := tableDesc.FindColumnByID(family.DefaultColumnID)
+			if err != nil {
+				return nil, err
+			}
+			value, err := MarshalColumnValue(col, val)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, IndexEntry{Key: familyKey, Value: value})
+			continue
+		}
+
+		colIDs := append([]ColumnID(nil), family.ColumnIDs...)
+		sort.Sort(ColumnIDs(colIDs))
+		var entryValue []byte
+		var lastColID ColumnID
+		for _, colID := range colIDs {
+			val := findColumnValue(colID, colMap, values)
+			if val == tree.DNull || skipKeyColumn(colID, val) {
+				continue
+			}
+			entryValue, err = EncodeTableValue(entryValue, colID-lastColID, val, nil)
+			if err != nil {
+				return nil, err
+			}
+			lastColID = colID
+		}
+		if family.ID != 0 && len(entryValue) == 0 {
+			// All the columns of the family are NULL.
+			continue
+		}
+		entry := IndexEntry{Key: familyKey}
+		entry.Value.SetTuple(entryValue)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
 // EncodeSecondaryIndexes encodes key/values for the secondary indexes. colMap
 // maps ColumnIDs to indices in `values`. secondaryIndexEntries is the return
 // value (passed as a parameter so the caller can reuse between rows) and is
 // expected to be the same length as indexes.
+//
+// preds, if not nil, holds the predicates of the partial indexes among
+// indexes. The entry of a partial index whose predicate the row does not
+// satisfy is left empty (it has a nil Key) and must not be written.
 func EncodeSecondaryIndexes(
 	tableDesc *TableDescriptor,
 	indexes []IndexDescriptor,
 	colMap map[ColumnID]int,
 	values []tree.Datum,
 	secondaryIndexEntries []IndexEntry,
+	preds *PartialIndexPredicates,
 ) ([]IndexEntry, error) {
 	if len(secondaryIndexEntries) != len(indexes) {
 		panic("Length of secondaryIndexEntries is not equal to the number of indexes.")
 	}
 	for i := range indexes {
+		if ok, err := preds.Satisfies(&indexes[i], colMap, values); err != nil {
+			return secondaryIndexEntries, err
+		} else if !ok {
+			secondaryIndexEntries[i] = IndexEntry{}
+			continue
+		}
 		entries, err := EncodeSecondaryIndex(tableDesc, &indexes[i], colMap, values)
 		if err != nil {
 			return secondaryIndexEntries, err
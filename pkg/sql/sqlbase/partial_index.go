@@ -0,0 +1,212 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sqlbase
+
+import (
+	"context"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+// IsPartial returns true if the index is a partial index, i.e. it only
+// contains entries for the rows which satisfy its predicate.
+func (desc *IndexDescriptor) IsPartial() bool {
+	return desc.Predicate != ""
+}
+
+// PredicateColumnIDs returns the sorted IDs of the columns referenced by the
+// index predicate, or nil if the index is not partial.
+func (desc *IndexDescriptor) PredicateColumnIDs(tableDesc *TableDescriptor) ([]ColumnID, error) {
+	if !desc.IsPartial() {
+		return nil, nil
+	}
+	parsed, err := parser.ParseExpr(desc.Predicate)
+	if err != nil {
+		return nil, pgerror.Wrapf(err, pgcode.Syntax,
+			"could not parse predicate of index %q", desc.Name)
+	}
+
+	colIDsUsed := make(map[ColumnID]struct{})
+	if _, err := replacePredicateVars(parsed, func(c *tree.ColumnItem) (tree.Expr, error) {
+		col, _, err := tableDesc.FindColumnByName(c.ColumnName)
+		if err != nil {
+			return nil, err
+		}
+		colIDsUsed[col.ID] = struct{}{}
+		return c, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	colIDs := make([]ColumnID, 0, len(colIDsUsed))
+	for colID := range colIDsUsed {
+		colIDs = append(colIDs, colID)
+	}
+	sort.Sort(ColumnIDs(colIDs))
+	return colIDs, nil
+}
+
+// replacePredicateVars walks the given index predicate and replaces every
+// column reference with the result of fn.
+func replacePredicateVars(
+	expr tree.Expr, fn func(*tree.ColumnItem) (tree.Expr, error),
+) (tree.Expr, error) {
+	return tree.SimpleVisit(expr, func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		vBase, ok := expr.(tree.VarName)
+		if !ok {
+			return true, expr, nil
+		}
+		v, err := vBase.NormalizeVarName()
+		if err != nil {
+			return false, nil, err
+		}
+		c, ok := v.(*tree.ColumnItem)
+		if !ok {
+			return false, v, nil
+		}
+		newExpr, err = fn(c)
+		return false, newExpr, err
+	})
+}
+
+// PartialIndexPredicates evaluates the predicates of partial indexes against
+// rows which are about to be written to (or deleted from) a table, in order to
+// decide which partial indexes must contain an entry for each row.
+//
+// Index predicates are required to be immutable when the index is created, so
+// their evaluation does not depend on the session or the transaction;
+// PartialIndexPredicates therefore carries its own evaluation context.
+type PartialIndexPredicates struct {
+	exprs map[IndexID]tree.TypedExpr
+	cols  []ColumnDescriptor
+
+	// curColMap and curValues describe the row currently being evaluated.
+	curColMap map[ColumnID]int
+	curValues []tree.Datum
+
+	evalCtx tree.EvalContext
+}
+
+var _ tree.IndexedVarContainer = &PartialIndexPredicates{}
+
+// NewPartialIndexPredicates prepares the predicates of the partial indexes in
+// the given slice for evaluation. It returns nil if none of the indexes are
+// partial.
+func NewPartialIndexPredicates(
+	tableDesc *TableDescriptor, indexes []IndexDescriptor,
+) (*PartialIndexPredicates, error) {
+	var p *PartialIndexPredicates
+	for i := range indexes {
+		index := &indexes[i]
+		if !index.IsPartial() {
+			continue
+		}
+		if p == nil {
+			p = &PartialIndexPredicates{
+				exprs: make(map[IndexID]tree.TypedExpr),
+				cols:  tableDesc.AllNonDropColumns(),
+				evalCtx: tree.EvalContext{
+					Context:     context.TODO(),
+					SessionData: &sessiondata.SessionData{},
+				},
+			}
+			p.evalCtx.IVarContainer = p
+		}
+		if err := p.addPredicate(index); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *PartialIndexPredicates) addPredicate(index *IndexDescriptor) error {
+	parsed, err := parser.ParseExpr(index.Predicate)
+	if err != nil {
+		return pgerror.Wrapf(err, pgcode.Syntax,
+			"could not parse predicate of index %q", index.Name)
+	}
+	ivarHelper := tree.MakeIndexedVarHelper(p, len(p.cols))
+	replaced, err := replacePredicateVars(parsed, func(c *tree.ColumnItem) (tree.Expr, error) {
+		for i := range p.cols {
+			if p.cols[i].Name == string(c.ColumnName) {
+				return ivarHelper.IndexedVar(i), nil
+			}
+		}
+		return nil, pgerror.Newf(pgcode.UndefinedColumn,
+			"column %q not found for predicate of index %q", c.ColumnName, index.Name)
+	})
+	if err != nil {
+		return err
+	}
+	semaCtx := tree.MakeSemaContext()
+	semaCtx.IVarContainer = p
+	typedExpr, err := tree.TypeCheckAndRequire(replaced, &semaCtx, types.Bool, "index predicate")
+	if err != nil {
+		return err
+	}
+	p.exprs[index.ID] = typedExpr
+	return nil
+}
+
+// Satisfies returns true if the row described by colMap and values satisfies
+// the predicate of the given index, i.e. if the index must contain an entry
+// for the row. It always returns true for indexes which are not partial. A
+// NULL predicate result is treated like false.
+func (p *PartialIndexPredicates) Satisfies(
+	index *IndexDescriptor, colMap map[ColumnID]int, values []tree.Datum,
+) (bool, error) {
+	if p == nil || !index.IsPartial() {
+		return true, nil
+	}
+	expr, ok := p.exprs[index.ID]
+	if !ok {
+		return false, errors.AssertionFailedf("predicate of index %q was not prepared", index.Name)
+	}
+	p.curColMap, p.curValues = colMap, values
+	d, err := expr.Eval(&p.evalCtx)
+	p.curColMap, p.curValues = nil, nil
+	if err != nil {
+		return false, err
+	}
+	if d == tree.DNull {
+		return false, nil
+	}
+	return bool(tree.MustBeDBool(d)), nil
+}
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (p *PartialIndexPredicates) IndexedVarEval(
+	idx int, ctx *tree.EvalContext,
+) (tree.Datum, error) {
+	rowIdx, ok := p.curColMap[p.cols[idx].ID]
+	if !ok {
+		return tree.DNull, nil
+	}
+	return p.curValues[rowIdx], nil
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (p *PartialIndexPredicates) IndexedVarResolvedType(idx int) *types.T {
+	return &p.cols[idx].Type
+}
+
+// IndexedVarNodeFormatter implements the tree.IndexedVarContainer interface.
+func (p *PartialIndexPredicates) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	n := tree.Name(p.cols[idx].Name)
+	return &n
+}
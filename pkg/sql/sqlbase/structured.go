@@ -11,6 +11,7 @@
 package sqlbase
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sort"
@@ -91,6 +92,20 @@ type FamilyID uint32
 // IndexID is a custom type for IndexDescriptor IDs.
 type IndexID tree.IndexID
 
+// IndexDescriptorEncodingType is a custom type for the encodings of the
+// entries of an index.
+type IndexDescriptorEncodingType uint32
+
+const (
+	// SecondaryIndexEncoding is the regular encoding of secondary indexes: a
+	// single KV per row and index entry, with the stored columns in the value.
+	// It is the zero value so that existing descriptors keep using it.
+	SecondaryIndexEncoding IndexDescriptorEncodingType = iota
+	// PrimaryIndexEncoding is the encoding of the primary index: one KV per
+	// row and column family, each holding the columns of its family.
+	PrimaryIndexEncoding
+)
+
 // DescriptorVersion is a custom type for TableDescriptor Versions.
 type DescriptorVersion uint32
 
@@ -305,6 +320,23 @@ func GetDatabaseDescFromID(
 	return db, nil
 }
 
+// GetTypeDescFromID retrieves the type descriptor for the type ID passed
+// in using an existing proto getter. Returns an error if the descriptor
+// doesn't exist or if it exists and is not a type.
+func GetTypeDescFromID(ctx context.Context, protoGetter protoGetter, id ID) (*TypeDescriptor, error) {
+	desc := &Descriptor{}
+	descKey := MakeDescMetadataKey(id)
+
+	if err := protoGetter.GetProto(ctx, descKey, desc); err != nil {
+		return nil, err
+	}
+	typ := desc.GetType()
+	if typ == nil {
+		return nil, ErrDescriptorNotFound
+	}
+	return typ, nil
+}
+
 // GetTableDescFromID retrieves the table descriptor for the table
 // ID passed in using an existing proto getter. Returns an error if the
 // descriptor doesn't exist or if it exists and is not a table.
@@ -595,6 +627,22 @@ func (desc *TableDescriptor) IsSequence() bool {
 	return desc.SequenceOpts != nil
 }
 
+// IsTemporary returns true if the TableDescriptor describes a
+// session-scoped temporary table or view.
+func (desc *TableDescriptor) IsTemporary() bool {
+	return desc.TemporarySchemaID != 0
+}
+
+// GetNamespaceParentID returns the ID under which the descriptor's name is
+// registered in system.namespace. This is the ID of the temporary schema for
+// temporary tables and views, and the ID of the parent database otherwise.
+func (desc *TableDescriptor) GetNamespaceParentID() ID {
+	if desc.IsTemporary() {
+		return desc.TemporarySchemaID
+	}
+	return desc.ParentID
+}
+
 // IsVirtualTable returns true if the TableDescriptor describes a
 // virtual Table (like the information_schema tables) and thus doesn't
 // need to be physically stored.
@@ -1778,6 +1826,13 @@ func (desc *TableDescriptor) ValidateTable() error {
 					"mutation in state %s, direction %s, constraint %v",
 					errors.Safe(m.State), errors.Safe(m.Direction), desc.Constraint.Name)
 			}
+		case *DescriptorMutation_PrimaryKeySwap:
+			if unSetEnums {
+				return errors.AssertionFailedf(
+					"mutation in state %s, direction %s, primary key swap to index %d",
+					errors.Safe(m.State), errors.Safe(m.Direction),
+					errors.Safe(desc.PrimaryKeySwap.NewPrimaryIndexID))
+			}
 		default:
 			return errors.AssertionFailedf(
 				"mutation in state %s, direction %s, and no column/index descriptor",
@@ -2893,6 +2948,9 @@ func (desc *MutableTableDescriptor) MakeMutationComplete(m DescriptorMutation) e
 			default:
 				return errors.Errorf("unsupported constraint type: %d", t.Constraint.ConstraintType)
 			}
+
+		case *DescriptorMutation_PrimaryKeySwap:
+			return desc.completePrimaryKeySwap(t.PrimaryKeySwap)
 		}
 
 	case DescriptorMutation_DROP:
@@ -2907,6 +2965,95 @@ func (desc *MutableTableDescriptor) MakeMutationComplete(m DescriptorMutation) e
 	return nil
 }
 
+// completePrimaryKeySwap makes the indexes built for a primary key change
+// public in place of the old ones. The new indexes have already been added to
+// desc.Indexes by the preceding mutations of the same group. The old primary
+// index and the old secondary indexes can't simply vanish: nodes still using
+// the previous version of the descriptor read them, so they are queued to be
+// dropped by a new group of mutations, and remain maintained by writers until
+// that drop has progressed.
+func (desc *MutableTableDescriptor) completePrimaryKeySwap(swap *PrimaryKeySwap) error {
+	replacements := make(map[IndexID]IndexID, len(swap.OldIndexIDs))
+	for i, id := range swap.OldIndexIDs {
+		replacements[id] = swap.NewIndexIDs[i]
+	}
+	newIndexes := make(map[IndexID]IndexDescriptor, len(swap.NewIndexIDs)+1)
+	for _, id := range append([]IndexID{swap.NewPrimaryIndexID}, swap.NewIndexIDs...) {
+		idx, err := desc.FindIndexByID(id)
+		if err != nil {
+			return errors.NewAssertionErrorWithWrappedErrf(err, "index %d not found", id)
+		}
+		newIndexes[id] = *idx
+	}
+	newPrimaryIndex := newIndexes[swap.NewPrimaryIndexID]
+
+	// The new secondary indexes take the place, and the name, of the indexes
+	// they replace.
+	var indexes, dropped []IndexDescriptor
+	for _, idx := range desc.Indexes {
+		if _, isNew := newIndexes[idx.ID]; isNew {
+			continue
+		}
+		newID, isOld := replacements[idx.ID]
+		if !isOld {
+			indexes = append(indexes, idx)
+			continue
+		}
+		newIdx := newIndexes[newID]
+		newIdx.Name = idx.Name
+		indexes = append(indexes, newIdx)
+		dropped = append(dropped, idx)
+	}
+
+	oldPrimaryIndex := desc.PrimaryIndex
+	newPrimaryIndex.Name = oldPrimaryIndex.Name
+	newPrimaryIndex.EncodingType = SecondaryIndexEncoding
+	newPrimaryIndex.StoreColumnIDs = nil
+	newPrimaryIndex.StoreColumnNames = nil
+	newPrimaryIndex.ExtraColumnIDs = nil
+	desc.PrimaryIndex = newPrimaryIndex
+	desc.Indexes = indexes
+
+	// Until it is dropped, the old primary index is written like any other
+	// index of the table, which requires it to store all the other columns.
+	oldPrimaryIndex.EncodingType = PrimaryIndexEncoding
+	for _, col := range desc.Columns {
+		if !oldPrimaryIndex.ContainsColumnID(col.ID) {
+			oldPrimaryIndex.StoreColumnIDs = append(oldPrimaryIndex.StoreColumnIDs, col.ID)
+			oldPrimaryIndex.StoreColumnNames = append(oldPrimaryIndex.StoreColumnNames, col.Name)
+		}
+	}
+	dropped = append([]IndexDescriptor{oldPrimaryIndex}, dropped...)
+	for i := range dropped {
+		if err := desc.AddIndexMutation(&dropped[i], DescriptorMutation_DROP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddPrimaryKeySwapMutation adds a mutation to desc.Mutations which, once the
+// indexes it refers to have been built, replaces the primary index and the
+// secondary indexes of the table with them.
+func (desc *MutableTableDescriptor) AddPrimaryKeySwapMutation(swap *PrimaryKeySwap) {
+	m := DescriptorMutation{
+		Descriptor_: &DescriptorMutation_PrimaryKeySwap{PrimaryKeySwap: swap},
+		Direction:   DescriptorMutation_ADD,
+	}
+	desc.addMutation(m)
+}
+
+// PrimaryKeySwapMutation returns the pending primary key swap of the table,
+// if any.
+func (desc *TableDescriptor) PrimaryKeySwapMutation() *PrimaryKeySwap {
+	for _, m := range desc.Mutations {
+		if swap := m.GetPrimaryKeySwap(); swap != nil && m.Direction == DescriptorMutation_ADD {
+			return swap
+		}
+	}
+	return nil
+}
+
 // AddCheckMutation adds a check constraint mutation to desc.Mutations.
 func (desc *MutableTableDescriptor) AddCheckMutation(
 	ck *TableDescriptor_CheckConstraint, direction DescriptorMutation_Direction,
@@ -3230,6 +3377,164 @@ func (desc *DatabaseDescriptor) Validate() error {
 	return desc.Privileges.Validate(desc.GetID())
 }
 
+// SetID implements the DescriptorProto interface.
+func (desc *TypeDescriptor) SetID(id ID) {
+	desc.ID = id
+}
+
+// TypeName returns the plain type of this descriptor.
+func (desc *TypeDescriptor) TypeName() string {
+	return "type"
+}
+
+// SetName implements the DescriptorProto interface.
+func (desc *TypeDescriptor) SetName(name string) {
+	desc.Name = name
+}
+
+// GetAuditMode is part of the DescriptorProto interface.
+// Types are never audited.
+func (desc *TypeDescriptor) GetAuditMode() TableDescriptor_AuditMode {
+	return TableDescriptor_DISABLED
+}
+
+// Validate validates that the type descriptor is well formed. Checks
+// include validating the type name and verifying that the members of an
+// ENUM are unique and sorted by their physical representations.
+func (desc *TypeDescriptor) Validate() error {
+	if err := validateName(desc.Name, "type"); err != nil {
+		return err
+	}
+	if desc.ID == 0 {
+		return fmt.Errorf("invalid type ID %d", desc.ID)
+	}
+	if desc.ParentID == 0 {
+		return fmt.Errorf("invalid parent ID %d", desc.ParentID)
+	}
+
+	labels := make(map[string]struct{}, len(desc.EnumMembers))
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		if _, ok := labels[member.LogicalRepresentation]; ok {
+			return fmt.Errorf("duplicate enum member %q", member.LogicalRepresentation)
+		}
+		labels[member.LogicalRepresentation] = struct{}{}
+		if i > 0 && bytes.Compare(
+			desc.EnumMembers[i-1].PhysicalRepresentation, member.PhysicalRepresentation) >= 0 {
+			return fmt.Errorf("enum members %q and %q are not sorted",
+				desc.EnumMembers[i-1].LogicalRepresentation, member.LogicalRepresentation)
+		}
+	}
+
+	return desc.Privileges.Validate(desc.GetID())
+}
+
+// FindEnumMember returns the index of the member of the ENUM with the given
+// label, or -1 if there is no such member.
+func (desc *TypeDescriptor) FindEnumMember(label string) int {
+	for i := range desc.EnumMembers {
+		if desc.EnumMembers[i].LogicalRepresentation == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// HasReadOnlyEnumMembers returns true if some members of the ENUM are still
+// being added.
+func (desc *TypeDescriptor) HasReadOnlyEnumMembers() bool {
+	for i := range desc.EnumMembers {
+		if desc.EnumMembers[i].Capability == TypeDescriptor_EnumMember_READ_ONLY {
+			return true
+		}
+	}
+	return false
+}
+
+// MakeTypesT returns the SQL type described by the descriptor.
+func (desc *TypeDescriptor) MakeTypesT() *types.T {
+	meta := types.EnumMetadata{
+		Name:                    desc.Name,
+		PhysicalRepresentations: make([][]byte, len(desc.EnumMembers)),
+		LogicalRepresentations:  make([]string, len(desc.EnumMembers)),
+		ReadOnly:                make([]bool, len(desc.EnumMembers)),
+	}
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		meta.PhysicalRepresentations[i] = member.PhysicalRepresentation
+		meta.LogicalRepresentations[i] = member.LogicalRepresentation
+		meta.ReadOnly[i] = member.Capability == TypeDescriptor_EnumMember_READ_ONLY
+	}
+	return types.MakeEnum(uint32(desc.ID), meta)
+}
+
+// IsTypeReference returns true if t refers to the user-defined type with
+// the given descriptor ID.
+func IsTypeReference(t *types.T, typeID ID) bool {
+	return t.Family() == types.EnumFamily && !t.IsUnresolvedReference() && !t.IsAmbiguous() &&
+		types.UserDefinedTypeOIDToID(t.Oid()) == uint32(typeID)
+}
+
+// EnumHasReadOnlyMembers returns true if t is an ENUM type with members that
+// are still being added.
+func EnumHasReadOnlyMembers(t *types.T) bool {
+	for i := range t.EnumLogicalRepresentations() {
+		if t.EnumMemberIsReadOnly(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// MakeEnumMembersWritable returns a copy of the ENUM type t in which none of
+// the members are read-only.
+func MakeEnumMembersWritable(t *types.T) *types.T {
+	meta := *t.InternalType.EnumData
+	meta.ReadOnly = nil
+	return types.MakeEnum(types.UserDefinedTypeOIDToID(t.Oid()), meta)
+}
+
+// ForeachColumnOfType calls f on every column of the table, including the
+// columns being added or dropped, whose type is the user-defined type with
+// the given ID.
+func (desc *TableDescriptor) ForeachColumnOfType(typeID ID, f func(*ColumnDescriptor)) {
+	for i := range desc.Columns {
+		if IsTypeReference(&desc.Columns[i].Type, typeID) {
+			f(&desc.Columns[i])
+		}
+	}
+	for i := range desc.Mutations {
+		if col := desc.Mutations[i].GetColumn(); col != nil && IsTypeReference(&col.Type, typeID) {
+			f(col)
+		}
+	}
+}
+
+// UsesType returns true if some column of the table, including the columns
+// being added or dropped, is of the user-defined type with the given ID.
+func (desc *TableDescriptor) UsesType(typeID ID) bool {
+	found := false
+	desc.ForeachColumnOfType(typeID, func(*ColumnDescriptor) { found = true })
+	return found
+}
+
+// HasReadOnlyEnumMembers returns true if the type of some column of the
+// table, including the columns being added or dropped, is an ENUM with
+// members that are still being added.
+func (desc *TableDescriptor) HasReadOnlyEnumMembers() bool {
+	for i := range desc.Columns {
+		if EnumHasReadOnlyMembers(&desc.Columns[i].Type) {
+			return true
+		}
+	}
+	for i := range desc.Mutations {
+		if col := desc.Mutations[i].GetColumn(); col != nil && EnumHasReadOnlyMembers(&col.Type) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetID returns the ID of the descriptor.
 func (desc *Descriptor) GetID() ID {
 	switch t := desc.Union.(type) {
@@ -3237,6 +3542,8 @@ func (desc *Descriptor) GetID() ID {
 		return t.Table.ID
 	case *Descriptor_Database:
 		return t.Database.ID
+	case *Descriptor_Type:
+		return t.Type.ID
 	default:
 		return 0
 	}
@@ -3249,6 +3556,8 @@ func (desc *Descriptor) GetName() string {
 		return t.Table.Name
 	case *Descriptor_Database:
 		return t.Database.Name
+	case *Descriptor_Type:
+		return t.Type.Name
 	default:
 		return ""
 	}
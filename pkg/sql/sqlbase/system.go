@@ -29,7 +29,7 @@ func init() {
 }
 
 // SplitAtIDHook determines whether a specific descriptor ID
-// should be considered for a split at all. If it is a database
+// should be considered for a split at all. If it is a database, a type
 // or a view table descriptor, it should not be considered.
 func SplitAtIDHook(id uint32, cfg *config.SystemConfig) bool {
 	descVal := cfg.GetDesc(MakeDescMetadataKey(ID(id)))
@@ -43,6 +43,9 @@ func SplitAtIDHook(id uint32, cfg *config.SystemConfig) bool {
 	if dbDesc := desc.GetDatabase(); dbDesc != nil {
 		return false
 	}
+	if typDesc := desc.GetType(); typDesc != nil {
+		return false
+	}
 	if tableDesc := desc.GetTable(); tableDesc != nil {
 		if viewStr := tableDesc.GetViewQuery(); viewStr != "" {
 			return false
@@ -224,6 +227,36 @@ CREATE TABLE system.comments (
    comment   STRING NOT NULL, -- the comment
    PRIMARY KEY (type, object_id, sub_id)
 );`
+
+	// scheduled_jobs stores the schedules on which jobs, such as backups, are
+	// periodically started. A schedule with a NULL next_run is paused.
+	ScheduledJobsTableSchema = `
+CREATE TABLE system.scheduled_jobs (
+	schedule_id      INT8      DEFAULT unique_rowid() PRIMARY KEY,
+	schedule_name    STRING    NOT NULL,
+	created          TIMESTAMP NOT NULL DEFAULT now(),
+	owner            STRING    NOT NULL,
+	next_run         TIMESTAMP,
+	schedule_expr    STRING    NOT NULL,
+	executor_type    STRING    NOT NULL,
+	execution_args   BYTES     NOT NULL,
+	INDEX (next_run),
+	FAMILY (schedule_id, schedule_name, created, owner, next_run, schedule_expr, executor_type, execution_args)
+);`
+
+	// protected_ts_records stores the protected timestamp records: timestamps
+	// below which the GC queue may not collect history in the given spans.
+	// The spans are encoded as a sequence of start and end keys (see package
+	// protectedts).
+	ProtectedTSRecordsTableSchema = `
+CREATE TABLE system.protected_ts_records (
+	id        UUID    PRIMARY KEY,
+	ts        DECIMAL NOT NULL,
+	meta_type STRING  NOT NULL,
+	meta      BYTES,
+	spans     BYTES   NOT NULL,
+	FAMILY (id, ts, meta_type, meta, spans)
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -264,6 +297,8 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	keys.LocationsTableID:       privilege.ReadWriteData,
 	keys.RoleMembersTableID:     privilege.ReadWriteData,
 	keys.CommentsTableID:        privilege.ReadWriteData,
+	keys.ScheduledJobsTableID:   privilege.ReadWriteData,
+	keys.ProtectedTSRecordsID:   privilege.ReadWriteData,
 }
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
@@ -861,6 +896,84 @@ var (
 		FormatVersion:  InterleavedFormatVersion,
 		NextMutationID: 1,
 	}
+
+	// ScheduledJobsTable is the descriptor for the scheduled jobs table.
+	ScheduledJobsTable = TableDescriptor{
+		Name:     "scheduled_jobs",
+		ID:       keys.ScheduledJobsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "schedule_id", ID: 1, Type: *types.Int, DefaultExpr: &uniqueRowIDString},
+			{Name: "schedule_name", ID: 2, Type: *types.String},
+			{Name: "created", ID: 3, Type: *types.Timestamp, DefaultExpr: &nowString},
+			{Name: "owner", ID: 4, Type: *types.String},
+			{Name: "next_run", ID: 5, Type: *types.Timestamp, Nullable: true},
+			{Name: "schedule_expr", ID: 6, Type: *types.String},
+			{Name: "executor_type", ID: 7, Type: *types.String},
+			{Name: "execution_args", ID: 8, Type: *types.Bytes},
+		},
+		NextColumnID: 9,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "fam_0_schedule_id_schedule_name_created_owner_next_run_schedule_expr_executor_type_execution_args",
+				ID:   0,
+				ColumnNames: []string{
+					"schedule_id", "schedule_name", "created", "owner",
+					"next_run", "schedule_expr", "executor_type", "execution_args",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7, 8},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("schedule_id"),
+		Indexes: []IndexDescriptor{
+			{
+				Name:             "scheduled_jobs_next_run_idx",
+				ID:               2,
+				Unique:           false,
+				ColumnNames:      []string{"next_run"},
+				ColumnDirections: singleASC,
+				ColumnIDs:        []ColumnID{5},
+				ExtraColumnIDs:   []ColumnID{1},
+			},
+		},
+		NextIndexID:    3,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.ScheduledJobsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// ProtectedTSRecordsTable is the descriptor for the protected timestamp
+	// records table.
+	ProtectedTSRecordsTable = TableDescriptor{
+		Name:     "protected_ts_records",
+		ID:       keys.ProtectedTSRecordsID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: *types.Uuid},
+			{Name: "ts", ID: 2, Type: *types.Decimal},
+			{Name: "meta_type", ID: 3, Type: *types.String},
+			{Name: "meta", ID: 4, Type: *types.Bytes, Nullable: true},
+			{Name: "spans", ID: 5, Type: *types.Bytes},
+		},
+		NextColumnID: 6,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_id_ts_meta_type_meta_spans",
+				ID:          0,
+				ColumnNames: []string{"id", "ts", "meta_type", "meta", "spans"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4, 5},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("id"),
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.ProtectedTSRecordsID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create a kv pair for the zone config for the given key and config value.
@@ -906,6 +1019,14 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 	// The CommentsTable has been introduced in 2.2. It was added here since it
 	// was introduced, but it's also created as a migration for older clusters.
 	target.AddDescriptor(keys.SystemDatabaseID, &CommentsTable)
+
+	// The ScheduledJobsTable has been introduced in 19.2. It is also created
+	// as a migration for older clusters.
+	target.AddDescriptor(keys.SystemDatabaseID, &ScheduledJobsTable)
+
+	// The ProtectedTSRecordsTable has been introduced in 19.2. It is also
+	// created as a migration for older clusters.
+	target.AddDescriptor(keys.SystemDatabaseID, &ProtectedTSRecordsTable)
 }
 
 // addSystemDatabaseToSchema populates the supplied MetadataSchema with the
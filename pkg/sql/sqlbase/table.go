@@ -97,6 +97,12 @@ func ValidateColumnDefType(t *types.T) error {
 		}
 		return ValidateColumnDefType(t.ArrayContents())
 
+	case types.EnumFamily:
+		if t.IsUnresolvedReference() || t.IsAmbiguous() {
+			return pgerror.Newf(pgcode.InvalidTableDefinition,
+				"value type %s cannot be used for table columns", t.String())
+		}
+
 	case types.BitFamily, types.IntFamily, types.FloatFamily, types.BoolFamily, types.BytesFamily, types.DateFamily,
 		types.INetFamily, types.IntervalFamily, types.JsonFamily, types.OidFamily, types.TimeFamily,
 		types.TimestampFamily, types.TimestampTZFamily, types.UuidFamily:
@@ -120,7 +126,7 @@ func ValidateColumnDefType(t *types.T) error {
 // expression.
 //
 // semaCtx can be nil if no default expression is used for the
-// column.
+// column and the column type is not a user-defined type.
 //
 // The DEFAULT expression is returned in TypedExpr form for analysis (e.g. recording
 // sequence dependencies).
@@ -151,11 +157,21 @@ func MakeColumnDefDescs(
 		Nullable: d.Nullable.Nullability != tree.NotNull && !d.PrimaryKey,
 	}
 
-	// Validate and assign column type.
-	err := ValidateColumnDefType(d.Type)
+	// Resolve, validate and assign column type.
+	typ, err := tree.ResolveTypeReference(semaCtx, d.Type)
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	if typ.Family() == types.EnumFamily && EnumHasReadOnlyMembers(typ) {
+		// A new column is only ever visible to nodes which also know about all
+		// the members of its type, so the members that are still being added to
+		// the type can be written to it right away.
+		typ = MakeEnumMembersWritable(typ)
+	}
+	d.Type = typ
+	if err := ValidateColumnDefType(d.Type); err != nil {
+		return nil, nil, nil, err
+	}
 	col.Type = *d.Type
 
 	var typedExpr tree.TypedExpr
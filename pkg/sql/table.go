@@ -146,7 +146,7 @@ func (tc *TableCollection) getMutableTableDescriptor(
 		log.Infof(ctx, "reading mutable descriptor on table '%s'", tn)
 	}
 
-	if tn.SchemaName != tree.PublicSchemaName {
+	if tn.SchemaName != tree.PublicSchemaName && !isTemporarySchemaName(tn.Schema()) {
 		if flags.required {
 			return nil, sqlbase.NewUnsupportedSchemaUsageError(tree.ErrString(tn))
 		}
@@ -169,7 +169,20 @@ func (tc *TableCollection) getMutableTableDescriptor(
 		}
 	}
 
-	if refuseFurtherLookup, table, err := tc.getUncommittedTable(dbID, tn, flags.required); refuseFurtherLookup || err != nil {
+	// Temporary tables are registered in system.namespace under the ID of
+	// their schema.
+	parentID := dbID
+	if isTemporarySchemaName(tn.Schema()) {
+		parentID, err = getTemporarySchemaID(ctx, txn, dbID, tn.Schema())
+		if err != nil || parentID == sqlbase.InvalidID {
+			if err == nil && flags.required {
+				err = sqlbase.NewUndefinedRelationError(tn)
+			}
+			return nil, err
+		}
+	}
+
+	if refuseFurtherLookup, table, err := tc.getUncommittedTable(parentID, tn, flags.required); refuseFurtherLookup || err != nil {
 		return nil, err
 	} else if mut := table.MutableTableDescriptor; mut != nil {
 		log.VEventf(ctx, 2, "found uncommitted table %d", mut.ID)
@@ -202,7 +215,7 @@ func (tc *TableCollection) getTableVersion(
 		log.Infof(ctx, "planner acquiring lease on table '%s'", tn)
 	}
 
-	if tn.SchemaName != tree.PublicSchemaName {
+	if tn.SchemaName != tree.PublicSchemaName && !isTemporarySchemaName(tn.Schema()) {
 		if flags.required {
 			return nil, sqlbase.NewUnsupportedSchemaUsageError(tree.ErrString(tn))
 		}
@@ -225,6 +238,19 @@ func (tc *TableCollection) getTableVersion(
 		}
 	}
 
+	// Temporary tables are registered in system.namespace under the ID of
+	// their schema.
+	parentID := dbID
+	if isTemporarySchemaName(tn.Schema()) {
+		parentID, err = getTemporarySchemaID(ctx, txn, dbID, tn.Schema())
+		if err != nil || parentID == sqlbase.InvalidID {
+			if err == nil && flags.required {
+				err = sqlbase.NewUndefinedRelationError(tn)
+			}
+			return nil, err
+		}
+	}
+
 	// TODO(vivek): Ideally we'd avoid caching for only the
 	// system.descriptor and system.lease tables, because they are
 	// used for acquiring leases, creating a chicken&egg problem.
@@ -232,10 +258,14 @@ func (tc *TableCollection) getTableVersion(
 	// disabling caching of system.eventlog, system.rangelog, and
 	// system.users. For now we're sticking to disabling caching of
 	// all system descriptors except the role-members-table.
+	//
+	// Temporary tables are not leased by name either. They can only be used by
+	// the session that created them.
 	avoidCache := flags.avoidCached || testDisableTableLeases ||
-		(tn.Catalog() == sqlbase.SystemDB.Name && tn.TableName.String() != sqlbase.RoleMembersTable.Name)
+		(tn.Catalog() == sqlbase.SystemDB.Name && tn.TableName.String() != sqlbase.RoleMembersTable.Name) ||
+		parentID != dbID
 
-	if refuseFurtherLookup, table, err := tc.getUncommittedTable(dbID, tn, flags.required); refuseFurtherLookup || err != nil {
+	if refuseFurtherLookup, table, err := tc.getUncommittedTable(parentID, tn, flags.required); refuseFurtherLookup || err != nil {
 		return nil, err
 	} else if immut := table.ImmutableTableDescriptor; immut != nil {
 		// If not forcing to resolve using KV, tables being added aren't visible.
@@ -270,7 +300,7 @@ func (tc *TableCollection) getTableVersion(
 	// transaction.
 	for _, table := range tc.leasedTables {
 		if table.Name == string(tn.TableName) &&
-			table.ParentID == dbID {
+			table.GetNamespaceParentID() == parentID {
 			log.VEventf(ctx, 2, "found table in table collection for table '%s'", tn)
 			return table, nil
 		}
@@ -522,8 +552,11 @@ func (tc *TableCollection) getUncommittedDatabaseID(
 // a known deletion of that table, so it would be invalid to miss the
 // cache and go to KV (where the descriptor prior to the DROP may
 // still exist).
+//
+// parentID is the ID under which the table's name is registered in
+// system.namespace; see TableDescriptor.GetNamespaceParentID.
 func (tc *TableCollection) getUncommittedTable(
-	dbID sqlbase.ID, tn *tree.TableName, required bool,
+	parentID sqlbase.ID, tn *tree.TableName, required bool,
 ) (refuseFurtherLookup bool, table uncommittedTable, err error) {
 	// Walk latest to earliest so that a DROP TABLE followed by a CREATE TABLE
 	// with the same name will result in the CREATE TABLE being seen.
@@ -537,7 +570,7 @@ func (tc *TableCollection) getUncommittedTable(
 		// effect of it.
 		for _, drain := range mutTbl.DrainingNames {
 			if drain.Name == string(tn.TableName) &&
-				drain.ParentID == dbID {
+				drain.ParentID == parentID {
 				// Table name has gone away.
 				if required {
 					// If it's required here, say it doesn't exist.
@@ -551,7 +584,7 @@ func (tc *TableCollection) getUncommittedTable(
 
 		// Do we know about a table with this name?
 		if mutTbl.Name == string(tn.TableName) &&
-			mutTbl.ParentID == dbID {
+			mutTbl.GetNamespaceParentID() == parentID {
 			// Right state?
 			if err = filterTableState(mutTbl.TableDesc()); err != nil && err != errTableAdding {
 				if !required {
@@ -162,7 +162,8 @@ func (td *tableDeleter) deleteAllRowsScan(
 		ValNeededForCol: valNeededForCol,
 	}
 	if err := rf.Init(
-		false /* reverse */, false /* returnRangeInfo */, false /* isCheck */, td.alloc, tableArgs,
+		false /* reverse */, false /* returnRangeInfo */, false, /* isCheck */
+		roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, td.alloc, tableArgs,
 	); err != nil {
 		return resume, err
 	}
@@ -274,7 +275,8 @@ func (td *tableDeleter) deleteIndexScan(
 		ValNeededForCol: valNeededForCol,
 	}
 	if err := rf.Init(
-		false /* reverse */, false /* returnRangeInfo */, false /* isCheck */, td.alloc, tableArgs,
+		false /* reverse */, false /* returnRangeInfo */, false, /* isCheck */
+		roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, td.alloc, tableArgs,
 	); err != nil {
 		return resume, err
 	}
@@ -327,7 +327,8 @@ func (tu *tableUpserter) init(txn *client.Txn, evalCtx *tree.EvalContext) error
 	}
 
 	if err := tu.fetcher.Init(
-		false /* reverse */, false /*returnRangeInfo*/, false /* isCheck */, tu.alloc, tableArgs,
+		false /* reverse */, false /*returnRangeInfo*/, false, /* isCheck */
+		roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK, tu.alloc, tableArgs,
 	); err != nil {
 		return err
 	}
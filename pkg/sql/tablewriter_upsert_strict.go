@@ -102,7 +102,9 @@ func (tu *strictTableUpserter) getUniqueIndexes() (err error) {
 	tableDesc := tu.tableDesc()
 	indexes := tableDesc.Indexes
 	for _, index := range indexes {
-		if index.Unique {
+		// Partial unique indexes are skipped: an insert row which conflicts on
+		// one of them fails with a uniqueness violation instead.
+		if index.Unique && !index.IsPartial() {
 			tu.conflictIndexes = append(tu.conflictIndexes, index)
 		}
 	}
@@ -0,0 +1,402 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uint128"
+	"github.com/cockroachdb/errors"
+)
+
+// Temporary tables and views live in a per-session, per-database schema
+// named pg_temp_<session ID>. The schema does not have a descriptor; it is
+// only an entry in system.namespace mapping (database ID, schema name) to a
+// freshly allocated ID, under which the namespace entries of the temporary
+// objects are stored. The schema is created lazily by the first temporary
+// object of the session and removed, together with all the objects in it,
+// when the session ends. Schemas left behind by sessions whose node died are
+// removed by a background cleaner.
+
+// temporarySchemaNamePrefix is the prefix of the names of all temporary
+// schemas.
+const temporarySchemaNamePrefix = sessiondata.PgTempSchemaName + "_"
+
+var temporaryObjectCleanupInterval = settings.RegisterNonNegativeDurationSetting(
+	"sql.temp_object_cleaner.cleanup_interval",
+	"how often to clean up temporary schemas and objects left behind by sessions that "+
+		"are no longer running",
+	30*time.Minute,
+)
+
+// temporarySchemaName returns the name of the temporary schema of the session
+// with the given ID.
+func temporarySchemaName(sessionID ClusterWideID) string {
+	return fmt.Sprintf("%s%d_%d", temporarySchemaNamePrefix, sessionID.Hi, sessionID.Lo)
+}
+
+// temporarySchemaSessionID extracts the ID of the session owning the given
+// temporary schema. ok is false if scName is not the name of a temporary
+// schema.
+func temporarySchemaSessionID(scName string) (_ ClusterWideID, ok bool) {
+	if !strings.HasPrefix(scName, temporarySchemaNamePrefix) {
+		return ClusterWideID{}, false
+	}
+	parts := strings.Split(strings.TrimPrefix(scName, temporarySchemaNamePrefix), "_")
+	if len(parts) != 2 {
+		return ClusterWideID{}, false
+	}
+	hi, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return ClusterWideID{}, false
+	}
+	lo, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return ClusterWideID{}, false
+	}
+	return ClusterWideID{Uint128: uint128.FromInts(hi, lo)}, true
+}
+
+// isTemporarySchemaName returns true if scName is the name of a temporary
+// schema of any session.
+func isTemporarySchemaName(scName string) bool {
+	_, ok := temporarySchemaSessionID(scName)
+	return ok
+}
+
+// getTemporarySchemaID looks up the ID of the temporary schema scName in the
+// given database. InvalidID is returned if the schema does not exist.
+func getTemporarySchemaID(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, scName string,
+) (sqlbase.ID, error) {
+	return getDescriptorID(ctx, txn, sqlbase.NewTableKey(dbID, scName))
+}
+
+// getOrCreateTemporarySchemaID returns the ID of the session's temporary
+// schema in the given database, creating the schema if it does not exist yet.
+func (p *planner) getOrCreateTemporarySchemaID(
+	ctx context.Context, dbID sqlbase.ID,
+) (sqlbase.ID, error) {
+	if !p.ExecCfg().Settings.Version.IsActive(cluster.VersionTemporaryTables) {
+		return sqlbase.InvalidID, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"temporary objects require all nodes to be upgraded to %s",
+			cluster.VersionByKey(cluster.VersionTemporaryTables))
+	}
+	scName := p.SessionData().TemporarySchemaName
+	if scName == "" || p.sessionDataMutator == nil {
+		return sqlbase.InvalidID, pgerror.New(pgcode.FeatureNotSupported,
+			"temporary objects cannot be created in this session")
+	}
+	id, err := getTemporarySchemaID(ctx, p.txn, dbID, scName)
+	if err != nil {
+		return sqlbase.InvalidID, err
+	}
+	if id == sqlbase.InvalidID {
+		id, err = GenerateUniqueDescID(ctx, p.ExecCfg().DB)
+		if err != nil {
+			return sqlbase.InvalidID, err
+		}
+		key := sqlbase.NewTableKey(dbID, scName).Key()
+		if p.extendedEvalCtx.Tracing.KVTracingEnabled() {
+			log.VEventf(ctx, 2, "CPut %s -> %d", key, id)
+		}
+		if err := p.txn.CPut(ctx, key, id, nil); err != nil {
+			return sqlbase.InvalidID, err
+		}
+	}
+	// Make the temporary schema visible to name resolution from now on.
+	p.sessionDataMutator.SetTemporarySchemaName(scName)
+	return id, nil
+}
+
+// resolveTemporarySchemaAlias maps the pg_temp alias to the name of the
+// session's temporary schema. Other names are returned unchanged.
+func (p *planner) resolveTemporarySchemaAlias(scName string) string {
+	if scName != sessiondata.PgTempSchemaName {
+		return scName
+	}
+	if tempSchemaName := p.SessionData().SearchPath.GetTemporarySchemaName(); tempSchemaName != "" {
+		return tempSchemaName
+	}
+	return scName
+}
+
+// checkTemporaryFKReference returns an error if tbl cannot reference target
+// because exactly one of them is temporary. Like in Postgres, permanent
+// tables may not reference temporary tables, as the latter go away with the
+// session, and vice versa.
+func checkTemporaryFKReference(tbl, target *sqlbase.TableDescriptor) error {
+	if tbl.IsTemporary() && !target.IsTemporary() {
+		return pgerror.New(pgcode.InvalidTableDefinition,
+			"constraints on temporary tables may reference only temporary tables")
+	}
+	if !tbl.IsTemporary() && target.IsTemporary() {
+		return pgerror.New(pgcode.InvalidTableDefinition,
+			"constraints on permanent tables may reference only permanent tables")
+	}
+	return nil
+}
+
+// checkTemporaryFKReferences runs checkTemporaryFKReference for all the
+// outbound foreign keys of the table being created. affected contains the
+// descriptors of the referenced tables.
+func checkTemporaryFKReferences(
+	desc *sqlbase.MutableTableDescriptor, affected map[sqlbase.ID]*sqlbase.MutableTableDescriptor,
+) error {
+	for i := range desc.OutboundFKs {
+		target, ok := affected[desc.OutboundFKs[i].ReferencedTableID]
+		if !ok {
+			// Self-references are always allowed.
+			continue
+		}
+		if err := checkTemporaryFKReference(desc.TableDesc(), target.TableDesc()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanupTemporarySchema drops all the objects in the temporary schema scName,
+// in every database, and then removes the schema itself.
+func cleanupTemporarySchema(ctx context.Context, cfg *ExecutorConfig, scName string) error {
+	ie := cfg.InternalExecutor
+	rows, err := ie.Query(
+		ctx, "find-temp-schemas", nil, /* txn */
+		`SELECT "parentID", id FROM system.namespace WHERE name = $1`, scName,
+	)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		dbID := sqlbase.ID(tree.MustBeDInt(row[0]))
+		schemaID := sqlbase.ID(tree.MustBeDInt(row[1]))
+		if err := cleanupTemporarySchemaInDatabase(ctx, cfg, dbID, schemaID, scName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanupTemporarySchemaInDatabase drops the objects in one temporary schema.
+func cleanupTemporarySchemaInDatabase(
+	ctx context.Context, cfg *ExecutorConfig, dbID, schemaID sqlbase.ID, scName string,
+) error {
+	ie := cfg.InternalExecutor
+	var dbName string
+	var views, tables []tree.TableName
+	if err := cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		views, tables = nil, nil
+		// A regular table which happens to be called like a temporary schema
+		// has a descriptor; leave it alone.
+		if err := getDescriptorByID(
+			ctx, txn, schemaID, &sqlbase.TableDescriptor{},
+		); err == nil {
+			return errTemporarySchemaIsTable
+		}
+		dbDesc, err := sqlbase.GetDatabaseDescFromID(ctx, txn, dbID)
+		if err != nil {
+			return err
+		}
+		dbName = dbDesc.Name
+		kvs, err := txn.Scan(
+			ctx, sqlbase.MakeNameMetadataKey(schemaID, ""),
+			sqlbase.MakeNameMetadataKey(schemaID+1, ""), 0, /* maxRows */
+		)
+		if err != nil {
+			return err
+		}
+		for _, kv := range kvs {
+			desc, err := sqlbase.GetTableDescFromID(ctx, txn, sqlbase.ID(kv.ValueInt()))
+			if err != nil {
+				if err == sqlbase.ErrDescriptorNotFound {
+					continue
+				}
+				return err
+			}
+			if desc.Dropped() {
+				continue
+			}
+			tn := tree.MakeTableNameWithSchema(tree.Name(dbName), tree.Name(scName), tree.Name(desc.Name))
+			if desc.IsView() {
+				views = append(views, tn)
+			} else {
+				tables = append(tables, tn)
+			}
+		}
+		return nil
+	}); err != nil {
+		if err == errTemporarySchemaIsTable {
+			return nil
+		}
+		return err
+	}
+
+	// Views go first so that CASCADE has less to do; IF EXISTS takes care of
+	// the objects already dropped through a dependency.
+	for i := range views {
+		if _, err := ie.Exec(
+			ctx, "drop-temp-view", nil, /* txn */
+			fmt.Sprintf("DROP VIEW IF EXISTS %s CASCADE", views[i].String()),
+		); err != nil {
+			return err
+		}
+	}
+	for i := range tables {
+		if _, err := ie.Exec(
+			ctx, "drop-temp-table", nil, /* txn */
+			fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", tables[i].String()),
+		); err != nil {
+			return err
+		}
+	}
+
+	log.VEventf(ctx, 2, "removing temporary schema %s.%s", dbName, scName)
+	return cfg.DB.Del(ctx, sqlbase.NewTableKey(dbID, scName).Key())
+}
+
+var errTemporarySchemaIsTable = errors.New("temporary schema name is used by a table")
+
+// PeriodicallyCleanupTemporaryObjects runs a loop which removes the temporary
+// schemas, and the objects in them, of sessions which are no longer running.
+// Sessions normally clean up after themselves when they end; this loop takes
+// care of the sessions whose gateway node died before it could do so.
+func (s *Server) PeriodicallyCleanupTemporaryObjects(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(temporaryObjectCleanupInterval.Get(&s.cfg.Settings.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			if err := cleanupOrphanedTemporarySchemas(ctx, s.cfg); err != nil {
+				log.Warningf(ctx, "failed to clean up orphaned temporary objects: %v", err)
+			}
+		}
+	})
+}
+
+// cleanupOrphanedTemporarySchemas removes the temporary schemas of all the
+// sessions which are known not to be running anymore.
+func cleanupOrphanedTemporarySchemas(ctx context.Context, cfg *ExecutorConfig) error {
+	if cfg.StatusServer == nil {
+		return nil
+	}
+	rows, err := cfg.InternalExecutor.Query(
+		ctx, "find-all-temp-schemas", nil, /* txn */
+		`SELECT DISTINCT name FROM system.namespace WHERE name LIKE $1`,
+		temporarySchemaNamePrefix+"%",
+	)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	resp, err := cfg.StatusServer.ListSessions(ctx, &serverpb.ListSessionsRequest{})
+	if err != nil {
+		return err
+	}
+	activeSessions := make(map[uint128.Uint128]struct{}, len(resp.Sessions))
+	for i := range resp.Sessions {
+		activeSessions[BytesToClusterWideID(resp.Sessions[i].ID).Uint128] = struct{}{}
+	}
+	// The sessions of nodes which did not respond are only orphaned if the node
+	// is dead; otherwise they may well still be running.
+	unreachable := make(map[roachpb.NodeID]struct{}, len(resp.Errors))
+	for i := range resp.Errors {
+		unreachable[resp.Errors[i].NodeID] = struct{}{}
+	}
+	isLive := func(nodeID roachpb.NodeID) bool {
+		var liveness storagepb.Liveness
+		if err := cfg.Gossip.GetInfoProto(gossip.MakeNodeLivenessKey(nodeID), &liveness); err != nil {
+			// Err on the side of not removing anything.
+			return true
+		}
+		return liveness.IsLive(cfg.Clock.Now(), cfg.Clock.MaxOffset())
+	}
+
+	for _, row := range rows {
+		scName := string(tree.MustBeDString(row[0]))
+		sessionID, ok := temporarySchemaSessionID(scName)
+		if !ok {
+			continue
+		}
+		if _, ok := activeSessions[sessionID.Uint128]; ok {
+			continue
+		}
+		nodeID := roachpb.NodeID(sessionID.GetNodeID())
+		if _, ok := unreachable[nodeID]; ok && isLive(nodeID) {
+			continue
+		}
+		log.Infof(ctx, "cleaning up orphaned temporary schema %s", scName)
+		if err := cleanupTemporarySchema(ctx, cfg, scName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// temporarySchema describes a temporary schema in some database.
+type temporarySchema struct {
+	dbID sqlbase.ID
+	id   sqlbase.ID
+	name string
+}
+
+// getTemporarySchemas returns all the temporary schemas in the cluster, as
+// visible to the planner's transaction.
+func (p *planner) getTemporarySchemas(ctx context.Context) ([]temporarySchema, error) {
+	rows, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.Query(
+		ctx, "read-temp-schemas", p.txn,
+		`SELECT "parentID", id, name FROM system.namespace WHERE name LIKE $1`,
+		temporarySchemaNamePrefix+"%",
+	)
+	if err != nil {
+		return nil, err
+	}
+	var res []temporarySchema
+	for _, row := range rows {
+		name := string(tree.MustBeDString(row[2]))
+		if !isTemporarySchemaName(name) {
+			continue
+		}
+		res = append(res, temporarySchema{
+			dbID: sqlbase.ID(tree.MustBeDInt(row[0])),
+			id:   sqlbase.ID(tree.MustBeDInt(row[1])),
+			name: name,
+		})
+	}
+	return res, nil
+}
@@ -151,6 +151,7 @@ var familyToOid = map[Family]oid.Oid{
 	JsonFamily:           oid.T_jsonb,
 	TupleFamily:          oid.T_record,
 	BitFamily:            oid.T_bit,
+	EnumFamily:           oid.T_anyenum,
 	AnyFamily:            oid.T_anyelement,
 }
 
@@ -186,6 +187,10 @@ func calcArrayOid(elemTyp *T) oid.Oid {
 		// so return 0 for that case (since there's no T__unknown). This is what
 		// previous versions of CRDB returned for this case.
 		return unknownArrayOid
+
+	case EnumFamily:
+		// User-defined types don't have array types of their own yet.
+		return oid.T_anyarray
 	}
 
 	// Map the OID of the array element type to the corresponding array OID.
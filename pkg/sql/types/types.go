@@ -333,6 +333,12 @@ var (
 	AnyTuple = &T{InternalType: InternalType{
 		Family: TupleFamily, TupleContents: []T{*Any}, Oid: oid.T_record, Locale: &emptyLocale}}
 
+	// AnyEnum is a special type used only during static analysis as a wildcard
+	// type that matches any user-defined ENUM type. Execution-time values should
+	// never have this type.
+	AnyEnum = &T{InternalType: InternalType{
+		Family: EnumFamily, Oid: oid.T_anyenum, Locale: &emptyLocale}}
+
 	// AnyCollatedString is a special type used only during static analysis as a
 	// wildcard type that matches a collated string with any locale. Execution-
 	// time values should never have this type.
@@ -363,6 +369,17 @@ var (
 		Family: ArrayFamily, Oid: oid.T_int2vector, ArrayContents: Int2, Locale: &emptyLocale}}
 )
 
+// UserDefinedTypeOIDOffset is added to the descriptor ID of a user-defined
+// type to compute its OID. This keeps the OIDs of user-defined types clear of
+// the OIDs of the types predefined by Postgres.
+const UserDefinedTypeOIDOffset = 100000
+
+// UserDefinedTypeOIDToID returns the descriptor ID of the user-defined type
+// with the given OID.
+func UserDefinedTypeOIDToID(o oid.Oid) uint32 {
+	return uint32(o) - UserDefinedTypeOIDOffset
+}
+
 // Unexported wrapper types.
 var (
 	// typeBit is the SQL BIT type. It is not exported to avoid confusion with
@@ -660,6 +677,35 @@ func MakeTimestampTZ(precision int32) *T {
 	panic(errors.AssertionFailedf("precision %d is not currently supported", precision))
 }
 
+// MakeEnum constructs a new instance of an EnumFamily type for the
+// user-defined type with the given descriptor ID, name and members.
+func MakeEnum(typeID uint32, meta EnumMetadata) *T {
+	return &T{InternalType: InternalType{
+		Family:   EnumFamily,
+		Oid:      oid.Oid(typeID + UserDefinedTypeOIDOffset),
+		Locale:   &emptyLocale,
+		EnumData: &meta,
+	}}
+}
+
+// MakeUserDefinedTypeReference constructs a placeholder for a user-defined
+// type that is only known by name. The parser produces such placeholders for
+// type names it does not know about. They need to be resolved against the
+// type descriptors before use; see IsUnresolvedReference.
+func MakeUserDefinedTypeReference(name string) *T {
+	return &T{InternalType: InternalType{
+		Family:   EnumFamily,
+		Locale:   &emptyLocale,
+		EnumData: &EnumMetadata{Name: name},
+	}}
+}
+
+// IsUnresolvedReference returns true if the type is a placeholder constructed
+// by MakeUserDefinedTypeReference.
+func (t *T) IsUnresolvedReference() bool {
+	return t.Family() == EnumFamily && t.Oid() == 0 && t.InternalType.EnumData != nil
+}
+
 // MakeArray constructs a new instance of an ArrayFamily type with the given
 // element type (which may itself be an ArrayFamily type).
 func MakeArray(typ *T) *T {
@@ -792,6 +838,40 @@ func (t *T) TupleContents() []T {
 	return t.InternalType.TupleContents
 }
 
+// TypeName returns the name of a user-defined type. It is empty for all other
+// types.
+func (t *T) TypeName() string {
+	if t.InternalType.EnumData == nil {
+		return ""
+	}
+	return t.InternalType.EnumData.Name
+}
+
+// EnumPhysicalRepresentations returns the encoded forms of the members of an
+// ENUM type, in sort order. It is nil for all other types.
+func (t *T) EnumPhysicalRepresentations() [][]byte {
+	if t.InternalType.EnumData == nil {
+		return nil
+	}
+	return t.InternalType.EnumData.PhysicalRepresentations
+}
+
+// EnumLogicalRepresentations returns the labels of the members of an ENUM
+// type, in sort order. It is nil for all other types.
+func (t *T) EnumLogicalRepresentations() []string {
+	if t.InternalType.EnumData == nil {
+		return nil
+	}
+	return t.InternalType.EnumData.LogicalRepresentations
+}
+
+// EnumMemberIsReadOnly returns true if the i-th member of an ENUM type is
+// still being added, and so cannot be written yet.
+func (t *T) EnumMemberIsReadOnly(i int) bool {
+	readOnly := t.InternalType.EnumData.ReadOnly
+	return i < len(readOnly) && readOnly[i]
+}
+
 // TupleLabels returns a slice containing the labels of each tuple field. This
 // is nil for types not in the TupleFamily, or if the tuple type does not
 // specify labels.
@@ -830,6 +910,11 @@ func (t *T) Name() string {
 		return "date"
 	case DecimalFamily:
 		return "decimal"
+	case EnumFamily:
+		if t.Oid() == oid.T_anyenum {
+			return "anyenum"
+		}
+		return t.TypeName()
 	case FloatFamily:
 		switch t.Width() {
 		case 64:
@@ -903,6 +988,9 @@ func (t *T) Name() string {
 //   int4[]       _int4
 //
 func (t *T) PGName() string {
+	if t.Family() == EnumFamily && t.Oid() != oid.T_anyenum {
+		return t.TypeName()
+	}
 	name, ok := oid.TypeName[t.Oid()]
 	if ok {
 		return strings.ToLower(name)
@@ -969,6 +1057,8 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 		return "bytea"
 	case DateFamily:
 		return "date"
+	case EnumFamily:
+		return t.Name()
 	case DecimalFamily:
 		if !haveTypmod || typmod <= 0 {
 			return "numeric"
@@ -1098,9 +1188,13 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 // This is different from SQLString() in that it must report SQL standard names
 // that are compatible with PostgreSQL client expectations.
 func (t *T) InformationSchemaName() string {
-	// This is the same as SQLStandardName, except for the case of arrays.
-	if t.Family() == ArrayFamily {
+	// This is the same as SQLStandardName, except for the case of arrays and
+	// user-defined types.
+	switch t.Family() {
+	case ArrayFamily:
 		return "ARRAY"
+	case EnumFamily:
+		return "USER-DEFINED"
 	}
 	return t.SQLStandardName()
 }
@@ -1154,6 +1248,12 @@ func (t *T) SQLString() string {
 	case JsonFamily:
 		// Only binary JSON is currently supported.
 		return "JSONB"
+	case EnumFamily:
+		if t.Oid() != oid.T_anyenum {
+			var buf bytes.Buffer
+			lex.EncodeRestrictedSQLIdent(&buf, t.TypeName(), lex.EncNoFlags)
+			return buf.String()
+		}
 	case TimestampFamily, TimestampTZFamily:
 		if t.Precision() != -1 {
 			return fmt.Sprintf("%s(%d)", strings.ToUpper(t.Name()), t.Precision())
@@ -1228,6 +1328,16 @@ func (t *T) Equivalent(other *T) bool {
 		if !t.ArrayContents().Equivalent(other.ArrayContents()) {
 			return false
 		}
+
+	case EnumFamily:
+		// AnyEnum is equivalent to any other ENUM type. Otherwise, two ENUM
+		// types are only equivalent if they are the same user-defined type.
+		if t.Oid() == oid.T_anyenum || other.Oid() == oid.T_anyenum {
+			return true
+		}
+		if t.Oid() != other.Oid() {
+			return false
+		}
 	}
 
 	return true
@@ -1315,9 +1425,45 @@ func (t *InternalType) Identical(other *InternalType) bool {
 			return false
 		}
 	}
+	if t.EnumData != nil && other.EnumData != nil {
+		if !t.EnumData.identical(other.EnumData) {
+			return false
+		}
+	} else if t.EnumData != nil {
+		return false
+	} else if other.EnumData != nil {
+		return false
+	}
 	return t.Oid == other.Oid
 }
 
+func (m *EnumMetadata) identical(other *EnumMetadata) bool {
+	if m.Name != other.Name {
+		return false
+	}
+	if len(m.PhysicalRepresentations) != len(other.PhysicalRepresentations) ||
+		len(m.LogicalRepresentations) != len(other.LogicalRepresentations) ||
+		len(m.ReadOnly) != len(other.ReadOnly) {
+		return false
+	}
+	for i := range m.PhysicalRepresentations {
+		if !bytes.Equal(m.PhysicalRepresentations[i], other.PhysicalRepresentations[i]) {
+			return false
+		}
+	}
+	for i := range m.LogicalRepresentations {
+		if m.LogicalRepresentations[i] != other.LogicalRepresentations[i] {
+			return false
+		}
+	}
+	for i := range m.ReadOnly {
+		if m.ReadOnly[i] != other.ReadOnly[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // Unmarshal deserializes a type from the given byte representation using gogo
 // protobuf serialization rules. It is backwards-compatible with formats used
 // by older versions of CRDB.
@@ -1669,6 +1815,8 @@ func (t *T) IsAmbiguous() bool {
 		return true
 	case CollatedStringFamily:
 		return t.Locale() == ""
+	case EnumFamily:
+		return t.Oid() == oid.T_anyenum
 	case TupleFamily:
 		if len(t.TupleContents()) == 0 {
 			return true
@@ -1703,6 +1851,8 @@ func IsValidArrayElementType(t *T) (valid bool, issueNum int) {
 	switch t.Family() {
 	case JsonFamily:
 		return false, 23468
+	case EnumFamily:
+		return false, 24873
 	default:
 		return true, 0
 	}
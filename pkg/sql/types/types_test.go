@@ -133,6 +133,20 @@ func TestTypes(t *testing.T) {
 			Family: DecimalFamily, Oid: oid.T_numeric, Precision: 10, Width: 3, Locale: &emptyLocale}}},
 		{MakeDecimal(10, 3), MakeScalar(DecimalFamily, oid.T_numeric, 10, 3, emptyLocale)},
 
+		// ENUM
+		{MakeEnum(52, EnumMetadata{
+			Name:                    "status",
+			PhysicalRepresentations: [][]byte{{0x40}, {0x80}},
+			LogicalRepresentations:  []string{"open", "closed"},
+			ReadOnly:                []bool{false, true},
+		}), &T{InternalType: InternalType{
+			Family: EnumFamily, Oid: 100052, Locale: &emptyLocale, EnumData: &EnumMetadata{
+				Name:                    "status",
+				PhysicalRepresentations: [][]byte{{0x40}, {0x80}},
+				LogicalRepresentations:  []string{"open", "closed"},
+				ReadOnly:                []bool{false, true},
+			}}}},
+
 		// FLOAT
 		{Float, &T{InternalType: InternalType{
 			Family: FloatFamily, Width: 64, Oid: oid.T_float8, Locale: &emptyLocale}}},
@@ -339,6 +353,14 @@ func TestEquivalent(t *testing.T) {
 		{Any, MakeDecimal(10, 0), true},
 		{Decimal, Float, false},
 
+		// ENUM
+		{MakeEnum(52, EnumMetadata{Name: "a"}), MakeEnum(52, EnumMetadata{Name: "a",
+			LogicalRepresentations: []string{"x"}, PhysicalRepresentations: [][]byte{{0x80}}}), true},
+		{MakeEnum(52, EnumMetadata{Name: "a"}), AnyEnum, true},
+		{AnyEnum, MakeEnum(53, EnumMetadata{Name: "b"}), true},
+		{MakeEnum(52, EnumMetadata{Name: "a"}), MakeEnum(53, EnumMetadata{Name: "b"}), false},
+		{MakeEnum(52, EnumMetadata{Name: "a"}), String, false},
+
 		// INT
 		{Int2, Int4, true},
 		{Int4, Int, true},
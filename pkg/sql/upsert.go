@@ -806,7 +806,9 @@ func upsertExprsAndIndex(
 	// General case: INSERT with an ON CONFLICT clause.
 
 	indexMatch := func(index sqlbase.IndexDescriptor) bool {
-		if !index.Unique {
+		// Partial indexes only guarantee uniqueness among the rows which satisfy
+		// their predicate, so they can't arbitrate conflicts.
+		if !index.Unique || index.IsPartial() {
 			return false
 		}
 		if len(index.ColumnNames) != len(onConflict.Columns) {
@@ -186,6 +186,12 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 			if n.specifiedIndex != nil {
 				v.observer.attr(name, "hint", fmt.Sprintf("force index @%s", n.specifiedIndex.Name))
 			}
+			if n.lockingStrength != tree.ForNone {
+				v.observer.attr(name, "locking strength", n.lockingStrength.String())
+			}
+			if n.lockingWaitPolicy != tree.LockWaitBlock {
+				v.observer.attr(name, "locking wait policy", n.lockingWaitPolicy.String())
+			}
 		}
 		if v.observer.spans != nil {
 			v.observer.spans(name, "spans", n.index, n.spans)
@@ -627,6 +633,9 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 	case *controlJobsNode:
 		n.rows = v.visit(n.rows)
 
+	case *controlSchedulesNode:
+		n.rows = v.visit(n.rows)
+
 	case *setZoneConfigNode:
 		if v.observer.expr != nil {
 			v.metadataExpr(name, "yaml", -1, n.yamlConfig)
@@ -753,6 +762,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&alterIndexNode{}):           "alter index",
 	reflect.TypeOf(&alterSequenceNode{}):        "alter sequence",
 	reflect.TypeOf(&alterTableNode{}):           "alter table",
+	reflect.TypeOf(&alterTypeNode{}):            "alter type",
 	reflect.TypeOf(&alterUserSetPasswordNode{}): "alter user",
 	reflect.TypeOf(&applyJoinNode{}):            "apply-join",
 	reflect.TypeOf(&bufferNode{}):               "buffer node",
@@ -763,11 +773,13 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&commentOnDatabaseNode{}):    "comment on database",
 	reflect.TypeOf(&commentOnTableNode{}):       "comment on table",
 	reflect.TypeOf(&controlJobsNode{}):          "control jobs",
+	reflect.TypeOf(&controlSchedulesNode{}):     "control schedules",
 	reflect.TypeOf(&createDatabaseNode{}):       "create database",
 	reflect.TypeOf(&createIndexNode{}):          "create index",
 	reflect.TypeOf(&createSequenceNode{}):       "create sequence",
 	reflect.TypeOf(&createStatsNode{}):          "create statistics",
 	reflect.TypeOf(&createTableNode{}):          "create table",
+	reflect.TypeOf(&createTypeNode{}):           "create type",
 	reflect.TypeOf(&CreateUserNode{}):           "create user/role",
 	reflect.TypeOf(&createViewNode{}):           "create view",
 	reflect.TypeOf(&delayedNode{}):              "virtual table",
@@ -778,6 +790,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&dropIndexNode{}):            "drop index",
 	reflect.TypeOf(&dropSequenceNode{}):         "drop sequence",
 	reflect.TypeOf(&dropTableNode{}):            "drop table",
+	reflect.TypeOf(&dropTypeNode{}):             "drop type",
 	reflect.TypeOf(&DropUserNode{}):             "drop user/role",
 	reflect.TypeOf(&dropViewNode{}):             "drop view",
 	reflect.TypeOf(&errorIfRowsNode{}):          "error if rows",
@@ -206,6 +206,20 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		name:   "propagate the ts purge interval to the new setting names",
 		workFn: retireOldTsPurgeIntervalSettings,
 	},
+	{
+		// Introduced in v19.2.
+		name:                "create system.scheduled_jobs table",
+		workFn:              createScheduledJobsTable,
+		includedInBootstrap: true,
+		newDescriptorIDs:    staticIDs(keys.ScheduledJobsTableID),
+	},
+	{
+		// Introduced in v19.2.
+		name:                "create system.protected_ts_records table",
+		workFn:              createProtectedTSRecordsTable,
+		includedInBootstrap: true,
+		newDescriptorIDs:    staticIDs(keys.ProtectedTSRecordsID),
+	},
 }
 
 func staticIDs(ids ...sqlbase.ID) func(ctx context.Context, db db) ([]sqlbase.ID, error) {
@@ -550,6 +564,14 @@ func createCommentTable(ctx context.Context, r runner) error {
 	return createSystemTable(ctx, r, sqlbase.CommentsTable)
 }
 
+func createScheduledJobsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.ScheduledJobsTable)
+}
+
+func createProtectedTSRecordsTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.ProtectedTSRecordsTable)
+}
+
 var reportingOptOut = envutil.EnvOrDefaultBool("COCKROACH_SKIP_ENABLING_DIAGNOSTIC_REPORTING", false)
 
 func runStmtAsRootWithRetry(
@@ -55,6 +55,10 @@ const (
 	removeDeadReplicaPriority             float64 = 1000
 	removeDecommissioningReplicaPriority  float64 = 200
 	removeExtraReplicaPriority            float64 = 100
+	removeDeadNonVoterPriority            float64 = 80
+	removeDecommissioningNonVoterPriority float64 = 70
+	addMissingNonVoterPriority            float64 = 60
+	removeExtraNonVoterPriority           float64 = 50
 )
 
 // MinLeaseTransferStatsDuration configures the minimum amount of time a
@@ -103,6 +107,8 @@ const (
 	AllocatorRemoveLearner
 	AllocatorConsiderRebalance
 	AllocatorRangeUnavailable
+	AllocatorAddNonVoter
+	AllocatorRemoveNonVoter
 )
 
 var allocatorActionNames = map[AllocatorAction]string{
@@ -114,6 +120,8 @@ var allocatorActionNames = map[AllocatorAction]string{
 	AllocatorRemoveLearner:         "remove learner",
 	AllocatorConsiderRebalance:     "consider rebalance",
 	AllocatorRangeUnavailable:      "range unavailable",
+	AllocatorAddNonVoter:           "add non-voter",
+	AllocatorRemoveNonVoter:        "remove non-voter",
 }
 
 func (a AllocatorAction) String() string {
@@ -339,8 +347,67 @@ func (a *Allocator) ComputeAction(
 		// removeLearnerReplicaPriority as the highest priority.
 		return AllocatorRemoveLearner, removeLearnerReplicaPriority
 	}
-	// computeAction expects to operate only on voters.
-	return a.computeAction(ctx, zone, desc.RangeID, desc.Replicas().Voters())
+	// computeAction expects to operate only on voters. The voters are repaired
+	// before the non-voting replicas are considered at all.
+	voterReplicas := desc.Replicas().Voters()
+	action, priority := a.computeAction(ctx, zone.VoterConfig(), desc.RangeID, voterReplicas)
+	if action != AllocatorConsiderRebalance {
+		return action, priority
+	}
+	return a.computeNonVoterAction(
+		ctx, zone, desc.RangeID, voterReplicas, desc.Replicas().NonVoters())
+}
+
+// computeNonVoterAction determines the operation needed to repair the
+// non-voting replicas of a range whose voting replicas need no repair.
+func (a *Allocator) computeNonVoterAction(
+	ctx context.Context,
+	zone *config.ZoneConfig,
+	rangeID roachpb.RangeID,
+	voterReplicas []roachpb.ReplicaDescriptor,
+	nonVoterReplicas []roachpb.ReplicaDescriptor,
+) (AllocatorAction, float64) {
+	have := len(nonVoterReplicas)
+	need := int(zone.GetNumNonVoters())
+	// A node holds at most one replica of a range, so there can't be more
+	// non-voters than nodes without a voter.
+	if max := a.storePool.ClusterNodeCount() - len(voterReplicas); need > max {
+		need = max
+	}
+	if need < 0 {
+		need = 0
+	}
+
+	// Non-voters don't participate in quorum, so unlike voters, dead and
+	// decommissioning ones are removed before their replacements are added.
+	if _, dead := a.storePool.liveAndDeadReplicas(rangeID, nonVoterReplicas); len(dead) > 0 {
+		priority := removeDeadNonVoterPriority
+		log.VEventf(ctx, 3, "AllocatorRemoveNonVoter - dead=%d, priority=%.2f", len(dead), priority)
+		return AllocatorRemoveNonVoter, priority
+	}
+	if decommissioning := a.storePool.decommissioningReplicas(
+		rangeID, nonVoterReplicas); len(decommissioning) > 0 {
+		priority := removeDecommissioningNonVoterPriority
+		log.VEventf(ctx, 3, "AllocatorRemoveNonVoter - num_decommissioning=%d, priority=%.2f",
+			len(decommissioning), priority)
+		return AllocatorRemoveNonVoter, priority
+	}
+
+	if have < need {
+		priority := addMissingNonVoterPriority
+		log.VEventf(ctx, 3, "AllocatorAddNonVoter - missing non-voter need=%d, have=%d, priority=%.2f",
+			need, have, priority)
+		return AllocatorAddNonVoter, priority
+	}
+	if have > need {
+		priority := removeExtraNonVoterPriority
+		log.VEventf(ctx, 3, "AllocatorRemoveNonVoter - need=%d, have=%d, priority=%.2f",
+			need, have, priority)
+		return AllocatorRemoveNonVoter, priority
+	}
+
+	// Nothing needs to be done, but we may want to rebalance.
+	return AllocatorConsiderRebalance, 0
 }
 
 func (a *Allocator) computeAction(
@@ -450,8 +517,35 @@ func (a *Allocator) AllocateTarget(
 	zone *config.ZoneConfig,
 	rangeID roachpb.RangeID,
 	existingReplicas []roachpb.ReplicaDescriptor,
+) (*roachpb.StoreDescriptor, string, error) {
+	return a.allocateTarget(ctx, zone, rangeID, existingReplicas, nil /* excludedReplicas */)
+}
+
+// AllocateVoterTarget is like AllocateTarget, but for a voting replica of a
+// range which may also have non-voting replicas. The zone is the voters' view
+// of the range's zone config (see ZoneConfig.VoterConfig) and
+// existingReplicas are the voters. Nodes holding the non-voting replicas are
+// ruled out as targets as well, without them counting towards the
+// constraints and diversity of the voters.
+func (a *Allocator) AllocateVoterTarget(
+	ctx context.Context,
+	zone *config.ZoneConfig,
+	rangeID roachpb.RangeID,
+	existingReplicas []roachpb.ReplicaDescriptor,
+	nonVoterReplicas []roachpb.ReplicaDescriptor,
+) (*roachpb.StoreDescriptor, string, error) {
+	return a.allocateTarget(ctx, zone, rangeID, existingReplicas, nonVoterReplicas)
+}
+
+func (a *Allocator) allocateTarget(
+	ctx context.Context,
+	zone *config.ZoneConfig,
+	rangeID roachpb.RangeID,
+	existingReplicas []roachpb.ReplicaDescriptor,
+	excludedReplicas []roachpb.ReplicaDescriptor,
 ) (*roachpb.StoreDescriptor, string, error) {
 	sl, aliveStoreCount, throttled := a.storePool.getStoreList(rangeID, storeFilterThrottled)
+	sl = sl.excludeNodes(excludedReplicas)
 
 	target, details := a.allocateTargetFromList(
 		ctx, sl, zone, existingReplicas, a.scorerOptions())
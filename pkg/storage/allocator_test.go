@@ -4812,6 +4812,92 @@ func TestAllocatorRemoveLearner(t *testing.T) {
 	require.Equal(t, AllocatorRemoveLearner, action)
 }
 
+func TestAllocatorComputeActionNonVoters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	zone := config.ZoneConfig{
+		NumReplicas: proto.Int32(5),
+		NumVoters:   proto.Int32(3),
+	}
+	// makeDesc returns a descriptor with a voter on each of the voters stores
+	// and a non-voter on each of the nonVoters stores.
+	makeDesc := func(voters, nonVoters []roachpb.StoreID) roachpb.RangeDescriptor {
+		var desc roachpb.RangeDescriptor
+		for _, storeID := range voters {
+			desc.InternalReplicas = append(desc.InternalReplicas, roachpb.ReplicaDescriptor{
+				StoreID:   storeID,
+				NodeID:    roachpb.NodeID(storeID),
+				ReplicaID: roachpb.ReplicaID(storeID),
+			})
+		}
+		for _, storeID := range nonVoters {
+			desc.InternalReplicas = append(desc.InternalReplicas, roachpb.ReplicaDescriptor{
+				StoreID:   storeID,
+				NodeID:    roachpb.NodeID(storeID),
+				ReplicaID: roachpb.ReplicaID(storeID),
+				Type:      roachpb.ReplicaTypeNonVoter(),
+			})
+		}
+		return desc
+	}
+
+	testCases := []struct {
+		desc            roachpb.RangeDescriptor
+		live            []roachpb.StoreID
+		dead            []roachpb.StoreID
+		decommissioning []roachpb.StoreID
+		expectedAction  AllocatorAction
+	}{
+		// The voters are repaired first.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2}, []roachpb.StoreID{4, 5}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+			expectedAction: AllocatorAdd,
+		},
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, nil),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+			expectedAction: AllocatorAddNonVoter,
+		},
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5},
+			expectedAction: AllocatorConsiderRebalance,
+		},
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5, 6}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 5, 6},
+			expectedAction: AllocatorRemoveNonVoter,
+		},
+		// Dead and decommissioning non-voters are removed before they're
+		// replaced.
+		{
+			desc:           makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5}),
+			live:           []roachpb.StoreID{1, 2, 3, 4, 6},
+			dead:           []roachpb.StoreID{5},
+			expectedAction: AllocatorRemoveNonVoter,
+		},
+		{
+			desc:            makeDesc([]roachpb.StoreID{1, 2, 3}, []roachpb.StoreID{4, 5}),
+			live:            []roachpb.StoreID{1, 2, 3, 4, 6},
+			decommissioning: []roachpb.StoreID{5},
+			expectedAction:  AllocatorRemoveNonVoter,
+		},
+	}
+
+	stopper, _, sp, a, _ := createTestAllocator(10, false /* deterministic */)
+	ctx := context.Background()
+	defer stopper.Stop(ctx)
+
+	for i, tcase := range testCases {
+		mockStorePool(sp, tcase.live, nil, tcase.dead, tcase.decommissioning, nil)
+		action, _ := a.ComputeAction(ctx, &zone, &tcase.desc)
+		if tcase.expectedAction != action {
+			t.Errorf("Test case %d expected action %s, got action %s", i, tcase.expectedAction, action)
+		}
+	}
+}
+
 func TestAllocatorComputeActionDynamicNumReplicas(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
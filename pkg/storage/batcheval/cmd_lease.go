@@ -42,12 +42,12 @@ func newFailedLeaseTrigger(isTransfer bool) result.Result {
 	return trigger
 }
 
-func checkNotLearnerReplica(rec EvalContext) error {
+func checkCanReceiveLease(rec EvalContext) error {
 	repDesc, ok := rec.Desc().GetReplicaDescriptor(rec.StoreID())
 	if !ok {
 		return errors.AssertionFailedf(
 			`could not find replica for store %s in %s`, rec.StoreID(), rec.Desc())
-	} else if t := repDesc.GetType(); t == roachpb.ReplicaType_LEARNER {
+	} else if t := repDesc.GetType(); t != roachpb.ReplicaType_VOTER {
 		return errors.Errorf(`cannot transfer lease to replica of type %s`, t)
 	}
 	return nil
@@ -112,6 +112,11 @@ func (m *mockEvalCtx) CanCreateTxnRecord(
 ) (bool, hlc.Timestamp, roachpb.TransactionAbortedReason) {
 	return m.canCreateTxnFn()
 }
+func (m *mockEvalCtx) GetConflictingLockedKeys(
+	roachpb.Span, *enginepb.TxnMeta, roachpb.KeyLockingStrength,
+) []roachpb.Key {
+	panic("unimplemented")
+}
 func (m *mockEvalCtx) GetGCThreshold() hlc.Timestamp {
 	return m.gcThreshold
 }
@@ -38,39 +38,51 @@ func ReverseScan(
 	var intents []roachpb.Intent
 	var resumeSpan *roachpb.Span
 
-	switch args.ScanFormat {
-	case roachpb.BATCH_RESPONSE:
-		var kvData []byte
-		var numKvs int64
-		kvData, numKvs, resumeSpan, intents, err = engine.MVCCScanToBytes(
-			ctx, batch, args.Key, args.EndKey, cArgs.MaxKeys, h.Timestamp,
-			engine.MVCCScanOptions{
-				Inconsistent:   h.ReadConsistency != roachpb.CONSISTENT,
-				IgnoreSequence: shouldIgnoreSequenceNums(),
-				Txn:            h.Txn,
-				Reverse:        true,
-			})
-		if err != nil {
-			return result.Result{}, err
-		}
-		reply.NumKeys = numKvs
-		reply.BatchResponses = [][]byte{kvData}
-	case roachpb.KEY_VALUES:
-		var rows []roachpb.KeyValue
-		rows, resumeSpan, intents, err = engine.MVCCScan(
-			ctx, batch, args.Key, args.EndKey, cArgs.MaxKeys, h.Timestamp, engine.MVCCScanOptions{
-				Inconsistent:   h.ReadConsistency != roachpb.CONSISTENT,
-				IgnoreSequence: shouldIgnoreSequenceNums(),
-				Txn:            h.Txn,
-				Reverse:        true,
-			})
-		if err != nil {
-			return result.Result{}, err
+	scan := func(span roachpb.Span, maxKeys int64) (int64, *roachpb.Span, error) {
+		switch args.ScanFormat {
+		case roachpb.BATCH_RESPONSE:
+			kvData, numKvs, resumeSpan, spanIntents, err := engine.MVCCScanToBytes(
+				ctx, batch, span.Key, span.EndKey, maxKeys, h.Timestamp,
+				engine.MVCCScanOptions{
+					Inconsistent:   h.ReadConsistency != roachpb.CONSISTENT,
+					IgnoreSequence: shouldIgnoreSequenceNums(),
+					Txn:            h.Txn,
+					Reverse:        true,
+				})
+			if err != nil {
+				return 0, nil, err
+			}
+			reply.NumKeys += numKvs
+			reply.BatchResponses = append(reply.BatchResponses, kvData)
+			intents = append(intents, spanIntents...)
+			return numKvs, resumeSpan, nil
+		case roachpb.KEY_VALUES:
+			rows, resumeSpan, spanIntents, err := engine.MVCCScan(
+				ctx, batch, span.Key, span.EndKey, maxKeys, h.Timestamp, engine.MVCCScanOptions{
+					Inconsistent:   h.ReadConsistency != roachpb.CONSISTENT,
+					IgnoreSequence: shouldIgnoreSequenceNums(),
+					Txn:            h.Txn,
+					Reverse:        true,
+				})
+			if err != nil {
+				return 0, nil, err
+			}
+			reply.NumKeys += int64(len(rows))
+			reply.Rows = append(reply.Rows, rows...)
+			intents = append(intents, spanIntents...)
+			return int64(len(rows)), resumeSpan, nil
+		default:
+			panic(fmt.Sprintf("Unknown scanFormat %d", args.ScanFormat))
 		}
-		reply.NumKeys = int64(len(rows))
-		reply.Rows = rows
-	default:
-		panic(fmt.Sprintf("Unknown scanFormat %d", args.ScanFormat))
+	}
+
+	if args.KeyLocking != roachpb.KEY_LOCKING_NONE && args.LockWaitPolicy == roachpb.LOCK_WAIT_SKIP {
+		resumeSpan, err = scanSkipLocked(cArgs, args.Span(), args.KeyLocking, true /* reverse */, scan)
+	} else {
+		_, resumeSpan, err = scan(args.Span(), cArgs.MaxKeys)
+	}
+	if err != nil {
+		return result.Result{}, err
 	}
 
 	if resumeSpan != nil {
@@ -80,6 +92,18 @@ func ReverseScan(
 
 	if h.ReadConsistency == roachpb.READ_UNCOMMITTED {
 		reply.IntentRows, err = CollectIntentRows(ctx, batch, cArgs, intents)
+		if err != nil {
+			return result.FromIntents(intents, args), err
+		}
+	}
+
+	res := result.FromIntents(intents, args)
+	locks, err := acquireLocksOnScannedKeys(h.Txn, args.KeyLocking, reply.BatchResponses, reply.Rows)
+	if err != nil {
+		return result.Result{}, err
+	}
+	if err := res.MergeAndDestroy(locks); err != nil {
+		return result.Result{}, err
 	}
-	return result.FromIntents(intents, args), err
+	return res, nil
 }
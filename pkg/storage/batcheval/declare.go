@@ -20,10 +20,12 @@ import (
 )
 
 // DefaultDeclareKeys is the default implementation of Command.DeclareKeys.
+// Locking reads declare read-write spans so that they are serialized with
+// writes to the keys that they acquire locks on.
 func DefaultDeclareKeys(
 	desc *roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *spanset.SpanSet,
 ) {
-	if roachpb.IsReadOnly(req) {
+	if roachpb.IsReadOnly(req) && !roachpb.IsLocking(req) {
 		spans.Add(spanset.SpanReadOnly, req.Header().Span())
 	} else {
 		spans.Add(spanset.SpanReadWrite, req.Header().Span())
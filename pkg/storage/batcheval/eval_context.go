@@ -92,4 +92,12 @@ type EvalContext interface {
 	GetGCThreshold() hlc.Timestamp
 	GetLastReplicaGCTimestamp(context.Context) (hlc.Timestamp, error)
 	GetLease() (roachpb.Lease, roachpb.Lease)
+
+	// GetConflictingLockedKeys returns the keys in the span that are locked by
+	// transactions other than txn with unreplicated locks that conflict with an
+	// access of the provided strength. It is used by locking reads with a SKIP
+	// LOCKED wait policy to skip over locked keys.
+	GetConflictingLockedKeys(
+		span roachpb.Span, txn *enginepb.TxnMeta, str roachpb.KeyLockingStrength,
+	) []roachpb.Key
 }
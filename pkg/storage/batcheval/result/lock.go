@@ -0,0 +1,37 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package result
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+)
+
+// LockAcquisition contains a transaction and the keys on which it acquired
+// unreplicated locks of the given strength.
+type LockAcquisition struct {
+	Txn      enginepb.TxnMeta
+	Strength roachpb.KeyLockingStrength
+	Keys     []roachpb.Key
+}
+
+// FromAcquiredLocks creates a Result communicating that the transaction
+// acquired locks of the given strength on the keys.
+func FromAcquiredLocks(
+	txn *roachpb.Transaction, str roachpb.KeyLockingStrength, keys []roachpb.Key,
+) Result {
+	var pd Result
+	if txn == nil || str == roachpb.KEY_LOCKING_NONE || len(keys) == 0 {
+		return pd
+	}
+	pd.Local.AcquiredLocks = &[]LockAcquisition{{Txn: txn.TxnMeta, Strength: str, Keys: keys}}
+	return pd
+}
@@ -44,6 +44,10 @@ type LocalResult struct {
 	// commit fails, or we may accidentally make uncommitted values
 	// live.
 	EndTxns *[]EndTxnIntents
+	// AcquiredLocks stores the unreplicated locks acquired by locking reads.
+	// They should be added to the leaseholder's lock table once the command
+	// has been evaluated.
+	AcquiredLocks *[]LockAcquisition
 	// Metrics contains counters which are to be passed to the
 	// metrics subsystem.
 	Metrics *Metrics
@@ -114,6 +118,20 @@ func (lResult *LocalResult) DetachIntents() []IntentsWithArg {
 	return r
 }
 
+// DetachAcquiredLocks returns (and removes) the locks acquired by locking
+// reads from the local result.
+func (lResult *LocalResult) DetachAcquiredLocks() []LockAcquisition {
+	if lResult == nil {
+		return nil
+	}
+	var r []LockAcquisition
+	if lResult.AcquiredLocks != nil {
+		r = *lResult.AcquiredLocks
+	}
+	lResult.AcquiredLocks = nil
+	return r
+}
+
 // DetachEndTxns returns (and removes) the EndTxnIntent objects from
 // the local result. If alwaysOnly is true, the slice is filtered to
 // include only those which have specified returnAlways=true, meaning
@@ -314,6 +332,15 @@ func (p *Result) MergeAndDestroy(q Result) error {
 	}
 	q.Local.EndTxns = nil
 
+	if q.Local.AcquiredLocks != nil {
+		if p.Local.AcquiredLocks == nil {
+			p.Local.AcquiredLocks = q.Local.AcquiredLocks
+		} else {
+			*p.Local.AcquiredLocks = append(*p.Local.AcquiredLocks, *q.Local.AcquiredLocks...)
+		}
+	}
+	q.Local.AcquiredLocks = nil
+
 	if p.Local.Metrics == nil {
 		p.Local.Metrics = q.Local.Metrics
 	} else if q.Local.Metrics != nil {
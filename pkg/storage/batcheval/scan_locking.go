@@ -0,0 +1,136 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package batcheval
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+)
+
+// scanFn scans the provided span, returning up to maxKeys keys. The scanned
+// keys are accumulated into the response of the scan only if no error is
+// returned.
+type scanFn func(span roachpb.Span, maxKeys int64) (numKeys int64, resumeSpan *roachpb.Span, err error)
+
+// scanSkipLocked evaluates a locking scan with a SKIP LOCKED wait policy. The
+// span is split around keys that are locked by other transactions, either
+// through unreplicated locks or through write intents, and the remaining
+// sub-spans are scanned in order until the key limit is reached.
+func scanSkipLocked(
+	cArgs CommandArgs, span roachpb.Span, str roachpb.KeyLockingStrength, reverse bool, scan scanFn,
+) (*roachpb.Span, error) {
+	var txn *enginepb.TxnMeta
+	if cArgs.Header.Txn != nil {
+		txn = &cArgs.Header.Txn.TxnMeta
+	}
+	pending := splitSpanAroundKeys(span, cArgs.EvalCtx.GetConflictingLockedKeys(span, txn, str))
+	maxKeys := cArgs.MaxKeys
+	for len(pending) > 0 && maxKeys > 0 {
+		var sp roachpb.Span
+		if reverse {
+			sp, pending = pending[len(pending)-1], pending[:len(pending)-1]
+		} else {
+			sp, pending = pending[0], pending[1:]
+		}
+
+		numKeys, resumeSpan, err := scan(sp, maxKeys)
+		if wiErr, ok := err.(*roachpb.WriteIntentError); ok {
+			// Skip over the keys with conflicting intents and scan the rest of
+			// the sub-span again.
+			intentKeys := make([]roachpb.Key, len(wiErr.Intents))
+			for i := range wiErr.Intents {
+				intentKeys[i] = wiErr.Intents[i].Key
+			}
+			remaining := splitSpanAroundKeys(sp, intentKeys)
+			if reverse {
+				pending = append(pending, remaining...)
+			} else {
+				pending = append(remaining, pending...)
+			}
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		maxKeys -= numKeys
+		if resumeSpan != nil {
+			if reverse {
+				return &roachpb.Span{Key: span.Key, EndKey: resumeSpan.EndKey}, nil
+			}
+			return &roachpb.Span{Key: resumeSpan.Key, EndKey: span.EndKey}, nil
+		}
+	}
+	if len(pending) > 0 {
+		// The key limit was reached before all sub-spans were scanned.
+		if reverse {
+			return &roachpb.Span{Key: span.Key, EndKey: pending[len(pending)-1].EndKey}, nil
+		}
+		return &roachpb.Span{Key: pending[0].Key, EndKey: span.EndKey}, nil
+	}
+	return nil, nil
+}
+
+// splitSpanAroundKeys returns the ordered sub-spans of span that remain after
+// removing each of the provided keys from it.
+func splitSpanAroundKeys(span roachpb.Span, keys []roachpb.Key) []roachpb.Span {
+	if len(keys) == 0 {
+		return []roachpb.Span{span}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Compare(keys[j]) < 0 })
+	res := make([]roachpb.Span, 0, len(keys)+1)
+	start := span.Key
+	for _, key := range keys {
+		if key.Compare(start) < 0 || key.Compare(span.EndKey) >= 0 {
+			continue
+		}
+		if start.Compare(key) < 0 {
+			res = append(res, roachpb.Span{Key: start, EndKey: key})
+		}
+		start = key.Next()
+	}
+	if start.Compare(span.EndKey) < 0 {
+		res = append(res, roachpb.Span{Key: start, EndKey: span.EndKey})
+	}
+	return res
+}
+
+// acquireLocksOnScannedKeys returns a Result communicating that the scan's
+// transaction acquired locks of the given strength on each of the keys that
+// the scan returned, in either of the two scan formats.
+func acquireLocksOnScannedKeys(
+	txn *roachpb.Transaction,
+	str roachpb.KeyLockingStrength,
+	batchResponses [][]byte,
+	rows []roachpb.KeyValue,
+) (result.Result, error) {
+	if txn == nil || str == roachpb.KEY_LOCKING_NONE {
+		return result.Result{}, nil
+	}
+	var keys []roachpb.Key
+	for _, kvData := range batchResponses {
+		for len(kvData) > 0 {
+			var key []byte
+			var err error
+			key, _, kvData, err = enginepb.ScanDecodeKeyValueNoTS(kvData)
+			if err != nil {
+				return result.Result{}, err
+			}
+			keys = append(keys, key)
+		}
+	}
+	for i := range rows {
+		keys = append(keys, rows[i].Key)
+	}
+	return result.FromAcquiredLocks(txn, str, keys), nil
+}
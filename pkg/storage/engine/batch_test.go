@@ -916,117 +916,113 @@ func TestBatchDistinctAfterApplyBatchRepr(t *testing.T) {
 func TestBatchDistinct(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
-	stopper := stop.NewStopper()
-	defer stopper.Stop(context.TODO())
-	e := NewInMem(roachpb.Attributes{}, 1<<20)
-	stopper.AddCloser(e)
-
-	if err := e.Put(mvccKey("b"), []byte("b")); err != nil {
-		t.Fatal(err)
-	}
+	runWithAllEngines(func(e Engine, t *testing.T) {
+		if err := e.Put(mvccKey("b"), []byte("b")); err != nil {
+			t.Fatal(err)
+		}
 
-	batch := e.NewBatch()
-	defer batch.Close()
+		batch := e.NewBatch()
+		defer batch.Close()
 
-	if err := batch.Put(mvccKey("a"), []byte("a")); err != nil {
-		t.Fatal(err)
-	}
-	if err := batch.Clear(mvccKey("b")); err != nil {
-		t.Fatal(err)
-	}
+		if err := batch.Put(mvccKey("a"), []byte("a")); err != nil {
+			t.Fatal(err)
+		}
+		if err := batch.Clear(mvccKey("b")); err != nil {
+			t.Fatal(err)
+		}
 
-	// The original batch can see the writes to the batch.
-	if v, err := batch.Get(mvccKey("a")); err != nil {
-		t.Fatal(err)
-	} else if string(v) != "a" {
-		t.Fatalf("expected a, but got %s", v)
-	}
+		// The original batch can see the writes to the batch.
+		if v, err := batch.Get(mvccKey("a")); err != nil {
+			t.Fatal(err)
+		} else if string(v) != "a" {
+			t.Fatalf("expected a, but got %s", v)
+		}
 
-	// The distinct batch will see previous writes to the batch.
-	distinct := batch.Distinct()
-	if v, err := distinct.Get(mvccKey("a")); err != nil {
-		t.Fatal(err)
-	} else if string(v) != "a" {
-		t.Fatalf("expected a, but got %s", v)
-	}
-	if v, err := distinct.Get(mvccKey("b")); err != nil {
-		t.Fatal(err)
-	} else if v != nil {
-		t.Fatalf("expected nothing, but got %s", v)
-	}
+		// The distinct batch will see previous writes to the batch.
+		distinct := batch.Distinct()
+		if v, err := distinct.Get(mvccKey("a")); err != nil {
+			t.Fatal(err)
+		} else if string(v) != "a" {
+			t.Fatalf("expected a, but got %s", v)
+		}
+		if v, err := distinct.Get(mvccKey("b")); err != nil {
+			t.Fatal(err)
+		} else if v != nil {
+			t.Fatalf("expected nothing, but got %s", v)
+		}
 
-	// Similarly, for distinct batch iterators we will see previous writes to the
-	// batch.
-	iter := distinct.NewIterator(IterOptions{UpperBound: roachpb.KeyMax})
-	iter.Seek(mvccKey("a"))
-	if ok, err := iter.Valid(); !ok {
-		t.Fatalf("expected iterator to be valid; err=%v", err)
-	}
-	if string(iter.Key().Key) != "a" {
-		t.Fatalf("expected a, but got %s", iter.Key())
-	}
+		// Similarly, for distinct batch iterators we will see previous writes to the
+		// batch.
+		iter := distinct.NewIterator(IterOptions{UpperBound: roachpb.KeyMax})
+		iter.Seek(mvccKey("a"))
+		if ok, err := iter.Valid(); !ok {
+			t.Fatalf("expected iterator to be valid; err=%v", err)
+		}
+		if string(iter.Key().Key) != "a" {
+			t.Fatalf("expected a, but got %s", iter.Key())
+		}
+		iter.Close()
 
-	// Writes to the distinct batch are not readable by the distinct batch.
-	if err := distinct.Put(mvccKey("c"), []byte("c")); err != nil {
-		t.Fatal(err)
-	}
-	if v, err := distinct.Get(mvccKey("c")); err != nil {
-		t.Fatal(err)
-	} else if v != nil {
-		t.Fatalf("expected nothing, but got %s", v)
-	}
-	distinct.Close()
+		// Writes to the distinct batch are not readable by the distinct batch.
+		if err := distinct.Put(mvccKey("c"), []byte("c")); err != nil {
+			t.Fatal(err)
+		}
+		if v, err := distinct.Get(mvccKey("c")); err != nil {
+			t.Fatal(err)
+		} else if v != nil {
+			t.Fatalf("expected nothing, but got %s", v)
+		}
+		distinct.Close()
 
-	// Writes to the distinct batch are reflected in the original batch.
-	if v, err := batch.Get(mvccKey("c")); err != nil {
-		t.Fatal(err)
-	} else if string(v) != "c" {
-		t.Fatalf("expected c, but got %s", v)
-	}
+		// Writes to the distinct batch are reflected in the original batch.
+		if v, err := batch.Get(mvccKey("c")); err != nil {
+			t.Fatal(err)
+		} else if string(v) != "c" {
+			t.Fatalf("expected c, but got %s", v)
+		}
+	}, t)
 }
 
 func TestWriteOnlyBatchDistinct(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
-	stopper := stop.NewStopper()
-	defer stopper.Stop(context.TODO())
-	e := NewInMem(roachpb.Attributes{}, 1<<20)
-	stopper.AddCloser(e)
-
-	if err := e.Put(mvccKey("b"), []byte("b")); err != nil {
-		t.Fatal(err)
-	}
-	if _, _, err := PutProto(e, mvccKey("c"), &roachpb.Value{}); err != nil {
-		t.Fatal(err)
-	}
+	runWithAllEngines(func(e Engine, t *testing.T) {
+		if err := e.Put(mvccKey("b"), []byte("b")); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := PutProto(e, mvccKey("c"), &roachpb.Value{}); err != nil {
+			t.Fatal(err)
+		}
 
-	b := e.NewWriteOnlyBatch()
-	defer b.Close()
+		b := e.NewWriteOnlyBatch()
+		defer b.Close()
 
-	distinct := b.Distinct()
-	defer distinct.Close()
+		distinct := b.Distinct()
+		defer distinct.Close()
 
-	// Verify that reads on the distinct batch go to the underlying engine, not
-	// to the write-only batch.
-	iter := distinct.NewIterator(IterOptions{UpperBound: roachpb.KeyMax})
-	iter.Seek(mvccKey("a"))
-	if ok, err := iter.Valid(); !ok {
-		t.Fatalf("expected iterator to be valid, err=%v", err)
-	}
-	if string(iter.Key().Key) != "b" {
-		t.Fatalf("expected b, but got %s", iter.Key())
-	}
+		// Verify that reads on the distinct batch go to the underlying engine, not
+		// to the write-only batch.
+		iter := distinct.NewIterator(IterOptions{UpperBound: roachpb.KeyMax})
+		iter.Seek(mvccKey("a"))
+		if ok, err := iter.Valid(); !ok {
+			t.Fatalf("expected iterator to be valid, err=%v", err)
+		}
+		if string(iter.Key().Key) != "b" {
+			t.Fatalf("expected b, but got %s", iter.Key())
+		}
+		iter.Close()
 
-	if v, err := distinct.Get(mvccKey("b")); err != nil {
-		t.Fatal(err)
-	} else if string(v) != "b" {
-		t.Fatalf("expected b, but got %s", v)
-	}
+		if v, err := distinct.Get(mvccKey("b")); err != nil {
+			t.Fatal(err)
+		} else if string(v) != "b" {
+			t.Fatalf("expected b, but got %s", v)
+		}
 
-	val := &roachpb.Value{}
-	if _, _, _, err := distinct.GetProto(mvccKey("c"), val); err != nil {
-		t.Fatal(err)
-	}
+		val := &roachpb.Value{}
+		if _, _, _, err := distinct.GetProto(mvccKey("c"), val); err != nil {
+			t.Fatal(err)
+		}
+	}, t)
 }
 
 func TestBatchDistinctPanics(t *testing.T) {
@@ -61,6 +61,10 @@ func runWithAllEngines(test func(e Engine, t *testing.T), t *testing.T) {
 	inMem := NewInMem(inMemAttrs, testCacheSize)
 	stopper.AddCloser(inMem)
 	test(inMem, t)
+
+	inMemPebble := newPebbleInMem(inMemAttrs, testCacheSize)
+	stopper.AddCloser(inMemPebble)
+	test(inMemPebble, t)
 }
 
 // TestEngineBatchCommit writes a batch containing 10K rows (all the
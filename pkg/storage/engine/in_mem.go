@@ -12,6 +12,16 @@ package engine
 
 import "github.com/cockroachdb/cockroach/pkg/roachpb"
 
+// InMemEngine is an Engine that may store its data in memory. Ingesting files
+// into such an engine requires first writing them to its in-memory filesystem.
+type InMemEngine interface {
+	Engine
+	// IsInMem returns true if the engine stores its data in memory.
+	IsInMem() bool
+	// WriteFile writes data to a file in the engine's filesystem.
+	WriteFile(filename string, data []byte) error
+}
+
 // InMem wraps RocksDB and configures it for in-memory only storage.
 type InMem struct {
 	*RocksDB
@@ -38,4 +48,9 @@ func NewInMem(attrs roachpb.Attributes, cacheSize int64) InMem {
 	return db
 }
 
-var _ Engine = InMem{}
+// IsInMem implements the InMemEngine interface.
+func (db InMem) IsInMem() bool {
+	return true
+}
+
+var _ InMemEngine = InMem{}
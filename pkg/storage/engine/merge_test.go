@@ -11,6 +11,7 @@
 package engine
 
 import (
+	"bytes"
 	"math"
 	"math/rand"
 	"reflect"
@@ -179,6 +180,10 @@ func TestGoMerge(t *testing.T) {
 		if err == nil {
 			t.Errorf("goMerge: %d: expected error", i)
 		}
+		_, err = mvccMerge(c.existing, c.update, true /* full */)
+		if err == nil {
+			t.Errorf("mvccMerge: %d: expected error", i)
+		}
 	}
 
 	gibber1, gibber2 := gibberishString(100), gibberishString(200)
@@ -210,6 +215,20 @@ func TestGoMerge(t *testing.T) {
 		if !reflect.DeepEqual(resultV, expectedV) {
 			t.Errorf("goMerge error: %d: want %+v, got %+v", i, expectedV, resultV)
 		}
+
+		// The Go merge operator used by Pebble must agree with the C++ one.
+		result, err = mvccMerge(c.existing, c.update, true /* full */)
+		if err != nil {
+			t.Errorf("mvccMerge error: %d: %+v", i, err)
+			continue
+		}
+		var goResultV enginepb.MVCCMetadata
+		if err := protoutil.Unmarshal(result, &goResultV); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(goResultV.RawBytes, expectedV.RawBytes) {
+			t.Errorf("mvccMerge error: %d: want %+v, got %+v", i, expectedV, goResultV)
+		}
 	}
 
 	// Each time series test case is a list of byte slice. The last byte slice
@@ -509,6 +528,19 @@ func TestGoMerge(t *testing.T) {
 					}
 				}
 			}
+
+			// The Go merge operator used by Pebble must produce the same result
+			// as merging the operands into nil.
+			var result []byte
+			for _, operand := range c[:len(c)-1] {
+				var err error
+				if result, err = mvccMerge(result, operand, true /* full */); err != nil {
+					t.Fatalf("mvccMerge error: %s", err)
+				}
+			}
+			if a, e := unmarshalTimeSeries(t, result), expectedTS; !reflect.DeepEqual(a, e) {
+				t.Errorf("mvccMerge returned wrong result got %v, wanted %v", a, e)
+			}
 		})
 	}
 }
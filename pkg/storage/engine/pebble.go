@@ -0,0 +1,925 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/petermattis/pebble"
+	"github.com/petermattis/pebble/cache"
+	"github.com/petermattis/pebble/vfs"
+	"github.com/pkg/errors"
+)
+
+// MVCCComparer is a pebble.Comparer object that implements MVCC-specific
+// comparator settings for use with Pebble. Its ordering matches the
+// cockroach_comparator of RocksDB, so the two engines can read each other's
+// sstables.
+var MVCCComparer = &pebble.Comparer{
+	Compare: MVCCKeyCompare,
+	AbbreviatedKey: func(k []byte) uint64 {
+		key, _, ok := enginepb.SplitMVCCKey(k)
+		if !ok {
+			return 0
+		}
+		return pebble.DefaultComparer.AbbreviatedKey(key)
+	},
+
+	Separator: func(dst, a, b []byte) []byte {
+		return append(dst, a...)
+	},
+
+	Successor: func(dst, a []byte) []byte {
+		return append(dst, a...)
+	},
+	Split: func(k []byte) int {
+		if len(k) == 0 {
+			return len(k)
+		}
+		// This is similar to what enginepb.SplitMVCCKey does.
+		tsLen := int(k[len(k)-1])
+		keyPartEnd := len(k) - 1 - tsLen
+		if keyPartEnd < 0 {
+			return len(k)
+		}
+		return keyPartEnd
+	},
+
+	Name: "cockroach_comparator",
+}
+
+// MVCCMerger is a pebble.Merger object that implements the merge operator
+// used by Cockroach, which is implemented in Go in pebble_merge.go.
+var MVCCMerger = &pebble.Merger{
+	Name: "cockroach_merge_operator",
+	Merge: func(key, oldValue, newValue, buf []byte) []byte {
+		res, err := merge(key, oldValue, newValue, buf)
+		if err != nil {
+			log.Fatalf(context.Background(), "merge: %v", err)
+		}
+		return res
+	},
+}
+
+// pebbleTimeBoundPropCollector implements a property collector for MVCC
+// Timestamps. Its behavior matches TimeBoundTblPropCollector in
+// table_props.cc, including the handling of an intent which is the last key
+// of the sstable.
+type pebbleTimeBoundPropCollector struct {
+	min, max  []byte
+	lastValue []byte
+}
+
+var _ pebble.TablePropertyCollector = &pebbleTimeBoundPropCollector{}
+
+func (t *pebbleTimeBoundPropCollector) Add(key pebble.InternalKey, value []byte) error {
+	_, ts, ok := enginepb.SplitMVCCKey(key.UserKey)
+	if !ok {
+		return nil
+	}
+	if len(ts) > 0 {
+		t.lastValue = t.lastValue[:0]
+		t.updateBounds(ts)
+		return nil
+	}
+	t.lastValue = append(t.lastValue[:0], value...)
+	return nil
+}
+
+func (t *pebbleTimeBoundPropCollector) Finish(userProps map[string]string) error {
+	if len(t.lastValue) > 0 {
+		// Check to see if an intent was the last key in the sstable. If it was,
+		// we need to extract the timestamp from the intent and update the
+		// bounds to include that timestamp.
+		var meta enginepb.MVCCMetadata
+		if err := protoutil.Unmarshal(t.lastValue, &meta); err != nil {
+			// We're unable to parse the MVCCMetadata. Fail open by not setting
+			// the min/max timestamp properties.
+			return nil
+		}
+		if meta.Txn != nil {
+			t.updateBounds(encodeTimestamp(hlc.Timestamp(meta.Timestamp)))
+		}
+	}
+
+	userProps["crdb.ts.min"] = string(t.min)
+	userProps["crdb.ts.max"] = string(t.max)
+	return nil
+}
+
+func (t *pebbleTimeBoundPropCollector) updateBounds(ts []byte) {
+	if len(t.min) == 0 || bytes.Compare(ts, t.min) < 0 {
+		t.min = append(t.min[:0], ts...)
+	}
+	if len(t.max) == 0 || bytes.Compare(ts, t.max) > 0 {
+		t.max = append(t.max[:0], ts...)
+	}
+}
+
+func (t *pebbleTimeBoundPropCollector) Name() string {
+	// NB: This name needs to match the one used by the C++ code.
+	return "TimeBoundTblPropCollectorFactory"
+}
+
+// pebbleDeleteRangeCollector identifies itself like the
+// DeleteRangeTblPropCollector in table_props.cc, so that sstables written by
+// pebble claim to have the same properties as those written by RocksDB. It
+// doesn't record any properties.
+type pebbleDeleteRangeCollector struct{}
+
+var _ pebble.TablePropertyCollector = &pebbleDeleteRangeCollector{}
+
+func (pebbleDeleteRangeCollector) Add(key pebble.InternalKey, value []byte) error {
+	return nil
+}
+
+func (pebbleDeleteRangeCollector) Finish(userProps map[string]string) error {
+	return nil
+}
+
+func (pebbleDeleteRangeCollector) Name() string {
+	// NB: This name needs to match the one used by the C++ code.
+	return "DeleteRangeTblPropCollectorFactory"
+}
+
+// PebbleTablePropertyCollectors is the list of property collectors of the
+// sstables written by pebble.
+var PebbleTablePropertyCollectors = []func() pebble.TablePropertyCollector{
+	func() pebble.TablePropertyCollector { return &pebbleTimeBoundPropCollector{} },
+	func() pebble.TablePropertyCollector { return &pebbleDeleteRangeCollector{} },
+}
+
+// encodeTimestamp encodes a timestamp like the timestamp suffix of an encoded
+// MVCCKey, without the sentinel and length bytes. This encoding must match
+// EncodeTimestamp in c-deps/libroach/encoding.cc.
+func encodeTimestamp(ts hlc.Timestamp) []byte {
+	encoded := EncodeKey(MVCCKey{Timestamp: ts})
+	if len(encoded) == 1 {
+		return nil
+	}
+	return encoded[1 : len(encoded)-1]
+}
+
+// DefaultPebbleOptions returns the default pebble options used by pebble
+// engines.
+func DefaultPebbleOptions() *pebble.Options {
+	opts := &pebble.Options{
+		Comparer:                    MVCCComparer,
+		L0CompactionThreshold:       2,
+		L0StopWritesThreshold:       1000,
+		LBaseMaxBytes:               64 << 20, // 64 MB
+		Levels:                      []pebble.LevelOptions{{BlockSize: 32 << 10}},
+		MemTableSize:                64 << 20, // 64 MB
+		MemTableStopWritesThreshold: 4,
+		Merger:                      MVCCMerger,
+		MinFlushRate:                4 << 20, // 4 MB/sec
+		TablePropertyCollectors:     PebbleTablePropertyCollectors,
+	}
+	opts.EnsureDefaults()
+	return opts
+}
+
+// PebbleConfig holds all configuration parameters and knobs used in setting
+// up a new Pebble instance.
+type PebbleConfig struct {
+	Attrs roachpb.Attributes
+	// Dir is the data directory for this store. An empty Dir creates an
+	// in-memory instance.
+	Dir string
+	// MaxSizeBytes is used for calculating free space and making rebalancing
+	// decisions. Zero indicates that there is no maximum size.
+	MaxSizeBytes int64
+	// Settings instance for cluster-wide knobs.
+	Settings *cluster.Settings
+	// Opts are the options of the pebble instance. If nil,
+	// DefaultPebbleOptions are used.
+	Opts *pebble.Options
+}
+
+// Pebble is a wrapper around a Pebble database instance. Pebble is a
+// RocksDB-compatible LSM written in Go, so unlike RocksDB it doesn't require
+// cgo.
+type Pebble struct {
+	db *pebble.DB
+
+	closed bool
+	cfg    PebbleConfig
+	fs     vfs.FS
+	// auxDir is used for storing auxiliary files. Ideally it is a subdirectory
+	// of Dir.
+	auxDir string
+}
+
+var _ Engine = &Pebble{}
+var _ WithSSTables = &Pebble{}
+
+// NewPebble creates a new Pebble instance, at the specified path or in
+// memory if the path is empty. The caller must call the engine's Close method
+// when the engine is no longer needed.
+func NewPebble(ctx context.Context, cfg PebbleConfig) (*Pebble, error) {
+	opts := cfg.Opts
+	if opts == nil {
+		opts = DefaultPebbleOptions()
+	} else {
+		// Don't modify the caller's options.
+		optsCopy := *opts
+		opts = &optsCopy
+	}
+
+	var auxDir string
+	if cfg.Dir == "" {
+		opts.FS = vfs.NewMem()
+		// Like in-memory RocksDB instances, in-memory Pebble instances use a
+		// temporary directory as their auxiliary directory.
+		var err error
+		if auxDir, err = ioutil.TempDir(os.TempDir(), "cockroach-auxiliary"); err != nil {
+			return nil, err
+		}
+	} else {
+		if opts.FS == nil {
+			opts.FS = vfs.Default
+		}
+		auxDir = filepath.Join(cfg.Dir, "auxiliary")
+	}
+	if err := opts.FS.MkdirAll(auxDir, 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := pebble.Open(cfg.Dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Dir == "" {
+		log.VEventf(ctx, 1, "opened in-memory pebble instance")
+	} else {
+		log.Infof(ctx, "opened pebble instance at %q", cfg.Dir)
+	}
+
+	return &Pebble{
+		db:     db,
+		cfg:    cfg,
+		fs:     opts.FS,
+		auxDir: auxDir,
+	}, nil
+}
+
+// newPebbleInMem creates a new in-memory Pebble instance for use in tests,
+// with the same size as the in-memory RocksDB instances of NewInMem.
+func newPebbleInMem(attrs roachpb.Attributes, cacheSize int64) *Pebble {
+	opts := DefaultPebbleOptions()
+	opts.Cache = cache.New(cacheSize)
+	db, err := NewPebble(context.Background(), PebbleConfig{
+		Attrs:        attrs,
+		MaxSizeBytes: 512 << 20, /* 512 MiB */
+		Opts:         opts,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// String formatter.
+func (p *Pebble) String() string {
+	dir := p.cfg.Dir
+	if dir == "" {
+		dir = "<in-mem>"
+	}
+	attrs := p.Attrs().String()
+	if attrs == "" {
+		attrs = "<no-attributes>"
+	}
+	return fmt.Sprintf("%s=%s", attrs, dir)
+}
+
+// Close implements the Engine interface.
+func (p *Pebble) Close() {
+	if p.closed {
+		log.Errorf(context.TODO(), "closing unopened pebble instance")
+		return
+	}
+	p.closed = true
+	if p.cfg.Dir == "" {
+		// Remove the temporary directory when the engine is in-memory.
+		if err := os.RemoveAll(p.auxDir); err != nil {
+			log.Warning(context.TODO(), err)
+		}
+	} else {
+		log.Infof(context.TODO(), "closing pebble instance at %q", p.cfg.Dir)
+	}
+	if err := p.db.Close(); err != nil {
+		panic(err)
+	}
+}
+
+// Closed implements the Engine interface.
+func (p *Pebble) Closed() bool {
+	return p.closed
+}
+
+// IsInMem implements the InMemEngine interface.
+func (p *Pebble) IsInMem() bool {
+	return p.cfg.Dir == ""
+}
+
+// Get implements the Engine interface.
+func (p *Pebble) Get(key MVCCKey) ([]byte, error) {
+	return pebbleGet(p.db, key)
+}
+
+// GetProto implements the Engine interface.
+func (p *Pebble) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return pebbleGetProto(p.db, key, msg)
+}
+
+// Iterate implements the Engine interface.
+func (p *Pebble) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	return pebbleIterate(p.db, p, start, end, f)
+}
+
+// NewIterator implements the Engine interface.
+func (p *Pebble) NewIterator(opts IterOptions) Iterator {
+	return newPebbleIterator(p.db, opts, p)
+}
+
+// ApplyBatchRepr implements the Engine interface.
+func (p *Pebble) ApplyBatchRepr(repr []byte, sync bool) error {
+	batch := p.db.NewBatch()
+	defer batch.Close()
+	if err := batch.SetRepr(repr); err != nil {
+		return err
+	}
+
+	opts := pebble.NoSync
+	if sync {
+		opts = pebble.Sync
+	}
+	return batch.Commit(opts)
+}
+
+// Clear implements the Engine interface.
+func (p *Pebble) Clear(key MVCCKey) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return p.db.Delete(EncodeKey(key), pebble.NoSync)
+}
+
+// SingleClear implements the Engine interface.
+func (p *Pebble) SingleClear(key MVCCKey) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return p.db.SingleDelete(EncodeKey(key), pebble.NoSync)
+}
+
+// ClearRange implements the Engine interface.
+func (p *Pebble) ClearRange(start, end MVCCKey) error {
+	return p.db.DeleteRange(EncodeKey(start), EncodeKey(end), pebble.NoSync)
+}
+
+// ClearIterRange implements the Engine interface.
+func (p *Pebble) ClearIterRange(iter Iterator, start, end MVCCKey) error {
+	// Write all of the deletions to a batch so that they're applied
+	// atomically, like with the other engine implementations.
+	batch := p.db.NewBatch()
+	defer batch.Close()
+	if err := pebbleClearIterRange(batch, iter, start, end); err != nil {
+		return err
+	}
+	return batch.Commit(pebble.NoSync)
+}
+
+// Merge implements the Engine interface.
+func (p *Pebble) Merge(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return p.db.Merge(EncodeKey(key), value, pebble.NoSync)
+}
+
+// Put implements the Engine interface.
+func (p *Pebble) Put(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return p.db.Set(EncodeKey(key), value, pebble.NoSync)
+}
+
+// LogData implements the Engine interface.
+func (p *Pebble) LogData(data []byte) error {
+	return p.db.LogData(data, pebble.NoSync)
+}
+
+// LogLogicalOp implements the Engine interface.
+func (p *Pebble) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
+	// No-op. Logical logging disabled.
+}
+
+// Attrs implements the Engine interface.
+func (p *Pebble) Attrs() roachpb.Attributes {
+	return p.cfg.Attrs
+}
+
+// Capacity implements the Engine interface.
+func (p *Pebble) Capacity() (roachpb.StoreCapacity, error) {
+	return computeCapacity(p.cfg.Dir, p.cfg.MaxSizeBytes)
+}
+
+// Flush implements the Engine interface.
+func (p *Pebble) Flush() error {
+	return p.db.Flush()
+}
+
+// GetStats implements the Engine interface.
+func (p *Pebble) GetStats() (*Stats, error) {
+	m := p.db.Metrics()
+	return &Stats{
+		BlockCacheHits:                 m.BlockCache.Hits,
+		BlockCacheMisses:               m.BlockCache.Misses,
+		BlockCacheUsage:                m.BlockCache.Size,
+		MemtableTotalSize:              int64(m.MemTable.Size),
+		Flushes:                        m.Flush.Count,
+		Compactions:                    m.Compact.Count,
+		PendingCompactionBytesEstimate: int64(m.Compact.EstimatedDebt),
+		L0FileCount:                    m.Levels[0].NumFiles,
+	}, nil
+}
+
+// GetEnvStats implements the Engine interface. Pebble stores don't support
+// encryption at rest.
+func (p *Pebble) GetEnvStats() (*EnvStats, error) {
+	m := p.db.Metrics()
+	stats := &EnvStats{}
+	for i := range m.Levels {
+		stats.TotalFiles += uint64(m.Levels[i].NumFiles)
+		stats.TotalBytes += m.Levels[i].Size
+	}
+	return stats, nil
+}
+
+// GetAuxiliaryDir implements the Engine interface.
+func (p *Pebble) GetAuxiliaryDir() string {
+	return p.auxDir
+}
+
+// NewBatch implements the Engine interface.
+func (p *Pebble) NewBatch() Batch {
+	return newPebbleBatch(p, false /* writeOnly */)
+}
+
+// NewReadOnly implements the Engine interface.
+func (p *Pebble) NewReadOnly() ReadWriter {
+	return &pebbleReadOnly{
+		parent: p,
+	}
+}
+
+// NewWriteOnlyBatch implements the Engine interface.
+func (p *Pebble) NewWriteOnlyBatch() Batch {
+	return newPebbleBatch(p, true /* writeOnly */)
+}
+
+// NewSnapshot implements the Engine interface.
+func (p *Pebble) NewSnapshot() Reader {
+	return &pebbleSnapshot{
+		parent:   p,
+		snapshot: p.db.NewSnapshot(),
+	}
+}
+
+// IngestExternalFiles implements the Engine interface. Like RocksDB, which
+// moves the ingested files, Pebble removes the ingested files from their
+// original paths. The skipWritingSeqNo and allowFileModifications arguments
+// are ignored since Pebble never modifies the ingested files.
+func (p *Pebble) IngestExternalFiles(
+	ctx context.Context, paths []string, skipWritingSeqNo, allowFileModifications bool,
+) error {
+	if err := p.db.Ingest(paths); err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := p.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// PreIngestDelay implements the Engine interface. See RocksDB.PreIngestDelay
+// for details.
+func (p *Pebble) PreIngestDelay(ctx context.Context) {
+	if p.cfg.Settings == nil {
+		return
+	}
+	stats, err := p.GetStats()
+	if err != nil {
+		log.Warningf(ctx, "failed to read stats: %+v", err)
+		return
+	}
+	targetDelay := calculatePreIngestDelay(p.cfg.Settings, stats)
+
+	if targetDelay == 0 {
+		return
+	}
+	log.VEventf(ctx, 2, "delaying SST ingestion %s. %d L0 files, %db pending compaction", targetDelay, stats.L0FileCount, stats.PendingCompactionBytesEstimate)
+
+	select {
+	case <-time.After(targetDelay):
+	case <-ctx.Done():
+	}
+}
+
+// ApproximateDiskBytes implements the Engine interface.
+func (p *Pebble) ApproximateDiskBytes(from, to roachpb.Key) (uint64, error) {
+	return p.db.EstimateDiskUsage(EncodeKey(MVCCKey{Key: from}), EncodeKey(MVCCKey{Key: to}))
+}
+
+// Compact forces compaction over the entire database.
+func (p *Pebble) Compact() error {
+	return p.db.Compact(EncodeKey(MVCCKey{Key: roachpb.KeyMin}), EncodeKey(MVCCKey{Key: roachpb.KeyMax}))
+}
+
+// CompactRange implements the Engine interface. Pebble compacts the range down
+// to the bottommost level containing data, but unlike RocksDB it has no way to
+// rewrite the sstables already in that level, so forceBottommost isn't
+// supported.
+//
+// TODO: honor forceBottommost once Pebble supports compacting the bottommost
+// level into itself.
+func (p *Pebble) CompactRange(start, end roachpb.Key, forceBottommost bool) error {
+	if forceBottommost {
+		return errors.New("pebble does not support compacting the bottommost level")
+	}
+	return p.db.Compact(EncodeKey(MVCCKey{Key: start}), EncodeKey(MVCCKey{Key: end}))
+}
+
+// OpenFile implements the Engine interface.
+func (p *Pebble) OpenFile(filename string) (DBFile, error) {
+	if err := p.fs.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, err
+	}
+	file, err := p.fs.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &pebbleFile{File: file}, nil
+}
+
+// WriteFile writes data to a file in the filesystem of the engine, which is
+// in memory for in-memory instances.
+func (p *Pebble) WriteFile(filename string, data []byte) error {
+	f, err := p.OpenFile(filename)
+	if err != nil {
+		return err
+	}
+	if err := f.Append(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadFile implements the Engine interface.
+func (p *Pebble) ReadFile(filename string) ([]byte, error) {
+	file, err := p.fs.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	return ioutil.ReadAll(file)
+}
+
+// DeleteFile implements the Engine interface.
+func (p *Pebble) DeleteFile(filename string) error {
+	if err := p.fs.Remove(filename); err != nil {
+		if os.IsNotExist(err) {
+			return os.ErrNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteDirAndFiles implements the Engine interface.
+func (p *Pebble) DeleteDirAndFiles(dir string) error {
+	files, err := p.fs.List(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, file := range files {
+		if err := p.fs.Remove(filepath.Join(dir, file)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := p.fs.Remove(dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// LinkFile implements the Engine interface.
+func (p *Pebble) LinkFile(oldname, newname string) error {
+	if err := p.fs.Link(oldname, newname); err != nil {
+		return &os.LinkError{
+			Op:  "link",
+			Old: oldname,
+			New: newname,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// CreateCheckpoint implements the Engine interface.
+func (p *Pebble) CreateCheckpoint(dir string) error {
+	return errors.Wrap(p.db.Checkpoint(dir), "unable to take pebble checkpoint")
+}
+
+// GetSSTables implements the WithSSTables interface.
+func (p *Pebble) GetSSTables() SSTableInfos {
+	var res SSTableInfos
+	for level, tables := range p.db.SSTables() {
+		for _, table := range tables {
+			start, _ := DecodeMVCCKey(table.Smallest.UserKey)
+			end, _ := DecodeMVCCKey(table.Largest.UserKey)
+			res = append(res, SSTableInfo{
+				Level: level,
+				Size:  int64(table.Size),
+				Start: start,
+				End:   end,
+			})
+		}
+	}
+	sort.Sort(res)
+	return res
+}
+
+// pebbleFile implements the DBFile interface on top of a pebble vfs.File.
+type pebbleFile struct {
+	vfs.File
+}
+
+var _ DBFile = &pebbleFile{}
+
+// Append implements the DBFile interface.
+func (f *pebbleFile) Append(data []byte) error {
+	_, err := f.Write(data)
+	return err
+}
+
+// pebbleReadOnly is a read-only view of a Pebble instance, which panics on
+// writes.
+type pebbleReadOnly struct {
+	parent *Pebble
+	closed bool
+}
+
+var _ ReadWriter = &pebbleReadOnly{}
+
+func (p *pebbleReadOnly) Close() {
+	if p.closed {
+		panic("closing an already-closed pebbleReadOnly")
+	}
+	p.closed = true
+}
+
+func (p *pebbleReadOnly) Closed() bool {
+	return p.closed
+}
+
+func (p *pebbleReadOnly) Get(key MVCCKey) ([]byte, error) {
+	if p.closed {
+		panic("using a closed pebbleReadOnly")
+	}
+	return pebbleGet(p.parent.db, key)
+}
+
+func (p *pebbleReadOnly) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	if p.closed {
+		panic("using a closed pebbleReadOnly")
+	}
+	return pebbleGetProto(p.parent.db, key, msg)
+}
+
+func (p *pebbleReadOnly) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	if p.closed {
+		panic("using a closed pebbleReadOnly")
+	}
+	return pebbleIterate(p.parent.db, p, start, end, f)
+}
+
+func (p *pebbleReadOnly) NewIterator(opts IterOptions) Iterator {
+	if p.closed {
+		panic("using a closed pebbleReadOnly")
+	}
+	return newPebbleIterator(p.parent.db, opts, p)
+}
+
+// Writer methods are not implemented for pebbleReadOnly.
+
+func (p *pebbleReadOnly) ApplyBatchRepr(repr []byte, sync bool) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) Clear(key MVCCKey) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) SingleClear(key MVCCKey) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) ClearRange(start, end MVCCKey) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) ClearIterRange(iter Iterator, start, end MVCCKey) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) Merge(key MVCCKey, value []byte) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) Put(key MVCCKey, value []byte) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) LogData(data []byte) error {
+	panic("not implemented")
+}
+
+func (p *pebbleReadOnly) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
+	panic("not implemented")
+}
+
+// pebbleSnapshot represents a snapshot created using Pebble.NewSnapshot().
+type pebbleSnapshot struct {
+	parent   *Pebble
+	snapshot *pebble.Snapshot
+	closed   bool
+}
+
+var _ Reader = &pebbleSnapshot{}
+
+// Close implements the Reader interface.
+func (p *pebbleSnapshot) Close() {
+	if err := p.snapshot.Close(); err != nil {
+		panic(err)
+	}
+	p.closed = true
+}
+
+// Closed implements the Reader interface.
+func (p *pebbleSnapshot) Closed() bool {
+	return p.closed
+}
+
+// Get implements the Reader interface.
+func (p *pebbleSnapshot) Get(key MVCCKey) ([]byte, error) {
+	return pebbleGet(p.snapshot, key)
+}
+
+// GetProto implements the Reader interface.
+func (p *pebbleSnapshot) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return pebbleGetProto(p.snapshot, key, msg)
+}
+
+// Iterate implements the Reader interface.
+func (p *pebbleSnapshot) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	return pebbleIterate(p.snapshot, p, start, end, f)
+}
+
+// NewIterator implements the Reader interface.
+func (p *pebbleSnapshot) NewIterator(opts IterOptions) Iterator {
+	return newPebbleIterator(p.snapshot, opts, p)
+}
+
+// pebbleGet returns the value for the given key.
+func pebbleGet(r pebble.Reader, key MVCCKey) ([]byte, error) {
+	if len(key.Key) == 0 {
+		return nil, emptyKeyError()
+	}
+	ret, err := r.Get(EncodeKey(key))
+	if err == pebble.ErrNotFound || len(ret) == 0 {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	// The returned value is only valid until the next write, so copy it.
+	return append([]byte(nil), ret...), nil
+}
+
+func pebbleGetProto(
+	r pebble.Reader, key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	if len(key.Key) == 0 {
+		err = emptyKeyError()
+		return
+	}
+	val, err := r.Get(EncodeKey(key))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			err = nil
+			msg.Reset()
+		}
+		return
+	}
+	if len(val) == 0 {
+		msg.Reset()
+		return
+	}
+	ok = true
+	if msg != nil {
+		err = protoutil.Unmarshal(val, msg)
+	}
+	keyBytes = int64(key.EncodedSize())
+	valBytes = int64(len(val))
+	return
+}
+
+func pebbleIterate(
+	r pebble.Reader, engine Reader, start, end MVCCKey, f func(MVCCKeyValue) (bool, error),
+) error {
+	if !start.Less(end) {
+		return nil
+	}
+	it := newPebbleIterator(r, IterOptions{UpperBound: end.Key}, engine)
+	defer it.Close()
+
+	it.Seek(start)
+	for ; ; it.Next() {
+		ok, err := it.Valid()
+		if err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		k := it.Key()
+		if !k.Less(end) {
+			break
+		}
+		if done, err := f(MVCCKeyValue{Key: k, Value: it.Value()}); done || err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pebbleClearIterRange writes a deletion to the batch for each of the keys
+// of the iterator in the span [start, end), like DBDeleteIterRange.
+func pebbleClearIterRange(batch *pebble.Batch, iter Iterator, start, end MVCCKey) error {
+	iter.Seek(start)
+	for {
+		valid, err := iter.Valid()
+		if err != nil {
+			return err
+		}
+		if !valid || !iter.UnsafeKey().Less(end) {
+			return nil
+		}
+		if err := batch.Delete(EncodeKey(iter.UnsafeKey()), nil); err != nil {
+			return err
+		}
+		iter.Next()
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/petermattis/pebble"
+)
+
+// pebbleBatch wraps a pebble Batch and implements the Batch interface. The
+// batch is indexed, so that it can read its own writes, unless it's
+// write-only.
+type pebbleBatch struct {
+	parent    *Pebble
+	batch     *pebble.Batch
+	writeOnly bool
+	closed    bool
+	committed bool
+	// The writes of the distinct batch are buffered in a separate batch, so
+	// that they aren't visible to the reads of the distinct batch, and are
+	// applied to the batch when the distinct batch is closed.
+	distinct     pebbleDistinctBatch
+	distinctOpen bool
+}
+
+var _ Batch = &pebbleBatch{}
+
+func newPebbleBatch(parent *Pebble, writeOnly bool) *pebbleBatch {
+	b := &pebbleBatch{
+		parent:    parent,
+		writeOnly: writeOnly,
+	}
+	if writeOnly {
+		b.batch = parent.db.NewBatch()
+	} else {
+		b.batch = parent.db.NewIndexedBatch()
+	}
+	b.distinct.parent = b
+	return b
+}
+
+// reader returns what the batch reads from: the indexed batch, which reads
+// through to the engine, or the engine itself for write-only batches.
+func (p *pebbleBatch) reader() pebble.Reader {
+	if p.writeOnly {
+		return p.parent.db
+	}
+	return p.batch
+}
+
+func (p *pebbleBatch) checkReadable() {
+	if p.writeOnly {
+		panic("write-only batch")
+	}
+	p.checkDistinctClosed()
+}
+
+func (p *pebbleBatch) checkDistinctClosed() {
+	if p.distinctOpen {
+		panic("distinct batch open")
+	}
+}
+
+// Close implements the Batch interface.
+func (p *pebbleBatch) Close() {
+	if p.closed {
+		panic("this batch was already closed")
+	}
+	if p.distinctOpen {
+		p.distinct.Close()
+	}
+	if err := p.batch.Close(); err != nil {
+		panic(err)
+	}
+	p.closed = true
+}
+
+// Closed implements the Batch interface.
+func (p *pebbleBatch) Closed() bool {
+	return p.closed || p.committed
+}
+
+// Get implements the Batch interface.
+func (p *pebbleBatch) Get(key MVCCKey) ([]byte, error) {
+	p.checkReadable()
+	return pebbleGet(p.batch, key)
+}
+
+// GetProto implements the Batch interface.
+func (p *pebbleBatch) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	p.checkReadable()
+	return pebbleGetProto(p.batch, key, msg)
+}
+
+// Iterate implements the Batch interface.
+func (p *pebbleBatch) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	p.checkReadable()
+	return pebbleIterate(p.batch, p, start, end, f)
+}
+
+// NewIterator implements the Batch interface.
+func (p *pebbleBatch) NewIterator(opts IterOptions) Iterator {
+	p.checkReadable()
+	return newPebbleIterator(p.batch, opts, p)
+}
+
+// ApplyBatchRepr implements the Batch interface.
+func (p *pebbleBatch) ApplyBatchRepr(repr []byte, sync bool) error {
+	p.checkDistinctClosed()
+	return pebbleApplyBatchRepr(p.batch, repr)
+}
+
+// Clear implements the Batch interface.
+func (p *pebbleBatch) Clear(key MVCCKey) error {
+	p.checkDistinctClosed()
+	return pebbleClear(p.batch, key)
+}
+
+// SingleClear implements the Batch interface.
+func (p *pebbleBatch) SingleClear(key MVCCKey) error {
+	p.checkDistinctClosed()
+	return pebbleSingleClear(p.batch, key)
+}
+
+// ClearRange implements the Batch interface.
+func (p *pebbleBatch) ClearRange(start, end MVCCKey) error {
+	p.checkDistinctClosed()
+	return p.batch.DeleteRange(EncodeKey(start), EncodeKey(end), nil)
+}
+
+// ClearIterRange implements the Batch interface.
+func (p *pebbleBatch) ClearIterRange(iter Iterator, start, end MVCCKey) error {
+	p.checkDistinctClosed()
+	return pebbleClearIterRange(p.batch, iter, start, end)
+}
+
+// Merge implements the Batch interface.
+func (p *pebbleBatch) Merge(key MVCCKey, value []byte) error {
+	p.checkDistinctClosed()
+	return pebbleMerge(p.batch, key, value)
+}
+
+// Put implements the Batch interface.
+func (p *pebbleBatch) Put(key MVCCKey, value []byte) error {
+	p.checkDistinctClosed()
+	return pebblePut(p.batch, key, value)
+}
+
+// LogData implements the Batch interface.
+func (p *pebbleBatch) LogData(data []byte) error {
+	p.checkDistinctClosed()
+	return p.batch.LogData(data, nil)
+}
+
+// LogLogicalOp implements the Batch interface.
+func (p *pebbleBatch) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
+	// No-op. Logical logging disabled.
+}
+
+// Commit implements the Batch interface.
+func (p *pebbleBatch) Commit(sync bool) error {
+	if p.Closed() {
+		panic("this batch was already committed")
+	}
+	if p.distinctOpen {
+		p.distinct.Close()
+	}
+	opts := pebble.NoSync
+	if sync {
+		opts = pebble.Sync
+	}
+	if err := p.batch.Commit(opts); err != nil {
+		return err
+	}
+	p.committed = true
+	return nil
+}
+
+// Distinct implements the Batch interface.
+func (p *pebbleBatch) Distinct() ReadWriter {
+	if p.distinctOpen {
+		panic("distinct batch already open")
+	}
+	p.distinctOpen = true
+	p.distinct.batch = p.parent.db.NewBatch()
+	return &p.distinct
+}
+
+// Empty implements the Batch interface.
+func (p *pebbleBatch) Empty() bool {
+	return p.batch.Empty()
+}
+
+// Len implements the Batch interface.
+func (p *pebbleBatch) Len() int {
+	return len(p.batch.Repr())
+}
+
+// Repr implements the Batch interface.
+func (p *pebbleBatch) Repr() []byte {
+	// The batch's representation is only valid until the next write, so copy
+	// it.
+	repr := p.batch.Repr()
+	reprCopy := make([]byte, len(repr))
+	copy(reprCopy, repr)
+	return reprCopy
+}
+
+// pebbleDistinctBatch is the distinct view of a pebbleBatch. Its reads go to
+// the parent batch, or to the engine if the parent is write-only, and its
+// writes are buffered until it's closed.
+type pebbleDistinctBatch struct {
+	parent *pebbleBatch
+	batch  *pebble.Batch
+}
+
+var _ ReadWriter = &pebbleDistinctBatch{}
+
+// Close applies the writes of the distinct batch to its parent batch.
+func (p *pebbleDistinctBatch) Close() {
+	if !p.parent.distinctOpen {
+		panic("distinct batch not open")
+	}
+	p.parent.distinctOpen = false
+	if err := p.parent.batch.Apply(p.batch, nil); err != nil {
+		panic(err)
+	}
+	if err := p.batch.Close(); err != nil {
+		panic(err)
+	}
+	p.batch = nil
+}
+
+func (p *pebbleDistinctBatch) Closed() bool {
+	return !p.parent.distinctOpen || p.parent.Closed()
+}
+
+func (p *pebbleDistinctBatch) Get(key MVCCKey) ([]byte, error) {
+	return pebbleGet(p.parent.reader(), key)
+}
+
+func (p *pebbleDistinctBatch) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return pebbleGetProto(p.parent.reader(), key, msg)
+}
+
+func (p *pebbleDistinctBatch) Iterate(
+	start, end MVCCKey, f func(MVCCKeyValue) (bool, error),
+) error {
+	return pebbleIterate(p.parent.reader(), p, start, end, f)
+}
+
+func (p *pebbleDistinctBatch) NewIterator(opts IterOptions) Iterator {
+	return newPebbleIterator(p.parent.reader(), opts, p)
+}
+
+func (p *pebbleDistinctBatch) ApplyBatchRepr(repr []byte, sync bool) error {
+	return pebbleApplyBatchRepr(p.batch, repr)
+}
+
+func (p *pebbleDistinctBatch) Clear(key MVCCKey) error {
+	return pebbleClear(p.batch, key)
+}
+
+func (p *pebbleDistinctBatch) SingleClear(key MVCCKey) error {
+	return pebbleSingleClear(p.batch, key)
+}
+
+func (p *pebbleDistinctBatch) ClearRange(start, end MVCCKey) error {
+	return p.batch.DeleteRange(EncodeKey(start), EncodeKey(end), nil)
+}
+
+func (p *pebbleDistinctBatch) ClearIterRange(iter Iterator, start, end MVCCKey) error {
+	return pebbleClearIterRange(p.batch, iter, start, end)
+}
+
+func (p *pebbleDistinctBatch) Merge(key MVCCKey, value []byte) error {
+	return pebbleMerge(p.batch, key, value)
+}
+
+func (p *pebbleDistinctBatch) Put(key MVCCKey, value []byte) error {
+	return pebblePut(p.batch, key, value)
+}
+
+func (p *pebbleDistinctBatch) LogData(data []byte) error {
+	return p.batch.LogData(data, nil)
+}
+
+func (p *pebbleDistinctBatch) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
+	// No-op. Logical logging disabled.
+}
+
+func pebbleApplyBatchRepr(batch *pebble.Batch, repr []byte) error {
+	var b pebble.Batch
+	if err := b.SetRepr(repr); err != nil {
+		return err
+	}
+	return batch.Apply(&b, nil)
+}
+
+func pebblePut(batch *pebble.Batch, key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return batch.Set(EncodeKey(key), value, nil)
+}
+
+func pebbleMerge(batch *pebble.Batch, key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return batch.Merge(EncodeKey(key), value, nil)
+}
+
+func pebbleClear(batch *pebble.Batch, key MVCCKey) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return batch.Delete(EncodeKey(key), nil)
+}
+
+func pebbleSingleClear(batch *pebble.Batch, key MVCCKey) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return batch.SingleDelete(EncodeKey(key), nil)
+}
@@ -0,0 +1,487 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/petermattis/pebble"
+	"github.com/pkg/errors"
+)
+
+// pebbleIterator is a wrapper around a pebble.Iterator that implements the
+// Iterator interface.
+type pebbleIterator struct {
+	// Underlying iterator for the DB.
+	iter    *pebble.Iterator
+	options pebble.IterOptions
+	// The engine the iterator was created from, used to check that the
+	// iterator isn't used after the engine was closed.
+	engine Reader
+	// Reusable buffers for the encoded bounds and seek keys.
+	lowerBoundBuf []byte
+	upperBoundBuf []byte
+	keyBuf        []byte
+	// Set to true to only iterate over the versions of the key passed to the
+	// last call to Seek or SeekReverse, which is saved in prefixKey. Pebble
+	// doesn't have prefix iterators, so Valid returns false once the iterator
+	// moves past the versions of the key.
+	prefix    bool
+	prefixKey []byte
+	// The number of sstables which were used by the iterator because their
+	// timestamp bounds overlapped with the timestamp hints.
+	timeBoundNumSSTs int
+}
+
+var _ Iterator = &pebbleIterator{}
+
+// newPebbleIterator returns a new iterator over the supplied pebble reader,
+// which may be a DB, a snapshot or an indexed batch. The caller must call
+// Close when finished with the iterator.
+func newPebbleIterator(handle pebble.Reader, opts IterOptions, engine Reader) *pebbleIterator {
+	if !opts.Prefix && len(opts.UpperBound) == 0 && len(opts.LowerBound) == 0 {
+		panic("iterator must set prefix or upper bound or lower bound")
+	}
+
+	p := &pebbleIterator{
+		engine: engine,
+		prefix: opts.Prefix,
+	}
+	if opts.LowerBound != nil {
+		p.lowerBoundBuf = EncodeKeyToBuf(p.lowerBoundBuf, MakeMVCCMetadataKey(opts.LowerBound))
+		p.options.LowerBound = p.lowerBoundBuf
+	}
+	if opts.UpperBound != nil {
+		p.upperBoundBuf = EncodeKeyToBuf(p.upperBoundBuf, MakeMVCCMetadataKey(opts.UpperBound))
+		p.options.UpperBound = p.upperBoundBuf
+	}
+
+	if opts.MinTimestampHint != (hlc.Timestamp{}) || opts.MaxTimestampHint != (hlc.Timestamp{}) {
+		// The table filter mirrors the one of RocksDB iterators, in
+		// c-deps/libroach/iterator.cc.
+		min := encodeTimestamp(opts.MinTimestampHint)
+		max := encodeTimestamp(opts.MaxTimestampHint)
+		p.options.TableFilter = func(userProps map[string]string) bool {
+			tblMin, ok := userProps["crdb.ts.min"]
+			if !ok || len(tblMin) == 0 {
+				p.timeBoundNumSSTs++
+				return true
+			}
+			tblMax, ok := userProps["crdb.ts.max"]
+			if !ok || len(tblMax) == 0 {
+				p.timeBoundNumSSTs++
+				return true
+			}
+			// If the timestamp range of the table overlaps with the timestamp
+			// range we want to iterate, the table might contain timestamps we
+			// care about.
+			used := bytes.Compare(max, []byte(tblMin)) >= 0 && bytes.Compare(min, []byte(tblMax)) <= 0
+			if used {
+				p.timeBoundNumSSTs++
+			}
+			return used
+		}
+	}
+
+	p.iter = handle.NewIter(&p.options)
+	if p.iter == nil {
+		panic("unable to create iterator")
+	}
+	return p
+}
+
+func (p *pebbleIterator) checkEngineOpen() {
+	if p.engine.Closed() {
+		panic("iterator used after backing engine closed")
+	}
+}
+
+// Close implements the Iterator interface.
+func (p *pebbleIterator) Close() {
+	if p.iter == nil {
+		return
+	}
+	if err := p.iter.Close(); err != nil {
+		panic(err)
+	}
+	p.iter = nil
+}
+
+// Seek implements the Iterator interface.
+func (p *pebbleIterator) Seek(key MVCCKey) {
+	p.checkEngineOpen()
+	if p.prefix {
+		p.prefixKey = append(p.prefixKey[:0], key.Key...)
+	}
+	if len(key.Key) == 0 {
+		p.iter.First()
+		return
+	}
+	p.keyBuf = EncodeKeyToBuf(p.keyBuf, key)
+	p.iter.SeekGE(p.keyBuf)
+}
+
+// SeekReverse implements the Iterator interface.
+func (p *pebbleIterator) SeekReverse(key MVCCKey) {
+	p.checkEngineOpen()
+	if p.prefix {
+		p.prefixKey = append(p.prefixKey[:0], key.Key...)
+	}
+	if len(key.Key) == 0 {
+		p.iter.Last()
+		return
+	}
+	p.keyBuf = EncodeKeyToBuf(p.keyBuf, key)
+	if !p.iter.SeekGE(p.keyBuf) {
+		// Maybe the key sorts after the last key in the engine.
+		if p.iter.Error() != nil || !p.iter.Last() {
+			return
+		}
+	}
+	// Make sure the current key is <= the provided key.
+	if MVCCKeyCompare(p.iter.Key(), p.keyBuf) > 0 {
+		p.iter.Prev()
+	}
+}
+
+// Valid implements the Iterator interface.
+func (p *pebbleIterator) Valid() (bool, error) {
+	if err := p.iter.Error(); err != nil {
+		return false, err
+	}
+	if !p.iter.Valid() {
+		return false, nil
+	}
+	if p.prefix {
+		key, _, ok := enginepb.SplitMVCCKey(p.iter.Key())
+		if !ok || !bytes.Equal(key, p.prefixKey) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Next implements the Iterator interface.
+func (p *pebbleIterator) Next() {
+	p.checkEngineOpen()
+	p.iter.Next()
+}
+
+// Prev implements the Iterator interface.
+func (p *pebbleIterator) Prev() {
+	p.checkEngineOpen()
+	p.iter.Prev()
+}
+
+// NextKey implements the Iterator interface. Like DBIterNext, it first tries
+// to step to the next key and only seeks if the current key has more
+// versions.
+func (p *pebbleIterator) NextKey() {
+	p.checkEngineOpen()
+	if valid, err := p.Valid(); err != nil || !valid {
+		return
+	}
+	p.keyBuf = append(p.keyBuf[:0], p.UnsafeKey().Key...)
+	if !p.iter.Next() {
+		return
+	}
+	if bytes.Equal(p.keyBuf, p.UnsafeKey().Key) {
+		// This is a version of the same key, seek past its remaining versions.
+		p.iter.SeekGE(EncodeKey(MVCCKey{Key: roachpb.Key(p.keyBuf).Next()}))
+	}
+}
+
+// PrevKey implements the Iterator interface. Like DBIterPrev, it first tries
+// to step to the previous key and only seeks if it has more versions.
+func (p *pebbleIterator) PrevKey() {
+	p.checkEngineOpen()
+	if valid, err := p.Valid(); err != nil || !valid {
+		return
+	}
+	p.keyBuf = append(p.keyBuf[:0], p.UnsafeKey().Key...)
+	if !p.iter.Prev() {
+		return
+	}
+	if bytes.Equal(p.keyBuf, p.UnsafeKey().Key) {
+		// This is a version of the same key, seek before its metadata key.
+		p.iter.SeekLT(EncodeKey(MakeMVCCMetadataKey(p.keyBuf)))
+	}
+}
+
+// Key implements the Iterator interface.
+func (p *pebbleIterator) Key() MVCCKey {
+	key := p.UnsafeKey()
+	keyCopy := make([]byte, len(key.Key))
+	copy(keyCopy, key.Key)
+	key.Key = keyCopy
+	return key
+}
+
+// Value implements the Iterator interface.
+func (p *pebbleIterator) Value() []byte {
+	value := p.UnsafeValue()
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+	return valueCopy
+}
+
+// ValueProto implements the Iterator interface.
+func (p *pebbleIterator) ValueProto(msg protoutil.Message) error {
+	value := p.UnsafeValue()
+	if len(value) == 0 {
+		return nil
+	}
+	return protoutil.Unmarshal(value, msg)
+}
+
+// UnsafeKey implements the Iterator interface.
+func (p *pebbleIterator) UnsafeKey() MVCCKey {
+	if valid, err := p.Valid(); err != nil || !valid {
+		return MVCCKey{}
+	}
+	mvccKey, err := DecodeMVCCKey(p.iter.Key())
+	if err != nil {
+		return MVCCKey{}
+	}
+	return mvccKey
+}
+
+// UnsafeValue implements the Iterator interface.
+func (p *pebbleIterator) UnsafeValue() []byte {
+	if valid, err := p.Valid(); err != nil || !valid {
+		return nil
+	}
+	return p.iter.Value()
+}
+
+// ComputeStats implements the Iterator interface.
+func (p *pebbleIterator) ComputeStats(
+	start, end MVCCKey, nowNanos int64,
+) (enginepb.MVCCStats, error) {
+	return ComputeStatsGo(p, start, end, nowNanos)
+}
+
+// FindSplitKey implements the Iterator interface. It's a port of
+// MVCCFindSplitKey in c-deps/libroach/mvcc.cc.
+func (p *pebbleIterator) FindSplitKey(
+	start, end, minSplitKey MVCCKey, targetSize int64,
+) (MVCCKey, error) {
+	var sizeSoFar int64
+	var bestSplitKey roachpb.Key
+	bestSplitDiff := int64(math.MaxInt64)
+	var prevKey []byte
+
+	p.Seek(start)
+	for ; ; p.Next() {
+		if valid, err := p.Valid(); err != nil {
+			return MVCCKey{}, err
+		} else if !valid {
+			break
+		}
+		mvccKey, err := DecodeMVCCKey(p.iter.Key())
+		if err != nil {
+			return MVCCKey{}, errors.Wrap(err, "unable to decode key")
+		}
+		if !mvccKey.Less(end) {
+			break
+		}
+
+		valid := isValidSplitKeyGo(mvccKey.Key) && bytes.Compare(mvccKey.Key, minSplitKey.Key) >= 0
+		diff := targetSize - sizeSoFar
+		if diff < 0 {
+			diff = -diff
+		}
+		if valid && diff < bestSplitDiff {
+			bestSplitKey = append(bestSplitKey[:0], mvccKey.Key...)
+			bestSplitDiff = diff
+		}
+		// If diff is increasing, that means we've passed the ideal split point
+		// and should return the first key that we can. Note that bestSplitKey
+		// may still be empty if we haven't reached minSplitKey yet.
+		if diff > bestSplitDiff && bestSplitKey != nil {
+			break
+		}
+
+		isValue := mvccKey.IsValue()
+		valueSize := int64(len(p.iter.Value()))
+		if isValue && bytes.Equal(mvccKey.Key, prevKey) {
+			sizeSoFar += MVCCVersionTimestampSize + valueSize
+		} else {
+			sizeSoFar += int64(len(mvccKey.Key)) + 1 + valueSize
+			if isValue {
+				sizeSoFar += MVCCVersionTimestampSize
+			}
+		}
+		prevKey = append(prevKey[:0], mvccKey.Key...)
+	}
+	return MVCCKey{Key: bestSplitKey}, nil
+}
+
+// isValidSplitKeyGo is the Go implementation of IsValidSplitKey, which
+// doesn't require cgo.
+func isValidSplitKeyGo(key roachpb.Key) bool {
+	if key.Equal(keys.Meta2KeyMax) {
+		// We do not allow splits at Meta2KeyMax. See IsValidSplitKey in
+		// c-deps/libroach/mvcc.cc.
+		return false
+	}
+	for _, span := range keys.NoSplitSpans {
+		if key.Compare(span.Key) > 0 && key.Compare(span.EndKey) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MVCCGet implements the Iterator interface.
+func (p *pebbleIterator) MVCCGet(
+	key roachpb.Key, timestamp hlc.Timestamp, opts MVCCGetOptions,
+) (*roachpb.Value, *roachpb.Intent, error) {
+	if opts.Inconsistent && opts.Txn != nil {
+		return nil, nil, errors.Errorf("cannot allow inconsistent reads within a transaction")
+	}
+	if len(key) == 0 {
+		return nil, nil, emptyKeyError()
+	}
+	p.checkEngineOpen()
+
+	scanner := newPebbleMVCCScanner(
+		p.iter, key, timestamp, 1 /* maxKeys */, opts.Txn,
+		opts.Inconsistent, opts.Tombstones, opts.IgnoreSequence, false, /* reverse */
+	)
+	scanner.get()
+
+	intents, err := p.scannerResult(scanner, timestamp, opts.Txn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !opts.Inconsistent && len(intents) > 0 {
+		return nil, nil, &roachpb.WriteIntentError{Intents: intents}
+	}
+
+	var intent *roachpb.Intent
+	if len(intents) > 1 {
+		return nil, nil, errors.Errorf("expected 0 or 1 intents, got %d", len(intents))
+	} else if len(intents) == 1 {
+		intent = &intents[0]
+	}
+
+	count := scanner.results.count
+	if count > 1 {
+		return nil, nil, errors.Errorf("expected 0 or 1 result, found %d", count)
+	}
+	if count == 0 {
+		return nil, intent, nil
+	}
+
+	// Extract the value from the results.
+	mvccKey, rawValue, _, err := MVCCScanDecodeKeyValue(scanner.results.repr)
+	if err != nil {
+		return nil, nil, err
+	}
+	value := &roachpb.Value{
+		RawBytes:  rawValue,
+		Timestamp: mvccKey.Timestamp,
+	}
+	return value, intent, nil
+}
+
+// MVCCScan implements the Iterator interface.
+func (p *pebbleIterator) MVCCScan(
+	start, end roachpb.Key, max int64, timestamp hlc.Timestamp, opts MVCCScanOptions,
+) (kvData []byte, numKVs int64, resumeSpan *roachpb.Span, intents []roachpb.Intent, err error) {
+	if opts.Inconsistent && opts.Txn != nil {
+		return nil, 0, nil, nil, errors.Errorf("cannot allow inconsistent reads within a transaction")
+	}
+	if len(end) == 0 {
+		return nil, 0, nil, nil, emptyKeyError()
+	}
+	if max == 0 {
+		resumeSpan = &roachpb.Span{Key: start, EndKey: end}
+		return nil, 0, resumeSpan, nil, nil
+	}
+	p.checkEngineOpen()
+
+	seekKey := start
+	if opts.Reverse {
+		seekKey = end
+	}
+	scanner := newPebbleMVCCScanner(
+		p.iter, seekKey, timestamp, max, opts.Txn,
+		opts.Inconsistent, opts.Tombstones, opts.IgnoreSequence, opts.Reverse,
+	)
+	scanner.scan()
+
+	intents, err = p.scannerResult(scanner, timestamp, opts.Txn)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+
+	kvData = scanner.results.repr
+	numKVs = scanner.results.count
+
+	if resumeKey := scanner.resumeKey; resumeKey != nil {
+		if opts.Reverse {
+			resumeSpan = &roachpb.Span{Key: start, EndKey: resumeKey.Next()}
+		} else {
+			resumeSpan = &roachpb.Span{Key: resumeKey, EndKey: end}
+		}
+	}
+
+	if !opts.Inconsistent && len(intents) > 0 {
+		// When encountering intents during a consistent scan we still need to
+		// return the resume key.
+		return nil, 0, resumeSpan, nil, &roachpb.WriteIntentError{Intents: intents}
+	}
+
+	return kvData, numKVs, resumeSpan, intents, nil
+}
+
+// scannerResult returns the error of a completed scan, if any, and otherwise
+// the intents it encountered.
+func (p *pebbleIterator) scannerResult(
+	scanner *pebbleMVCCScanner, timestamp hlc.Timestamp, txn *roachpb.Transaction,
+) ([]roachpb.Intent, error) {
+	if scanner.err != nil {
+		return nil, scanner.err
+	}
+	if err := p.iter.Error(); err != nil {
+		return nil, err
+	}
+	if scanner.uncertaintyTS != (hlc.Timestamp{}) {
+		return nil, roachpb.NewReadWithinUncertaintyIntervalError(
+			timestamp, scanner.uncertaintyTS, txn)
+	}
+	if scanner.intents.count == 0 {
+		return nil, nil
+	}
+	return buildScanIntents(scanner.intents.Finish())
+}
+
+// SetUpperBound implements the Iterator interface.
+func (p *pebbleIterator) SetUpperBound(upperBound roachpb.Key) {
+	p.upperBoundBuf = EncodeKeyToBuf(p.upperBoundBuf, MakeMVCCMetadataKey(upperBound))
+	p.options.UpperBound = p.upperBoundBuf
+	p.iter.SetBounds(p.options.LowerBound, p.options.UpperBound)
+}
+
+// Stats implements the Iterator interface.
+func (p *pebbleIterator) Stats() IteratorStats {
+	return IteratorStats{
+		TimeBoundNumSSTs: p.timeBoundNumSSTs,
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/pkg/errors"
+)
+
+// This file contains a Go implementation of the merge operator in
+// c-deps/libroach/merge.cc, for use by the Pebble engine. Its behavior must
+// match that of the C++ merge operator.
+
+const (
+	mvccValueChecksumSize = 4
+	mvccValueTagPos       = mvccValueChecksumSize
+	mvccValueHeaderSize   = mvccValueTagPos + 1
+)
+
+// merge implements the merge operator of pebble.Merger for MVCCMerger. Pebble
+// doesn't distinguish between full and partial merges, so all of its merges
+// are full merges.
+func merge(key, oldValue, newValue, buf []byte) ([]byte, error) {
+	res, err := mvccMerge(oldValue, newValue, true /* full */)
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, res...), nil
+}
+
+// mvccMerge merges the update, a marshaled MVCCMetadata holding an inline
+// value, into the existing marshaled MVCCMetadata, which may be empty. It
+// mirrors DBMergeOne and DBPartialMergeOne.
+func mvccMerge(existing, update []byte, full bool) ([]byte, error) {
+	var meta, operand enginepb.MVCCMetadata
+	if err := protoutil.Unmarshal(existing, &meta); err != nil {
+		return nil, errors.Wrap(err, "corrupted existing value")
+	}
+	if err := protoutil.Unmarshal(update, &operand); err != nil {
+		return nil, errors.Wrap(err, "corrupted operand value")
+	}
+	if err := mergeValues(&meta, &operand, full); err != nil {
+		return nil, errors.Wrapf(err, "existing=%q, update=%q", existing, update)
+	}
+	return protoutil.Marshal(&meta)
+}
+
+// mergeValues merges the right MVCCMetadata into the left one.
+func mergeValues(left, right *enginepb.MVCCMetadata, full bool) error {
+	if left.RawBytes == nil {
+		left.RawBytes = append([]byte{}, right.RawBytes...)
+		if right.MergeTimestamp != nil {
+			ts := *right.MergeTimestamp
+			left.MergeTimestamp = &ts
+		}
+		if full && isTimeSeriesData(left.RawBytes) {
+			var err error
+			left.RawBytes, err = consolidateTimeSeriesValue(left.RawBytes)
+			return err
+		}
+		return nil
+	}
+
+	if right.RawBytes == nil {
+		return errors.New("inconsistent value types for merge (left = bytes, right = ?)")
+	}
+	// Replay Advisory: Because merge commands pass through raft, it is possible
+	// for merging values to be "replayed". Currently, the only actual use of
+	// the merge system is for time series data, which is safe against replay;
+	// however, this property is not general for all potential mergeable types.
+	if isTimeSeriesData(left.RawBytes) || isTimeSeriesData(right.RawBytes) {
+		if !isTimeSeriesData(left.RawBytes) || !isTimeSeriesData(right.RawBytes) {
+			return errors.New(
+				"inconsistent value types for merging time series data (type(left) != type(right))")
+		}
+		var err error
+		left.RawBytes, err = mergeTimeSeriesValues(left.RawBytes, right.RawBytes, full)
+		return err
+	}
+	left.RawBytes = append(left.RawBytes, valueDataBytes(right.RawBytes)...)
+	return nil
+}
+
+func valueDataBytes(val []byte) []byte {
+	if len(val) < mvccValueHeaderSize {
+		return nil
+	}
+	return val[mvccValueHeaderSize:]
+}
+
+func isTimeSeriesData(val []byte) bool {
+	return len(val) >= mvccValueHeaderSize &&
+		roachpb.ValueType(val[mvccValueTagPos]) == roachpb.ValueType_TIMESERIES
+}
+
+func parseTimeSeriesFromValue(val []byte) (roachpb.InternalTimeSeriesData, error) {
+	var ts roachpb.InternalTimeSeriesData
+	if len(val) < mvccValueHeaderSize {
+		return ts, errors.New("InternalTimeSeriesData could not be parsed from bytes")
+	}
+	if err := protoutil.Unmarshal(valueDataBytes(val), &ts); err != nil {
+		return ts, errors.Wrap(err, "InternalTimeSeriesData could not be parsed from bytes")
+	}
+	return ts, nil
+}
+
+// serializeTimeSeriesToValue marshals the time series data into a value with
+// a zero checksum, like SerializeTimeSeriesToValue in merge.cc.
+func serializeTimeSeriesToValue(ts *roachpb.InternalTimeSeriesData) ([]byte, error) {
+	val := make([]byte, mvccValueHeaderSize+ts.Size())
+	val[mvccValueTagPos] = byte(roachpb.ValueType_TIMESERIES)
+	if _, err := protoutil.MarshalToWithoutFuzzing(ts, val[mvccValueHeaderSize:]); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// appendTimeSeries appends the samples and columns of right to left, which is
+// what proto's MergeFrom does for the repeated fields of
+// InternalTimeSeriesData.
+func appendTimeSeries(left, right *roachpb.InternalTimeSeriesData) {
+	left.Samples = append(left.Samples, right.Samples...)
+	left.Offset = append(left.Offset, right.Offset...)
+	left.Last = append(left.Last, right.Last...)
+	left.Count = append(left.Count, right.Count...)
+	left.Sum = append(left.Sum, right.Sum...)
+	left.Max = append(left.Max, right.Max...)
+	left.Min = append(left.Min, right.Min...)
+	left.First = append(left.First, right.First...)
+	left.Variance = append(left.Variance, right.Variance...)
+}
+
+func mergeTimeSeriesValues(left, right []byte, full bool) ([]byte, error) {
+	leftTS, err := parseTimeSeriesFromValue(left)
+	if err != nil {
+		return nil, errors.Wrap(err, "left")
+	}
+	rightTS, err := parseTimeSeriesFromValue(right)
+	if err != nil {
+		return nil, errors.Wrap(err, "right")
+	}
+	if leftTS.StartTimestampNanos != rightTS.StartTimestampNanos {
+		return nil, errors.New("TimeSeries merge failed due to mismatched start timestamps")
+	}
+	if leftTS.SampleDurationNanos != rightTS.SampleDurationNanos {
+		return nil, errors.New("TimeSeries merge failed due to mismatched sample durations")
+	}
+
+	// Determine if we are using row or columnar format, by checking if either
+	// format has a "last" column.
+	useColumnFormat := len(leftTS.Last) > 0 || len(rightTS.Last) > 0
+
+	// If only a partial merge, do not sort and combine - instead, just quickly
+	// merge the two values together. Values will be processed later after a
+	// full merge.
+	if !full {
+		// If using columnar format, convert both operands even in a partial
+		// merge. This is necessary to keep the order of merges stable.
+		if useColumnFormat {
+			convertToColumnar(&leftTS)
+			convertToColumnar(&rightTS)
+		}
+		appendTimeSeries(&leftTS, &rightTS)
+		return serializeTimeSeriesToValue(&leftTS)
+	}
+
+	if useColumnFormat {
+		convertToColumnar(&leftTS)
+		convertToColumnar(&rightTS)
+
+		// Find the minimum offset of the right collection, and find the highest
+		// index in the left collection which is greater than or equal to that
+		// minimum. This determines how many elements of the left collection
+		// will need to be re-sorted and de-duplicated.
+		firstUnsorted := len(leftTS.Offset)
+		if len(rightTS.Offset) > 0 {
+			minOffset := rightTS.Offset[0]
+			for _, o := range rightTS.Offset[1:] {
+				if o < minOffset {
+					minOffset = o
+				}
+			}
+			firstUnsorted = sort.Search(len(leftTS.Offset), func(i int) bool {
+				return leftTS.Offset[i] >= minOffset
+			})
+		}
+		appendTimeSeries(&leftTS, &rightTS)
+		sortAndDeduplicateColumns(&leftTS, firstUnsorted)
+		return serializeTimeSeriesToValue(&leftTS)
+	}
+
+	// Sort the samples of the right collection. Those of the left collection
+	// are assumed to be sorted already.
+	sort.SliceStable(rightTS.Samples, func(i, j int) bool {
+		return rightTS.Samples[i].Offset < rightTS.Samples[j].Offset
+	})
+
+	// Merge the samples of both sides. Only the most recently merged sample
+	// with a given offset is kept.
+	newTS := roachpb.InternalTimeSeriesData{
+		StartTimestampNanos: leftTS.StartTimestampNanos,
+		SampleDurationNanos: leftTS.SampleDurationNanos,
+	}
+	l, r := leftTS.Samples, rightTS.Samples
+	for len(l) > 0 || len(r) > 0 {
+		var nextOffset int32
+		switch {
+		case len(l) == 0:
+			nextOffset = r[0].Offset
+		case len(r) == 0:
+			nextOffset = l[0].Offset
+		case l[0].Offset <= r[0].Offset:
+			nextOffset = l[0].Offset
+		default:
+			nextOffset = r[0].Offset
+		}
+		var src roachpb.InternalTimeSeriesSample
+		for len(l) > 0 && l[0].Offset == nextOffset {
+			src, l = l[0], l[1:]
+		}
+		for len(r) > 0 && r[0].Offset == nextOffset {
+			src, r = r[0], r[1:]
+		}
+		newTS.Samples = append(newTS.Samples, src)
+	}
+	return serializeTimeSeriesToValue(&newTS)
+}
+
+func consolidateTimeSeriesValue(val []byte) ([]byte, error) {
+	ts, err := parseTimeSeriesFromValue(val)
+	if err != nil {
+		return nil, err
+	}
+
+	// Detect if the value is in columnar or row format. Columnar format is
+	// detected by the presence of a non-zero-length offset field.
+	if len(ts.Offset) > 0 {
+		// It's possible that, due to partial merges, the value contains both
+		// row-format and column-format data. Convert it all to columnar.
+		convertToColumnar(&ts)
+		sortAndDeduplicateColumns(&ts, 0)
+	} else {
+		sort.SliceStable(ts.Samples, func(i, j int) bool {
+			return ts.Samples[i].Offset < ts.Samples[j].Offset
+		})
+		// Deduplicate the samples, keeping only the last sample merged with a
+		// given offset.
+		deduped := ts.Samples[:0]
+		for i, s := range ts.Samples {
+			if i+1 < len(ts.Samples) && ts.Samples[i+1].Offset == s.Offset {
+				continue
+			}
+			deduped = append(deduped, s)
+		}
+		ts.Samples = deduped
+	}
+	return serializeTimeSeriesToValue(&ts)
+}
+
+// convertToColumnar converts the row-format samples of the time series data
+// to the columnar format. While the row format contains other values (such
+// as min and max), these were not stored in actual usage, so only the offset
+// and the sum, as the last value, are converted.
+func convertToColumnar(ts *roachpb.InternalTimeSeriesData) {
+	if len(ts.Samples) == 0 {
+		return
+	}
+	for _, s := range ts.Samples {
+		ts.Offset = append(ts.Offset, s.Offset)
+		ts.Last = append(ts.Last, s.Sum)
+	}
+	ts.Samples = nil
+}
+
+// sortAndDeduplicateColumns sorts the columns of the time series data from
+// index firstUnsorted onwards by offset, keeping only the last element merged
+// for any given offset. The elements before firstUnsorted must be sorted and
+// have lower offsets than all the others.
+func sortAndDeduplicateColumns(ts *roachpb.InternalTimeSeriesData, firstUnsorted int) {
+	order := make([]int, 0, len(ts.Offset)-firstUnsorted)
+	for i := firstUnsorted; i < len(ts.Offset); i++ {
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return ts.Offset[order[i]] < ts.Offset[order[j]]
+	})
+	deduped := order[:0]
+	for i, idx := range order {
+		if i+1 < len(order) && ts.Offset[order[i+1]] == ts.Offset[idx] {
+			continue
+		}
+		deduped = append(deduped, idx)
+	}
+	order = deduped
+
+	ts.Offset = permuteInt32s(ts.Offset, firstUnsorted, order)
+	ts.Last = permuteFloat64s(ts.Last, firstUnsorted, order)
+	// These columns are only present at resolutions generated as rollups. We
+	// detect this by checking if there are any count columns present (the
+	// choice of "count" is arbitrary, all of these columns will be present or
+	// not).
+	if len(ts.Count) > 0 {
+		count := append([]uint32(nil), ts.Count[:firstUnsorted]...)
+		for _, idx := range order {
+			count = append(count, ts.Count[idx])
+		}
+		ts.Count = count
+		ts.Sum = permuteFloat64s(ts.Sum, firstUnsorted, order)
+		ts.Min = permuteFloat64s(ts.Min, firstUnsorted, order)
+		ts.Max = permuteFloat64s(ts.Max, firstUnsorted, order)
+		ts.First = permuteFloat64s(ts.First, firstUnsorted, order)
+		ts.Variance = permuteFloat64s(ts.Variance, firstUnsorted, order)
+	}
+}
+
+func permuteInt32s(col []int32, prefix int, order []int) []int32 {
+	res := append(make([]int32, 0, prefix+len(order)), col[:prefix]...)
+	for _, idx := range order {
+		res = append(res, col[idx])
+	}
+	return res
+}
+
+func permuteFloat64s(col []float64, prefix int, order []int) []float64 {
+	res := append(make([]float64, 0, prefix+len(order)), col[:prefix]...)
+	for _, idx := range order {
+		res = append(res, col[idx])
+	}
+	return res
+}
@@ -0,0 +1,608 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/petermattis/pebble"
+	"github.com/pkg/errors"
+)
+
+// maxItersBeforeSeek is the number of calls to iter.{Next,Prev}() to perform
+// when looking for the next/prev key or a particular version before calling
+// iter.Seek{GE,LT}(). Note that pebbleMVCCScanner makes this number adaptive.
+// It starts with a value of maxItersBeforeSeek/2 and increases the value
+// every time a call to iter.{Next,Prev}() successfully finds the desired next
+// key. It decrements the value whenever a call to iter.Seek{GE,LT}() occurs.
+// The adaptive iters-before-seek value is constrained to the range
+// [1,maxItersBeforeSeek].
+const maxItersBeforeSeek = 10
+
+// pebbleResults accumulates the key/value pairs returned by a
+// pebbleMVCCScanner, in the format of the C++ scanner's results, which is
+// decoded by MVCCScanDecodeKeyValue.
+type pebbleResults struct {
+	count int64
+	repr  []byte
+}
+
+// put appends the key and value, prefixed by their sizes encoded as a single
+// little-endian uint64, like chunkedBuffer::Put.
+func (p *pebbleResults) put(key []byte, value []byte) {
+	var sizes [8]byte
+	binary.LittleEndian.PutUint32(sizes[0:4], uint32(len(value)))
+	binary.LittleEndian.PutUint32(sizes[4:8], uint32(len(key)))
+	p.repr = append(p.repr, sizes[:]...)
+	p.repr = append(p.repr, key...)
+	p.repr = append(p.repr, value...)
+	p.count++
+}
+
+func (p *pebbleResults) clear() {
+	*p = pebbleResults{}
+}
+
+// pebbleMVCCScanner implements MVCCGet and MVCCScan on top of a pebble
+// iterator. It's a port of the C++ mvccScanner in c-deps/libroach/mvcc.h,
+// whose comments describe the algorithm in more detail, and must behave
+// identically.
+//
+// The MVCC data is sorted by key and descending timestamp. If a key has a
+// write intent, a key with a zero timestamp and an MVCCMetadata value sorts
+// first. The scanner relies on the bounds of the iterator to stop the scan.
+//
+// WARNING: Do not use parent.Key() or parent.Value() directly, use curRawKey,
+// curKey and curValue instead. In order to efficiently support reverse scans,
+// the scanner maintains a single entry buffer that allows "peeking" at the
+// previous key, during which the iterator points to different data than what
+// the scanner considers the current key/value.
+type pebbleMVCCScanner struct {
+	parent  *pebble.Iterator
+	reverse bool
+	peeked  bool
+	// The key to seek to: the start key of a forward scan or get, or the end
+	// key of a reverse scan.
+	seekKey roachpb.Key
+	// The timestamp to read at, and the maximum number of keys to return.
+	ts      hlc.Timestamp
+	maxKeys int64
+	// The reading transaction, if any.
+	txn *roachpb.Transaction
+	// Options copied from MVCC{Scan,Get}Options.
+	inconsistent     bool
+	tombstones       bool
+	ignoreSeq        bool
+	checkUncertainty bool
+	// The metadata of the intent or inline value at the current key.
+	meta enginepb.MVCCMetadata
+	// curRawKey holds either parent.Key() or the saved value of parent.Key()
+	// if we've peeked at the previous key (and peeked is true). curKey is the
+	// decoded key and curTS its timestamp. curValue holds either
+	// parent.Value() or its saved value if we've peeked.
+	curRawKey []byte
+	curKey    []byte
+	curTS     hlc.Timestamp
+	curValue  []byte
+	keyBuf    []byte
+	savedBuf  []byte
+	results   pebbleResults
+	intents   RocksDBBatchBuilder
+	// resumeKey is set if the scan stopped because it retrieved maxKeys keys.
+	resumeKey roachpb.Key
+	// uncertaintyTS is set to the timestamp of the value which caused an
+	// uncertainty error, if any.
+	uncertaintyTS hlc.Timestamp
+	// err is set if the scan failed.
+	err             error
+	itersBeforeSeek int
+}
+
+func newPebbleMVCCScanner(
+	parent *pebble.Iterator,
+	seekKey roachpb.Key,
+	ts hlc.Timestamp,
+	maxKeys int64,
+	txn *roachpb.Transaction,
+	inconsistent, tombstones, ignoreSeq, reverse bool,
+) *pebbleMVCCScanner {
+	p := &pebbleMVCCScanner{
+		parent:          parent,
+		reverse:         reverse,
+		seekKey:         seekKey,
+		ts:              ts,
+		maxKeys:         maxKeys,
+		txn:             txn,
+		inconsistent:    inconsistent,
+		tombstones:      tombstones,
+		ignoreSeq:       ignoreSeq,
+		itersBeforeSeek: maxItersBeforeSeek / 2,
+	}
+	if txn != nil {
+		p.checkUncertainty = ts.Less(txn.MaxTimestamp)
+	}
+	return p
+}
+
+// get retrieves the value of seekKey.
+func (p *pebbleMVCCScanner) get() {
+	if !p.iterSeek(MVCCKey{Key: p.seekKey}) {
+		return
+	}
+	// Unlike the prefix iterators of RocksDB, the iterator may have moved past
+	// the key.
+	if !bytes.Equal(p.curKey, p.seekKey) {
+		return
+	}
+	p.getAndAdvance()
+}
+
+// scan retrieves the values in the bounds of the iterator.
+func (p *pebbleMVCCScanner) scan() {
+	if p.reverse {
+		if !p.iterSeekReverse(MVCCKey{Key: p.seekKey}) {
+			return
+		}
+	} else {
+		if !p.iterSeek(MVCCKey{Key: p.seekKey}) {
+			return
+		}
+	}
+
+	for p.getAndAdvance() {
+	}
+
+	if p.results.count == p.maxKeys && p.advanceKey() {
+		// curKey may point into savedBuf or the iterator's memory, so copy it.
+		p.resumeKey = append(roachpb.Key(nil), p.curKey...)
+	}
+}
+
+// getFromIntentHistory adds the value in the intent history with the highest
+// sequence number which is less than or equal to the read sequence, returning
+// false if there is no such value.
+func (p *pebbleMVCCScanner) getFromIntentHistory() bool {
+	history := p.meta.IntentHistory
+	// upIdx is the index of the first intent in history with a sequence number
+	// greater than the read sequence.
+	upIdx := sort.Search(len(history), func(i int) bool {
+		return history[i].Sequence > p.txn.Sequence
+	})
+	if upIdx == 0 {
+		// It is possible that no intent exists such that the sequence is less
+		// than the read sequence. In this case, we cannot read a value from the
+		// intent history.
+		return false
+	}
+	intent := history[upIdx-1]
+	if len(intent.Value) > 0 || p.tombstones {
+		p.results.put(p.curRawKey, intent.Value)
+	}
+	return true
+}
+
+func (p *pebbleMVCCScanner) uncertaintyError(ts hlc.Timestamp) bool {
+	p.uncertaintyTS = ts
+	p.results.clear()
+	p.intents.reset()
+	return false
+}
+
+func (p *pebbleMVCCScanner) setError(err error) bool {
+	p.err = err
+	return false
+}
+
+// getAndAdvance retrieves the value at the current key, if any, and advances
+// to the next key. It returns false once the scan is done.
+func (p *pebbleMVCCScanner) getAndAdvance() bool {
+	if p.curTS != (hlc.Timestamp{}) {
+		if !p.ts.Less(p.curTS) {
+			// 1. Fast path: there is no intent and our read timestamp is newer
+			// than the most recent version's timestamp.
+			return p.addAndAdvance(p.curValue)
+		}
+
+		if p.checkUncertainty {
+			// 2. Our txn's read timestamp is less than the max timestamp seen by
+			// the txn. We need to check for clock uncertainty errors.
+			if !p.txn.MaxTimestamp.Less(p.curTS) {
+				return p.uncertaintyError(p.curTS)
+			}
+			return p.seekVersion(p.txn.MaxTimestamp, true)
+		}
+
+		// 3. Our txn's read timestamp is greater than or equal to the max
+		// timestamp seen by the txn so clock uncertainty checks are
+		// unnecessary. We need to seek to the desired version of the value.
+		return p.seekVersion(p.ts, false)
+	}
+
+	if len(p.curValue) == 0 {
+		return p.setError(errors.New("zero-length mvcc metadata"))
+	}
+	p.meta.Reset()
+	if err := protoutil.Unmarshal(p.curValue, &p.meta); err != nil {
+		return p.setError(errors.Wrap(err, "unable to decode MVCCMetadata"))
+	}
+
+	if p.meta.RawBytes != nil {
+		// 4. Emit immediately if the value is inline.
+		return p.addAndAdvance(p.meta.RawBytes)
+	}
+
+	if p.meta.Txn == nil {
+		return p.setError(errors.New("intent without transaction"))
+	}
+
+	ownIntent := p.txn != nil && p.meta.Txn.ID == p.txn.ID
+	metaTS := hlc.Timestamp(p.meta.Timestamp)
+	// If we end up ignoring the intent, we read at a timestamp strictly below
+	// it, but which also does not exceed our read timestamp.
+	prevTS := p.ts
+	if !p.ts.Less(metaTS) {
+		prevTS = metaTS.Prev()
+	}
+
+	if p.ts.Less(metaTS) && !ownIntent {
+		// 5. The key contains an intent, but we're reading before the intent.
+		// Seek to the desired version. Note that if we own the intent (i.e.
+		// we're reading transactionally) we want to read the intent regardless
+		// of our read timestamp and fall into case 8 below.
+		return p.seekVersion(p.ts, false)
+	}
+
+	if p.inconsistent {
+		// 6. The key contains an intent and we're doing an inconsistent read at
+		// a timestamp newer than the intent. We ignore the intent by insisting
+		// that the timestamp we're reading at is a historical timestamp < the
+		// intent timestamp. However, we return the intent separately; the
+		// caller may want to resolve it.
+		if p.results.count == p.maxKeys {
+			// We've already retrieved the desired number of keys and now we're
+			// adding the resume key. The intents should only correspond to KVs
+			// that lie before the resume key.
+			return false
+		}
+		p.intents.Put(MVCCKey{Key: p.curKey}, p.curValue)
+		return p.seekVersion(prevTS, false)
+	}
+
+	if !ownIntent {
+		// 7. The key contains an intent which was not written by our
+		// transaction and our read timestamp is newer than that of the intent.
+		// This will result in a WriteIntentError. We continue scanning so that
+		// we can return all of the intents in the scan range.
+		p.intents.Put(MVCCKey{Key: p.curKey}, p.curValue)
+		return p.advanceKey()
+	}
+
+	if p.txn.Epoch == p.meta.Txn.Epoch {
+		if p.ignoreSeq || p.txn.Sequence >= p.meta.Txn.Sequence {
+			// 8. We're reading our own txn's intent at an equal or higher
+			// sequence. Note that we read at the intent timestamp, not at our
+			// read timestamp as the intent timestamp may have been pushed
+			// forward by another transaction. Txn's always need to read their
+			// own writes.
+			return p.seekVersion(metaTS, false)
+		}
+		// 9. We're reading our own txn's intent at a lower sequence than is
+		// currently present in the intent. If there exists a value in the
+		// intent history that has a sequence number equal to or less than the
+		// read sequence, read that value.
+		if p.getFromIntentHistory() {
+			return p.advanceKey()
+		}
+		// 10. If no value in the intent history has a sequence number equal to
+		// or less than the read, we must ignore the intents laid down by the
+		// transaction all together.
+		return p.seekVersion(prevTS, false)
+	}
+
+	if p.txn.Epoch < p.meta.Txn.Epoch {
+		// 11. We're reading our own txn's intent but the current txn has an
+		// earlier epoch than the intent. Return an error so that the earlier
+		// incarnation of our transaction aborts (presumably this is some
+		// operation that was retried).
+		return p.setError(errors.Errorf(
+			"failed to read with epoch %d due to a write intent with epoch %d",
+			p.txn.Epoch, p.meta.Txn.Epoch))
+	}
+
+	// 12. We're reading our own txn's intent but the current txn has a later
+	// epoch than the intent. This can happen if the txn was restarted and an
+	// earlier iteration wrote the value we're now reading. In this case, we
+	// ignore the intent and read the previous value as if the transaction
+	// were starting fresh.
+	return p.seekVersion(prevTS, false)
+}
+
+// nextKey advances the iterator to point to the next MVCC key greater than
+// curKey. Returns false if the iterator is exhausted or an error occurs.
+func (p *pebbleMVCCScanner) nextKey() bool {
+	p.keyBuf = append(p.keyBuf[:0], p.curKey...)
+
+	for i := 0; i < p.itersBeforeSeek; i++ {
+		if !p.iterNext() {
+			return false
+		}
+		if !bytes.Equal(p.curKey, p.keyBuf) {
+			p.incrementItersBeforeSeek()
+			return true
+		}
+	}
+
+	// We're pointed at a different version of the same key. Fall back to
+	// seeking to the next key.
+	p.decrementItersBeforeSeek()
+	return p.iterSeek(MVCCKey{Key: roachpb.Key(p.keyBuf).Next()})
+}
+
+// backwardLatestVersion backs up the iterator to the latest version of the
+// specified key. The parameter i is used to maintain the iteration count
+// between the loop here and the caller (usually prevKey). Returns false if an
+// error occurred.
+func (p *pebbleMVCCScanner) backwardLatestVersion(key []byte, i int) bool {
+	p.keyBuf = append(p.keyBuf[:0], key...)
+
+	for ; i < p.itersBeforeSeek; i++ {
+		peekedKey, ok := p.iterPeekPrev()
+		if !ok {
+			return false
+		}
+		if !bytes.Equal(peekedKey, p.keyBuf) {
+			// The key changed which means the current key is the latest version.
+			p.incrementItersBeforeSeek()
+			return true
+		}
+		if !p.iterPrev() {
+			return false
+		}
+	}
+
+	p.decrementItersBeforeSeek()
+	return p.iterSeek(MVCCKey{Key: p.keyBuf})
+}
+
+// prevKey backs up the iterator to point to the prev MVCC key less than the
+// specified key. Returns false if the iterator is exhausted or an error
+// occurs.
+func (p *pebbleMVCCScanner) prevKey(key []byte) bool {
+	p.keyBuf = append(p.keyBuf[:0], key...)
+
+	for i := 0; i < p.itersBeforeSeek; i++ {
+		peekedKey, ok := p.iterPeekPrev()
+		if !ok {
+			return false
+		}
+		if peekedKey == nil {
+			// iterPeekPrev may return true even when it did not find a key,
+			// in which case there is no previous key.
+			return false
+		}
+		if !bytes.Equal(peekedKey, p.keyBuf) {
+			return p.backwardLatestVersion(peekedKey, i+1)
+		}
+		if !p.iterPrev() {
+			return false
+		}
+	}
+
+	p.decrementItersBeforeSeek()
+	return p.iterSeekReverse(MVCCKey{Key: p.keyBuf})
+}
+
+// advanceKey advances the iterator to point to the next MVCC key. Returns
+// false if the iterator is exhausted or an error occurs.
+func (p *pebbleMVCCScanner) advanceKey() bool {
+	if p.reverse {
+		return p.prevKey(p.curKey)
+	}
+	return p.nextKey()
+}
+
+func (p *pebbleMVCCScanner) advanceKeyAtEnd() bool {
+	if p.reverse {
+		// Iterating to the next key might have caused the iterator to reach the
+		// end of the key space. If that happens, back up to the very last key.
+		p.peeked = false
+		p.parent.Last()
+		if !p.updateCurrent() {
+			return false
+		}
+		return p.advanceKey()
+	}
+	// We've reached the end of the iterator and there is nothing left to do.
+	return false
+}
+
+func (p *pebbleMVCCScanner) advanceKeyAtNewKey(key []byte) bool {
+	if p.reverse {
+		// We've advanced to the next key but need to move back to the previous
+		// key.
+		return p.prevKey(key)
+	}
+	// We're already at the new key so there is nothing to do.
+	return true
+}
+
+func (p *pebbleMVCCScanner) addAndAdvance(val []byte) bool {
+	// Don't include deleted versions (len(val) == 0), unless we've been
+	// instructed to include tombstones in the results.
+	if len(val) > 0 || p.tombstones {
+		p.results.put(p.curRawKey, val)
+		if p.results.count == p.maxKeys {
+			return false
+		}
+	}
+	return p.advanceKey()
+}
+
+// seekVersion advances the iterator to point to an MVCC version of the
+// current key that is earlier than ts, and adds it. Returns false if the
+// iterator is exhausted or an error occurs, and otherwise advances the
+// iterator to the next key. If checkUncertainty is true, then observing any
+// version of the key with a timestamp larger than our read timestamp results
+// in an uncertainty error.
+func (p *pebbleMVCCScanner) seekVersion(ts hlc.Timestamp, checkUncertainty bool) bool {
+	p.keyBuf = append(p.keyBuf[:0], p.curKey...)
+
+	for i := 0; i < p.itersBeforeSeek; i++ {
+		if !p.iterNext() {
+			return p.advanceKeyAtEnd()
+		}
+		if !bytes.Equal(p.curKey, p.keyBuf) {
+			p.incrementItersBeforeSeek()
+			return p.advanceKeyAtNewKey(p.keyBuf)
+		}
+		if !ts.Less(p.curTS) {
+			p.incrementItersBeforeSeek()
+			if checkUncertainty && p.ts.Less(p.curTS) {
+				return p.uncertaintyError(p.curTS)
+			}
+			return p.addAndAdvance(p.curValue)
+		}
+	}
+
+	p.decrementItersBeforeSeek()
+	if !p.iterSeek(MVCCKey{Key: p.keyBuf, Timestamp: ts}) {
+		return p.advanceKeyAtEnd()
+	}
+	if !bytes.Equal(p.curKey, p.keyBuf) {
+		return p.advanceKeyAtNewKey(p.keyBuf)
+	}
+	if !ts.Less(p.curTS) {
+		if checkUncertainty && p.ts.Less(p.curTS) {
+			return p.uncertaintyError(p.curTS)
+		}
+		return p.addAndAdvance(p.curValue)
+	}
+	return p.advanceKey()
+}
+
+func (p *pebbleMVCCScanner) updateCurrent() bool {
+	if !p.parent.Valid() {
+		return false
+	}
+	p.curRawKey = p.parent.Key()
+	p.curValue = p.parent.Value()
+	var err error
+	p.curKey, p.curTS, err = enginepb.DecodeKey(p.curRawKey)
+	if err != nil {
+		return p.setError(errors.Wrap(err, "failed to split mvcc key"))
+	}
+	return true
+}
+
+// iterSeek positions the iterator at the first key that is greater than or
+// equal to key.
+func (p *pebbleMVCCScanner) iterSeek(key MVCCKey) bool {
+	p.peeked = false
+	p.parent.SeekGE(EncodeKey(key))
+	return p.updateCurrent()
+}
+
+// iterSeekReverse positions the iterator at the latest version of the last
+// key that is less than key.
+func (p *pebbleMVCCScanner) iterSeekReverse(key MVCCKey) bool {
+	p.peeked = false
+	p.parent.SeekLT(EncodeKey(key))
+	if !p.updateCurrent() {
+		return false
+	}
+	if p.curTS == (hlc.Timestamp{}) {
+		// We landed on an intent or inline value.
+		return true
+	}
+
+	// We landed on a versioned value, we need to back up to find the latest
+	// version.
+	return p.backwardLatestVersion(p.curKey, 0)
+}
+
+func (p *pebbleMVCCScanner) iterNext() bool {
+	if p.reverse && p.peeked {
+		// If we had peeked at the previous entry, we need to advance the
+		// iterator twice to get to the real next entry.
+		p.peeked = false
+		if !p.parent.Next() {
+			return false
+		}
+	}
+	p.parent.Next()
+	return p.updateCurrent()
+}
+
+func (p *pebbleMVCCScanner) iterPrev() bool {
+	if p.peeked {
+		p.peeked = false
+		return p.updateCurrent()
+	}
+	p.parent.Prev()
+	return p.updateCurrent()
+}
+
+// iterPeekPrev returns the key of the entry before the current iterator
+// position, without changing what the scanner considers the current entry.
+// It returns a nil key if there is no previous entry.
+func (p *pebbleMVCCScanner) iterPeekPrev() ([]byte, bool) {
+	if !p.peeked {
+		p.peeked = true
+		// We need to save a copy of the current iterator key and value and
+		// adjust curRawKey, curKey and curValue to point to this saved data.
+		p.savedBuf = append(p.savedBuf[:0], p.curRawKey...)
+		p.savedBuf = append(p.savedBuf, p.curValue...)
+		p.curRawKey = p.savedBuf[:len(p.curRawKey)]
+		p.curValue = p.savedBuf[len(p.curRawKey):]
+		var ok bool
+		if p.curKey, _, ok = enginepb.SplitMVCCKey(p.curRawKey); !ok {
+			return nil, p.setError(errors.New("failed to split mvcc key"))
+		}
+
+		// With the current iterator state saved we can move the iterator to
+		// the previous entry.
+		if !p.parent.Prev() {
+			// Peeking at the previous key should never leave the iterator
+			// invalid. Instead, we seek back to the first key and return a nil
+			// key. Note that this prevents using reverse scan to scan to the
+			// empty key.
+			p.peeked = false
+			p.parent.First()
+			return nil, p.updateCurrent()
+		}
+	}
+
+	peekedKey, _, ok := enginepb.SplitMVCCKey(p.parent.Key())
+	if !ok {
+		return nil, p.setError(errors.New("failed to split mvcc key"))
+	}
+	return peekedKey, true
+}
+
+func (p *pebbleMVCCScanner) incrementItersBeforeSeek() {
+	p.itersBeforeSeek++
+	if p.itersBeforeSeek > maxItersBeforeSeek {
+		p.itersBeforeSeek = maxItersBeforeSeek
+	}
+}
+
+func (p *pebbleMVCCScanner) decrementItersBeforeSeek() {
+	p.itersBeforeSeek--
+	if p.itersBeforeSeek < 1 {
+		p.itersBeforeSeek = 1
+	}
+}
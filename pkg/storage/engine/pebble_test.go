@@ -0,0 +1,177 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/petermattis/pebble"
+)
+
+func TestMVCCComparer(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	keys := []MVCCKey{
+		{Key: roachpb.Key("a")},
+		{Key: roachpb.Key("a"), Timestamp: hlc.Timestamp{WallTime: 2, Logical: 1}},
+		{Key: roachpb.Key("a"), Timestamp: hlc.Timestamp{WallTime: 2}},
+		{Key: roachpb.Key("a"), Timestamp: hlc.Timestamp{WallTime: 1}},
+		{Key: roachpb.Key("a\x00")},
+		{Key: roachpb.Key("a\x00"), Timestamp: hlc.Timestamp{WallTime: 1}},
+		{Key: roachpb.Key("b")},
+		{Key: roachpb.Key("b"), Timestamp: hlc.Timestamp{WallTime: 3}},
+	}
+
+	encoded := make([][]byte, len(keys))
+	for i, key := range keys {
+		encoded[i] = EncodeKey(key)
+	}
+	rand.Shuffle(len(encoded), func(i, j int) {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	})
+	sort.Slice(encoded, func(i, j int) bool {
+		return MVCCComparer.Compare(encoded[i], encoded[j]) < 0
+	})
+
+	for i, key := range keys {
+		decoded, err := DecodeMVCCKey(encoded[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.Equal(key) {
+			t.Errorf("%d: expected %s, but got %s", i, key, decoded)
+		}
+		// The prefix of the key, as used by bloom filters, is the user key.
+		prefix := encoded[i][:MVCCComparer.Split(encoded[i])]
+		if !key.Key.Equal(prefix) {
+			t.Errorf("%d: expected prefix %x, but got %x", i, key.Key, prefix)
+		}
+	}
+}
+
+func TestPebbleTimeBoundPropCollector(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts1 := hlc.Timestamp{WallTime: 1}
+	ts2 := hlc.Timestamp{WallTime: 2}
+	ts3 := hlc.Timestamp{WallTime: 3}
+
+	intent := func(ts hlc.Timestamp) []byte {
+		meta := enginepb.MVCCMetadata{
+			Txn:       &enginepb.TxnMeta{ID: uuid.MakeV4(), Timestamp: ts},
+			Timestamp: hlc.LegacyTimestamp(ts),
+		}
+		b, err := protoutil.Marshal(&meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	testCases := []struct {
+		name     string
+		kvs      []MVCCKeyValue
+		min, max hlc.Timestamp
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "versions",
+			kvs: []MVCCKeyValue{
+				{Key: MVCCKey{Key: roachpb.Key("a"), Timestamp: ts2}},
+				{Key: MVCCKey{Key: roachpb.Key("a"), Timestamp: ts1}},
+				{Key: MVCCKey{Key: roachpb.Key("b"), Timestamp: ts3}},
+			},
+			min: ts1,
+			max: ts3,
+		},
+		{
+			name: "intent followed by versions",
+			kvs: []MVCCKeyValue{
+				{Key: MVCCKey{Key: roachpb.Key("a")}, Value: intent(ts3)},
+				{Key: MVCCKey{Key: roachpb.Key("a"), Timestamp: ts2}},
+			},
+			min: ts2,
+			max: ts2,
+		},
+		{
+			name: "trailing intent",
+			kvs: []MVCCKeyValue{
+				{Key: MVCCKey{Key: roachpb.Key("a"), Timestamp: ts1}},
+				{Key: MVCCKey{Key: roachpb.Key("b")}, Value: intent(ts3)},
+			},
+			min: ts1,
+			max: ts3,
+		},
+		{
+			name: "trailing inline value",
+			kvs: []MVCCKeyValue{
+				{Key: MVCCKey{Key: roachpb.Key("a"), Timestamp: ts1}},
+				{Key: MVCCKey{Key: roachpb.Key("b")}, Value: mustMarshal(&enginepb.MVCCMetadata{
+					RawBytes: []byte("b"),
+				})},
+			},
+			min: ts1,
+			max: ts1,
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			collector := &pebbleTimeBoundPropCollector{}
+			for _, kv := range c.kvs {
+				ikey := pebble.InternalKey{UserKey: EncodeKey(kv.Key)}
+				if err := collector.Add(ikey, kv.Value); err != nil {
+					t.Fatal(err)
+				}
+			}
+			props := make(map[string]string)
+			if err := collector.Finish(props); err != nil {
+				t.Fatal(err)
+			}
+			if e, a := string(encodeTimestamp(c.min)), props["crdb.ts.min"]; e != a {
+				t.Errorf("expected min %x, but got %x", e, a)
+			}
+			if e, a := string(encodeTimestamp(c.max)), props["crdb.ts.max"]; e != a {
+				t.Errorf("expected max %x, but got %x", e, a)
+			}
+		})
+	}
+}
+
+func TestPebbleCompactRangeForceBottommost(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	p := newPebbleInMem(roachpb.Attributes{}, 1<<20)
+	defer p.Close()
+
+	if err := p.Put(MakeMVCCMetadataKey(roachpb.Key("a")), []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.CompactRange(roachpb.KeyMin, roachpb.KeyMax, false /* forceBottommost */); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.CompactRange(roachpb.KeyMin, roachpb.KeyMax, true /* forceBottommost */); !testutils.IsError(
+		err, "does not support compacting the bottommost level",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -873,16 +873,22 @@ func (r *RocksDB) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)
 
 // Capacity queries the underlying file system for disk capacity information.
 func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
+	return computeCapacity(r.cfg.Dir, r.cfg.MaxSizeBytes)
+}
+
+// computeCapacity returns the capacity details of a store in the specified
+// directory, or of an in-memory store if the directory is empty, whose size
+// is limited to maxSizeBytes unless it's zero.
+func computeCapacity(dir string, maxSizeBytes int64) (roachpb.StoreCapacity, error) {
 	fileSystemUsage := gosigar.FileSystemUsage{}
-	dir := r.cfg.Dir
 	if dir == "" {
 		// This is an in-memory instance. Pretend we're empty since we
 		// don't know better and only use this for testing. Using any
 		// part of the actual file system here can throw off allocator
 		// rebalancing in a hard-to-trace manner. See #7050.
 		return roachpb.StoreCapacity{
-			Capacity:  r.cfg.MaxSizeBytes,
-			Available: r.cfg.MaxSizeBytes,
+			Capacity:  maxSizeBytes,
+			Available: maxSizeBytes,
 		}, nil
 	}
 	if err := fileSystemUsage.Get(dir); err != nil {
@@ -903,7 +909,7 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 	// Find the total size of all the files in the r.dir and all its
 	// subdirectories.
 	var totalUsedBytes int64
-	if errOuter := filepath.Walk(r.cfg.Dir, func(path string, info os.FileInfo, err error) error {
+	if errOuter := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			// This can happen if rocksdb removes files out from under us - just keep
 			// going to get the best estimate we can.
@@ -929,7 +935,7 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 	// If no size limitation have been placed on the store size or if the
 	// limitation is greater than what's available, just return the actual
 	// totals.
-	if r.cfg.MaxSizeBytes == 0 || r.cfg.MaxSizeBytes >= fsuTotal || r.cfg.Dir == "" {
+	if maxSizeBytes == 0 || maxSizeBytes >= fsuTotal || dir == "" {
 		return roachpb.StoreCapacity{
 			Capacity:  fsuTotal,
 			Available: fsuAvail,
@@ -937,7 +943,7 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 		}, nil
 	}
 
-	available := r.cfg.MaxSizeBytes - totalUsedBytes
+	available := maxSizeBytes - totalUsedBytes
 	if available > fsuAvail {
 		available = fsuAvail
 	}
@@ -946,7 +952,7 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 	}
 
 	return roachpb.StoreCapacity{
-		Capacity:  r.cfg.MaxSizeBytes,
+		Capacity:  maxSizeBytes,
 		Available: available,
 		Used:      totalUsedBytes,
 	}, nil
@@ -3130,7 +3136,7 @@ func (r *RocksDB) PreIngestDelay(ctx context.Context) {
 		log.Warningf(ctx, "failed to read stats: %+v", err)
 		return
 	}
-	targetDelay := calculatePreIngestDelay(r.cfg, stats)
+	targetDelay := calculatePreIngestDelay(r.cfg.Settings, stats)
 
 	if targetDelay == 0 {
 		return
@@ -3143,10 +3149,10 @@ func (r *RocksDB) PreIngestDelay(ctx context.Context) {
 	}
 }
 
-func calculatePreIngestDelay(cfg RocksDBConfig, stats *Stats) time.Duration {
-	maxDelay := ingestDelayTime.Get(&cfg.Settings.SV)
-	l0Filelimit := ingestDelayL0Threshold.Get(&cfg.Settings.SV)
-	compactionLimit := ingestDelayPendingLimit.Get(&cfg.Settings.SV)
+func calculatePreIngestDelay(settings *cluster.Settings, stats *Stats) time.Duration {
+	maxDelay := ingestDelayTime.Get(&settings.SV)
+	l0Filelimit := ingestDelayL0Threshold.Get(&settings.SV)
+	compactionLimit := ingestDelayPendingLimit.Get(&settings.SV)
 
 	if stats.PendingCompactionBytesEstimate >= compactionLimit {
 		return maxDelay
@@ -3296,6 +3302,8 @@ func ExportToSst(
 		cdbEngine = v.rdb
 	case *rocksDBReadOnly:
 		cdbEngine = v.parent.rdb
+	case *Pebble, *pebbleReadOnly:
+		return nil, roachpb.BulkOpSummary{}, errors.New("export is not supported on pebble stores")
 	default:
 		panic(errors.Errorf("Not a rocksdb or rocksdbReadOnly engine but a %T", e))
 	}
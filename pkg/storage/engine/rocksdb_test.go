@@ -336,7 +336,7 @@ func makeKey(i int) MVCCKey {
 }
 
 func benchmarkIterOnBatch(ctx context.Context, b *testing.B, writes int) {
-	engine := createTestEngine()
+	engine := createTestRocksDBEngine()
 	defer engine.Close()
 
 	for i := 0; i < writes; i++ {
@@ -368,7 +368,7 @@ func benchmarkIterOnBatch(ctx context.Context, b *testing.B, writes int) {
 func benchmarkIterOnReadWriter(
 	ctx context.Context, b *testing.B, writes int, f func(Engine) ReadWriter, closeReadWriter bool,
 ) {
-	engine := createTestEngine()
+	engine := createTestRocksDBEngine()
 	defer engine.Close()
 
 	for i := 0; i < writes; i++ {
@@ -1812,7 +1812,7 @@ func TestRocksDBWALFileEmptyBatch(t *testing.T) {
 
 func TestIngestDelayLimit(t *testing.T) {
 	defer leaktest.AfterTest(t)()
-	cfg := RocksDBConfig{Settings: cluster.MakeTestingClusterSettings()}
+	s := cluster.MakeTestingClusterSettings()
 
 	max, ramp := time.Second*5, time.Second*5/10
 
@@ -1830,6 +1830,6 @@ func TestIngestDelayLimit(t *testing.T) {
 		{max, Stats{L0FileCount: 25, PendingCompactionBytesEstimate: 80 << 30}},
 		{max, Stats{L0FileCount: 35, PendingCompactionBytesEstimate: 20 << 30}},
 	} {
-		require.Equal(t, tc.exp, calculatePreIngestDelay(cfg, &tc.stats))
+		require.Equal(t, tc.exp, calculatePreIngestDelay(s, &tc.stats))
 	}
 }
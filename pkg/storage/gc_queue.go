@@ -148,7 +148,12 @@ func (r gcQueueScore) String() string {
 func (gcq *gcQueue) shouldQueue(
 	ctx context.Context, now hlc.Timestamp, repl *Replica, sysCfg *config.SystemConfig,
 ) (bool, float64) {
-	r := makeGCQueueScore(ctx, repl, now, sysCfg)
+	_, zone := repl.DescAndZone()
+	canGC, gcTimestamp := repl.checkProtectedTimestampsForGC(ctx, now, *zone.GC)
+	if !canGC {
+		return false, 0
+	}
+	r := makeGCQueueScore(ctx, repl, gcTimestamp, sysCfg)
 	return r.ShouldQueue, r.FinalScore
 }
 
@@ -548,17 +553,24 @@ func (r *replicaGCer) GC(ctx context.Context, keys []roachpb.GCRequest_GCKey) er
 // 7) push these transactions (again, recreating txn entries).
 // 8) send a GCRequest.
 func (gcq *gcQueue) process(ctx context.Context, repl *Replica, sysCfg *config.SystemConfig) error {
+	// Lookup the descriptor and GC policy for the zone containing this key range.
+	desc, zone := repl.DescAndZone()
+
+	// Consult the protected timestamp records to determine the time against
+	// which the GC TTL is measured.
 	now := repl.store.Clock().Now()
-	r := makeGCQueueScore(ctx, repl, now, sysCfg)
+	canGC, gcTimestamp := repl.checkProtectedTimestampsForGC(ctx, now, *zone.GC)
+	if !canGC {
+		log.VEventf(ctx, 2, "not processing replica %s: protected timestamps unknown", repl)
+		return nil
+	}
+	r := makeGCQueueScore(ctx, repl, gcTimestamp, sysCfg)
 	log.VEventf(ctx, 2, "processing replica %s with score %s", repl.String(), r)
 
 	snap := repl.store.Engine().NewSnapshot()
 	defer snap.Close()
 
-	// Lookup the descriptor and GC policy for the zone containing this key range.
-	desc, zone := repl.DescAndZone()
-
-	info, err := RunGC(ctx, desc, snap, now, *zone.GC, &replicaGCer{repl: repl},
+	info, err := RunGC(ctx, desc, snap, now, gcTimestamp, *zone.GC, &replicaGCer{repl: repl},
 		func(ctx context.Context, intents []roachpb.Intent) error {
 			intentCount, err := repl.store.intentResolver.CleanupIntents(ctx, intents, now, roachpb.PUSH_ABORT)
 			if err == nil {
@@ -623,7 +635,11 @@ type GCInfo struct {
 	// ResolveTotal is the total number of attempted intent resolutions in
 	// this cycle.
 	ResolveTotal int
-	// Threshold is the computed expiration timestamp. Equal to `Now - Policy`.
+	// GCTimestamp is the timestamp against which the GC TTL is measured. It
+	// trails Now if history is protected (see package protectedts).
+	GCTimestamp hlc.Timestamp
+	// Threshold is the computed expiration timestamp. Equal to
+	// `GCTimestamp - Policy`.
 	Threshold hlc.Timestamp
 	// AffectedVersionsKeyBytes is the number of (fully encoded) bytes deleted from keys in the storage engine.
 	// Note that this does not account for compression that the storage engine uses to store data on disk. Real
@@ -673,12 +689,14 @@ type GCer interface {
 // to run garbage collection once on all implicated spans,
 // cleanupIntentsFn to resolve intents synchronously, and
 // cleanupTxnIntentsAsyncFn to asynchronously cleanup intents and
-// associated transaction record on success.
+// associated transaction record on success. The GC TTL of the policy is
+// measured against gcTimestamp, which may not exceed now.
 func RunGC(
 	ctx context.Context,
 	desc *roachpb.RangeDescriptor,
 	snap engine.Reader,
 	now hlc.Timestamp,
+	gcTimestamp hlc.Timestamp,
 	policy config.GCPolicy,
 	gcer GCer,
 	cleanupIntentsFn cleanupIntentsFunc,
@@ -691,12 +709,13 @@ func RunGC(
 	var infoMu = lockableGCInfo{}
 	infoMu.Policy = policy
 	infoMu.Now = now
+	infoMu.GCTimestamp = gcTimestamp
 
 	// Compute intent expiration (intent age at which we attempt to resolve).
 	intentExp := now.Add(-intentAgeThreshold.Nanoseconds(), 0)
 	txnExp := now.Add(-storagebase.TxnCleanupThreshold.Nanoseconds(), 0)
 
-	gc := engine.MakeGarbageCollector(now, policy)
+	gc := engine.MakeGarbageCollector(gcTimestamp, policy)
 	infoMu.Threshold = gc.Threshold
 
 	if err := gcer.SetGCThreshold(ctx, GCThreshold{
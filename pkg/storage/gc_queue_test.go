@@ -502,7 +502,7 @@ func TestGCQueueProcess(t *testing.T) {
 		}
 
 		now := tc.Clock().Now()
-		return RunGC(ctx, desc, snap, now, *zone.GC,
+		return RunGC(ctx, desc, snap, now, now, *zone.GC,
 			NoopGCer{},
 			func(ctx context.Context, intents []roachpb.Intent) error {
 				return nil
@@ -0,0 +1,694 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/google/btree"
+)
+
+// The degree of the lockTable btree.
+const lockTableBtreeDegree = 16
+
+// lockTableDeadlockDetectionPushDelay is how long a request waits in the lock
+// table for a conflicting lock before pushing its holder. Pushes are what
+// detects deadlocks between lock holders (see txnwait.Queue), so this also
+// bounds how long a deadlock goes unnoticed.
+var lockTableDeadlockDetectionPushDelay = settings.RegisterNonNegativeDurationSetting(
+	"kv.lock_table.deadlock_detection_push_delay",
+	"the delay before a request waiting for a conflicting lock pushes the lock holder, "+
+		"which detects deadlocks between lock holders",
+	100*time.Millisecond,
+)
+
+// lockDurability is the durability of a lock held in the lockTable. Nothing
+// in the lockTable is persisted; the durability only says whether the lock is
+// backed by a write intent.
+type lockDurability int8
+
+const (
+	// lockUnreplicated locks are acquired by locking reads and are only held
+	// in the lockTable of the leaseholder.
+	lockUnreplicated lockDurability = iota
+	// lockIntent locks mirror write intents, which are what makes them
+	// durable. The lockTable learns about them from the writes applied by the
+	// leaseholder and from the intents which locking requests discover during
+	// evaluation, and forgets about them when they are resolved.
+	lockIntent
+)
+
+// lockHolder is the holder of an exclusive lock.
+type lockHolder struct {
+	txn        enginepb.TxnMeta
+	durability lockDurability
+}
+
+// lockWaiter is a request queued on a lockedKey.
+type lockWaiter struct {
+	g   *lockGuard
+	str roachpb.KeyLockingStrength
+	// The index of the request in its batch.
+	index int32
+}
+
+// lockedKey is a single key in the lockTable, along with the transactions
+// that hold locks on it and the requests waiting for them. A key is either
+// locked exclusively by a single transaction or in shared mode by one or more
+// transactions. Shared locks are always unreplicated.
+type lockedKey struct {
+	key       roachpb.Key
+	exclusive *lockHolder
+	shared    map[uuid.UUID]enginepb.TxnMeta
+	// The requests queued on the key, in the order in which they arrived.
+	queue []*lockWaiter
+}
+
+// Less implements the btree.Item interface.
+func (k *lockedKey) Less(b btree.Item) bool {
+	return k.key.Compare(b.(*lockedKey).key) < 0
+}
+
+// empty returns whether no transaction holds a lock on the key.
+func (k *lockedKey) empty() bool {
+	return k.exclusive == nil && len(k.shared) == 0
+}
+
+// unused returns whether the key can be removed from the lockTable.
+func (k *lockedKey) unused() bool {
+	return k.empty() && len(k.queue) == 0
+}
+
+// conflictingHolder returns a transaction other than txn that holds a lock
+// on the key which conflicts with a lock of the provided strength, or nil
+// if there is no such transaction. Exclusive locks conflict with all other
+// locks and shared locks only conflict with exclusive locks.
+func (k *lockedKey) conflictingHolder(
+	txn *enginepb.TxnMeta, str roachpb.KeyLockingStrength,
+) *enginepb.TxnMeta {
+	if k.exclusive != nil && (txn == nil || k.exclusive.txn.ID != txn.ID) {
+		return &k.exclusive.txn
+	}
+	if str != roachpb.KEY_LOCKING_EXCLUSIVE {
+		return nil
+	}
+	for id, holder := range k.shared {
+		if txn == nil || id != txn.ID {
+			holder := holder
+			return &holder
+		}
+	}
+	return nil
+}
+
+// signalWaiters notifies the requests queued on the key that its state
+// changed.
+func (k *lockedKey) signalWaiters() {
+	for _, w := range k.queue {
+		w.g.notify()
+	}
+}
+
+// waitersConflict returns whether two requests of the provided transactions
+// and locking strengths conflict with each other.
+func waitersConflict(
+	txnA *enginepb.TxnMeta,
+	strA roachpb.KeyLockingStrength,
+	txnB *enginepb.TxnMeta,
+	strB roachpb.KeyLockingStrength,
+) bool {
+	if txnA != nil && txnB != nil && txnA.ID == txnB.ID {
+		return false
+	}
+	return strA == roachpb.KEY_LOCKING_EXCLUSIVE || strB == roachpb.KEY_LOCKING_EXCLUSIVE
+}
+
+// lockRequest describes the locks needed by a request in a batch.
+type lockRequest struct {
+	span roachpb.Span
+	str  roachpb.KeyLockingStrength
+}
+
+// queuedLockWaiter is an entry of a lockGuard in the queue of a lockedKey.
+type queuedLockWaiter struct {
+	k *lockedKey
+	w *lockWaiter
+}
+
+// lockGuard is the position of a batch in the wait queues of the lockTable.
+// It's held from the time the batch starts waiting until it is done
+// evaluating, which makes requests that arrive later wait behind it.
+type lockGuard struct {
+	txn    *enginepb.TxnMeta
+	signal chan struct{}
+	queued []queuedLockWaiter
+}
+
+// notify wakes up the goroutine waiting on the guard, if any.
+func (g *lockGuard) notify() {
+	select {
+	case g.signal <- struct{}{}:
+	default:
+	}
+}
+
+// lockTable tracks the locks held on a replica's keys, and sequences the
+// writes and locking reads (SELECT FOR UPDATE/SHARE) which conflict with
+// them. The table is only maintained on the leaseholder and its contents are
+// lost when the lease changes hands or the range splits or merges. This is
+// safe because the locks are purely a mechanism to avoid contention-induced
+// transaction retries; transactional isolation continues to be provided by
+// the timestamp cache and write intents.
+//
+// Two kinds of locks are tracked: the unreplicated locks acquired by locking
+// reads, which only exist in the table, and the write intents that the table
+// learns about from writes and from intent discovery, which it drops once they
+// are resolved. The table doesn't persist any lock of its own: durable locks
+// acquired by locking reads aren't supported, and a lost write intent is
+// rediscovered by the next request that runs into it. Non-locking reads don't
+// consult the table; they continue to discover intents during evaluation.
+//
+// The table sits in front of the spanlatch manager. Before acquiring latches,
+// writes and locking reads wait in a queue on each of the keys they lock for
+// the conflicting locks to be released and for the conflicting requests which
+// arrived before them to finish. If a conflicting lock isn't released within
+// kv.lock_table.deadlock_detection_push_delay, the request pushes its holder,
+// which waits in the txnWaitQueue where deadlocks between lock holders are
+// detected. Conflicts with locks held in the table are reported in the form
+// of a WriteIntentError, so that the conflicting request is sequenced behind
+// the lock holder using the same push and wait machinery as for write
+// intents. Once it holds its latches, a request checks for conflicts again,
+// since the keys of ranged requests can't be queued on before they're known
+// to be locked.
+type lockTable struct {
+	mu syncutil.Mutex
+	t  *btree.BTree
+	// Avoids allocs.
+	tmp1, tmp2 lockedKey
+}
+
+// getOrCreateLocked returns the lockedKey for key, creating it if necessary.
+// lt.mu must be held.
+func (lt *lockTable) getOrCreateLocked(key roachpb.Key) *lockedKey {
+	if lt.t == nil {
+		// Lazily initialize btree.
+		lt.t = btree.New(lockTableBtreeDegree)
+	}
+	lt.tmp1.key = key
+	defer func() { lt.tmp1 = lockedKey{} }()
+	if i := lt.t.Get(&lt.tmp1); i != nil {
+		return i.(*lockedKey)
+	}
+	k := &lockedKey{key: append(roachpb.Key(nil), key...)}
+	lt.t.ReplaceOrInsert(k)
+	return k
+}
+
+// removeIfUnusedLocked removes k from the table if it's unused. k may have been
+// dropped from the table already if the table was cleared. lt.mu must be held.
+func (lt *lockTable) removeIfUnusedLocked(k *lockedKey) {
+	if !k.unused() || lt.t == nil {
+		return
+	}
+	if i := lt.t.Get(k); i == k {
+		lt.t.Delete(k)
+	}
+}
+
+// acquire records that txn holds an unreplicated lock of the provided
+// strength on each of the provided keys. Acquiring a lock on a key that the
+// transaction already holds a lock on upgrades the lock if necessary.
+func (lt *lockTable) acquire(
+	txn *enginepb.TxnMeta, str roachpb.KeyLockingStrength, keys []roachpb.Key,
+) {
+	if str == roachpb.KEY_LOCKING_NONE || len(keys) == 0 {
+		return
+	}
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	for _, key := range keys {
+		k := lt.getOrCreateLocked(key)
+		switch str {
+		case roachpb.KEY_LOCKING_EXCLUSIVE:
+			delete(k.shared, txn.ID)
+			if k.exclusive != nil && k.exclusive.txn.ID == txn.ID {
+				// Don't downgrade an intent.
+				continue
+			}
+			k.exclusive = &lockHolder{txn: *txn, durability: lockUnreplicated}
+		case roachpb.KEY_LOCKING_SHARED:
+			if k.exclusive != nil && k.exclusive.txn.ID == txn.ID {
+				// Already holds a stronger lock.
+				continue
+			}
+			if k.shared == nil {
+				k.shared = make(map[uuid.UUID]enginepb.TxnMeta)
+			}
+			k.shared[txn.ID] = *txn
+		}
+	}
+}
+
+// acquireIntents records that txn holds a write intent on each of the
+// provided keys.
+func (lt *lockTable) acquireIntents(txn *enginepb.TxnMeta, keys []roachpb.Key) {
+	if len(keys) == 0 {
+		return
+	}
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	for _, key := range keys {
+		k := lt.getOrCreateLocked(key)
+		delete(k.shared, txn.ID)
+		k.exclusive = &lockHolder{txn: *txn, durability: lockIntent}
+	}
+}
+
+// findConflict returns an intent describing a lock held by a transaction
+// other than txn in the provided span that conflicts with an access of the
+// provided strength. Writes should pass KEY_LOCKING_EXCLUSIVE. If no such
+// lock is held, ok is false.
+func (lt *lockTable) findConflict(
+	span roachpb.Span, txn *enginepb.TxnMeta, str roachpb.KeyLockingStrength,
+) (intent roachpb.Intent, ok bool) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	lt.forEachInSpanLocked(span, func(k *lockedKey) bool {
+		if holder := k.conflictingHolder(txn, str); holder != nil {
+			intent = roachpb.Intent{Span: roachpb.Span{Key: k.key}, Txn: *holder}
+			ok = true
+			return false
+		}
+		return true
+	})
+	return intent, ok
+}
+
+// conflictingKeys returns the keys in the provided span that are locked by
+// transactions other than txn with locks that conflict with an access of the
+// provided strength.
+func (lt *lockTable) conflictingKeys(
+	span roachpb.Span, txn *enginepb.TxnMeta, str roachpb.KeyLockingStrength,
+) []roachpb.Key {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	var res []roachpb.Key
+	lt.forEachInSpanLocked(span, func(k *lockedKey) bool {
+		if k.conflictingHolder(txn, str) != nil {
+			res = append(res, k.key)
+		}
+		return true
+	})
+	return res
+}
+
+// release releases all locks held by the transaction in the provided span.
+func (lt *lockTable) release(span roachpb.Span, txnID uuid.UUID) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	var toRemove []*lockedKey
+	lt.forEachInSpanLocked(span, func(k *lockedKey) bool {
+		released := false
+		if k.exclusive != nil && k.exclusive.txn.ID == txnID {
+			k.exclusive = nil
+			released = true
+		}
+		if _, ok := k.shared[txnID]; ok {
+			delete(k.shared, txnID)
+			released = true
+		}
+		if released {
+			k.signalWaiters()
+		}
+		if k.unused() {
+			toRemove = append(toRemove, k)
+		}
+		return true
+	})
+	for _, k := range toRemove {
+		lt.t.Delete(k)
+	}
+}
+
+// releaseStaleIntents releases the write intents of txn in the provided span
+// which were written at an epoch older than txn's. Resolving an intent of a
+// transaction that's still pending removes the intent if it's from an earlier
+// epoch.
+func (lt *lockTable) releaseStaleIntents(span roachpb.Span, txn *enginepb.TxnMeta) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	var toRemove []*lockedKey
+	lt.forEachInSpanLocked(span, func(k *lockedKey) bool {
+		if h := k.exclusive; h != nil && h.durability == lockIntent &&
+			h.txn.ID == txn.ID && h.txn.Epoch < txn.Epoch {
+			k.exclusive = nil
+			k.signalWaiters()
+		}
+		if k.unused() {
+			toRemove = append(toRemove, k)
+		}
+		return true
+	})
+	for _, k := range toRemove {
+		lt.t.Delete(k)
+	}
+}
+
+// clear removes all locks from the table. The requests waiting in the table
+// stop waiting.
+func (lt *lockTable) clear() {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	if lt.t == nil {
+		return
+	}
+	lt.t.Ascend(func(i btree.Item) bool {
+		k := i.(*lockedKey)
+		k.exclusive, k.shared = nil, nil
+		k.signalWaiters()
+		return true
+	})
+	lt.t = nil
+}
+
+// enqueue queues a batch of the provided transaction, which needs the
+// provided locks, in the table. Requests for a single key are queued on
+// that key; ranged requests are only queued on the keys in their span which
+// are already in the table. It returns nil if the batch needs no locks. The
+// returned guard must be passed to wait and then to dequeue once the batch
+// is done.
+func (lt *lockTable) enqueue(txn *enginepb.TxnMeta, reqs []lockRequest) *lockGuard {
+	var g *lockGuard
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	for i, req := range reqs {
+		if req.str == roachpb.KEY_LOCKING_NONE {
+			continue
+		}
+		if g == nil {
+			g = &lockGuard{txn: txn, signal: make(chan struct{}, 1)}
+		}
+		w := &lockWaiter{g: g, str: req.str, index: int32(i)}
+		queue := func(k *lockedKey) bool {
+			k.queue = append(k.queue, w)
+			g.queued = append(g.queued, queuedLockWaiter{k: k, w: w})
+			return true
+		}
+		if len(req.span.EndKey) == 0 {
+			queue(lt.getOrCreateLocked(req.span.Key))
+		} else {
+			lt.forEachInSpanLocked(req.span, queue)
+		}
+	}
+	return g
+}
+
+// findBlockerLocked returns the index of a request in g's batch which is
+// blocked, either by a conflicting lock or, unless ignoreWaiters is set, by a
+// conflicting request queued ahead of it. If it is blocked by a lock, the
+// lock is returned as an intent. lt.mu must be held.
+func (lt *lockTable) findBlockerLocked(
+	g *lockGuard, ignoreWaiters bool,
+) (index int32, intent *roachpb.Intent) {
+	for _, q := range g.queued {
+		if holder := q.k.conflictingHolder(g.txn, q.w.str); holder != nil {
+			return q.w.index, &roachpb.Intent{Span: roachpb.Span{Key: q.k.key}, Txn: *holder}
+		}
+	}
+	if ignoreWaiters {
+		return -1, nil
+	}
+	for _, q := range g.queued {
+		for _, w := range q.k.queue {
+			if w == q.w {
+				break
+			}
+			if waitersConflict(g.txn, q.w.str, w.g.txn, w.str) {
+				return q.w.index, nil
+			}
+		}
+	}
+	return -1, nil
+}
+
+// wait waits until none of the requests in g's batch are blocked. If a
+// request is still blocked by a conflicting lock once pushDelay has elapsed,
+// the index of the request and the lock, as an intent, are returned so that
+// the caller can push its holder. Requests queued ahead of the batch are only
+// waited for until pushDelay has elapsed: they may themselves be waiting for
+// a lock held by the batch's transaction, which would be a deadlock that
+// isn't detected by pushes.
+func (lt *lockTable) wait(
+	ctx context.Context, g *lockGuard, pushDelay time.Duration,
+) (index int32, intent *roachpb.Intent, _ error) {
+	var timer timeutil.Timer
+	defer timer.Stop()
+	timer.Reset(pushDelay)
+	pushDelayElapsed := false
+	for {
+		lt.mu.Lock()
+		index, intent := lt.findBlockerLocked(g, pushDelayElapsed /* ignoreWaiters */)
+		lt.mu.Unlock()
+		if index < 0 {
+			return -1, nil, nil
+		}
+		if intent != nil && pushDelayElapsed {
+			return index, intent, nil
+		}
+		select {
+		case <-g.signal:
+		case <-timer.C:
+			timer.Read = true
+			pushDelayElapsed = true
+		case <-ctx.Done():
+			return -1, nil, ctx.Err()
+		}
+	}
+}
+
+// dequeue removes the batch of g from the wait queues of the table, letting
+// the requests queued behind it proceed. g may be nil.
+func (lt *lockTable) dequeue(g *lockGuard) {
+	if g == nil {
+		return
+	}
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	for _, q := range g.queued {
+		k := q.k
+		for i, w := range k.queue {
+			if w == q.w {
+				k.queue = append(k.queue[:i], k.queue[i+1:]...)
+				break
+			}
+		}
+		k.signalWaiters()
+		lt.removeIfUnusedLocked(k)
+	}
+	g.queued = nil
+}
+
+// forEachInSpanLocked calls fn on each locked key in the provided span, until
+// fn returns false. lt.mu must be held.
+func (lt *lockTable) forEachInSpanLocked(span roachpb.Span, fn func(*lockedKey) bool) {
+	if lt.t == nil {
+		return
+	}
+	if len(span.EndKey) == 0 {
+		lt.tmp1.key = span.Key
+		if i := lt.t.Get(&lt.tmp1); i != nil {
+			fn(i.(*lockedKey))
+		}
+		lt.tmp1 = lockedKey{}
+		return
+	}
+	lt.tmp1.key, lt.tmp2.key = span.Key, span.EndKey
+	lt.t.AscendRange(&lt.tmp1, &lt.tmp2, func(i btree.Item) bool {
+		return fn(i.(*lockedKey))
+	})
+	lt.tmp1, lt.tmp2 = lockedKey{}, lockedKey{}
+}
+
+// GetConflictingLockedKeys implements the batcheval.EvalContext interface.
+func (r *Replica) GetConflictingLockedKeys(
+	span roachpb.Span, txn *enginepb.TxnMeta, str roachpb.KeyLockingStrength,
+) []roachpb.Key {
+	return r.locks.conflictingKeys(span, txn, str)
+}
+
+// lockingOpts returns the locking strength and wait policy of a request. Writes
+// are treated as exclusive locking requests that block on conflicting locks.
+func lockingOpts(req roachpb.Request) (roachpb.KeyLockingStrength, roachpb.LockWaitPolicy) {
+	switch t := req.(type) {
+	case *roachpb.ScanRequest:
+		return t.KeyLocking, t.LockWaitPolicy
+	case *roachpb.ReverseScanRequest:
+		return t.KeyLocking, t.LockWaitPolicy
+	}
+	if roachpb.IsTransactionWrite(req) {
+		return roachpb.KEY_LOCKING_EXCLUSIVE, roachpb.LOCK_WAIT_BLOCK
+	}
+	return roachpb.KEY_LOCKING_NONE, roachpb.LOCK_WAIT_BLOCK
+}
+
+// waitForLocks sequences the writes and locking reads in the batch behind the
+// conflicting locks and the conflicting requests which arrived before them.
+// It must be called before acquiring latches. Requests that don't block on
+// conflicting locks (SKIP LOCKED and NOWAIT) aren't sequenced; their
+// conflicts are handled once the batch holds its latches. The returned guard
+// must be released with lockTable.dequeue once the batch is done.
+//
+// If a request is blocked by a lock for longer than the deadlock detection
+// push delay, a WriteIntentError for the lock is returned so that the holder
+// is pushed.
+func (r *Replica) waitForLocks(
+	ctx context.Context, ba *roachpb.BatchRequest,
+) (*lockGuard, *roachpb.Error) {
+	if !ba.IsWrite() && !ba.IsLocking() {
+		return nil, nil
+	}
+	var txn *enginepb.TxnMeta
+	if ba.Txn != nil {
+		txn = &ba.Txn.TxnMeta
+	}
+	reqs := make([]lockRequest, len(ba.Requests))
+	for i, union := range ba.Requests {
+		req := union.GetInner()
+		str, waitPolicy := lockingOpts(req)
+		if waitPolicy != roachpb.LOCK_WAIT_BLOCK {
+			str = roachpb.KEY_LOCKING_NONE
+		}
+		reqs[i] = lockRequest{span: req.Header().Span(), str: str}
+	}
+	g := r.locks.enqueue(txn, reqs)
+	if g == nil {
+		return nil, nil
+	}
+	pushDelay := lockTableDeadlockDetectionPushDelay.Get(&r.store.cfg.Settings.SV)
+	index, intent, err := r.locks.wait(ctx, g, pushDelay)
+	if err != nil {
+		r.locks.dequeue(g)
+		return nil, roachpb.NewError(err)
+	}
+	if intent != nil {
+		r.locks.dequeue(g)
+		log.VEventf(ctx, 2, "pushing holder of lock on %s after waiting for %s", intent.Key, pushDelay)
+		pErr := roachpb.NewError(&roachpb.WriteIntentError{Intents: []roachpb.Intent{*intent}})
+		pErr.SetErrorIndex(index)
+		return nil, pErr
+	}
+	return g, nil
+}
+
+// checkLockConflicts returns a WriteIntentError if any request in the batch
+// conflicts with a lock held by another transaction. Locking reads with a
+// SKIP LOCKED wait policy never conflict, as they skip over locked keys
+// during evaluation instead. Must be called with the batch's latches held.
+func (r *Replica) checkLockConflicts(ba *roachpb.BatchRequest) *roachpb.Error {
+	var txn *enginepb.TxnMeta
+	if ba.Txn != nil {
+		txn = &ba.Txn.TxnMeta
+	}
+	for i, union := range ba.Requests {
+		req := union.GetInner()
+		str, waitPolicy := lockingOpts(req)
+		if str == roachpb.KEY_LOCKING_NONE || waitPolicy == roachpb.LOCK_WAIT_SKIP {
+			continue
+		}
+		if intent, ok := r.locks.findConflict(req.Header().Span(), txn, str); ok {
+			pErr := roachpb.NewError(&roachpb.WriteIntentError{Intents: []roachpb.Intent{intent}})
+			pErr.SetErrorIndex(int32(i))
+			return pErr
+		}
+	}
+	return nil
+}
+
+// handleAcquiredLocks adds the locks acquired by the locking reads in an
+// evaluated batch to the lock table.
+func (r *Replica) handleAcquiredLocks(acquired []result.LockAcquisition) {
+	for i := range acquired {
+		r.locks.acquire(&acquired[i].Txn, acquired[i].Strength, acquired[i].Keys)
+	}
+}
+
+// handleDiscoveredIntents adds the intents that a write or locking read
+// discovered during evaluation to the lock table, so that the requests which
+// arrive while the intents are being pushed and resolved wait for them in the
+// lock table instead of discovering them again.
+func (r *Replica) handleDiscoveredIntents(ba *roachpb.BatchRequest, pErr *roachpb.Error) {
+	wiErr, ok := pErr.GetDetail().(*roachpb.WriteIntentError)
+	if !ok || (!ba.IsWrite() && !ba.IsLocking()) {
+		return
+	}
+	for i := range wiErr.Intents {
+		intent := &wiErr.Intents[i]
+		if len(intent.EndKey) == 0 {
+			r.locks.acquireIntents(&intent.Txn, []roachpb.Key{intent.Key})
+		}
+	}
+}
+
+// releaseLocksForBatch releases the locks of any transaction that the
+// successfully executed batch finalized or resolved intents for, and adds the
+// intents that it wrote to the lock table. Resolving the intents of a pending
+// transaction only releases those that the resolution removed.
+func (r *Replica) releaseLocksForBatch(ba *roachpb.BatchRequest) {
+	for _, union := range ba.Requests {
+		switch t := union.GetInner().(type) {
+		case *roachpb.ResolveIntentRequest:
+			if t.Status != roachpb.PENDING {
+				r.locks.release(t.Span(), t.IntentTxn.ID)
+			} else {
+				r.locks.releaseStaleIntents(t.Span(), &t.IntentTxn)
+			}
+		case *roachpb.ResolveIntentRangeRequest:
+			if t.Status != roachpb.PENDING {
+				r.locks.release(t.Span(), t.IntentTxn.ID)
+			} else {
+				r.locks.releaseStaleIntents(t.Span(), &t.IntentTxn)
+			}
+		case *roachpb.EndTransactionRequest:
+			if ba.Txn == nil {
+				continue
+			}
+			for _, sp := range t.IntentSpans {
+				r.locks.release(sp, ba.Txn.ID)
+			}
+		}
+	}
+
+	// Batches which end their transaction either didn't leave intents behind
+	// (1PC) or are about to resolve them.
+	if ba.Txn == nil {
+		return
+	}
+	if _, ok := ba.GetArg(roachpb.EndTransaction); ok {
+		return
+	}
+	var written []roachpb.Key
+	for _, union := range ba.Requests {
+		req := union.GetInner()
+		if h := req.Header(); roachpb.IsTransactionWrite(req) && len(h.EndKey) == 0 {
+			written = append(written, h.Key)
+		}
+	}
+	r.locks.acquireIntents(&ba.Txn.TxnMeta, written)
+}
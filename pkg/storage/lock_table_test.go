@@ -0,0 +1,230 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+func TestLockTable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	txn1 := &enginepb.TxnMeta{ID: uuid.MakeV4()}
+	txn2 := &enginepb.TxnMeta{ID: uuid.MakeV4()}
+	keyA, keyB, keyC := roachpb.Key("a"), roachpb.Key("b"), roachpb.Key("c")
+	spanAll := roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")}
+
+	var lt lockTable
+
+	// An empty table has no conflicts.
+	if _, ok := lt.findConflict(spanAll, txn1, roachpb.KEY_LOCKING_EXCLUSIVE); ok {
+		t.Fatal("unexpected conflict in empty lock table")
+	}
+
+	// Shared locks don't conflict with each other.
+	lt.acquire(txn1, roachpb.KEY_LOCKING_SHARED, []roachpb.Key{keyA})
+	if _, ok := lt.findConflict(spanAll, txn2, roachpb.KEY_LOCKING_SHARED); ok {
+		t.Fatal("unexpected conflict between shared locks")
+	}
+	// But they do conflict with exclusive locks.
+	if intent, ok := lt.findConflict(spanAll, txn2, roachpb.KEY_LOCKING_EXCLUSIVE); !ok {
+		t.Fatal("expected conflict between shared and exclusive locks")
+	} else if intent.Txn.ID != txn1.ID || !intent.Key.Equal(keyA) {
+		t.Fatalf("unexpected conflicting intent %+v", intent)
+	}
+	// A transaction never conflicts with itself.
+	if _, ok := lt.findConflict(spanAll, txn1, roachpb.KEY_LOCKING_EXCLUSIVE); ok {
+		t.Fatal("unexpected conflict with own lock")
+	}
+
+	// Upgrade the lock and lock more keys exclusively.
+	lt.acquire(txn1, roachpb.KEY_LOCKING_EXCLUSIVE, []roachpb.Key{keyA, keyB})
+	if _, ok := lt.findConflict(roachpb.Span{Key: keyA}, txn2, roachpb.KEY_LOCKING_SHARED); !ok {
+		t.Fatal("expected conflict with exclusive lock")
+	}
+	if _, ok := lt.findConflict(roachpb.Span{Key: keyC}, txn2, roachpb.KEY_LOCKING_EXCLUSIVE); ok {
+		t.Fatal("unexpected conflict on unlocked key")
+	}
+	if keys := lt.conflictingKeys(spanAll, txn2, roachpb.KEY_LOCKING_SHARED); !reflect.DeepEqual(
+		keys, []roachpb.Key{keyA, keyB},
+	) {
+		t.Fatalf("unexpected conflicting keys %v", keys)
+	}
+
+	// Releasing another transaction's locks is a no-op.
+	lt.release(spanAll, txn2.ID)
+	if keys := lt.conflictingKeys(spanAll, txn2, roachpb.KEY_LOCKING_SHARED); len(keys) != 2 {
+		t.Fatalf("unexpected conflicting keys %v", keys)
+	}
+
+	// Release part of the locks.
+	lt.release(roachpb.Span{Key: keyA}, txn1.ID)
+	if keys := lt.conflictingKeys(spanAll, txn2, roachpb.KEY_LOCKING_SHARED); !reflect.DeepEqual(
+		keys, []roachpb.Key{keyB},
+	) {
+		t.Fatalf("unexpected conflicting keys %v", keys)
+	}
+
+	// Clearing the table releases all locks.
+	lt.clear()
+	if _, ok := lt.findConflict(spanAll, txn2, roachpb.KEY_LOCKING_EXCLUSIVE); ok {
+		t.Fatal("unexpected conflict after clearing lock table")
+	}
+}
+
+func TestLockTableReplicatedLocks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	txn1 := &enginepb.TxnMeta{ID: uuid.MakeV4()}
+	txn2 := &enginepb.TxnMeta{ID: uuid.MakeV4()}
+	keyA := roachpb.Key("a")
+	spanA := roachpb.Span{Key: keyA}
+
+	var lt lockTable
+
+	// A write intent replaces the shared lock of its transaction.
+	lt.acquire(txn1, roachpb.KEY_LOCKING_SHARED, []roachpb.Key{keyA})
+	lt.acquireIntents(txn1, []roachpb.Key{keyA})
+	if _, ok := lt.findConflict(spanA, txn2, roachpb.KEY_LOCKING_SHARED); !ok {
+		t.Fatal("expected conflict with write intent")
+	}
+
+	// Acquiring an unreplicated lock doesn't downgrade the intent.
+	lt.acquire(txn1, roachpb.KEY_LOCKING_EXCLUSIVE, []roachpb.Key{keyA})
+	lt.acquire(txn1, roachpb.KEY_LOCKING_SHARED, []roachpb.Key{keyA})
+	lt.mu.Lock()
+	lt.forEachInSpanLocked(spanA, func(k *lockedKey) bool {
+		if k.exclusive == nil || k.exclusive.durability != lockIntent || len(k.shared) != 0 {
+			t.Fatalf("unexpected locks on %s: %+v, %+v", k.key, k.exclusive, k.shared)
+		}
+		return true
+	})
+	lt.mu.Unlock()
+
+	// Resolving the intent for a pending transaction only releases the lock if
+	// the intent is from an earlier epoch.
+	lt.releaseStaleIntents(spanA, txn1)
+	if _, ok := lt.findConflict(spanA, txn2, roachpb.KEY_LOCKING_EXCLUSIVE); !ok {
+		t.Fatal("expected conflict with write intent of the current epoch")
+	}
+	txn1Restarted := *txn1
+	txn1Restarted.Epoch++
+	lt.releaseStaleIntents(spanA, &txn1Restarted)
+	if _, ok := lt.findConflict(spanA, txn2, roachpb.KEY_LOCKING_EXCLUSIVE); ok {
+		t.Fatal("unexpected conflict with write intent of an earlier epoch")
+	}
+
+	// Resolving the intent of a finalized transaction releases the lock.
+	lt.acquireIntents(txn1, []roachpb.Key{keyA})
+	lt.release(spanA, txn1.ID)
+	if _, ok := lt.findConflict(spanA, txn2, roachpb.KEY_LOCKING_EXCLUSIVE); ok {
+		t.Fatal("unexpected conflict after releasing write intent")
+	}
+}
+
+func TestLockTableWaitQueue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	txn1 := &enginepb.TxnMeta{ID: uuid.MakeV4()}
+	txn2 := &enginepb.TxnMeta{ID: uuid.MakeV4()}
+	txn3 := &enginepb.TxnMeta{ID: uuid.MakeV4()}
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+	exclusive := func(key roachpb.Key) []lockRequest {
+		return []lockRequest{{span: roachpb.Span{Key: key}, str: roachpb.KEY_LOCKING_EXCLUSIVE}}
+	}
+	const noPush = time.Hour
+
+	var lt lockTable
+
+	// Requests that don't lock aren't queued.
+	if g := lt.enqueue(txn1, []lockRequest{{span: roachpb.Span{Key: keyA}}}); g != nil {
+		t.Fatal("unexpected guard for non-locking request")
+	}
+
+	// A request on an unlocked key doesn't wait.
+	g1 := lt.enqueue(txn1, exclusive(keyA))
+	if _, intent, err := lt.wait(ctx, g1, noPush); err != nil || intent != nil {
+		t.Fatalf("unexpected wait result %v, %v", intent, err)
+	}
+	lt.acquire(txn1, roachpb.KEY_LOCKING_EXCLUSIVE, []roachpb.Key{keyA})
+
+	// Requests of other transactions wait for the lock to be released, and
+	// then for the requests ahead of them.
+	g2 := lt.enqueue(txn2, exclusive(keyA))
+	g3 := lt.enqueue(txn3, exclusive(keyA))
+	done2, done3 := make(chan error, 1), make(chan error, 1)
+	go func() {
+		_, _, err := lt.wait(ctx, g2, noPush)
+		done2 <- err
+	}()
+	go func() {
+		_, _, err := lt.wait(ctx, g3, noPush)
+		done3 <- err
+	}()
+	lt.dequeue(g1)
+	select {
+	case err := <-done2:
+		t.Fatalf("request finished waiting while key was locked: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	lt.release(roachpb.Span{Key: keyA}, txn1.ID)
+	if err := <-done2; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done3:
+		t.Fatalf("request finished waiting before the request ahead of it: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	lt.dequeue(g2)
+	if err := <-done3; err != nil {
+		t.Fatal(err)
+	}
+	lt.dequeue(g3)
+
+	// A request still blocked by a lock after the push delay returns the lock
+	// so that its holder can be pushed.
+	lt.acquire(txn1, roachpb.KEY_LOCKING_SHARED, []roachpb.Key{keyB})
+	g := lt.enqueue(txn2, append(exclusive(keyA), exclusive(keyB)...))
+	index, intent, err := lt.wait(ctx, g, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 1 || intent == nil || intent.Txn.ID != txn1.ID || !intent.Key.Equal(keyB) {
+		t.Fatalf("unexpected wait result %d, %+v", index, intent)
+	}
+	lt.dequeue(g)
+
+	// Waiting stops when the context is canceled.
+	g = lt.enqueue(txn2, exclusive(keyB))
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := lt.wait(cancelCtx, g, noPush); err != context.Canceled {
+		t.Fatalf("expected context cancellation, got %v", err)
+	}
+	lt.dequeue(g)
+
+	// Once the locks are gone and no requests are queued, nothing is left in
+	// the table.
+	lt.release(roachpb.Span{Key: keyB}, txn1.ID)
+	if n := lt.t.Len(); n != 0 {
+		t.Fatalf("expected empty lock table, found %d keys", n)
+	}
+}
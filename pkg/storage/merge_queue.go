@@ -275,16 +275,17 @@ func (mq *mergeQueue) process(
 	}
 
 	{
-		// AdminMerge errors if there are learners on either side and
-		// AdminRelocateRange removes any on the range it operates on. For the sake
-		// of obviousness, just remove them all upfront.
-		newLHSDesc, err := removeLearners(ctx, lhsRepl.store.DB(), lhsDesc)
+		// AdminMerge errors if there are learners or non-voters on either side
+		// and AdminRelocateRange removes any on the range it operates on. For the
+		// sake of obviousness, just remove them all upfront. The replicate queue
+		// adds the non-voters of the merged range back.
+		newLHSDesc, err := removeNonVotingReplicas(ctx, lhsRepl.store.DB(), lhsDesc)
 		if err != nil {
 			log.VEventf(ctx, 2, `%v`, err)
 			return err
 		}
 		lhsDesc = newLHSDesc
-		newRHSDesc, err := removeLearners(ctx, lhsRepl.store.DB(), &rhsDesc)
+		newRHSDesc, err := removeNonVotingReplicas(ctx, lhsRepl.store.DB(), &rhsDesc)
 		if err != nil {
 			log.VEventf(ctx, 2, `%v`, err)
 			return err
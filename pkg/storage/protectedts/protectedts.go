@@ -0,0 +1,123 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package protectedts houses the interfaces and basic definitions of the
+// protected timestamp subsystem.
+//
+// Protected timestamps allow long-running operations, such as backups and
+// changefeeds, to prevent the GC queue from collecting the history they still
+// need to read, independently of the GC TTL of the zones involved. A client
+// protects a timestamp over a set of spans by writing a Record into the
+// system.protected_ts_records table through the Storage interface and, once
+// it no longer needs the history, releases it.
+//
+// Every store reads the records through a Cache, which polls the table
+// periodically. The GC queue won't move the GC threshold of a range to or past
+// the timestamp of any record overlapping it. Records written after the cache
+// was last read are not yet visible to it, so the GC queue measures the GC TTL
+// against the time at which the cache was read rather than the current time.
+// As a consequence, a record is only guaranteed to be honored if, when it is
+// written, the data it protects hasn't yet expired according to the GC TTL.
+// Callers are expected to protect timestamps that are at most a few minutes
+// old; protecting an older timestamp may fail to prevent the GC of data that
+// has already expired.
+package protectedts
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
+)
+
+// ErrNotExists is returned from Release and GetRecord when a record does not
+// exist.
+var ErrNotExists = errors.New("protected timestamp record does not exist")
+
+// ErrExists is returned from Protect when a record with the same ID already
+// exists.
+var ErrExists = errors.New("protected timestamp record already exists")
+
+// CheckVersion returns an error unless protected timestamps may be used in the
+// cluster. Records written while older nodes are around wouldn't be honored by
+// their GC queues, so protecting a timestamp must fail rather than silently
+// not protect anything.
+func CheckVersion(settings *cluster.Settings) error {
+	if !settings.Version.IsActive(cluster.VersionProtectedTimestamps) {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"protected timestamps require all nodes to be upgraded to %s",
+			cluster.VersionByKey(cluster.VersionProtectedTimestamps))
+	}
+	return nil
+}
+
+// Record is a protection of the history of a set of spans at and above a
+// timestamp.
+type Record struct {
+	// ID uniquely identifies the record. It is chosen by the client.
+	ID uuid.UUID
+	// Timestamp is the protected timestamp. The GC queue won't collect any
+	// version of a key in one of the spans which is visible at this timestamp
+	// or later.
+	Timestamp hlc.Timestamp
+	// MetaType and Meta allow the client to associate the record with the
+	// entity which holds it, for example a job. They aren't interpreted by the
+	// subsystem.
+	MetaType string
+	Meta     []byte
+	// Spans are the spans whose history is protected.
+	Spans []roachpb.Span
+}
+
+// Storage provides transactional access to the protected timestamp records.
+type Storage interface {
+	// Protect writes the record in the transaction. It returns ErrExists if a
+	// record with the same ID already exists.
+	Protect(ctx context.Context, txn *client.Txn, r *Record) error
+
+	// GetRecord reads the record with the given ID in the transaction. It
+	// returns ErrNotExists if the record does not exist.
+	GetRecord(ctx context.Context, txn *client.Txn, id uuid.UUID) (*Record, error)
+
+	// Release removes the record with the given ID in the transaction. It
+	// returns ErrNotExists if the record does not exist.
+	Release(ctx context.Context, txn *client.Txn, id uuid.UUID) error
+}
+
+// Iterator is called for each record visited by Cache.Iterate. Iteration stops
+// if it returns false. The record must not be modified or retained.
+type Iterator func(*Record) (wantMore bool)
+
+// Cache is a periodically updated view of the protected timestamp records.
+type Cache interface {
+	// Iterate calls it for each record which overlaps the span [from, to). It
+	// returns the timestamp as of which the records were read, which is zero
+	// if they haven't been read yet.
+	Iterate(ctx context.Context, from, to roachpb.Key, it Iterator) (asOf hlc.Timestamp)
+
+	// Refresh reads the records if they were last read before asOf.
+	Refresh(ctx context.Context, asOf hlc.Timestamp) error
+}
+
+// Provider is the protected timestamp subsystem of a node.
+type Provider interface {
+	Storage
+	Cache
+
+	// Start starts polling the records in the background.
+	Start(ctx context.Context, stopper *stop.Stopper) error
+}
@@ -0,0 +1,271 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package protectedts
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	protectQuery = `
+INSERT INTO system.protected_ts_records (id, ts, meta_type, meta, spans)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT DO NOTHING`
+	getRecordQuery = `
+SELECT id, ts, meta_type, meta, spans FROM system.protected_ts_records WHERE id = $1`
+	getRecordsQuery = `
+SELECT id, ts, meta_type, meta, spans FROM system.protected_ts_records`
+	releaseQuery = `
+DELETE FROM system.protected_ts_records WHERE id = $1`
+)
+
+// Config configures a Provider.
+type Config struct {
+	Settings *cluster.Settings
+	DB       *client.DB
+	Executor sqlutil.InternalExecutor
+}
+
+// provider implements Provider on top of the system.protected_ts_records
+// table. The cache holds all of the records in memory: they're expected to be
+// few, as each is held by a long-running operation.
+type provider struct {
+	settings *cluster.Settings
+	db       *client.DB
+	ex       sqlutil.InternalExecutor
+
+	mu struct {
+		syncutil.RWMutex
+		records []Record
+		readAt  hlc.Timestamp
+	}
+}
+
+var _ Provider = (*provider)(nil)
+
+// NewProvider returns a Provider which stores the records in the
+// system.protected_ts_records table.
+func NewProvider(cfg Config) Provider {
+	return &provider{
+		settings: cfg.Settings,
+		db:       cfg.DB,
+		ex:       cfg.Executor,
+	}
+}
+
+// Protect implements Storage.
+func (p *provider) Protect(ctx context.Context, txn *client.Txn, r *Record) error {
+	if err := CheckVersion(p.settings); err != nil {
+		return err
+	}
+	if r.ID == uuid.Nil {
+		return errors.New("protected timestamp record must have an ID")
+	}
+	if r.Timestamp == (hlc.Timestamp{}) {
+		return errors.Errorf("protected timestamp record %s must have a timestamp", r.ID)
+	}
+	if len(r.Spans) == 0 {
+		return errors.Errorf("protected timestamp record %s must protect at least one span", r.ID)
+	}
+	var meta tree.Datum = tree.DNull
+	if r.Meta != nil {
+		meta = tree.NewDBytes(tree.DBytes(r.Meta))
+	}
+	rows, err := p.ex.Exec(ctx, "protectedts-protect", txn, protectQuery,
+		tree.NewDUuid(tree.DUuid{UUID: r.ID}),
+		tree.TimestampToDecimal(r.Timestamp),
+		r.MetaType,
+		meta,
+		tree.NewDBytes(tree.DBytes(encodeSpans(r.Spans))),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write protected timestamp record %s", r.ID)
+	}
+	if rows == 0 {
+		return ErrExists
+	}
+	return nil
+}
+
+// GetRecord implements Storage.
+func (p *provider) GetRecord(
+	ctx context.Context, txn *client.Txn, id uuid.UUID,
+) (*Record, error) {
+	row, err := p.ex.QueryRow(ctx, "protectedts-get-record", txn, getRecordQuery,
+		tree.NewDUuid(tree.DUuid{UUID: id}))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read protected timestamp record %s", id)
+	}
+	if row == nil {
+		return nil, ErrNotExists
+	}
+	return recordFromRow(row)
+}
+
+// Release implements Storage.
+func (p *provider) Release(ctx context.Context, txn *client.Txn, id uuid.UUID) error {
+	rows, err := p.ex.Exec(ctx, "protectedts-release", txn, releaseQuery,
+		tree.NewDUuid(tree.DUuid{UUID: id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to release protected timestamp record %s", id)
+	}
+	if rows == 0 {
+		return ErrNotExists
+	}
+	return nil
+}
+
+// Iterate implements Cache.
+func (p *provider) Iterate(
+	_ context.Context, from, to roachpb.Key, it Iterator,
+) (asOf hlc.Timestamp) {
+	span := roachpb.Span{Key: from, EndKey: to}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for i := range p.mu.records {
+		r := &p.mu.records[i]
+		for _, sp := range r.Spans {
+			if span.Overlaps(sp) {
+				if !it(r) {
+					return p.mu.readAt
+				}
+				break
+			}
+		}
+	}
+	return p.mu.readAt
+}
+
+// Refresh implements Cache.
+func (p *provider) Refresh(ctx context.Context, asOf hlc.Timestamp) error {
+	p.mu.RLock()
+	readAt := p.mu.readAt
+	p.mu.RUnlock()
+	if !readAt.Less(asOf) {
+		return nil
+	}
+	return p.read(ctx)
+}
+
+// Start implements Provider.
+func (p *provider) Start(ctx context.Context, stopper *stop.Stopper) error {
+	return stopper.RunAsyncTask(ctx, "protectedts-poll", func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+
+		var timer timeutil.Timer
+		defer timer.Stop()
+		timer.Reset(0)
+		for {
+			select {
+			case <-timer.C:
+				timer.Read = true
+				if err := p.read(ctx); err != nil {
+					log.Warningf(ctx, "%v", err)
+				}
+				timer.Reset(PollInterval.Get(&p.settings.SV))
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// read reads all of the records, replacing the cached ones.
+func (p *provider) read(ctx context.Context) error {
+	var records []Record
+	var readAt hlc.Timestamp
+	if err := p.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		rows, err := p.ex.Query(ctx, "protectedts-read-records", txn, getRecordsQuery)
+		if err != nil {
+			return err
+		}
+		records = make([]Record, 0, len(rows))
+		for _, row := range rows {
+			r, err := recordFromRow(row)
+			if err != nil {
+				return err
+			}
+			records = append(records, *r)
+		}
+		readAt = txn.CommitTimestamp()
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "failed to read protected timestamp records")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.mu.readAt.Less(readAt) {
+		p.mu.records, p.mu.readAt = records, readAt
+	}
+	return nil
+}
+
+func recordFromRow(row tree.Datums) (*Record, error) {
+	ts, err := tree.DecimalToHLC(&row[1].(*tree.DDecimal).Decimal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode protected timestamp")
+	}
+	r := &Record{
+		ID:        row[0].(*tree.DUuid).UUID,
+		Timestamp: ts,
+		MetaType:  string(tree.MustBeDString(row[2])),
+	}
+	if row[3] != tree.DNull {
+		r.Meta = []byte(tree.MustBeDBytes(row[3]))
+	}
+	if r.Spans, err = decodeSpans([]byte(tree.MustBeDBytes(row[4]))); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode spans of protected timestamp record %s", r.ID)
+	}
+	return r, nil
+}
+
+// encodeSpans encodes spans as a sequence of their start and end keys.
+func encodeSpans(spans []roachpb.Span) []byte {
+	var buf []byte
+	for _, sp := range spans {
+		buf = encoding.EncodeBytesAscending(buf, sp.Key)
+		buf = encoding.EncodeBytesAscending(buf, sp.EndKey)
+	}
+	return buf
+}
+
+// decodeSpans decodes spans encoded with encodeSpans.
+func decodeSpans(buf []byte) ([]roachpb.Span, error) {
+	var spans []roachpb.Span
+	for len(buf) > 0 {
+		var sp roachpb.Span
+		var err error
+		if buf, sp.Key, err = encoding.DecodeBytesAscending(buf, nil); err != nil {
+			return nil, err
+		}
+		if buf, sp.EndKey, err = encoding.DecodeBytesAscending(buf, nil); err != nil {
+			return nil, err
+		}
+		spans = append(spans, sp)
+	}
+	return spans, nil
+}
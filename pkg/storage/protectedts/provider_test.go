@@ -0,0 +1,171 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package protectedts_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, _, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	p := protectedts.NewProvider(protectedts.Config{
+		Settings: s.ClusterSettings(),
+		DB:       kvDB,
+		Executor: s.InternalExecutor().(sqlutil.InternalExecutor),
+	})
+	protect := func(r *protectedts.Record) error {
+		return kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return p.Protect(ctx, txn, r)
+		})
+	}
+	release := func(id uuid.UUID) error {
+		return kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			return p.Release(ctx, txn, id)
+		})
+	}
+	iterate := func(from, to string) (ids []uuid.UUID, asOf hlc.Timestamp) {
+		asOf = p.Iterate(ctx, roachpb.Key(from), roachpb.Key(to), func(r *protectedts.Record) bool {
+			ids = append(ids, r.ID)
+			return true
+		})
+		return ids, asOf
+	}
+
+	// Nothing is known before the records are read.
+	_, asOf := iterate("a", "z")
+	require.Equal(t, hlc.Timestamp{}, asOf)
+
+	rec := &protectedts.Record{
+		ID:        uuid.MakeV4(),
+		Timestamp: s.Clock().Now(),
+		MetaType:  "test",
+		Meta:      []byte("meta"),
+		Spans: []roachpb.Span{
+			{Key: roachpb.Key("b"), EndKey: roachpb.Key("c")},
+			{Key: roachpb.Key("e"), EndKey: roachpb.Key("f")},
+		},
+	}
+	require.NoError(t, protect(rec))
+	require.Equal(t, protectedts.ErrExists, protect(rec))
+	require.Error(t, protect(&protectedts.Record{ID: uuid.MakeV4(), Timestamp: s.Clock().Now()}))
+
+	var got *protectedts.Record
+	require.NoError(t, kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) (err error) {
+		got, err = p.GetRecord(ctx, txn, rec.ID)
+		return err
+	}))
+	require.Equal(t, rec, got)
+
+	// The record is visible in the cache after a refresh past its write.
+	now := s.Clock().Now()
+	require.NoError(t, p.Refresh(ctx, now))
+	ids, asOf := iterate("a", "z")
+	require.Equal(t, []uuid.UUID{rec.ID}, ids)
+	require.False(t, asOf.Less(now))
+	ids, _ = iterate("c", "e")
+	require.Empty(t, ids)
+	ids, _ = iterate("e\x00", "e\x01")
+	require.Equal(t, []uuid.UUID{rec.ID}, ids)
+
+	require.NoError(t, release(rec.ID))
+	require.Equal(t, protectedts.ErrNotExists, release(rec.ID))
+	require.NoError(t, kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		_, err := p.GetRecord(ctx, txn, rec.ID)
+		require.Equal(t, protectedts.ErrNotExists, err)
+		return nil
+	}))
+
+	// The cache keeps the record until it is refreshed.
+	ids, _ = iterate("a", "z")
+	require.Equal(t, []uuid.UUID{rec.ID}, ids)
+	require.NoError(t, p.Refresh(ctx, s.Clock().Now()))
+	ids, _ = iterate("a", "z")
+	require.Empty(t, ids)
+}
+
+// Refresh is a no-op when the records were read at or after asOf.
+func TestProviderRefreshNoop(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, _, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	p := protectedts.NewProvider(protectedts.Config{
+		Settings: cluster.MakeTestingClusterSettings(),
+		DB:       kvDB,
+		Executor: s.InternalExecutor().(sqlutil.InternalExecutor),
+	})
+	require.NoError(t, p.Refresh(ctx, s.Clock().Now()))
+	asOf := p.Iterate(ctx, roachpb.KeyMin, roachpb.KeyMax, func(*protectedts.Record) bool { return true })
+	require.NoError(t, kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return p.Protect(ctx, txn, &protectedts.Record{
+			ID:        uuid.MakeV4(),
+			Timestamp: s.Clock().Now(),
+			Spans:     []roachpb.Span{{Key: roachpb.KeyMin, EndKey: roachpb.KeyMax}},
+		})
+	}))
+	require.NoError(t, p.Refresh(ctx, asOf))
+	var n int
+	require.Equal(t, asOf, p.Iterate(ctx, roachpb.KeyMin, roachpb.KeyMax, func(*protectedts.Record) bool {
+		n++
+		return true
+	}))
+	require.Zero(t, n)
+}
+
+// Protect fails until all nodes can honor the records.
+func TestProviderProtectRequiresVersion(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, _, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	v := cluster.VersionByKey(cluster.VersionProtectedTimestamps - 1)
+	p := protectedts.NewProvider(protectedts.Config{
+		Settings: cluster.MakeTestingClusterSettingsWithVersion(v, v),
+		DB:       kvDB,
+		Executor: s.InternalExecutor().(sqlutil.InternalExecutor),
+	})
+	rec := &protectedts.Record{
+		ID:        uuid.MakeV4(),
+		Timestamp: s.Clock().Now(),
+		Spans:     []roachpb.Span{{Key: roachpb.KeyMin, EndKey: roachpb.KeyMax}},
+	}
+	err := kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		return p.Protect(ctx, txn, rec)
+	})
+	require.Regexp(t, "protected timestamps require all nodes to be upgraded", err)
+	require.NoError(t, kvDB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		_, err := p.GetRecord(ctx, txn, rec.ID)
+		require.Equal(t, protectedts.ErrNotExists, err)
+		return nil
+	}))
+}
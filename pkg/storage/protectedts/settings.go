@@ -0,0 +1,26 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package protectedts
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
+
+// PollInterval is how often each node reads the protected timestamp records.
+// The GC queue measures the GC TTL against the time at which the records were
+// last read, so a longer interval delays GC by up to that long.
+var PollInterval = settings.RegisterNonNegativeDurationSetting(
+	"kv.protectedts.poll_interval",
+	"the interval at which the protected timestamp records are read by each node",
+	2*time.Minute,
+)
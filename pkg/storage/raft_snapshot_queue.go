@@ -110,8 +110,9 @@ func (rq *raftSnapshotQueue) processRaftSnapshot(
 	// A learner replica is either getting a snapshot of type LEARNER by the node
 	// that's adding it or it's been orphaned and it's about to be cleaned up by
 	// the replicate queue. Either way, no point in also sending it a snapshot of
-	// type RAFT.
-	if repDesc.GetType() == roachpb.ReplicaType_LEARNER {
+	// type RAFT. The same goes for a non-voter that is being added, which also
+	// gets a snapshot of type LEARNER.
+	if typ := repDesc.GetType(); typ == roachpb.ReplicaType_LEARNER || typ == roachpb.ReplicaType_NON_VOTER {
 		if index := repl.getAndGCSnapshotLogTruncationConstraints(timeutil.Now()); index > 0 {
 			// There is a snapshot being transferred. It's probably a LEARNER snap, so
 			// bail for now and try again later.
@@ -13,6 +13,7 @@ package rangefeed
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -110,6 +111,10 @@ type Processor struct {
 	reg registry
 	rts resolvedTimestamp
 
+	// withDiffRegs is the number of registrations that want the previous value
+	// of each key in the updates they receive. Accessed atomically.
+	withDiffRegs int32
+
 	regC     chan registration
 	unregC   chan *registration
 	lenReqC  chan struct{}
@@ -224,13 +229,13 @@ func (p *Processor) Start(stopper *stop.Stopper, rtsIter engine.SimpleIterator)
 						r.catchupIter.Close() // clean up
 					}
 					r.disconnect(roachpb.NewError(err))
-					p.reg.Unregister(&r)
+					p.unregister(&r)
 				}
 
 			// Respond to unregistration requests; these come from registrations that
 			// encounter an error during their output loop.
 			case r := <-p.unregC:
-				p.reg.Unregister(r)
+				p.unregister(r)
 
 			// Respond to answers about the processor goroutine state.
 			case <-p.lenReqC:
@@ -336,6 +341,11 @@ func (p *Processor) sendStop(pErr *roachpb.Error) {
 // The optionally provided "catch-up" iterator is used to read changes from the
 // engine which occurred after the provided start timestamp.
 //
+// If withDiff is true, the RangeFeedValue events published to the registration
+// will include the previous value of their key. The caller is expected to
+// populate the previous values of the logical ops it passes to the Processor
+// for as long as NeedsPrevValues returns true.
+//
 // If the method returns false, the processor will have been stopped, so calling
 // Stop is not necessary.
 //
@@ -344,6 +354,7 @@ func (p *Processor) Register(
 	span roachpb.RSpan,
 	startTS hlc.Timestamp,
 	catchupIter engine.SimpleIterator,
+	withDiff bool,
 	stream Stream,
 	errC chan<- *roachpb.Error,
 ) bool {
@@ -353,15 +364,45 @@ func (p *Processor) Register(
 	p.syncEventC()
 
 	r := newRegistration(
-		span.AsRawSpanWithNoLocals(), startTS, catchupIter, p.Config.EventChanCap,
-		p.Metrics, stream, errC,
+		span.AsRawSpanWithNoLocals(), startTS, catchupIter, withDiff,
+		p.Config.EventChanCap, p.Metrics, stream, errC,
 	)
+	// Count the registration before handing it to the processor goroutine so
+	// that any logical ops consumed after this method returns carry previous
+	// values.
+	if withDiff {
+		atomic.AddInt32(&p.withDiffRegs, 1)
+	}
 	select {
 	case p.regC <- r:
 		return true
 	case <-p.stoppedC:
+		if withDiff {
+			atomic.AddInt32(&p.withDiffRegs, -1)
+		}
+		return false
+	}
+}
+
+// unregister removes the registration from the registry.
+func (p *Processor) unregister(r *registration) {
+	p.reg.Unregister(r)
+	if r.withDiff {
+		atomic.AddInt32(&p.withDiffRegs, -1)
+	}
+}
+
+// NeedsPrevValues returns whether any registration wants the previous value of
+// each key in the updates it receives. If so, the logical ops passed to
+// ConsumeLogicalOps are expected to include the previous values of the keys
+// they write.
+//
+// Safe to call on nil Processor.
+func (p *Processor) NeedsPrevValues() bool {
+	if p == nil {
 		return false
 	}
+	return atomic.LoadInt32(&p.withDiffRegs) > 0
 }
 
 // Len returns the number of registrations attached to the processor.
@@ -496,7 +537,7 @@ func (p *Processor) consumeLogicalOps(ctx context.Context, ops []enginepb.MVCCLo
 		switch t := op.GetValue().(type) {
 		case *enginepb.MVCCWriteValueOp:
 			// Publish the new value directly.
-			p.publishValue(ctx, t.Key, t.Timestamp, t.Value)
+			p.publishValue(ctx, t.Key, t.Timestamp, t.Value, t.PrevValue)
 
 		case *enginepb.MVCCWriteIntentOp:
 			// No updates to publish.
@@ -506,7 +547,7 @@ func (p *Processor) consumeLogicalOps(ctx context.Context, ops []enginepb.MVCCLo
 
 		case *enginepb.MVCCCommitIntentOp:
 			// Publish the newly committed value.
-			p.publishValue(ctx, t.Key, t.Timestamp, t.Value)
+			p.publishValue(ctx, t.Key, t.Timestamp, t.Value, t.PrevValue)
 
 		case *enginepb.MVCCAbortIntentOp:
 			// No updates to publish.
@@ -539,7 +580,7 @@ func (p *Processor) initResolvedTS(ctx context.Context) {
 }
 
 func (p *Processor) publishValue(
-	ctx context.Context, key roachpb.Key, timestamp hlc.Timestamp, value []byte,
+	ctx context.Context, key roachpb.Key, timestamp hlc.Timestamp, value, prevValue []byte,
 ) {
 	if !p.Span.ContainsKey(roachpb.RKey(key)) {
 		log.Fatalf(ctx, "key %v not in Processor's key range %v", key, p.Span)
@@ -553,6 +594,9 @@ func (p *Processor) publishValue(
 			RawBytes:  value,
 			Timestamp: timestamp,
 		},
+		PrevValue: roachpb.Value{
+			RawBytes: prevValue,
+		},
 	})
 	p.reg.PublishToOverlapping(span, &event)
 }
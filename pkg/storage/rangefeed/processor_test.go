@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -117,6 +118,14 @@ func rangeFeedValue(key roachpb.Key, val roachpb.Value) *roachpb.RangeFeedEvent
 	})
 }
 
+func rangeFeedValueWithPrev(key roachpb.Key, val, prev roachpb.Value) *roachpb.RangeFeedEvent {
+	return makeRangeFeedEvent(&roachpb.RangeFeedValue{
+		Key:       key,
+		Value:     val,
+		PrevValue: prev,
+	})
+}
+
 func rangeFeedCheckpoint(span roachpb.Span, ts hlc.Timestamp) *roachpb.RangeFeedEvent {
 	return makeRangeFeedEvent(&roachpb.RangeFeedCheckpoint{
 		Span:       span,
@@ -186,7 +195,8 @@ func TestProcessorBasic(t *testing.T) {
 	r1OK := p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("m")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,   /* catchUpIter */
+		false, /* withDiff */
 		r1Stream,
 		r1ErrC,
 	)
@@ -301,7 +311,8 @@ func TestProcessorBasic(t *testing.T) {
 	r2OK := p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("c"), EndKey: roachpb.RKey("z")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,   /* catchUpIter */
+		false, /* withDiff */
 		r2Stream,
 		r2ErrC,
 	)
@@ -371,13 +382,98 @@ func TestProcessorBasic(t *testing.T) {
 	r3OK := p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("c"), EndKey: roachpb.RKey("z")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,   /* catchUpIter */
+		false, /* withDiff */
 		r3Stream,
 		r3ErrC,
 	)
 	require.False(t, r3OK)
 }
 
+func TestProcessorWithDiff(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	p, stopper := newTestProcessor(nil /* rtsIter */)
+	defer stopper.Stop(context.Background())
+	require.False(t, p.NeedsPrevValues())
+
+	// Add a registration without a diff and one with a diff.
+	r1Stream := newTestStream()
+	r1ErrC := make(chan *roachpb.Error, 1)
+	require.True(t, p.Register(
+		roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("m")},
+		hlc.Timestamp{WallTime: 1},
+		nil,   /* catchUpIter */
+		false, /* withDiff */
+		r1Stream,
+		r1ErrC,
+	))
+	require.False(t, p.NeedsPrevValues())
+	r2Stream := newTestStream()
+	r2ErrC := make(chan *roachpb.Error, 1)
+	require.True(t, p.Register(
+		roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("m")},
+		hlc.Timestamp{WallTime: 1},
+		nil,  /* catchUpIter */
+		true, /* withDiff */
+		r2Stream,
+		r2ErrC,
+	))
+	require.True(t, p.NeedsPrevValues())
+	p.syncEventAndRegistrations()
+	r1Stream.Events()
+	r2Stream.Events()
+
+	// Only the registration with a diff sees the previous values.
+	txn := uuid.MakeV4()
+	writeOp := writeValueOpWithKV(roachpb.Key("c"), hlc.Timestamp{WallTime: 6}, []byte("val"))
+	writeOp.WriteValue.PrevValue = []byte("prev")
+	p.ConsumeLogicalOps(
+		writeOp,
+		writeIntentOpWithKey(txn, roachpb.Key("d"), hlc.Timestamp{WallTime: 7}),
+		commitIntentOpWithKV(txn, roachpb.Key("d"), hlc.Timestamp{WallTime: 7}, []byte("val2")),
+	)
+	p.syncEventAndRegistrations()
+	require.Equal(t,
+		[]*roachpb.RangeFeedEvent{
+			rangeFeedValue(
+				roachpb.Key("c"),
+				roachpb.Value{RawBytes: []byte("val"), Timestamp: hlc.Timestamp{WallTime: 6}},
+			),
+			rangeFeedValue(
+				roachpb.Key("d"),
+				roachpb.Value{RawBytes: []byte("val2"), Timestamp: hlc.Timestamp{WallTime: 7}},
+			),
+		},
+		r1Stream.Events(),
+	)
+	require.Equal(t,
+		[]*roachpb.RangeFeedEvent{
+			rangeFeedValueWithPrev(
+				roachpb.Key("c"),
+				roachpb.Value{RawBytes: []byte("val"), Timestamp: hlc.Timestamp{WallTime: 6}},
+				roachpb.Value{RawBytes: []byte("prev")},
+			),
+			rangeFeedValueWithPrev(
+				roachpb.Key("d"),
+				roachpb.Value{RawBytes: []byte("val2"), Timestamp: hlc.Timestamp{WallTime: 7}},
+				roachpb.Value{},
+			),
+		},
+		r2Stream.Events(),
+	)
+
+	// Previous values are no longer needed once the registration with a diff
+	// is gone.
+	r2Stream.Cancel()
+	require.NotNil(t, <-r2ErrC)
+	testutils.SucceedsSoon(t, func() error {
+		if p.NeedsPrevValues() {
+			return errors.New("previous values still needed")
+		}
+		return nil
+	})
+}
+
 func TestNilProcessor(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	var p *Processor
@@ -390,11 +486,12 @@ func TestNilProcessor(t *testing.T) {
 	require.NotPanics(t, func() { p.ConsumeLogicalOps(make([]enginepb.MVCCLogicalOp, 5)...) })
 	require.NotPanics(t, func() { p.ForwardClosedTS(hlc.Timestamp{}) })
 	require.NotPanics(t, func() { p.ForwardClosedTS(hlc.Timestamp{WallTime: 1}) })
+	require.False(t, p.NeedsPrevValues())
 
 	// The following should panic because they are not safe
 	// to call on a nil Processor.
 	require.Panics(t, func() { p.Start(stop.NewStopper(), nil) })
-	require.Panics(t, func() { p.Register(roachpb.RSpan{}, hlc.Timestamp{}, nil, nil, nil) })
+	require.Panics(t, func() { p.Register(roachpb.RSpan{}, hlc.Timestamp{}, nil, false, nil, nil) })
 }
 
 func TestProcessorSlowConsumer(t *testing.T) {
@@ -411,7 +508,8 @@ func TestProcessorSlowConsumer(t *testing.T) {
 	p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("m")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,   /* catchUpIter */
+		false, /* withDiff */
 		r1Stream,
 		r1ErrC,
 	)
@@ -420,7 +518,8 @@ func TestProcessorSlowConsumer(t *testing.T) {
 	p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("z")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,   /* catchUpIter */
+		false, /* withDiff */
 		r2Stream,
 		r2ErrC,
 	)
@@ -539,7 +638,8 @@ func TestProcessorInitializeResolvedTimestamp(t *testing.T) {
 	p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("m")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,   /* catchUpIter */
+		false, /* withDiff */
 		r1Stream,
 		make(chan *roachpb.Error, 1),
 	)
@@ -783,7 +883,7 @@ func TestProcessorConcurrentStop(t *testing.T) {
 			runtime.Gosched()
 			s := newTestStream()
 			errC := make(chan<- *roachpb.Error, 1)
-			p.Register(p.Span, hlc.Timestamp{}, nil, s, errC)
+			p.Register(p.Span, hlc.Timestamp{}, nil, false /* withDiff */, s, errC)
 		}()
 		go func() {
 			defer wg.Done()
@@ -852,7 +952,7 @@ func TestProcessorRegistrationObservesOnlyNewEvents(t *testing.T) {
 			s := newTestStream()
 			regs[s] = firstIdx
 			errC := make(chan *roachpb.Error, 1)
-			p.Register(p.Span, hlc.Timestamp{}, nil, s, errC)
+			p.Register(p.Span, hlc.Timestamp{}, nil, false /* withDiff */, s, errC)
 			regDone <- struct{}{}
 		}
 	}()
@@ -56,6 +56,7 @@ type registration struct {
 	span             roachpb.Span
 	catchupIter      engine.SimpleIterator
 	catchupTimestamp hlc.Timestamp
+	withDiff         bool
 	metrics          *Metrics
 
 	// Output.
@@ -86,6 +87,7 @@ func newRegistration(
 	span roachpb.Span,
 	startTS hlc.Timestamp,
 	catchupIter engine.SimpleIterator,
+	withDiff bool,
 	bufferSz int,
 	metrics *Metrics,
 	stream Stream,
@@ -94,6 +96,7 @@ func newRegistration(
 	r := registration{
 		span:             span,
 		catchupIter:      catchupIter,
+		withDiff:         withDiff,
 		metrics:          metrics,
 		stream:           stream,
 		errC:             errC,
@@ -237,7 +240,12 @@ func (r *registration) runCatchupScan() error {
 	// Iterate though all keys using Next. We want to publish all committed
 	// versions of each key that are after the registration's startTS, so we
 	// can't use NextKey.
+	//
+	// If the registration wants diffs, each event also carries the value of
+	// the next older version of its key, which is the next one encountered by
+	// the iterator, even if it's at or before the registration's startTS.
 	var meta enginepb.MVCCMetadata
+	var addPrevToLastEvent bool
 
 	for r.catchupIter.Seek(startKey); ; r.catchupIter.Next() {
 		if ok, err := r.catchupIter.Valid(); err != nil {
@@ -272,7 +280,13 @@ func (r *registration) runCatchupScan() error {
 			unsafeVal = meta.RawBytes
 		} else if !r.catchupTimestamp.Less(unsafeKey.Timestamp) {
 			// At or before the registration's exclusive starting timestamp.
-			// Ignore.
+			// Ignore, unless it is the previous value of the last event added
+			// for this key.
+			if addPrevToLastEvent && bytes.Equal(unsafeKey.Key, lastKey) {
+				lastEvent := reorderBuf[len(reorderBuf)-1].Val
+				a, lastEvent.PrevValue.RawBytes = a.Copy(unsafeVal, 0)
+			}
+			addPrevToLastEvent = false
 			continue
 		}
 
@@ -287,6 +301,8 @@ func (r *registration) runCatchupScan() error {
 				return err
 			}
 			lastKey = key
+		} else if addPrevToLastEvent {
+			reorderBuf[len(reorderBuf)-1].Val.PrevValue.RawBytes = val
 		}
 
 		var event roachpb.RangeFeedEvent
@@ -298,6 +314,7 @@ func (r *registration) runCatchupScan() error {
 			},
 		})
 		reorderBuf = append(reorderBuf, event)
+		addPrevToLastEvent = r.withDiff
 	}
 
 	// Output events for the last key encountered.
@@ -371,12 +388,24 @@ func (reg *registry) PublishToOverlapping(span roachpb.Span, event *roachpb.Rang
 		panic(fmt.Sprintf("unexpected RangeFeedEvent variant: %v", event))
 	}
 
+	// Registrations that didn't ask for diffs are published a copy of the
+	// event without the previous value, which is created lazily.
+	var eventWithoutDiff *roachpb.RangeFeedEvent
 	reg.forOverlappingRegs(span, func(r *registration) (bool, *roachpb.Error) {
 		// Don't publish events if they are equal to or less
 		// than the registration's starting timestamp.
 
 		if r.catchupTimestamp.Less(minTS) {
-			r.publish(event)
+			if !r.withDiff && event.Val != nil && event.Val.PrevValue.RawBytes != nil {
+				if eventWithoutDiff == nil {
+					val := *event.Val
+					val.PrevValue = roachpb.Value{}
+					eventWithoutDiff = &roachpb.RangeFeedEvent{Val: &val}
+				}
+				r.publish(eventWithoutDiff)
+			} else {
+				r.publish(event)
+			}
 		}
 		return false, nil
 	})
@@ -18,6 +18,7 @@ import (
 	_ "github.com/cockroachdb/cockroach/pkg/keys" // hook up pretty printer
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
@@ -96,7 +97,7 @@ type testRegistration struct {
 }
 
 func newTestRegistration(
-	span roachpb.Span, ts hlc.Timestamp, catchup engine.SimpleIterator,
+	span roachpb.Span, ts hlc.Timestamp, catchup engine.SimpleIterator, withDiff bool,
 ) *testRegistration {
 	s := newTestStream()
 	errC := make(chan *roachpb.Error, 1)
@@ -105,6 +106,7 @@ func newTestRegistration(
 			span,
 			ts,
 			catchup,
+			withDiff,
 			5,
 			NewMetrics(),
 			s,
@@ -137,7 +139,7 @@ func TestRegistrationBasic(t *testing.T) {
 	ev2.MustSetValue(&roachpb.RangeFeedValue{Value: val})
 
 	// Registration with no catchup scan specified.
-	noCatchupReg := newTestRegistration(spAB, hlc.Timestamp{}, nil)
+	noCatchupReg := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
 	noCatchupReg.publish(ev1)
 	noCatchupReg.publish(ev2)
 	require.Equal(t, len(noCatchupReg.buf), 2)
@@ -153,7 +155,7 @@ func TestRegistrationBasic(t *testing.T) {
 		makeInline("ba", "val2"),
 		makeKV("bc", "val3", 11),
 		makeKV("bd", "val4", 9),
-	}))
+	}), false /* withDiff */)
 	catchupReg.publish(ev1)
 	catchupReg.publish(ev2)
 	require.Equal(t, len(catchupReg.buf), 2)
@@ -167,7 +169,7 @@ func TestRegistrationBasic(t *testing.T) {
 
 	// EXIT CONDITIONS
 	// External Disconnect.
-	disconnectReg := newTestRegistration(spAB, hlc.Timestamp{}, nil)
+	disconnectReg := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
 	disconnectReg.publish(ev1)
 	disconnectReg.publish(ev2)
 	go disconnectReg.runOutputLoop(context.Background())
@@ -178,7 +180,7 @@ func TestRegistrationBasic(t *testing.T) {
 	require.Equal(t, discErr, err)
 
 	// Overflow.
-	overflowReg := newTestRegistration(spAB, hlc.Timestamp{}, nil)
+	overflowReg := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
 	for i := 0; i < cap(overflowReg.buf)+3; i++ {
 		overflowReg.publish(ev1)
 	}
@@ -188,7 +190,7 @@ func TestRegistrationBasic(t *testing.T) {
 	require.Equal(t, cap(overflowReg.buf), len(overflowReg.Events()))
 
 	// Stream Error.
-	streamErrReg := newTestRegistration(spAB, hlc.Timestamp{}, nil)
+	streamErrReg := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
 	streamErr := fmt.Errorf("stream error")
 	streamErrReg.stream.SetSendErr(streamErr)
 	go streamErrReg.runOutputLoop(context.Background())
@@ -197,7 +199,7 @@ func TestRegistrationBasic(t *testing.T) {
 	require.Equal(t, streamErr.Error(), err.GoError().Error())
 
 	// Stream Context Canceled.
-	streamCancelReg := newTestRegistration(spAB, hlc.Timestamp{}, nil)
+	streamCancelReg := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
 	streamCancelReg.stream.Cancel()
 	go streamCancelReg.runOutputLoop(context.Background())
 	require.NoError(t, streamCancelReg.waitForCaughtUp())
@@ -208,60 +210,79 @@ func TestRegistrationBasic(t *testing.T) {
 func TestRegistrationCatchUpScan(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
-	// Run a catch-up scan for a registration over a test
-	// iterator with the following keys.
-	txn1, txn2 := uuid.MakeV4(), uuid.MakeV4()
-	iter := newTestIterator([]engine.MVCCKeyValue{
-		makeKV("a", "val1", 10),
-		makeInline("b", "val2"),
-		makeIntent("c", txn1, "txnKey1", 15),
-		makeProvisionalKV("c", "txnKey1", 15),
-		makeKV("c", "val3", 11),
-		makeKV("c", "val4", 9),
-		makeIntent("d", txn2, "txnKey2", 21),
-		makeProvisionalKV("d", "txnKey2", 21),
-		makeKV("d", "val5", 20),
-		makeKV("d", "val6", 19),
-		makeInline("g", "val7"),
-		makeKV("m", "val8", 1),
-		makeIntent("n", txn1, "txnKey1", 12),
-		makeProvisionalKV("n", "txnKey1", 12),
-		makeIntent("r", txn1, "txnKey1", 19),
-		makeProvisionalKV("r", "txnKey1", 19),
-		makeKV("r", "val9", 4),
-		makeIntent("w", txn1, "txnKey1", 3),
-		makeProvisionalKV("w", "txnKey1", 3),
-		makeInline("x", "val10"),
-		makeIntent("z", txn2, "txnKey2", 21),
-		makeProvisionalKV("z", "txnKey2", 21),
-		makeKV("z", "val11", 4),
+	testutils.RunTrueAndFalse(t, "withDiff", func(t *testing.T, withDiff bool) {
+		// Run a catch-up scan for a registration over a test
+		// iterator with the following keys.
+		txn1, txn2 := uuid.MakeV4(), uuid.MakeV4()
+		iter := newTestIterator([]engine.MVCCKeyValue{
+			makeKV("a", "val1", 10),
+			makeInline("b", "val2"),
+			makeIntent("c", txn1, "txnKey1", 15),
+			makeProvisionalKV("c", "txnKey1", 15),
+			makeKV("c", "val3", 11),
+			makeKV("c", "val4", 9),
+			makeIntent("d", txn2, "txnKey2", 21),
+			makeProvisionalKV("d", "txnKey2", 21),
+			makeKV("d", "val5", 20),
+			makeKV("d", "val6", 19),
+			makeInline("g", "val7"),
+			makeKV("m", "val8", 5),
+			makeKV("m", "val9", 1),
+			makeIntent("n", txn1, "txnKey1", 12),
+			makeProvisionalKV("n", "txnKey1", 12),
+			makeIntent("r", txn1, "txnKey1", 19),
+			makeProvisionalKV("r", "txnKey1", 19),
+			makeKV("r", "val10", 4),
+			makeIntent("w", txn1, "txnKey1", 3),
+			makeProvisionalKV("w", "txnKey1", 3),
+			makeInline("x", "val11"),
+			makeIntent("z", txn2, "txnKey2", 21),
+			makeProvisionalKV("z", "txnKey2", 21),
+			makeKV("z", "val12", 4),
+		})
+		r := newTestRegistration(roachpb.Span{
+			Key:    roachpb.Key("d"),
+			EndKey: roachpb.Key("w"),
+		}, hlc.Timestamp{WallTime: 4}, iter, withDiff)
+
+		require.Zero(t, r.metrics.RangeFeedCatchupScanNanos.Count())
+		require.NoError(t, r.runCatchupScan())
+		require.True(t, iter.closed)
+		require.NotZero(t, r.metrics.RangeFeedCatchupScanNanos.Count())
+
+		// Compare the events sent on the registration's Stream to the expected
+		// events. With a diff, each value carries the next older version of its
+		// key, even if that version is below the registration's start timestamp.
+		prevValue := func(val string) roachpb.Value {
+			if !withDiff {
+				return roachpb.Value{}
+			}
+			return roachpb.Value{RawBytes: []byte(val)}
+		}
+		expEvents := []*roachpb.RangeFeedEvent{
+			rangeFeedValueWithPrev(
+				roachpb.Key("d"),
+				roachpb.Value{RawBytes: []byte("val6"), Timestamp: hlc.Timestamp{WallTime: 19}},
+				roachpb.Value{},
+			),
+			rangeFeedValueWithPrev(
+				roachpb.Key("d"),
+				roachpb.Value{RawBytes: []byte("val5"), Timestamp: hlc.Timestamp{WallTime: 20}},
+				prevValue("val6"),
+			),
+			rangeFeedValueWithPrev(
+				roachpb.Key("g"),
+				roachpb.Value{RawBytes: []byte("val7"), Timestamp: hlc.Timestamp{WallTime: 0}},
+				roachpb.Value{},
+			),
+			rangeFeedValueWithPrev(
+				roachpb.Key("m"),
+				roachpb.Value{RawBytes: []byte("val8"), Timestamp: hlc.Timestamp{WallTime: 5}},
+				prevValue("val9"),
+			),
+		}
+		require.Equal(t, expEvents, r.Events())
 	})
-	r := newTestRegistration(roachpb.Span{
-		Key:    roachpb.Key("d"),
-		EndKey: roachpb.Key("w"),
-	}, hlc.Timestamp{WallTime: 4}, iter)
-
-	require.Zero(t, r.metrics.RangeFeedCatchupScanNanos.Count())
-	require.NoError(t, r.runCatchupScan())
-	require.True(t, iter.closed)
-	require.NotZero(t, r.metrics.RangeFeedCatchupScanNanos.Count())
-
-	// Compare the events sent on the registration's Stream to the expected events.
-	expEvents := []*roachpb.RangeFeedEvent{
-		rangeFeedValue(
-			roachpb.Key("d"),
-			roachpb.Value{RawBytes: []byte("val6"), Timestamp: hlc.Timestamp{WallTime: 19}},
-		),
-		rangeFeedValue(
-			roachpb.Key("d"),
-			roachpb.Value{RawBytes: []byte("val5"), Timestamp: hlc.Timestamp{WallTime: 20}},
-		),
-		rangeFeedValue(
-			roachpb.Key("g"),
-			roachpb.Value{RawBytes: []byte("val7"), Timestamp: hlc.Timestamp{WallTime: 0}},
-		),
-	}
-	require.Equal(t, expEvents, r.Events())
 }
 
 func TestRegistryBasic(t *testing.T) {
@@ -282,10 +303,10 @@ func TestRegistryBasic(t *testing.T) {
 	require.NotPanics(t, func() { reg.Disconnect(spAB) })
 	require.NotPanics(t, func() { reg.DisconnectWithErr(spAB, err1) })
 
-	rAB := newTestRegistration(spAB, hlc.Timestamp{}, nil)
-	rBC := newTestRegistration(spBC, hlc.Timestamp{}, nil)
-	rCD := newTestRegistration(spCD, hlc.Timestamp{}, nil)
-	rAC := newTestRegistration(spAC, hlc.Timestamp{}, nil)
+	rAB := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
+	rBC := newTestRegistration(spBC, hlc.Timestamp{}, nil, false /* withDiff */)
+	rCD := newTestRegistration(spCD, hlc.Timestamp{}, nil, false /* withDiff */)
+	rAC := newTestRegistration(spAC, hlc.Timestamp{}, nil, false /* withDiff */)
 	go rAB.runOutputLoop(context.Background())
 	go rBC.runOutputLoop(context.Background())
 	go rCD.runOutputLoop(context.Background())
@@ -348,7 +369,7 @@ func TestRegistryPublishBeneathStartTimestamp(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	reg := makeRegistry()
 
-	r := newTestRegistration(spAB, hlc.Timestamp{WallTime: 10}, nil)
+	r := newTestRegistration(spAB, hlc.Timestamp{WallTime: 10}, nil, false /* withDiff */)
 	go r.runOutputLoop(context.Background())
 	reg.Register(&r.registration)
 
@@ -229,6 +229,10 @@ type Replica struct {
 	// the rest (e.g. RangeDescriptor, transaction record, Lease, ...).
 	latchMgr spanlatch.Manager
 
+	// Tracks the locks held on the range's keys and the writes and locking
+	// reads waiting for them. Only maintained on the leaseholder.
+	locks lockTable
+
 	mu struct {
 		// Protects all fields in the mu struct.
 		syncutil.RWMutex
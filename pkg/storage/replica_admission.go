@@ -0,0 +1,90 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// admitBatch waits for the batch to be admitted by the store's admission
+// queue. It must be called after the batch is done waiting for locks and
+// before it acquires latches, and the returned function must be called once
+// the batch is done evaluating. This way, admitted work only waits for the
+// latches of work which was admitted before it, so it can't deadlock with
+// work that waits for admission. The returned function may be called multiple
+// times.
+func (r *Replica) admitBatch(
+	ctx context.Context, ba *roachpb.BatchRequest,
+) (func(), *roachpb.Error) {
+	info := r.admissionWorkInfo(ba)
+	enabled, err := r.store.admissionQ.Admit(ctx, info)
+	if err != nil {
+		return nil, roachpb.NewError(err)
+	}
+	if !enabled {
+		return func() {}, nil
+	}
+	log.Event(ctx, "admitted")
+	done := false
+	return func() {
+		if !done {
+			done = true
+			r.store.admissionQ.AdmittedWorkDone(info.Priority)
+		}
+	}, nil
+}
+
+// admissionWorkInfo returns the admission WorkInfo of a batch. Bulk work,
+// such as the SST ingestions of index backfills, imports and restores, and
+// the batches of low-priority transactions have a low priority so that they
+// are throttled first when the store is overloaded.
+//
+// Work on system ranges bypasses admission, as does work which finalizes
+// transactions, resolves their intents and transfers leases, since other
+// work may be waiting for it.
+func (r *Replica) admissionWorkInfo(ba *roachpb.BatchRequest) admission.WorkInfo {
+	info := admission.WorkInfo{
+		Priority:   admission.NormalPri,
+		CreateTime: ba.Timestamp.WallTime,
+	}
+	if ba.Txn != nil {
+		// Admit older transactions first.
+		info.CreateTime = ba.Txn.OrigTimestamp.WallTime
+	}
+	switch {
+	case ba.UserPriority > roachpb.NormalUserPriority:
+		info.Priority = admission.HighPri
+	case ba.UserPriority != roachpb.UnspecifiedUserPriority && ba.UserPriority < roachpb.NormalUserPriority:
+		info.Priority = admission.LowPri
+	}
+
+	if r.Desc().StartKey.Less(roachpb.RKey(keys.UserTableDataMin)) {
+		info.BypassAdmission = true
+		return info
+	}
+	for _, union := range ba.Requests {
+		switch union.GetInner().Method() {
+		case roachpb.EndTransaction, roachpb.HeartbeatTxn, roachpb.PushTxn, roachpb.RecoverTxn,
+			roachpb.QueryTxn, roachpb.QueryIntent, roachpb.ResolveIntent, roachpb.ResolveIntentRange,
+			roachpb.RequestLease, roachpb.TransferLease, roachpb.LeaseInfo, roachpb.Subsume:
+			info.BypassAdmission = true
+			return info
+		case roachpb.AddSSTable, roachpb.Import, roachpb.Export, roachpb.RevertRange, roachpb.ClearRange:
+			info.Priority = admission.LowPri
+		}
+	}
+	return info
+}
@@ -874,6 +874,10 @@ func (sm *replicaStateMachine) ApplySideEffects(
 	}
 	if cmd.localResult != nil {
 		sm.r.handleLocalEvalResult(ctx, *cmd.localResult)
+		// Update the lock table as the command applies rather than when its
+		// proposer is signaled, which doesn't happen if the client gave up
+		// waiting or the command was proposed with async consensus.
+		sm.r.releaseLocksForBatch(cmd.proposal.Request)
 	}
 	if err := sm.maybeApplyConfChange(ctx, cmd); err != nil {
 		return nil, wrapWithNonDeterministicFailure(err, "unable to apply conf change")
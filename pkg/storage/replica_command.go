@@ -570,10 +570,9 @@ func (r *Replica) AdminMerge(
 			// Should never happen, but just in case.
 			return errors.Errorf("ranges are not adjacent; %s != %s", origLeftDesc.EndKey, rightDesc.StartKey)
 		}
-		// For simplicity, don't handle learner replicas, expect the caller to
-		// resolve them first. (Defensively, we check that there are no non-voter
-		// replicas, in case some third type is later added). This behavior can be
-		// changed later if the complexity becomes worth it, but it's not right now.
+		// For simplicity, don't handle learner or non-voting replicas, expect the
+		// caller to resolve them first. This behavior can be changed later if the
+		// complexity becomes worth it, but it's not right now.
 		lReplicas, rReplicas := origLeftDesc.Replicas(), rightDesc.Replicas()
 		if len(lReplicas.Voters()) != len(lReplicas.All()) {
 			return errors.Errorf("cannot merge range with non-voter replicas on lhs: %s", lReplicas)
@@ -920,17 +919,30 @@ func (r *Replica) ChangeReplicas(
 		if chgs[0].ChangeType == roachpb.ADD_REPLICA {
 			return r.addReplicaLegacyPreemptiveSnapshot(ctx, chgs[0].Target, desc, priority, reason, details)
 		}
+		if chgs[0].ChangeType == roachpb.ADD_NON_VOTER {
+			return nil, errors.Errorf("non-voting replicas require learner replicas to be enabled")
+		}
 		// We're removing a single voter.
 		return r.finalizeChangeReplicas(ctx, desc, priority, reason, details, chgs)
 	}
 
+	if adds := chgs.NonVoterAdditions(); len(adds) > 0 {
+		if !settings.Version.IsActive(cluster.VersionNonVotingReplicas) {
+			return nil, errors.Errorf("non-voting replicas require all nodes to be upgraded to %s",
+				cluster.VersionByKey(cluster.VersionNonVotingReplicas))
+		}
+		return r.addNonVoters(ctx, desc, priority, reason, details, adds)
+	}
+
 	if adds := chgs.Additions(); len(adds) > 0 {
 		// For all newly added nodes, first add raft learner replicas. They accept raft traffic
 		// (so they can catch up) but don't get to vote (so they don't affect quorum and thus
 		// don't introduce fragility into the system). For details see:
 		_ = roachpb.ReplicaDescriptors.Learners
 		var err error
-		desc, err = addLearnerReplicas(ctx, r.store, desc, reason, details, chgs.Additions())
+		desc, err = addLearnerReplicas(
+			ctx, r.store, desc, reason, details, chgs.Additions(), roachpb.ReplicaType_LEARNER,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -974,7 +986,7 @@ func validateReplicationChanges(
 	for _, rDesc := range desc.Replicas().All() {
 		chg, ok := byNodeID[rDesc.NodeID]
 		delete(byNodeID, rDesc.NodeID)
-		if !ok || chg.ChangeType != roachpb.ADD_REPLICA {
+		if !ok || (chg.ChangeType != roachpb.ADD_REPLICA && chg.ChangeType != roachpb.ADD_NON_VOTER) {
 			continue
 		}
 		// We're adding a replica that's already there. This isn't allowed, even
@@ -992,6 +1004,10 @@ func validateReplicationChanges(
 			return errors.Errorf(
 				"unable to add replica %v which is already present as a learner in %s", chg.Target, desc)
 		}
+		if rDesc.GetType() == roachpb.ReplicaType_NON_VOTER {
+			return errors.Errorf(
+				"unable to add replica %v which is already present as a non-voter in %s", chg.Target, desc)
+		}
 
 		// Otherwise, we already had a full voter replica. Can't add another to
 		// this store.
@@ -1008,7 +1024,8 @@ func validateReplicationChanges(
 	return nil
 }
 
-// addLearnerReplicas adds learners to the given replication targets.
+// addLearnerReplicas adds raft learners to the given replication targets. The
+// type of the added replicas is either LEARNER or NON_VOTER.
 func addLearnerReplicas(
 	ctx context.Context,
 	store *Store,
@@ -1016,6 +1033,7 @@ func addLearnerReplicas(
 	reason storagepb.RangeLogEventReason,
 	details string,
 	targets []roachpb.ReplicationTarget,
+	typ roachpb.ReplicaType,
 ) (*roachpb.RangeDescriptor, error) {
 	newDesc := *desc
 	newDesc.SetReplicas(desc.Replicas().DeepCopy())
@@ -1025,7 +1043,7 @@ func addLearnerReplicas(
 			NodeID:    target.NodeID,
 			StoreID:   target.StoreID,
 			ReplicaID: desc.NextReplicaID,
-			Type:      roachpb.ReplicaTypeLearner(),
+			Type:      typ.Enum(),
 		}
 		newDesc.NextReplicaID++
 		newDesc.AddReplica(replDesc)
@@ -1037,6 +1055,36 @@ func addLearnerReplicas(
 	return &newDesc, err
 }
 
+// addNonVoters adds non-voting replicas to the given replication targets and
+// sends them snapshots. Non-voters are raft learners which are never promoted,
+// so there's nothing left to do once they're caught up. If a snapshot fails,
+// the non-voter is left in place and the raft snapshot queue catches it up.
+func (r *Replica) addNonVoters(
+	ctx context.Context,
+	desc *roachpb.RangeDescriptor,
+	priority SnapshotRequest_Priority,
+	reason storagepb.RangeLogEventReason,
+	details string,
+	targets []roachpb.ReplicationTarget,
+) (*roachpb.RangeDescriptor, error) {
+	newDesc, err := addLearnerReplicas(
+		ctx, r.store, desc, reason, details, targets, roachpb.ReplicaType_NON_VOTER,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, target := range targets {
+		rDesc, ok := newDesc.GetReplicaDescriptor(target.StoreID)
+		if !ok {
+			return nil, errors.Errorf("programming error: replica %v not found in %v", target, newDesc)
+		}
+		if err := r.sendSnapshot(ctx, rDesc, SnapshotRequest_LEARNER, priority); err != nil {
+			return nil, err
+		}
+	}
+	return newDesc, nil
+}
+
 // finalizeChangeReplicas carries out the atomic membership change that finalizes
 // the addition and/or removal of replicas. Any voters in the process of being
 // added (as reflected by the replication changes) must have been added as
@@ -1692,14 +1740,15 @@ func updateRangeDescriptor(
 func (s *Store) AdminRelocateRange(
 	ctx context.Context, rangeDesc roachpb.RangeDescriptor, targets []roachpb.ReplicationTarget,
 ) error {
-	// Step 0: Remove all learners so we don't have to think about them. We could
-	// do something smarter here and try to promote them, but it doesn't seem
-	// worth the complexity right now. Revisit if this is an issue in practice.
+	// Step 0: Remove all learners and non-voters so we don't have to think about
+	// them. We could do something smarter here and try to promote them, but it
+	// doesn't seem worth the complexity right now. Revisit if this is an issue in
+	// practice. The replicate queue adds the non-voters back afterwards.
 	//
 	// Note that we can't just add the learners to removeTargets. The below logic
 	// always does add then remove and if the learner was in the requested
 	// targets, we might try to add it before removing it.
-	newDesc, err := removeLearners(ctx, s.DB(), &rangeDesc)
+	newDesc, err := removeNonVotingReplicas(ctx, s.DB(), &rangeDesc)
 	if err != nil {
 		log.Warning(ctx, err)
 		return err
@@ -1707,8 +1756,8 @@ func (s *Store) AdminRelocateRange(
 	rangeDesc = *newDesc
 	rangeReplicas := rangeDesc.Replicas().All()
 	if len(rangeReplicas) != len(rangeDesc.Replicas().Voters()) {
-		// We just removed all the learners, so there shouldn't be anything but
-		// voters.
+		// We just removed all the learners and non-voters, so there shouldn't be
+		// anything but voters.
 		return crdberrors.AssertionFailedf(
 			`range %s had non-voter replicas: %v`, &rangeDesc, rangeDesc.Replicas())
 	}
@@ -1861,8 +1910,8 @@ func (s *Store) AdminRelocateRange(
 			rangeDesc = *newDesc
 			rangeReplicas = rangeDesc.Replicas().All()
 			if len(rangeReplicas) != len(rangeDesc.Replicas().Voters()) {
-				// We just removed all the learners, so there shouldn't be anything but
-				// voters.
+				// We just removed all the learners and non-voters, so there shouldn't
+				// be anything but voters.
 				return crdberrors.AssertionFailedf(
 					`range %s had non-voter replicas: %v`, &rangeDesc, rangeDesc.Replicas())
 			}
@@ -1903,8 +1952,8 @@ func (s *Store) AdminRelocateRange(
 			rangeDesc = *newDesc
 			rangeReplicas = rangeDesc.Replicas().All()
 			if len(rangeReplicas) != len(rangeDesc.Replicas().Voters()) {
-				// We just removed all the learners, so there shouldn't be anything but
-				// voters.
+				// We just removed all the learners and non-voters, so there shouldn't
+				// be anything but voters.
 				return crdberrors.AssertionFailedf(
 					`range %s had non-voter replicas: %v`, &rangeDesc, rangeDesc.Replicas())
 			}
@@ -1933,23 +1982,27 @@ func removeTargetFromSlice(
 	return targets
 }
 
-func removeLearners(
+// removeNonVotingReplicas removes all learner and non-voting replicas from the
+// range. Non-voters are added back by the replicate queue.
+func removeNonVotingReplicas(
 	ctx context.Context, db *client.DB, desc *roachpb.RangeDescriptor,
 ) (*roachpb.RangeDescriptor, error) {
-	learners := desc.Replicas().Learners()
-	if len(learners) == 0 {
-		return desc, nil
+	var targets []roachpb.ReplicationTarget
+	for _, rDesc := range desc.Replicas().All() {
+		if rDesc.GetType() != roachpb.ReplicaType_VOTER {
+			targets = append(targets, roachpb.ReplicationTarget{
+				NodeID: rDesc.NodeID, StoreID: rDesc.StoreID,
+			})
+		}
 	}
-	targets := make([]roachpb.ReplicationTarget, len(learners))
-	for i := range learners {
-		targets[i].NodeID = learners[i].NodeID
-		targets[i].StoreID = learners[i].StoreID
+	if len(targets) == 0 {
+		return desc, nil
 	}
-	log.VEventf(ctx, 2, `removing learner replicas %v from %v`, targets, desc)
+	log.VEventf(ctx, 2, `removing non-voting replicas %v from %v`, targets, desc)
 	newDesc, err := db.AdminChangeReplicas(ctx, desc.StartKey, *desc,
 		roachpb.MakeReplicationChanges(roachpb.REMOVE_REPLICA, targets...))
 	if err != nil {
-		return nil, errors.Wrapf(err, `removing learners from %s`, desc)
+		return nil, errors.Wrapf(err, `removing non-voting replicas from %s`, desc)
 	}
 	return newDesc, nil
 }
@@ -166,6 +166,15 @@ func (rec SpanSetReplicaEvalContext) GetGCThreshold() hlc.Timestamp {
 	return rec.i.GetGCThreshold()
 }
 
+// GetConflictingLockedKeys returns the keys in the span that are locked by
+// other transactions with conflicting unreplicated locks.
+func (rec SpanSetReplicaEvalContext) GetConflictingLockedKeys(
+	span roachpb.Span, txn *enginepb.TxnMeta, str roachpb.KeyLockingStrength,
+) []roachpb.Key {
+	rec.ss.AssertAllowed(spanset.SpanReadOnly, span)
+	return rec.i.GetConflictingLockedKeys(span, txn, str)
+}
+
 // String implements Stringer.
 func (rec SpanSetReplicaEvalContext) String() string {
 	return rec.i.String()
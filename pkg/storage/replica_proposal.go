@@ -281,6 +281,14 @@ func (r *Replica) leasePostApply(ctx context.Context, newLease roachpb.Lease, pe
 		}
 	}
 
+	if leaseChangingHands {
+		// Unreplicated locks are only tracked by the leaseholder. Any locks
+		// held in the table from a previous lease are no longer valid, as
+		// they may have been released while this replica was not the
+		// leaseholder.
+		r.locks.clear()
+	}
+
 	if leaseChangingHands && iAmTheLeaseHolder {
 		// When taking over the lease, we need to check whether a merge is in
 		// progress, as only the old leaseholder would have been explicitly notified
@@ -456,7 +464,7 @@ func addSSTablePreApply(
 	canSkipSeqNo := st.Version.IsActive(cluster.VersionUnreplicatedRaftTruncatedState)
 
 	copied := false
-	if inmem, ok := eng.(engine.InMem); ok {
+	if inmem, ok := eng.(engine.InMemEngine); ok && inmem.IsInMem() {
 		path = fmt.Sprintf("%x", checksum)
 		if err := inmem.WriteFile(path, sst.Data); err != nil {
 			panic(err)
@@ -574,6 +582,12 @@ func (r *Replica) handleLocalEvalResult(ctx context.Context, lResult result.Loca
 		log.Fatalf(ctx, "LocalEvalResult.MaybeWatchForMerge should be false")
 	}
 
+	// Locking reads which are part of a write batch acquire their locks once
+	// the batch applies.
+	if acquired := lResult.DetachAcquiredLocks(); len(acquired) > 0 {
+		r.handleAcquiredLocks(acquired)
+	}
+
 	if lResult.GossipFirstRange {
 		// We need to run the gossip in an async task because gossiping requires
 		// the range lease and we'll deadlock if we try to acquire it while
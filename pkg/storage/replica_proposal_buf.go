@@ -458,7 +458,8 @@ func (b *propBuf) FlushLockedWithRaftGroup(raftGroup *raft.RawNode) error {
 				switch typ {
 				case roachpb.ReplicaType_VOTER:
 					changeType = raftpb.ConfChangeAddNode
-				case roachpb.ReplicaType_LEARNER:
+				case roachpb.ReplicaType_LEARNER, roachpb.ReplicaType_NON_VOTER:
+					// Non-voters are raft learners which are never promoted.
 					changeType = raftpb.ConfChangeAddLearnerNode
 				default:
 					panic(errors.Errorf("unknown replica type %v", typ))
@@ -170,6 +170,12 @@ func (r *Replica) updateProposalQuotaRaftMuLocked(
 			return
 		}
 
+		// Non-voters are often far away from the leader and don't take part in
+		// committing entries, so they aren't allowed to slow down proposals.
+		if rep.GetType() == roachpb.ReplicaType_NON_VOTER {
+			return
+		}
+
 		// Only consider followers that are active.
 		if !r.mu.lastUpdateTimes.isFollowerActive(ctx, rep.ReplicaID, now) {
 			return
@@ -0,0 +1,62 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// checkProtectedTimestampsForGC determines whether the GC queue may process
+// the replica and, if so, the timestamp against which the GC TTL of its data
+// should be measured (see engine.MakeGarbageCollector).
+//
+// The GC threshold must stay below the timestamp of every protected timestamp
+// record overlapping the range. Records written after the store's cache of
+// them was read are not known, so the TTL is measured against the time at
+// which the cache was read rather than now; see package protectedts for why
+// that is enough to honor them. If the cache hasn't been read at all, nothing
+// is known and no GC may happen.
+func (r *Replica) checkProtectedTimestampsForGC(
+	ctx context.Context, now hlc.Timestamp, policy config.GCPolicy,
+) (canGC bool, gcTimestamp hlc.Timestamp) {
+	cache := r.store.cfg.ProtectedTimestampCache
+	if cache == nil {
+		return true, now
+	}
+
+	desc := r.Desc()
+	var earliest hlc.Timestamp
+	asOf := cache.Iterate(ctx, desc.StartKey.AsRawKey(), desc.EndKey.AsRawKey(),
+		func(rec *protectedts.Record) bool {
+			if earliest == (hlc.Timestamp{}) || rec.Timestamp.Less(earliest) {
+				earliest = rec.Timestamp
+			}
+			return true
+		})
+	if asOf == (hlc.Timestamp{}) {
+		return false, hlc.Timestamp{}
+	}
+
+	gcTimestamp = now
+	gcTimestamp.Backward(asOf)
+	if earliest != (hlc.Timestamp{}) {
+		// Measuring the TTL against this timestamp puts the GC threshold just
+		// below the earliest protected timestamp.
+		ttl := time.Duration(policy.TTLSeconds) * time.Second
+		gcTimestamp.Backward(earliest.Prev().Add(ttl.Nanoseconds(), 0))
+	}
+	return true, gcTimestamp
+}
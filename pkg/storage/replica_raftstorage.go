@@ -72,6 +72,9 @@ func (r *replicaRaftStorage) InitialState() (raftpb.HardState, raftpb.ConfState,
 	for _, rep := range r.mu.state.Desc.Replicas().Learners() {
 		cs.Learners = append(cs.Learners, uint64(rep.ReplicaID))
 	}
+	for _, rep := range r.mu.state.Desc.Replicas().NonVoters() {
+		cs.Learners = append(cs.Learners, uint64(rep.ReplicaID))
+	}
 
 	return hs, cs, nil
 }
@@ -541,6 +544,9 @@ func snapshot(
 	for _, rep := range desc.Replicas().Learners() {
 		cs.Learners = append(cs.Learners, uint64(rep.ReplicaID))
 	}
+	for _, rep := range desc.Replicas().NonVoters() {
+		cs.Learners = append(cs.Learners, uint64(rep.ReplicaID))
+	}
 
 	term, err := term(ctx, rsl, snap, rangeID, eCache, appliedIndex)
 	if err != nil {
@@ -217,7 +217,7 @@ func (r *Replica) RangeFeed(
 		iterSemRelease = nil
 	}
 	p := r.registerWithRangefeedRaftMuLocked(
-		ctx, rspan, args.Timestamp, catchUpIter, lockedStream, errC,
+		ctx, rspan, args.Timestamp, catchUpIter, args.WithDiff, lockedStream, errC,
 	)
 	r.raftMu.Unlock()
 
@@ -275,6 +275,7 @@ func (r *Replica) registerWithRangefeedRaftMuLocked(
 	span roachpb.RSpan,
 	startTS hlc.Timestamp,
 	catchupIter engine.SimpleIterator,
+	withDiff bool,
 	stream rangefeed.Stream,
 	errC chan<- *roachpb.Error,
 ) *rangefeed.Processor {
@@ -284,7 +285,7 @@ func (r *Replica) registerWithRangefeedRaftMuLocked(
 	r.rangefeedMu.RLock()
 	p := r.rangefeedMu.proc
 	if p != nil {
-		reg := p.Register(span, startTS, catchupIter, stream, errC)
+		reg := p.Register(span, startTS, catchupIter, withDiff, stream, errC)
 		r.rangefeedMu.RUnlock()
 		if reg {
 			// Registered successfully with an existing processor.
@@ -331,7 +332,7 @@ func (r *Replica) registerWithRangefeedRaftMuLocked(
 	// any other goroutines are able to stop the processor. In other words,
 	// this ensures that the only time the registration fails is during
 	// server shutdown.
-	reg := p.Register(span, startTS, catchupIter, stream, errC)
+	reg := p.Register(span, startTS, catchupIter, withDiff, stream, errC)
 	if !reg {
 		catchupIter.Close() // clean up
 		select {
@@ -433,16 +434,18 @@ func (r *Replica) handleLogicalOpLogRaftMuLocked(
 	}
 
 	// When reading straight from the Raft log, some logical ops will not be
-	// fully populated. Read from the Reader to populate all fields.
+	// fully populated. Read from the Reader to populate all fields. The
+	// previous values of keys are only read if a registration wants them.
+	needsPrevValues := p.NeedsPrevValues()
 	for _, op := range ops.Ops {
 		var key []byte
 		var ts hlc.Timestamp
-		var valPtr *[]byte
+		var valPtr, prevValPtr *[]byte
 		switch t := op.GetValue().(type) {
 		case *enginepb.MVCCWriteValueOp:
-			key, ts, valPtr = t.Key, t.Timestamp, &t.Value
+			key, ts, valPtr, prevValPtr = t.Key, t.Timestamp, &t.Value, &t.PrevValue
 		case *enginepb.MVCCCommitIntentOp:
-			key, ts, valPtr = t.Key, t.Timestamp, &t.Value
+			key, ts, valPtr, prevValPtr = t.Key, t.Timestamp, &t.Value, &t.PrevValue
 		case *enginepb.MVCCWriteIntentOp,
 			*enginepb.MVCCUpdateIntentOp,
 			*enginepb.MVCCAbortIntentOp,
@@ -467,6 +470,25 @@ func (r *Replica) handleLogicalOpLogRaftMuLocked(
 			return
 		}
 		*valPtr = val.RawBytes
+
+		if !needsPrevValues {
+			continue
+		}
+		// Read the previous value of the key, which is the most recent version
+		// below the one just written. A missing value or a deletion tombstone
+		// both result in an empty previous value.
+		prevVal, _, err := engine.MVCCGet(ctx, reader, key, ts.Prev(), engine.MVCCGetOptions{
+			Tombstones: true, Inconsistent: true,
+		})
+		if err != nil {
+			r.disconnectRangefeedWithErr(p, roachpb.NewErrorf(
+				"error consuming %T for key %v @ ts %v: %v", op, key, ts, err,
+			))
+			return
+		}
+		if prevVal != nil {
+			*prevValPtr = prevVal.RawBytes
+		}
 	}
 
 	// Pass the ops to the rangefeed processor.
@@ -122,7 +122,8 @@ func TestReplicaRangefeed(t *testing.T) {
 					Timestamp: initTime,
 					RangeID:   rangeID,
 				},
-				Span: roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")},
+				Span:     roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")},
+				WithDiff: true,
 			}
 
 			pErr := mtc.Store(i).RangeFeed(&req, stream)
@@ -204,6 +205,24 @@ func TestReplicaRangefeed(t *testing.T) {
 	}...)
 	checkForExpEvents(expEvents)
 
+	// Overwrite the first key. The streams were registered with a diff, so
+	// they observe the value being overwritten.
+	mtc.manualClock.Increment(1)
+	ts4 := mtc.clock.Now()
+	pArgs = putArgs(roachpb.Key("c"), []byte("val4"))
+	_, pErr = client.SendWrappedWith(ctx, db, roachpb.Header{Timestamp: ts4}, pArgs)
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	val4 := roachpb.MakeValueFromBytesAndTimestamp([]byte("val4"), ts4)
+	expEvents = append(expEvents, &roachpb.RangeFeedEvent{
+		Val: &roachpb.RangeFeedValue{
+			Key: roachpb.Key("c"), Value: val4, PrevValue: roachpb.Value{RawBytes: val2.RawBytes},
+		},
+	})
+	checkForExpEvents(expEvents)
+
 	// Cancel each of the rangefeed streams.
 	for _, stream := range streams {
 		stream.Cancel()
@@ -702,7 +721,7 @@ func TestReplicaRangefeedNudgeSlowClosedTimestamp(t *testing.T) {
 			span := roachpb.Span{
 				Key: desc.StartKey.AsRawKey(), EndKey: desc.EndKey.AsRawKey(),
 			}
-			rangeFeedErrC <- ds.RangeFeed(rangeFeedCtx, span, ts1, rangeFeedCh)
+			rangeFeedErrC <- ds.RangeFeed(rangeFeedCtx, span, ts1, false /* withDiff */, rangeFeedCh)
 		}()
 	}
 
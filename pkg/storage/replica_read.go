@@ -47,6 +47,23 @@ func (r *Replica) executeReadOnlyBatch(
 		return nil, roachpb.NewError(err)
 	}
 
+	// Locking reads wait in the lock table for conflicting locks and for
+	// the conflicting requests that arrived before them.
+	if ba.IsLocking() {
+		lg, pErr := r.waitForLocks(ctx, ba)
+		if pErr != nil {
+			return nil, pErr
+		}
+		defer r.locks.dequeue(lg)
+	}
+
+	// Wait for admission, which lasts until the batch is done evaluating.
+	admitted, pErr := r.admitBatch(ctx, ba)
+	if pErr != nil {
+		return nil, pErr
+	}
+	defer admitted()
+
 	// Acquire latches to prevent overlapping commands from executing
 	// until this command completes.
 	log.Event(ctx, "acquire latches")
@@ -81,6 +98,12 @@ func (r *Replica) executeReadOnlyBatch(
 		return nil, roachpb.NewError(err)
 	}
 
+	// Locking reads must wait for conflicting unreplicated locks held by
+	// other transactions to be released.
+	if pErr := r.checkLockConflicts(ba); pErr != nil {
+		return nil, pErr
+	}
+
 	// Evaluate read-only batch command. It checks for matching key range; note
 	// that holding readOnlyCmdMu throughout is important to avoid reads from the
 	// "wrong" key range being served after the range has been split.
@@ -92,6 +115,9 @@ func (r *Replica) executeReadOnlyBatch(
 	}
 	defer readOnly.Close()
 	br, result, pErr = evaluateBatch(ctx, storagebase.CmdIDKey(""), readOnly, rec, nil, ba, true /* readOnly */)
+	if pErr != nil {
+		r.handleDiscoveredIntents(ba, pErr)
+	}
 
 	// A merge is (likely) about to be carried out, and this replica
 	// needs to block all traffic until the merge either commits or
@@ -102,6 +128,10 @@ func (r *Replica) executeReadOnlyBatch(
 		}
 	}
 
+	if acquired := result.Local.DetachAcquiredLocks(); len(acquired) > 0 && pErr == nil {
+		r.handleAcquiredLocks(acquired)
+	}
+
 	if intents := result.Local.DetachIntents(); len(intents) > 0 {
 		log.Eventf(ctx, "submitting %d intents to asynchronous processing", len(intents))
 		// We only allow synchronous intent resolution for consistent requests.
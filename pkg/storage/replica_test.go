@@ -11834,3 +11834,186 @@ func enableTraceDebugUseAfterFree() (restore func()) {
 	trace.DebugUseAfterFinish = true
 	return func() { trace.DebugUseAfterFinish = prev }
 }
+
+// TestReplicaLockTableDeadlock verifies that two transactions which wait in
+// the lock table for each other's write intents push each other once the
+// deadlock detection push delay has elapsed, which lets the txnWaitQueue
+// detect the dependency cycle and abort one of them.
+func TestReplicaLockTableDeadlock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	store, _ := createTestStore(t, testStoreOpts{createSystemRanges: true}, stopper)
+
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+	txn1 := beginTransaction(t, store, -1, keyA, true /* putKey */)
+	txn2 := beginTransaction(t, store, -2, keyB, true /* putKey */)
+
+	// Each transaction writes the key the other one holds an intent on.
+	put := func(txn *roachpb.Transaction, key roachpb.Key, errCh chan<- error) {
+		put := putArgs(key, []byte("value"))
+		assignSeqNumsForReqs(txn, &put)
+		_, pErr := client.SendWrappedWith(ctx, store.TestSender(), roachpb.Header{Txn: txn}, &put)
+		errCh <- pErr.GoError()
+	}
+	errCh1, errCh2 := make(chan error, 1), make(chan error, 1)
+	go put(txn1, keyB, errCh1)
+	go put(txn2, keyA, errCh2)
+
+	var aborted int
+	for _, errCh := range []chan error{errCh1, errCh2} {
+		select {
+		case err := <-errCh:
+			if err == nil {
+				continue
+			}
+			if _, ok := err.(*roachpb.UnhandledRetryableError); !ok {
+				t.Fatalf("expected transaction aborted error; got %T: %v", err, err)
+			}
+			aborted++
+		case <-time.After(testutils.DefaultSucceedsSoonDuration):
+			t.Fatal("deadlock wasn't detected")
+		}
+	}
+	if aborted != 1 {
+		t.Fatalf("expected exactly one transaction to be aborted, got %d", aborted)
+	}
+}
+
+// TestReplicaWaitForLocks verifies that writes and locking reads wait in the
+// lock table for the write intents of other transactions, and that they're
+// let through once the transaction holding the intent commits.
+func TestReplicaWaitForLocks(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	store, _ := createTestStore(t, testStoreOpts{createSystemRanges: true}, stopper)
+	// Never push the lock holder, so that the request keeps waiting in the
+	// lock table until the lock is released.
+	lockTableDeadlockDetectionPushDelay.Override(&store.ClusterSettings().SV, time.Hour)
+
+	testCases := []struct {
+		name string
+		req  func(key roachpb.Key) roachpb.Request
+	}{
+		{"write", func(key roachpb.Key) roachpb.Request {
+			put := putArgs(key, []byte("value2"))
+			return &put
+		}},
+		{"locking read", func(key roachpb.Key) roachpb.Request {
+			scan := scanArgs(key, key.PrefixEnd())
+			scan.KeyLocking = roachpb.KEY_LOCKING_EXCLUSIVE
+			return &scan
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key := roachpb.Key(tc.name)
+			txn1 := beginTransaction(t, store, 1, key, true /* putKey */)
+			txn2 := beginTransaction(t, store, 1, key, false /* putKey */)
+
+			errCh := make(chan error, 1)
+			go func() {
+				req := tc.req(key)
+				assignSeqNumsForReqs(txn2, req)
+				_, pErr := client.SendWrappedWith(ctx, store.TestSender(), roachpb.Header{Txn: txn2}, req)
+				errCh <- pErr.GoError()
+			}()
+
+			// The request queues up behind txn1's intent in the lock table.
+			repl := store.LookupReplica(roachpb.RKey(key))
+			testutils.SucceedsSoon(t, func() error {
+				var waiters int
+				repl.locks.mu.Lock()
+				repl.locks.forEachInSpanLocked(roachpb.Span{Key: key}, func(k *lockedKey) bool {
+					waiters = len(k.queue)
+					return true
+				})
+				repl.locks.mu.Unlock()
+				if waiters != 1 {
+					return errors.Errorf("expected 1 waiter on %s, found %d", key, waiters)
+				}
+				return nil
+			})
+			select {
+			case err := <-errCh:
+				t.Fatalf("request wasn't blocked by the lock: %v", err)
+			default:
+			}
+
+			// Committing txn1 releases its lock and lets the request through.
+			et, _ := endTxnArgs(txn1, true)
+			et.IntentSpans = []roachpb.Span{{Key: key}}
+			et.NoRefreshSpans = true
+			assignSeqNumsForReqs(txn1, &et)
+			if _, pErr := client.SendWrappedWith(ctx, store.TestSender(), roachpb.Header{Txn: txn1}, &et); pErr != nil {
+				t.Fatal(pErr)
+			}
+			if err := <-errCh; err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// TestReplicaLockingReadInWriteBatch verifies that the locks acquired by a
+// locking read which is part of a write batch are added to the lock table
+// once the batch applies, and that resolving the transaction's intents
+// releases them along with the intents, even when no request is waiting.
+func TestReplicaLockingReadInWriteBatch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	tc := testContext{}
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	tc.Start(t, stopper)
+
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+	pArgs := putArgs(keyB, []byte("value"))
+	if _, pErr := tc.SendWrapped(&pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	// Write "a" and lock "b" in the same batch.
+	txn := newTransaction("test", keyA, 1, tc.Clock())
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: txn}
+	put := putArgs(keyA, []byte("value"))
+	scan := scanArgs(keyB, keyB.PrefixEnd())
+	scan.KeyLocking = roachpb.KEY_LOCKING_EXCLUSIVE
+	ba.Add(&put, &scan)
+	assignSeqNumsForReqs(txn, &put, &scan)
+	if _, pErr := tc.Sender().Send(ctx, ba); pErr != nil {
+		t.Fatal(pErr)
+	}
+
+	other := &enginepb.TxnMeta{ID: uuid.MakeV4()}
+	for _, key := range []roachpb.Key{keyA, keyB} {
+		intent, ok := tc.repl.locks.findConflict(roachpb.Span{Key: key}, other, roachpb.KEY_LOCKING_SHARED)
+		if !ok {
+			t.Fatalf("expected %s to be locked", key)
+		}
+		if intent.Txn.ID != txn.ID {
+			t.Fatalf("expected %s to be locked by %s, found %s", key, txn.ID, intent.Txn.ID)
+		}
+	}
+
+	// Resolving the intents releases all of the transaction's locks.
+	rArgs := &roachpb.ResolveIntentRangeRequest{
+		RequestHeader: roachpb.RequestHeader{
+			Key:    keyA,
+			EndKey: keyB.PrefixEnd(),
+		},
+		IntentTxn: txn.TxnMeta,
+		Status:    roachpb.COMMITTED,
+	}
+	if _, pErr := tc.SendWrapped(rArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	span := roachpb.Span{Key: keyA, EndKey: keyB.PrefixEnd()}
+	if intent, ok := tc.repl.locks.findConflict(span, other, roachpb.KEY_LOCKING_EXCLUSIVE); ok {
+		t.Fatalf("unexpected lock after resolving intents: %+v", intent)
+	}
+}
@@ -80,7 +80,22 @@ func (r *Replica) executeWriteBatch(
 	}
 
 	var ec endCmds
+	admitted := func() {}
 	if !ba.IsLeaseRequest() {
+		// Wait in the lock table for conflicting locks and for the conflicting
+		// requests that arrived before this one.
+		lg, pErr := r.waitForLocks(ctx, ba)
+		if pErr != nil {
+			return nil, pErr
+		}
+		defer r.locks.dequeue(lg)
+
+		// Wait for admission, which lasts until the batch is evaluated.
+		if admitted, pErr = r.admitBatch(ctx, ba); pErr != nil {
+			return nil, pErr
+		}
+		defer admitted()
+
 		// Acquire latches to prevent overlapping commands from executing until
 		// this command completes. Note that this must be done before getting
 		// the max timestamp for the key(s), as timestamp cache is only updated
@@ -146,9 +161,16 @@ func (r *Replica) executeWriteBatch(
 
 	log.Event(ctx, "applied timestamp cache")
 
+	// Writes must wait for conflicting unreplicated locks held by other
+	// transactions to be released.
+	if pErr := r.checkLockConflicts(ba); pErr != nil {
+		return nil, pErr
+	}
+
 	// After the command is proposed to Raft, invoking endCmds.done is the
 	// responsibility of Raft, so move the endCmds into evalAndPropose.
 	ch, abandon, maxLeaseIndex, pErr := r.evalAndPropose(ctx, lease, ba, spans, ec.move())
+	admitted()
 	if pErr != nil {
 		if maxLeaseIndex != 0 {
 			log.Fatalf(
@@ -196,6 +218,9 @@ func (r *Replica) executeWriteBatch(
 					log.Warning(ctx, err)
 				}
 			}
+			if propResult.Err != nil {
+				r.handleDiscoveredIntents(ba, propResult.Err)
+			}
 			return propResult.Reply, propResult.Err
 		case <-slowTimer.C:
 			slowTimer.Read = true
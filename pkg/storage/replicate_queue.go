@@ -75,6 +75,18 @@ var (
 		Measurement: "Replica Removals",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaReplicateQueueAddNonVoterReplicaCount = metric.Metadata{
+		Name:        "queue.replicate.addnonvoterreplica",
+		Help:        "Number of non-voting replica additions attempted by the replicate queue",
+		Measurement: "Replica Additions",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaReplicateQueueRemoveNonVoterReplicaCount = metric.Metadata{
+		Name:        "queue.replicate.removenonvoterreplica",
+		Help:        "Number of non-voting replica removals attempted by the replicate queue",
+		Measurement: "Replica Removals",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaReplicateQueueRebalanceReplicaCount = metric.Metadata{
 		Name:        "queue.replicate.rebalancereplica",
 		Help:        "Number of replica rebalancer-initiated additions attempted by the replicate queue",
@@ -110,22 +122,26 @@ func (*quorumError) purgatoryErrorMarker() {}
 
 // ReplicateQueueMetrics is the set of metrics for the replicate queue.
 type ReplicateQueueMetrics struct {
-	AddReplicaCount           *metric.Counter
-	RemoveReplicaCount        *metric.Counter
-	RemoveDeadReplicaCount    *metric.Counter
-	RemoveLearnerReplicaCount *metric.Counter
-	RebalanceReplicaCount     *metric.Counter
-	TransferLeaseCount        *metric.Counter
+	AddReplicaCount            *metric.Counter
+	RemoveReplicaCount         *metric.Counter
+	RemoveDeadReplicaCount     *metric.Counter
+	RemoveLearnerReplicaCount  *metric.Counter
+	AddNonVoterReplicaCount    *metric.Counter
+	RemoveNonVoterReplicaCount *metric.Counter
+	RebalanceReplicaCount      *metric.Counter
+	TransferLeaseCount         *metric.Counter
 }
 
 func makeReplicateQueueMetrics() ReplicateQueueMetrics {
 	return ReplicateQueueMetrics{
-		AddReplicaCount:           metric.NewCounter(metaReplicateQueueAddReplicaCount),
-		RemoveReplicaCount:        metric.NewCounter(metaReplicateQueueRemoveReplicaCount),
-		RemoveDeadReplicaCount:    metric.NewCounter(metaReplicateQueueRemoveDeadReplicaCount),
-		RemoveLearnerReplicaCount: metric.NewCounter(metaReplicateQueueRemoveLearnerReplicaCount),
-		RebalanceReplicaCount:     metric.NewCounter(metaReplicateQueueRebalanceReplicaCount),
-		TransferLeaseCount:        metric.NewCounter(metaReplicateQueueTransferLeaseCount),
+		AddReplicaCount:            metric.NewCounter(metaReplicateQueueAddReplicaCount),
+		RemoveReplicaCount:         metric.NewCounter(metaReplicateQueueRemoveReplicaCount),
+		RemoveDeadReplicaCount:     metric.NewCounter(metaReplicateQueueRemoveDeadReplicaCount),
+		RemoveLearnerReplicaCount:  metric.NewCounter(metaReplicateQueueRemoveLearnerReplicaCount),
+		AddNonVoterReplicaCount:    metric.NewCounter(metaReplicateQueueAddNonVoterReplicaCount),
+		RemoveNonVoterReplicaCount: metric.NewCounter(metaReplicateQueueRemoveNonVoterReplicaCount),
+		RebalanceReplicaCount:      metric.NewCounter(metaReplicateQueueRebalanceReplicaCount),
+		TransferLeaseCount:         metric.NewCounter(metaReplicateQueueTransferLeaseCount),
 	}
 }
 
@@ -207,6 +223,8 @@ func (rq *replicateQueue) shouldQueue(
 		return true, priority
 	}
 	voterReplicas := desc.Replicas().Voters()
+	// The remaining decisions only concern the voters.
+	zone = zone.VoterConfig()
 
 	if action == AllocatorNoop {
 		log.VEventf(ctx, 2, "no action to take")
@@ -335,6 +353,10 @@ func (rq *replicateQueue) processOneChange(
 		return rq.removeDead(ctx, repl, deadVoterReplicas, dryRun)
 	case AllocatorRemoveLearner:
 		return rq.removeLearner(ctx, repl, dryRun)
+	case AllocatorAddNonVoter:
+		return rq.addNonVoter(ctx, repl, dryRun)
+	case AllocatorRemoveNonVoter:
+		return rq.removeNonVoter(ctx, repl, dryRun)
 	case AllocatorConsiderRebalance:
 		return rq.considerRebalance(ctx, repl, voterReplicas, canTransferLease, dryRun)
 	}
@@ -345,11 +367,14 @@ func (rq *replicateQueue) add(
 	ctx context.Context, repl *Replica, existingReplicas []roachpb.ReplicaDescriptor, dryRun bool,
 ) (requeue bool, _ error) {
 	desc, zone := repl.DescAndZone()
-	newStore, details, err := rq.allocator.AllocateTarget(
+	zone = zone.VoterConfig()
+	nonVoterReplicas := desc.Replicas().NonVoters()
+	newStore, details, err := rq.allocator.AllocateVoterTarget(
 		ctx,
 		zone,
 		desc.RangeID,
 		existingReplicas,
+		nonVoterReplicas,
 	)
 	if err != nil {
 		return false, err
@@ -381,11 +406,12 @@ func (rq *replicateQueue) add(
 			NodeID:  newStore.Node.NodeID,
 			StoreID: newStore.StoreID,
 		})
-		_, _, err := rq.allocator.AllocateTarget(
+		_, _, err := rq.allocator.AllocateVoterTarget(
 			ctx,
 			zone,
 			desc.RangeID,
 			oldPlusNewReplicas,
+			nonVoterReplicas,
 		)
 		if err != nil {
 			// It does not seem possible to go to the next odd replica state. Note
@@ -416,6 +442,7 @@ func (rq *replicateQueue) remove(
 	ctx context.Context, repl *Replica, existingReplicas []roachpb.ReplicaDescriptor, dryRun bool,
 ) (requeue bool, _ error) {
 	desc, zone := repl.DescAndZone()
+	zone = zone.VoterConfig()
 	// This retry loop involves quick operations on local state, so a
 	// small MaxBackoff is good (but those local variables change on
 	// network time scales as raft receives responses).
@@ -526,8 +553,9 @@ func (rq *replicateQueue) removeDecommissioning(
 	ctx context.Context, repl *Replica, dryRun bool,
 ) (requeue bool, _ error) {
 	desc, zone := repl.DescAndZone()
+	zone = zone.VoterConfig()
 	decommissioningReplicas := rq.allocator.storePool.decommissioningReplicas(
-		desc.RangeID, desc.Replicas().All())
+		desc.RangeID, desc.Replicas().Voters())
 	if len(decommissioningReplicas) == 0 {
 		log.VEventf(ctx, 1, "range of replica %s was identified as having decommissioning replicas, "+
 			"but no decommissioning replicas were found", repl)
@@ -620,6 +648,85 @@ func (rq *replicateQueue) removeLearner(
 	return true, nil
 }
 
+func (rq *replicateQueue) addNonVoter(
+	ctx context.Context, repl *Replica, dryRun bool,
+) (requeue bool, _ error) {
+	desc, zone := repl.DescAndZone()
+	// Non-voters are placed according to the constraints of the zone as a whole
+	// and are diversified against all of the replicas of the range.
+	newStore, details, err := rq.allocator.AllocateTarget(
+		ctx,
+		zone,
+		desc.RangeID,
+		desc.Replicas().All(),
+	)
+	if err != nil {
+		return false, err
+	}
+	newReplica := roachpb.ReplicationTarget{
+		NodeID:  newStore.Node.NodeID,
+		StoreID: newStore.StoreID,
+	}
+	rq.metrics.AddNonVoterReplicaCount.Inc(1)
+	log.VEventf(ctx, 1, "adding non-voting replica %+v due to under-replication", newReplica)
+	if dryRun {
+		return false, nil
+	}
+	chgs := roachpb.MakeReplicationChanges(roachpb.ADD_NON_VOTER, newReplica)
+	if _, err := repl.ChangeReplicas(
+		ctx, desc, SnapshotRequest_RECOVERY, storagepb.ReasonRangeUnderReplicated, details, chgs,
+	); err != nil {
+		return false, err
+	}
+	rangeUsageInfo := rangeUsageInfoForRepl(repl)
+	rq.allocator.storePool.updateLocalStoreAfterRebalance(
+		newReplica.StoreID, rangeUsageInfo, roachpb.ADD_REPLICA)
+	return true, nil
+}
+
+func (rq *replicateQueue) removeNonVoter(
+	ctx context.Context, repl *Replica, dryRun bool,
+) (requeue bool, _ error) {
+	desc, zone := repl.DescAndZone()
+	nonVoterReplicas := desc.Replicas().NonVoters()
+	if len(nonVoterReplicas) == 0 {
+		log.VEventf(ctx, 1, "range of replica %s was identified as having non-voting replicas "+
+			"to remove, but no non-voting replicas were found", repl)
+		return true, nil
+	}
+
+	// Dead and decommissioning non-voters are removed first. If there are none,
+	// the range has too many non-voters.
+	var removeReplica roachpb.ReplicaDescriptor
+	var reason storagepb.RangeLogEventReason
+	var details string
+	if _, dead := rq.allocator.storePool.liveAndDeadReplicas(
+		desc.RangeID, nonVoterReplicas); len(dead) > 0 {
+		removeReplica, reason = dead[0], storagepb.ReasonStoreDead
+	} else if decommissioning := rq.allocator.storePool.decommissioningReplicas(
+		desc.RangeID, nonVoterReplicas); len(decommissioning) > 0 {
+		removeReplica, reason = decommissioning[0], storagepb.ReasonStoreDecommissioning
+	} else {
+		var err error
+		removeReplica, details, err = rq.allocator.RemoveTarget(
+			ctx, zone, nonVoterReplicas, desc.Replicas().All())
+		if err != nil {
+			return false, err
+		}
+		reason = storagepb.ReasonRangeOverReplicated
+	}
+	rq.metrics.RemoveNonVoterReplicaCount.Inc(1)
+	log.VEventf(ctx, 1, "removing non-voting replica %+v (%s)", removeReplica, reason)
+	target := roachpb.ReplicationTarget{
+		NodeID:  removeReplica.NodeID,
+		StoreID: removeReplica.StoreID,
+	}
+	if err := rq.removeReplica(ctx, repl, target, desc, reason, details, dryRun); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (rq *replicateQueue) considerRebalance(
 	ctx context.Context,
 	repl *Replica,
@@ -628,6 +735,7 @@ func (rq *replicateQueue) considerRebalance(
 	dryRun bool,
 ) (requeue bool, _ error) {
 	desc, zone := repl.DescAndZone()
+	zone = zone.VoterConfig()
 	// The Noop case will result if this replica was queued in order to
 	// rebalance. Attempt to find a rebalancing target.
 	if !rq.store.TestingKnobs().DisableReplicaRebalancing {
@@ -637,6 +745,10 @@ func (rq *replicateQueue) considerRebalance(
 			storeFilterThrottled)
 		if rebalanceStore == nil {
 			log.VEventf(ctx, 1, "no suitable rebalance target")
+		} else if nodeHasReplica(rebalanceStore.Node.NodeID, desc.Replicas().NonVoters()) {
+			// The target's node already holds a non-voting replica of the range.
+			// The replicate queue doesn't promote non-voters, so don't rebalance.
+			log.VEventf(ctx, 1, "rebalance target s%d holds a non-voting replica", rebalanceStore.StoreID)
 		} else {
 			rebalanceReplica := roachpb.ReplicationTarget{
 				NodeID:  rebalanceStore.Node.NodeID,
@@ -43,11 +43,13 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/idalloc"
 	"github.com/cockroachdb/cockroach/pkg/storage/intentresolver"
+	"github.com/cockroachdb/cockroach/pkg/storage/protectedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/raftentry"
 	"github.com/cockroachdb/cockroach/pkg/storage/stateloader"
 	"github.com/cockroachdb/cockroach/pkg/storage/tscache"
 	"github.com/cockroachdb/cockroach/pkg/storage/txnrecovery"
 	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
+	"github.com/cockroachdb/cockroach/pkg/util/admission"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -406,6 +408,7 @@ type Store struct {
 	recoveryMgr        txnrecovery.Manager
 	raftEntryCache     *raftentry.Cache
 	limiters           batcheval.Limiters
+	admissionQ         *admission.WorkQueue // Admission control of KV work
 	txnWaitMetrics     *txnwait.Metrics
 	sss                SSTSnapshotStorage
 
@@ -636,6 +639,10 @@ type StoreConfig struct {
 	// SQLExecutor is used by the store to execute SQL statements.
 	SQLExecutor sqlutil.InternalExecutor
 
+	// ProtectedTimestampCache is consulted by the GC queue to avoid collecting
+	// history which is protected. If nil, no history is protected.
+	ProtectedTimestampCache protectedts.Cache
+
 	// TimeSeriesDataStore is an interface used by the store's time series
 	// maintenance queue to dispatch individual maintenance tasks.
 	TimeSeriesDataStore TimeSeriesDataStore
@@ -832,6 +839,9 @@ func NewStore(
 	s.txnWaitMetrics = txnwait.NewMetrics(cfg.HistogramWindowInterval)
 	s.metrics.registry.AddMetricStruct(s.txnWaitMetrics)
 
+	s.admissionQ = admission.NewWorkQueue(cfg.Settings, cfg.HistogramWindowInterval)
+	s.metrics.registry.AddMetricStruct(s.admissionQ.Metrics())
+
 	s.compactor = compactor.NewCompactor(
 		s.cfg.Settings,
 		s.engine.(engine.WithSSTables),
@@ -1447,6 +1457,8 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 		s.startLeaseRenewer(ctx)
 	}
 
+	s.startAdmissionLSMStatsLoop(ctx)
+
 	// Connect rangefeeds to closed timestamp updates.
 	s.startClosedTimestampRangefeedSubscriber(ctx)
 
@@ -1615,6 +1627,38 @@ func (s *Store) startLeaseRenewer(ctx context.Context) {
 	})
 }
 
+// admissionLSMStatsInterval is how often the store informs its admission
+// queue about the health of its LSM.
+const admissionLSMStatsInterval = time.Second
+
+// startAdmissionLSMStatsLoop starts a goroutine that periodically informs the
+// admission queue about the L0 file count and the read amplification of the
+// store's engine, which the queue uses to throttle low-priority work when the
+// LSM is overloaded.
+func (s *Store) startAdmissionLSMStatsLoop(ctx context.Context) {
+	s.stopper.RunWorker(ctx, func(ctx context.Context) {
+		ticker := time.NewTicker(admissionLSMStatsInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				stats, err := s.engine.GetStats()
+				if err != nil {
+					log.Warningf(ctx, "failed to read engine stats for admission control: %s", err)
+					continue
+				}
+				var readAmp int
+				if eng, ok := s.engine.(engine.WithSSTables); ok {
+					readAmp = eng.GetSSTables().ReadAmplification()
+				}
+				s.admissionQ.SetLSMStats(stats.L0FileCount, int64(readAmp))
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
 // startClosedTimestampRangefeedSubscriber establishes a new ClosedTimestamp
 // subscription and runs an infinite loop to listen for closed timestamp updates
 // and inform Replicas with active Rangefeeds about them.
@@ -2236,6 +2280,12 @@ func splitPostApply(
 		log.Fatalf(ctx, "%s: failed to update Store after split: %+v", r, err)
 	}
 
+	// The lock table of the LHS tracks locks on keys that now belong to the
+	// RHS. Rather than moving them over, drop them all: the table is only an
+	// optimization, and the locks that are write intents are rediscovered by
+	// the requests that run into them.
+	r.locks.clear()
+
 	// Update store stats with difference in stats before and after split.
 	r.store.metrics.addMVCCStats(deltaMS)
 
@@ -2406,6 +2456,11 @@ func (s *Store) MergeRange(
 	// left-hand replica, if necessary.
 	rightRepl.txnWaitQueue.Clear(true /* disable */)
 
+	// Likewise, drop the locks tracked by both sides of the merge, which lets
+	// the requests waiting in the RHS's lock table proceed and be redirected.
+	leftRepl.locks.clear()
+	rightRepl.locks.clear()
+
 	leftLease, _ := leftRepl.GetLease()
 	rightLease, _ := rightRepl.GetLease()
 	if leftLease.OwnedBy(s.Ident.StoreID) && !rightLease.OwnedBy(s.Ident.StoreID) {
@@ -3074,7 +3129,7 @@ func (s *Store) Send(
 			// this is the code path with the requesting client waiting.
 			if pErr.Index != nil {
 				var pushType roachpb.PushTxnType
-				if ba.IsWrite() {
+				if ba.IsWrite() || ba.IsLocking() {
 					pushType = roachpb.PUSH_ABORT
 				} else {
 					pushType = roachpb.PUSH_TIMESTAMP
@@ -3082,6 +3137,12 @@ func (s *Store) Send(
 
 				index := pErr.Index
 				args := ba.Requests[index.Index].GetInner()
+				if _, waitPolicy := lockingOpts(args); waitPolicy == roachpb.LOCK_WAIT_ERROR {
+					// Locking reads with a NOWAIT wait policy don't wait for
+					// conflicting intents or locks; the conflict is returned to
+					// the client instead.
+					return nil, pErr
+				}
 				// Make a copy of the header for the upcoming push; we will update
 				// the timestamp.
 				h := ba.Header
@@ -544,6 +544,21 @@ func (sl StoreList) String() string {
 	return buf.String()
 }
 
+// excludeNodes returns the store list without the stores on the nodes of the
+// given replicas. It maintains the original order of the passed in store list.
+func (sl StoreList) excludeNodes(replicas []roachpb.ReplicaDescriptor) StoreList {
+	if len(replicas) == 0 {
+		return sl
+	}
+	var filteredDescs []roachpb.StoreDescriptor
+	for _, store := range sl.stores {
+		if !nodeHasReplica(store.Node.NodeID, replicas) {
+			filteredDescs = append(filteredDescs, store)
+		}
+	}
+	return makeStoreList(filteredDescs)
+}
+
 // filter takes a store list and filters it using the passed in constraints. It
 // maintains the original order of the passed in store list.
 func (sl StoreList) filter(constraints []config.Constraints) StoreList {
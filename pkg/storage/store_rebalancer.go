@@ -494,11 +494,14 @@ func (sr *StoreRebalancer) chooseReplicaToRebalance(
 		log.VEventf(ctx, 3, "considering replica rebalance for r%d with %.2f qps",
 			desc.RangeID, replWithStats.qps)
 
+		// Only the voters are rebalanced. RelocateRange removes any non-voting
+		// replicas, which the replicate queue adds back afterwards.
+		zone = zone.VoterConfig()
 		clusterNodes := sr.rq.allocator.storePool.ClusterNodeCount()
 		desiredReplicas := GetNeededReplicas(*zone.NumReplicas, clusterNodes)
 		targets := make([]roachpb.ReplicationTarget, 0, desiredReplicas)
 		targetReplicas := make([]roachpb.ReplicaDescriptor, 0, desiredReplicas)
-		currentReplicas := desc.Replicas().All()
+		currentReplicas := desc.Replicas().Voters()
 
 		// Check the range's existing diversity score, since we want to ensure we
 		// don't hurt locality diversity just to improve QPS.
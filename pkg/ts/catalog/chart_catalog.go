@@ -762,6 +762,47 @@ var charts = []sectionDescription{
 			},
 		},
 	},
+	{
+		Organization: [][]string{{KVTransactionLayer, "Admission Control"}},
+		Charts: []chartDescription{
+			{
+				Title: "Work Items",
+				Metrics: []string{
+					"admission.kv.requested",
+					"admission.kv.admitted",
+					"admission.kv.errored",
+				},
+				AxisLabel: "Work Items",
+			},
+			{
+				Title: "Wait Time",
+				Metrics: []string{
+					"admission.kv.wait_durations",
+				},
+				AxisLabel: "Wait Time",
+			},
+			{
+				Title: "Waiting",
+				Metrics: []string{
+					"admission.kv.wait_queue_length",
+				},
+				AxisLabel: "Work Items",
+			},
+			{
+				Title: "Used Slots",
+				Metrics: []string{
+					"admission.kv.used_slots",
+				},
+				AxisLabel: "Slots",
+			},
+			{
+				Title: "LSM Overloaded",
+				Metrics: []string{
+					"admission.kv.lsm_overloaded",
+				},
+			},
+		},
+	},
 	{
 		Organization: [][]string{{KVTransactionLayer, "Transactions"}},
 		Charts: []chartDescription{
@@ -1268,8 +1309,11 @@ var charts = []sectionDescription{
 		Organization: [][]string{{ReplicationLayer, "Replicate Queue"}},
 		Charts: []chartDescription{
 			{
-				Title:   "Add Replica Count",
-				Metrics: []string{"queue.replicate.addreplica"},
+				Title: "Add Replica Count",
+				Metrics: []string{
+					"queue.replicate.addreplica",
+					"queue.replicate.addnonvoterreplica",
+				},
 			},
 			{
 				Title:   "Lease Transfer Count",
@@ -1293,6 +1337,7 @@ var charts = []sectionDescription{
 					"queue.replicate.removedeadreplica",
 					"queue.replicate.removereplica",
 					"queue.replicate.removelearnerreplica",
+					"queue.replicate.removenonvoterreplica",
 				},
 			},
 			{
@@ -52,6 +52,12 @@ export function getEventDescription(e: Event$Properties): string {
       return `Sequence Altered: User ${info.User} altered sequence ${info.SequenceName}`;
     case eventTypes.DROP_SEQUENCE:
       return `Sequence Dropped: User ${info.User} dropped sequence ${info.SequenceName}`;
+    case eventTypes.CREATE_TYPE:
+      return `Type Created: User ${info.User} created type ${info.TypeName}`;
+    case eventTypes.ALTER_TYPE:
+      return `Type Altered: User ${info.User} altered type ${info.TypeName}`;
+    case eventTypes.DROP_TYPE:
+      return `Type Dropped: User ${info.User} dropped type ${info.TypeName}`;
     case eventTypes.REVERSE_SCHEMA_CHANGE:
       return `Schema Change Reversed: Schema change with ID ${info.MutationID} was reversed.`;
     case eventTypes.FINISH_SCHEMA_CHANGE:
@@ -92,6 +98,7 @@ export interface EventInfo {
   MutationID?: string;
   ViewName?: string;
   SequenceName?: string;
+  TypeName?: string;
   SettingName?: string;
   Value?: string;
   Target?: string;
@@ -0,0 +1,98 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+)
+
+// Metrics contains the metrics of a WorkQueue.
+type Metrics struct {
+	Requested       *metric.Counter
+	Admitted        *metric.Counter
+	Errored         *metric.Counter
+	WaitDurations   *metric.Histogram
+	WaitQueueLength *metric.Gauge
+	UsedSlots       *metric.Gauge
+	LSMOverloaded   *metric.Gauge
+}
+
+func makeMetrics(histogramWindowInterval time.Duration) *Metrics {
+	return &Metrics{
+		Requested: metric.NewCounter(
+			metric.Metadata{
+				Name:        "admission.kv.requested",
+				Help:        "Number of KV work items that requested admission",
+				Measurement: "Work Items",
+				Unit:        metric.Unit_COUNT,
+			},
+		),
+
+		Admitted: metric.NewCounter(
+			metric.Metadata{
+				Name:        "admission.kv.admitted",
+				Help:        "Number of KV work items that were admitted",
+				Measurement: "Work Items",
+				Unit:        metric.Unit_COUNT,
+			},
+		),
+
+		Errored: metric.NewCounter(
+			metric.Metadata{
+				Name:        "admission.kv.errored",
+				Help:        "Number of KV work items whose context was canceled while waiting for admission",
+				Measurement: "Work Items",
+				Unit:        metric.Unit_COUNT,
+			},
+		),
+
+		WaitDurations: metric.NewHistogram(
+			metric.Metadata{
+				Name:        "admission.kv.wait_durations",
+				Help:        "Histogram of durations spent waiting for admission by KV work items that had to wait",
+				Measurement: "Wait time",
+				Unit:        metric.Unit_NANOSECONDS,
+			},
+			histogramWindowInterval,
+			time.Minute.Nanoseconds(),
+			1,
+		),
+
+		WaitQueueLength: metric.NewGauge(
+			metric.Metadata{
+				Name:        "admission.kv.wait_queue_length",
+				Help:        "Number of KV work items waiting for admission",
+				Measurement: "Work Items",
+				Unit:        metric.Unit_COUNT,
+			},
+		),
+
+		UsedSlots: metric.NewGauge(
+			metric.Metadata{
+				Name:        "admission.kv.used_slots",
+				Help:        "Number of admitted KV work items that are executing",
+				Measurement: "Slots",
+				Unit:        metric.Unit_COUNT,
+			},
+		),
+
+		LSMOverloaded: metric.NewGauge(
+			metric.Metadata{
+				Name:        "admission.kv.lsm_overloaded",
+				Help:        "Whether low-priority KV work is being throttled because the LSM is overloaded (1) or not (0)",
+				Measurement: "Overloaded",
+				Unit:        metric.Unit_COUNT,
+			},
+		),
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package admission implements admission control for the work executed by a
+// store. Work waits in a WorkQueue, ordered by priority and then by the time
+// at which it was created, until it's granted one of the store's CPU slots.
+// When the store's LSM is unhealthy, as indicated by its L0 file count and
+// read amplification, low-priority work such as bulk ingestion is throttled
+// further so that compactions can catch up without affecting foreground
+// traffic.
+package admission
+
+import (
+	"container/heap"
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+var admissionControlEnabled = settings.RegisterBoolSetting(
+	"kv.admission.enabled",
+	"when true, KV work waits for admission in a queue ordered by priority when the store is overloaded",
+	true,
+)
+
+var slotsPerCPU = settings.RegisterPositiveIntSetting(
+	"kv.admission.slots_per_cpu",
+	"the number of KV work items that may execute concurrently on a store, per CPU",
+	32,
+)
+
+var l0FileCountOverloadThreshold = settings.RegisterPositiveIntSetting(
+	"kv.admission.l0_file_count_overload_threshold",
+	"the number of L0 files in a store's LSM above which low-priority KV work is throttled",
+	20,
+)
+
+var readAmpOverloadThreshold = settings.RegisterPositiveIntSetting(
+	"kv.admission.read_amplification_overload_threshold",
+	"the read amplification of a store's LSM above which low-priority KV work is throttled",
+	30,
+)
+
+// maxLowPriWhenOverloaded is the number of low-priority work items which may
+// execute concurrently while the LSM is overloaded. Low-priority work isn't
+// stopped entirely, so that it doesn't time out.
+const maxLowPriWhenOverloaded = 1
+
+// WorkPriority is the priority of work waiting for admission. Work with a
+// higher priority is always admitted before work with a lower priority.
+type WorkPriority int8
+
+const (
+	// LowPri is the priority of background work, such as bulk ingestion and
+	// low-priority transactions. It's throttled when the LSM is unhealthy.
+	LowPri WorkPriority = -10
+	// NormalPri is the priority of regular foreground work.
+	NormalPri WorkPriority = 0
+	// HighPri is the priority of high-priority transactions.
+	HighPri WorkPriority = 10
+)
+
+// WorkInfo describes work waiting for admission.
+type WorkInfo struct {
+	// Priority is the priority of the work.
+	Priority WorkPriority
+	// CreateTime orders work of the same priority, in nanoseconds. Work which
+	// was created earlier is admitted first.
+	CreateTime int64
+	// BypassAdmission is set for work which must not wait for admission,
+	// usually because other admitted work may be waiting for it to complete.
+	BypassAdmission bool
+}
+
+// waitingWork is work waiting in a WorkQueue.
+type waitingWork struct {
+	priority   WorkPriority
+	createTime int64
+	// Receives once the work is granted admission.
+	ch      chan struct{}
+	granted bool
+	// The index of the work in the waitingWorkHeap.
+	index int
+}
+
+// waitingWorkHeap implements heap.Interface, ordering work by descending
+// priority and then by ascending create time.
+type waitingWorkHeap []*waitingWork
+
+var _ heap.Interface = (*waitingWorkHeap)(nil)
+
+func (h waitingWorkHeap) Len() int { return len(h) }
+
+func (h waitingWorkHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].createTime < h[j].createTime
+}
+
+func (h waitingWorkHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *waitingWorkHeap) Push(x interface{}) {
+	w := x.(*waitingWork)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waitingWorkHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return w
+}
+
+// WorkQueue admits the KV work executed by a store. Work is admitted when one
+// of the store's CPU slots is free, of which there are
+// kv.admission.slots_per_cpu per CPU, and holds the slot until it's done.
+// While the store's LSM is overloaded, at most maxLowPriWhenOverloaded
+// low-priority work items execute concurrently. Waiting work is admitted in
+// order of priority and then of creation.
+type WorkQueue struct {
+	settings *cluster.Settings
+	metrics  *Metrics
+
+	mu struct {
+		syncutil.Mutex
+		// The number of admitted work items which aren't done, in total and of
+		// low priority.
+		used, usedLowPri int
+		// Whether the LSM is overloaded, as of the last call to SetLSMStats.
+		overloaded bool
+		waiting    waitingWorkHeap
+	}
+}
+
+// NewWorkQueue creates a WorkQueue.
+func NewWorkQueue(st *cluster.Settings, histogramWindowInterval time.Duration) *WorkQueue {
+	return &WorkQueue{
+		settings: st,
+		metrics:  makeMetrics(histogramWindowInterval),
+	}
+}
+
+// Metrics returns the metrics of the queue.
+func (q *WorkQueue) Metrics() *Metrics {
+	return q.metrics
+}
+
+// Admit blocks until the work is admitted or the context is canceled. If
+// enabled is returned true, the work was admitted and the caller must call
+// AdmittedWorkDone once the work is done. Otherwise, the work either bypassed
+// admission or, if err is set, wasn't admitted.
+func (q *WorkQueue) Admit(ctx context.Context, info WorkInfo) (enabled bool, err error) {
+	if info.BypassAdmission || !admissionControlEnabled.Get(&q.settings.SV) {
+		return false, nil
+	}
+	q.metrics.Requested.Inc(1)
+
+	q.mu.Lock()
+	if len(q.mu.waiting) == 0 && q.canGrantLocked(info.Priority) {
+		q.grantLocked(info.Priority)
+		q.mu.Unlock()
+		q.metrics.Admitted.Inc(1)
+		return true, nil
+	}
+	w := &waitingWork{
+		priority:   info.Priority,
+		createTime: info.CreateTime,
+		ch:         make(chan struct{}, 1),
+	}
+	heap.Push(&q.mu.waiting, w)
+	// The work may be admitted right away if it has a higher priority than
+	// the work that was already waiting.
+	q.tryGrantLocked()
+	q.mu.Unlock()
+
+	start := timeutil.Now()
+	select {
+	case <-w.ch:
+		q.metrics.WaitDurations.RecordValue(timeutil.Since(start).Nanoseconds())
+		q.metrics.Admitted.Inc(1)
+		return true, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		if w.granted {
+			// The work was admitted concurrently with the cancellation.
+			q.workDoneLocked(w.priority)
+		} else {
+			heap.Remove(&q.mu.waiting, w.index)
+			q.metrics.WaitQueueLength.Update(int64(len(q.mu.waiting)))
+		}
+		q.mu.Unlock()
+		q.metrics.Errored.Inc(1)
+		return false, errors.Wrap(ctx.Err(), "context canceled while waiting for admission")
+	}
+}
+
+// AdmittedWorkDone is called once work admitted with the provided priority is
+// done, releasing its slot.
+func (q *WorkQueue) AdmittedWorkDone(priority WorkPriority) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.workDoneLocked(priority)
+}
+
+// SetLSMStats updates the health of the store's LSM from the number of files
+// in its L0 and its read amplification.
+func (q *WorkQueue) SetLSMStats(l0FileCount, readAmp int64) {
+	overloaded := l0FileCount > l0FileCountOverloadThreshold.Get(&q.settings.SV) ||
+		readAmp > readAmpOverloadThreshold.Get(&q.settings.SV)
+	if overloaded {
+		q.metrics.LSMOverloaded.Update(1)
+	} else {
+		q.metrics.LSMOverloaded.Update(0)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.mu.overloaded = overloaded
+	q.tryGrantLocked()
+}
+
+// slots returns the number of work items which may execute concurrently.
+func (q *WorkQueue) slots() int {
+	return int(slotsPerCPU.Get(&q.settings.SV)) * runtime.GOMAXPROCS(0)
+}
+
+// canGrantLocked returns whether work of the provided priority can be
+// admitted. q.mu must be held.
+func (q *WorkQueue) canGrantLocked(priority WorkPriority) bool {
+	if q.mu.used >= q.slots() {
+		return false
+	}
+	if priority <= LowPri && q.mu.overloaded && q.mu.usedLowPri >= maxLowPriWhenOverloaded {
+		return false
+	}
+	return true
+}
+
+// grantLocked accounts for the admission of work of the provided priority.
+// q.mu must be held.
+func (q *WorkQueue) grantLocked(priority WorkPriority) {
+	q.mu.used++
+	if priority <= LowPri {
+		q.mu.usedLowPri++
+	}
+	q.metrics.UsedSlots.Update(int64(q.mu.used))
+}
+
+// workDoneLocked releases the slot of work of the provided priority, and
+// admits waiting work if possible. q.mu must be held.
+func (q *WorkQueue) workDoneLocked(priority WorkPriority) {
+	q.mu.used--
+	if priority <= LowPri {
+		q.mu.usedLowPri--
+	}
+	q.metrics.UsedSlots.Update(int64(q.mu.used))
+	q.tryGrantLocked()
+}
+
+// tryGrantLocked admits waiting work, in order, for as long as possible.
+// q.mu must be held.
+func (q *WorkQueue) tryGrantLocked() {
+	for len(q.mu.waiting) > 0 {
+		w := q.mu.waiting[0]
+		if !q.canGrantLocked(w.priority) {
+			// All other waiting work has the same or a lower priority.
+			break
+		}
+		heap.Pop(&q.mu.waiting)
+		q.grantLocked(w.priority)
+		w.granted = true
+		w.ch <- struct{}{}
+	}
+	q.metrics.WaitQueueLength.Update(int64(len(q.mu.waiting)))
+}
@@ -0,0 +1,136 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package admission
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// admitAsync starts admitting work with the provided info, returning a
+// channel that receives the result.
+func admitAsync(q *WorkQueue, info WorkInfo) chan error {
+	ch := make(chan error, 1)
+	go func() {
+		enabled, err := q.Admit(context.Background(), info)
+		if err == nil && !enabled {
+			panic("admission unexpectedly disabled")
+		}
+		ch <- err
+	}()
+	return ch
+}
+
+// waitForQueueLength waits until n work items are waiting in q.
+func waitForQueueLength(t *testing.T, q *WorkQueue, n int) {
+	t.Helper()
+	for start := time.Now(); ; {
+		q.mu.Lock()
+		l := len(q.mu.waiting)
+		q.mu.Unlock()
+		if l == n {
+			return
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatalf("expected %d waiting work items, found %d", n, l)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// expectAdmitted checks that the work whose result is sent on ch was admitted
+// and that none of the work of the others was.
+func expectAdmitted(t *testing.T, ch chan error, others ...chan error) {
+	t.Helper()
+	if err := <-ch; err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range others {
+		select {
+		case err := <-o:
+			t.Fatalf("unexpected admission: %v", err)
+		default:
+		}
+	}
+}
+
+func TestWorkQueue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	slotsPerCPU.Override(&st.SV, 4)
+	q := NewWorkQueue(st, time.Minute)
+	slots := 4 * runtime.GOMAXPROCS(0)
+
+	// Work which bypasses admission isn't tracked.
+	if enabled, err := q.Admit(ctx, WorkInfo{BypassAdmission: true}); err != nil || enabled {
+		t.Fatalf("unexpected admission result %t, %v", enabled, err)
+	}
+
+	// Use up all of the slots.
+	for i := 0; i < slots; i++ {
+		if enabled, err := q.Admit(ctx, WorkInfo{Priority: NormalPri}); err != nil || !enabled {
+			t.Fatalf("unexpected admission result %t, %v", enabled, err)
+		}
+	}
+
+	// Waiting work is admitted in order of priority and then of creation.
+	low := admitAsync(q, WorkInfo{Priority: LowPri, CreateTime: 1})
+	waitForQueueLength(t, q, 1)
+	normal2 := admitAsync(q, WorkInfo{Priority: NormalPri, CreateTime: 2})
+	waitForQueueLength(t, q, 2)
+	normal1 := admitAsync(q, WorkInfo{Priority: NormalPri, CreateTime: 1})
+	waitForQueueLength(t, q, 3)
+	high := admitAsync(q, WorkInfo{Priority: HighPri, CreateTime: 3})
+	waitForQueueLength(t, q, 4)
+
+	q.AdmittedWorkDone(NormalPri)
+	expectAdmitted(t, high, low, normal1, normal2)
+	q.AdmittedWorkDone(HighPri)
+	expectAdmitted(t, normal1, low, normal2)
+	q.AdmittedWorkDone(NormalPri)
+	expectAdmitted(t, normal2, low)
+	q.AdmittedWorkDone(NormalPri)
+	expectAdmitted(t, low)
+
+	// Canceled work stops waiting.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if enabled, err := q.Admit(cancelCtx, WorkInfo{Priority: HighPri}); err == nil || enabled {
+		t.Fatalf("unexpected admission result %t, %v", enabled, err)
+	}
+	waitForQueueLength(t, q, 0)
+
+	// While the LSM is overloaded, low-priority work is throttled even though
+	// slots are available.
+	q.AdmittedWorkDone(LowPri)
+	q.AdmittedWorkDone(NormalPri)
+	q.SetLSMStats(l0FileCountOverloadThreshold.Get(&st.SV)+1, 0)
+	if enabled, err := q.Admit(ctx, WorkInfo{Priority: LowPri}); err != nil || !enabled {
+		t.Fatalf("unexpected admission result %t, %v", enabled, err)
+	}
+	low = admitAsync(q, WorkInfo{Priority: LowPri})
+	waitForQueueLength(t, q, 1)
+	if enabled, err := q.Admit(ctx, WorkInfo{Priority: NormalPri}); err != nil || !enabled {
+		t.Fatalf("unexpected admission result %t, %v", enabled, err)
+	}
+	q.AdmittedWorkDone(NormalPri)
+
+	// The throttled work is admitted once the LSM is healthy again.
+	q.SetLSMStats(0, 0)
+	expectAdmitted(t, low)
+}
@@ -161,6 +161,9 @@ type Reader struct {
 	// numLine is the current line being read in the CSV file.
 	numLine int
 
+	// offset is the number of bytes of the input consumed so far.
+	offset int64
+
 	// rawBuffer is a line buffer only used by the readLine method.
 	rawBuffer []byte
 
@@ -222,6 +225,13 @@ func (r *Reader) ReadAll() (records [][]string, err error) {
 	}
 }
 
+// InputOffset returns the input stream byte offset of the end of the most
+// recently read record. Resuming reading from that offset, with a new Reader,
+// returns the records that follow it.
+func (r *Reader) InputOffset() int64 {
+	return r.offset
+}
+
 // readLine reads the next line (with the trailing endline).
 // If EOF is hit without a trailing endline, it will be omitted.
 // If some bytes were read, then the error is never io.EOF.
@@ -236,6 +246,7 @@ func (r *Reader) readLine() ([]byte, error) {
 		}
 		line = r.rawBuffer
 	}
+	r.offset += int64(len(line))
 	if len(line) > 0 && err == io.EOF {
 		err = nil
 		// For backwards compatibility, drop trailing \r before EOF.
@@ -422,6 +422,39 @@ x,,,
 	}
 }
 
+func TestInputOffset(t *testing.T) {
+	const input = "a,b\r\n# comment\n\n\"c\nd\",e\nf,g"
+	r := NewReader(strings.NewReader(input))
+	r.Comment = '#'
+	var offsets []int64
+	for {
+		_, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		offsets = append(offsets, r.InputOffset())
+	}
+	if expected := []int64{5, 24, int64(len(input))}; !reflect.DeepEqual(offsets, expected) {
+		t.Fatalf("expected offsets %v, got %v", expected, offsets)
+	}
+
+	// Reading from any of the offsets returns the records that follow it.
+	for i, off := range offsets {
+		r := NewReader(strings.NewReader(input[off:]))
+		r.Comment = '#'
+		out, err := r.ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(out) != len(offsets)-i-1 {
+			t.Fatalf("expected %d records after offset %d, got %q", len(offsets)-i-1, off, out)
+		}
+	}
+}
+
 // nTimes is an io.Reader which yields the string s n times.
 type nTimes struct {
 	s   string
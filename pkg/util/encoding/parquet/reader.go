@@ -0,0 +1,308 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+)
+
+// File is the decoded contents of a parquet file.
+type File struct {
+	Columns     []Column
+	Compression Compression
+	CreatedBy   string
+	NumRows     int64
+	// Rows holds a value for each column of each row, as accepted by
+	// Writer.AddRow, except that ByteArray values are always []byte.
+	Rows [][]interface{}
+}
+
+// ReadFile decodes the contents of a parquet file written by Writer. It's
+// meant for tests and only supports the subset of the format that Writer
+// uses.
+func ReadFile(contents []byte) (*File, error) {
+	if len(contents) < 2*len(magic)+4 ||
+		!bytes.HasPrefix(contents, []byte(magic)) || !bytes.HasSuffix(contents, []byte(magic)) {
+		return nil, errors.New(`not a parquet file`)
+	}
+	end := len(contents) - len(magic) - 4
+	footerLen := int(binary.LittleEndian.Uint32(contents[end:]))
+	if footerLen > end-len(magic) {
+		return nil, errors.Errorf(`invalid footer length %d`, footerLen)
+	}
+	r := thriftCompactReader{buf: contents[end-footerLen : end]}
+	m, err := r.readStruct()
+	if err != nil {
+		return nil, errors.Wrap(err, `decoding file metadata`)
+	}
+
+	f := &File{}
+	f.NumRows, _ = m[3].(int64)
+	createdBy, _ := m[6].([]byte)
+	f.CreatedBy = string(createdBy)
+	schema, _ := m[2].([]interface{})
+	for i := 1; i < len(schema); {
+		e, _ := schema[i].(map[int16]interface{})
+		if e[6] == int64(convertedTypeList) {
+			if i+2 >= len(schema) {
+				return nil, errors.Errorf(`truncated schema of list column %s`, e[4])
+			}
+			leaf, _ := schema[i+2].(map[int16]interface{})
+			c := leafColumn(leaf)
+			c.Name = string(e[4].([]byte))
+			c.Required = e[3] == int64(repetitionRequired)
+			c.List = true
+			f.Columns = append(f.Columns, c)
+			i += 3
+			continue
+		}
+		c := leafColumn(e)
+		c.Required = e[3] == int64(repetitionRequired)
+		f.Columns = append(f.Columns, c)
+		i++
+	}
+
+	rowGroups, _ := m[4].([]interface{})
+	if len(rowGroups) != 1 {
+		return nil, errors.Errorf(`expected 1 row group, got %d`, len(rowGroups))
+	}
+	chunks, _ := rowGroups[0].(map[int16]interface{})[1].([]interface{})
+	if len(chunks) != len(f.Columns) {
+		return nil, errors.Errorf(`expected %d column chunks, got %d`, len(f.Columns), len(chunks))
+	}
+	f.Rows = make([][]interface{}, f.NumRows)
+	for i := range f.Rows {
+		f.Rows[i] = make([]interface{}, len(f.Columns))
+	}
+	for i := range chunks {
+		meta, _ := chunks[i].(map[int16]interface{})[3].(map[int16]interface{})
+		codec, _ := meta[4].(int64)
+		f.Compression = Compression(codec)
+		offset, _ := meta[9].(int64)
+		values, err := readColumnChunk(contents, int(offset), &f.Columns[i], f.Compression)
+		if err != nil {
+			return nil, errors.Wrapf(err, `reading column %s`, f.Columns[i].Name)
+		}
+		if len(values) != len(f.Rows) {
+			return nil, errors.Errorf(`expected %d values in column %s, got %d`,
+				len(f.Rows), f.Columns[i].Name, len(values))
+		}
+		for j := range values {
+			f.Rows[j][i] = values[j]
+		}
+	}
+	return f, nil
+}
+
+// leafColumn returns the column described by a leaf schema element, without
+// its name, repetition or list-ness.
+func leafColumn(e map[int16]interface{}) Column {
+	name, _ := e[4].([]byte)
+	typ, _ := e[1].(int64)
+	c := Column{Name: string(name), Type: Type(typ)}
+	if convertedType, ok := e[6].(int64); ok {
+		for a, t := range convertedTypes {
+			if int64(t) == convertedType {
+				c.Annotation = a
+			}
+		}
+	}
+	if c.Annotation == Decimal {
+		scale, _ := e[7].(int64)
+		precision, _ := e[8].(int64)
+		c.Scale, c.Precision = int32(scale), int32(precision)
+	}
+	return c
+}
+
+// readColumnChunk decodes the single data page of the column chunk starting at
+// the given offset and returns the value of each row.
+func readColumnChunk(contents []byte, offset int, c *Column, codec Compression) ([]interface{}, error) {
+	if offset < 0 || offset >= len(contents) {
+		return nil, errors.Errorf(`invalid column chunk offset %d`, offset)
+	}
+	r := thriftCompactReader{buf: contents[offset:]}
+	header, err := r.readStruct()
+	if err != nil {
+		return nil, errors.Wrap(err, `decoding page header`)
+	}
+	compressedSize, _ := header[3].(int64)
+	dataHeader, _ := header[5].(map[int16]interface{})
+	numLevels, _ := dataHeader[1].(int64)
+	if compressedSize < 0 || r.pos+int(compressedSize) > len(r.buf) {
+		return nil, errors.Errorf(`invalid compressed page size %d`, compressedSize)
+	}
+	page, err := decompress(codec, r.buf[r.pos:r.pos+int(compressedSize)])
+	if err != nil {
+		return nil, err
+	}
+
+	maxDef, maxRep := c.maxLevels()
+	repLevels := make([]uint8, numLevels)
+	defLevels := make([]uint8, numLevels)
+	if maxRep > 0 {
+		if page, err = readLevels(page, repLevels); err != nil {
+			return nil, err
+		}
+	}
+	if maxDef > 0 {
+		if page, err = readLevels(page, defLevels); err != nil {
+			return nil, err
+		}
+	}
+	var numValues int
+	for _, def := range defLevels {
+		if def == maxDef {
+			numValues++
+		}
+	}
+	values, err := decodePlain(page, c, numValues)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []interface{}
+	for i, def := range defLevels {
+		if !c.List {
+			if def == maxDef {
+				rows = append(rows, values[0])
+				values = values[1:]
+			} else {
+				rows = append(rows, nil)
+			}
+			continue
+		}
+		if repLevels[i] == 0 {
+			if def < maxDef-2 {
+				// The list is NULL.
+				rows = append(rows, nil)
+				continue
+			}
+			rows = append(rows, []interface{}{})
+			if def == maxDef-2 {
+				// The list is empty.
+				continue
+			}
+		}
+		list := rows[len(rows)-1].([]interface{})
+		if def == maxDef {
+			list = append(list, values[0])
+			values = values[1:]
+		} else {
+			list = append(list, nil)
+		}
+		rows[len(rows)-1] = list
+	}
+	return rows, nil
+}
+
+func decompress(codec Compression, compressed []byte) ([]byte, error) {
+	switch codec {
+	case Uncompressed:
+		return compressed, nil
+	case Snappy:
+		return snappy.Decode(nil, compressed)
+	case Gzip:
+		gz, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(gz)
+	default:
+		return nil, errors.Errorf(`unknown parquet compression codec %d`, codec)
+	}
+}
+
+// readLevels decodes levels written by appendLevels and returns the rest of
+// the page.
+func readLevels(page []byte, levels []uint8) ([]byte, error) {
+	if len(page) < 4 {
+		return nil, errors.New(`truncated levels`)
+	}
+	length := int(binary.LittleEndian.Uint32(page))
+	page = page[4:]
+	if length > len(page) {
+		return nil, errors.Errorf(`invalid levels length %d`, length)
+	}
+	encoded := page[:length]
+	for i := 0; i < len(levels); {
+		header, n := binary.Uvarint(encoded)
+		if n <= 0 || n >= len(encoded) {
+			return nil, errors.New(`truncated run of levels`)
+		}
+		if header&1 != 0 {
+			return nil, errors.New(`bit-packed levels are not supported`)
+		}
+		count := int(header >> 1)
+		if i+count > len(levels) {
+			return nil, errors.New(`too many levels`)
+		}
+		for j := 0; j < count; j++ {
+			levels[i+j] = encoded[n]
+		}
+		i += count
+		encoded = encoded[n+1:]
+	}
+	return page[length:], nil
+}
+
+// decodePlain decodes n PLAIN encoded values of the column.
+func decodePlain(buf []byte, c *Column, n int) ([]interface{}, error) {
+	values := make([]interface{}, n)
+	if c.Type == Boolean {
+		if len(buf) < (n+7)/8 {
+			return nil, errors.New(`truncated values`)
+		}
+		for i := range values {
+			values[i] = buf[i/8]&(1<<uint(i%8)) != 0
+		}
+		return values, nil
+	}
+	for i := range values {
+		var size int
+		switch c.Type {
+		case Int32:
+			size = 4
+		case Int64, Double:
+			size = 8
+		case ByteArray:
+			if len(buf) < 4 {
+				return nil, errors.New(`truncated values`)
+			}
+			size = int(binary.LittleEndian.Uint32(buf))
+			buf = buf[4:]
+		default:
+			return nil, errors.Errorf(`unknown parquet type %d of column %s`, c.Type, c.Name)
+		}
+		if len(buf) < size {
+			return nil, errors.New(`truncated values`)
+		}
+		switch c.Type {
+		case Int32:
+			values[i] = int32(binary.LittleEndian.Uint32(buf))
+		case Int64:
+			values[i] = int64(binary.LittleEndian.Uint64(buf))
+		case Double:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf))
+		case ByteArray:
+			values[i] = append([]byte(nil), buf[:size]...)
+		}
+		buf = buf[size:]
+	}
+	return values, nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// The thrift compact protocol type ids that we use.
+const (
+	thriftTypeI32    = 5
+	thriftTypeI64    = 6
+	thriftTypeBinary = 8
+	thriftTypeList   = 9
+	thriftTypeStruct = 12
+)
+
+// thriftCompactWriter serializes structs using the thrift compact protocol,
+// which parquet uses for its metadata. Only the subset of the protocol needed
+// by Writer is implemented.
+//
+// https://github.com/apache/thrift/blob/master/doc/specs/thrift-compact-protocol.md
+type thriftCompactWriter struct {
+	buf         bytes.Buffer
+	lastFieldID int16
+	stack       []int16
+}
+
+// beginStruct starts a struct, either the top-level one or an element of a
+// list. Nested struct fields use structField instead.
+func (w *thriftCompactWriter) beginStruct() {
+	w.stack = append(w.stack, w.lastFieldID)
+	w.lastFieldID = 0
+}
+
+func (w *thriftCompactWriter) endStruct() {
+	w.buf.WriteByte(0)
+	w.lastFieldID = w.stack[len(w.stack)-1]
+	w.stack = w.stack[:len(w.stack)-1]
+}
+
+func (w *thriftCompactWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - w.lastFieldID; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.writeVarint(int64(id))
+	}
+	w.lastFieldID = id
+}
+
+func (w *thriftCompactWriter) structField(id int16) {
+	w.fieldHeader(id, thriftTypeStruct)
+	w.beginStruct()
+}
+
+func (w *thriftCompactWriter) i32Field(id int16, v int32) {
+	w.fieldHeader(id, thriftTypeI32)
+	w.writeVarint(int64(v))
+}
+
+func (w *thriftCompactWriter) i64Field(id int16, v int64) {
+	w.fieldHeader(id, thriftTypeI64)
+	w.writeVarint(v)
+}
+
+func (w *thriftCompactWriter) binaryField(id int16, v string) {
+	w.fieldHeader(id, thriftTypeBinary)
+	w.writeBinary(v)
+}
+
+// listField writes the header of a list field. It must be followed by exactly
+// size elements of the given type.
+func (w *thriftCompactWriter) listField(id int16, elemType byte, size int) {
+	w.fieldHeader(id, thriftTypeList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		w.buf.WriteByte(0xf0 | elemType)
+		w.writeUvarint(uint64(size))
+	}
+}
+
+// writeVarint writes a zigzag encoded varint, which is how the compact
+// protocol represents all integers.
+func (w *thriftCompactWriter) writeVarint(v int64) {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(scratch[:], v)
+	w.buf.Write(scratch[:n])
+}
+
+func (w *thriftCompactWriter) writeUvarint(v uint64) {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(scratch[:], v)
+	w.buf.Write(scratch[:n])
+}
+
+func (w *thriftCompactWriter) writeBinary(v string) {
+	w.writeUvarint(uint64(len(v)))
+	w.buf.WriteString(v)
+}
+
+// thriftCompactReader deserializes structs written by thriftCompactWriter.
+// Structs are decoded into maps from field ids to values, lists into slices,
+// integers into int64s and binaries into []byte.
+type thriftCompactReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftCompactReader) readStruct() (map[int16]interface{}, error) {
+	fields := make(map[int16]interface{})
+	var lastFieldID int16
+	for {
+		b, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0 {
+			return fields, nil
+		}
+		id := lastFieldID + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := r.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		lastFieldID = id
+		if fields[id], err = r.readValue(b & 0x0f); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (r *thriftCompactReader) readValue(typ byte) (interface{}, error) {
+	switch typ {
+	case thriftTypeI32, thriftTypeI64:
+		return r.readVarint()
+	case thriftTypeBinary:
+		n, err := r.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(r.buf)-r.pos) {
+			return nil, errors.New(`truncated binary`)
+		}
+		v := r.buf[r.pos : r.pos+int(n)]
+		r.pos += int(n)
+		return v, nil
+	case thriftTypeList:
+		b, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(b >> 4)
+		if size == 15 {
+			if size, err = r.readUvarint(); err != nil {
+				return nil, err
+			}
+		}
+		if size > uint64(len(r.buf)-r.pos) {
+			return nil, errors.New(`truncated list`)
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			if list[i], err = r.readValue(b & 0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case thriftTypeStruct:
+		return r.readStruct()
+	default:
+		return nil, errors.Errorf(`unsupported thrift type %d`, typ)
+	}
+}
+
+func (r *thriftCompactReader) readByte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errors.New(`unexpected end of thrift struct`)
+	}
+	r.pos++
+	return r.buf[r.pos-1], nil
+}
+
+func (r *thriftCompactReader) readVarint() (int64, error) {
+	v, n := binary.Varint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errors.New(`invalid varint`)
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftCompactReader) readUvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errors.New(`invalid varint`)
+	}
+	r.pos += n
+	return v, nil
+}
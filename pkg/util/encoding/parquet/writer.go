@@ -0,0 +1,518 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package parquet implements a minimal writer of Apache Parquet files. Every
+// file has a flat schema of columns, which may be lists of values, a single
+// row group, and a single PLAIN encoded data page per column.
+//
+// https://github.com/apache/parquet-format
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math"
+
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+)
+
+const magic = `PAR1`
+
+// Type is the physical type of the values of a column. The values are those
+// of the Type enum in parquet.thrift.
+type Type int32
+
+// The physical types supported by Writer.
+const (
+	Boolean   Type = 0
+	Int32     Type = 1
+	Int64     Type = 2
+	Double    Type = 5
+	ByteArray Type = 6
+)
+
+// Annotation describes how the values of a column are interpreted, on top of
+// their physical type.
+type Annotation int
+
+const (
+	// NoAnnotation means the values are interpreted as their physical type.
+	NoAnnotation Annotation = iota
+	// UTF8 annotates ByteArray columns holding strings.
+	UTF8
+	// JSON annotates ByteArray columns holding JSON documents.
+	JSON
+	// Decimal annotates ByteArray columns holding the unscaled value of a
+	// decimal as a big-endian two's complement integer. The precision and
+	// scale of the decimal are those of the column.
+	Decimal
+	// Date annotates Int32 columns holding the number of days since the Unix
+	// epoch.
+	Date
+	// TimeMicros annotates Int64 columns holding the number of microseconds
+	// since midnight.
+	TimeMicros
+	// TimestampMicros annotates Int64 columns holding the number of
+	// microseconds since the Unix epoch, in UTC.
+	TimestampMicros
+)
+
+// convertedTypes maps annotations to the values of the ConvertedType enum in
+// parquet.thrift.
+var convertedTypes = map[Annotation]int32{
+	UTF8:            0,
+	Decimal:         5,
+	Date:            6,
+	TimeMicros:      8,
+	TimestampMicros: 10,
+	JSON:            19,
+}
+
+// Compression is a codec that the pages of a file are compressed with. The
+// values are those of the CompressionCodec enum in parquet.thrift.
+type Compression int32
+
+// The codecs supported by Writer.
+const (
+	Uncompressed Compression = 0
+	Snappy       Compression = 1
+	Gzip         Compression = 2
+)
+
+// The values of the other enums in parquet.thrift that we use.
+const (
+	repetitionRequired = 0
+	repetitionOptional = 1
+	repetitionRepeated = 2
+
+	convertedTypeList = 3
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	pageTypeData = 0
+)
+
+// Column describes a column of a parquet file.
+type Column struct {
+	Name       string
+	Type       Type
+	Annotation Annotation
+	// Precision and Scale are those of Decimal columns.
+	Precision, Scale int32
+	// Required columns can't have NULL values. Columns are optional otherwise.
+	Required bool
+	// List columns hold a list of values, any of which can be NULL, rather than
+	// a single value. They are written as the standard three-level LIST
+	// structure, so that e.g. column `a` has a leaf `a.list.element`.
+	List bool
+}
+
+// maxLevels returns the maximum definition and repetition levels of the
+// values of the column.
+func (c *Column) maxLevels() (def, rep uint8) {
+	if !c.Required {
+		def++
+	}
+	if c.List {
+		// The repeated group and its optional element each add a level.
+		def += 2
+		rep++
+	}
+	return def, rep
+}
+
+type columnChunk struct {
+	defLevels []uint8
+	repLevels []uint8
+	bools     []bool
+	values    bytes.Buffer
+}
+
+// Writer accumulates rows and writes them out as a parquet file.
+type Writer struct {
+	columns     []Column
+	compression Compression
+	createdBy   string
+	chunks      []columnChunk
+	numRows     int64
+	size        int
+	scratch     [][]byte
+}
+
+// NewWriter returns a Writer of files with the given columns. The createdBy
+// string is recorded in the metadata of the files.
+func NewWriter(columns []Column, compression Compression, createdBy string) *Writer {
+	return &Writer{
+		columns:     columns,
+		compression: compression,
+		createdBy:   createdBy,
+		chunks:      make([]columnChunk, len(columns)),
+	}
+}
+
+// NumRows returns the number of rows added.
+func (w *Writer) NumRows() int64 {
+	return w.numRows
+}
+
+// Size returns the total size of the values added, before compression.
+func (w *Writer) Size() int {
+	return w.size
+}
+
+// AddRow adds a row with a value for each column. A nil value is NULL.
+// Otherwise, the value of a column must match its physical type: bool for
+// Boolean, int32 for Int32, int64 for Int64, float64 for Double, and []byte or
+// string for ByteArray. The value of a List column is a []interface{} of such
+// values. A row with an invalid value isn't added.
+func (w *Writer) AddRow(row []interface{}) error {
+	if len(row) != len(w.columns) {
+		return errors.Errorf(`expected %d values, got %d`, len(w.columns), len(row))
+	}
+	// Encode the values before appending any of them, so that invalid rows are
+	// rejected as a whole.
+	w.scratch = w.scratch[:0]
+	for i := range w.columns {
+		c := &w.columns[i]
+		v := row[i]
+		if v == nil {
+			if c.Required {
+				return errors.Errorf(`null value in required column %s`, c.Name)
+			}
+			w.scratch = append(w.scratch, nil)
+			continue
+		}
+		if !c.List {
+			enc, err := encodePlain(nil, c, v)
+			if err != nil {
+				return err
+			}
+			w.scratch = append(w.scratch, enc)
+			continue
+		}
+		list, ok := v.([]interface{})
+		if !ok {
+			return errors.Errorf(`unexpected value %T for list column %s`, v, c.Name)
+		}
+		var enc []byte
+		for _, elem := range list {
+			if elem == nil {
+				continue
+			}
+			var err error
+			if enc, err = encodePlain(enc, c, elem); err != nil {
+				return err
+			}
+		}
+		w.scratch = append(w.scratch, enc)
+	}
+
+	for i := range w.columns {
+		c, chunk := &w.columns[i], &w.chunks[i]
+		maxDef, _ := c.maxLevels()
+		if row[i] == nil {
+			chunk.defLevels = append(chunk.defLevels, 0)
+			chunk.repLevels = append(chunk.repLevels, 0)
+			continue
+		}
+		if !c.List {
+			chunk.defLevels = append(chunk.defLevels, maxDef)
+			chunk.repLevels = append(chunk.repLevels, 0)
+		} else if list := row[i].([]interface{}); len(list) == 0 {
+			// The list is defined, but has no elements.
+			chunk.defLevels = append(chunk.defLevels, maxDef-2)
+			chunk.repLevels = append(chunk.repLevels, 0)
+		} else {
+			for j, elem := range list {
+				def, rep := maxDef, uint8(1)
+				if elem == nil {
+					def--
+				}
+				if j == 0 {
+					rep = 0
+				}
+				chunk.defLevels = append(chunk.defLevels, def)
+				chunk.repLevels = append(chunk.repLevels, rep)
+			}
+		}
+		if c.Type == Boolean {
+			// Booleans are bit-packed when the page is written.
+			for _, b := range w.scratch[i] {
+				chunk.bools = append(chunk.bools, b == 1)
+			}
+		} else {
+			chunk.values.Write(w.scratch[i])
+		}
+		w.size += len(w.scratch[i])
+	}
+	w.numRows++
+	return nil
+}
+
+// encodePlain appends the PLAIN encoding of a value, except for booleans,
+// which are appended as a whole byte.
+func encodePlain(buf []byte, c *Column, v interface{}) ([]byte, error) {
+	var scratch [8]byte
+	switch c.Type {
+	case Boolean:
+		if b, ok := v.(bool); ok {
+			if b {
+				return append(buf, 1), nil
+			}
+			return append(buf, 0), nil
+		}
+	case Int32:
+		if i, ok := v.(int32); ok {
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(i))
+			return append(buf, scratch[:4]...), nil
+		}
+	case Int64:
+		if i, ok := v.(int64); ok {
+			binary.LittleEndian.PutUint64(scratch[:], uint64(i))
+			return append(buf, scratch[:]...), nil
+		}
+	case Double:
+		if f, ok := v.(float64); ok {
+			binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(f))
+			return append(buf, scratch[:]...), nil
+		}
+	case ByteArray:
+		switch s := v.(type) {
+		case []byte:
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(len(s)))
+			return append(append(buf, scratch[:4]...), s...), nil
+		case string:
+			binary.LittleEndian.PutUint32(scratch[:4], uint32(len(s)))
+			return append(append(buf, scratch[:4]...), s...), nil
+		}
+	default:
+		return nil, errors.Errorf(`unknown parquet type %d of column %s`, c.Type, c.Name)
+	}
+	return nil, errors.Errorf(`unexpected value %T for column %s`, v, c.Name)
+}
+
+// Finish returns the contents of the parquet file holding the rows added.
+func (w *Writer) Finish() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+
+	offsets := make([]int64, len(w.chunks))
+	uncompressedSizes := make([]int64, len(w.chunks))
+	compressedSizes := make([]int64, len(w.chunks))
+	for i := range w.chunks {
+		page := w.chunks[i].page(&w.columns[i])
+		compressed, err := compress(w.compression, page)
+		if err != nil {
+			return nil, err
+		}
+		var header thriftCompactWriter
+		header.beginStruct()
+		header.i32Field(1, pageTypeData)
+		header.i32Field(2, int32(len(page)))
+		header.i32Field(3, int32(len(compressed)))
+		header.structField(5)
+		header.i32Field(1, int32(len(w.chunks[i].defLevels)))
+		header.i32Field(2, encodingPlain)
+		header.i32Field(3, encodingRLE)
+		header.i32Field(4, encodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		offsets[i] = int64(buf.Len())
+		uncompressedSizes[i] = int64(header.buf.Len() + len(page))
+		compressedSizes[i] = int64(header.buf.Len() + len(compressed))
+		buf.Write(header.buf.Bytes())
+		buf.Write(compressed)
+	}
+
+	footer := w.fileMetaData(offsets, uncompressedSizes, compressedSizes)
+	buf.Write(footer)
+	var footerLen [4]byte
+	binary.LittleEndian.PutUint32(footerLen[:], uint32(len(footer)))
+	buf.Write(footerLen[:])
+	buf.WriteString(magic)
+	return buf.Bytes(), nil
+}
+
+func compress(codec Compression, page []byte) ([]byte, error) {
+	switch codec {
+	case Uncompressed:
+		return page, nil
+	case Snappy:
+		return snappy.Encode(nil, page), nil
+	case Gzip:
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(page); err != nil {
+			return nil, err
+		}
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, errors.Errorf(`unknown parquet compression codec %d`, codec)
+	}
+}
+
+// fileMetaData returns the serialized FileMetaData struct of the file, given
+// the offset and sizes of each column chunk.
+func (w *Writer) fileMetaData(offsets, uncompressedSizes, compressedSizes []int64) []byte {
+	var m thriftCompactWriter
+	m.beginStruct()
+	m.i32Field(1, 1 /* version */)
+
+	numElements := 1
+	for i := range w.columns {
+		numElements++
+		if w.columns[i].List {
+			numElements += 2
+		}
+	}
+	m.listField(2, thriftTypeStruct, numElements)
+	m.beginStruct()
+	m.binaryField(4, `schema`)
+	m.i32Field(5, int32(len(w.columns)))
+	m.endStruct()
+	for i := range w.columns {
+		c := &w.columns[i]
+		repetition := int32(repetitionOptional)
+		if c.Required {
+			repetition = repetitionRequired
+		}
+		if !c.List {
+			writeLeafSchemaElement(&m, c, c.Name, repetition)
+			continue
+		}
+		m.beginStruct()
+		m.i32Field(3, repetition)
+		m.binaryField(4, c.Name)
+		m.i32Field(5, 1)
+		m.i32Field(6, convertedTypeList)
+		m.endStruct()
+		m.beginStruct()
+		m.i32Field(3, repetitionRepeated)
+		m.binaryField(4, `list`)
+		m.i32Field(5, 1)
+		m.endStruct()
+		writeLeafSchemaElement(&m, c, `element`, repetitionOptional)
+	}
+
+	m.i64Field(3, w.numRows)
+
+	m.listField(4, thriftTypeStruct, 1)
+	m.beginStruct()
+	m.listField(1, thriftTypeStruct, len(w.columns))
+	var totalSize int64
+	for i := range w.columns {
+		c := &w.columns[i]
+		totalSize += uncompressedSizes[i]
+		m.beginStruct()
+		m.i64Field(2, offsets[i])
+		m.structField(3)
+		m.i32Field(1, int32(c.Type))
+		m.listField(2, thriftTypeI32, 2)
+		m.writeVarint(encodingPlain)
+		m.writeVarint(encodingRLE)
+		if c.List {
+			m.listField(3, thriftTypeBinary, 3)
+			m.writeBinary(c.Name)
+			m.writeBinary(`list`)
+			m.writeBinary(`element`)
+		} else {
+			m.listField(3, thriftTypeBinary, 1)
+			m.writeBinary(c.Name)
+		}
+		m.i32Field(4, int32(w.compression))
+		m.i64Field(5, int64(len(w.chunks[i].defLevels)))
+		m.i64Field(6, uncompressedSizes[i])
+		m.i64Field(7, compressedSizes[i])
+		m.i64Field(9, offsets[i])
+		m.endStruct()
+		m.endStruct()
+	}
+	m.i64Field(2, totalSize)
+	m.i64Field(3, w.numRows)
+	m.endStruct()
+
+	m.binaryField(6, w.createdBy)
+	m.endStruct()
+	return m.buf.Bytes()
+}
+
+func writeLeafSchemaElement(m *thriftCompactWriter, c *Column, name string, repetition int32) {
+	m.beginStruct()
+	m.i32Field(1, int32(c.Type))
+	m.i32Field(3, repetition)
+	m.binaryField(4, name)
+	if convertedType, ok := convertedTypes[c.Annotation]; ok {
+		m.i32Field(6, convertedType)
+	}
+	if c.Annotation == Decimal {
+		m.i32Field(7, c.Scale)
+		m.i32Field(8, c.Precision)
+	}
+	m.endStruct()
+}
+
+// page returns the uncompressed data page of the column chunk: the repetition
+// and definition levels, for the levels that can be non-zero, followed by the
+// non-null values.
+func (c *columnChunk) page(col *Column) []byte {
+	var page []byte
+	maxDef, maxRep := col.maxLevels()
+	if maxRep > 0 {
+		page = appendLevels(page, c.repLevels)
+	}
+	if maxDef > 0 {
+		page = appendLevels(page, c.defLevels)
+	}
+	if col.Type == Boolean {
+		// Booleans are bit-packed, least significant bit first.
+		packed := make([]byte, (len(c.bools)+7)/8)
+		for i, b := range c.bools {
+			if b {
+				packed[i/8] |= 1 << uint(i%8)
+			}
+		}
+		return append(page, packed...)
+	}
+	return append(page, c.values.Bytes()...)
+}
+
+// appendLevels appends levels, preceded by the length of their encoding.
+func appendLevels(buf []byte, levels []uint8) []byte {
+	encoded := appendRLE(nil, levels)
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(encoded)))
+	buf = append(buf, length[:]...)
+	return append(buf, encoded...)
+}
+
+// appendRLE encodes levels in runs of the RLE/bit-packing hybrid encoding.
+// The bit width of the levels is that of the maximum level, which is never
+// more than 8, so the value of each run takes a single byte.
+func appendRLE(buf []byte, levels []uint8) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		n := binary.PutUvarint(scratch[:], uint64(j-i)<<1)
+		buf = append(buf, scratch[:n]...)
+		buf = append(buf, levels[i])
+		i = j
+	}
+	return buf
+}
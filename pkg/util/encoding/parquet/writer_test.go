@@ -0,0 +1,112 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThriftCompactWriter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var w thriftCompactWriter
+	w.beginStruct()
+	w.i32Field(1, 1)
+	w.binaryField(4, `ab`)
+	// A field id delta too large for the short form.
+	w.i64Field(20, -1)
+	w.structField(21)
+	w.i32Field(1, 3)
+	w.endStruct()
+	w.listField(22, thriftTypeI32, 2)
+	w.writeVarint(0)
+	w.writeVarint(3)
+	w.endStruct()
+
+	require.Equal(t, []byte{
+		0x15, 0x02,
+		0x38, 0x02, 'a', 'b',
+		0x06, 0x28, 0x01,
+		0x1c, 0x15, 0x06, 0x00,
+		0x19, 0x25, 0x00, 0x06,
+		0x00,
+	}, w.buf.Bytes())
+}
+
+func TestRLE(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	require.Equal(t, []byte(nil), appendRLE(nil, nil))
+	require.Equal(t, []byte{0x06, 0x01, 0x02, 0x00},
+		appendRLE(nil, []uint8{1, 1, 1, 0}))
+	require.Equal(t, []byte{0x02, 0x03, 0x04, 0x02},
+		appendRLE(nil, []uint8{3, 2, 2}))
+}
+
+func TestWriter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	columns := []Column{
+		{Name: `a`, Type: Int64, Required: true},
+		{Name: `b`, Type: ByteArray, Annotation: UTF8},
+		{Name: `c`, Type: Boolean, List: true},
+	}
+	w := NewWriter(columns, Uncompressed, `test`)
+	require.NoError(t, w.AddRow([]interface{}{int64(1), `x`, []interface{}{true, nil, false}}))
+	require.NoError(t, w.AddRow([]interface{}{int64(2), nil, []interface{}{}}))
+	require.NoError(t, w.AddRow([]interface{}{int64(3), nil, nil}))
+
+	require.EqualError(t, w.AddRow([]interface{}{int64(4)}), `expected 3 values, got 1`)
+	require.EqualError(t, w.AddRow([]interface{}{nil, nil, nil}),
+		`null value in required column a`)
+	require.EqualError(t, w.AddRow([]interface{}{int64(4), 5, nil}),
+		`unexpected value int for column b`)
+	require.EqualError(t, w.AddRow([]interface{}{int64(4), nil, true}),
+		`unexpected value bool for list column c`)
+
+	// Invalid rows are not added.
+	require.Equal(t, int64(3), w.NumRows())
+	require.Equal(t, 8*3+4+1+2, w.Size())
+	require.Equal(t, []uint8{1, 0, 0}, w.chunks[1].defLevels)
+	require.Equal(t, []uint8{3, 2, 3, 1, 0}, w.chunks[2].defLevels)
+	require.Equal(t, []uint8{0, 1, 1, 0, 0}, w.chunks[2].repLevels)
+	require.Equal(t, []bool{true, false}, w.chunks[2].bools)
+
+	for _, compression := range []Compression{Uncompressed, Snappy, Gzip} {
+		w.compression = compression
+		contents, err := w.Finish()
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(contents, []byte(magic)))
+		require.True(t, bytes.HasSuffix(contents, []byte(magic)))
+		footerLen := binary.LittleEndian.Uint32(contents[len(contents)-8:])
+		require.True(t, int(footerLen) < len(contents)-12)
+
+		f, err := ReadFile(contents)
+		require.NoError(t, err)
+		require.Equal(t, columns, f.Columns)
+		require.Equal(t, compression, f.Compression)
+		require.Equal(t, `test`, f.CreatedBy)
+		require.Equal(t, int64(3), f.NumRows)
+		require.Equal(t, [][]interface{}{
+			{int64(1), []byte(`x`), []interface{}{true, nil, false}},
+			{int64(2), nil, []interface{}{}},
+			{int64(3), nil, nil},
+		}, f.Rows)
+	}
+
+	_, err := ReadFile([]byte(`PAR1PAR1`))
+	require.EqualError(t, err, `not a parquet file`)
+}